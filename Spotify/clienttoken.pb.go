@@ -0,0 +1,619 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: Spotify/clienttoken.proto
+
+package Spotify
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ClientTokenRequestType int32
+
+const (
+	ClientTokenRequestType_REQUEST_UNKNOWN                   ClientTokenRequestType = 0
+	ClientTokenRequestType_REQUEST_CLIENT_DATA_REQUEST       ClientTokenRequestType = 1
+	ClientTokenRequestType_REQUEST_CHALLENGE_ANSWERS_REQUEST ClientTokenRequestType = 2
+)
+
+// Enum value maps for ClientTokenRequestType.
+var (
+	ClientTokenRequestType_name = map[int32]string{
+		0: "REQUEST_UNKNOWN",
+		1: "REQUEST_CLIENT_DATA_REQUEST",
+		2: "REQUEST_CHALLENGE_ANSWERS_REQUEST",
+	}
+	ClientTokenRequestType_value = map[string]int32{
+		"REQUEST_UNKNOWN":                   0,
+		"REQUEST_CLIENT_DATA_REQUEST":       1,
+		"REQUEST_CHALLENGE_ANSWERS_REQUEST": 2,
+	}
+)
+
+func (x ClientTokenRequestType) Enum() *ClientTokenRequestType {
+	p := new(ClientTokenRequestType)
+	*p = x
+	return p
+}
+
+func (x ClientTokenRequestType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ClientTokenRequestType) Descriptor() protoreflect.EnumDescriptor {
+	return file_Spotify_clienttoken_proto_enumTypes[0].Descriptor()
+}
+
+func (ClientTokenRequestType) Type() protoreflect.EnumType {
+	return &file_Spotify_clienttoken_proto_enumTypes[0]
+}
+
+func (x ClientTokenRequestType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *ClientTokenRequestType) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = ClientTokenRequestType(num)
+	return nil
+}
+
+// Deprecated: Use ClientTokenRequestType.Descriptor instead.
+func (ClientTokenRequestType) EnumDescriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{0}
+}
+
+type ClientTokenResponseType int32
+
+const (
+	ClientTokenResponseType_RESPONSE_UNKNOWN                ClientTokenResponseType = 0
+	ClientTokenResponseType_RESPONSE_GRANTED_TOKEN_RESPONSE ClientTokenResponseType = 1
+	ClientTokenResponseType_RESPONSE_CHALLENGES_RESPONSE    ClientTokenResponseType = 2
+)
+
+// Enum value maps for ClientTokenResponseType.
+var (
+	ClientTokenResponseType_name = map[int32]string{
+		0: "RESPONSE_UNKNOWN",
+		1: "RESPONSE_GRANTED_TOKEN_RESPONSE",
+		2: "RESPONSE_CHALLENGES_RESPONSE",
+	}
+	ClientTokenResponseType_value = map[string]int32{
+		"RESPONSE_UNKNOWN":                0,
+		"RESPONSE_GRANTED_TOKEN_RESPONSE": 1,
+		"RESPONSE_CHALLENGES_RESPONSE":    2,
+	}
+)
+
+func (x ClientTokenResponseType) Enum() *ClientTokenResponseType {
+	p := new(ClientTokenResponseType)
+	*p = x
+	return p
+}
+
+func (x ClientTokenResponseType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ClientTokenResponseType) Descriptor() protoreflect.EnumDescriptor {
+	return file_Spotify_clienttoken_proto_enumTypes[1].Descriptor()
+}
+
+func (ClientTokenResponseType) Type() protoreflect.EnumType {
+	return &file_Spotify_clienttoken_proto_enumTypes[1]
+}
+
+func (x ClientTokenResponseType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *ClientTokenResponseType) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = ClientTokenResponseType(num)
+	return nil
+}
+
+// Deprecated: Use ClientTokenResponseType.Descriptor instead.
+func (ClientTokenResponseType) EnumDescriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{1}
+}
+
+type ClientTokenRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestType *ClientTokenRequestType `protobuf:"varint,1,opt,name=request_type,json=requestType,enum=Spotify.ClientTokenRequestType" json:"request_type,omitempty"`
+	ClientData  *ClientDataRequest      `protobuf:"bytes,2,opt,name=client_data,json=clientData" json:"client_data,omitempty"`
+}
+
+func (x *ClientTokenRequest) Reset() {
+	*x = ClientTokenRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_clienttoken_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientTokenRequest) ProtoMessage() {}
+
+func (x *ClientTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_clienttoken_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientTokenRequest.ProtoReflect.Descriptor instead.
+func (*ClientTokenRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ClientTokenRequest) GetRequestType() ClientTokenRequestType {
+	if x != nil && x.RequestType != nil {
+		return *x.RequestType
+	}
+	return ClientTokenRequestType_REQUEST_UNKNOWN
+}
+
+func (x *ClientTokenRequest) GetClientData() *ClientDataRequest {
+	if x != nil {
+		return x.ClientData
+	}
+	return nil
+}
+
+type ClientDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClientVersion       *string              `protobuf:"bytes,1,opt,name=client_version,json=clientVersion" json:"client_version,omitempty"`
+	ClientId            *string              `protobuf:"bytes,2,opt,name=client_id,json=clientId" json:"client_id,omitempty"`
+	ConnectivitySdkData *ConnectivitySdkData `protobuf:"bytes,3,opt,name=connectivity_sdk_data,json=connectivitySdkData" json:"connectivity_sdk_data,omitempty"`
+}
+
+func (x *ClientDataRequest) Reset() {
+	*x = ClientDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_clienttoken_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientDataRequest) ProtoMessage() {}
+
+func (x *ClientDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_clienttoken_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientDataRequest.ProtoReflect.Descriptor instead.
+func (*ClientDataRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClientDataRequest) GetClientVersion() string {
+	if x != nil && x.ClientVersion != nil {
+		return *x.ClientVersion
+	}
+	return ""
+}
+
+func (x *ClientDataRequest) GetClientId() string {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return ""
+}
+
+func (x *ClientDataRequest) GetConnectivitySdkData() *ConnectivitySdkData {
+	if x != nil {
+		return x.ConnectivitySdkData
+	}
+	return nil
+}
+
+type ConnectivitySdkData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId *string `protobuf:"bytes,2,opt,name=device_id,json=deviceId" json:"device_id,omitempty"`
+}
+
+func (x *ConnectivitySdkData) Reset() {
+	*x = ConnectivitySdkData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_clienttoken_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConnectivitySdkData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectivitySdkData) ProtoMessage() {}
+
+func (x *ConnectivitySdkData) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_clienttoken_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectivitySdkData.ProtoReflect.Descriptor instead.
+func (*ConnectivitySdkData) Descriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConnectivitySdkData) GetDeviceId() string {
+	if x != nil && x.DeviceId != nil {
+		return *x.DeviceId
+	}
+	return ""
+}
+
+type ClientTokenResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ResponseType *ClientTokenResponseType `protobuf:"varint,1,opt,name=response_type,json=responseType,enum=Spotify.ClientTokenResponseType,def=1" json:"response_type,omitempty"`
+	GrantedToken *GrantedTokenResponse    `protobuf:"bytes,2,opt,name=granted_token,json=grantedToken" json:"granted_token,omitempty"`
+}
+
+// Default values for ClientTokenResponse fields.
+const (
+	Default_ClientTokenResponse_ResponseType = ClientTokenResponseType_RESPONSE_GRANTED_TOKEN_RESPONSE
+)
+
+func (x *ClientTokenResponse) Reset() {
+	*x = ClientTokenResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_clienttoken_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientTokenResponse) ProtoMessage() {}
+
+func (x *ClientTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_clienttoken_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientTokenResponse.ProtoReflect.Descriptor instead.
+func (*ClientTokenResponse) Descriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClientTokenResponse) GetResponseType() ClientTokenResponseType {
+	if x != nil && x.ResponseType != nil {
+		return *x.ResponseType
+	}
+	return Default_ClientTokenResponse_ResponseType
+}
+
+func (x *ClientTokenResponse) GetGrantedToken() *GrantedTokenResponse {
+	if x != nil {
+		return x.GrantedToken
+	}
+	return nil
+}
+
+type GrantedTokenResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token               *string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	ExpiresAfterSeconds *int32  `protobuf:"varint,2,opt,name=expires_after_seconds,json=expiresAfterSeconds" json:"expires_after_seconds,omitempty"`
+	RefreshAfterSeconds *int32  `protobuf:"varint,3,opt,name=refresh_after_seconds,json=refreshAfterSeconds" json:"refresh_after_seconds,omitempty"`
+}
+
+func (x *GrantedTokenResponse) Reset() {
+	*x = GrantedTokenResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_clienttoken_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GrantedTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantedTokenResponse) ProtoMessage() {}
+
+func (x *GrantedTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_clienttoken_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantedTokenResponse.ProtoReflect.Descriptor instead.
+func (*GrantedTokenResponse) Descriptor() ([]byte, []int) {
+	return file_Spotify_clienttoken_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GrantedTokenResponse) GetToken() string {
+	if x != nil && x.Token != nil {
+		return *x.Token
+	}
+	return ""
+}
+
+func (x *GrantedTokenResponse) GetExpiresAfterSeconds() int32 {
+	if x != nil && x.ExpiresAfterSeconds != nil {
+		return *x.ExpiresAfterSeconds
+	}
+	return 0
+}
+
+func (x *GrantedTokenResponse) GetRefreshAfterSeconds() int32 {
+	if x != nil && x.RefreshAfterSeconds != nil {
+		return *x.RefreshAfterSeconds
+	}
+	return 0
+}
+
+var File_Spotify_clienttoken_proto protoreflect.FileDescriptor
+
+var file_Spotify_clienttoken_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x53, 0x70, 0x6f,
+	0x74, 0x69, 0x66, 0x79, 0x22, 0x95, 0x01, 0x0a, 0x12, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x42, 0x0a, 0x0c, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x1f, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x0b, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x3b, 0x0a, 0x0b, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x52, 0x0a, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x22, 0xa9, 0x01, 0x0a,
+	0x11, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x50, 0x0a, 0x15, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x5f, 0x73, 0x64, 0x6b, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x53, 0x64, 0x6b, 0x44,
+	0x61, 0x74, 0x61, 0x52, 0x13, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74,
+	0x79, 0x53, 0x64, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x22, 0x32, 0x0a, 0x13, 0x43, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x53, 0x64, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x22, 0xc1, 0x01, 0x0a,
+	0x13, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x66, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x53, 0x70,
+	0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x79, 0x70, 0x65, 0x3a, 0x1f, 0x52,
+	0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x47, 0x52, 0x41, 0x4e, 0x54, 0x45, 0x44, 0x5f,
+	0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x52, 0x0c,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x42, 0x0a, 0x0d,
+	0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x47, 0x72,
+	0x61, 0x6e, 0x74, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x52, 0x0c, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x22, 0x94, 0x01, 0x0a, 0x14, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x32, 0x0a, 0x15, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72,
+	0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x66, 0x74, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x5f, 0x61,
+	0x66, 0x74, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x13, 0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x41, 0x66, 0x74, 0x65, 0x72,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x2a, 0x75, 0x0a, 0x16, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x13, 0x0a, 0x0f, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x5f, 0x55, 0x4e, 0x4b,
+	0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53,
+	0x54, 0x5f, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x44, 0x41, 0x54, 0x41, 0x5f, 0x52, 0x45,
+	0x51, 0x55, 0x45, 0x53, 0x54, 0x10, 0x01, 0x12, 0x25, 0x0a, 0x21, 0x52, 0x45, 0x51, 0x55, 0x45,
+	0x53, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47, 0x45, 0x5f, 0x41, 0x4e, 0x53,
+	0x57, 0x45, 0x52, 0x53, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x10, 0x02, 0x2a, 0x76,
+	0x0a, 0x17, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x10, 0x52, 0x45, 0x53,
+	0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
+	0x23, 0x0a, 0x1f, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x5f, 0x47, 0x52, 0x41, 0x4e,
+	0x54, 0x45, 0x44, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e,
+	0x53, 0x45, 0x10, 0x01, 0x12, 0x20, 0x0a, 0x1c, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45,
+	0x5f, 0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47, 0x45, 0x53, 0x5f, 0x52, 0x45, 0x53, 0x50,
+	0x4f, 0x4e, 0x53, 0x45, 0x10, 0x02, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32,
+}
+
+var (
+	file_Spotify_clienttoken_proto_rawDescOnce sync.Once
+	file_Spotify_clienttoken_proto_rawDescData = file_Spotify_clienttoken_proto_rawDesc
+)
+
+func file_Spotify_clienttoken_proto_rawDescGZIP() []byte {
+	file_Spotify_clienttoken_proto_rawDescOnce.Do(func() {
+		file_Spotify_clienttoken_proto_rawDescData = protoimpl.X.CompressGZIP(file_Spotify_clienttoken_proto_rawDescData)
+	})
+	return file_Spotify_clienttoken_proto_rawDescData
+}
+
+var file_Spotify_clienttoken_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_Spotify_clienttoken_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_Spotify_clienttoken_proto_goTypes = []interface{}{
+	(ClientTokenRequestType)(0),  // 0: Spotify.ClientTokenRequestType
+	(ClientTokenResponseType)(0), // 1: Spotify.ClientTokenResponseType
+	(*ClientTokenRequest)(nil),   // 2: Spotify.ClientTokenRequest
+	(*ClientDataRequest)(nil),    // 3: Spotify.ClientDataRequest
+	(*ConnectivitySdkData)(nil),  // 4: Spotify.ConnectivitySdkData
+	(*ClientTokenResponse)(nil),  // 5: Spotify.ClientTokenResponse
+	(*GrantedTokenResponse)(nil), // 6: Spotify.GrantedTokenResponse
+}
+var file_Spotify_clienttoken_proto_depIdxs = []int32{
+	0, // 0: Spotify.ClientTokenRequest.request_type:type_name -> Spotify.ClientTokenRequestType
+	3, // 1: Spotify.ClientTokenRequest.client_data:type_name -> Spotify.ClientDataRequest
+	4, // 2: Spotify.ClientDataRequest.connectivity_sdk_data:type_name -> Spotify.ConnectivitySdkData
+	1, // 3: Spotify.ClientTokenResponse.response_type:type_name -> Spotify.ClientTokenResponseType
+	6, // 4: Spotify.ClientTokenResponse.granted_token:type_name -> Spotify.GrantedTokenResponse
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_Spotify_clienttoken_proto_init() }
+func file_Spotify_clienttoken_proto_init() {
+	if File_Spotify_clienttoken_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_Spotify_clienttoken_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientTokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_clienttoken_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_clienttoken_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConnectivitySdkData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_clienttoken_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientTokenResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_clienttoken_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GrantedTokenResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_Spotify_clienttoken_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_Spotify_clienttoken_proto_goTypes,
+		DependencyIndexes: file_Spotify_clienttoken_proto_depIdxs,
+		EnumInfos:         file_Spotify_clienttoken_proto_enumTypes,
+		MessageInfos:      file_Spotify_clienttoken_proto_msgTypes,
+	}.Build()
+	File_Spotify_clienttoken_proto = out.File
+	file_Spotify_clienttoken_proto_rawDesc = nil
+	file_Spotify_clienttoken_proto_goTypes = nil
+	file_Spotify_clienttoken_proto_depIdxs = nil
+}
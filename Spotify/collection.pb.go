@@ -0,0 +1,665 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: Spotify/collection.proto
+
+package Spotify
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CollectionPageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username        *string `protobuf:"bytes,1,opt,name=username" json:"username,omitempty"`
+	Set             *string `protobuf:"bytes,2,opt,name=set" json:"set,omitempty"`
+	PaginationToken *string `protobuf:"bytes,3,opt,name=pagination_token,json=paginationToken" json:"pagination_token,omitempty"`
+	Limit           *int32  `protobuf:"varint,4,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (x *CollectionPageRequest) Reset() {
+	*x = CollectionPageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionPageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionPageRequest) ProtoMessage() {}
+
+func (x *CollectionPageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionPageRequest.ProtoReflect.Descriptor instead.
+func (*CollectionPageRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CollectionPageRequest) GetUsername() string {
+	if x != nil && x.Username != nil {
+		return *x.Username
+	}
+	return ""
+}
+
+func (x *CollectionPageRequest) GetSet() string {
+	if x != nil && x.Set != nil {
+		return *x.Set
+	}
+	return ""
+}
+
+func (x *CollectionPageRequest) GetPaginationToken() string {
+	if x != nil && x.PaginationToken != nil {
+		return *x.PaginationToken
+	}
+	return ""
+}
+
+func (x *CollectionPageRequest) GetLimit() int32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+type CollectionPageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items         []*CollectionItem `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+	NextPageToken *string           `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+	SyncToken     *string           `protobuf:"bytes,3,opt,name=sync_token,json=syncToken" json:"sync_token,omitempty"`
+}
+
+func (x *CollectionPageResponse) Reset() {
+	*x = CollectionPageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionPageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionPageResponse) ProtoMessage() {}
+
+func (x *CollectionPageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionPageResponse.ProtoReflect.Descriptor instead.
+func (*CollectionPageResponse) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CollectionPageResponse) GetItems() []*CollectionItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CollectionPageResponse) GetNextPageToken() string {
+	if x != nil && x.NextPageToken != nil {
+		return *x.NextPageToken
+	}
+	return ""
+}
+
+func (x *CollectionPageResponse) GetSyncToken() string {
+	if x != nil && x.SyncToken != nil {
+		return *x.SyncToken
+	}
+	return ""
+}
+
+type CollectionItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uri       *string `protobuf:"bytes,1,opt,name=uri" json:"uri,omitempty"`
+	AddedAt   *int32  `protobuf:"varint,2,opt,name=added_at,json=addedAt" json:"added_at,omitempty"`
+	IsRemoved *bool   `protobuf:"varint,3,opt,name=is_removed,json=isRemoved" json:"is_removed,omitempty"`
+}
+
+func (x *CollectionItem) Reset() {
+	*x = CollectionItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionItem) ProtoMessage() {}
+
+func (x *CollectionItem) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionItem.ProtoReflect.Descriptor instead.
+func (*CollectionItem) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CollectionItem) GetUri() string {
+	if x != nil && x.Uri != nil {
+		return *x.Uri
+	}
+	return ""
+}
+
+func (x *CollectionItem) GetAddedAt() int32 {
+	if x != nil && x.AddedAt != nil {
+		return *x.AddedAt
+	}
+	return 0
+}
+
+func (x *CollectionItem) GetIsRemoved() bool {
+	if x != nil && x.IsRemoved != nil {
+		return *x.IsRemoved
+	}
+	return false
+}
+
+type CollectionWriteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username       *string           `protobuf:"bytes,1,opt,name=username" json:"username,omitempty"`
+	Set            *string           `protobuf:"bytes,2,opt,name=set" json:"set,omitempty"`
+	Items          []*CollectionItem `protobuf:"bytes,3,rep,name=items" json:"items,omitempty"`
+	ClientUpdateId *string           `protobuf:"bytes,4,opt,name=client_update_id,json=clientUpdateId" json:"client_update_id,omitempty"`
+}
+
+func (x *CollectionWriteRequest) Reset() {
+	*x = CollectionWriteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionWriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionWriteRequest) ProtoMessage() {}
+
+func (x *CollectionWriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionWriteRequest.ProtoReflect.Descriptor instead.
+func (*CollectionWriteRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CollectionWriteRequest) GetUsername() string {
+	if x != nil && x.Username != nil {
+		return *x.Username
+	}
+	return ""
+}
+
+func (x *CollectionWriteRequest) GetSet() string {
+	if x != nil && x.Set != nil {
+		return *x.Set
+	}
+	return ""
+}
+
+func (x *CollectionWriteRequest) GetItems() []*CollectionItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CollectionWriteRequest) GetClientUpdateId() string {
+	if x != nil && x.ClientUpdateId != nil {
+		return *x.ClientUpdateId
+	}
+	return ""
+}
+
+type CollectionPubSubUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username *string           `protobuf:"bytes,1,opt,name=username" json:"username,omitempty"`
+	Set      *string           `protobuf:"bytes,2,opt,name=set" json:"set,omitempty"`
+	Items    []*CollectionItem `protobuf:"bytes,3,rep,name=items" json:"items,omitempty"`
+}
+
+func (x *CollectionPubSubUpdate) Reset() {
+	*x = CollectionPubSubUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionPubSubUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionPubSubUpdate) ProtoMessage() {}
+
+func (x *CollectionPubSubUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionPubSubUpdate.ProtoReflect.Descriptor instead.
+func (*CollectionPubSubUpdate) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CollectionPubSubUpdate) GetUsername() string {
+	if x != nil && x.Username != nil {
+		return *x.Username
+	}
+	return ""
+}
+
+func (x *CollectionPubSubUpdate) GetSet() string {
+	if x != nil && x.Set != nil {
+		return *x.Set
+	}
+	return ""
+}
+
+func (x *CollectionPubSubUpdate) GetItems() []*CollectionItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CollectionContainsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items    []string `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+	Username *string  `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
+	Set      *string  `protobuf:"bytes,3,opt,name=set" json:"set,omitempty"`
+}
+
+func (x *CollectionContainsRequest) Reset() {
+	*x = CollectionContainsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionContainsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionContainsRequest) ProtoMessage() {}
+
+func (x *CollectionContainsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionContainsRequest.ProtoReflect.Descriptor instead.
+func (*CollectionContainsRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CollectionContainsRequest) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CollectionContainsRequest) GetUsername() string {
+	if x != nil && x.Username != nil {
+		return *x.Username
+	}
+	return ""
+}
+
+func (x *CollectionContainsRequest) GetSet() string {
+	if x != nil && x.Set != nil {
+		return *x.Set
+	}
+	return ""
+}
+
+type CollectionContainsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Found []bool `protobuf:"varint,1,rep,name=found" json:"found,omitempty"`
+}
+
+func (x *CollectionContainsResponse) Reset() {
+	*x = CollectionContainsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_collection_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollectionContainsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionContainsResponse) ProtoMessage() {}
+
+func (x *CollectionContainsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_collection_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionContainsResponse.ProtoReflect.Descriptor instead.
+func (*CollectionContainsResponse) Descriptor() ([]byte, []int) {
+	return file_Spotify_collection_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CollectionContainsResponse) GetFound() []bool {
+	if x != nil {
+		return x.Found
+	}
+	return nil
+}
+
+var File_Spotify_collection_proto protoreflect.FileDescriptor
+
+var file_Spotify_collection_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2f, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x53, 0x70, 0x6f, 0x74,
+	0x69, 0x66, 0x79, 0x22, 0x86, 0x01, 0x0a, 0x15, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a,
+	0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x65, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x70,
+	0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x8e, 0x01, 0x0a,
+	0x16, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79,
+	0x2e, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x74, 0x65, 0x6d, 0x52,
+	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x79, 0x6e, 0x63, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x5c, 0x0a,
+	0x0e, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x74, 0x65, 0x6d, 0x12,
+	0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72,
+	0x69, 0x12, 0x19, 0x0a, 0x08, 0x61, 0x64, 0x64, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x61, 0x64, 0x64, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x69, 0x73, 0x5f, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x09, 0x69, 0x73, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x22, 0x9f, 0x01, 0x0a, 0x16,
+	0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x73, 0x65, 0x74, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f,
+	0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74,
+	0x65, 0x6d, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x64, 0x22, 0x75, 0x0a,
+	0x16, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x75, 0x62, 0x53, 0x75,
+	0x62, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x73, 0x65, 0x74, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43,
+	0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x22, 0x5f, 0x0a, 0x19, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x73, 0x65, 0x74, 0x22, 0x32, 0x0a, 0x1a, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x32,
+}
+
+var (
+	file_Spotify_collection_proto_rawDescOnce sync.Once
+	file_Spotify_collection_proto_rawDescData = file_Spotify_collection_proto_rawDesc
+)
+
+func file_Spotify_collection_proto_rawDescGZIP() []byte {
+	file_Spotify_collection_proto_rawDescOnce.Do(func() {
+		file_Spotify_collection_proto_rawDescData = protoimpl.X.CompressGZIP(file_Spotify_collection_proto_rawDescData)
+	})
+	return file_Spotify_collection_proto_rawDescData
+}
+
+var file_Spotify_collection_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_Spotify_collection_proto_goTypes = []interface{}{
+	(*CollectionPageRequest)(nil),      // 0: Spotify.CollectionPageRequest
+	(*CollectionPageResponse)(nil),     // 1: Spotify.CollectionPageResponse
+	(*CollectionItem)(nil),             // 2: Spotify.CollectionItem
+	(*CollectionWriteRequest)(nil),     // 3: Spotify.CollectionWriteRequest
+	(*CollectionPubSubUpdate)(nil),     // 4: Spotify.CollectionPubSubUpdate
+	(*CollectionContainsRequest)(nil),  // 5: Spotify.CollectionContainsRequest
+	(*CollectionContainsResponse)(nil), // 6: Spotify.CollectionContainsResponse
+}
+var file_Spotify_collection_proto_depIdxs = []int32{
+	2, // 0: Spotify.CollectionPageResponse.items:type_name -> Spotify.CollectionItem
+	2, // 1: Spotify.CollectionWriteRequest.items:type_name -> Spotify.CollectionItem
+	2, // 2: Spotify.CollectionPubSubUpdate.items:type_name -> Spotify.CollectionItem
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_Spotify_collection_proto_init() }
+func file_Spotify_collection_proto_init() {
+	if File_Spotify_collection_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_Spotify_collection_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionPageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_collection_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionPageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_collection_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_collection_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionWriteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_collection_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionPubSubUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_collection_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionContainsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_collection_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectionContainsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_Spotify_collection_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_Spotify_collection_proto_goTypes,
+		DependencyIndexes: file_Spotify_collection_proto_depIdxs,
+		MessageInfos:      file_Spotify_collection_proto_msgTypes,
+	}.Build()
+	File_Spotify_collection_proto = out.File
+	file_Spotify_collection_proto_rawDesc = nil
+	file_Spotify_collection_proto_goTypes = nil
+	file_Spotify_collection_proto_depIdxs = nil
+}
@@ -0,0 +1,1869 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: Spotify/connect.proto
+
+package Spotify
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MemberType int32
+
+const (
+	MemberType_SPIRC_V2      MemberType = 0
+	MemberType_SPIRC_V3      MemberType = 1
+	MemberType_CONNECT_STATE MemberType = 2
+)
+
+// Enum value maps for MemberType.
+var (
+	MemberType_name = map[int32]string{
+		0: "SPIRC_V2",
+		1: "SPIRC_V3",
+		2: "CONNECT_STATE",
+	}
+	MemberType_value = map[string]int32{
+		"SPIRC_V2":      0,
+		"SPIRC_V3":      1,
+		"CONNECT_STATE": 2,
+	}
+)
+
+func (x MemberType) Enum() *MemberType {
+	p := new(MemberType)
+	*p = x
+	return p
+}
+
+func (x MemberType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MemberType) Descriptor() protoreflect.EnumDescriptor {
+	return file_Spotify_connect_proto_enumTypes[0].Descriptor()
+}
+
+func (MemberType) Type() protoreflect.EnumType {
+	return &file_Spotify_connect_proto_enumTypes[0]
+}
+
+func (x MemberType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *MemberType) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = MemberType(num)
+	return nil
+}
+
+// Deprecated: Use MemberType.Descriptor instead.
+func (MemberType) EnumDescriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{0}
+}
+
+type PutStateReason int32
+
+const (
+	PutStateReason_UNKNOWN_PUT_STATE_REASON PutStateReason = 0
+	PutStateReason_SPIRC_HELLO              PutStateReason = 1
+	PutStateReason_SPIRC_NOTIFY             PutStateReason = 2
+	PutStateReason_NEW_DEVICE               PutStateReason = 3
+	PutStateReason_PLAYER_STATE_CHANGED     PutStateReason = 4
+	PutStateReason_VOLUME_CHANGED           PutStateReason = 5
+	PutStateReason_PICKER_OPENED            PutStateReason = 6
+	PutStateReason_BECAME_INACTIVE          PutStateReason = 7
+)
+
+// Enum value maps for PutStateReason.
+var (
+	PutStateReason_name = map[int32]string{
+		0: "UNKNOWN_PUT_STATE_REASON",
+		1: "SPIRC_HELLO",
+		2: "SPIRC_NOTIFY",
+		3: "NEW_DEVICE",
+		4: "PLAYER_STATE_CHANGED",
+		5: "VOLUME_CHANGED",
+		6: "PICKER_OPENED",
+		7: "BECAME_INACTIVE",
+	}
+	PutStateReason_value = map[string]int32{
+		"UNKNOWN_PUT_STATE_REASON": 0,
+		"SPIRC_HELLO":              1,
+		"SPIRC_NOTIFY":             2,
+		"NEW_DEVICE":               3,
+		"PLAYER_STATE_CHANGED":     4,
+		"VOLUME_CHANGED":           5,
+		"PICKER_OPENED":            6,
+		"BECAME_INACTIVE":          7,
+	}
+)
+
+func (x PutStateReason) Enum() *PutStateReason {
+	p := new(PutStateReason)
+	*p = x
+	return p
+}
+
+func (x PutStateReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PutStateReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_Spotify_connect_proto_enumTypes[1].Descriptor()
+}
+
+func (PutStateReason) Type() protoreflect.EnumType {
+	return &file_Spotify_connect_proto_enumTypes[1]
+}
+
+func (x PutStateReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *PutStateReason) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = PutStateReason(num)
+	return nil
+}
+
+// Deprecated: Use PutStateReason.Descriptor instead.
+func (PutStateReason) EnumDescriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{1}
+}
+
+type ClusterUpdateReason int32
+
+const (
+	ClusterUpdateReason_INVALID_CLUSTER_UPDATE_REASON ClusterUpdateReason = 0
+	ClusterUpdateReason_DEVICES_DISAPPEARED           ClusterUpdateReason = 1
+	ClusterUpdateReason_DEVICE_STATE_CHANGED          ClusterUpdateReason = 2
+	ClusterUpdateReason_NEW_DEVICE_APPEARED           ClusterUpdateReason = 3
+	ClusterUpdateReason_DEVICE_VOLUME_CHANGED         ClusterUpdateReason = 4
+	ClusterUpdateReason_DEVICE_ALIAS_CHANGED          ClusterUpdateReason = 5
+)
+
+// Enum value maps for ClusterUpdateReason.
+var (
+	ClusterUpdateReason_name = map[int32]string{
+		0: "INVALID_CLUSTER_UPDATE_REASON",
+		1: "DEVICES_DISAPPEARED",
+		2: "DEVICE_STATE_CHANGED",
+		3: "NEW_DEVICE_APPEARED",
+		4: "DEVICE_VOLUME_CHANGED",
+		5: "DEVICE_ALIAS_CHANGED",
+	}
+	ClusterUpdateReason_value = map[string]int32{
+		"INVALID_CLUSTER_UPDATE_REASON": 0,
+		"DEVICES_DISAPPEARED":           1,
+		"DEVICE_STATE_CHANGED":          2,
+		"NEW_DEVICE_APPEARED":           3,
+		"DEVICE_VOLUME_CHANGED":         4,
+		"DEVICE_ALIAS_CHANGED":          5,
+	}
+)
+
+func (x ClusterUpdateReason) Enum() *ClusterUpdateReason {
+	p := new(ClusterUpdateReason)
+	*p = x
+	return p
+}
+
+func (x ClusterUpdateReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ClusterUpdateReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_Spotify_connect_proto_enumTypes[2].Descriptor()
+}
+
+func (ClusterUpdateReason) Type() protoreflect.EnumType {
+	return &file_Spotify_connect_proto_enumTypes[2]
+}
+
+func (x ClusterUpdateReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *ClusterUpdateReason) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = ClusterUpdateReason(num)
+	return nil
+}
+
+// Deprecated: Use ClusterUpdateReason.Descriptor instead.
+func (ClusterUpdateReason) EnumDescriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{2}
+}
+
+type PutStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CallbackUrl               *string         `protobuf:"bytes,1,opt,name=callback_url,json=callbackUrl" json:"callback_url,omitempty"`
+	Device                    *Device         `protobuf:"bytes,2,opt,name=device" json:"device,omitempty"`
+	MemberType                *MemberType     `protobuf:"varint,3,opt,name=member_type,json=memberType,enum=Spotify.MemberType" json:"member_type,omitempty"`
+	IsActive                  *bool           `protobuf:"varint,4,opt,name=is_active,json=isActive" json:"is_active,omitempty"`
+	PutStateReason            *PutStateReason `protobuf:"varint,5,opt,name=put_state_reason,json=putStateReason,enum=Spotify.PutStateReason" json:"put_state_reason,omitempty"`
+	MessageId                 *uint32         `protobuf:"varint,6,opt,name=message_id,json=messageId" json:"message_id,omitempty"`
+	LastCommandSentByDeviceId *string         `protobuf:"bytes,7,opt,name=last_command_sent_by_device_id,json=lastCommandSentByDeviceId" json:"last_command_sent_by_device_id,omitempty"`
+	LastCommandMessageId      *uint32         `protobuf:"varint,8,opt,name=last_command_message_id,json=lastCommandMessageId" json:"last_command_message_id,omitempty"`
+	StartedPlayingAt          *uint64         `protobuf:"varint,9,opt,name=started_playing_at,json=startedPlayingAt" json:"started_playing_at,omitempty"`
+	HasBeenPlayingForMs       *uint64         `protobuf:"varint,11,opt,name=has_been_playing_for_ms,json=hasBeenPlayingForMs" json:"has_been_playing_for_ms,omitempty"`
+	ClientSideTimestamp       *uint64         `protobuf:"varint,12,opt,name=client_side_timestamp,json=clientSideTimestamp" json:"client_side_timestamp,omitempty"`
+	OnlyWritePlayerState      *bool           `protobuf:"varint,13,opt,name=only_write_player_state,json=onlyWritePlayerState" json:"only_write_player_state,omitempty"`
+}
+
+func (x *PutStateRequest) Reset() {
+	*x = PutStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutStateRequest) ProtoMessage() {}
+
+func (x *PutStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutStateRequest.ProtoReflect.Descriptor instead.
+func (*PutStateRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PutStateRequest) GetCallbackUrl() string {
+	if x != nil && x.CallbackUrl != nil {
+		return *x.CallbackUrl
+	}
+	return ""
+}
+
+func (x *PutStateRequest) GetDevice() *Device {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *PutStateRequest) GetMemberType() MemberType {
+	if x != nil && x.MemberType != nil {
+		return *x.MemberType
+	}
+	return MemberType_SPIRC_V2
+}
+
+func (x *PutStateRequest) GetIsActive() bool {
+	if x != nil && x.IsActive != nil {
+		return *x.IsActive
+	}
+	return false
+}
+
+func (x *PutStateRequest) GetPutStateReason() PutStateReason {
+	if x != nil && x.PutStateReason != nil {
+		return *x.PutStateReason
+	}
+	return PutStateReason_UNKNOWN_PUT_STATE_REASON
+}
+
+func (x *PutStateRequest) GetMessageId() uint32 {
+	if x != nil && x.MessageId != nil {
+		return *x.MessageId
+	}
+	return 0
+}
+
+func (x *PutStateRequest) GetLastCommandSentByDeviceId() string {
+	if x != nil && x.LastCommandSentByDeviceId != nil {
+		return *x.LastCommandSentByDeviceId
+	}
+	return ""
+}
+
+func (x *PutStateRequest) GetLastCommandMessageId() uint32 {
+	if x != nil && x.LastCommandMessageId != nil {
+		return *x.LastCommandMessageId
+	}
+	return 0
+}
+
+func (x *PutStateRequest) GetStartedPlayingAt() uint64 {
+	if x != nil && x.StartedPlayingAt != nil {
+		return *x.StartedPlayingAt
+	}
+	return 0
+}
+
+func (x *PutStateRequest) GetHasBeenPlayingForMs() uint64 {
+	if x != nil && x.HasBeenPlayingForMs != nil {
+		return *x.HasBeenPlayingForMs
+	}
+	return 0
+}
+
+func (x *PutStateRequest) GetClientSideTimestamp() uint64 {
+	if x != nil && x.ClientSideTimestamp != nil {
+		return *x.ClientSideTimestamp
+	}
+	return 0
+}
+
+func (x *PutStateRequest) GetOnlyWritePlayerState() bool {
+	if x != nil && x.OnlyWritePlayerState != nil {
+		return *x.OnlyWritePlayerState
+	}
+	return false
+}
+
+type Device struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceInfo  *DeviceInfo  `protobuf:"bytes,1,opt,name=device_info,json=deviceInfo" json:"device_info,omitempty"`
+	PlayerState *PlayerState `protobuf:"bytes,2,opt,name=player_state,json=playerState" json:"player_state,omitempty"`
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Device) GetDeviceInfo() *DeviceInfo {
+	if x != nil {
+		return x.DeviceInfo
+	}
+	return nil
+}
+
+func (x *Device) GetPlayerState() *PlayerState {
+	if x != nil {
+		return x.PlayerState
+	}
+	return nil
+}
+
+type DeviceInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CanPlay               *bool             `protobuf:"varint,1,opt,name=can_play,json=canPlay" json:"can_play,omitempty"`
+	Volume                *uint32           `protobuf:"varint,2,opt,name=volume" json:"volume,omitempty"`
+	Name                  *string           `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Capabilities          *Capabilities     `protobuf:"bytes,4,opt,name=capabilities" json:"capabilities,omitempty"`
+	DeviceSoftwareVersion *string           `protobuf:"bytes,6,opt,name=device_software_version,json=deviceSoftwareVersion" json:"device_software_version,omitempty"`
+	DeviceType            *int32            `protobuf:"varint,7,opt,name=device_type,json=deviceType" json:"device_type,omitempty"`
+	SpircVersion          *string           `protobuf:"bytes,9,opt,name=spirc_version,json=spircVersion" json:"spirc_version,omitempty"`
+	DeviceId              *string           `protobuf:"bytes,10,opt,name=device_id,json=deviceId" json:"device_id,omitempty"`
+	IsPrivateSession      *bool             `protobuf:"varint,11,opt,name=is_private_session,json=isPrivateSession" json:"is_private_session,omitempty"`
+	IsSocialConnect       *bool             `protobuf:"varint,12,opt,name=is_social_connect,json=isSocialConnect" json:"is_social_connect,omitempty"`
+	ClientId              *string           `protobuf:"bytes,13,opt,name=client_id,json=clientId" json:"client_id,omitempty"`
+	Brand                 *string           `protobuf:"bytes,14,opt,name=brand" json:"brand,omitempty"`
+	Model                 *string           `protobuf:"bytes,15,opt,name=model" json:"model,omitempty"`
+	MetadataMap           map[string]string `protobuf:"bytes,16,rep,name=metadata_map,json=metadataMap" json:"metadata_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (x *DeviceInfo) Reset() {
+	*x = DeviceInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceInfo) ProtoMessage() {}
+
+func (x *DeviceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceInfo.ProtoReflect.Descriptor instead.
+func (*DeviceInfo) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeviceInfo) GetCanPlay() bool {
+	if x != nil && x.CanPlay != nil {
+		return *x.CanPlay
+	}
+	return false
+}
+
+func (x *DeviceInfo) GetVolume() uint32 {
+	if x != nil && x.Volume != nil {
+		return *x.Volume
+	}
+	return 0
+}
+
+func (x *DeviceInfo) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *DeviceInfo) GetDeviceSoftwareVersion() string {
+	if x != nil && x.DeviceSoftwareVersion != nil {
+		return *x.DeviceSoftwareVersion
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetDeviceType() int32 {
+	if x != nil && x.DeviceType != nil {
+		return *x.DeviceType
+	}
+	return 0
+}
+
+func (x *DeviceInfo) GetSpircVersion() string {
+	if x != nil && x.SpircVersion != nil {
+		return *x.SpircVersion
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetDeviceId() string {
+	if x != nil && x.DeviceId != nil {
+		return *x.DeviceId
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetIsPrivateSession() bool {
+	if x != nil && x.IsPrivateSession != nil {
+		return *x.IsPrivateSession
+	}
+	return false
+}
+
+func (x *DeviceInfo) GetIsSocialConnect() bool {
+	if x != nil && x.IsSocialConnect != nil {
+		return *x.IsSocialConnect
+	}
+	return false
+}
+
+func (x *DeviceInfo) GetClientId() string {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetBrand() string {
+	if x != nil && x.Brand != nil {
+		return *x.Brand
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetMetadataMap() map[string]string {
+	if x != nil {
+		return x.MetadataMap
+	}
+	return nil
+}
+
+type Capabilities struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CanBePlayer                *bool    `protobuf:"varint,2,opt,name=can_be_player,json=canBePlayer" json:"can_be_player,omitempty"`
+	RestrictToLocal            *bool    `protobuf:"varint,3,opt,name=restrict_to_local,json=restrictToLocal" json:"restrict_to_local,omitempty"`
+	GaiaEqConnectId            *bool    `protobuf:"varint,5,opt,name=gaia_eq_connect_id,json=gaiaEqConnectId" json:"gaia_eq_connect_id,omitempty"`
+	SupportsLogout             *bool    `protobuf:"varint,6,opt,name=supports_logout,json=supportsLogout" json:"supports_logout,omitempty"`
+	IsObservable               *bool    `protobuf:"varint,7,opt,name=is_observable,json=isObservable" json:"is_observable,omitempty"`
+	VolumeSteps                *int32   `protobuf:"varint,8,opt,name=volume_steps,json=volumeSteps" json:"volume_steps,omitempty"`
+	SupportedTypes             []string `protobuf:"bytes,9,rep,name=supported_types,json=supportedTypes" json:"supported_types,omitempty"`
+	CommandAcks                *bool    `protobuf:"varint,10,opt,name=command_acks,json=commandAcks" json:"command_acks,omitempty"`
+	SupportsRename             *bool    `protobuf:"varint,11,opt,name=supports_rename,json=supportsRename" json:"supports_rename,omitempty"`
+	Hidden                     *bool    `protobuf:"varint,12,opt,name=hidden" json:"hidden,omitempty"`
+	DisableVolume              *bool    `protobuf:"varint,13,opt,name=disable_volume,json=disableVolume" json:"disable_volume,omitempty"`
+	ConnectDisabled            *bool    `protobuf:"varint,14,opt,name=connect_disabled,json=connectDisabled" json:"connect_disabled,omitempty"`
+	SupportsPlaylistV2         *bool    `protobuf:"varint,15,opt,name=supports_playlist_v2,json=supportsPlaylistV2" json:"supports_playlist_v2,omitempty"`
+	IsControllable             *bool    `protobuf:"varint,16,opt,name=is_controllable,json=isControllable" json:"is_controllable,omitempty"`
+	SupportsExternalEpisodes   *bool    `protobuf:"varint,17,opt,name=supports_external_episodes,json=supportsExternalEpisodes" json:"supports_external_episodes,omitempty"`
+	SupportsSetBackendMetadata *bool    `protobuf:"varint,18,opt,name=supports_set_backend_metadata,json=supportsSetBackendMetadata" json:"supports_set_backend_metadata,omitempty"`
+	SupportsTransferCommand    *bool    `protobuf:"varint,19,opt,name=supports_transfer_command,json=supportsTransferCommand" json:"supports_transfer_command,omitempty"`
+	SupportsCommandRequest     *bool    `protobuf:"varint,20,opt,name=supports_command_request,json=supportsCommandRequest" json:"supports_command_request,omitempty"`
+	IsVoiceEnabled             *bool    `protobuf:"varint,21,opt,name=is_voice_enabled,json=isVoiceEnabled" json:"is_voice_enabled,omitempty"`
+	NeedsFullPlayerState       *bool    `protobuf:"varint,22,opt,name=needs_full_player_state,json=needsFullPlayerState" json:"needs_full_player_state,omitempty"`
+	SupportsGzipPushes         *bool    `protobuf:"varint,23,opt,name=supports_gzip_pushes,json=supportsGzipPushes" json:"supports_gzip_pushes,omitempty"`
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Capabilities) GetCanBePlayer() bool {
+	if x != nil && x.CanBePlayer != nil {
+		return *x.CanBePlayer
+	}
+	return false
+}
+
+func (x *Capabilities) GetRestrictToLocal() bool {
+	if x != nil && x.RestrictToLocal != nil {
+		return *x.RestrictToLocal
+	}
+	return false
+}
+
+func (x *Capabilities) GetGaiaEqConnectId() bool {
+	if x != nil && x.GaiaEqConnectId != nil {
+		return *x.GaiaEqConnectId
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsLogout() bool {
+	if x != nil && x.SupportsLogout != nil {
+		return *x.SupportsLogout
+	}
+	return false
+}
+
+func (x *Capabilities) GetIsObservable() bool {
+	if x != nil && x.IsObservable != nil {
+		return *x.IsObservable
+	}
+	return false
+}
+
+func (x *Capabilities) GetVolumeSteps() int32 {
+	if x != nil && x.VolumeSteps != nil {
+		return *x.VolumeSteps
+	}
+	return 0
+}
+
+func (x *Capabilities) GetSupportedTypes() []string {
+	if x != nil {
+		return x.SupportedTypes
+	}
+	return nil
+}
+
+func (x *Capabilities) GetCommandAcks() bool {
+	if x != nil && x.CommandAcks != nil {
+		return *x.CommandAcks
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsRename() bool {
+	if x != nil && x.SupportsRename != nil {
+		return *x.SupportsRename
+	}
+	return false
+}
+
+func (x *Capabilities) GetHidden() bool {
+	if x != nil && x.Hidden != nil {
+		return *x.Hidden
+	}
+	return false
+}
+
+func (x *Capabilities) GetDisableVolume() bool {
+	if x != nil && x.DisableVolume != nil {
+		return *x.DisableVolume
+	}
+	return false
+}
+
+func (x *Capabilities) GetConnectDisabled() bool {
+	if x != nil && x.ConnectDisabled != nil {
+		return *x.ConnectDisabled
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsPlaylistV2() bool {
+	if x != nil && x.SupportsPlaylistV2 != nil {
+		return *x.SupportsPlaylistV2
+	}
+	return false
+}
+
+func (x *Capabilities) GetIsControllable() bool {
+	if x != nil && x.IsControllable != nil {
+		return *x.IsControllable
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsExternalEpisodes() bool {
+	if x != nil && x.SupportsExternalEpisodes != nil {
+		return *x.SupportsExternalEpisodes
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsSetBackendMetadata() bool {
+	if x != nil && x.SupportsSetBackendMetadata != nil {
+		return *x.SupportsSetBackendMetadata
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsTransferCommand() bool {
+	if x != nil && x.SupportsTransferCommand != nil {
+		return *x.SupportsTransferCommand
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsCommandRequest() bool {
+	if x != nil && x.SupportsCommandRequest != nil {
+		return *x.SupportsCommandRequest
+	}
+	return false
+}
+
+func (x *Capabilities) GetIsVoiceEnabled() bool {
+	if x != nil && x.IsVoiceEnabled != nil {
+		return *x.IsVoiceEnabled
+	}
+	return false
+}
+
+func (x *Capabilities) GetNeedsFullPlayerState() bool {
+	if x != nil && x.NeedsFullPlayerState != nil {
+		return *x.NeedsFullPlayerState
+	}
+	return false
+}
+
+func (x *Capabilities) GetSupportsGzipPushes() bool {
+	if x != nil && x.SupportsGzipPushes != nil {
+		return *x.SupportsGzipPushes
+	}
+	return false
+}
+
+type PlayerState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp             *int64         `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	ContextUri            *string        `protobuf:"bytes,2,opt,name=context_uri,json=contextUri" json:"context_uri,omitempty"`
+	ContextUrl            *string        `protobuf:"bytes,3,opt,name=context_url,json=contextUrl" json:"context_url,omitempty"`
+	Index                 *uint32        `protobuf:"varint,6,opt,name=index" json:"index,omitempty"`
+	Track                 *ProvidedTrack `protobuf:"bytes,7,opt,name=track" json:"track,omitempty"`
+	PlaybackId            *string        `protobuf:"bytes,8,opt,name=playback_id,json=playbackId" json:"playback_id,omitempty"`
+	PlaybackSpeed         *float64       `protobuf:"fixed64,9,opt,name=playback_speed,json=playbackSpeed" json:"playback_speed,omitempty"`
+	PositionAsOfTimestamp *int64         `protobuf:"varint,10,opt,name=position_as_of_timestamp,json=positionAsOfTimestamp" json:"position_as_of_timestamp,omitempty"`
+	Duration              *int64         `protobuf:"varint,11,opt,name=duration" json:"duration,omitempty"`
+	IsPlaying             *bool          `protobuf:"varint,12,opt,name=is_playing,json=isPlaying" json:"is_playing,omitempty"`
+	IsPaused              *bool          `protobuf:"varint,13,opt,name=is_paused,json=isPaused" json:"is_paused,omitempty"`
+	IsBuffering           *bool          `protobuf:"varint,14,opt,name=is_buffering,json=isBuffering" json:"is_buffering,omitempty"`
+	IsSystemInitiated     *bool          `protobuf:"varint,15,opt,name=is_system_initiated,json=isSystemInitiated" json:"is_system_initiated,omitempty"`
+	Restrictions          *Restrictions  `protobuf:"bytes,17,opt,name=restrictions" json:"restrictions,omitempty"`
+	SessionId             *string        `protobuf:"bytes,23,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	Position              *int64         `protobuf:"varint,25,opt,name=position" json:"position,omitempty"`
+}
+
+func (x *PlayerState) Reset() {
+	*x = PlayerState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerState) ProtoMessage() {}
+
+func (x *PlayerState) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerState.ProtoReflect.Descriptor instead.
+func (*PlayerState) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PlayerState) GetTimestamp() int64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+func (x *PlayerState) GetContextUri() string {
+	if x != nil && x.ContextUri != nil {
+		return *x.ContextUri
+	}
+	return ""
+}
+
+func (x *PlayerState) GetContextUrl() string {
+	if x != nil && x.ContextUrl != nil {
+		return *x.ContextUrl
+	}
+	return ""
+}
+
+func (x *PlayerState) GetIndex() uint32 {
+	if x != nil && x.Index != nil {
+		return *x.Index
+	}
+	return 0
+}
+
+func (x *PlayerState) GetTrack() *ProvidedTrack {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+func (x *PlayerState) GetPlaybackId() string {
+	if x != nil && x.PlaybackId != nil {
+		return *x.PlaybackId
+	}
+	return ""
+}
+
+func (x *PlayerState) GetPlaybackSpeed() float64 {
+	if x != nil && x.PlaybackSpeed != nil {
+		return *x.PlaybackSpeed
+	}
+	return 0
+}
+
+func (x *PlayerState) GetPositionAsOfTimestamp() int64 {
+	if x != nil && x.PositionAsOfTimestamp != nil {
+		return *x.PositionAsOfTimestamp
+	}
+	return 0
+}
+
+func (x *PlayerState) GetDuration() int64 {
+	if x != nil && x.Duration != nil {
+		return *x.Duration
+	}
+	return 0
+}
+
+func (x *PlayerState) GetIsPlaying() bool {
+	if x != nil && x.IsPlaying != nil {
+		return *x.IsPlaying
+	}
+	return false
+}
+
+func (x *PlayerState) GetIsPaused() bool {
+	if x != nil && x.IsPaused != nil {
+		return *x.IsPaused
+	}
+	return false
+}
+
+func (x *PlayerState) GetIsBuffering() bool {
+	if x != nil && x.IsBuffering != nil {
+		return *x.IsBuffering
+	}
+	return false
+}
+
+func (x *PlayerState) GetIsSystemInitiated() bool {
+	if x != nil && x.IsSystemInitiated != nil {
+		return *x.IsSystemInitiated
+	}
+	return false
+}
+
+func (x *PlayerState) GetRestrictions() *Restrictions {
+	if x != nil {
+		return x.Restrictions
+	}
+	return nil
+}
+
+func (x *PlayerState) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+func (x *PlayerState) GetPosition() int64 {
+	if x != nil && x.Position != nil {
+		return *x.Position
+	}
+	return 0
+}
+
+type ProvidedTrack struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uri      *string           `protobuf:"bytes,1,opt,name=uri" json:"uri,omitempty"`
+	Uid      *string           `protobuf:"bytes,2,opt,name=uid" json:"uid,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,3,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Provider *string           `protobuf:"bytes,6,opt,name=provider" json:"provider,omitempty"`
+}
+
+func (x *ProvidedTrack) Reset() {
+	*x = ProvidedTrack{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProvidedTrack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvidedTrack) ProtoMessage() {}
+
+func (x *ProvidedTrack) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvidedTrack.ProtoReflect.Descriptor instead.
+func (*ProvidedTrack) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProvidedTrack) GetUri() string {
+	if x != nil && x.Uri != nil {
+		return *x.Uri
+	}
+	return ""
+}
+
+func (x *ProvidedTrack) GetUid() string {
+	if x != nil && x.Uid != nil {
+		return *x.Uid
+	}
+	return ""
+}
+
+func (x *ProvidedTrack) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ProvidedTrack) GetProvider() string {
+	if x != nil && x.Provider != nil {
+		return *x.Provider
+	}
+	return ""
+}
+
+type Restrictions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DisallowPausingReasons               []string `protobuf:"bytes,1,rep,name=disallow_pausing_reasons,json=disallowPausingReasons" json:"disallow_pausing_reasons,omitempty"`
+	DisallowResumingReasons              []string `protobuf:"bytes,2,rep,name=disallow_resuming_reasons,json=disallowResumingReasons" json:"disallow_resuming_reasons,omitempty"`
+	DisallowSeekingReasons               []string `protobuf:"bytes,3,rep,name=disallow_seeking_reasons,json=disallowSeekingReasons" json:"disallow_seeking_reasons,omitempty"`
+	DisallowPeekingPrevReasons           []string `protobuf:"bytes,4,rep,name=disallow_peeking_prev_reasons,json=disallowPeekingPrevReasons" json:"disallow_peeking_prev_reasons,omitempty"`
+	DisallowPeekingNextReasons           []string `protobuf:"bytes,5,rep,name=disallow_peeking_next_reasons,json=disallowPeekingNextReasons" json:"disallow_peeking_next_reasons,omitempty"`
+	DisallowSkippingPrevReasons          []string `protobuf:"bytes,6,rep,name=disallow_skipping_prev_reasons,json=disallowSkippingPrevReasons" json:"disallow_skipping_prev_reasons,omitempty"`
+	DisallowSkippingNextReasons          []string `protobuf:"bytes,7,rep,name=disallow_skipping_next_reasons,json=disallowSkippingNextReasons" json:"disallow_skipping_next_reasons,omitempty"`
+	DisallowTogglingRepeatContextReasons []string `protobuf:"bytes,8,rep,name=disallow_toggling_repeat_context_reasons,json=disallowTogglingRepeatContextReasons" json:"disallow_toggling_repeat_context_reasons,omitempty"`
+	DisallowTogglingRepeatTrackReasons   []string `protobuf:"bytes,9,rep,name=disallow_toggling_repeat_track_reasons,json=disallowTogglingRepeatTrackReasons" json:"disallow_toggling_repeat_track_reasons,omitempty"`
+	DisallowTogglingShuffleReasons       []string `protobuf:"bytes,10,rep,name=disallow_toggling_shuffle_reasons,json=disallowTogglingShuffleReasons" json:"disallow_toggling_shuffle_reasons,omitempty"`
+	DisallowSetQueueReasons              []string `protobuf:"bytes,11,rep,name=disallow_set_queue_reasons,json=disallowSetQueueReasons" json:"disallow_set_queue_reasons,omitempty"`
+	DisallowInterruptingPlaybackReasons  []string `protobuf:"bytes,12,rep,name=disallow_interrupting_playback_reasons,json=disallowInterruptingPlaybackReasons" json:"disallow_interrupting_playback_reasons,omitempty"`
+	DisallowTransferringPlaybackReasons  []string `protobuf:"bytes,13,rep,name=disallow_transferring_playback_reasons,json=disallowTransferringPlaybackReasons" json:"disallow_transferring_playback_reasons,omitempty"`
+	DisallowRemoteControlReasons         []string `protobuf:"bytes,14,rep,name=disallow_remote_control_reasons,json=disallowRemoteControlReasons" json:"disallow_remote_control_reasons,omitempty"`
+}
+
+func (x *Restrictions) Reset() {
+	*x = Restrictions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Restrictions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Restrictions) ProtoMessage() {}
+
+func (x *Restrictions) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Restrictions.ProtoReflect.Descriptor instead.
+func (*Restrictions) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Restrictions) GetDisallowPausingReasons() []string {
+	if x != nil {
+		return x.DisallowPausingReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowResumingReasons() []string {
+	if x != nil {
+		return x.DisallowResumingReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowSeekingReasons() []string {
+	if x != nil {
+		return x.DisallowSeekingReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowPeekingPrevReasons() []string {
+	if x != nil {
+		return x.DisallowPeekingPrevReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowPeekingNextReasons() []string {
+	if x != nil {
+		return x.DisallowPeekingNextReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowSkippingPrevReasons() []string {
+	if x != nil {
+		return x.DisallowSkippingPrevReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowSkippingNextReasons() []string {
+	if x != nil {
+		return x.DisallowSkippingNextReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowTogglingRepeatContextReasons() []string {
+	if x != nil {
+		return x.DisallowTogglingRepeatContextReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowTogglingRepeatTrackReasons() []string {
+	if x != nil {
+		return x.DisallowTogglingRepeatTrackReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowTogglingShuffleReasons() []string {
+	if x != nil {
+		return x.DisallowTogglingShuffleReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowSetQueueReasons() []string {
+	if x != nil {
+		return x.DisallowSetQueueReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowInterruptingPlaybackReasons() []string {
+	if x != nil {
+		return x.DisallowInterruptingPlaybackReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowTransferringPlaybackReasons() []string {
+	if x != nil {
+		return x.DisallowTransferringPlaybackReasons
+	}
+	return nil
+}
+
+func (x *Restrictions) GetDisallowRemoteControlReasons() []string {
+	if x != nil {
+		return x.DisallowRemoteControlReasons
+	}
+	return nil
+}
+
+type Cluster struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp                *int64                 `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	ActiveDeviceId           *string                `protobuf:"bytes,2,opt,name=active_device_id,json=activeDeviceId" json:"active_device_id,omitempty"`
+	PlayerState              *PlayerState           `protobuf:"bytes,3,opt,name=player_state,json=playerState" json:"player_state,omitempty"`
+	Device                   map[string]*DeviceInfo `protobuf:"bytes,4,rep,name=device" json:"device,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TransferData             []byte                 `protobuf:"bytes,5,opt,name=transfer_data,json=transferData" json:"transfer_data,omitempty"`
+	TransferDataTimestamp    *uint64                `protobuf:"varint,6,opt,name=transfer_data_timestamp,json=transferDataTimestamp" json:"transfer_data_timestamp,omitempty"`
+	NotPlayingSinceTimestamp *int64                 `protobuf:"varint,7,opt,name=not_playing_since_timestamp,json=notPlayingSinceTimestamp" json:"not_playing_since_timestamp,omitempty"`
+	NeedFullPlayerState      *bool                  `protobuf:"varint,8,opt,name=need_full_player_state,json=needFullPlayerState" json:"need_full_player_state,omitempty"`
+	ServerTimestampMs        *int64                 `protobuf:"varint,9,opt,name=server_timestamp_ms,json=serverTimestampMs" json:"server_timestamp_ms,omitempty"`
+}
+
+func (x *Cluster) Reset() {
+	*x = Cluster{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Cluster) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cluster) ProtoMessage() {}
+
+func (x *Cluster) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cluster.ProtoReflect.Descriptor instead.
+func (*Cluster) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Cluster) GetTimestamp() int64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+func (x *Cluster) GetActiveDeviceId() string {
+	if x != nil && x.ActiveDeviceId != nil {
+		return *x.ActiveDeviceId
+	}
+	return ""
+}
+
+func (x *Cluster) GetPlayerState() *PlayerState {
+	if x != nil {
+		return x.PlayerState
+	}
+	return nil
+}
+
+func (x *Cluster) GetDevice() map[string]*DeviceInfo {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *Cluster) GetTransferData() []byte {
+	if x != nil {
+		return x.TransferData
+	}
+	return nil
+}
+
+func (x *Cluster) GetTransferDataTimestamp() uint64 {
+	if x != nil && x.TransferDataTimestamp != nil {
+		return *x.TransferDataTimestamp
+	}
+	return 0
+}
+
+func (x *Cluster) GetNotPlayingSinceTimestamp() int64 {
+	if x != nil && x.NotPlayingSinceTimestamp != nil {
+		return *x.NotPlayingSinceTimestamp
+	}
+	return 0
+}
+
+func (x *Cluster) GetNeedFullPlayerState() bool {
+	if x != nil && x.NeedFullPlayerState != nil {
+		return *x.NeedFullPlayerState
+	}
+	return false
+}
+
+func (x *Cluster) GetServerTimestampMs() int64 {
+	if x != nil && x.ServerTimestampMs != nil {
+		return *x.ServerTimestampMs
+	}
+	return 0
+}
+
+type ClusterUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cluster            *Cluster             `protobuf:"bytes,1,opt,name=cluster" json:"cluster,omitempty"`
+	UpdateReason       *ClusterUpdateReason `protobuf:"varint,2,opt,name=update_reason,json=updateReason,enum=Spotify.ClusterUpdateReason" json:"update_reason,omitempty"`
+	AckId              *string              `protobuf:"bytes,3,opt,name=ack_id,json=ackId" json:"ack_id,omitempty"`
+	DevicesThatChanged []string             `protobuf:"bytes,4,rep,name=devices_that_changed,json=devicesThatChanged" json:"devices_that_changed,omitempty"`
+}
+
+func (x *ClusterUpdate) Reset() {
+	*x = ClusterUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_connect_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClusterUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClusterUpdate) ProtoMessage() {}
+
+func (x *ClusterUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_connect_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClusterUpdate.ProtoReflect.Descriptor instead.
+func (*ClusterUpdate) Descriptor() ([]byte, []int) {
+	return file_Spotify_connect_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ClusterUpdate) GetCluster() *Cluster {
+	if x != nil {
+		return x.Cluster
+	}
+	return nil
+}
+
+func (x *ClusterUpdate) GetUpdateReason() ClusterUpdateReason {
+	if x != nil && x.UpdateReason != nil {
+		return *x.UpdateReason
+	}
+	return ClusterUpdateReason_INVALID_CLUSTER_UPDATE_REASON
+}
+
+func (x *ClusterUpdate) GetAckId() string {
+	if x != nil && x.AckId != nil {
+		return *x.AckId
+	}
+	return ""
+}
+
+func (x *ClusterUpdate) GetDevicesThatChanged() []string {
+	if x != nil {
+		return x.DevicesThatChanged
+	}
+	return nil
+}
+
+var File_Spotify_connect_proto protoreflect.FileDescriptor
+
+var file_Spotify_connect_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79,
+	0x22, 0xdb, 0x04, 0x0a, 0x0f, 0x50, 0x75, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b,
+	0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x6c, 0x6c,
+	0x62, 0x61, 0x63, 0x6b, 0x55, 0x72, 0x6c, 0x12, 0x27, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66,
+	0x79, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x34, 0x0a, 0x0b, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e,
+	0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x6d, 0x65, 0x6d, 0x62,
+	0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x61, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x41, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x12, 0x41, 0x0a, 0x10, 0x70, 0x75, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e,
+	0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x50, 0x75, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x0e, 0x70, 0x75, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x41, 0x0a, 0x1e, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x62, 0x79, 0x5f, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x19, 0x6c,
+	0x61, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x65, 0x6e, 0x74, 0x42, 0x79,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x35, 0x0a, 0x17, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x6c, 0x61, 0x73, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12,
+	0x2c, 0x0a, 0x12, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x69,
+	0x6e, 0x67, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x65, 0x64, 0x50, 0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x41, 0x74, 0x12, 0x34, 0x0a,
+	0x17, 0x68, 0x61, 0x73, 0x5f, 0x62, 0x65, 0x65, 0x6e, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x69, 0x6e,
+	0x67, 0x5f, 0x66, 0x6f, 0x72, 0x5f, 0x6d, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x13,
+	0x68, 0x61, 0x73, 0x42, 0x65, 0x65, 0x6e, 0x50, 0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x46, 0x6f,
+	0x72, 0x4d, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x69,
+	0x64, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x13, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x69, 0x64, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x35, 0x0a, 0x17, 0x6f, 0x6e, 0x6c, 0x79, 0x5f,
+	0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x6f, 0x6e, 0x6c, 0x79, 0x57, 0x72,
+	0x69, 0x74, 0x65, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x22, 0x77,
+	0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x37,
+	0x0a, 0x0c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x50,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0b, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x22, 0xd5, 0x04, 0x0a, 0x0a, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x61, 0x6e, 0x5f, 0x70, 0x6c,
+	0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x61, 0x6e, 0x50, 0x6c, 0x61,
+	0x79, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x39, 0x0a,
+	0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x61,
+	0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x52, 0x0c, 0x63, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x17, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x73, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x70, 0x69, 0x72, 0x63, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x70, 0x69, 0x72, 0x63, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x69, 0x73, 0x5f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x10, 0x69, 0x73, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x2a, 0x0a, 0x11, 0x69, 0x73, 0x5f, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f, 0x63,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x73,
+	0x53, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x72,
+	0x61, 0x6e, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x62, 0x72, 0x61, 0x6e, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x47, 0x0a, 0x0c, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x53,
+	0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x6e, 0x66,
+	0x6f, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0b, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4d, 0x61, 0x70, 0x1a,
+	0x3e, 0x0a, 0x10, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x4d, 0x61, 0x70, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0xc0, 0x07, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x12, 0x22, 0x0a, 0x0d, 0x63, 0x61, 0x6e, 0x5f, 0x62, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x63, 0x61, 0x6e, 0x42, 0x65, 0x50, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74,
+	0x5f, 0x74, 0x6f, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x72, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x54, 0x6f, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
+	0x12, 0x2b, 0x0a, 0x12, 0x67, 0x61, 0x69, 0x61, 0x5f, 0x65, 0x71, 0x5f, 0x63, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x67, 0x61,
+	0x69, 0x61, 0x45, 0x71, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x49, 0x64, 0x12, 0x27, 0x0a,
+	0x0f, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x5f, 0x6c, 0x6f, 0x67, 0x6f, 0x75, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73,
+	0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x73, 0x5f, 0x6f, 0x62, 0x73,
+	0x65, 0x72, 0x76, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69,
+	0x73, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x76,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x53, 0x74, 0x65, 0x70, 0x73, 0x12, 0x27,
+	0x0a, 0x0f, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74,
+	0x65, 0x64, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x5f, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x41, 0x63, 0x6b, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x75,
+	0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x5f, 0x72, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0e, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x64,
+	0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0d, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x56, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x5f, 0x64, 0x69,
+	0x73, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x63, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x30, 0x0a,
+	0x14, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x6c, 0x69,
+	0x73, 0x74, 0x5f, 0x76, 0x32, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x73, 0x75, 0x70,
+	0x70, 0x6f, 0x72, 0x74, 0x73, 0x50, 0x6c, 0x61, 0x79, 0x6c, 0x69, 0x73, 0x74, 0x56, 0x32, 0x12,
+	0x27, 0x0a, 0x0f, 0x69, 0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x61, 0x62,
+	0x6c, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x73, 0x43, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x3c, 0x0a, 0x1a, 0x73, 0x75, 0x70, 0x70,
+	0x6f, 0x72, 0x74, 0x73, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x65, 0x70,
+	0x69, 0x73, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x18, 0x73, 0x75,
+	0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x45, 0x70,
+	0x69, 0x73, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x1d, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72,
+	0x74, 0x73, 0x5f, 0x73, 0x65, 0x74, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x1a, 0x73,
+	0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x53, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x3a, 0x0a, 0x19, 0x73, 0x75, 0x70,
+	0x70, 0x6f, 0x72, 0x74, 0x73, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x17, 0x73, 0x75,
+	0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x38, 0x0a, 0x18, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74,
+	0x73, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x08, 0x52, 0x16, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74,
+	0x73, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x28, 0x0a, 0x10, 0x69, 0x73, 0x5f, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x5f, 0x65, 0x6e, 0x61, 0x62,
+	0x6c, 0x65, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x73, 0x56, 0x6f, 0x69,
+	0x63, 0x65, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x35, 0x0a, 0x17, 0x6e, 0x65, 0x65,
+	0x64, 0x73, 0x5f, 0x66, 0x75, 0x6c, 0x6c, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x6e, 0x65, 0x65, 0x64,
+	0x73, 0x46, 0x75, 0x6c, 0x6c, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x30, 0x0a, 0x14, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x5f, 0x67, 0x7a, 0x69,
+	0x70, 0x5f, 0x70, 0x75, 0x73, 0x68, 0x65, 0x73, 0x18, 0x17, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12,
+	0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x47, 0x7a, 0x69, 0x70, 0x50, 0x75, 0x73, 0x68,
+	0x65, 0x73, 0x22, 0xd3, 0x04, 0x0a, 0x0b, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x75, 0x72, 0x69, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x55, 0x72,
+	0x69, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x75, 0x72, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x55,
+	0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2c, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66,
+	0x79, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x64, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x52,
+	0x05, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6c, 0x61,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x6c, 0x61, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0d, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x53, 0x70, 0x65, 0x65, 0x64, 0x12, 0x37,
+	0x0a, 0x18, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x61, 0x73, 0x5f, 0x6f, 0x66,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x15, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x73, 0x4f, 0x66, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x69, 0x6e,
+	0x67, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x50, 0x6c, 0x61, 0x79, 0x69,
+	0x6e, 0x67, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x50, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12,
+	0x21, 0x0a, 0x0c, 0x69, 0x73, 0x5f, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x18,
+	0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69,
+	0x6e, 0x67, 0x12, 0x2e, 0x0a, 0x13, 0x69, 0x73, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f,
+	0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x74, 0x65, 0x64, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x11, 0x69, 0x73, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x74,
+	0x65, 0x64, 0x12, 0x39, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69,
+	0x66, 0x79, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x0c, 0x72, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x19, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xce, 0x01, 0x0a, 0x0d, 0x50, 0x72, 0x6f,
+	0x76, 0x69, 0x64, 0x65, 0x64, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x10, 0x0a, 0x03,
+	0x75, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12, 0x40,
+	0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x24, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69,
+	0x64, 0x65, 0x64, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x1a, 0x3b, 0x0a, 0x0d,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xf3, 0x07, 0x0a, 0x0c, 0x52, 0x65,
+	0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x38, 0x0a, 0x18, 0x64, 0x69,
+	0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x70, 0x61, 0x75, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x16, 0x64, 0x69,
+	0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x50, 0x61, 0x75, 0x73, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x73, 0x12, 0x3a, 0x0a, 0x19, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x5f, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x17, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73,
+	0x12, 0x38, 0x0a, 0x18, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x65, 0x65,
+	0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x16, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x53, 0x65, 0x65, 0x6b,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x41, 0x0a, 0x1d, 0x64, 0x69,
+	0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x70, 0x65, 0x65, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x70,
+	0x72, 0x65, 0x76, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x1a, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x50, 0x65, 0x65, 0x6b, 0x69,
+	0x6e, 0x67, 0x50, 0x72, 0x65, 0x76, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x41, 0x0a,
+	0x1d, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x70, 0x65, 0x65, 0x6b, 0x69, 0x6e,
+	0x67, 0x5f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x1a, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x50, 0x65,
+	0x65, 0x6b, 0x69, 0x6e, 0x67, 0x4e, 0x65, 0x78, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73,
+	0x12, 0x43, 0x0a, 0x1e, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x6b, 0x69,
+	0x70, 0x70, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x72, 0x65, 0x76, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1b, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x65, 0x76, 0x52, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x43, 0x0a, 0x1e, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x5f, 0x6e, 0x65, 0x78, 0x74, 0x5f,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1b, 0x64,
+	0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x4e,
+	0x65, 0x78, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x56, 0x0a, 0x28, 0x64, 0x69,
+	0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x74, 0x6f, 0x67, 0x67, 0x6c, 0x69, 0x6e, 0x67, 0x5f,
+	0x72, 0x65, 0x70, 0x65, 0x61, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x24, 0x64, 0x69,
+	0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x54, 0x6f, 0x67, 0x67, 0x6c, 0x69, 0x6e, 0x67, 0x52, 0x65,
+	0x70, 0x65, 0x61, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x73, 0x12, 0x52, 0x0a, 0x26, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x74,
+	0x6f, 0x67, 0x67, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x70, 0x65, 0x61, 0x74, 0x5f, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x22, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x54, 0x6f, 0x67, 0x67,
+	0x6c, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x49, 0x0a, 0x21, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x5f, 0x74, 0x6f, 0x67, 0x67, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x68, 0x75, 0x66,
+	0x66, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x1e, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x54, 0x6f, 0x67, 0x67, 0x6c,
+	0x69, 0x6e, 0x67, 0x53, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x73, 0x12, 0x3b, 0x0a, 0x1a, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x65,
+	0x74, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18,
+	0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x17, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x53,
+	0x65, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x53,
+	0x0a, 0x26, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x72, 0x75, 0x70, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x23,
+	0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x72, 0x75, 0x70,
+	0x74, 0x69, 0x6e, 0x67, 0x50, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x73, 0x12, 0x53, 0x0a, 0x26, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6c, 0x61,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x0d, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x23, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x50, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x45, 0x0a, 0x1f, 0x64, 0x69, 0x73, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x1c, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x22,
+	0x91, 0x04, 0x0a, 0x07, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x49, 0x64, 0x12, 0x37, 0x0a, 0x0c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x53, 0x70, 0x6f, 0x74,
+	0x69, 0x66, 0x79, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x0b, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x34, 0x0a, 0x06,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x53,
+	0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x36, 0x0a, 0x17, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x15, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12,
+	0x3d, 0x0a, 0x1b, 0x6e, 0x6f, 0x74, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x5f, 0x73,
+	0x69, 0x6e, 0x63, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x18, 0x6e, 0x6f, 0x74, 0x50, 0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67,
+	0x53, 0x69, 0x6e, 0x63, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x33,
+	0x0a, 0x16, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x66, 0x75, 0x6c, 0x6c, 0x5f, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13,
+	0x6e, 0x65, 0x65, 0x64, 0x46, 0x75, 0x6c, 0x6c, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x2e, 0x0a, 0x13, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x11, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x4d, 0x73, 0x1a, 0x4e, 0x0a, 0x0b, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x29, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xc7, 0x01, 0x0a, 0x0d, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2a, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79,
+	0x2e, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x12, 0x41, 0x0a, 0x0d, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69,
+	0x66, 0x79, 0x2e, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x30, 0x0a, 0x14, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x5f, 0x74, 0x68, 0x61, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x64, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x73, 0x54, 0x68, 0x61, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x2a, 0x3b, 0x0a,
+	0x0a, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x53,
+	0x50, 0x49, 0x52, 0x43, 0x5f, 0x56, 0x32, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x50, 0x49,
+	0x52, 0x43, 0x5f, 0x56, 0x33, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4e, 0x4e, 0x45,
+	0x43, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x02, 0x2a, 0xb7, 0x01, 0x0a, 0x0e, 0x50,
+	0x75, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a,
+	0x18, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x50, 0x55, 0x54, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x53,
+	0x50, 0x49, 0x52, 0x43, 0x5f, 0x48, 0x45, 0x4c, 0x4c, 0x4f, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c,
+	0x53, 0x50, 0x49, 0x52, 0x43, 0x5f, 0x4e, 0x4f, 0x54, 0x49, 0x46, 0x59, 0x10, 0x02, 0x12, 0x0e,
+	0x0a, 0x0a, 0x4e, 0x45, 0x57, 0x5f, 0x44, 0x45, 0x56, 0x49, 0x43, 0x45, 0x10, 0x03, 0x12, 0x18,
+	0x0a, 0x14, 0x50, 0x4c, 0x41, 0x59, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x43,
+	0x48, 0x41, 0x4e, 0x47, 0x45, 0x44, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x56, 0x4f, 0x4c, 0x55,
+	0x4d, 0x45, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x44, 0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d,
+	0x50, 0x49, 0x43, 0x4b, 0x45, 0x52, 0x5f, 0x4f, 0x50, 0x45, 0x4e, 0x45, 0x44, 0x10, 0x06, 0x12,
+	0x13, 0x0a, 0x0f, 0x42, 0x45, 0x43, 0x41, 0x4d, 0x45, 0x5f, 0x49, 0x4e, 0x41, 0x43, 0x54, 0x49,
+	0x56, 0x45, 0x10, 0x07, 0x2a, 0xb9, 0x01, 0x0a, 0x13, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x1d,
+	0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x43, 0x4c, 0x55, 0x53, 0x54, 0x45, 0x52, 0x5f,
+	0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x10, 0x00, 0x12,
+	0x17, 0x0a, 0x13, 0x44, 0x45, 0x56, 0x49, 0x43, 0x45, 0x53, 0x5f, 0x44, 0x49, 0x53, 0x41, 0x50,
+	0x50, 0x45, 0x41, 0x52, 0x45, 0x44, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x44, 0x45, 0x56, 0x49,
+	0x43, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x44,
+	0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x4e, 0x45, 0x57, 0x5f, 0x44, 0x45, 0x56, 0x49, 0x43, 0x45,
+	0x5f, 0x41, 0x50, 0x50, 0x45, 0x41, 0x52, 0x45, 0x44, 0x10, 0x03, 0x12, 0x19, 0x0a, 0x15, 0x44,
+	0x45, 0x56, 0x49, 0x43, 0x45, 0x5f, 0x56, 0x4f, 0x4c, 0x55, 0x4d, 0x45, 0x5f, 0x43, 0x48, 0x41,
+	0x4e, 0x47, 0x45, 0x44, 0x10, 0x04, 0x12, 0x18, 0x0a, 0x14, 0x44, 0x45, 0x56, 0x49, 0x43, 0x45,
+	0x5f, 0x41, 0x4c, 0x49, 0x41, 0x53, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x44, 0x10, 0x05,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32,
+}
+
+var (
+	file_Spotify_connect_proto_rawDescOnce sync.Once
+	file_Spotify_connect_proto_rawDescData = file_Spotify_connect_proto_rawDesc
+)
+
+func file_Spotify_connect_proto_rawDescGZIP() []byte {
+	file_Spotify_connect_proto_rawDescOnce.Do(func() {
+		file_Spotify_connect_proto_rawDescData = protoimpl.X.CompressGZIP(file_Spotify_connect_proto_rawDescData)
+	})
+	return file_Spotify_connect_proto_rawDescData
+}
+
+var file_Spotify_connect_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_Spotify_connect_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_Spotify_connect_proto_goTypes = []interface{}{
+	(MemberType)(0),          // 0: Spotify.MemberType
+	(PutStateReason)(0),      // 1: Spotify.PutStateReason
+	(ClusterUpdateReason)(0), // 2: Spotify.ClusterUpdateReason
+	(*PutStateRequest)(nil),  // 3: Spotify.PutStateRequest
+	(*Device)(nil),           // 4: Spotify.Device
+	(*DeviceInfo)(nil),       // 5: Spotify.DeviceInfo
+	(*Capabilities)(nil),     // 6: Spotify.Capabilities
+	(*PlayerState)(nil),      // 7: Spotify.PlayerState
+	(*ProvidedTrack)(nil),    // 8: Spotify.ProvidedTrack
+	(*Restrictions)(nil),     // 9: Spotify.Restrictions
+	(*Cluster)(nil),          // 10: Spotify.Cluster
+	(*ClusterUpdate)(nil),    // 11: Spotify.ClusterUpdate
+	nil,                      // 12: Spotify.DeviceInfo.MetadataMapEntry
+	nil,                      // 13: Spotify.ProvidedTrack.MetadataEntry
+	nil,                      // 14: Spotify.Cluster.DeviceEntry
+}
+var file_Spotify_connect_proto_depIdxs = []int32{
+	4,  // 0: Spotify.PutStateRequest.device:type_name -> Spotify.Device
+	0,  // 1: Spotify.PutStateRequest.member_type:type_name -> Spotify.MemberType
+	1,  // 2: Spotify.PutStateRequest.put_state_reason:type_name -> Spotify.PutStateReason
+	5,  // 3: Spotify.Device.device_info:type_name -> Spotify.DeviceInfo
+	7,  // 4: Spotify.Device.player_state:type_name -> Spotify.PlayerState
+	6,  // 5: Spotify.DeviceInfo.capabilities:type_name -> Spotify.Capabilities
+	12, // 6: Spotify.DeviceInfo.metadata_map:type_name -> Spotify.DeviceInfo.MetadataMapEntry
+	8,  // 7: Spotify.PlayerState.track:type_name -> Spotify.ProvidedTrack
+	9,  // 8: Spotify.PlayerState.restrictions:type_name -> Spotify.Restrictions
+	13, // 9: Spotify.ProvidedTrack.metadata:type_name -> Spotify.ProvidedTrack.MetadataEntry
+	7,  // 10: Spotify.Cluster.player_state:type_name -> Spotify.PlayerState
+	14, // 11: Spotify.Cluster.device:type_name -> Spotify.Cluster.DeviceEntry
+	10, // 12: Spotify.ClusterUpdate.cluster:type_name -> Spotify.Cluster
+	2,  // 13: Spotify.ClusterUpdate.update_reason:type_name -> Spotify.ClusterUpdateReason
+	5,  // 14: Spotify.Cluster.DeviceEntry.value:type_name -> Spotify.DeviceInfo
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_Spotify_connect_proto_init() }
+func file_Spotify_connect_proto_init() {
+	if File_Spotify_connect_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_Spotify_connect_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Device); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Capabilities); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProvidedTrack); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Restrictions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Cluster); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_connect_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClusterUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_Spotify_connect_proto_rawDesc,
+			NumEnums:      3,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_Spotify_connect_proto_goTypes,
+		DependencyIndexes: file_Spotify_connect_proto_depIdxs,
+		EnumInfos:         file_Spotify_connect_proto_enumTypes,
+		MessageInfos:      file_Spotify_connect_proto_msgTypes,
+	}.Build()
+	File_Spotify_connect_proto = out.File
+	file_Spotify_connect_proto_rawDesc = nil
+	file_Spotify_connect_proto_goTypes = nil
+	file_Spotify_connect_proto_depIdxs = nil
+}
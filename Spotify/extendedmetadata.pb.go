@@ -0,0 +1,733 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: Spotify/extendedmetadata.proto
+
+package Spotify
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BatchedEntityRequestHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Country   *string `protobuf:"bytes,1,opt,name=country" json:"country,omitempty"`
+	Catalogue *string `protobuf:"bytes,2,opt,name=catalogue" json:"catalogue,omitempty"`
+	TaskId    []byte  `protobuf:"bytes,3,opt,name=task_id,json=taskId" json:"task_id,omitempty"`
+}
+
+func (x *BatchedEntityRequestHeader) Reset() {
+	*x = BatchedEntityRequestHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchedEntityRequestHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchedEntityRequestHeader) ProtoMessage() {}
+
+func (x *BatchedEntityRequestHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchedEntityRequestHeader.ProtoReflect.Descriptor instead.
+func (*BatchedEntityRequestHeader) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BatchedEntityRequestHeader) GetCountry() string {
+	if x != nil && x.Country != nil {
+		return *x.Country
+	}
+	return ""
+}
+
+func (x *BatchedEntityRequestHeader) GetCatalogue() string {
+	if x != nil && x.Catalogue != nil {
+		return *x.Catalogue
+	}
+	return ""
+}
+
+func (x *BatchedEntityRequestHeader) GetTaskId() []byte {
+	if x != nil {
+		return x.TaskId
+	}
+	return nil
+}
+
+type BatchedEntityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Header        *BatchedEntityRequestHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	EntityRequest []*EntityRequest            `protobuf:"bytes,2,rep,name=entity_request,json=entityRequest" json:"entity_request,omitempty"`
+}
+
+func (x *BatchedEntityRequest) Reset() {
+	*x = BatchedEntityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchedEntityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchedEntityRequest) ProtoMessage() {}
+
+func (x *BatchedEntityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchedEntityRequest.ProtoReflect.Descriptor instead.
+func (*BatchedEntityRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchedEntityRequest) GetHeader() *BatchedEntityRequestHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *BatchedEntityRequest) GetEntityRequest() []*EntityRequest {
+	if x != nil {
+		return x.EntityRequest
+	}
+	return nil
+}
+
+type EntityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EntityUri *string           `protobuf:"bytes,1,opt,name=entity_uri,json=entityUri" json:"entity_uri,omitempty"`
+	Query     []*ExtensionQuery `protobuf:"bytes,2,rep,name=query" json:"query,omitempty"`
+}
+
+func (x *EntityRequest) Reset() {
+	*x = EntityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityRequest) ProtoMessage() {}
+
+func (x *EntityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityRequest.ProtoReflect.Descriptor instead.
+func (*EntityRequest) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EntityRequest) GetEntityUri() string {
+	if x != nil && x.EntityUri != nil {
+		return *x.EntityUri
+	}
+	return ""
+}
+
+func (x *EntityRequest) GetQuery() []*ExtensionQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+type ExtensionQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ExtensionKind *int32  `protobuf:"varint,1,opt,name=extension_kind,json=extensionKind" json:"extension_kind,omitempty"`
+	Etag          *string `protobuf:"bytes,2,opt,name=etag" json:"etag,omitempty"`
+}
+
+func (x *ExtensionQuery) Reset() {
+	*x = ExtensionQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExtensionQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtensionQuery) ProtoMessage() {}
+
+func (x *ExtensionQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtensionQuery.ProtoReflect.Descriptor instead.
+func (*ExtensionQuery) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExtensionQuery) GetExtensionKind() int32 {
+	if x != nil && x.ExtensionKind != nil {
+		return *x.ExtensionKind
+	}
+	return 0
+}
+
+func (x *ExtensionQuery) GetEtag() string {
+	if x != nil && x.Etag != nil {
+		return *x.Etag
+	}
+	return ""
+}
+
+type BatchedExtensionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ExtendedMetadata []*EntityExtensionDataArray `protobuf:"bytes,2,rep,name=extended_metadata,json=extendedMetadata" json:"extended_metadata,omitempty"`
+}
+
+func (x *BatchedExtensionResponse) Reset() {
+	*x = BatchedExtensionResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchedExtensionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchedExtensionResponse) ProtoMessage() {}
+
+func (x *BatchedExtensionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchedExtensionResponse.ProtoReflect.Descriptor instead.
+func (*BatchedExtensionResponse) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchedExtensionResponse) GetExtendedMetadata() []*EntityExtensionDataArray {
+	if x != nil {
+		return x.ExtendedMetadata
+	}
+	return nil
+}
+
+type EntityExtensionDataArray struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ExtensionKind *int32                 `protobuf:"varint,2,opt,name=extension_kind,json=extensionKind" json:"extension_kind,omitempty"`
+	ExtensionData []*EntityExtensionData `protobuf:"bytes,3,rep,name=extension_data,json=extensionData" json:"extension_data,omitempty"`
+}
+
+func (x *EntityExtensionDataArray) Reset() {
+	*x = EntityExtensionDataArray{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntityExtensionDataArray) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityExtensionDataArray) ProtoMessage() {}
+
+func (x *EntityExtensionDataArray) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityExtensionDataArray.ProtoReflect.Descriptor instead.
+func (*EntityExtensionDataArray) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EntityExtensionDataArray) GetExtensionKind() int32 {
+	if x != nil && x.ExtensionKind != nil {
+		return *x.ExtensionKind
+	}
+	return 0
+}
+
+func (x *EntityExtensionDataArray) GetExtensionData() []*EntityExtensionData {
+	if x != nil {
+		return x.ExtensionData
+	}
+	return nil
+}
+
+type EntityExtensionData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Header        *EntityExtensionDataHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	EntityUri     *string                    `protobuf:"bytes,2,opt,name=entity_uri,json=entityUri" json:"entity_uri,omitempty"`
+	ExtensionData *anypb.Any                 `protobuf:"bytes,3,opt,name=extension_data,json=extensionData" json:"extension_data,omitempty"`
+}
+
+func (x *EntityExtensionData) Reset() {
+	*x = EntityExtensionData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntityExtensionData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityExtensionData) ProtoMessage() {}
+
+func (x *EntityExtensionData) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityExtensionData.ProtoReflect.Descriptor instead.
+func (*EntityExtensionData) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EntityExtensionData) GetHeader() *EntityExtensionDataHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *EntityExtensionData) GetEntityUri() string {
+	if x != nil && x.EntityUri != nil {
+		return *x.EntityUri
+	}
+	return ""
+}
+
+func (x *EntityExtensionData) GetExtensionData() *anypb.Any {
+	if x != nil {
+		return x.ExtensionData
+	}
+	return nil
+}
+
+type EntityExtensionDataHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StatusCode          *int32  `protobuf:"varint,1,opt,name=status_code,json=statusCode" json:"status_code,omitempty"`
+	Etag                *string `protobuf:"bytes,2,opt,name=etag" json:"etag,omitempty"`
+	Locale              *string `protobuf:"bytes,3,opt,name=locale" json:"locale,omitempty"`
+	CacheTtlInSeconds   *int64  `protobuf:"varint,4,opt,name=cache_ttl_in_seconds,json=cacheTtlInSeconds" json:"cache_ttl_in_seconds,omitempty"`
+	OfflineTtlInSeconds *int64  `protobuf:"varint,5,opt,name=offline_ttl_in_seconds,json=offlineTtlInSeconds" json:"offline_ttl_in_seconds,omitempty"`
+}
+
+func (x *EntityExtensionDataHeader) Reset() {
+	*x = EntityExtensionDataHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_extendedmetadata_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntityExtensionDataHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityExtensionDataHeader) ProtoMessage() {}
+
+func (x *EntityExtensionDataHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_extendedmetadata_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityExtensionDataHeader.ProtoReflect.Descriptor instead.
+func (*EntityExtensionDataHeader) Descriptor() ([]byte, []int) {
+	return file_Spotify_extendedmetadata_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EntityExtensionDataHeader) GetStatusCode() int32 {
+	if x != nil && x.StatusCode != nil {
+		return *x.StatusCode
+	}
+	return 0
+}
+
+func (x *EntityExtensionDataHeader) GetEtag() string {
+	if x != nil && x.Etag != nil {
+		return *x.Etag
+	}
+	return ""
+}
+
+func (x *EntityExtensionDataHeader) GetLocale() string {
+	if x != nil && x.Locale != nil {
+		return *x.Locale
+	}
+	return ""
+}
+
+func (x *EntityExtensionDataHeader) GetCacheTtlInSeconds() int64 {
+	if x != nil && x.CacheTtlInSeconds != nil {
+		return *x.CacheTtlInSeconds
+	}
+	return 0
+}
+
+func (x *EntityExtensionDataHeader) GetOfflineTtlInSeconds() int64 {
+	if x != nil && x.OfflineTtlInSeconds != nil {
+		return *x.OfflineTtlInSeconds
+	}
+	return 0
+}
+
+var File_Spotify_extendedmetadata_proto protoreflect.FileDescriptor
+
+var file_Spotify_extendedmetadata_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x64,
+	0x65, 0x64, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x07, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x1a, 0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6d, 0x0a, 0x1a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x45,
+	0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09,
+	0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x75, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x61,
+	0x73, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x74, 0x61, 0x73,
+	0x6b, 0x49, 0x64, 0x22, 0x92, 0x01, 0x0a, 0x14, 0x42, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x45,
+	0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x06,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x53,
+	0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x45, 0x6e,
+	0x74, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x0e, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x45, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x0d, 0x65, 0x6e, 0x74, 0x69, 0x74,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5d, 0x0a, 0x0d, 0x45, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x5f, 0x75, 0x72, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65,
+	0x6e, 0x74, 0x69, 0x74, 0x79, 0x55, 0x72, 0x69, 0x12, 0x2d, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66,
+	0x79, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x22, 0x4b, 0x0a, 0x0e, 0x45, 0x78, 0x74, 0x65, 0x6e,
+	0x73, 0x69, 0x6f, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x65, 0x78, 0x74,
+	0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x4b, 0x69, 0x6e, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x65, 0x74, 0x61, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x65, 0x74, 0x61, 0x67, 0x22, 0x6a, 0x0a, 0x18, 0x42, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x45,
+	0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x4e, 0x0a, 0x11, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x53, 0x70,
+	0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x45, 0x78, 0x74, 0x65,
+	0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x41, 0x72, 0x72, 0x61, 0x79, 0x52, 0x10,
+	0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0x86, 0x01, 0x0a, 0x18, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e,
+	0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x41, 0x72, 0x72, 0x61, 0x79, 0x12, 0x25, 0x0a,
+	0x0e, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6b, 0x69, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
+	0x4b, 0x69, 0x6e, 0x64, 0x12, 0x43, 0x0a, 0x0e, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x53,
+	0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x45, 0x78, 0x74,
+	0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x0d, 0x65, 0x78, 0x74, 0x65,
+	0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x22, 0xad, 0x01, 0x0a, 0x13, 0x45, 0x6e,
+	0x74, 0x69, 0x74, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74,
+	0x61, 0x12, 0x3a, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x45, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x0a,
+	0x0a, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x5f, 0x75, 0x72, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x55, 0x72, 0x69, 0x12, 0x3b, 0x0a, 0x0e,
+	0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x0d, 0x65, 0x78, 0x74, 0x65,
+	0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x22, 0xce, 0x01, 0x0a, 0x19, 0x45, 0x6e,
+	0x74, 0x69, 0x74, 0x79, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74,
+	0x61, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x65, 0x74, 0x61, 0x67,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x65, 0x74, 0x61, 0x67, 0x12, 0x16, 0x0a, 0x06,
+	0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f,
+	0x63, 0x61, 0x6c, 0x65, 0x12, 0x2f, 0x0a, 0x14, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x74, 0x74,
+	0x6c, 0x5f, 0x69, 0x6e, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x11, 0x63, 0x61, 0x63, 0x68, 0x65, 0x54, 0x74, 0x6c, 0x49, 0x6e, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x33, 0x0a, 0x16, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65,
+	0x5f, 0x74, 0x74, 0x6c, 0x5f, 0x69, 0x6e, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x54, 0x74,
+	0x6c, 0x49, 0x6e, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x32,
+}
+
+var (
+	file_Spotify_extendedmetadata_proto_rawDescOnce sync.Once
+	file_Spotify_extendedmetadata_proto_rawDescData = file_Spotify_extendedmetadata_proto_rawDesc
+)
+
+func file_Spotify_extendedmetadata_proto_rawDescGZIP() []byte {
+	file_Spotify_extendedmetadata_proto_rawDescOnce.Do(func() {
+		file_Spotify_extendedmetadata_proto_rawDescData = protoimpl.X.CompressGZIP(file_Spotify_extendedmetadata_proto_rawDescData)
+	})
+	return file_Spotify_extendedmetadata_proto_rawDescData
+}
+
+var file_Spotify_extendedmetadata_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_Spotify_extendedmetadata_proto_goTypes = []interface{}{
+	(*BatchedEntityRequestHeader)(nil), // 0: Spotify.BatchedEntityRequestHeader
+	(*BatchedEntityRequest)(nil),       // 1: Spotify.BatchedEntityRequest
+	(*EntityRequest)(nil),              // 2: Spotify.EntityRequest
+	(*ExtensionQuery)(nil),             // 3: Spotify.ExtensionQuery
+	(*BatchedExtensionResponse)(nil),   // 4: Spotify.BatchedExtensionResponse
+	(*EntityExtensionDataArray)(nil),   // 5: Spotify.EntityExtensionDataArray
+	(*EntityExtensionData)(nil),        // 6: Spotify.EntityExtensionData
+	(*EntityExtensionDataHeader)(nil),  // 7: Spotify.EntityExtensionDataHeader
+	(*anypb.Any)(nil),                  // 8: google.protobuf.Any
+}
+var file_Spotify_extendedmetadata_proto_depIdxs = []int32{
+	0, // 0: Spotify.BatchedEntityRequest.header:type_name -> Spotify.BatchedEntityRequestHeader
+	2, // 1: Spotify.BatchedEntityRequest.entity_request:type_name -> Spotify.EntityRequest
+	3, // 2: Spotify.EntityRequest.query:type_name -> Spotify.ExtensionQuery
+	5, // 3: Spotify.BatchedExtensionResponse.extended_metadata:type_name -> Spotify.EntityExtensionDataArray
+	6, // 4: Spotify.EntityExtensionDataArray.extension_data:type_name -> Spotify.EntityExtensionData
+	7, // 5: Spotify.EntityExtensionData.header:type_name -> Spotify.EntityExtensionDataHeader
+	8, // 6: Spotify.EntityExtensionData.extension_data:type_name -> google.protobuf.Any
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_Spotify_extendedmetadata_proto_init() }
+func file_Spotify_extendedmetadata_proto_init() {
+	if File_Spotify_extendedmetadata_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_Spotify_extendedmetadata_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchedEntityRequestHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchedEntityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExtensionQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchedExtensionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntityExtensionDataArray); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntityExtensionData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_extendedmetadata_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntityExtensionDataHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_Spotify_extendedmetadata_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_Spotify_extendedmetadata_proto_goTypes,
+		DependencyIndexes: file_Spotify_extendedmetadata_proto_depIdxs,
+		MessageInfos:      file_Spotify_extendedmetadata_proto_msgTypes,
+	}.Build()
+	File_Spotify_extendedmetadata_proto = out.File
+	file_Spotify_extendedmetadata_proto_rawDesc = nil
+	file_Spotify_extendedmetadata_proto_goTypes = nil
+	file_Spotify_extendedmetadata_proto_depIdxs = nil
+}
@@ -0,0 +1,230 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: Spotify/storage-resolve.proto
+
+package Spotify
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StorageResolveResponse_Result int32
+
+const (
+	StorageResolveResponse_CDN        StorageResolveResponse_Result = 0
+	StorageResolveResponse_STORAGE    StorageResolveResponse_Result = 1
+	StorageResolveResponse_RESTRICTED StorageResolveResponse_Result = 3
+)
+
+// Enum value maps for StorageResolveResponse_Result.
+var (
+	StorageResolveResponse_Result_name = map[int32]string{
+		0: "CDN",
+		1: "STORAGE",
+		3: "RESTRICTED",
+	}
+	StorageResolveResponse_Result_value = map[string]int32{
+		"CDN":        0,
+		"STORAGE":    1,
+		"RESTRICTED": 3,
+	}
+)
+
+func (x StorageResolveResponse_Result) Enum() *StorageResolveResponse_Result {
+	p := new(StorageResolveResponse_Result)
+	*p = x
+	return p
+}
+
+func (x StorageResolveResponse_Result) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StorageResolveResponse_Result) Descriptor() protoreflect.EnumDescriptor {
+	return file_Spotify_storage_resolve_proto_enumTypes[0].Descriptor()
+}
+
+func (StorageResolveResponse_Result) Type() protoreflect.EnumType {
+	return &file_Spotify_storage_resolve_proto_enumTypes[0]
+}
+
+func (x StorageResolveResponse_Result) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *StorageResolveResponse_Result) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = StorageResolveResponse_Result(num)
+	return nil
+}
+
+// Deprecated: Use StorageResolveResponse_Result.Descriptor instead.
+func (StorageResolveResponse_Result) EnumDescriptor() ([]byte, []int) {
+	return file_Spotify_storage_resolve_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type StorageResolveResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *StorageResolveResponse_Result `protobuf:"varint,1,opt,name=result,enum=Spotify.StorageResolveResponse_Result" json:"result,omitempty"`
+	Cdnurl []string                       `protobuf:"bytes,2,rep,name=cdnurl" json:"cdnurl,omitempty"`
+	Fileid []byte                         `protobuf:"bytes,4,opt,name=fileid" json:"fileid,omitempty"`
+}
+
+func (x *StorageResolveResponse) Reset() {
+	*x = StorageResolveResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_storage_resolve_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageResolveResponse) ProtoMessage() {}
+
+func (x *StorageResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_storage_resolve_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageResolveResponse.ProtoReflect.Descriptor instead.
+func (*StorageResolveResponse) Descriptor() ([]byte, []int) {
+	return file_Spotify_storage_resolve_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StorageResolveResponse) GetResult() StorageResolveResponse_Result {
+	if x != nil && x.Result != nil {
+		return *x.Result
+	}
+	return StorageResolveResponse_CDN
+}
+
+func (x *StorageResolveResponse) GetCdnurl() []string {
+	if x != nil {
+		return x.Cdnurl
+	}
+	return nil
+}
+
+func (x *StorageResolveResponse) GetFileid() []byte {
+	if x != nil {
+		return x.Fileid
+	}
+	return nil
+}
+
+var File_Spotify_storage_resolve_proto protoreflect.FileDescriptor
+
+var file_Spotify_storage_resolve_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x2d, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x07, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x22, 0xb8, 0x01, 0x0a, 0x16, 0x53, 0x74, 0x6f,
+	0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x26, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x53, 0x74,
+	0x6f, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x64, 0x6e, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x63, 0x64, 0x6e, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x66,
+	0x69, 0x6c, 0x65, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x66, 0x69, 0x6c,
+	0x65, 0x69, 0x64, 0x22, 0x2e, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x07, 0x0a,
+	0x03, 0x43, 0x44, 0x4e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47,
+	0x45, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x52, 0x45, 0x53, 0x54, 0x52, 0x49, 0x43, 0x54, 0x45,
+	0x44, 0x10, 0x03, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32,
+}
+
+var (
+	file_Spotify_storage_resolve_proto_rawDescOnce sync.Once
+	file_Spotify_storage_resolve_proto_rawDescData = file_Spotify_storage_resolve_proto_rawDesc
+)
+
+func file_Spotify_storage_resolve_proto_rawDescGZIP() []byte {
+	file_Spotify_storage_resolve_proto_rawDescOnce.Do(func() {
+		file_Spotify_storage_resolve_proto_rawDescData = protoimpl.X.CompressGZIP(file_Spotify_storage_resolve_proto_rawDescData)
+	})
+	return file_Spotify_storage_resolve_proto_rawDescData
+}
+
+var file_Spotify_storage_resolve_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_Spotify_storage_resolve_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_Spotify_storage_resolve_proto_goTypes = []interface{}{
+	(StorageResolveResponse_Result)(0), // 0: Spotify.StorageResolveResponse.Result
+	(*StorageResolveResponse)(nil),     // 1: Spotify.StorageResolveResponse
+}
+var file_Spotify_storage_resolve_proto_depIdxs = []int32{
+	0, // 0: Spotify.StorageResolveResponse.result:type_name -> Spotify.StorageResolveResponse.Result
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_Spotify_storage_resolve_proto_init() }
+func file_Spotify_storage_resolve_proto_init() {
+	if File_Spotify_storage_resolve_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_Spotify_storage_resolve_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageResolveResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_Spotify_storage_resolve_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_Spotify_storage_resolve_proto_goTypes,
+		DependencyIndexes: file_Spotify_storage_resolve_proto_depIdxs,
+		EnumInfos:         file_Spotify_storage_resolve_proto_enumTypes,
+		MessageInfos:      file_Spotify_storage_resolve_proto_msgTypes,
+	}.Build()
+	File_Spotify_storage_resolve_proto = out.File
+	file_Spotify_storage_resolve_proto_rawDesc = nil
+	file_Spotify_storage_resolve_proto_goTypes = nil
+	file_Spotify_storage_resolve_proto_depIdxs = nil
+}
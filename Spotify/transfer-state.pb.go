@@ -0,0 +1,984 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: Spotify/transfer-state.proto
+
+package Spotify
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransferState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Options           *ContextPlayerOptions `protobuf:"bytes,1,opt,name=options" json:"options,omitempty"`
+	Playback          *Playback             `protobuf:"bytes,2,opt,name=playback" json:"playback,omitempty"`
+	CurrentSession    *Session              `protobuf:"bytes,3,opt,name=current_session,json=currentSession" json:"current_session,omitempty"`
+	Queue             *Queue                `protobuf:"bytes,4,opt,name=queue" json:"queue,omitempty"`
+	CreationTimestamp *int64                `protobuf:"varint,5,opt,name=creation_timestamp,json=creationTimestamp" json:"creation_timestamp,omitempty"`
+}
+
+func (x *TransferState) Reset() {
+	*x = TransferState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferState) ProtoMessage() {}
+
+func (x *TransferState) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferState.ProtoReflect.Descriptor instead.
+func (*TransferState) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TransferState) GetOptions() *ContextPlayerOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *TransferState) GetPlayback() *Playback {
+	if x != nil {
+		return x.Playback
+	}
+	return nil
+}
+
+func (x *TransferState) GetCurrentSession() *Session {
+	if x != nil {
+		return x.CurrentSession
+	}
+	return nil
+}
+
+func (x *TransferState) GetQueue() *Queue {
+	if x != nil {
+		return x.Queue
+	}
+	return nil
+}
+
+func (x *TransferState) GetCreationTimestamp() int64 {
+	if x != nil && x.CreationTimestamp != nil {
+		return *x.CreationTimestamp
+	}
+	return 0
+}
+
+type ContextPlayerOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ShufflingContext *bool `protobuf:"varint,1,opt,name=shuffling_context,json=shufflingContext" json:"shuffling_context,omitempty"`
+	RepeatingContext *bool `protobuf:"varint,2,opt,name=repeating_context,json=repeatingContext" json:"repeating_context,omitempty"`
+	RepeatingTrack   *bool `protobuf:"varint,3,opt,name=repeating_track,json=repeatingTrack" json:"repeating_track,omitempty"`
+}
+
+func (x *ContextPlayerOptions) Reset() {
+	*x = ContextPlayerOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContextPlayerOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextPlayerOptions) ProtoMessage() {}
+
+func (x *ContextPlayerOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextPlayerOptions.ProtoReflect.Descriptor instead.
+func (*ContextPlayerOptions) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ContextPlayerOptions) GetShufflingContext() bool {
+	if x != nil && x.ShufflingContext != nil {
+		return *x.ShufflingContext
+	}
+	return false
+}
+
+func (x *ContextPlayerOptions) GetRepeatingContext() bool {
+	if x != nil && x.RepeatingContext != nil {
+		return *x.RepeatingContext
+	}
+	return false
+}
+
+func (x *ContextPlayerOptions) GetRepeatingTrack() bool {
+	if x != nil && x.RepeatingTrack != nil {
+		return *x.RepeatingTrack
+	}
+	return false
+}
+
+type Playback struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp             *int64        `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	PositionAsOfTimestamp *int32        `protobuf:"varint,2,opt,name=position_as_of_timestamp,json=positionAsOfTimestamp" json:"position_as_of_timestamp,omitempty"`
+	PlaybackSpeed         *float64      `protobuf:"fixed64,3,opt,name=playback_speed,json=playbackSpeed" json:"playback_speed,omitempty"`
+	IsPaused              *bool         `protobuf:"varint,4,opt,name=is_paused,json=isPaused" json:"is_paused,omitempty"`
+	CurrentTrack          *ContextTrack `protobuf:"bytes,5,opt,name=current_track,json=currentTrack" json:"current_track,omitempty"`
+}
+
+func (x *Playback) Reset() {
+	*x = Playback{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Playback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Playback) ProtoMessage() {}
+
+func (x *Playback) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Playback.ProtoReflect.Descriptor instead.
+func (*Playback) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Playback) GetTimestamp() int64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+func (x *Playback) GetPositionAsOfTimestamp() int32 {
+	if x != nil && x.PositionAsOfTimestamp != nil {
+		return *x.PositionAsOfTimestamp
+	}
+	return 0
+}
+
+func (x *Playback) GetPlaybackSpeed() float64 {
+	if x != nil && x.PlaybackSpeed != nil {
+		return *x.PlaybackSpeed
+	}
+	return 0
+}
+
+func (x *Playback) GetIsPaused() bool {
+	if x != nil && x.IsPaused != nil {
+		return *x.IsPaused
+	}
+	return false
+}
+
+func (x *Playback) GetCurrentTrack() *ContextTrack {
+	if x != nil {
+		return x.CurrentTrack
+	}
+	return nil
+}
+
+type Session struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PlayOrigin *PlayOrigin `protobuf:"bytes,1,opt,name=play_origin,json=playOrigin" json:"play_origin,omitempty"`
+	Context    *Context    `protobuf:"bytes,2,opt,name=context" json:"context,omitempty"`
+	CurrentUid *string     `protobuf:"bytes,3,opt,name=current_uid,json=currentUid" json:"current_uid,omitempty"`
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Session) GetPlayOrigin() *PlayOrigin {
+	if x != nil {
+		return x.PlayOrigin
+	}
+	return nil
+}
+
+func (x *Session) GetContext() *Context {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *Session) GetCurrentUid() string {
+	if x != nil && x.CurrentUid != nil {
+		return *x.CurrentUid
+	}
+	return ""
+}
+
+type Queue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tracks         []*ContextTrack `protobuf:"bytes,1,rep,name=tracks" json:"tracks,omitempty"`
+	IsPlayingQueue *bool           `protobuf:"varint,2,opt,name=is_playing_queue,json=isPlayingQueue" json:"is_playing_queue,omitempty"`
+}
+
+func (x *Queue) Reset() {
+	*x = Queue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Queue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Queue) ProtoMessage() {}
+
+func (x *Queue) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Queue.ProtoReflect.Descriptor instead.
+func (*Queue) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Queue) GetTracks() []*ContextTrack {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *Queue) GetIsPlayingQueue() bool {
+	if x != nil && x.IsPlayingQueue != nil {
+		return *x.IsPlayingQueue
+	}
+	return false
+}
+
+type PlayOrigin struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FeatureIdentifier  *string `protobuf:"bytes,1,opt,name=feature_identifier,json=featureIdentifier" json:"feature_identifier,omitempty"`
+	FeatureVersion     *string `protobuf:"bytes,2,opt,name=feature_version,json=featureVersion" json:"feature_version,omitempty"`
+	ViewUri            *string `protobuf:"bytes,3,opt,name=view_uri,json=viewUri" json:"view_uri,omitempty"`
+	ExternalReferrer   *string `protobuf:"bytes,4,opt,name=external_referrer,json=externalReferrer" json:"external_referrer,omitempty"`
+	ReferrerIdentifier *string `protobuf:"bytes,5,opt,name=referrer_identifier,json=referrerIdentifier" json:"referrer_identifier,omitempty"`
+	DeviceIdentifier   *string `protobuf:"bytes,6,opt,name=device_identifier,json=deviceIdentifier" json:"device_identifier,omitempty"`
+}
+
+func (x *PlayOrigin) Reset() {
+	*x = PlayOrigin{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayOrigin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayOrigin) ProtoMessage() {}
+
+func (x *PlayOrigin) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayOrigin.ProtoReflect.Descriptor instead.
+func (*PlayOrigin) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PlayOrigin) GetFeatureIdentifier() string {
+	if x != nil && x.FeatureIdentifier != nil {
+		return *x.FeatureIdentifier
+	}
+	return ""
+}
+
+func (x *PlayOrigin) GetFeatureVersion() string {
+	if x != nil && x.FeatureVersion != nil {
+		return *x.FeatureVersion
+	}
+	return ""
+}
+
+func (x *PlayOrigin) GetViewUri() string {
+	if x != nil && x.ViewUri != nil {
+		return *x.ViewUri
+	}
+	return ""
+}
+
+func (x *PlayOrigin) GetExternalReferrer() string {
+	if x != nil && x.ExternalReferrer != nil {
+		return *x.ExternalReferrer
+	}
+	return ""
+}
+
+func (x *PlayOrigin) GetReferrerIdentifier() string {
+	if x != nil && x.ReferrerIdentifier != nil {
+		return *x.ReferrerIdentifier
+	}
+	return ""
+}
+
+func (x *PlayOrigin) GetDeviceIdentifier() string {
+	if x != nil && x.DeviceIdentifier != nil {
+		return *x.DeviceIdentifier
+	}
+	return ""
+}
+
+type Context struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uri      *string           `protobuf:"bytes,1,opt,name=uri" json:"uri,omitempty"`
+	Url      *string           `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,3,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Pages    []*ContextPage    `protobuf:"bytes,5,rep,name=pages" json:"pages,omitempty"`
+	Loading  *bool             `protobuf:"varint,6,opt,name=loading" json:"loading,omitempty"`
+}
+
+func (x *Context) Reset() {
+	*x = Context{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Context) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Context) ProtoMessage() {}
+
+func (x *Context) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Context.ProtoReflect.Descriptor instead.
+func (*Context) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Context) GetUri() string {
+	if x != nil && x.Uri != nil {
+		return *x.Uri
+	}
+	return ""
+}
+
+func (x *Context) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *Context) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Context) GetPages() []*ContextPage {
+	if x != nil {
+		return x.Pages
+	}
+	return nil
+}
+
+func (x *Context) GetLoading() bool {
+	if x != nil && x.Loading != nil {
+		return *x.Loading
+	}
+	return false
+}
+
+type ContextPage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PageUrl     *string           `protobuf:"bytes,1,opt,name=page_url,json=pageUrl" json:"page_url,omitempty"`
+	NextPageUrl *string           `protobuf:"bytes,2,opt,name=next_page_url,json=nextPageUrl" json:"next_page_url,omitempty"`
+	Metadata    map[string]string `protobuf:"bytes,3,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tracks      []*ContextTrack   `protobuf:"bytes,4,rep,name=tracks" json:"tracks,omitempty"`
+	Loading     *bool             `protobuf:"varint,5,opt,name=loading" json:"loading,omitempty"`
+}
+
+func (x *ContextPage) Reset() {
+	*x = ContextPage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContextPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextPage) ProtoMessage() {}
+
+func (x *ContextPage) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextPage.ProtoReflect.Descriptor instead.
+func (*ContextPage) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ContextPage) GetPageUrl() string {
+	if x != nil && x.PageUrl != nil {
+		return *x.PageUrl
+	}
+	return ""
+}
+
+func (x *ContextPage) GetNextPageUrl() string {
+	if x != nil && x.NextPageUrl != nil {
+		return *x.NextPageUrl
+	}
+	return ""
+}
+
+func (x *ContextPage) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ContextPage) GetTracks() []*ContextTrack {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *ContextPage) GetLoading() bool {
+	if x != nil && x.Loading != nil {
+		return *x.Loading
+	}
+	return false
+}
+
+type ContextTrack struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uri      *string           `protobuf:"bytes,1,opt,name=uri" json:"uri,omitempty"`
+	Uid      *string           `protobuf:"bytes,2,opt,name=uid" json:"uid,omitempty"`
+	Gid      []byte            `protobuf:"bytes,3,opt,name=gid" json:"gid,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,4,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (x *ContextTrack) Reset() {
+	*x = ContextTrack{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_Spotify_transfer_state_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContextTrack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextTrack) ProtoMessage() {}
+
+func (x *ContextTrack) ProtoReflect() protoreflect.Message {
+	mi := &file_Spotify_transfer_state_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextTrack.ProtoReflect.Descriptor instead.
+func (*ContextTrack) Descriptor() ([]byte, []int) {
+	return file_Spotify_transfer_state_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ContextTrack) GetUri() string {
+	if x != nil && x.Uri != nil {
+		return *x.Uri
+	}
+	return ""
+}
+
+func (x *ContextTrack) GetUid() string {
+	if x != nil && x.Uid != nil {
+		return *x.Uid
+	}
+	return ""
+}
+
+func (x *ContextTrack) GetGid() []byte {
+	if x != nil {
+		return x.Gid
+	}
+	return nil
+}
+
+func (x *ContextTrack) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+var File_Spotify_transfer_state_proto protoreflect.FileDescriptor
+
+var file_Spotify_transfer_state_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x2d, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07,
+	0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x22, 0x87, 0x02, 0x0a, 0x0d, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x37, 0x0a, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x53, 0x70, 0x6f,
+	0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x50, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x2d, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x50,
+	0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x12, 0x39, 0x0a, 0x0f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x53, 0x70, 0x6f,
+	0x74, 0x69, 0x66, 0x79, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x05,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x70,
+	0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x05, 0x71, 0x75, 0x65,
+	0x75, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x22, 0x99, 0x01, 0x0a, 0x14, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x50, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x68,
+	0x75, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x67,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x70, 0x65, 0x61,
+	0x74, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x70, 0x65, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e,
+	0x74, 0x65, 0x78, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x70, 0x65, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x5f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x72,
+	0x65, 0x70, 0x65, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x22, 0xe1, 0x01,
+	0x0a, 0x08, 0x50, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x37, 0x0a, 0x18, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x61, 0x73, 0x5f, 0x6f, 0x66, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x15, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x41, 0x73, 0x4f, 0x66, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x73, 0x70,
+	0x65, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x70, 0x6c, 0x61, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x53, 0x70, 0x65, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x70,
+	0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x50,
+	0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x3a, 0x0a, 0x0d, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x5f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x53,
+	0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54, 0x72,
+	0x61, 0x63, 0x6b, 0x52, 0x0c, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x54, 0x72, 0x61, 0x63,
+	0x6b, 0x22, 0x8c, 0x01, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x34, 0x0a,
+	0x0b, 0x70, 0x6c, 0x61, 0x79, 0x5f, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x50, 0x6c, 0x61,
+	0x79, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x52, 0x0a, 0x70, 0x6c, 0x61, 0x79, 0x4f, 0x72, 0x69,
+	0x67, 0x69, 0x6e, 0x12, 0x2a, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12,
+	0x1f, 0x0a, 0x0b, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x55, 0x69, 0x64,
+	0x22, 0x60, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x75, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x74, 0x72, 0x61,
+	0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x53, 0x70, 0x6f, 0x74,
+	0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54, 0x72, 0x61, 0x63, 0x6b,
+	0x52, 0x06, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x69, 0x73, 0x5f, 0x70,
+	0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0e, 0x69, 0x73, 0x50, 0x6c, 0x61, 0x79, 0x69, 0x6e, 0x67, 0x51, 0x75, 0x65,
+	0x75, 0x65, 0x22, 0x8a, 0x02, 0x0a, 0x0a, 0x50, 0x6c, 0x61, 0x79, 0x4f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x12, 0x2d, 0x0a, 0x12, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x69, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x66,
+	0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72,
+	0x12, 0x27, 0x0a, 0x0f, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x66, 0x65, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x76, 0x69, 0x65,
+	0x77, 0x5f, 0x75, 0x72, 0x69, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x69, 0x65,
+	0x77, 0x55, 0x72, 0x69, 0x12, 0x2b, 0x0a, 0x11, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
+	0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65,
+	0x72, 0x12, 0x2f, 0x0a, 0x13, 0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x5f, 0x69, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12,
+	0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22,
+	0xec, 0x01, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75,
+	0x72, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x10, 0x0a,
+	0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12,
+	0x3a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2a, 0x0a, 0x05, 0x70,
+	0x61, 0x67, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x53, 0x70, 0x6f,
+	0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65,
+	0x52, 0x05, 0x70, 0x61, 0x67, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x6f, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x92,
+	0x02, 0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x12, 0x19,
+	0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x70, 0x61, 0x67, 0x65, 0x55, 0x72, 0x6c, 0x12, 0x22, 0x0a, 0x0d, 0x6e, 0x65, 0x78,
+	0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x55, 0x72, 0x6c, 0x12, 0x3e, 0x0a,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x22, 0x2e, 0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x50, 0x61, 0x67, 0x65, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2d, 0x0a,
+	0x06, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x53, 0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54,
+	0x72, 0x61, 0x63, 0x6b, 0x52, 0x06, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x6c, 0x6f, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c,
+	0x6f, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xc2, 0x01, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54,
+	0x72, 0x61, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x67, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x67, 0x69, 0x64, 0x12, 0x3f, 0x0a, 0x08, 0x6d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x53,
+	0x70, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54, 0x72,
+	0x61, 0x63, 0x6b, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32,
+}
+
+var (
+	file_Spotify_transfer_state_proto_rawDescOnce sync.Once
+	file_Spotify_transfer_state_proto_rawDescData = file_Spotify_transfer_state_proto_rawDesc
+)
+
+func file_Spotify_transfer_state_proto_rawDescGZIP() []byte {
+	file_Spotify_transfer_state_proto_rawDescOnce.Do(func() {
+		file_Spotify_transfer_state_proto_rawDescData = protoimpl.X.CompressGZIP(file_Spotify_transfer_state_proto_rawDescData)
+	})
+	return file_Spotify_transfer_state_proto_rawDescData
+}
+
+var file_Spotify_transfer_state_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_Spotify_transfer_state_proto_goTypes = []interface{}{
+	(*TransferState)(nil),        // 0: Spotify.TransferState
+	(*ContextPlayerOptions)(nil), // 1: Spotify.ContextPlayerOptions
+	(*Playback)(nil),             // 2: Spotify.Playback
+	(*Session)(nil),              // 3: Spotify.Session
+	(*Queue)(nil),                // 4: Spotify.Queue
+	(*PlayOrigin)(nil),           // 5: Spotify.PlayOrigin
+	(*Context)(nil),              // 6: Spotify.Context
+	(*ContextPage)(nil),          // 7: Spotify.ContextPage
+	(*ContextTrack)(nil),         // 8: Spotify.ContextTrack
+	nil,                          // 9: Spotify.Context.MetadataEntry
+	nil,                          // 10: Spotify.ContextPage.MetadataEntry
+	nil,                          // 11: Spotify.ContextTrack.MetadataEntry
+}
+var file_Spotify_transfer_state_proto_depIdxs = []int32{
+	1,  // 0: Spotify.TransferState.options:type_name -> Spotify.ContextPlayerOptions
+	2,  // 1: Spotify.TransferState.playback:type_name -> Spotify.Playback
+	3,  // 2: Spotify.TransferState.current_session:type_name -> Spotify.Session
+	4,  // 3: Spotify.TransferState.queue:type_name -> Spotify.Queue
+	8,  // 4: Spotify.Playback.current_track:type_name -> Spotify.ContextTrack
+	5,  // 5: Spotify.Session.play_origin:type_name -> Spotify.PlayOrigin
+	6,  // 6: Spotify.Session.context:type_name -> Spotify.Context
+	8,  // 7: Spotify.Queue.tracks:type_name -> Spotify.ContextTrack
+	9,  // 8: Spotify.Context.metadata:type_name -> Spotify.Context.MetadataEntry
+	7,  // 9: Spotify.Context.pages:type_name -> Spotify.ContextPage
+	10, // 10: Spotify.ContextPage.metadata:type_name -> Spotify.ContextPage.MetadataEntry
+	8,  // 11: Spotify.ContextPage.tracks:type_name -> Spotify.ContextTrack
+	11, // 12: Spotify.ContextTrack.metadata:type_name -> Spotify.ContextTrack.MetadataEntry
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_Spotify_transfer_state_proto_init() }
+func file_Spotify_transfer_state_proto_init() {
+	if File_Spotify_transfer_state_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_Spotify_transfer_state_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContextPlayerOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Playback); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Session); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Queue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayOrigin); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Context); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContextPage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_Spotify_transfer_state_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContextTrack); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_Spotify_transfer_state_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_Spotify_transfer_state_proto_goTypes,
+		DependencyIndexes: file_Spotify_transfer_state_proto_depIdxs,
+		MessageInfos:      file_Spotify_transfer_state_proto_msgTypes,
+	}.Build()
+	File_Spotify_transfer_state_proto = out.File
+	file_Spotify_transfer_state_proto_rawDesc = nil
+	file_Spotify_transfer_state_proto_goTypes = nil
+	file_Spotify_transfer_state_proto_depIdxs = nil
+}
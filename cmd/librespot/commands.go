@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fischerling/librespot-golang/librespot/core"
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+func printHelp() {
+	fmt.Println("\nAvailable commands:")
+	fmt.Println("play <track>:                   play specified track by spotify base62 id")
+	fmt.Println("track <track>:                  show details on specified track by spotify base62 id")
+	fmt.Println("album <album>:                  show details on specified album by spotify base62 id")
+	fmt.Println("artist <artist>:                show details on specified artist by spotify base62 id")
+	fmt.Println("search <keywords>:              start a search on the specified keywords")
+	fmt.Println("playlists:                      show your playlists")
+	fmt.Println("help:                           show this help")
+	fmt.Println("quit:                           exit")
+}
+
+func funcTrack(session *core.Session, trackID string) {
+	fmt.Println("Loading track: ", trackID)
+
+	id, err := ids.ParseKind(ids.KindTrack, trackID)
+	if err != nil {
+		fmt.Println("Invalid track:", err)
+		return
+	}
+	track, err := session.Mercury().GetTrack(id.Hex())
+	if err != nil {
+		fmt.Println("Error loading track: ", err)
+		return
+	}
+
+	fmt.Println("Track title: ", track.GetName())
+}
+
+func funcArtist(session *core.Session, artistID string) {
+	id, err := ids.ParseKind(ids.KindArtist, artistID)
+	if err != nil {
+		fmt.Println("Invalid artist:", err)
+		return
+	}
+	artist, err := session.Mercury().GetArtist(id.Hex())
+	if err != nil {
+		fmt.Println("Error loading artist:", err)
+		return
+	}
+
+	fmt.Printf("Artist: %s\n", artist.GetName())
+	fmt.Printf("Popularity: %d\n", artist.GetPopularity())
+	fmt.Printf("Genre: %s\n", artist.GetGenre())
+
+	if artist.GetTopTrack() != nil && len(artist.GetTopTrack()) > 0 {
+		// Spotify returns top tracks in multiple countries. We take the first
+		// one as example, but we should use the country data returned by the
+		// Spotify server (session.Country())
+		tt := artist.GetTopTrack()[0]
+		fmt.Printf("\nTop tracks (country %s):\n", tt.GetCountry())
+
+		for _, t := range tt.GetTrack() {
+			// To save bandwidth, only track IDs are returned. If you want
+			// the track name, you need to fetch it.
+			fmt.Printf(" => %s\n", utils.ConvertTo62(t.GetGid()))
+		}
+	}
+
+	fmt.Printf("\nAlbums:\n")
+	for _, ag := range artist.GetAlbumGroup() {
+		for _, a := range ag.GetAlbum() {
+			fmt.Printf(" => %s\n", utils.ConvertTo62(a.GetGid()))
+		}
+	}
+
+}
+
+func funcAlbum(session *core.Session, albumID string) {
+	id, err := ids.ParseKind(ids.KindAlbum, albumID)
+	if err != nil {
+		fmt.Println("Invalid album:", err)
+		return
+	}
+	album, err := session.Mercury().GetAlbum(id.Hex())
+	if err != nil {
+		fmt.Println("Error loading album:", err)
+		return
+	}
+
+	fmt.Printf("Album: %s\n", album.GetName())
+	fmt.Printf("Popularity: %d\n", album.GetPopularity())
+	fmt.Printf("Genre: %s\n", album.GetGenre())
+	fmt.Printf("Date: %d-%d-%d\n", album.GetDate().GetYear(), album.GetDate().GetMonth(), album.GetDate().GetDay())
+	fmt.Printf("Label: %s\n", album.GetLabel())
+	fmt.Printf("Type: %s\n", album.GetTyp())
+
+	fmt.Printf("Artists: ")
+	for _, artist := range album.GetArtist() {
+		fmt.Printf("%s ", utils.ConvertTo62(artist.GetGid()))
+	}
+	fmt.Printf("\n")
+
+	for _, disc := range album.GetDisc() {
+		fmt.Printf("\nDisc %d (%s): \n", disc.GetNumber(), disc.GetName())
+
+		for _, track := range disc.GetTrack() {
+			fmt.Printf(" => %s\n", utils.ConvertTo62(track.GetGid()))
+		}
+	}
+
+}
+
+func funcPlaylists(session *core.Session) {
+	fmt.Println("Listing playlists")
+
+	playlist, err := session.Mercury().GetRootPlaylist(session.Username())
+
+	if err != nil || playlist.Contents == nil {
+		fmt.Println("Error getting root list: ", err)
+		return
+	}
+
+	items := playlist.Contents.Items
+	for i := 0; i < len(items); i++ {
+		id := strings.TrimPrefix(items[i].GetUri(), "spotify:")
+		id = strings.Replace(id, ":", "/", -1)
+		list, _ := session.Mercury().GetPlaylist(id)
+		fmt.Println(list.Attributes.GetName(), id)
+
+		if list.Contents != nil {
+			for j := 0; j < len(list.Contents.Items); j++ {
+				item := list.Contents.Items[j]
+				fmt.Println(" ==> ", *item.Uri)
+			}
+		}
+	}
+}
+
+func funcSearch(session *core.Session, keyword string) {
+	resp, err := session.Search(keyword, 12)
+
+	if err != nil {
+		fmt.Println("Failed to search:", err)
+		return
+	}
+
+	res := resp.Results
+
+	fmt.Println("Search results for ", keyword)
+	fmt.Println("=============================")
+
+	if res.Error != nil {
+		fmt.Println("Search result error:", res.Error)
+	}
+
+	fmt.Printf("Albums: %d (total %d)\n", len(res.Albums.Hits), res.Albums.Total)
+
+	for _, album := range res.Albums.Hits {
+		fmt.Printf(" => %s (%s)\n", album.Name, album.Uri)
+	}
+
+	fmt.Printf("\nArtists: %d (total %d)\n", len(res.Artists.Hits), res.Artists.Total)
+
+	for _, artist := range res.Artists.Hits {
+		fmt.Printf(" => %s (%s)\n", artist.Name, artist.Uri)
+	}
+
+	fmt.Printf("\nTracks: %d (total %d)\n", len(res.Tracks.Hits), res.Tracks.Total)
+
+	for _, track := range res.Tracks.Hits {
+		fmt.Printf(" => %s (%s)\n", track.Name, track.Uri)
+	}
+}
@@ -0,0 +1,175 @@
+// Command librespot is a command line Spotify client built on the library: it logs in, browses the metadata and the
+// playlists of the user, searches, and plays tracks to the local audio device.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fischerling/librespot-golang/librespot"
+	"github.com/fischerling/librespot-golang/librespot/core"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+const (
+	// The device name that is registered to Spotify servers
+	defaultDeviceName = "librespot"
+)
+
+func main() {
+	// Read flags from commandline
+	username := flag.String("username", "", "spotify username")
+	password := flag.String("password", "", "spotify password")
+	blob := flag.String("blob", "blob.bin", "spotify auth blob")
+	devicename := flag.String("devicename", defaultDeviceName, "name of device")
+	zeroconf := flag.Bool("zeroconf", false, "wait for a Spotify app to log in through Spotify Connect, "+
+		"or reuse the blob it saved")
+	backend := flag.String("backend", "pulseaudio", "audio backend: portaudio (built with the portaudio tag), "+
+		"pulseaudio, alsa, or pipe")
+	device := flag.String("device", "", "ALSA device, or file the pipe backend writes to")
+	normalize := flag.Bool("normalize", false, "normalize the volume of the tracks")
+	debugAddr := flag.String("debug", "", "serve pprof, the runtime metrics and the health of the session on this "+
+		"address, e.g. localhost:6060")
+	flag.Parse()
+
+	out, err := newOutput(*backend, *device)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Authenticate
+	var session *core.Session
+
+	if *username != "" && *password != "" {
+		// Authenticate using a regular login and password, and store it in the blob file.
+		session, err = librespot.Login(*username, *password, *devicename)
+		if err == nil {
+			if err := ioutil.WriteFile(*blob, session.ReusableAuthBlob(), 0600); err != nil {
+				fmt.Printf("Could not store authentication blob in %s: %s\n", *blob, err)
+			}
+		}
+	} else if *zeroconf {
+		// Authenticate through Spotify Connect, the blob being saved by the first login
+		if _, statErr := os.Stat(*blob); statErr == nil {
+			session, err = librespot.LoginDiscoveryBlobFile(*blob, *devicename)
+		} else {
+			fmt.Printf("Waiting for a Spotify app to connect to %q...\n", *devicename)
+			session, err = librespot.LoginDiscovery(*blob, *devicename)
+		}
+	} else if *blob != "" && *username != "" {
+		// Authenticate reusing an existing blob
+		blobBytes, err := ioutil.ReadFile(*blob)
+
+		if err != nil {
+			fmt.Printf("Unable to read auth blob from %s: %s\n", *blob, err)
+			os.Exit(1)
+			return
+		}
+
+		session, err = librespot.LoginSaved(*username, blobBytes, *devicename)
+	} else if os.Getenv("client_secret") != "" {
+		// Authenticate using OAuth, in the browser
+		session, err = librespot.LoginOAuth(*devicename, os.Getenv("client_id"), os.Getenv("client_secret"))
+	} else {
+		// No valid options, show the help
+		fmt.Println("need to supply a username and password, a blob file path, or to log in through zeroconf")
+		fmt.Println("./librespot --username SPOTIFY_USERNAME [--blob ./path/to/blob]")
+		fmt.Println("or")
+		fmt.Println("./librespot --username SPOTIFY_USERNAME --password SPOTIFY_PASSWORD [--blob ./path/to/blob]")
+		fmt.Println("or")
+		fmt.Println("./librespot --zeroconf [--blob ./path/to/blob]")
+		fmt.Println("or, with the client_id and client_secret environment variables set")
+		fmt.Println("./librespot")
+		return
+	}
+
+	if err != nil {
+		fmt.Println("Error logging in: ", err)
+		os.Exit(1)
+		return
+	}
+
+	if *debugAddr != "" {
+		addr, err := session.ServeDebug(*debugAddr)
+		if err != nil {
+			fmt.Println("Error starting the debug server: ", err)
+		} else {
+			fmt.Printf("Debug server listening on http://%s/debug/\n", addr)
+		}
+	}
+
+	if *normalize {
+		session.Player().SetNormalization(&player.NormalizationConfig{Limiter: true})
+	}
+
+	// Command loop
+	reader := bufio.NewReader(os.Stdin)
+
+	printHelp()
+
+	for {
+		fmt.Print("> ")
+		text, err := reader.ReadString('\n')
+		if err != nil && text == "" {
+			return
+		}
+		cmds := strings.Fields(text)
+		if len(cmds) == 0 {
+			continue
+		}
+
+		switch cmds[0] {
+		case "help":
+			printHelp()
+
+		case "track":
+			if len(cmds) < 2 {
+				fmt.Println("You must specify the Base62 Spotify ID of the track")
+			} else {
+				funcTrack(session, cmds[1])
+			}
+
+		case "artist":
+			if len(cmds) < 2 {
+				fmt.Println("You must specify the Base62 Spotify ID of the artist")
+			} else {
+				funcArtist(session, cmds[1])
+			}
+
+		case "album":
+			if len(cmds) < 2 {
+				fmt.Println("You must specify the Base62 Spotify ID of the album")
+			} else {
+				funcAlbum(session, cmds[1])
+			}
+
+		case "playlists":
+			funcPlaylists(session)
+
+		case "search":
+			if len(cmds) < 2 {
+				fmt.Println("You must specify the keywords to search")
+			} else {
+				funcSearch(session, strings.Join(cmds[1:], " "))
+			}
+
+		case "play":
+			if len(cmds) < 2 {
+				fmt.Println("You must specify the Base62 Spotify ID of the track")
+			} else {
+				funcPlay(session, out, cmds[1])
+			}
+
+		case "quit":
+			return
+
+		default:
+			fmt.Println("Unknown command")
+		}
+	}
+}
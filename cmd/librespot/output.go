@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/sink"
+	"github.com/fischerling/librespot-golang/librespot/vorbis"
+)
+
+// output opens the sinks of an audio backend
+type output struct {
+	backend string
+	// device is the ALSA device, or the file the pipe backend writes to, e.g. the FIFO of snapcast
+	device string
+}
+
+// newOutput returns the output of an audio backend
+func newOutput(backend string, device string) (output, error) {
+	switch backend {
+	case "portaudio", "pulseaudio", "alsa":
+		return output{backend: backend, device: device}, nil
+	case "pipe":
+		if device == "" {
+			return output{}, fmt.Errorf("the pipe backend needs the file to write to as device")
+		}
+		return output{backend: backend, device: device}, nil
+	default:
+		return output{}, fmt.Errorf("unknown audio backend %q", backend)
+	}
+}
+
+// open returns a sink playing PCM of the format
+func (o output) open(format sink.Format) (sink.AudioSink, error) {
+	switch o.backend {
+	case "portaudio":
+		return sink.NewPortAudioSink(format)
+	case "pulseaudio":
+		return sink.NewPulseAudioSink(format)
+	case "alsa":
+		return sink.NewALSASink(format, o.device)
+	default:
+		f, err := os.OpenFile(o.device, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return sink.NewWriterSink(f), nil
+	}
+}
+
+// play writes the frames decoded from a track to a sink of the output, until the end of the track
+func (o output) play(dec vorbis.Decoder, normalizer *player.Normalizer) error {
+	info := dec.Info()
+	s, err := o.open(sink.Format{SampleRate: info.SampleRate, Channels: info.Channels})
+	if err != nil {
+		return err
+	}
+
+	var pcm []byte
+	for {
+		var frame [][]float32
+		frame, err = dec.ReadFrame()
+		if err != nil {
+			break
+		}
+		if normalizer != nil {
+			normalizer.ProcessFrames(frame)
+		}
+		pcm = appendPCM(pcm[:0], frame, info.Channels)
+		if _, err = s.Write(pcm); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if closeErr := s.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// appendPCM appends the frames as signed 16-bit little-endian PCM
+func appendPCM(pcm []byte, frame [][]float32, channels int) []byte {
+	var buf [2]byte
+	for _, sample := range frame {
+		for c := 0; c < channels; c++ {
+			var v float32
+			if c < len(sample) {
+				v = sample[c]
+			}
+			binary.LittleEndian.PutUint16(buf[:], uint16(int16(math.Max(-1, math.Min(1, float64(v)))*32767)))
+			pcm = append(pcm, buf[:]...)
+		}
+	}
+	return pcm
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/librespot/core"
+	"github.com/fischerling/librespot-golang/librespot/vorbis"
+)
+
+func funcPlay(session *core.Session, out output, trackID string) {
+	fmt.Println("Loading track for play: ", trackID)
+
+	// Synchronously load the track: its metadata holds information about which files and encodings are available,
+	// and the OGG variant with the closest bitrate to the 160kbps ("high" quality in the Spotify apps) is selected,
+	// as it is the only format the decoder supports
+	playback, err := session.PlayURI(context.Background(), trackID)
+	if err != nil {
+		fmt.Printf("Error while loading track: %s\n", err)
+		return
+	}
+	fmt.Println("Track:", playback.Track.Name)
+	fmt.Printf("Format: %s (%s)\n", playback.Format.Selected, playback.Format.Reason)
+
+	// We have the track audio, let's play it! Initialize the Ogg Vorbis decoder, and stream its frames to the audio
+	// device. The Spotify-specific header of the file, holding the normalization data, is already skipped by the
+	// audio file.
+	fmt.Println("Setting up Vorbis decoder...")
+	audioFile := playback.Audio
+	dec, err := vorbis.NewDecoder(audioFile)
+	if err != nil {
+		fmt.Printf("Error while decoding track: %s\n", err)
+		return
+	}
+	defer dec.Close()
+
+	info := dec.Info()
+	normalizer := audioFile.Normalizer()
+	if normalizer != nil {
+		fmt.Printf("Normalization gain: %.2f dB\n", normalizer.GainDb())
+	}
+
+	fmt.Printf("Channels: %d / SampleRate: %d\n", info.Channels, info.SampleRate)
+	fmt.Println("Starting playback...")
+	if err := out.play(dec, normalizer); err != nil {
+		fmt.Printf("Error while playing track: %s\n", err)
+	}
+}
@@ -10,7 +10,8 @@ require (
 	github.com/xlab/portaudio-go v0.0.0-20170905165025-132d041879db
 	github.com/xlab/vorbis-go v0.0.0-20190125051917-087364aef51d
 	golang.org/x/crypto v0.0.0-20190418165655-df01cb2cc480
-	golang.org/x/net v0.0.0-20190420063019-afa5a82059c6 // indirect
+	golang.org/x/net v0.0.0-20190420063019-afa5a82059c6
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
+	golang.org/x/sys v0.0.0-20190403152447-81d4e9dc473e
 	google.golang.org/protobuf v1.27.1
 )
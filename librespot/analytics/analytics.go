@@ -0,0 +1,267 @@
+// Package analytics aggregates the playback events of a player into per-track listening records, kept locally and
+// exported as JSON, for self-hosted listening statistics tools.
+package analytics
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// DefaultMaxRecords is the number of records kept by default, the oldest ones are dropped first
+const DefaultMaxRecords = 10000
+
+// Record is the playback of a track, from its start to its end, or until another track started
+type Record struct {
+	TrackId    string    `json:"track_id,omitempty"`
+	FileId     string    `json:"file_id"`
+	ContextUri string    `json:"context_uri,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	// MsPlayed is the time between the start and the end of the playback, buffering excluded
+	MsPlayed int64 `json:"ms_played"`
+	// Skipped is set when another track started before the end of this one
+	Skipped     bool   `json:"skipped"`
+	BufferingMs int64  `json:"buffering_ms"`
+	Error       string `json:"error,omitempty"`
+}
+
+// TrackStats sums up the records of a track
+type TrackStats struct {
+	TrackId     string    `json:"track_id"`
+	Plays       int       `json:"plays"`
+	Skips       int       `json:"skips"`
+	MsPlayed    int64     `json:"ms_played"`
+	BufferingMs int64     `json:"buffering_ms"`
+	LastPlayed  time.Time `json:"last_played"`
+}
+
+// Export is the JSON document written by Recorder.Export and served by Recorder.ServeHTTP
+type Export struct {
+	Records []Record     `json:"records"`
+	Tracks  []TrackStats `json:"tracks"`
+}
+
+// RecordCallback is called with the records once completed
+type RecordCallback func(record Record)
+
+// playback is a record in progress
+type playback struct {
+	record         Record
+	bufferingSince time.Time
+}
+
+// Recorder builds the records from the player events. It implements http.Handler, serving the Export document.
+type Recorder struct {
+	lock       sync.Mutex
+	maxRecords int
+	current    map[string]*playback
+	records    []Record
+	callbacks  []RecordCallback
+}
+
+// NewRecorder creates a recorder keeping up to maxRecords records, or DefaultMaxRecords if maxRecords is 0
+func NewRecorder(maxRecords int) *Recorder {
+	if maxRecords <= 0 {
+		maxRecords = DefaultMaxRecords
+	}
+	return &Recorder{
+		maxRecords: maxRecords,
+		current:    map[string]*playback{},
+	}
+}
+
+// Listener returns a player event listener feeding the recorder, to be registered with Player.OnEvent
+func (r *Recorder) Listener() player.EventListener {
+	return r.Handle
+}
+
+// Handle updates the records with a playback event
+func (r *Recorder) Handle(event player.Event) {
+	finished := r.update(event)
+	if len(finished) == 0 {
+		return
+	}
+
+	r.lock.Lock()
+	callbacks := append([]RecordCallback{}, r.callbacks...)
+	r.lock.Unlock()
+	for _, record := range finished {
+		for _, cb := range callbacks {
+			cb(record)
+		}
+	}
+}
+
+// OnRecord registers a callback notified of every completed record, e.g. to report the plays to a listening history
+func (r *Recorder) OnRecord(cb RecordCallback) {
+	r.lock.Lock()
+	r.callbacks = append(r.callbacks, cb)
+	r.lock.Unlock()
+}
+
+// update updates the records with a playback event, and returns the records it completed
+func (r *Recorder) update(event player.Event) []Record {
+	fileId := hex.EncodeToString(event.FileId)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var finished []Record
+	current := r.current[fileId]
+	switch event.Type {
+	case player.EventTrackStart:
+		// Only one track plays at a time: the ones still playing were skipped
+		for id, p := range r.current {
+			p.record.Skipped = true
+			finished = append(finished, r.finish(id, event.Time))
+		}
+
+		record := Record{
+			FileId:     fileId,
+			ContextUri: event.Origin.ContextUri,
+			Start:      event.Time,
+		}
+		if event.TrackId != nil {
+			record.TrackId = utils.ConvertTo62(event.TrackId)
+		}
+		r.current[fileId] = &playback{record: record}
+
+	case player.EventBufferingStart:
+		if current != nil && current.bufferingSince.IsZero() {
+			current.bufferingSince = event.Time
+		}
+
+	case player.EventBufferingEnd:
+		if current != nil && !current.bufferingSince.IsZero() {
+			current.record.BufferingMs += event.Time.Sub(current.bufferingSince).Milliseconds()
+			current.bufferingSince = time.Time{}
+		}
+
+	case player.EventTrackEnd:
+		if current != nil {
+			finished = append(finished, r.finish(fileId, event.Time))
+		}
+
+	case player.EventError:
+		if current != nil {
+			if event.Err != nil {
+				current.record.Error = event.Err.Error()
+			}
+			finished = append(finished, r.finish(fileId, event.Time))
+		}
+	}
+	return finished
+}
+
+// finish completes the record of a playback in progress, and returns it. It must be called with the lock held.
+func (r *Recorder) finish(fileId string, end time.Time) Record {
+	p := r.current[fileId]
+	delete(r.current, fileId)
+
+	if !p.bufferingSince.IsZero() {
+		p.record.BufferingMs += end.Sub(p.bufferingSince).Milliseconds()
+	}
+	p.record.End = end
+	p.record.MsPlayed = end.Sub(p.record.Start).Milliseconds() - p.record.BufferingMs
+	if p.record.MsPlayed < 0 {
+		p.record.MsPlayed = 0
+	}
+
+	r.records = append(r.records, p.record)
+	if len(r.records) > r.maxRecords {
+		r.records = append([]Record{}, r.records[len(r.records)-r.maxRecords:]...)
+	}
+	return p.record
+}
+
+// Records returns the completed records, oldest first
+func (r *Recorder) Records() []Record {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]Record{}, r.records...)
+}
+
+// Tracks sums up the completed records by track, the most played first
+func (r *Recorder) Tracks() []TrackStats {
+	byTrack := map[string]*TrackStats{}
+	for _, record := range r.Records() {
+		id := record.TrackId
+		if id == "" {
+			id = record.FileId
+		}
+
+		stats, ok := byTrack[id]
+		if !ok {
+			stats = &TrackStats{TrackId: id}
+			byTrack[id] = stats
+		}
+		stats.Plays++
+		if record.Skipped {
+			stats.Skips++
+		}
+		stats.MsPlayed += record.MsPlayed
+		stats.BufferingMs += record.BufferingMs
+		if record.End.After(stats.LastPlayed) {
+			stats.LastPlayed = record.End
+		}
+	}
+
+	tracks := make([]TrackStats, 0, len(byTrack))
+	for _, stats := range byTrack {
+		tracks = append(tracks, *stats)
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].MsPlayed != tracks[j].MsPlayed {
+			return tracks[i].MsPlayed > tracks[j].MsPlayed
+		}
+		return tracks[i].TrackId < tracks[j].TrackId
+	})
+	return tracks
+}
+
+// Export writes the records and the track statistics as a JSON document
+func (r *Recorder) Export(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Export{
+		Records: r.Records(),
+		Tracks:  r.Tracks(),
+	})
+}
+
+// ExportFile writes the Export document to path. The file is replaced atomically, so that a reader never sees a
+// partial export.
+func (r *Recorder) ExportFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.Export(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ServeHTTP serves the Export document
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	r.Export(w)
+}
@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+func TestRecords(t *testing.T) {
+	r := NewRecorder(0)
+	start := time.Unix(1000, 0)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+	first, second := []byte{0x1}, []byte{0x2}
+	var notified []Record
+	r.OnRecord(func(record Record) {
+		notified = append(notified, record)
+	})
+
+	r.Handle(player.Event{Type: player.EventTrackStart, Time: at(0), TrackId: first, FileId: first})
+	r.Handle(player.Event{Type: player.EventBufferingStart, Time: at(10), FileId: first})
+	r.Handle(player.Event{Type: player.EventBufferingEnd, Time: at(12), FileId: first})
+	r.Handle(player.Event{Type: player.EventTrackEnd, Time: at(180), FileId: first})
+
+	// The second track is skipped when the first one plays again
+	r.Handle(player.Event{Type: player.EventTrackStart, Time: at(200), TrackId: second, FileId: second})
+	r.Handle(player.Event{Type: player.EventTrackStart, Time: at(230), TrackId: first, FileId: first})
+
+	records := r.Records()
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %v", records)
+	}
+	if records[0].MsPlayed != 178000 || records[0].BufferingMs != 2000 || records[0].Skipped {
+		t.Errorf("bad first record %+v", records[0])
+	}
+	if records[1].MsPlayed != 30000 || !records[1].Skipped {
+		t.Errorf("bad skipped record %+v", records[1])
+	}
+	if len(notified) != 2 || notified[1] != records[1] {
+		t.Errorf("bad notified records %+v", notified)
+	}
+
+	r.Handle(player.Event{Type: player.EventTrackEnd, Time: at(400), FileId: first})
+	tracks := r.Tracks()
+	if len(tracks) != 2 || tracks[0].TrackId != records[0].TrackId || tracks[0].Plays != 2 || tracks[0].MsPlayed != 348000 {
+		t.Errorf("bad track stats %+v", tracks)
+	}
+	if tracks[1].Skips != 1 {
+		t.Errorf("bad skips %+v", tracks[1])
+	}
+
+	var buf bytes.Buffer
+	if err := r.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var export Export
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Records) != 3 || len(export.Tracks) != 2 {
+		t.Errorf("bad export %s", buf.String())
+	}
+}
@@ -0,0 +1,152 @@
+package aptest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/golang/protobuf/proto"
+)
+
+// MercuryHandler answers a mercury request of the user, e.g. with a mercury.Response whose StatusCode is 200 and
+// whose Payload is the marshaled metadata
+type MercuryHandler func(username string, req mercury.Request) mercury.Response
+
+type mercuryHandler struct {
+	prefix  string
+	handler MercuryHandler
+}
+
+// HandleMercury registers a handler for the mercury requests whose URI starts with prefix, including the
+// subscriptions. The requests not handled by any handler get a 404 response, except the subscriptions which are
+// accepted.
+func (s *Server) HandleMercury(prefix string, handler MercuryHandler) {
+	s.lock.Lock()
+	s.handlers = append(s.handlers, mercuryHandler{prefix, handler})
+	s.lock.Unlock()
+}
+
+// Publish sends a mercury event to the sessions logged in, e.g. to the ones subscribed to the URI
+func (s *Server) Publish(uri string, payload ...[]byte) error {
+	packet, err := encodeMercury(nil, &Spotify.Header{Uri: proto.String(uri)}, payload)
+	if err != nil {
+		return err
+	}
+	return s.Send(connection.PacketMercuryEvent, packet)
+}
+
+// handler returns the handler of the URI, nil if none
+func (s *Server) handler(uri string) MercuryHandler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, h := range s.handlers {
+		if strings.HasPrefix(uri, h.prefix) {
+			return h.handler
+		}
+	}
+	return nil
+}
+
+// handleMercury answers a mercury request, with the same packet type and sequence
+func (s *Server) handleMercury(c *conn, cmd connection.PacketType, data []byte) error {
+	seq, header, payload, err := decodeMercury(data)
+	if err != nil {
+		return err
+	}
+
+	response := mercury.Response{StatusCode: 404}
+	if cmd == connection.PacketMercurySub || cmd == connection.PacketMercuryUnsub {
+		response.StatusCode = 200
+	}
+	if handler := s.handler(header.GetUri()); handler != nil {
+		s.lock.Lock()
+		username := c.username
+		s.lock.Unlock()
+		response = handler(username, mercury.Request{
+			Method:      header.GetMethod(),
+			Uri:         header.GetUri(),
+			ContentType: header.GetContentType(),
+			Payload:     payload,
+		})
+	}
+
+	uri := response.Uri
+	if uri == "" {
+		uri = header.GetUri()
+	}
+	packet, err := encodeMercury(seq, &Spotify.Header{
+		Uri:        proto.String(uri),
+		StatusCode: proto.Int32(response.StatusCode),
+	}, response.Payload)
+	if err != nil {
+		return err
+	}
+	return c.stream.SendPacket(cmd, packet)
+}
+
+// decodeMercury returns the sequence, the header and the payload of a mercury packet, which must be in a single part
+func decodeMercury(data []byte) (seq []byte, header *Spotify.Header, payload [][]byte, err error) {
+	reader := bytes.NewReader(data)
+	var seqLength, count uint16
+	var flags uint8
+	if err = binary.Read(reader, binary.BigEndian, &seqLength); err != nil {
+		return
+	}
+	seq = make([]byte, seqLength)
+	if _, err = io.ReadFull(reader, seq); err != nil {
+		return
+	}
+	if err = binary.Read(reader, binary.BigEndian, &flags); err != nil {
+		return
+	}
+	if err = binary.Read(reader, binary.BigEndian, &count); err != nil {
+		return
+	}
+	if count == 0 {
+		err = fmt.Errorf("mercury packet without header")
+		return
+	}
+
+	parts := make([][]byte, count)
+	for i := range parts {
+		var size uint16
+		if err = binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return
+		}
+		parts[i] = make([]byte, size)
+		if _, err = io.ReadFull(reader, parts[i]); err != nil {
+			return
+		}
+	}
+
+	header = &Spotify.Header{}
+	if err = proto.Unmarshal(parts[0], header); err != nil {
+		err = fmt.Errorf("bad mercury header: %v", err)
+		return
+	}
+	return seq, header, parts[1:], nil
+}
+
+// encodeMercury returns a mercury packet of the header and the payload, in a single part
+func encodeMercury(seq []byte, header *Spotify.Header, payload [][]byte) ([]byte, error) {
+	headerData, err := proto.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint16(len(seq)))
+	buf.Write(seq)
+	buf.WriteByte(1)
+	binary.Write(buf, binary.BigEndian, uint16(1+len(payload)))
+	for _, part := range append([][]byte{headerData}, payload...) {
+		binary.Write(buf, binary.BigEndian, uint16(len(part)))
+		buf.Write(part)
+	}
+	return buf.Bytes(), nil
+}
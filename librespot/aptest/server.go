@@ -0,0 +1,317 @@
+// Package aptest provides a fake access point, to test the handshake, login and mercury logic of the sessions without
+// connecting to Spotify. The sessions reach it through its in-memory transport:
+//
+//	server, err := aptest.NewServer()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer server.Close()
+//	server.Users["user"] = "password"
+//	session, err := core.NewSession(core.SessionConfig{
+//		ApAddress:   aptest.Address,
+//		Transport:   server.Transport(),
+//		ApServerKey: server.PublicKey(),
+//	})
+//
+// It implements the server side of the Diffie-Hellman handshake and of the Shannon encryption. Its keys are signed
+// with a key of its own instead of the one of Spotify, which the sessions must be configured with.
+package aptest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/crypto"
+	"github.com/golang/protobuf/proto"
+)
+
+// Address is an address of access point to configure the sessions with, any address reaching the server
+const Address = "ap.test:4070"
+
+// storedPrefix prefixes the username in the reusable credentials sent to the users who logged in
+const storedPrefix = "stored:"
+
+// Server is a fake access point accepting the connections of its transport. Its fields must be set before the
+// sessions connect.
+type Server struct {
+	// Users are the passwords of the users, who can log in with them, or with the reusable credentials received once
+	// logged in
+	Users map[string]string
+	// Tokens are the users of the access tokens accepted to log in
+	Tokens map[string]string
+	// Country is the country code sent to the users once logged in, "US" if empty
+	Country string
+
+	transport *connection.MemoryTransport
+	done      chan struct{}
+	key       *rsa.PrivateKey
+
+	lock     sync.Mutex
+	conns    map[*conn]bool
+	handlers []mercuryHandler
+}
+
+// conn is a connection of a session, once the handshake is done
+type conn struct {
+	net    net.Conn
+	stream connection.PacketStream
+	// username is the user logged in, empty until then
+	username string
+}
+
+// NewServer creates a server, and starts accepting the connections of its transport
+func NewServer() (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the server key: %v", err)
+	}
+	s := &Server{
+		Users:     map[string]string{},
+		Tokens:    map[string]string{},
+		transport: connection.NewMemoryTransport(),
+		done:      make(chan struct{}),
+		conns:     map[*conn]bool{},
+		key:       key,
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Transport returns the transport the sessions reach the server through, see core.SessionConfig.Transport
+func (s *Server) Transport() *connection.MemoryTransport {
+	return s.transport
+}
+
+// PublicKey returns the key the server signs its Diffie-Hellman keys with, see core.SessionConfig.ApServerKey
+func (s *Server) PublicKey() *rsa.PublicKey {
+	return &s.key.PublicKey
+}
+
+// Close stops accepting connections, and closes the connections of the sessions
+func (s *Server) Close() error {
+	err := s.transport.Close()
+	<-s.done
+	s.Disconnect()
+	return err
+}
+
+// Disconnect closes the connections of the sessions, e.g. to test their reconnection
+func (s *Server) Disconnect() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for c := range s.conns {
+		c.net.Close()
+	}
+}
+
+// Send sends a packet to the sessions logged in, e.g. a ping
+func (s *Server) Send(cmd connection.PacketType, data []byte) error {
+	s.lock.Lock()
+	var conns []*conn
+	for c := range s.conns {
+		if c.username != "" {
+			conns = append(conns, c)
+		}
+	}
+	s.lock.Unlock()
+
+	if len(conns) == 0 {
+		return errors.New("no session logged in")
+	}
+	for _, c := range conns {
+		if err := c.stream.SendPacket(cmd, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) serve() {
+	defer close(s.done)
+	for {
+		c, err := s.transport.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+// handle performs the handshake with a session, and answers its packets until the connection is closed
+func (s *Server) handle(netConn net.Conn) {
+	defer netConn.Close()
+
+	stream, err := handshake(netConn, s.key)
+	if err != nil {
+		log.Printf("aptest: handshake failed: %v\n", err)
+		return
+	}
+	c := &conn{net: netConn, stream: stream}
+	s.lock.Lock()
+	s.conns[c] = true
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, c)
+		s.lock.Unlock()
+	}()
+
+	for {
+		cmd, data, err := stream.RecvPacket()
+		if err != nil {
+			return
+		}
+		switch {
+		case cmd == connection.PacketLogin:
+			err = s.login(c, data)
+		case cmd == connection.PacketPong:
+			err = stream.SendPacket(connection.PacketPongAck, nil)
+		case cmd.IsMercury():
+			err = s.handleMercury(c, cmd, data)
+		}
+		if err != nil {
+			log.Printf("aptest: failed to handle %v packet: %v\n", cmd, err)
+			return
+		}
+	}
+}
+
+// handshake accepts the Diffie-Hellman key exchange of the client, signing the key of the server with key, and returns
+// the encrypted stream of the connection
+func handshake(netConn net.Conn, key *rsa.PrivateKey) (connection.PacketStream, error) {
+	conn := connection.MakePlainConnection(netConn, netConn)
+
+	// The hello of the client is prefixed with the protocol version, which its size includes
+	clientPacket := make([]byte, 6)
+	if _, err := io.ReadFull(netConn, clientPacket); err != nil {
+		return nil, fmt.Errorf("failed to read client hello: %v", err)
+	}
+	size := binary.BigEndian.Uint32(clientPacket[2:])
+	if size < 6 {
+		return nil, fmt.Errorf("bad client hello size %d", size)
+	}
+	clientPacket = append(clientPacket, make([]byte, size-6)...)
+	if _, err := io.ReadFull(netConn, clientPacket[6:]); err != nil {
+		return nil, fmt.Errorf("failed to read client hello: %v", err)
+	}
+	hello := &Spotify.ClientHello{}
+	if err := proto.Unmarshal(clientPacket[6:], hello); err != nil {
+		return nil, fmt.Errorf("bad client hello: %v", err)
+	}
+	clientKey := hello.GetLoginCryptoHello().GetDiffieHellman().GetGc()
+	if clientKey == nil {
+		return nil, errors.New("client hello has no Diffie-Hellman key")
+	}
+
+	keys := crypto.GenerateKeys()
+	signature, err := crypto.SignServerKey(key, keys.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	response, err := proto.Marshal(&Spotify.APResponseMessage{
+		Challenge: &Spotify.APChallenge{
+			LoginCryptoChallenge: &Spotify.LoginCryptoChallengeUnion{
+				DiffieHellman: &Spotify.LoginCryptoDiffieHellmanChallenge{
+					Gs:                 keys.PubKey(),
+					ServerSignatureKey: proto.Int32(0),
+					GsSignature:        signature,
+				},
+			},
+			FingerprintChallenge: &Spotify.FingerprintChallengeUnion{},
+			PowChallenge:         &Spotify.PoWChallengeUnion{},
+			CryptoChallenge:      &Spotify.CryptoChallengeUnion{},
+			ServerNonce:          crypto.RandomVec(0x10),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	serverPacket, err := conn.SendPrefixPacket([]byte{}, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write server hello: %v", err)
+	}
+
+	sharedKeys := keys.AddRemoteKey(clientKey, clientPacket, serverPacket)
+
+	plainResponse, err := conn.RecvPacket()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client response: %v", err)
+	}
+	challenge := &Spotify.ClientResponsePlaintext{}
+	if err := proto.Unmarshal(plainResponse[4:], challenge); err != nil {
+		return nil, fmt.Errorf("bad client response: %v", err)
+	}
+	if !bytes.Equal(challenge.GetLoginCryptoResponse().GetDiffieHellman().GetHmac(), sharedKeys.Challenge()) {
+		return nil, errors.New("bad challenge response")
+	}
+
+	return crypto.CreateStream(sharedKeys.Reversed(), conn), nil
+}
+
+// login authenticates the user of the login packet, and welcomes them or sends the reason of the failure
+func (s *Server) login(c *conn, data []byte) error {
+	packet := &Spotify.ClientResponseEncrypted{}
+	if err := proto.Unmarshal(data, packet); err != nil {
+		return fmt.Errorf("bad login packet: %v", err)
+	}
+
+	username, ok := s.authenticate(packet.GetLoginCredentials())
+	if !ok {
+		failed, _ := proto.Marshal(&Spotify.APLoginFailed{
+			ErrorCode: Spotify.ErrorCode_BadCredentials.Enum(),
+		})
+		return c.stream.SendPacket(connection.PacketAuthFailure, failed)
+	}
+
+	welcome, err := proto.Marshal(&Spotify.APWelcome{
+		CanonicalUsername:           proto.String(username),
+		AccountTypeLoggedIn:         Spotify.AccountType_Spotify.Enum(),
+		CredentialsTypeLoggedIn:     Spotify.AccountType_Spotify.Enum(),
+		ReusableAuthCredentialsType: Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum(),
+		ReusableAuthCredentials:     []byte(storedPrefix + username),
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.stream.SendPacket(connection.PacketAPWelcome, welcome); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	c.username = username
+	s.lock.Unlock()
+
+	country := s.Country
+	if country == "" {
+		country = "US"
+	}
+	return c.stream.SendPacket(connection.PacketCountryCode, []byte(country))
+}
+
+// authenticate returns the user of the credentials, and whether they are valid
+func (s *Server) authenticate(credentials *Spotify.LoginCredentials) (string, bool) {
+	username := credentials.GetUsername()
+	authData := string(credentials.GetAuthData())
+	switch credentials.GetTyp() {
+	case Spotify.AuthenticationType_AUTHENTICATION_USER_PASS:
+		password, ok := s.Users[username]
+		return username, ok && password == authData
+	case Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS:
+		_, ok := s.Users[username]
+		return username, ok && authData == storedPrefix+username
+	case Spotify.AuthenticationType_AUTHENTICATION_SPOTIFY_TOKEN:
+		user, ok := s.Tokens[authData]
+		return user, ok
+	}
+	return "", false
+}
@@ -0,0 +1,120 @@
+package aptest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/aptest"
+	"github.com/fischerling/librespot-golang/librespot/core"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+func newSession(t *testing.T, server *aptest.Server) *core.Session {
+	s, err := core.NewSession(core.SessionConfig{
+		DeviceName:   "test",
+		ApAddress:    aptest.Address,
+		Transport:    server.Transport(),
+		ApServerKey:  server.PublicKey(),
+		LoginLimiter: core.NewLoginLimiter(core.LoginLimitPolicy{}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestServer(t *testing.T) {
+	server, err := aptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Users["user"] = "password"
+	server.Country = "FR"
+	server.HandleMercury("hm://test/", func(username string, req mercury.Request) mercury.Response {
+		return mercury.Response{StatusCode: 200, Payload: [][]byte{[]byte(username + " " + req.Method)}}
+	})
+
+	s := newSession(t, server)
+	defer s.Close(context.Background())
+	if err := s.Login("user", "wrong"); !errors.Is(err, core.ErrBadCredentials) {
+		t.Errorf("Expected ErrBadCredentials, got %v", err)
+	}
+
+	s = newSession(t, server)
+	defer s.Close(context.Background())
+	if err := s.Login("user", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Username() != "user" {
+		t.Errorf("Logged in as %q", s.Username())
+	}
+
+	res, err := s.Mercury().Do(mercury.Request{Method: "GET", Uri: "hm://test/hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || len(res.Payload) != 1 || string(res.Payload[0]) != "user GET" {
+		t.Errorf("Bad response %+v", res)
+	}
+	if res, err := s.Mercury().Do(mercury.Request{Method: "GET", Uri: "hm://unknown"}); err == nil &&
+		res.StatusCode != 404 {
+		t.Errorf("Bad response for an unknown URI %+v", res)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for s.Country() != "FR" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.Country() != "FR" {
+		t.Errorf("Got country %q", s.Country())
+	}
+
+	// The reusable credentials log in again
+	again := newSession(t, server)
+	defer again.Close(context.Background())
+	if err := again.LoginSaved("user", s.ReusableAuthBlob()); err != nil {
+		t.Fatal(err)
+	}
+	if dialed := server.Transport().Dialed(); len(dialed) != 3 || dialed[0] != aptest.Address {
+		t.Errorf("Dialed %v", dialed)
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	server, err := aptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Tokens["token"] = "user"
+
+	s := newSession(t, server)
+	defer s.Close(context.Background())
+	if err := s.LoginWithAccessToken(core.AccessToken{Token: "token"}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan mercury.Response, 1)
+	subscribed := make(chan mercury.Response, 1)
+	s.Mercury().Subscribe("hm://test/events", events, func(res mercury.Response) {
+		subscribed <- res
+	})
+	if res := <-subscribed; res.StatusCode != 200 {
+		t.Fatalf("Subscription failed: %+v", res)
+	}
+
+	if err := server.Publish("hm://test/events", []byte("event")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-events:
+		if event.Uri != "hm://test/events" || string(event.Payload[0]) != "event" {
+			t.Errorf("Bad event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Event not received")
+	}
+}
@@ -0,0 +1,306 @@
+// Package collection reads and modifies the library of a user: the saved tracks and albums, the followed artists and
+// shows and the saved episodes, through the collection API of the spclient. The changes made by the other devices of
+// the user are pushed through the dealer.
+package collection
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/dealer"
+	"github.com/fischerling/librespot-golang/librespot/spclient"
+	"github.com/golang/protobuf/proto"
+)
+
+// Sets of items of the library
+const (
+	// SetLibrary holds the saved tracks ("Liked Songs") and albums
+	SetLibrary = "collection"
+	// SetArtists holds the followed artists
+	SetArtists = "artist"
+	// SetShows holds the followed shows
+	SetShows = "show"
+	// SetListenLater holds the saved episodes
+	SetListenLater = "listenlater"
+)
+
+// DealerPrefix is the prefix of the uris of the dealer messages notifying the changes of the library, see
+// Client.HandleMessage
+const DealerPrefix = "hm://collection/"
+
+// DefaultPageSize is the number of items requested per page by Items
+const DefaultPageSize = 300
+
+// contentType is the content type of the requests and responses of the collection API
+const contentType = "application/vnd.collection-v2.spotify.proto"
+
+// Item is an entry of the library
+type Item struct {
+	Uri     string
+	AddedAt time.Time
+}
+
+// Page is a page of the items of a set, the most recently added first
+type Page struct {
+	Items []Item
+	// NextPageToken requests the next page, it is empty on the last page
+	NextPageToken string
+	// SyncToken identifies the revision of the set
+	SyncToken string
+}
+
+// Change is the addition or the removal of an item of the library, made by this client or another device
+type Change struct {
+	Set     string
+	Item    Item
+	Removed bool
+}
+
+// ChangeCallback is called with the changes of the library
+type ChangeCallback func(change Change)
+
+// Client reads and modifies the library of a user through the spclient
+type Client struct {
+	spclient *spclient.Client
+	username string
+
+	lock      sync.Mutex
+	callbacks []ChangeCallback
+	log       *log.Logger
+}
+
+// NewClient creates a client acting as the specified user
+func NewClient(sp *spclient.Client, username string) *Client {
+	return &Client{
+		spclient: sp,
+		username: username,
+	}
+}
+
+// SetLogger sets the logger receiving the invalid updates, the standard logger being used if nil. It must be called
+// before handling messages.
+func (c *Client) SetLogger(logger *log.Logger) {
+	c.log = logger
+}
+
+// logger returns the logger of the client, see SetLogger
+func (c *Client) logger() *log.Logger {
+	if c.log == nil {
+		return log.Default()
+	}
+	return c.log
+}
+
+// SetOf returns the set holding the items of the kind of a uri, e.g. SetArtists for spotify:artist:..., or "" if
+// such items can't be saved
+func SetOf(uri string) string {
+	switch {
+	case strings.HasPrefix(uri, "spotify:track:"), strings.HasPrefix(uri, "spotify:album:"):
+		return SetLibrary
+	case strings.HasPrefix(uri, "spotify:artist:"):
+		return SetArtists
+	case strings.HasPrefix(uri, "spotify:show:"):
+		return SetShows
+	case strings.HasPrefix(uri, "spotify:episode:"):
+		return SetListenLater
+	default:
+		return ""
+	}
+}
+
+// Page fetches a page of up to limit items of a set, starting at the page token, or at the first page if empty
+func (c *Client) Page(set string, pageToken string, limit int) (*Page, error) {
+	req := &Spotify.CollectionPageRequest{
+		Username: proto.String(c.username),
+		Set:      proto.String(set),
+		Limit:    proto.Int32(int32(limit)),
+	}
+	if pageToken != "" {
+		req.PaginationToken = proto.String(pageToken)
+	}
+	res := &Spotify.CollectionPageResponse{}
+	if err := c.request("/collection/v2/paging", req, res); err != nil {
+		return nil, fmt.Errorf("failed to get %s page: %v", set, err)
+	}
+	return &Page{
+		Items:         pageItems(res),
+		NextPageToken: res.GetNextPageToken(),
+		SyncToken:     res.GetSyncToken(),
+	}, nil
+}
+
+// Items fetches all the items of a set, the most recently added first
+func (c *Client) Items(set string) ([]Item, error) {
+	var items []Item
+	token := ""
+	for {
+		page, err := c.Page(set, token, DefaultPageSize)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextPageToken == "" || page.NextPageToken == token {
+			return items, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+// SavedTracks fetches the saved tracks, the most recently added first
+func (c *Client) SavedTracks() ([]Item, error) {
+	return c.itemsWithPrefix(SetLibrary, "spotify:track:")
+}
+
+// SavedAlbums fetches the saved albums, the most recently added first
+func (c *Client) SavedAlbums() ([]Item, error) {
+	return c.itemsWithPrefix(SetLibrary, "spotify:album:")
+}
+
+// FollowedArtists fetches the followed artists, the most recently followed first
+func (c *Client) FollowedArtists() ([]Item, error) {
+	return c.Items(SetArtists)
+}
+
+// FollowedShows fetches the followed shows, the most recently followed first
+func (c *Client) FollowedShows() ([]Item, error) {
+	return c.Items(SetShows)
+}
+
+// itemsWithPrefix fetches the items of a set whose uris start with the prefix
+func (c *Client) itemsWithPrefix(set string, prefix string) ([]Item, error) {
+	items, err := c.Items(set)
+	if err != nil {
+		return nil, err
+	}
+	res := items[:0]
+	for _, item := range items {
+		if strings.HasPrefix(item.Uri, prefix) {
+			res = append(res, item)
+		}
+	}
+	return res, nil
+}
+
+// Add saves items in the library, e.g. tracks, or follows artists or shows, grouping the uris by set
+func (c *Client) Add(uris ...string) error {
+	return c.write(uris, false)
+}
+
+// Remove removes items from the library, e.g. tracks, or unfollows artists or shows, grouping the uris by set
+func (c *Client) Remove(uris ...string) error {
+	return c.write(uris, true)
+}
+
+// write adds or removes items, with a request per set
+func (c *Client) write(uris []string, removed bool) error {
+	bySet := map[string][]string{}
+	var sets []string
+	for _, uri := range uris {
+		set := SetOf(uri)
+		if set == "" {
+			return fmt.Errorf("%s can't be saved in the library", uri)
+		}
+		if _, ok := bySet[set]; !ok {
+			sets = append(sets, set)
+		}
+		bySet[set] = append(bySet[set], uri)
+	}
+
+	now := time.Now()
+	for _, set := range sets {
+		err := c.request("/collection/v2/write",
+			writeRequest(c.username, set, bySet[set], now, removed, newClientUpdateId()), nil)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %v", set, err)
+		}
+	}
+	return nil
+}
+
+// Contains returns whether each of the uris is in a set
+func (c *Client) Contains(set string, uris ...string) ([]bool, error) {
+	req := &Spotify.CollectionContainsRequest{
+		Items:    uris,
+		Username: proto.String(c.username),
+		Set:      proto.String(set),
+	}
+	res := &Spotify.CollectionContainsResponse{}
+	if err := c.request("/collection/v2/contains", req, res); err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %v", set, err)
+	}
+	if len(res.GetFound()) != len(uris) {
+		return nil, fmt.Errorf("invalid %s lookup: %d results for %d items", set, len(res.GetFound()), len(uris))
+	}
+	return res.GetFound(), nil
+}
+
+// request posts a message to the collection API, and unmarshals the response into res unless nil
+func (c *Client) request(path string, req proto.Message, res proto.Message) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	body, err = c.spclient.Request("POST", path, body, contentType)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return nil
+	}
+	if err := proto.Unmarshal(body, res); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	return nil
+}
+
+// OnChange registers a callback notified of the changes of the library, once the client receives the dealer
+// messages, see HandleMessage
+func (c *Client) OnChange(cb ChangeCallback) {
+	c.lock.Lock()
+	c.callbacks = append(c.callbacks, cb)
+	c.lock.Unlock()
+}
+
+// HandleMessage notifies the changes of a dealer message to the callbacks. It is the handler of the messages whose
+// uri starts with DealerPrefix:
+//
+//	d.Handle(collection.DealerPrefix, client.HandleMessage)
+func (c *Client) HandleMessage(msg dealer.Message) {
+	// The same updates are sent as JSON to the uris ending with /json
+	if strings.HasSuffix(msg.Uri, "/json") {
+		return
+	}
+
+	for _, payload := range msg.Payloads {
+		update := &Spotify.CollectionPubSubUpdate{}
+		if err := proto.Unmarshal(payload, update); err != nil {
+			c.logger().Printf("Invalid collection update from %s: %v", msg.Uri, err)
+			continue
+		}
+		c.emit(updateChanges(update))
+	}
+}
+
+func (c *Client) emit(changes []Change) {
+	c.lock.Lock()
+	callbacks := append([]ChangeCallback{}, c.callbacks...)
+	c.lock.Unlock()
+	for _, change := range changes {
+		for _, cb := range callbacks {
+			cb(change)
+		}
+	}
+}
+
+// newClientUpdateId returns a random identifier of a write request
+func newClientUpdateId() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
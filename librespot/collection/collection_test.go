@@ -0,0 +1,160 @@
+package collection
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/dealer"
+	"github.com/fischerling/librespot-golang/librespot/spclient"
+	"github.com/golang/protobuf/proto"
+)
+
+type fakeAuthorizer struct{}
+
+func (fakeAuthorizer) Authorize(req *http.Request, scopes ...string) error {
+	req.Header.Set("Authorization", "Bearer token")
+	return nil
+}
+
+func (fakeAuthorizer) Invalidate(scopes ...string) {}
+
+func newItem(uri string, addedAt int32, removed bool) *Spotify.CollectionItem {
+	return &Spotify.CollectionItem{Uri: proto.String(uri), AddedAt: proto.Int32(addedAt), IsRemoved: proto.Bool(removed)}
+}
+
+func TestCollection(t *testing.T) {
+	var lock sync.Mutex
+	var writes []*Spotify.CollectionWriteRequest
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != contentType {
+			t.Errorf("Got content type %q", r.Header.Get("Content-Type"))
+		}
+
+		var res proto.Message
+		switch r.URL.Path {
+		case "/collection/v2/paging":
+			req := &Spotify.CollectionPageRequest{}
+			proto.Unmarshal(body, req)
+			if req.GetUsername() != "user" || req.GetSet() != SetLibrary {
+				t.Errorf("Got page request %v", req)
+			}
+			if req.GetPaginationToken() == "" {
+				res = &Spotify.CollectionPageResponse{
+					Items: []*Spotify.CollectionItem{
+						newItem("spotify:track:a", 3000, false),
+						newItem("spotify:album:b", 2000, false),
+					},
+					NextPageToken: proto.String("next"),
+					SyncToken:     proto.String("sync"),
+				}
+			} else {
+				res = &Spotify.CollectionPageResponse{
+					Items: []*Spotify.CollectionItem{
+						newItem("spotify:track:c", 1000, true),
+						newItem("spotify:track:d", 500, false),
+					},
+					SyncToken: proto.String("sync"),
+				}
+			}
+		case "/collection/v2/write":
+			req := &Spotify.CollectionWriteRequest{}
+			proto.Unmarshal(body, req)
+			lock.Lock()
+			writes = append(writes, req)
+			lock.Unlock()
+		case "/collection/v2/contains":
+			req := &Spotify.CollectionContainsRequest{}
+			proto.Unmarshal(body, req)
+			contains := &Spotify.CollectionContainsResponse{}
+			for _, uri := range req.GetItems() {
+				contains.Found = append(contains.Found, uri == "spotify:artist:a")
+			}
+			res = contains
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var data []byte
+		if res != nil {
+			data, _ = proto.Marshal(res)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	sp := spclient.New(strings.TrimPrefix(server.URL, "https://"), fakeAuthorizer{}, server.Client(), "")
+	client := NewClient(sp, "user")
+
+	tracks, err := client.SavedTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 || tracks[0].Uri != "spotify:track:a" || !tracks[0].AddedAt.Equal(time.Unix(3000, 0)) ||
+		tracks[1].Uri != "spotify:track:d" {
+		t.Errorf("Got saved tracks %v", tracks)
+	}
+	albums, err := client.SavedAlbums()
+	if err != nil || len(albums) != 1 || albums[0].Uri != "spotify:album:b" {
+		t.Errorf("Got saved albums %v, %v", albums, err)
+	}
+
+	found, err := client.Contains(SetArtists, "spotify:artist:a", "spotify:artist:b")
+	if err != nil || len(found) != 2 || !found[0] || found[1] {
+		t.Errorf("Got %v, %v", found, err)
+	}
+
+	if err := client.Add("spotify:track:x", "spotify:artist:y", "spotify:track:z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Remove("spotify:playlist:p"); err == nil {
+		t.Errorf("Playlists can't be saved in the library")
+	}
+	if len(writes) != 2 {
+		t.Fatalf("Expected a write per set, got %d", len(writes))
+	}
+	if writes[0].GetSet() != SetLibrary || len(writes[0].GetItems()) != 2 || writes[0].GetClientUpdateId() == "" {
+		t.Errorf("Got write request %v", writes[0])
+	}
+	if writes[1].GetSet() != SetArtists || len(writes[1].GetItems()) != 1 {
+		t.Errorf("Got write request %v", writes[1])
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	client := NewClient(nil, "user")
+	var changes []Change
+	client.OnChange(func(change Change) {
+		changes = append(changes, change)
+	})
+
+	update, _ := proto.Marshal(&Spotify.CollectionPubSubUpdate{
+		Username: proto.String("user"),
+		Set:      proto.String(SetArtists),
+		Items: []*Spotify.CollectionItem{
+			newItem("spotify:artist:a", 1000, false),
+			newItem("spotify:artist:b", 0, true),
+		},
+	})
+
+	client.HandleMessage(dealer.Message{Uri: DealerPrefix + "artist/user", Payloads: [][]byte{update}})
+	client.HandleMessage(dealer.Message{Uri: DealerPrefix + "artist/user/json", Payloads: [][]byte{[]byte("[]")}})
+
+	if len(changes) != 2 {
+		t.Fatalf("Got changes %v", changes)
+	}
+	if changes[0].Set != SetArtists || changes[0].Item.Uri != "spotify:artist:a" || changes[0].Removed ||
+		!changes[0].Item.AddedAt.Equal(time.Unix(1000, 0)) {
+		t.Errorf("Got change %v", changes[0])
+	}
+	if changes[1].Item.Uri != "spotify:artist:b" || !changes[1].Removed {
+		t.Errorf("Got change %v", changes[1])
+	}
+}
@@ -0,0 +1,61 @@
+package collection
+
+import (
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// writeRequest returns a WriteRequest adding or removing items of a set
+func writeRequest(username, set string, uris []string, addedAt time.Time, removed bool,
+	clientUpdateId string) *Spotify.CollectionWriteRequest {
+	req := &Spotify.CollectionWriteRequest{
+		Username:       proto.String(username),
+		Set:            proto.String(set),
+		ClientUpdateId: proto.String(clientUpdateId),
+	}
+	for _, uri := range uris {
+		item := &Spotify.CollectionItem{
+			Uri:     proto.String(uri),
+			AddedAt: proto.Int32(int32(addedAt.Unix())),
+		}
+		if removed {
+			item.IsRemoved = proto.Bool(true)
+		}
+		req.Items = append(req.Items, item)
+	}
+	return req
+}
+
+// pageItems returns the items of a PageResponse. The removed items are left out.
+func pageItems(res *Spotify.CollectionPageResponse) []Item {
+	var items []Item
+	for _, item := range res.GetItems() {
+		if !item.GetIsRemoved() {
+			items = append(items, collectionItem(item))
+		}
+	}
+	return items
+}
+
+// updateChanges returns the changes of a PubSubUpdate, pushed by the dealer
+func updateChanges(update *Spotify.CollectionPubSubUpdate) []Change {
+	changes := make([]Change, 0, len(update.GetItems()))
+	for _, item := range update.GetItems() {
+		changes = append(changes, Change{
+			Set:     update.GetSet(),
+			Item:    collectionItem(item),
+			Removed: item.GetIsRemoved(),
+		})
+	}
+	return changes
+}
+
+func collectionItem(item *Spotify.CollectionItem) Item {
+	res := Item{Uri: item.GetUri()}
+	if item.GetAddedAt() != 0 {
+		res.AddedAt = time.Unix(int64(item.GetAddedAt()), 0)
+	}
+	return res
+}
@@ -0,0 +1,198 @@
+package connection
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Dialer establishes the TCP connections to the access points. Hostnames are resolved to both IPv6 and IPv4
+// addresses, which are then tried following the "happy eyeballs" algorithm (RFC 8305): addresses families are
+// interleaved, starting with IPv6, and a new attempt is started whenever the previous one failed or did not succeed
+// within FallbackDelay. The first established connection wins. This way, IPv6-only networks and networks with a
+// broken IPv4 (or IPv6) connectivity connect quickly instead of waiting for a timeout.
+type Dialer struct {
+	// Timeout is the maximum time spent connecting to an address, all attempts included
+	Timeout time.Duration
+	// FallbackDelay is the time to wait for an attempt before starting the next one in parallel
+	FallbackDelay time.Duration
+	// Resolver is used to resolve the hostnames. If nil, DefaultResolver is used.
+	Resolver Resolver
+	// Proxy selects the proxy to connect through, for the access points as well as the HTTP APIs. If nil, all the
+	// connections are direct.
+	Proxy ProxyFunc
+	// TLSConfig is used for the TLS connections, e.g. to require a minimum TLS version, or to trust the CA of a
+	// corporate proxy intercepting TLS. If nil, the default configuration is used.
+	TLSConfig *tls.Config
+}
+
+// NewDialer creates a dialer with the default timeouts, resolving hostnames with DefaultResolver and using the proxy
+// configured in the environment, if any
+func NewDialer() *Dialer {
+	return &Dialer{
+		Resolver:      DefaultResolver,
+		Timeout:       30 * time.Second,
+		FallbackDelay: 250 * time.Millisecond,
+		Proxy:         ProxyFromEnvironment,
+	}
+}
+
+// TLSClient starts a TLS session over the connection, with the TLS configuration of this dialer
+func (d *Dialer) TLSClient(conn net.Conn, serverName string) *tls.Conn {
+	return tls.Client(conn, d.tlsConfig(serverName))
+}
+
+// tlsConfig returns a copy of the TLS configuration of this dialer, for the specified server
+func (d *Dialer) tlsConfig(serverName string) *tls.Config {
+	config := &tls.Config{}
+	if d.TLSConfig != nil {
+		config = d.TLSConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = serverName
+	}
+	return config
+}
+
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// Dial connects to the specified address, in the host:port form
+func (d *Dialer) Dial(address string) (net.Conn, error) {
+	ctx := context.Background()
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+	return d.DialContext(ctx, address)
+}
+
+// DialContext connects to the specified address, in the host:port form, until the context is done
+func (d *Dialer) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	proxy, err := d.proxyFor(address)
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		return d.dialProxy(ctx, proxy, address)
+	}
+	return d.dialDirect(ctx, address)
+}
+
+// dialDirect connects to the address without going through a proxy
+func (d *Dialer) dialDirect(ctx context.Context, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	addrs, err := d.resolver().LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %v", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address found for %s", host)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ordered := interleaveAddrs(addrs)
+	results := make(chan dialResult, len(ordered))
+	dialer := net.Dialer{}
+
+	next, pending := 0, 0
+	var firstErr error
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			// Start a new attempt, either because it is the first one, or because the previous one is too slow
+			if next < len(ordered) {
+				addr := net.JoinHostPort(ordered[next].String(), port)
+				next++
+				pending++
+				go func() {
+					conn, err := dialer.DialContext(ctx, "tcp", addr)
+					results <- dialResult{conn, err}
+				}()
+				timer.Reset(d.FallbackDelay)
+			}
+
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				// Close the connections of the attempts that may still succeed
+				go drainDials(results, pending)
+				return res.conn, nil
+			}
+
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if pending == 0 && next == len(ordered) {
+				return nil, firstErr
+			}
+
+			// Don't wait for the fallback delay, the next address can be tried right away
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(0)
+
+		case <-ctx.Done():
+			go drainDials(results, pending)
+			if firstErr != nil {
+				return nil, firstErr
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// drainDials waits for the remaining attempts, and closes the connections they established
+func drainDials(results chan dialResult, pending int) {
+	for ; pending > 0; pending-- {
+		if res := <-results; res.err == nil {
+			res.conn.Close()
+		}
+	}
+}
+
+// interleaveAddrs orders the addresses alternating between IPv6 and IPv4, starting with IPv6
+func interleaveAddrs(addrs []net.IPAddr) []net.IPAddr {
+	var v6, v4 []net.IPAddr
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			v4 = append(v4, addr)
+		} else {
+			v6 = append(v6, addr)
+		}
+	}
+
+	res := make([]net.IPAddr, 0, len(addrs))
+	for i := 0; i < len(v6) || i < len(v4); i++ {
+		if i < len(v6) {
+			res = append(res, v6[i])
+		}
+		if i < len(v4) {
+			res = append(res, v4[i])
+		}
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package connection
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticResolver map[string]string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ip, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+}
+
+func TestInterleaveAddrs(t *testing.T) {
+	addrs := []net.IPAddr{
+		{IP: net.ParseIP("10.0.0.1")},
+		{IP: net.ParseIP("10.0.0.2")},
+		{IP: net.ParseIP("10.0.0.3")},
+		{IP: net.ParseIP("2001:db8::1")},
+	}
+
+	expected := []string{"2001:db8::1", "10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	res := interleaveAddrs(addrs)
+	if len(res) != len(expected) {
+		t.Fatalf("got %d addresses, expected %d", len(res), len(expected))
+	}
+	for i, addr := range res {
+		if addr.String() != expected[i] {
+			t.Errorf("address %d: got %s, expected %s", i, addr.String(), expected[i])
+		}
+	}
+}
+
+func TestDialFallback(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	// localhost may also resolve to ::1, on which nobody listens: the dialer must fall back to IPv4
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	conn, err := NewDialer().Dial(net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestDialCustomResolver(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	dialer := NewDialer()
+	dialer.Resolver = staticResolver{"ap.example": "127.0.0.1"}
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	conn, err := dialer.Dial(net.JoinHostPort("ap.example", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if _, err := dialer.Dial(net.JoinHostPort("unknown.example", port)); err == nil {
+		t.Errorf("expected a resolution error")
+	}
+}
+
+func TestHTTPClientTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The certificate of the test server is not trusted by default
+	if _, err := NewDialer().HTTPClient().Get(server.URL); err == nil {
+		t.Errorf("expected a certificate error")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	dialer := NewDialer()
+	dialer.TLSConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+	resp, err := dialer.HTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,222 @@
+package connection
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrInjected is the error returned by FaultyStream and FaultyConn once their FailAfter limit is reached
+var ErrInjected = errors.New("injected connection failure")
+
+// Faults configures the errors injected by FaultyStream and FaultyConn, to test the reconnection, retry and player
+// resilience code paths. Rates are probabilities between 0 and 1. The injected errors only depend on the Seed and on
+// the sequence of operations, so that a failing test can be reproduced.
+type Faults struct {
+	Seed int64
+	// Latency is added to every operation, plus a random duration up to Jitter
+	Latency time.Duration
+	Jitter  time.Duration
+	// DropRate is the probability of a packet being silently dropped
+	DropRate float64
+	// CorruptRate is the probability of a packet, or of the data returned by a read, having a byte flipped
+	CorruptRate float64
+	// ShortReadRate is the probability of a read returning fewer bytes than requested. It only applies to
+	// FaultyConn, as packets are never split.
+	ShortReadRate float64
+	// FailAfter makes all the operations fail with ErrInjected after that many successful ones. Zero disables it.
+	FailAfter int
+}
+
+// FaultStats counts the errors injected so far
+type FaultStats struct {
+	Operations int
+	Dropped    int
+	Corrupted  int
+	ShortReads int
+	Failed     int
+}
+
+// faultInjector draws the errors to inject, in a deterministic sequence
+type faultInjector struct {
+	faults Faults
+	lock   sync.Mutex
+	rand   *rand.Rand
+	stats  FaultStats
+}
+
+// fault is the set of errors to inject into one operation
+type fault struct {
+	delay    time.Duration
+	fail     bool
+	drop     bool
+	corrupt  int
+	short    bool
+	shortLen float64
+}
+
+func newFaultInjector(faults Faults) *faultInjector {
+	return &faultInjector{
+		faults: faults,
+		rand:   rand.New(rand.NewSource(faults.Seed)),
+	}
+}
+
+// next draws the errors of the next operation, on data of the specified size. All the random values are drawn for
+// every operation, so that the sequence doesn't depend on the previous outcomes.
+func (f *faultInjector) next(size int) fault {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	res := fault{delay: f.faults.Latency}
+	if f.faults.Jitter > 0 {
+		res.delay += time.Duration(f.rand.Int63n(int64(f.faults.Jitter)))
+	}
+	drop := f.rand.Float64() < f.faults.DropRate
+	corrupt := f.rand.Float64() < f.faults.CorruptRate
+	corruptIdx := f.rand.Int()
+	short := f.rand.Float64() < f.faults.ShortReadRate
+	shortLen := f.rand.Float64()
+
+	if f.faults.FailAfter > 0 && f.stats.Operations >= f.faults.FailAfter {
+		f.stats.Failed++
+		res.fail = true
+		return res
+	}
+
+	f.stats.Operations++
+	switch {
+	case drop:
+		f.stats.Dropped++
+		res.drop = true
+	case corrupt && size > 0:
+		f.stats.Corrupted++
+		res.corrupt = corruptIdx%size + 1
+	}
+	res.short, res.shortLen = short, shortLen
+	return res
+}
+
+func (f *faultInjector) statsCopy() FaultStats {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.stats
+}
+
+// corruptCopy returns a copy of data, with the byte at index fault.corrupt-1 flipped if needed
+func (f fault) corruptCopy(data []byte) []byte {
+	if f.corrupt == 0 {
+		return data
+	}
+	res := make([]byte, len(data))
+	copy(res, data)
+	res[f.corrupt-1] ^= 0xff
+	return res
+}
+
+// FaultyStream is a PacketStream injecting latency, dropped, corrupted packets and failures into another one
+type FaultyStream struct {
+	stream   PacketStream
+	injector *faultInjector
+}
+
+// NewFaultyStream wraps the stream, injecting the specified faults into the packets sent and received
+func NewFaultyStream(stream PacketStream, faults Faults) *FaultyStream {
+	return &FaultyStream{
+		stream:   stream,
+		injector: newFaultInjector(faults),
+	}
+}
+
+// Stats returns the number of errors injected so far
+func (s *FaultyStream) Stats() FaultStats {
+	return s.injector.statsCopy()
+}
+
+func (s *FaultyStream) SendPacket(cmd PacketType, data []byte) error {
+	f := s.injector.next(len(data))
+	time.Sleep(f.delay)
+
+	switch {
+	case f.fail:
+		return ErrInjected
+	case f.drop:
+		return nil
+	default:
+		return s.stream.SendPacket(cmd, f.corruptCopy(data))
+	}
+}
+
+func (s *FaultyStream) RecvPacket() (PacketType, []byte, error) {
+	for {
+		cmd, data, err := s.stream.RecvPacket()
+		if err != nil {
+			return cmd, data, err
+		}
+
+		f := s.injector.next(len(data))
+		time.Sleep(f.delay)
+
+		switch {
+		case f.fail:
+			return 0, nil, ErrInjected
+		case f.drop:
+			continue
+		default:
+			return cmd, f.corruptCopy(data), nil
+		}
+	}
+}
+
+// FaultyConn is a net.Conn injecting latency, short reads, corrupted data and failures into another one. Dropping
+// data is not supported, as it would break the stream framing instead of simulating a lost packet.
+type FaultyConn struct {
+	net.Conn
+	injector *faultInjector
+}
+
+// NewFaultyConn wraps the connection, injecting the specified faults into its reads and writes
+func NewFaultyConn(conn net.Conn, faults Faults) *FaultyConn {
+	faults.DropRate = 0
+	return &FaultyConn{
+		Conn:     conn,
+		injector: newFaultInjector(faults),
+	}
+}
+
+// Stats returns the number of errors injected so far
+func (c *FaultyConn) Stats() FaultStats {
+	return c.injector.statsCopy()
+}
+
+func (c *FaultyConn) Read(b []byte) (int, error) {
+	f := c.injector.next(len(b))
+	time.Sleep(f.delay)
+	if f.fail {
+		return 0, ErrInjected
+	}
+
+	if f.short && len(b) > 1 {
+		c.injector.lock.Lock()
+		c.injector.stats.ShortReads++
+		c.injector.lock.Unlock()
+		b = b[:1+int(f.shortLen*float64(len(b)-1))]
+	}
+
+	n, err := c.Conn.Read(b)
+	if f.corrupt > 0 && n > 0 {
+		b[(f.corrupt-1)%n] ^= 0xff
+	}
+	return n, err
+}
+
+func (c *FaultyConn) Write(b []byte) (int, error) {
+	f := c.injector.next(len(b))
+	time.Sleep(f.delay)
+	if f.fail {
+		return 0, ErrInjected
+	}
+	return c.Conn.Write(f.corruptCopy(b))
+}
@@ -0,0 +1,84 @@
+package connection
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+type recordingStream struct {
+	sent [][]byte
+}
+
+func (r *recordingStream) SendPacket(cmd PacketType, data []byte) error {
+	r.sent = append(r.sent, data)
+	return nil
+}
+
+func (r *recordingStream) RecvPacket() (PacketType, []byte, error) {
+	return 0, []byte{1, 2, 3}, nil
+}
+
+func sendPackets(faults Faults) ([][]byte, FaultStats) {
+	recorder := &recordingStream{}
+	stream := NewFaultyStream(recorder, faults)
+	for i := 0; i < 100; i++ {
+		stream.SendPacket(0, []byte{byte(i), 0, 0, 0})
+	}
+	return recorder.sent, stream.Stats()
+}
+
+func TestFaultyStreamDeterministic(t *testing.T) {
+	faults := Faults{Seed: 42, DropRate: 0.2, CorruptRate: 0.2}
+
+	sent, stats := sendPackets(faults)
+	if stats.Dropped == 0 || stats.Corrupted == 0 || len(sent) != 100-stats.Dropped {
+		t.Errorf("Unexpected stats %+v for %d packets sent", stats, len(sent))
+	}
+
+	again, _ := sendPackets(faults)
+	if len(again) != len(sent) {
+		t.Fatalf("Different number of packets with the same seed: %d, %d", len(again), len(sent))
+	}
+	for i := range sent {
+		if !bytes.Equal(sent[i], again[i]) {
+			t.Errorf("Packet %d differs with the same seed: %x, %x", i, sent[i], again[i])
+		}
+	}
+}
+
+func TestFaultyStreamFailAfter(t *testing.T) {
+	stream := NewFaultyStream(&recordingStream{}, Faults{FailAfter: 2})
+	for i := 0; i < 2; i++ {
+		if _, _, err := stream.RecvPacket(); err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+	}
+	if _, _, err := stream.RecvPacket(); err != ErrInjected {
+		t.Errorf("Expected injected error, got %v", err)
+	}
+}
+
+func TestPlainConnectionShortReads(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	faulty := NewFaultyConn(client, Faults{Seed: 1, ShortReadRate: 0.8})
+	conn := MakePlainConnection(faulty, faulty)
+
+	payload := bytes.Repeat([]byte{0xab}, 1000)
+	sender := MakePlainConnection(server, server)
+	go sender.SendPrefixPacket(nil, payload)
+
+	packet, err := conn.RecvPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(packet[4:], payload) {
+		t.Errorf("Packet corrupted by short reads")
+	}
+	if faulty.Stats().ShortReads == 0 {
+		t.Errorf("No short read injected")
+	}
+}
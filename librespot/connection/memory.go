@@ -0,0 +1,87 @@
+package connection
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// MemoryTransport connects in memory, without touching the network, to a server accepting the connections of the
+// transport as a net.Listener, e.g. a fake access point in tests. The connections are synchronous, see net.Pipe.
+type MemoryTransport struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	lock   sync.Mutex
+	dialed []string
+}
+
+var _ Transport = (*MemoryTransport)(nil)
+var _ net.Listener = (*MemoryTransport)(nil)
+
+// NewMemoryTransport creates a transport whose connections are accepted with Accept
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// DialContext connects to the server accepting the connections of the transport, whatever the address, until the
+// context is done
+func (t *MemoryTransport) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	t.lock.Lock()
+	t.dialed = append(t.dialed, address)
+	t.lock.Unlock()
+
+	client, server := net.Pipe()
+	select {
+	case t.conns <- server:
+		return client, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.closed:
+		return nil, &net.OpError{Op: "dial", Net: "memory", Err: net.ErrClosed}
+	}
+}
+
+// Dialed returns the addresses dialed so far, in order
+func (t *MemoryTransport) Dialed() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]string{}, t.dialed...)
+}
+
+// Accept waits for the next connection dialed through the transport
+func (t *MemoryTransport) Accept() (net.Conn, error) {
+	select {
+	case conn := <-t.conns:
+		return conn, nil
+	case <-t.closed:
+		return nil, &net.OpError{Op: "accept", Net: "memory", Err: net.ErrClosed}
+	}
+}
+
+// Close makes the pending and future dials and accepts fail. The connections established are left open.
+func (t *MemoryTransport) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+	})
+	return nil
+}
+
+func (t *MemoryTransport) Addr() net.Addr {
+	return memoryAddr{}
+}
+
+// memoryAddr is the address of the connections of a MemoryTransport
+type memoryAddr struct{}
+
+func (memoryAddr) Network() string {
+	return "memory"
+}
+
+func (memoryAddr) String() string {
+	return "memory"
+}
@@ -0,0 +1,50 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	transport := NewMemoryTransport()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := transport.Accept()
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- conn
+	}()
+
+	conn, err := transport.DialContext(context.Background(), "ap.test:4070")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := <-accepted
+	go conn.Write([]byte("hello"))
+	buf := make([]byte, 5)
+	if _, err := server.Read(buf); err != nil || string(buf) != "hello" {
+		t.Errorf("Read %q, %v", buf, err)
+	}
+
+	// Without a server accepting the connection, the dial lasts until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := transport.DialContext(ctx, "other:443"); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if dialed := transport.Dialed(); len(dialed) != 2 || dialed[0] != "ap.test:4070" || dialed[1] != "other:443" {
+		t.Errorf("Dialed %v", dialed)
+	}
+
+	transport.Close()
+	if _, err := transport.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Expected net.ErrClosed, got %v", err)
+	}
+	if _, err := transport.DialContext(context.Background(), "ap.test:4070"); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Expected net.ErrClosed, got %v", err)
+	}
+}
@@ -1,33 +1,116 @@
 package connection
 
+import "fmt"
+
+// PacketType is the command byte identifying the packets exchanged with the access point
+type PacketType uint8
+
 const (
-	PacketSecretBlock    = 0x02
-	PacketPing           = 0x04
-	PacketStreamChunk    = 0x08
-	PacketStreamChunkRes = 0x09
-	PacketChannelError   = 0x0a
-	PacketChannelAbort   = 0x0b
-	PacketRequestKey     = 0x0c
-	PacketAesKey         = 0x0d
-	PacketAesKeyError    = 0x0e
-
-	PacketImage       = 0x19
-	PacketCountryCode = 0x1b
-
-	PacketPong    = 0x49
-	PacketPongAck = 0x4a
-	PacketPause   = 0x4b
-
-	PacketProductInfo   = 0x50
-	PacketLegacyWelcome = 0x69
-
-	PacketLicenseVersion = 0x76
-
-	PacketLogin       = 0xab
-	PacketAPWelcome   = 0xac
-	PacketAuthFailure = 0xad
-
-	PacketMercuryReq   = 0xb2
-	PacketMercurySub   = 0xb3
-	PacketMercuryUnsub = 0xb4
+	// PacketSecretBlock holds the old RSA public key, sent after login
+	PacketSecretBlock PacketType = 0x02
+	// PacketPing is sent by the server every 2 minutes, and must be answered with PacketPong
+	PacketPing PacketType = 0x04
+	// PacketStreamChunk requests a chunk of an audio file over a channel
+	PacketStreamChunk PacketType = 0x08
+	// PacketStreamChunkRes carries the headers and data of a channel
+	PacketStreamChunkRes PacketType = 0x09
+	// PacketChannelError aborts a channel, with an error code
+	PacketChannelError PacketType = 0x0a
+	// PacketChannelAbort aborts a channel
+	PacketChannelAbort PacketType = 0x0b
+	// PacketRequestKey requests the audio key of a file
+	PacketRequestKey PacketType = 0x0c
+	// PacketAesKey answers PacketRequestKey with the audio key
+	PacketAesKey PacketType = 0x0d
+	// PacketAesKeyError answers PacketRequestKey when the key is refused
+	PacketAesKeyError PacketType = 0x0e
+
+	// PacketImage requests a cover art image over a channel
+	PacketImage PacketType = 0x19
+	// PacketCountryCode holds the country of the user
+	PacketCountryCode PacketType = 0x1b
+	// PacketUnknownDataAllZeros is sent after login, its data is zeroes only
+	PacketUnknownDataAllZeros PacketType = 0x1f
+
+	// PacketPong answers PacketPing
+	PacketPong PacketType = 0x49
+	// PacketPongAck acknowledges PacketPong
+	PacketPongAck PacketType = 0x4a
+	// PacketPause is sent when the account starts playing on another device
+	PacketPause PacketType = 0x4b
+
+	// PacketProductInfo holds the product and A/B testing setup of the account, as XML
+	PacketProductInfo PacketType = 0x50
+	// PacketLegacyWelcome is an empty welcome packet
+	PacketLegacyWelcome PacketType = 0x69
+
+	// PacketPreferredLocale sets the language of the metadata
+	PacketPreferredLocale PacketType = 0x74
+
+	// PacketLicenseVersion holds the current Spotify license version
+	PacketLicenseVersion PacketType = 0x76
+
+	// PacketTrackEndedTime reports the playback time of a track
+	PacketTrackEndedTime PacketType = 0x82
+
+	// PacketLogin holds the ClientResponseEncrypted authenticating the user
+	PacketLogin PacketType = 0xab
+	// PacketAPWelcome answers a successful PacketLogin
+	PacketAPWelcome PacketType = 0xac
+	// PacketAuthFailure answers a failed PacketLogin
+	PacketAuthFailure PacketType = 0xad
+
+	// PacketMercuryReq is a mercury request or response
+	PacketMercuryReq PacketType = 0xb2
+	// PacketMercurySub subscribes to a mercury uri
+	PacketMercurySub PacketType = 0xb3
+	// PacketMercuryUnsub unsubscribes from a mercury uri
+	PacketMercuryUnsub PacketType = 0xb4
+	// PacketMercuryEvent is a message published on a subscribed mercury uri
+	PacketMercuryEvent PacketType = 0xb5
+	// PacketMercuryUnknown is a mercury packet of unknown meaning
+	PacketMercuryUnknown PacketType = 0xb6
 )
+
+var packetNames = map[PacketType]string{
+	PacketSecretBlock:         "SecretBlock",
+	PacketPing:                "Ping",
+	PacketStreamChunk:         "StreamChunk",
+	PacketStreamChunkRes:      "StreamChunkRes",
+	PacketChannelError:        "ChannelError",
+	PacketChannelAbort:        "ChannelAbort",
+	PacketRequestKey:          "RequestKey",
+	PacketAesKey:              "AesKey",
+	PacketAesKeyError:         "AesKeyError",
+	PacketImage:               "Image",
+	PacketCountryCode:         "CountryCode",
+	PacketUnknownDataAllZeros: "UnknownDataAllZeros",
+	PacketPong:                "Pong",
+	PacketPongAck:             "PongAck",
+	PacketPause:               "Pause",
+	PacketProductInfo:         "ProductInfo",
+	PacketLegacyWelcome:       "LegacyWelcome",
+	PacketPreferredLocale:     "PreferredLocale",
+	PacketLicenseVersion:      "LicenseVersion",
+	PacketTrackEndedTime:      "TrackEndedTime",
+	PacketLogin:               "Login",
+	PacketAPWelcome:           "APWelcome",
+	PacketAuthFailure:         "AuthFailure",
+	PacketMercuryReq:          "MercuryReq",
+	PacketMercurySub:          "MercurySub",
+	PacketMercuryUnsub:        "MercuryUnsub",
+	PacketMercuryEvent:        "MercuryEvent",
+	PacketMercuryUnknown:      "MercuryUnknown",
+}
+
+func (p PacketType) String() string {
+	if name, ok := packetNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("PacketType(0x%02x)", uint8(p))
+}
+
+// IsMercury returns whether the packet belongs to the mercury protocol
+func (p PacketType) IsMercury() bool {
+	return PacketMercuryReq <= p && p <= PacketMercuryUnknown
+}
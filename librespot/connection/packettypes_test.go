@@ -0,0 +1,15 @@
+package connection
+
+import "testing"
+
+func TestPacketTypeString(t *testing.T) {
+	if s := PacketMercuryEvent.String(); s != "MercuryEvent" {
+		t.Errorf("Bad name %s", s)
+	}
+	if s := PacketType(0xfe).String(); s != "PacketType(0xfe)" {
+		t.Errorf("Bad name for an unknown packet %s", s)
+	}
+	if !PacketMercuryUnsub.IsMercury() || PacketPing.IsMercury() {
+		t.Errorf("Bad mercury packet detection")
+	}
+}
@@ -0,0 +1,273 @@
+package connection
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ProxyFunc returns the URL of the proxy to connect through to reach the specified address, in the host:port form,
+// or nil to connect directly. The http, https (HTTP CONNECT) and socks5 schemes are supported.
+type ProxyFunc func(address string) (*url.URL, error)
+
+// ProxyFromEnvironment uses the proxy configured by the HTTPS_PROXY environment variable, except for the hosts
+// listed in NO_PROXY
+func ProxyFromEnvironment(address string) (*url.URL, error) {
+	return http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: "https", Host: address}})
+}
+
+// ProxyURL always connects through the proxy at the specified URL
+func ProxyURL(proxy *url.URL) ProxyFunc {
+	return func(string) (*url.URL, error) {
+		return proxy, nil
+	}
+}
+
+// proxyFor returns the proxy to use to reach the address, nil if it must be reached directly
+func (d *Dialer) proxyFor(address string) (*url.URL, error) {
+	if d.Proxy == nil {
+		return nil, nil
+	}
+	proxy, err := d.Proxy(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy for %s: %v", address, err)
+	}
+	return proxy, nil
+}
+
+// dialProxy connects to the address through the proxy
+func (d *Dialer) dialProxy(ctx context.Context, proxy *url.URL, address string) (net.Conn, error) {
+	proxyAddress := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddress = net.JoinHostPort(proxy.Hostname(), defaultProxyPort(proxy.Scheme))
+	}
+
+	conn, err := d.dialDirect(ctx, proxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %v", proxyAddress, err)
+	}
+
+	// The handshake must not outlive the dial
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tunnel := conn
+	switch proxy.Scheme {
+	case "http":
+		tunnel, err = httpConnect(conn, proxy, address)
+	case "https":
+		conn = d.TLSClient(conn, proxy.Hostname())
+		tunnel, err = httpConnect(conn, proxy, address)
+	case "socks5", "socks5h":
+		err = socks5Connect(conn, proxy, address)
+	default:
+		err = fmt.Errorf("unsupported scheme %q", proxy.Scheme)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %v", proxyAddress, err)
+	}
+
+	tunnel.SetDeadline(time.Time{})
+	return tunnel, nil
+}
+
+func defaultProxyPort(scheme string) string {
+	switch scheme {
+	case "https":
+		return "443"
+	case "socks5", "socks5h":
+		return "1080"
+	default:
+		return "80"
+	}
+}
+
+// httpConnect asks an HTTP proxy to open a tunnel to the address
+func httpConnect(conn net.Conn, proxy *url.URL, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CONNECT to %s failed: %s", address, resp.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		// Don't lose the data the server may already have sent through the tunnel
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were read into a buffer
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// SOCKS5 protocol constants, see RFC 1928 and RFC 1929
+const (
+	socks5Version      = 0x05
+	socks5NoAuth       = 0x00
+	socks5PasswordAuth = 0x02
+	socks5NoAcceptable = 0xff
+	socks5CmdConnect   = 0x01
+	socks5AddrIPv4     = 0x01
+	socks5AddrDomain   = 0x03
+	socks5AddrIPv6     = 0x04
+)
+
+var socks5Errors = []string{
+	"",
+	"general failure",
+	"connection not allowed by ruleset",
+	"network unreachable",
+	"host unreachable",
+	"connection refused",
+	"TTL expired",
+	"command not supported",
+	"address type not supported",
+}
+
+// socks5Connect asks a SOCKS5 proxy to connect to the address. The hostname is resolved by the proxy.
+func socks5Connect(conn net.Conn, proxy *url.URL, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 0xffff {
+		return fmt.Errorf("invalid port %q", portStr)
+	}
+
+	methods := []byte{socks5Version, 1, socks5NoAuth}
+	if proxy.User != nil {
+		methods = []byte{socks5Version, 2, socks5NoAuth, socks5PasswordAuth}
+	}
+	if _, err := conn.Write(methods); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("unexpected SOCKS version %d", reply[0])
+	}
+
+	switch reply[1] {
+	case socks5NoAuth:
+	case socks5PasswordAuth:
+		if proxy.User == nil {
+			return errors.New("SOCKS5 proxy requires authentication")
+		}
+		username := proxy.User.Username()
+		password, _ := proxy.User.Password()
+		if len(username) > 255 || len(password) > 255 {
+			return errors.New("SOCKS5 username or password too long")
+		}
+
+		auth := []byte{0x01, byte(len(username))}
+		auth = append(auth, username...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0 {
+			return errors.New("SOCKS5 authentication failed")
+		}
+	case socks5NoAcceptable:
+		return errors.New("no acceptable SOCKS5 authentication method")
+	default:
+		return fmt.Errorf("unexpected SOCKS5 authentication method %d", reply[1])
+	}
+
+	req := []byte{socks5Version, socks5CmdConnect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("hostname %s too long", host)
+		}
+		req = append(req, socks5AddrDomain, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, socks5AddrIPv4)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, socks5AddrIPv6)
+		req = append(req, ip.To16()...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0 {
+		if int(header[1]) < len(socks5Errors) {
+			return fmt.Errorf("SOCKS5 connect to %s failed: %s", address, socks5Errors[header[1]])
+		}
+		return fmt.Errorf("SOCKS5 connect to %s failed: error %d", address, header[1])
+	}
+
+	// Skip the address the proxy bound to
+	var skip int
+	switch header[3] {
+	case socks5AddrIPv4:
+		skip = net.IPv4len
+	case socks5AddrIPv6:
+		skip = net.IPv6len
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("unexpected SOCKS5 address type %d", header[3])
+	}
+	bound := make([]byte, skip+2)
+	if _, err := io.ReadFull(conn, bound); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package connection
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// echoServer accepts connections and sends back whatever it receives
+func echoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return listener
+}
+
+// fakeProxy accepts a single connection, performs the proxy handshake with handshake, which returns the requested
+// address, and then forwards the data to that address
+func fakeProxy(t *testing.T, handshake func(conn net.Conn) string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		address := handshake(conn)
+		if address == "" {
+			return
+		}
+		target, err := net.Dial("tcp", address)
+		if err != nil {
+			return
+		}
+		defer target.Close()
+		go io.Copy(target, conn)
+		io.Copy(conn, target)
+	}()
+	return listener
+}
+
+func checkEcho(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got %q through the proxy", buf)
+	}
+}
+
+func TestDialHTTPProxy(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+
+	proxy := fakeProxy(t, func(conn net.Conn) string {
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil || req.Method != "CONNECT" {
+			return ""
+		}
+		if req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+			return ""
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		return req.Host
+	})
+	defer proxy.Close()
+
+	dialer := NewDialer()
+	dialer.Proxy = ProxyURL(&url.URL{Scheme: "http", Host: proxy.Addr().String(), User: url.UserPassword("user", "pass")})
+
+	conn, err := dialer.Dial(echo.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEcho(t, conn)
+}
+
+func TestDialSocks5Proxy(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+
+	proxy := fakeProxy(t, func(conn net.Conn) string {
+		// Greeting: only no authentication is offered
+		greeting := make([]byte, 3)
+		if _, err := io.ReadFull(conn, greeting); err != nil || !bytes.Equal(greeting, []byte{5, 1, 0}) {
+			return ""
+		}
+		conn.Write([]byte{5, 0})
+
+		// Connect request, with a domain name
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(conn, header); err != nil || header[3] != socks5AddrDomain {
+			return ""
+		}
+		rest := make([]byte, int(header[4])+2)
+		if _, err := io.ReadFull(conn, rest); err != nil {
+			return ""
+		}
+		host := string(rest[:header[4]])
+		port := binary.BigEndian.Uint16(rest[header[4]:])
+		if host != "ap.example" {
+			conn.Write([]byte{5, 4, 0, 1, 0, 0, 0, 0, 0, 0})
+			return ""
+		}
+
+		conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+		return net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port)))
+	})
+	defer proxy.Close()
+
+	dialer := NewDialer()
+	// The hostname must be resolved by the proxy, not by the dialer
+	dialer.Resolver = staticResolver{}
+	dialer.Proxy = ProxyURL(&url.URL{Scheme: "socks5", Host: proxy.Addr().String()})
+
+	_, port, _ := net.SplitHostPort(echo.Addr().String())
+	conn, err := dialer.Dial(net.JoinHostPort("ap.example", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEcho(t, conn)
+}
+
+func TestDialProxyRefused(t *testing.T) {
+	proxy := fakeProxy(t, func(conn net.Conn) string {
+		if _, err := http.ReadRequest(bufio.NewReader(conn)); err == nil {
+			conn.Write([]byte("HTTP/1.1 403 Forbidden\r\n\r\n"))
+		}
+		return ""
+	})
+	defer proxy.Close()
+
+	dialer := NewDialer()
+	dialer.Proxy = ProxyURL(&url.URL{Scheme: "http", Host: proxy.Addr().String()})
+
+	if _, err := dialer.Dial("ap.example:4070"); err == nil {
+		t.Errorf("Dial should fail when the proxy refuses the connection")
+	}
+}
@@ -0,0 +1,60 @@
+package connection
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Resolver resolves hostnames to IP addresses. *net.Resolver implements it, and can be configured to query a
+// specific DNS server. Custom implementations can be used to resolve through DNS-over-HTTPS, DNS-over-TLS, a static
+// table, etc.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// DefaultResolver is the resolver used for all the hostname lookups performed by the library (access points, HTTP
+// APIs). It must be changed before creating any session.
+var DefaultResolver Resolver = net.DefaultResolver
+
+// HTTPClient returns an HTTP client connecting through this dialer, so that the hostnames are resolved with its
+// resolver, the requests go through its proxy and use its TLS configuration
+func (d *Dialer) HTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: d.Timeout,
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return d.proxyFor(canonicalAddr(req.URL))
+			},
+			// The transport connects to the proxy itself
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return d.dialDirect(ctx, address)
+			},
+			TLSClientConfig:     d.tlsConfig(""),
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// canonicalAddr returns the host:port address of the URL, with the default port of its scheme if needed
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" || u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func (d *Dialer) resolver() Resolver {
+	if d.Resolver != nil {
+		return d.Resolver
+	}
+	return DefaultResolver
+}
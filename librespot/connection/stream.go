@@ -1,6 +1,6 @@
 package connection
 
 type PacketStream interface {
-	SendPacket(cmd uint8, data []byte) (err error)
-	RecvPacket() (cmd uint8, buf []byte, err error)
+	SendPacket(cmd PacketType, data []byte) (err error)
+	RecvPacket() (cmd PacketType, buf []byte, err error)
 }
@@ -0,0 +1,24 @@
+package connection
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// TLSHandshake starts a TLS session over the connection to a proxy, with the TLS configuration of this dialer, and
+// waits for the handshake to complete until the context is done. The access points don't speak TLS, their keys are
+// verified by the handshake of the session instead.
+func (d *Dialer) TLSHandshake(ctx context.Context, conn net.Conn, serverName string) (*tls.Conn, error) {
+	tlsConn := tls.Client(conn, d.tlsConfig(serverName))
+
+	if deadline, ok := ctx.Deadline(); ok {
+		tlsConn.SetDeadline(deadline)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
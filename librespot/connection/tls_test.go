@@ -0,0 +1,41 @@
+package connection
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTLSHandshake(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	handshake := func(dialer *Dialer) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		_, err = dialer.TLSHandshake(ctx, conn, "example.com")
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	dialer := NewDialer()
+	dialer.TLSConfig = &tls.Config{RootCAs: roots}
+	if err := handshake(dialer); err != nil {
+		t.Errorf("Handshake with a trusted certificate failed: %v", err)
+	}
+	if err := handshake(NewDialer()); err == nil {
+		t.Errorf("Handshake with an untrusted certificate succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// Transport establishes the connections to the access points, over which the handshake and the encrypted packets are
+// exchanged. *Dialer is the default transport, connecting over TCP.
+type Transport interface {
+	// DialContext connects to the access point at address, in the host:port form, until the context is done
+	DialContext(ctx context.Context, address string) (net.Conn, error)
+}
+
+var _ Transport = (*Dialer)(nil)
+
+// WebSocketTransport reaches the access points through a WebSocket proxy relaying the binary messages to their TCP
+// connection, for the platforms where raw TCP isn't available, e.g. browsers running WASM or gopherjs builds. The
+// bytes of the connection are sent as binary messages, whose boundaries don't matter.
+type WebSocketTransport struct {
+	// URL is the ws:// or wss:// URL of the proxy. The address of the access point is added as its ap query
+	// parameter, which the proxies forwarding to a fixed access point can ignore.
+	URL string
+	// Origin is sent with the handshake, for the proxies checking it. The browsers set it themselves.
+	Origin string
+	// Dialer establishes the connections to the proxy, except in browsers. NewDialer is used if nil.
+	Dialer *Dialer
+}
+
+// NewWebSocketTransport creates a transport through the WebSocket proxy at the specified URL
+func NewWebSocketTransport(proxyURL string) *WebSocketTransport {
+	return &WebSocketTransport{URL: proxyURL}
+}
+
+// location returns the URL to connect to, to reach the access point at address
+func (t *WebSocketTransport) location(address string) (*url.URL, error) {
+	location, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebSocket proxy URL: %v", err)
+	}
+	if location.Scheme != "ws" && location.Scheme != "wss" {
+		return nil, fmt.Errorf("invalid WebSocket proxy URL scheme %q", location.Scheme)
+	}
+	query := location.Query()
+	query.Set("ap", address)
+	location.RawQuery = query.Encode()
+	return location, nil
+}
@@ -0,0 +1,75 @@
+//go:build !js
+// +build !js
+
+package connection
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// DialContext connects to the proxy, and asks it to relay the connection to the access point at address
+func (t *WebSocketTransport) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	location, err := t.location(address)
+	if err != nil {
+		return nil, err
+	}
+	config, err := websocket.NewConfig(location.String(), t.origin(location))
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := t.Dialer
+	if dialer == nil {
+		dialer = NewDialer()
+	}
+	proxyAddress := location.Host
+	if location.Port() == "" {
+		if location.Scheme == "wss" {
+			proxyAddress = net.JoinHostPort(location.Hostname(), "443")
+		} else {
+			proxyAddress = net.JoinHostPort(location.Hostname(), "80")
+		}
+	}
+	conn, err := dialer.DialContext(ctx, proxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to WebSocket proxy %s: %v", proxyAddress, err)
+	}
+	if location.Scheme == "wss" {
+		tlsConn, err := dialer.TLSHandshake(ctx, conn, location.Hostname())
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("WebSocket proxy TLS handshake failed: %v", err)
+		}
+		conn = tlsConn
+	}
+
+	// The handshake is bounded by the context, like the connection
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket proxy handshake failed: %v", err)
+	}
+	conn.SetDeadline(time.Time{})
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
+
+// origin returns the origin sent with the handshake, the one of the proxy if not configured
+func (t *WebSocketTransport) origin(location *url.URL) string {
+	if t.Origin != "" {
+		return t.Origin
+	}
+	if location.Scheme == "wss" {
+		return "https://" + location.Host
+	}
+	return "http://" + location.Host
+}
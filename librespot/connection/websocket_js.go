@@ -0,0 +1,197 @@
+//go:build js
+// +build js
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// DialContext opens a WebSocket of the browser to the proxy, asking it to relay the connection to the access point at
+// address
+func (t *WebSocketTransport) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	location, err := t.location(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := js.Global().Get("WebSocket").New(location.String())
+	ws.Set("binaryType", "arraybuffer")
+	c := &jsWebSocket{ws: ws, addr: webSocketAddr(location.String()), opened: make(chan error, 1)}
+	c.cond = sync.NewCond(&c.lock)
+	c.handle("onopen", func(event js.Value) {
+		c.open(nil)
+	})
+	c.handle("onmessage", func(event js.Value) {
+		array := js.Global().Get("Uint8Array").New(event.Get("data"))
+		data := make([]byte, array.Get("length").Int())
+		js.CopyBytesToGo(data, array)
+
+		c.lock.Lock()
+		c.pending = append(c.pending, data...)
+		c.cond.Broadcast()
+		c.lock.Unlock()
+	})
+	c.handle("onclose", func(event js.Value) {
+		c.open(errors.New("WebSocket proxy closed the connection"))
+		c.fail(io.EOF)
+	})
+
+	select {
+	case err := <-c.opened:
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		return c, nil
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// jsWebSocket is a connection over a WebSocket of the browser. The callbacks of the WebSocket run on the event loop,
+// and must not block.
+type jsWebSocket struct {
+	ws     js.Value
+	addr   webSocketAddr
+	funcs  []js.Func
+	opened chan error
+
+	lock     sync.Mutex
+	cond     *sync.Cond
+	pending  []byte
+	err      error
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// handle sets an event handler of the WebSocket
+func (c *jsWebSocket) handle(name string, handler func(event js.Value)) {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		handler(args[0])
+		return nil
+	})
+	c.funcs = append(c.funcs, f)
+	c.ws.Set(name, f)
+}
+
+// open reports the outcome of the opening of the WebSocket, only the first one being kept
+func (c *jsWebSocket) open(err error) {
+	select {
+	case c.opened <- err:
+	default:
+	}
+}
+
+// fail makes the reads fail with err once the data received is read
+func (c *jsWebSocket) fail(err error) {
+	c.lock.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.cond.Broadcast()
+	c.lock.Unlock()
+}
+
+func (c *jsWebSocket) Read(b []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for len(c.pending) == 0 && c.err == nil {
+		if !c.deadline.IsZero() && !time.Now().Before(c.deadline) {
+			return 0, os.ErrDeadlineExceeded
+		}
+		c.cond.Wait()
+	}
+	if len(c.pending) == 0 {
+		return 0, c.err
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *jsWebSocket) Write(b []byte) (int, error) {
+	c.lock.Lock()
+	err := c.err
+	c.lock.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	// The data is buffered by the browser, the writes don't block
+	array := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(array, b)
+	c.ws.Call("send", array)
+	return len(b), nil
+}
+
+func (c *jsWebSocket) Close() error {
+	c.fail(net.ErrClosed)
+	c.ws.Call("close")
+	for _, name := range []string{"onopen", "onmessage", "onclose"} {
+		c.ws.Set(name, js.Null())
+	}
+	for _, f := range c.funcs {
+		f.Release()
+	}
+	c.funcs = nil
+	return nil
+}
+
+func (c *jsWebSocket) LocalAddr() net.Addr {
+	return c.addr
+}
+
+func (c *jsWebSocket) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// SetDeadline only sets the read deadline, the writes never block
+func (c *jsWebSocket) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *jsWebSocket) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deadline = t
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if !t.IsZero() {
+		// Wake up the reads once the deadline passes
+		c.timer = time.AfterFunc(time.Until(t), func() {
+			c.lock.Lock()
+			c.cond.Broadcast()
+			c.lock.Unlock()
+		})
+	}
+	c.cond.Broadcast()
+	return nil
+}
+
+func (c *jsWebSocket) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// webSocketAddr is the address of a WebSocket connection, its URL
+type webSocketAddr string
+
+func (a webSocketAddr) Network() string {
+	return "websocket"
+}
+
+func (a webSocketAddr) String() string {
+	return string(a)
+}
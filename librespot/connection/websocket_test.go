@@ -0,0 +1,61 @@
+package connection
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// webSocketProxy relays the binary messages of the WebSockets to the TCP connection of the address in their ap query
+// parameter
+func webSocketProxy() *httptest.Server {
+	return httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		ws.PayloadType = websocket.BinaryFrame
+		target, err := net.Dial("tcp", ws.Request().URL.Query().Get("ap"))
+		if err != nil {
+			return
+		}
+		defer target.Close()
+		go io.Copy(target, ws)
+		io.Copy(ws, target)
+	}))
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+	proxy := webSocketProxy()
+	defer proxy.Close()
+
+	transport := NewWebSocketTransport(strings.Replace(proxy.URL, "http://", "ws://", 1) + "/ap")
+	transport.Dialer = &Dialer{Timeout: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := transport.DialContext(ctx, echo.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The message boundaries don't matter
+	if _, err := conn.Write([]byte("hello ")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 11)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "hello world" {
+		t.Errorf("Got %q, %v", buf, err)
+	}
+
+	if _, err := NewWebSocketTransport("http://proxy").DialContext(ctx, "ap:4070"); err == nil {
+		t.Errorf("Expected an error for a non WebSocket URL")
+	}
+}
@@ -0,0 +1,188 @@
+package connection
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultWireLogDumpSize is the number of bytes of each packet dumped by a WireLog
+const DefaultWireLogDumpSize = 256
+
+// secretPackets carry credentials or keys in their whole payload, which is never dumped
+var secretPackets = map[PacketType]bool{
+	PacketSecretBlock: true,
+	PacketLogin:       true,
+	PacketAPWelcome:   true,
+	PacketAesKey:      true,
+}
+
+// secretPatterns match the secrets found in the packets, URLs and headers. The secret itself is the last group.
+var secretPatterns = []*regexp.Regexp{
+	// JSON fields, e.g. the keymaster and login5 responses
+	regexp.MustCompile(`(?i)"(access_?token|refresh_?token|client_?secret|password|token|auth_?data)"\s*:\s*"([^"]+)"`),
+	// Authorization headers
+	regexp.MustCompile(`(?i)(Bearer|Basic) ([A-Za-z0-9._~+/=-]+)`),
+	// URL query parameters
+	regexp.MustCompile(`(?i)([?&](access_token|token|code|client_secret)=)([^&\s]+)`),
+}
+
+// secretHeaders are the HTTP headers whose values are never logged
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Client-Token":        true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Proxy-Authorization": true,
+}
+
+// WireLog logs the traffic of a session for debugging: hex dumps of the decrypted packets, the mercury requests and
+// the HTTP requests. The credentials, tokens and keys are redacted, so that the log can be attached to bug reports.
+// A nil *WireLog logs nothing.
+type WireLog struct {
+	logger *log.Logger
+	// DumpSize is the number of bytes of each packet dumped, the whole packet if negative
+	DumpSize int
+}
+
+// NewWireLog creates a wire log writing to the logger
+func NewWireLog(logger *log.Logger) *WireLog {
+	return &WireLog{logger: logger, DumpSize: DefaultWireLogDumpSize}
+}
+
+// Printf logs a message, with its secrets redacted
+func (w *WireLog) Printf(format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	w.logger.Print(Redact(fmt.Sprintf(format, args...)))
+}
+
+// Packet logs a packet sent or received, direction being "send" or "recv"
+func (w *WireLog) Packet(direction string, cmd PacketType, data []byte) {
+	if w == nil {
+		return
+	}
+	if cmd.IsSecret() {
+		w.logger.Printf("%s %v (%d bytes): [redacted]", direction, cmd, len(data))
+		return
+	}
+
+	dump := RedactBytes(data)
+	if w.DumpSize >= 0 && len(dump) > w.DumpSize {
+		dump = dump[:w.DumpSize]
+	}
+	w.logger.Printf("%s %v (%d bytes)\n%s", direction, cmd, len(data), hex.Dump(dump))
+}
+
+// Request logs an HTTP request, and its response or error
+func (w *WireLog) Request(req *http.Request, res *http.Response, err error) {
+	if w == nil {
+		return
+	}
+	msg := fmt.Sprintf("http %s %s%s", req.Method, Redact(req.URL.String()), formatHeaders(req.Header))
+	if err != nil {
+		msg += fmt.Sprintf("\n-> error: %v", err)
+	} else if res != nil {
+		msg += fmt.Sprintf("\n-> %s%s", res.Status, formatHeaders(res.Header))
+	}
+	w.logger.Print(Redact(msg))
+}
+
+// formatHeaders formats the headers one per line, sorted, with the values of the secret ones redacted
+func formatHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		if secretHeaders[http.CanonicalHeaderKey(key)] {
+			value = "[redacted]"
+		}
+		fmt.Fprintf(&b, "\n  %s: %s", key, value)
+	}
+	return b.String()
+}
+
+// Transport wraps an HTTP transport so that its requests are logged, http.DefaultTransport if nil
+func (w *WireLog) Transport(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if w == nil {
+		return transport
+	}
+	return &wireLogTransport{transport: transport, log: w}
+}
+
+type wireLogTransport struct {
+	transport http.RoundTripper
+	log       *WireLog
+}
+
+func (t *wireLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	t.log.Request(req, res, err)
+	return res, err
+}
+
+// IsSecret returns whether the whole payload of the packet is secret, credentials or keys, so that it must never be
+// logged
+func (p PacketType) IsSecret() bool {
+	return secretPackets[p]
+}
+
+// Redact replaces the secrets found in the string (tokens in JSON, Authorization headers and URL parameters) with
+// asterisks
+func Redact(s string) string {
+	return string(RedactBytes([]byte(s)))
+}
+
+// RedactBytes returns a copy of the data with the secrets replaced with asterisks of the same length, so that the
+// structure of binary messages is preserved
+func RedactBytes(data []byte) []byte {
+	redacted := append([]byte{}, data...)
+	for _, pattern := range secretPatterns {
+		for _, match := range pattern.FindAllSubmatchIndex(redacted, -1) {
+			start, end := match[len(match)-2], match[len(match)-1]
+			for i := start; i < end; i++ {
+				redacted[i] = '*'
+			}
+		}
+	}
+	return redacted
+}
+
+// LoggingStream is a PacketStream logging the packets sent and received to a WireLog
+type LoggingStream struct {
+	stream PacketStream
+	log    *WireLog
+}
+
+// NewLoggingStream wraps the stream, logging its packets
+func NewLoggingStream(stream PacketStream, log *WireLog) *LoggingStream {
+	return &LoggingStream{stream: stream, log: log}
+}
+
+func (s *LoggingStream) SendPacket(cmd PacketType, data []byte) error {
+	s.log.Packet("send", cmd, data)
+	return s.stream.SendPacket(cmd, data)
+}
+
+func (s *LoggingStream) RecvPacket() (PacketType, []byte, error) {
+	cmd, data, err := s.stream.RecvPacket()
+	if err != nil {
+		s.log.Printf("recv error: %v", err)
+	} else {
+		s.log.Packet("recv", cmd, data)
+	}
+	return cmd, data, err
+}
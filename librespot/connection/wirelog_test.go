@@ -0,0 +1,82 @@
+package connection
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := map[string]string{
+		`{"accessToken":"BQD-secret","expiresIn":3600}`: `{"accessToken":"**********","expiresIn":3600}`,
+		`Authorization: Bearer abc.def`:                 `Authorization: Bearer *******`,
+		`https://api/x?market=FR&access_token=secret`:   `https://api/x?market=FR&access_token=******`,
+		`spotify:track:0065zxtT6XKaQww7cLne0h`:          `spotify:track:0065zxtT6XKaQww7cLne0h`,
+	}
+	for input, expected := range tests {
+		if redacted := Redact(input); redacted != expected {
+			t.Errorf("Redact(%q) = %q, want %q", input, redacted, expected)
+		}
+	}
+
+	data := []byte("\x00\x01\"token\": \"secret\"\xff")
+	redacted := RedactBytes(data)
+	if len(redacted) != len(data) || bytes.Contains(redacted, []byte("secret")) || data[12] != 's' {
+		t.Errorf("Bad redaction %q of %q", redacted, data)
+	}
+}
+
+func TestLoggingStream(t *testing.T) {
+	var out bytes.Buffer
+	stream := NewLoggingStream(&recordingStream{}, NewWireLog(log.New(&out, "", 0)))
+
+	stream.SendPacket(PacketLogin, []byte("password"))
+	stream.SendPacket(PacketMercuryReq, []byte(`hm://keymaster/token {"accessToken":"abcdef"}`))
+	stream.RecvPacket()
+
+	logged := out.String()
+	for _, secret := range []string{"password", "abcdef", "70 61 73 73"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("Secret %q logged:\n%s", secret, logged)
+		}
+	}
+	for _, expected := range []string{"send Login (8 bytes): [redacted]", "hm://keymaster", "recv"} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("%q not logged:\n%s", expected, logged)
+		}
+	}
+}
+
+func TestWireLogTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := &http.Client{Transport: NewWireLog(log.New(&out, "", 0)).Transport(nil)}
+	req, _ := http.NewRequest("GET", server.URL+"/storage-resolve?token=secret", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("client-token", "secret")
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	logged := out.String()
+	if strings.Contains(logged, "secret") || !strings.Contains(logged, "GET "+server.URL+"/storage-resolve") ||
+		!strings.Contains(logged, "418") {
+		t.Errorf("Bad log:\n%s", logged)
+	}
+
+	var nilLog *WireLog
+	nilLog.Printf("nothing")
+	if nilLog.Transport(http.DefaultTransport) != http.DefaultTransport {
+		t.Errorf("Nil wire log wraps the transport")
+	}
+}
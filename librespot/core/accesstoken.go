@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// streamingScope is the scope the access point requires from the access tokens it accepts
+const streamingScope = "streaming"
+
+// ErrInvalidAccessToken is matched by the AccessTokenErrors, returned when logging in with a token which cannot be
+// sent to the access point
+var ErrInvalidAccessToken = errors.New("invalid access token")
+
+// ErrAccessTokenExpired is matched by the AccessTokenErrors of the tokens known to be expired
+var ErrAccessTokenExpired = errors.New("access token expired")
+
+// AccessToken is an OAuth access token of the user, e.g. obtained by the own OAuth flow of an application, with what
+// is known about it
+type AccessToken struct {
+	// Token is the access token, with or without the "Bearer " prefix
+	Token string
+	// Expiry is when the token expires, zero if unknown
+	Expiry time.Time
+	// Scopes are the scopes granted to the token, nil if unknown
+	Scopes []string
+}
+
+// Value returns the token without its "Bearer " prefix and surrounding spaces
+func (t AccessToken) Value() string {
+	value := strings.TrimSpace(t.Token)
+	if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+		value = strings.TrimSpace(value[7:])
+	}
+	return value
+}
+
+// Expired tells whether the token is known to be expired at the specified time
+func (t AccessToken) Expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Before(t.Expiry)
+}
+
+// AccessTokenError is returned when logging in with an access token which cannot be used. It matches
+// ErrInvalidAccessToken with errors.Is, or ErrAccessTokenExpired if the token is expired.
+type AccessTokenError struct {
+	Reason  string
+	Expired bool
+}
+
+func (e *AccessTokenError) Error() string {
+	return "invalid access token: " + e.Reason
+}
+
+// Is matches ErrInvalidAccessToken, or ErrAccessTokenExpired for the expired tokens
+func (e *AccessTokenError) Is(target error) bool {
+	return target == ErrInvalidAccessToken || (e.Expired && target == ErrAccessTokenExpired)
+}
+
+// Validate checks that the token can be used to log in at the specified time: it must be well-formed, not be expired,
+// and be granted the streaming scope if its scopes are known. The error returned is an *AccessTokenError.
+func (t AccessToken) Validate(now time.Time) error {
+	value := t.Value()
+	if value == "" {
+		return &AccessTokenError{Reason: "empty token"}
+	}
+	for _, c := range value {
+		if c <= ' ' || c >= 0x7f {
+			return &AccessTokenError{Reason: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	if t.Expired(now) {
+		return &AccessTokenError{Reason: "expired at " + t.Expiry.Format(time.RFC3339), Expired: true}
+	}
+	if t.Scopes != nil {
+		for _, scope := range t.Scopes {
+			if scope == streamingScope {
+				return nil
+			}
+		}
+		return &AccessTokenError{Reason: "missing the " + streamingScope + " scope"}
+	}
+	return nil
+}
+
+// Credentials returns the credentials logging in with the token. The user is identified by the token, so the username
+// is empty.
+func (t AccessToken) Credentials() Credentials {
+	return Credentials{
+		AuthType: Spotify.AuthenticationType_AUTHENTICATION_SPOTIFY_TOKEN,
+		AuthData: []byte(t.Value()),
+	}
+}
+
+// LoginWithAccessToken logs in to Spotify with an access token obtained elsewhere, e.g. by the OAuth flow of the
+// application. The token is validated before connecting to an access point.
+func LoginWithAccessToken(token AccessToken, deviceName string) (*Session, error) {
+	if err := token.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	s, err := NewSession(SessionConfig{DeviceName: deviceName})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.LoginWithAccessToken(token)
+	if err != nil {
+		s.disconnect()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// LoginWithAccessToken authenticates the session with an access token obtained elsewhere, see the function of the
+// same name. The reusable credentials received can then be used to log in again once the token expired.
+func (s *Session) LoginWithAccessToken(token AccessToken) error {
+	if err := token.Validate(s.Now()); err != nil {
+		return err
+	}
+	credentials := token.Credentials()
+	return s.loginBlob(credentials.Username, credentials.AuthData, credentials.AuthType.Enum())
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestAccessTokenValidate(t *testing.T) {
+	now := time.Unix(1000, 0)
+	for _, test := range []struct {
+		token    AccessToken
+		expected error
+	}{
+		{AccessToken{Token: "abc"}, nil},
+		{AccessToken{Token: " Bearer abc ", Expiry: now.Add(time.Minute), Scopes: []string{"streaming"}}, nil},
+		{AccessToken{Token: "  "}, ErrInvalidAccessToken},
+		{AccessToken{Token: "a\nb"}, ErrInvalidAccessToken},
+		{AccessToken{Token: "abc", Scopes: []string{"user-read-private"}}, ErrInvalidAccessToken},
+		{AccessToken{Token: "abc", Expiry: now}, ErrAccessTokenExpired},
+	} {
+		err := test.token.Validate(now)
+		if (test.expected == nil && err != nil) || !errors.Is(err, test.expected) {
+			t.Errorf("Validating %+v: got %v, expected %v", test.token, err, test.expected)
+		}
+	}
+	if value := (AccessToken{Token: "bearer abc"}).Value(); value != "abc" {
+		t.Errorf("Got token value %q", value)
+	}
+}
+
+func TestOAuthAccessToken(t *testing.T) {
+	issued := time.Unix(1000, 0)
+	token := (&OAuth{AccessToken: "abc", Scope: "streaming user-read-private", ExpiresIn: 3600}).AccessTokenAt(issued)
+	if token.Token != "abc" || !token.Expiry.Equal(issued.Add(time.Hour)) || len(token.Scopes) != 2 {
+		t.Errorf("Got access token %+v", token)
+	}
+}
+
+func TestLoginWithAccessToken(t *testing.T) {
+	// The token logins have no username, and don't share the failures of the other ones
+	limiter := NewLoginLimiter(LoginLimitPolicy{Cooldown: time.Minute, MaxCooldown: time.Minute})
+	limiter.Failure("")
+	s, stream := newHandshakeSession(SessionConfig{LoginLimiter: limiter})
+	if err := s.LoginWithAccessToken(AccessToken{Token: "abc", Expiry: time.Now().Add(-time.Second)}); !errors.Is(
+		err, ErrAccessTokenExpired) {
+		t.Fatalf("Expected ErrAccessTokenExpired, got %v", err)
+	}
+
+	welcome, _ := proto.Marshal(&Spotify.APWelcome{
+		CanonicalUsername:           proto.String("canonical"),
+		AccountTypeLoggedIn:         Spotify.AccountType_Spotify.Enum(),
+		CredentialsTypeLoggedIn:     Spotify.AccountType_Spotify.Enum(),
+		ReusableAuthCredentialsType: Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum(),
+		ReusableAuthCredentials:     []byte{1, 2, 3},
+	})
+	stream.recvPackets <- shanPacket{cmd: connection.PacketAPWelcome, buf: welcome}
+
+	if err := s.LoginWithAccessToken(AccessToken{Token: "Bearer abc", Expiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	login := <-stream.sendPackets
+	packet := &Spotify.ClientResponseEncrypted{}
+	proto.Unmarshal(login.buf, packet)
+	credentials := packet.GetLoginCredentials()
+	if credentials.GetTyp() != Spotify.AuthenticationType_AUTHENTICATION_SPOTIFY_TOKEN ||
+		!bytes.Equal(credentials.GetAuthData(), []byte("abc")) || credentials.GetUsername() != "" {
+		t.Errorf("Bad login credentials: %v", credentials)
+	}
+	if s.Username() != "canonical" || !bytes.Equal(s.ReusableAuthBlob(), []byte{1, 2, 3}) {
+		t.Errorf("Got user %s with auth blob %v", s.Username(), s.ReusableAuthBlob())
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrBadCredentials is matched by the AuthErrors of rejected usernames, passwords or tokens
+	ErrBadCredentials = errors.New("bad credentials")
+	// ErrTryAnotherAP is matched by the AuthErrors of access points refusing the login, which may succeed on another
+	// one
+	ErrTryAnotherAP = errors.New("try another access point")
+	// ErrTravelRestriction is matched by the AuthErrors of users logging in from another country than the one of
+	// their account for too long
+	ErrTravelRestriction = errors.New("travel restriction")
+)
+
+// AuthError is returned when the access point rejects the login, with the reason sent in its APLoginFailed message.
+// It matches ErrAuthenticationFailed with errors.Is, and depending on its code ErrBadCredentials, ErrPremiumRequired,
+// ErrTryAnotherAP or ErrTravelRestriction.
+type AuthError struct {
+	Code Spotify.ErrorCode
+	// RetryDelay is how long to wait before trying again, if sent by the server
+	RetryDelay  time.Duration
+	Description string
+}
+
+func (e *AuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("authentication failed: %v (%s)", e.Code, e.Description)
+	}
+	return fmt.Sprintf("authentication failed: %v", e.Code)
+}
+
+// Is matches ErrAuthenticationFailed, and the sentinel error of the code of the error
+func (e *AuthError) Is(target error) bool {
+	if target == ErrAuthenticationFailed {
+		return true
+	}
+	switch e.Code {
+	case Spotify.ErrorCode_BadCredentials, Spotify.ErrorCode_CouldNotValidateCredentials:
+		return target == ErrBadCredentials
+	case Spotify.ErrorCode_PremiumAccountRequired:
+		return target == ErrPremiumRequired
+	case Spotify.ErrorCode_TryAnotherAP:
+		return target == ErrTryAnotherAP
+	case Spotify.ErrorCode_TravelRestriction:
+		return target == ErrTravelRestriction
+	}
+	return false
+}
+
+// Retryable returns true if the login may succeed if tried again with the same credentials, possibly on another
+// access point or after RetryDelay
+func (e *AuthError) Retryable() bool {
+	switch e.Code {
+	case Spotify.ErrorCode_ProtocolError, Spotify.ErrorCode_TryAnotherAP, Spotify.ErrorCode_BadConnectionId:
+		return true
+	}
+	return false
+}
+
+// parseLoginFailed returns the AuthError of a PacketAuthFailure packet, or ErrAuthenticationFailed if it can't be
+// decoded
+func parseLoginFailed(data []byte) error {
+	failed := &Spotify.APLoginFailed{}
+	if err := proto.Unmarshal(data, failed); err != nil {
+		return ErrAuthenticationFailed
+	}
+	return &AuthError{
+		Code:        failed.GetErrorCode(),
+		RetryDelay:  time.Duration(failed.GetRetryDelay()) * time.Second,
+		Description: failed.GetErrorDescription(),
+	}
+}
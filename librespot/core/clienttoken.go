@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/tokens"
+	"github.com/golang/protobuf/proto"
+)
+
+// clientTokenUrl is the endpoint granting the client tokens
+const clientTokenUrl = "https://clienttoken.spotify.com/v1/clienttoken"
+
+// fetchClientToken requests a client token for this device. It is the ClientTokenFetcher of the token provider.
+func (s *Session) fetchClientToken() (*tokens.Token, error) {
+	body, err := proto.Marshal(clientTokenRequest(s.config.VersionString, tokens.KeymasterClientId, s.deviceId))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", clientTokenUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Accept", "application/x-protobuf")
+
+	res, err := s.HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("clienttoken: %s", res.Status)
+	}
+	body, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresIn, err := parseClientTokenResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	return &tokens.Token{AccessToken: token, Expiry: s.Now().Add(expiresIn)}, nil
+}
+
+// clientTokenRequest returns a ClientTokenRequest requesting a token for the client data
+func clientTokenRequest(clientVersion, clientId, deviceId string) *Spotify.ClientTokenRequest {
+	return &Spotify.ClientTokenRequest{
+		RequestType: Spotify.ClientTokenRequestType_REQUEST_CLIENT_DATA_REQUEST.Enum(),
+		ClientData: &Spotify.ClientDataRequest{
+			ClientVersion: proto.String(clientVersion),
+			ClientId:      proto.String(clientId),
+			ConnectivitySdkData: &Spotify.ConnectivitySdkData{
+				DeviceId: proto.String(deviceId),
+			},
+		},
+	}
+}
+
+// parseClientTokenResponse returns the token granted by a ClientTokenResponse, and how long it is valid
+func parseClientTokenResponse(body []byte) (string, time.Duration, error) {
+	res := &Spotify.ClientTokenResponse{}
+	if err := proto.Unmarshal(body, res); err != nil {
+		return "", 0, err
+	}
+	switch res.GetResponseType() {
+	case Spotify.ClientTokenResponseType_RESPONSE_GRANTED_TOKEN_RESPONSE:
+	case Spotify.ClientTokenResponseType_RESPONSE_CHALLENGES_RESPONSE:
+		return "", 0, errors.New("clienttoken: challenges are not supported")
+	default:
+		return "", 0, fmt.Errorf("clienttoken: unsupported response %d", res.GetResponseType())
+	}
+
+	granted := res.GetGrantedToken()
+	if granted.GetToken() == "" {
+		return "", 0, errors.New("clienttoken: no token granted")
+	}
+	return granted.GetToken(), time.Duration(granted.GetExpiresAfterSeconds()) * time.Second, nil
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestClientTokenRequest(t *testing.T) {
+	data, err := proto.Marshal(clientTokenRequest("1.0", "client", "device"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &Spotify.ClientTokenRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		t.Fatal(err)
+	}
+	if req.GetRequestType() != Spotify.ClientTokenRequestType_REQUEST_CLIENT_DATA_REQUEST ||
+		req.GetClientData().GetClientId() != "client" ||
+		req.GetClientData().GetConnectivitySdkData().GetDeviceId() != "device" {
+		t.Errorf("Bad request %v", req)
+	}
+}
+
+func TestParseClientTokenResponse(t *testing.T) {
+	body, _ := proto.Marshal(&Spotify.ClientTokenResponse{
+		ResponseType: Spotify.ClientTokenResponseType_RESPONSE_GRANTED_TOKEN_RESPONSE.Enum(),
+		GrantedToken: &Spotify.GrantedTokenResponse{
+			Token:               proto.String("AAEF"),
+			ExpiresAfterSeconds: proto.Int32(1209600),
+		},
+	})
+
+	token, expiresIn, err := parseClientTokenResponse(body)
+	if err != nil || token != "AAEF" || expiresIn != 14*24*time.Hour {
+		t.Errorf("Bad token %q expiring in %v: %v", token, expiresIn, err)
+	}
+
+	challenges, _ := proto.Marshal(&Spotify.ClientTokenResponse{
+		ResponseType: Spotify.ClientTokenResponseType_RESPONSE_CHALLENGES_RESPONSE.Enum(),
+	})
+	if _, _, err := parseClientTokenResponse(challenges); err == nil {
+		t.Errorf("Expected an error for a challenge")
+	}
+}
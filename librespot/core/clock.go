@@ -0,0 +1,62 @@
+package core
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultClockSkewTolerance is the difference between the local clock and the time of the access point ignored by
+// default. The time of the access point has a precision of a second, and is delayed by the network.
+const DefaultClockSkewTolerance = 10 * time.Second
+
+// ClockSkew returns the time of the access point minus the local time, measured at its last ping, and false if no
+// ping was received yet
+func (s *Session) ClockSkew() (time.Duration, bool) {
+	if atomic.LoadInt32(&s.clockSkewKnown) == 0 {
+		return 0, false
+	}
+	return time.Duration(atomic.LoadInt64(&s.clockSkew)), true
+}
+
+// Now returns the current time, with which the expiry of the tokens is evaluated. It is the local time, unless
+// SessionConfig.TrustServerTime is set and the local clock differs from the time of the access point by more than
+// the tolerance, in which case it is the time of the access point.
+func (s *Session) Now() time.Time {
+	now := time.Now()
+	if !s.config.TrustServerTime {
+		return now
+	}
+	skew, ok := s.ClockSkew()
+	if !ok || (skew <= s.clockSkewTolerance() && skew >= -s.clockSkewTolerance()) {
+		return now
+	}
+	// The monotonic reading of now is kept, so that the durations measured are not affected by the skew
+	return now.Add(skew)
+}
+
+func (s *Session) clockSkewTolerance() time.Duration {
+	if s.config.ClockSkewTolerance == 0 {
+		return DefaultClockSkewTolerance
+	}
+	return s.config.ClockSkewTolerance
+}
+
+// recordServerTime measures the clock skew with the time of the access point, sent as the unix time in seconds in
+// the payload of the pings
+func (s *Session) recordServerTime(ping []byte) {
+	if len(ping) < 4 {
+		return
+	}
+	server := time.Unix(int64(binary.BigEndian.Uint32(ping)), 0)
+	skew := server.Sub(time.Now())
+
+	previous, known := s.ClockSkew()
+	atomic.StoreInt64(&s.clockSkew, int64(skew))
+	atomic.StoreInt32(&s.clockSkewKnown, 1)
+
+	tolerance := s.clockSkewTolerance()
+	if (skew > tolerance || skew < -tolerance) && (!known || (previous <= tolerance && previous >= -tolerance)) {
+		s.Logger().Printf("The local clock differs from the access point by %v\n", skew)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serverTimePing(t time.Time) []byte {
+	ping := make([]byte, 4)
+	binary.BigEndian.PutUint32(ping, uint32(t.Unix()))
+	return ping
+}
+
+func TestClockSkew(t *testing.T) {
+	logs := new(bytes.Buffer)
+	s := &Session{config: SessionConfig{TrustServerTime: true, Logger: log.New(logs, "", 0)}}
+	if _, ok := s.ClockSkew(); ok {
+		t.Errorf("Skew known before any ping")
+	}
+
+	// Within the tolerance, the local time is used
+	s.recordServerTime(serverTimePing(time.Now().Add(5 * time.Second)))
+	if skew, ok := s.ClockSkew(); !ok || skew < 3*time.Second || skew > 6*time.Second {
+		t.Errorf("Skew %v, %v", skew, ok)
+	}
+	if diff := s.Now().Sub(time.Now()); diff > time.Second || diff < -time.Second {
+		t.Errorf("Local time not used with a skew within the tolerance: %v", diff)
+	}
+	if logs.Len() > 0 {
+		t.Errorf("Skew within the tolerance logged: %q", logs.String())
+	}
+
+	// A local clock an hour ahead is replaced by the time of the access point
+	s.recordServerTime(serverTimePing(time.Now().Add(-time.Hour)))
+	if diff := time.Now().Sub(s.Now()); diff < time.Hour-2*time.Second || diff > time.Hour+time.Second {
+		t.Errorf("Time of the access point not used: %v", diff)
+	}
+	if !strings.Contains(logs.String(), "differs from the access point") {
+		t.Errorf("Skew not logged: %q", logs.String())
+	}
+
+	// Unless the server isn't trusted
+	s.config.TrustServerTime = false
+	if diff := s.Now().Sub(time.Now()); diff > time.Second || diff < -time.Second {
+		t.Errorf("Time of the access point used: %v", diff)
+	}
+}
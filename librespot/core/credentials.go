@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/storage"
+)
+
+// ErrNoCredentials is returned by CredentialStore.Get when no credentials are stored for the user
+var ErrNoCredentials = errors.New("no stored credentials")
+
+// CredentialStore persists the reusable credentials of the users, see SessionConfig.CredentialStore
+type CredentialStore interface {
+	// Get returns the credentials stored for the user, or ErrNoCredentials
+	Get(username string) (Credentials, error)
+	// Put stores the credentials, replacing the ones of the same user
+	Put(credentials Credentials) error
+	// Delete forgets the credentials of the user, if any
+	Delete(username string) error
+}
+
+// MemoryCredentialStore keeps the credentials in memory, for the lifetime of the process
+type MemoryCredentialStore struct {
+	lock        sync.Mutex
+	credentials map[string]Credentials
+}
+
+// NewMemoryCredentialStore creates an empty in-memory credential store
+func NewMemoryCredentialStore() *MemoryCredentialStore {
+	return &MemoryCredentialStore{credentials: map[string]Credentials{}}
+}
+
+func (m *MemoryCredentialStore) Get(username string) (Credentials, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	credentials, ok := m.credentials[username]
+	if !ok {
+		return Credentials{}, ErrNoCredentials
+	}
+	return credentials, nil
+}
+
+func (m *MemoryCredentialStore) Put(credentials Credentials) error {
+	m.lock.Lock()
+	m.credentials[credentials.Username] = credentials
+	m.lock.Unlock()
+	return nil
+}
+
+func (m *MemoryCredentialStore) Delete(username string) error {
+	m.lock.Lock()
+	delete(m.credentials, username)
+	m.lock.Unlock()
+	return nil
+}
+
+// storedCredentials is the JSON representation of the credentials, as stored in files and keyrings
+type storedCredentials struct {
+	Username string `json:"username"`
+	AuthType int32  `json:"auth_type"`
+	AuthData []byte `json:"auth_data"`
+}
+
+func encodeCredentials(credentials Credentials) ([]byte, error) {
+	return json.Marshal(storedCredentials{
+		Username: credentials.Username,
+		AuthType: int32(credentials.AuthType),
+		AuthData: credentials.AuthData,
+	})
+}
+
+func decodeCredentials(data []byte) (Credentials, error) {
+	var stored storedCredentials
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{
+		Username: stored.Username,
+		AuthType: Spotify.AuthenticationType(stored.AuthType),
+		AuthData: stored.AuthData,
+	}, nil
+}
+
+// KVCredentialStore stores the credentials of each user as JSON in a key-value store, e.g. the database of the
+// embedder
+type KVCredentialStore struct {
+	kv storage.KV
+}
+
+// NewKVCredentialStore creates a store keeping the credentials in kv
+func NewKVCredentialStore(kv storage.KV) *KVCredentialStore {
+	return &KVCredentialStore{kv: kv}
+}
+
+// key returns the key of the user. The username is hex-encoded, so that it can't escape the directory of a
+// storage.Dir.
+func (k *KVCredentialStore) key(username string) string {
+	return hex.EncodeToString([]byte(username)) + ".json"
+}
+
+func (k *KVCredentialStore) Get(username string) (Credentials, error) {
+	data, err := k.kv.Get(k.key(username))
+	if err == storage.ErrNotFound {
+		return Credentials{}, ErrNoCredentials
+	} else if err != nil {
+		return Credentials{}, err
+	}
+	return decodeCredentials(data)
+}
+
+func (k *KVCredentialStore) Put(credentials Credentials) error {
+	data, err := encodeCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	return k.kv.Put(k.key(credentials.Username), data)
+}
+
+func (k *KVCredentialStore) Delete(username string) error {
+	return k.kv.Delete(k.key(username))
+}
+
+// FileCredentialStore stores the credentials of each user in a JSON file of a directory, only readable by the
+// current user
+type FileCredentialStore struct {
+	KVCredentialStore
+}
+
+// NewFileCredentialStore creates a store keeping the credentials in dir, which is created if needed
+func NewFileCredentialStore(dir string) (*FileCredentialStore, error) {
+	d, err := storage.NewDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &FileCredentialStore{KVCredentialStore{kv: d}}, nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+func testCredentialStore(t *testing.T, store CredentialStore) {
+	if _, err := store.Get("user"); err != ErrNoCredentials {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	if err := store.Put(StoredCredentials("user", []byte{1, 2, 3})); err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := store.Get("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials.Username != "user" || !bytes.Equal(credentials.AuthData, []byte{1, 2, 3}) ||
+		credentials.AuthType != Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS {
+		t.Errorf("Bad credentials %v", credentials)
+	}
+
+	if err := store.Delete("user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("user"); err != ErrNoCredentials {
+		t.Errorf("Expected ErrNoCredentials after Delete, got %v", err)
+	}
+}
+
+func TestMemoryCredentialStore(t *testing.T) {
+	testCredentialStore(t, NewMemoryCredentialStore())
+}
+
+func TestFileCredentialStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileCredentialStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCredentialStore(t, store)
+
+	// Usernames can't escape the directory
+	if err := store.Put(Credentials{Username: "../user", AuthData: []byte("data")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, hex.EncodeToString([]byte("../user"))+".json")); err != nil {
+		t.Errorf("Credentials not stored in the directory: %v", err)
+	}
+}
+
+func TestKeyringCredentialStore(t *testing.T) {
+	secrets := map[string]string{}
+	store := NewKeyringCredentialStore("librespot")
+	store.goos = "linux"
+	store.run = func(input string, name string, args ...string) (string, error) {
+		// secret-tool <action> ... service librespot username <username>
+		key := args[len(args)-1]
+		switch args[0] {
+		case "store":
+			secrets[key] = input
+		case "lookup":
+			return secrets[key], nil
+		case "clear":
+			delete(secrets, key)
+		}
+		return "", nil
+	}
+	testCredentialStore(t, store)
+
+	// The password is passed to security on its standard input, not on the command line
+	var command string
+	store.goos = "darwin"
+	store.run = func(input string, name string, args ...string) (string, error) {
+		if name != "security" || len(args) != 1 || args[0] != "-i" {
+			t.Errorf("Bad command %s %v", name, args)
+		}
+		command = input
+		return "", nil
+	}
+	if err := store.Put(Credentials{Username: `a "user"`, AuthType: 1, AuthData: []byte("secret")}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(command, `"add-generic-password" "-U" "-s" "librespot" "-a" "a \"user\"" "-w" "`) ||
+		!strings.HasSuffix(command, "\"\n") {
+		t.Errorf("Bad security command %q", command)
+	}
+
+	store.goos = "plan9"
+	if _, err := store.Get("user"); err != ErrKeyringUnsupported {
+		t.Errorf("Expected ErrKeyringUnsupported, got %v", err)
+	}
+}
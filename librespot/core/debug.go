@@ -0,0 +1,113 @@
+package core
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+)
+
+// RuntimeMetrics are the metrics of the Go runtime served by the debug server
+type RuntimeMetrics struct {
+	Time         time.Time `json:"time"`
+	Goroutines   int       `json:"goroutines"`
+	CPUs         int       `json:"cpus"`
+	HeapAlloc    uint64    `json:"heap_alloc"`
+	HeapInuse    uint64    `json:"heap_inuse"`
+	HeapObjects  uint64    `json:"heap_objects"`
+	Sys          uint64    `json:"sys"`
+	TotalAlloc   uint64    `json:"total_alloc"`
+	NumGC        uint32    `json:"num_gc"`
+	PauseTotalNs uint64    `json:"pause_total_ns"`
+	LastGC       time.Time `json:"last_gc"`
+}
+
+// ReadRuntimeMetrics returns the current metrics of the Go runtime. It stops the world briefly, like
+// runtime.ReadMemStats.
+func ReadRuntimeMetrics() RuntimeMetrics {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return RuntimeMetrics{
+		Time:         time.Now(),
+		Goroutines:   runtime.NumGoroutine(),
+		CPUs:         runtime.NumCPU(),
+		HeapAlloc:    stats.HeapAlloc,
+		HeapInuse:    stats.HeapInuse,
+		HeapObjects:  stats.HeapObjects,
+		Sys:          stats.Sys,
+		TotalAlloc:   stats.TotalAlloc,
+		NumGC:        stats.NumGC,
+		PauseTotalNs: stats.PauseTotalNs,
+		LastGC:       time.Unix(0, int64(stats.LastGC)),
+	}
+}
+
+// DebugHandler returns the handler of the debug server, to be mounted on an HTTP server of the application. It
+// serves:
+//
+//	/debug/pprof/      the profiles of net/http/pprof, e.g. /debug/pprof/profile?seconds=30 for the CPU profile
+//	/debug/goroutines  a dump of the stacks of all the goroutines, as printed by a panic
+//	/debug/metrics     the RuntimeMetrics, as JSON
+//	/debug/health      the Health of the session, as JSON
+//
+// The profiles and the dumps expose the internals of the process, the handler must not be reachable by untrusted
+// clients.
+func (s *Session) DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	// The handlers are registered on a private mux rather than http.DefaultServeMux, like net/http/pprof does when
+	// imported, so that the application doesn't serve them by accident
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	mux.HandleFunc("/debug/metrics", func(w http.ResponseWriter, req *http.Request) {
+		writeDebugJSON(w, ReadRuntimeMetrics())
+	})
+	mux.HandleFunc("/debug/health", func(w http.ResponseWriter, req *http.Request) {
+		writeDebugJSON(w, s.Health())
+	})
+	return mux
+}
+
+// ServeDebug starts serving the DebugHandler on addr in the background, e.g. "localhost:6060", until the session is
+// closed. The debug server is opt-in, as it exposes the internals of the process: it should listen on the loopback
+// interface, or be reached through an SSH tunnel, rather than being exposed on the network. It returns the address
+// the server listens on, which has the port chosen by the system if the one of addr is 0.
+func (s *Session) ServeDebug(addr string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: s.DebugHandler()}
+
+	s.stateLock.Lock()
+	if s.debugServer != nil {
+		s.debugServer.Close()
+	}
+	s.debugServer = server
+	s.stateLock.Unlock()
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.Logger().Printf("Debug server failed: %v\n", err)
+		}
+	}()
+	return listener.Addr(), nil
+}
+
+// writeDebugJSON writes a JSON response of the debug server
+func writeDebugJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
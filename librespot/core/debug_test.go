@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/ops"
+)
+
+func TestDebugServer(t *testing.T) {
+	s := &Session{ops: ops.NewRegistry()}
+	s.state = StateReconnecting
+	_, done := s.ops.Start(ops.KindTokenRefresh, "refresh")
+	defer done()
+
+	supervisor := NewSupervisor(SupervisorConfig{})
+	last := time.Unix(1000, 0)
+	supervisor.Watch(SubsystemDealer, func() (time.Time, bool) { return last, true }, func() error { return nil })
+	supervisor.check(last.Add(time.Hour))
+	s.supervisor = supervisor
+
+	addr, err := s.ServeDebug("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) (string, []byte) {
+		res, err := http.Get("http://" + addr.String() + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil || res.StatusCode != http.StatusOK {
+			t.Fatalf("Got status %d for %s, %v", res.StatusCode, path, err)
+		}
+		return res.Header.Get("Content-Type"), body
+	}
+
+	var health Health
+	contentType, body := get("/debug/health")
+	if contentType != "application/json" || json.Unmarshal(body, &health) != nil {
+		t.Fatalf("Invalid health %s: %s", contentType, body)
+	}
+	if health.State != "reconnecting" || len(health.Operations) != 1 || len(health.Subsystems) != 1 {
+		t.Errorf("Got health %+v", health)
+	}
+	if sub := health.Subsystems[0]; sub.Name != SubsystemDealer || !sub.Active || !sub.LastHeartbeat.Equal(last) ||
+		sub.Restarts != 1 {
+		t.Errorf("Got subsystem %+v", sub)
+	}
+
+	var metrics RuntimeMetrics
+	if _, body := get("/debug/metrics"); json.Unmarshal(body, &metrics) != nil || metrics.Goroutines == 0 {
+		t.Errorf("Got metrics %s", body)
+	}
+	if _, body := get("/debug/goroutines"); !strings.Contains(string(body), "TestDebugServer") {
+		t.Errorf("The goroutine dump lacks the test: %s", body)
+	}
+	if _, body := get("/debug/pprof/"); !strings.Contains(string(body), "goroutine") {
+		t.Errorf("Got pprof index %s", body)
+	}
+
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.Get("http://" + addr.String() + "/debug/health"); err == nil {
+		t.Errorf("The debug server should be closed with the session")
+	}
+}
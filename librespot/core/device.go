@@ -0,0 +1,65 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/librespot/crypto"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// deviceIdentityUsername is the entry of the credential stores holding the device identity. Spotify usernames can't
+// contain colons, so it can't be the one of a user.
+const deviceIdentityUsername = "librespot:device"
+
+// connectKeySize is the size of the private Diffie-Hellman keys of Spotify Connect, in bytes
+const connectKeySize = 95
+
+// DeviceIdentity identifies the device to Spotify. Keeping it across restarts, reinstalls and upgrades makes the
+// device show up once in the device list of the account, rather than once per identity.
+type DeviceIdentity struct {
+	DeviceId string `json:"device_id"`
+	// ConnectKey is the private Diffie-Hellman key of the Spotify Connect discovery, see
+	// discovery.ServerConfig.PrivateKey
+	ConnectKey []byte `json:"connect_key"`
+}
+
+// LoadDeviceIdentity returns the device identity kept in the credential store, creating and storing it the first
+// time. The identity is then created from the device name like the ones of the previous versions, so that the
+// existing devices keep their id.
+func LoadDeviceIdentity(store CredentialStore, deviceName string) (DeviceIdentity, error) {
+	credentials, err := store.Get(deviceIdentityUsername)
+	if err == nil {
+		var identity DeviceIdentity
+		if err := json.Unmarshal(credentials.AuthData, &identity); err != nil {
+			return DeviceIdentity{}, fmt.Errorf("bad device identity: %v", err)
+		}
+		if identity.DeviceId != "" {
+			return identity, nil
+		}
+	} else if err != ErrNoCredentials {
+		return DeviceIdentity{}, fmt.Errorf("failed to get device identity: %v", err)
+	}
+
+	identity := DeviceIdentity{
+		DeviceId:   utils.GenerateDeviceId(deviceName),
+		ConnectKey: crypto.RandomVec(connectKeySize),
+	}
+	if err := StoreDeviceIdentity(store, identity); err != nil {
+		return DeviceIdentity{}, err
+	}
+	return identity, nil
+}
+
+// StoreDeviceIdentity keeps the device identity in the credential store, e.g. to migrate the identity of another
+// installation
+func StoreDeviceIdentity(store CredentialStore, identity DeviceIdentity) error {
+	data, err := json.Marshal(identity)
+	if err != nil {
+		return err
+	}
+	if err := store.Put(Credentials{Username: deviceIdentityUsername, AuthData: data}); err != nil {
+		return fmt.Errorf("failed to store device identity: %v", err)
+	}
+	return nil
+}
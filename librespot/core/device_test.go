@@ -0,0 +1,42 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+func TestLoadDeviceIdentity(t *testing.T) {
+	store := NewMemoryCredentialStore()
+	identity, err := LoadDeviceIdentity(store, "speaker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.DeviceId != utils.GenerateDeviceId("speaker") || len(identity.ConnectKey) != connectKeySize {
+		t.Errorf("Created identity %+v", identity)
+	}
+
+	// The identity is kept when the device is renamed
+	loaded, err := LoadDeviceIdentity(store, "kitchen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.DeviceId != identity.DeviceId || !bytes.Equal(loaded.ConnectKey, identity.ConnectKey) {
+		t.Errorf("Loaded identity %+v, expected %+v", loaded, identity)
+	}
+
+	migrated := DeviceIdentity{DeviceId: "abc", ConnectKey: []byte{1, 2, 3}}
+	if err := StoreDeviceIdentity(store, migrated); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadDeviceIdentity(store, "speaker"); err != nil || loaded.DeviceId != "abc" ||
+		!bytes.Equal(loaded.ConnectKey, migrated.ConnectKey) {
+		t.Errorf("Loaded identity %+v after migration, error %v", loaded, err)
+	}
+
+	store.Put(Credentials{Username: deviceIdentityUsername, AuthData: []byte("{")})
+	if _, err := LoadDeviceIdentity(store, "speaker"); err == nil {
+		t.Error("Expected an error for a bad identity")
+	}
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// DefaultApAttemptTimeout is the time given to each access point to accept the connection before trying the next one
+const DefaultApAttemptTimeout = 10 * time.Second
+
+// apFallbackPorts are the ports tried, in order, when the default port of an access point can't be reached. Firewalls
+// usually let them through.
+var apFallbackPorts = []string{"443", "80"}
+
+// apDefaultPort is the port the access points listen on by default
+const apDefaultPort = "4070"
+
+// apFailureMemory is how long an access point which failed is tried after the others
+const apFailureMemory = 10 * time.Minute
+
+// endpointHealth remembers the servers which recently failed, so that they are tried last
+type endpointHealth struct {
+	lock     sync.Mutex
+	failures map[string]time.Time
+	now      func() time.Time
+}
+
+// apHealth is shared by all the sessions, so that a new session or a reconnection doesn't try a broken access point
+// first again
+var apHealth = newEndpointHealth()
+
+func newEndpointHealth() *endpointHealth {
+	return &endpointHealth{
+		failures: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (h *endpointHealth) failed(address string) {
+	h.lock.Lock()
+	h.failures[address] = h.now()
+	h.lock.Unlock()
+}
+
+func (h *endpointHealth) succeeded(address string) {
+	h.lock.Lock()
+	delete(h.failures, address)
+	h.lock.Unlock()
+}
+
+// order returns the addresses, the ones which recently failed moved to the end, the oldest failure first
+func (h *endpointHealth) order(addresses []string) []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	now := h.now()
+	var healthy, failed []string
+	for _, address := range addresses {
+		at, ok := h.failures[address]
+		if ok && now.Sub(at) > apFailureMemory {
+			delete(h.failures, address)
+			ok = false
+		}
+		if ok {
+			failed = append(failed, address)
+		} else {
+			healthy = append(healthy, address)
+		}
+	}
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return h.failures[failed[i]].Before(h.failures[failed[j]])
+	})
+	return append(healthy, failed...)
+}
+
+// Endpoints returns the servers resolved for this session
+func (s *Session) Endpoints() utils.Endpoints {
+	s.endpointsLock.Lock()
+	defer s.endpointsLock.Unlock()
+	return s.endpoints
+}
+
+// AccessPoint returns the address of the access point the session is connected to
+func (s *Session) AccessPoint() string {
+	s.endpointsLock.Lock()
+	defer s.endpointsLock.Unlock()
+	return s.accessPoint
+}
+
+// resolveEndpoints fetches the lists of servers, falling back to the well-known ones if the resolver can't be reached
+func (s *Session) resolveEndpoints() utils.Endpoints {
+	endpoints := utils.FallbackEndpoints
+	if s.config.ApAddress != "" {
+		endpoints.AccessPoints = []string{s.config.ApAddress}
+	} else if resolved, err := utils.ResolveEndpoints(s.HTTPClient()); err != nil {
+		s.Logger().Println("Failed to resolve endpoints, using the default ones:", err)
+	} else {
+		endpoints.AccessPoints = resolved.AccessPoints
+		if len(resolved.Dealers) > 0 {
+			endpoints.Dealers = resolved.Dealers
+		}
+		if len(resolved.SpClients) > 0 {
+			endpoints.SpClients = resolved.SpClients
+		}
+	}
+
+	s.endpointsLock.Lock()
+	s.endpoints = endpoints
+	s.endpointsLock.Unlock()
+	return endpoints
+}
+
+// connectAccessPoint tries the access points in turn, the ones which recently failed last, until a connection is
+// established
+func (s *Session) connectAccessPoint(addresses []string) error {
+	if len(addresses) == 0 {
+		return errors.New("no access point to connect to")
+	}
+
+	timeout := s.config.ApAttemptTimeout
+	if timeout <= 0 {
+		timeout = DefaultApAttemptTimeout
+	}
+
+	addresses = apHealth.order(addresses)
+	if !s.config.DisableApPortFallback {
+		addresses = withPortFallbacks(addresses)
+	}
+
+	var firstErr error
+	for _, address := range addresses {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		conn, err := s.dialAccessPoint(ctx, address)
+		cancel()
+
+		if err != nil {
+			s.Logger().Printf("Failed to connect to %s: %v\n", address, err)
+			apHealth.failed(address)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to connect to %s: %v", address, err)
+			}
+			continue
+		}
+
+		apHealth.succeeded(address)
+		s.connLock.Lock()
+		s.tcpCon = conn
+		s.connLock.Unlock()
+		s.endpointsLock.Lock()
+		s.accessPoint = address
+		s.endpointsLock.Unlock()
+		return nil
+	}
+
+	return firstErr
+}
+
+// dialAccessPoint connects to the access point through the transport of the session
+func (s *Session) dialAccessPoint(ctx context.Context, address string) (net.Conn, error) {
+	var transport connection.Transport = s.dialer
+	if s.config.Transport != nil {
+		transport = s.config.Transport
+	}
+	return transport.DialContext(ctx, address)
+}
+
+// withPortFallbacks appends the fallback ports of the access points listening on the default port, after all the
+// addresses, unless they are already listed
+func withPortFallbacks(addresses []string) []string {
+	known := map[string]bool{}
+	for _, address := range addresses {
+		known[address] = true
+	}
+
+	res := append([]string{}, addresses...)
+	for _, port := range apFallbackPorts {
+		for _, address := range addresses {
+			host, p, err := net.SplitHostPort(address)
+			if err != nil || p != apDefaultPort {
+				continue
+			}
+			fallback := net.JoinHostPort(host, port)
+			if !known[fallback] {
+				known[fallback] = true
+				res = append(res, fallback)
+			}
+		}
+	}
+	return res
+}
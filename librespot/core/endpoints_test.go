@@ -0,0 +1,74 @@
+package core
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+)
+
+func TestEndpointHealthOrder(t *testing.T) {
+	now := time.Unix(1000, 0)
+	h := newEndpointHealth()
+	h.now = func() time.Time { return now }
+
+	h.failed("b:443")
+	now = now.Add(time.Second)
+	h.failed("a:443")
+
+	addresses := []string{"a:443", "b:443", "c:443", "d:443"}
+	if res := h.order(addresses); !reflect.DeepEqual(res, []string{"c:443", "d:443", "b:443", "a:443"}) {
+		t.Errorf("Bad order %v", res)
+	}
+
+	h.succeeded("a:443")
+	now = now.Add(apFailureMemory + time.Second)
+	if res := h.order(addresses); !reflect.DeepEqual(res, addresses) {
+		t.Errorf("Failures should be forgotten, got %v", res)
+	}
+}
+
+func TestConnectAccessPointFallback(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	// Nobody listens on the first address anymore
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := closed.Addr().String()
+	closed.Close()
+
+	s := &Session{dialer: connection.NewDialer()}
+	defer s.disconnect()
+	if err := s.connectAccessPoint([]string{broken, listener.Addr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	if s.AccessPoint() != listener.Addr().String() {
+		t.Errorf("Connected to %s", s.AccessPoint())
+	}
+
+	if order := apHealth.order([]string{broken, listener.Addr().String()}); order[0] == broken {
+		t.Errorf("The broken access point should be tried last")
+	}
+}
+
+func TestWithPortFallbacks(t *testing.T) {
+	addresses := []string{"a:4070", "b:443", "c:4070", "c:443"}
+	expected := []string{"a:4070", "b:443", "c:4070", "c:443", "a:443", "a:80", "c:80"}
+	if res := withPortFallbacks(addresses); !reflect.DeepEqual(res, expected) {
+		t.Errorf("Bad fallbacks %v", res)
+	}
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+// PacketCallback is called with a packet received from the access point
+type PacketCallback func(cmd connection.PacketType, data []byte)
+
+// sessionListeners are the callbacks registered to observe the protocol activity of a session
+type sessionListeners struct {
+	country        []func(country string)
+	licenseVersion []func(version string)
+	pong           []func()
+	pingTimeout    []func(err *PingTimeoutError)
+	mercuryEvent   []mercury.Callback
+	unknownPacket  []PacketCallback
+}
+
+// OnCountry registers a callback called with the country of the user, sent by the server after the login
+func (s *Session) OnCountry(cb func(country string)) {
+	s.listenersLock.Lock()
+	s.listeners.country = append(s.listeners.country, cb)
+	s.listenersLock.Unlock()
+}
+
+// OnLicenseVersion registers a callback called with the license version sent by the server after the login, e.g.
+// "1.0.1-FR"
+func (s *Session) OnLicenseVersion(cb func(version string)) {
+	s.listenersLock.Lock()
+	s.listeners.licenseVersion = append(s.listeners.licenseVersion, cb)
+	s.listenersLock.Unlock()
+}
+
+// OnPong registers a callback called when the server acknowledges the pong sent in reply to its ping, which it does
+// every two minutes
+func (s *Session) OnPong(cb func()) {
+	s.listenersLock.Lock()
+	s.listeners.pong = append(s.listeners.pong, cb)
+	s.listenersLock.Unlock()
+}
+
+// OnMercuryEvent registers a callback called for every mercury event pushed by the server, whether subscribed to or
+// not
+func (s *Session) OnMercuryEvent(cb mercury.Callback) {
+	s.listenersLock.Lock()
+	s.listeners.mercuryEvent = append(s.listeners.mercuryEvent, cb)
+	s.listenersLock.Unlock()
+}
+
+// OnConnectionStateChange registers a callback notified of every connection state change, like OnStateChange
+func (s *Session) OnConnectionStateChange(cb StateCallback) {
+	s.OnStateChange(cb)
+}
+
+// OnUnknownPacket registers a callback called with the packets the session doesn't handle
+func (s *Session) OnUnknownPacket(cb PacketCallback) {
+	s.listenersLock.Lock()
+	s.listeners.unknownPacket = append(s.listeners.unknownPacket, cb)
+	s.listenersLock.Unlock()
+}
+
+// LicenseVersion returns the license version sent by the server, empty until received
+func (s *Session) LicenseVersion() string {
+	s.listenersLock.Lock()
+	defer s.listenersLock.Unlock()
+	return s.licenseVersion
+}
+
+func (s *Session) emitCountry(country string) {
+	s.listenersLock.Lock()
+	callbacks := append([]func(string){}, s.listeners.country...)
+	s.listenersLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(country)
+	}
+}
+
+func (s *Session) emitLicenseVersion(version string) {
+	s.listenersLock.Lock()
+	s.licenseVersion = version
+	callbacks := append([]func(string){}, s.listeners.licenseVersion...)
+	s.listenersLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(version)
+	}
+}
+
+func (s *Session) emitPong() {
+	s.listenersLock.Lock()
+	callbacks := append([]func(){}, s.listeners.pong...)
+	s.listenersLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb()
+	}
+}
+
+func (s *Session) emitMercuryEvent(event mercury.Response) {
+	s.listenersLock.Lock()
+	callbacks := append([]mercury.Callback{}, s.listeners.mercuryEvent...)
+	s.listenersLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(event)
+	}
+}
+
+func (s *Session) emitUnknownPacket(cmd connection.PacketType, data []byte) {
+	s.listenersLock.Lock()
+	callbacks := append([]PacketCallback{}, s.listeners.unknownPacket...)
+	s.listenersLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(cmd, data)
+	}
+}
+
+// parseLicenseVersion returns the license of a PacketLicenseVersion packet: [ uint16 id (= 0x001), uint8 len,
+// string license ]
+func parseLicenseVersion(data []byte) string {
+	if len(data) < 3 {
+		return ""
+	}
+	n := int(data[2])
+	if len(data) < 3+n {
+		n = len(data) - 3
+	}
+	return string(data[3 : 3+n])
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+)
+
+func TestSessionEvents(t *testing.T) {
+	s := &Session{}
+
+	var country, license string
+	var pongs int
+	var unknown []connection.PacketType
+	s.OnCountry(func(c string) { country = c })
+	s.OnLicenseVersion(func(v string) { license = v })
+	s.OnPong(func() { pongs++ })
+	s.OnUnknownPacket(func(cmd connection.PacketType, data []byte) { unknown = append(unknown, cmd) })
+
+	packets := []struct {
+		cmd  connection.PacketType
+		data []byte
+	}{
+		{connection.PacketCountryCode, []byte("FR")},
+		{connection.PacketLicenseVersion, append([]byte{0, 1, 8}, "1.0.1-FR"...)},
+		{connection.PacketPongAck, nil},
+		{connection.PacketType(0xfe), []byte{1, 2}},
+	}
+	for _, p := range packets {
+		if err := s.handle(p.cmd, p.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if country != "FR" || s.Country() != "FR" {
+		t.Errorf("got country %q", country)
+	}
+	if license != "1.0.1-FR" || s.LicenseVersion() != "1.0.1-FR" {
+		t.Errorf("got license version %q", license)
+	}
+	if pongs != 1 {
+		t.Errorf("got %d pongs, want 1", pongs)
+	}
+	if len(unknown) != 1 || unknown[0] != 0xfe {
+		t.Errorf("got unknown packets %v", unknown)
+	}
+}
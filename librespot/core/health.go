@@ -0,0 +1,60 @@
+package core
+
+import (
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/ops"
+)
+
+// Health is a snapshot of the state of the session and its subsystems, e.g. to diagnose a device remotely
+type Health struct {
+	Time        time.Time `json:"time"`
+	State       string    `json:"state"`
+	Username    string    `json:"username,omitempty"`
+	AccessPoint string    `json:"access_point,omitempty"`
+	LastPing    time.Time `json:"last_ping"`
+	// ClockSkew is the time of the access point minus the local time, only set if ClockSkewKnown is
+	ClockSkew      time.Duration `json:"clock_skew"`
+	ClockSkewKnown bool          `json:"clock_skew_known"`
+	// DealerActive is set while the dealer is in use, and LastDealerActivity is the time it last received a message
+	DealerActive       bool      `json:"dealer_active"`
+	LastDealerActivity time.Time `json:"last_dealer_activity"`
+	// Downloading is set while the player downloads audio data, and LastDownload is the time it last received some
+	Downloading  bool      `json:"downloading"`
+	LastDownload time.Time `json:"last_download"`
+	// Subsystems is the state of the subsystems watched by the supervisor, empty unless Supervise was called
+	Subsystems []SubsystemHealth `json:"subsystems"`
+	Operations []ops.Operation   `json:"operations"`
+}
+
+// Health returns a snapshot of the state of the session and its subsystems
+func (s *Session) Health() Health {
+	health := Health{
+		Time:        time.Now(),
+		State:       s.State().String(),
+		Username:    s.Username(),
+		AccessPoint: s.AccessPoint(),
+		LastPing:    s.LastPing(),
+		Operations:  s.Operations().List(),
+	}
+	health.ClockSkew, health.ClockSkewKnown = s.ClockSkew()
+
+	s.dealerLock.Lock()
+	d := s.dealer
+	s.dealerLock.Unlock()
+	if d != nil {
+		health.LastDealerActivity, health.DealerActive = d.LastActivity()
+	}
+
+	if p := s.Player(); p != nil {
+		health.LastDownload, health.Downloading = p.LastActivity()
+	}
+
+	s.stateLock.Lock()
+	supervisor := s.supervisor
+	s.stateLock.Unlock()
+	if supervisor != nil {
+		health.Subsystems = supervisor.Health()
+	}
+	return health
+}
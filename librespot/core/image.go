@@ -0,0 +1,105 @@
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+)
+
+// ImageCdnUrl is the address of the images on the CDN, followed by their hex file id
+const ImageCdnUrl = "https://i.scdn.co/image/"
+
+// Image is a picture downloaded by GetImage
+type Image struct {
+	Data []byte
+	// ContentType is the MIME type of the image, usually "image/jpeg"
+	ContentType string
+}
+
+// GetImage downloads the image with the file id, as found in the metadata (album covers, artist portraits, ...).
+// The image is requested from the CDN, and through the channel protocol of the access point if the CDN fails. It is
+// kept in the ChunkCache of the session, if any.
+func (s *Session) GetImage(fileId []byte) (*Image, error) {
+	if len(fileId) == 0 {
+		return nil, errors.New("empty image file id")
+	}
+
+	cache := s.config.ChunkCache
+	if cache != nil {
+		if data, ok := cache.GetChunk(fileId, 0); ok {
+			return &Image{Data: data, ContentType: http.DetectContentType(data)}, nil
+		}
+	}
+
+	img, err := s.fetchCdnImage(fileId)
+	if err != nil {
+		var channelErr error
+		img, channelErr = s.fetchChannelImage(fileId)
+		if channelErr != nil {
+			return nil, fmt.Errorf("failed to get image %x: %v, and through a channel: %v", fileId, err, channelErr)
+		}
+	}
+
+	if cache != nil {
+		if err := cache.PutChunk(fileId, 0, img.Data); err != nil {
+			s.Logger().Println("Failed to cache image:", err)
+		}
+	}
+	return img, nil
+}
+
+// GetCover downloads the image of the specified size among the sizes of a picture, or the closest one, see
+// metadata.SelectImage
+func (s *Session) GetCover(images []metadata.Image, size Spotify.Image_Size) (*Image, error) {
+	selected, ok := metadata.SelectImage(images, size)
+	if !ok {
+		return nil, errors.New("no image")
+	}
+	fileId, err := hex.DecodeString(selected.FileId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image file id %q: %v", selected.FileId, err)
+	}
+	return s.GetImage(fileId)
+}
+
+func (s *Session) fetchCdnImage(fileId []byte) (*Image, error) {
+	res, err := s.HTTPClient().Get(ImageCdnUrl + hex.EncodeToString(fileId))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image cdn: %s", res.Status)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return &Image{Data: data, ContentType: contentType}, nil
+}
+
+func (s *Session) fetchChannelImage(fileId []byte) (*Image, error) {
+	channels := s.Channels()
+	if channels == nil {
+		return nil, ErrNotConnected
+	}
+	reader, err := channels.RequestImage(fileId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Data: data, ContentType: http.DetectContentType(data)}, nil
+}
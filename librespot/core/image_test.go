@@ -0,0 +1,64 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestGetImage(t *testing.T) {
+	jpeg := []byte("\xff\xd8\xff\xe0 jpeg data")
+	var requested []string
+	fail := false
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		if fail {
+			return nil, errors.New("offline")
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"image/jpeg"}},
+			Body:       ioutil.NopCloser(bytes.NewReader(jpeg)),
+		}, nil
+	})}
+	s := &Session{config: SessionConfig{HTTPClient: client, ChunkCache: player.NewMemoryChunkCache(1 << 20)}}
+
+	images := []metadata.Image{
+		{FileId: "ab00000000000000000000000000000000000001", Size: Spotify.Image_SMALL},
+		{FileId: "ab00000000000000000000000000000000000002", Size: Spotify.Image_LARGE},
+	}
+	img, err := s.GetCover(images, Spotify.Image_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(img.Data, jpeg) || img.ContentType != "image/jpeg" {
+		t.Errorf("Bad image %q (%s)", img.Data, img.ContentType)
+	}
+	if len(requested) != 1 || requested[0] != ImageCdnUrl+images[1].FileId {
+		t.Errorf("Bad requests %v", requested)
+	}
+
+	// The cached image is returned without request
+	fail = true
+	img, err = s.GetCover(images, Spotify.Image_LARGE)
+	if err != nil || !bytes.Equal(img.Data, jpeg) || img.ContentType != "image/jpeg" || len(requested) != 1 {
+		t.Errorf("Image not cached: %v, %d requests", err, len(requested))
+	}
+
+	// Without connection, the channel fallback fails too
+	if _, err := s.GetCover(images, Spotify.Image_SMALL); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPingTimeout is the time without ping from the access point after which the connection is considered dead.
+// The access point pings every 2 minutes.
+const DefaultPingTimeout = 3 * time.Minute
+
+// PingTimeoutError is passed to the OnPingTimeout callbacks when the access point stopped pinging the session
+type PingTimeoutError struct {
+	// LastPing is the time the last ping was received, or the session logged in if none was
+	LastPing time.Time
+	Timeout  time.Duration
+}
+
+func (e *PingTimeoutError) Error() string {
+	return fmt.Sprintf("no ping from the access point since %v (timeout %v)", e.LastPing.Format(time.RFC3339), e.Timeout)
+}
+
+// OnPingTimeout registers a callback called when the access point went silent for longer than the ping timeout,
+// right before the session reconnects
+func (s *Session) OnPingTimeout(cb func(err *PingTimeoutError)) {
+	s.listenersLock.Lock()
+	s.listeners.pingTimeout = append(s.listeners.pingTimeout, cb)
+	s.listenersLock.Unlock()
+}
+
+// LastPing returns the time the last ping was received from the access point, or the session logged in if none was
+func (s *Session) LastPing() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&s.lastPing))
+}
+
+func (s *Session) pingTimeout() time.Duration {
+	if s.config.PingTimeout == 0 {
+		return DefaultPingTimeout
+	}
+	return s.config.PingTimeout
+}
+
+// watchKeepalive checks that the access point keeps pinging the connection of the poll loop of the generation, until
+// done is closed. When it went silent, the socket is closed so that the poll loop fails and reconnects.
+func (s *Session) watchKeepalive(generation int, done chan struct{}) {
+	timeout := s.pingTimeout()
+	if timeout < 0 {
+		return
+	}
+
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			if s.isClosed() || !s.isPollGeneration(generation) {
+				return
+			}
+			last := s.LastPing()
+			if now.Sub(last) <= timeout || s.State() != StateConnected {
+				continue
+			}
+
+			err := &PingTimeoutError{LastPing: last, Timeout: timeout}
+			s.Logger().Printf("%v, reconnecting\n", err)
+			s.emitPingTimeout(err)
+			s.disconnect()
+			return
+		}
+	}
+}
+
+func (s *Session) emitPingTimeout(err *PingTimeoutError) {
+	s.listenersLock.Lock()
+	callbacks := append([]func(*PingTimeoutError){}, s.listeners.pingTimeout...)
+	s.listenersLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(err)
+	}
+}
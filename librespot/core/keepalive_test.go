@@ -0,0 +1,96 @@
+package core
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+// silentStream receives the packets of recvPackets until closed, like a connection whose server went silent
+type silentStream struct {
+	recvPackets chan shanPacket
+	sendPackets chan shanPacket
+	closed      chan struct{}
+}
+
+func (f *silentStream) SendPacket(cmd connection.PacketType, data []byte) error {
+	f.sendPackets <- shanPacket{cmd: cmd, buf: data}
+	return nil
+}
+
+func (f *silentStream) RecvPacket() (connection.PacketType, []byte, error) {
+	select {
+	case p := <-f.recvPackets:
+		return p.cmd, p.buf, nil
+	case <-f.closed:
+		return 0, nil, io.EOF
+	}
+}
+
+func TestPingTimeout(t *testing.T) {
+	s := &Session{
+		config: SessionConfig{
+			PingTimeout:           100 * time.Millisecond,
+			ApAddress:             "127.0.0.1:1",
+			DisableApPortFallback: true,
+		},
+		mercuryConstructor: mercury.CreateMercury,
+		dialer:             connection.NewDialer(),
+		reconnectPolicy:    ReconnectPolicy{MaxAttempts: 1, InitialDelay: time.Millisecond},
+	}
+	stream := &silentStream{
+		recvPackets: make(chan shanPacket),
+		sendPackets: make(chan shanPacket, 10),
+		closed:      make(chan struct{}),
+	}
+	if err := s.setStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	s.tcpCon = &closingCon{onClose: func() {
+		close(stream.closed)
+	}}
+
+	timeouts := make(chan *PingTimeoutError, 1)
+	s.OnPingTimeout(func(err *PingTimeoutError) {
+		timeouts <- err
+	})
+	states := make(chan ConnectionState, 10)
+	s.OnStateChange(func(state ConnectionState) {
+		states <- state
+	})
+
+	start := time.Now()
+	s.lastPing = start.UnixNano()
+	s.startPollLoop()
+
+	// The pings are answered, and postpone the timeout
+	time.Sleep(60 * time.Millisecond)
+	stream.recvPackets <- shanPacket{cmd: connection.PacketPing, buf: []byte{1, 2, 3, 4}}
+	if pong := <-stream.sendPackets; pong.cmd != connection.PacketPong {
+		t.Errorf("Ping answered with %v", pong.cmd)
+	}
+
+	select {
+	case err := <-timeouts:
+		if silent := time.Since(err.LastPing); silent < err.Timeout || !err.LastPing.After(start) {
+			t.Errorf("Timeout after %v of silence since %v", silent, err.LastPing)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Ping timeout not detected")
+	}
+
+	// The reconnection fails as there is no access point
+	for _, want := range []ConnectionState{StateReconnecting, StateDisconnected} {
+		select {
+		case state := <-states:
+			if state != want {
+				t.Errorf("State %v, want %v", state, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("No reconnection, still %v", s.State())
+		}
+	}
+}
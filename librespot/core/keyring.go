@@ -0,0 +1,114 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrKeyringUnsupported is returned by the KeyringCredentialStore on the platforms without a supported keyring
+var ErrKeyringUnsupported = errors.New("no supported keyring on this platform")
+
+// KeyringCredentialStore keeps the credentials in the keyring of the operating system: the Secret Service on Linux,
+// through the secret-tool command of libsecret, and the login keychain on macOS, through the security command
+type KeyringCredentialStore struct {
+	service string
+	goos    string
+	// run executes the command with the input on its standard input, and returns its standard output
+	run func(input string, name string, args ...string) (string, error)
+}
+
+// NewKeyringCredentialStore creates a store keeping the credentials under the specified service name
+func NewKeyringCredentialStore(service string) *KeyringCredentialStore {
+	return &KeyringCredentialStore{
+		service: service,
+		goos:    runtime.GOOS,
+		run:     runCommand,
+	}
+}
+
+func runCommand(input string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %v %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func (k *KeyringCredentialStore) Get(username string) (Credentials, error) {
+	var out string
+	var err error
+	switch k.goos {
+	case "linux":
+		out, err = k.run("", "secret-tool", "lookup", "service", k.service, "username", username)
+	case "darwin":
+		out, err = k.run("", "security", "find-generic-password", "-s", k.service, "-a", username, "-w")
+	default:
+		return Credentials{}, ErrKeyringUnsupported
+	}
+	out = strings.TrimSpace(out)
+	if err != nil || out == "" {
+		// Both tools fail when the secret doesn't exist
+		return Credentials{}, ErrNoCredentials
+	}
+
+	data, err := base64.StdEncoding.DecodeString(out)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("bad credentials in keyring: %v", err)
+	}
+	return decodeCredentials(data)
+}
+
+func (k *KeyringCredentialStore) Put(credentials Credentials) error {
+	data, err := encodeCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	secret := base64.StdEncoding.EncodeToString(data)
+
+	switch k.goos {
+	case "linux":
+		_, err = k.run(secret, "secret-tool", "store", "--label", k.service+" credentials of "+credentials.Username,
+			"service", k.service, "username", credentials.Username)
+	case "darwin":
+		// security only takes the password as an argument, which is read from its standard input in interactive
+		// mode instead of the command line, visible to the other processes
+		command := securityCommand("add-generic-password", "-U", "-s", k.service, "-a", credentials.Username,
+			"-w", secret)
+		_, err = k.run(command, "security", "-i")
+	default:
+		return ErrKeyringUnsupported
+	}
+	return err
+}
+
+// securityCommand quotes the arguments into a command line of the interactive mode of security
+func securityCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, `\`, `\\`)
+		quoted[i] = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+	}
+	return strings.Join(quoted, " ") + "\n"
+}
+
+func (k *KeyringCredentialStore) Delete(username string) error {
+	switch k.goos {
+	case "linux":
+		_, err := k.run("", "secret-tool", "clear", "service", k.service, "username", username)
+		return err
+	case "darwin":
+		// Deleting a missing password fails, which is not an error here
+		k.run("", "security", "delete-generic-password", "-s", k.service, "-a", username)
+		return nil
+	default:
+		return ErrKeyringUnsupported
+	}
+}
@@ -2,58 +2,100 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/connection"
 	"github.com/fischerling/librespot-golang/librespot/discovery"
 	"github.com/fischerling/librespot-golang/librespot/utils"
-	"log"
 )
 
 var Version = "master"
 var BuildID = "dev"
 
+// ErrAuthenticationFailed is returned when the access point rejects the credentials, and matched by the AuthErrors
+var ErrAuthenticationFailed = errors.New("authentication failed")
+
 // Login to Spotify using username and password
 func Login(username string, password string, deviceName string) (*Session, error) {
-	s, err := setupSession()
+	s, err := NewSession(SessionConfig{DeviceName: deviceName})
 	if err != nil {
-		return s, err
+		return nil, err
 	}
 
-	return s, s.loginSession(username, password, deviceName)
-}
+	err = s.Login(username, password)
+	if err != nil {
+		s.disconnect()
+		return nil, err
+	}
 
-func (s *Session) loginSession(username string, password string, deviceName string) error {
-	s.deviceId = utils.GenerateDeviceId(deviceName)
-	s.deviceName = deviceName
+	return s, nil
+}
 
+// Login authenticates the session using username and password
+func (s *Session) Login(username string, password string) error {
 	err := s.startConnection()
 	if err != nil {
 		return err
 	}
-	loginPacket := makeLoginPasswordPacket(username, password, s.deviceId)
+	loginPacket, err := s.makeLoginPasswordPacket(username, password)
+	if err != nil {
+		return err
+	}
 	return s.doLogin(loginPacket, username)
 }
 
 // Login to Spotify using an existing authData blob
 func LoginSaved(username string, authData []byte, deviceName string) (*Session, error) {
-	s, err := setupSession()
+	s, err := NewSession(SessionConfig{DeviceName: deviceName})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.LoginSaved(username, authData)
 	if err != nil {
-		return s, err
+		s.disconnect()
+		return nil, err
 	}
-	s.deviceId = utils.GenerateDeviceId(deviceName)
-	s.deviceName = deviceName
 
-	err = s.startConnection()
+	return s, nil
+}
+
+// LoginSaved authenticates the session using an existing authData blob, see ReusableAuthBlob
+func (s *Session) LoginSaved(username string, authData []byte) error {
+	return s.loginBlob(username, authData,
+		Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum())
+}
+
+// LoginStored authenticates the session with the credentials of the user kept in the CredentialStore of the session
+func (s *Session) LoginStored(username string) error {
+	store := s.config.CredentialStore
+	if store == nil {
+		return errors.New("no credential store configured")
+	}
+	credentials, err := store.Get(username)
+	if err != nil {
+		return err
+	}
+	return s.loginBlob(credentials.Username, credentials.AuthData, credentials.AuthType.Enum())
+}
+
+func (s *Session) loginBlob(username string, authData []byte, authType *Spotify.AuthenticationType) error {
+	err := s.startConnection()
 	if err != nil {
-		return s, err
+		return err
 	}
 
-	packet := makeLoginBlobPacket(username, authData,
-		Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum(), s.deviceId)
-	return s, s.doLogin(packet, username)
+	packet, err := s.makeLoginBlobPacket(username, authData, authType)
+	if err != nil {
+		return err
+	}
+	return s.doLogin(packet, username)
 }
 
 // Registers librespot as a Spotify Connect device via mdns. When user connects, logs on to Spotify and saves
@@ -61,105 +103,205 @@ func LoginSaved(username string, authData []byte, deviceName string) (*Session,
 // Spotify Connect devices and control them.
 func LoginDiscovery(cacheBlobPath string, deviceName string) (*Session, error) {
 	deviceId := utils.GenerateDeviceId(deviceName)
-	disc := discovery.LoginFromConnect(cacheBlobPath, deviceId, deviceName)
-	return sessionFromDiscovery(disc)
+	disc, err := discovery.LoginFromConnect(cacheBlobPath, deviceId, deviceName)
+	if err != nil {
+		return nil, err
+	}
+	return sessionFromDiscovery(disc, nil)
+}
+
+// LoginDiscoveryStore is similar to LoginDiscovery, except that the credentials are kept in the store instead of a
+// file, along with the identity of the device, see LoadDeviceIdentity. The session can then be created again with
+// LoginStored.
+func LoginDiscoveryStore(store CredentialStore, deviceName string) (*Session, error) {
+	identity, err := LoadDeviceIdentity(store, deviceName)
+	if err != nil {
+		return nil, err
+	}
+	disc, err := discovery.Listen(discovery.ServerConfig{PrivateKey: identity.ConnectKey}, "", identity.DeviceId,
+		deviceName)
+	if err != nil {
+		return nil, err
+	}
+	disc.WaitLogin(context.Background())
+	return sessionFromDiscovery(disc, store)
+}
+
+// LoginStored logs in the user with the credentials kept in the store, e.g. by a previous LoginDiscoveryStore
+func LoginStored(store CredentialStore, username string, deviceName string) (*Session, error) {
+	s, err := NewSession(SessionConfig{DeviceName: deviceName, CredentialStore: store})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.LoginStored(username)
+	if err != nil {
+		s.disconnect()
+		return nil, err
+	}
+
+	return s, nil
 }
 
 // Login using an authentication blob through Spotify Connect discovery system, reading an existing blob data. To read
 // from a file, see LoginDiscoveryBlobFile.
 func LoginDiscoveryBlob(username string, blob string, deviceName string) (*Session, error) {
 	deviceId := utils.GenerateDeviceId(deviceName)
-	disc := discovery.CreateFromBlob(utils.BlobInfo{
+	disc, err := discovery.CreateFromBlob(utils.BlobInfo{
 		Username:    username,
 		DecodedBlob: blob,
 	}, "", deviceId, deviceName)
-	return sessionFromDiscovery(disc)
+	if err != nil {
+		return nil, err
+	}
+	return sessionFromDiscovery(disc, nil)
 }
 
 // Login from credentials at cacheBlobPath previously saved by LoginDiscovery. Similar to LoginDiscoveryBlob, except
 // it reads it directly from a file.
 func LoginDiscoveryBlobFile(cacheBlobPath, deviceName string) (*Session, error) {
 	deviceId := utils.GenerateDeviceId(deviceName)
-	disc := discovery.CreateFromFile(cacheBlobPath, deviceId, deviceName)
-	return sessionFromDiscovery(disc)
+	disc, err := discovery.CreateFromFile(cacheBlobPath, deviceId, deviceName)
+	if err != nil {
+		return nil, err
+	}
+	return sessionFromDiscovery(disc, nil)
 }
 
 // Login to Spotify using the OAuth method
 func LoginOAuth(deviceName string, clientId string, clientSecret string) (*Session, error) {
-	token := getOAuthToken(clientId, clientSecret)
-	return loginOAuthToken(token.AccessToken, deviceName)
-}
-
-func loginOAuthToken(accessToken string, deviceName string) (*Session, error) {
-	s, err := setupSession()
+	token, err := getOAuthToken(clientId, clientSecret)
 	if err != nil {
-		return s, err
+		return nil, err
 	}
-
-	s.deviceId = utils.GenerateDeviceId(deviceName)
-	s.deviceName = deviceName
-
-	err = s.startConnection()
-	if err != nil {
-		return s, err
-	}
-
-	packet := makeLoginBlobPacket("", []byte(accessToken),
-		Spotify.AuthenticationType_AUTHENTICATION_SPOTIFY_TOKEN.Enum(), s.deviceId)
-	return s, s.doLogin(packet, "")
+	return LoginWithAccessToken(token.AccessTokenAt(time.Now()), deviceName)
 }
 
 func (s *Session) doLogin(packet []byte, username string) error {
-	err := s.stream.SendPacket(connection.PacketLogin, packet)
-	if err != nil {
-		log.Fatal("bad shannon write", err)
-	}
-
-	// Pll once for authentication response
-	welcome, err := s.handleLogin()
+	welcome, err := s.authenticate(packet, username)
 	if err != nil {
 		return err
 	}
 
 	// Store the few interesting values
-	s.username = welcome.GetCanonicalUsername()
-	if s.username == "" {
-		// Spotify might not return a canonical username, so reuse the blob's one instead
-		s.username = s.discovery.LoginBlob().Username
+	canonical := welcome.GetCanonicalUsername()
+	if canonical == "" {
+		// Spotify might not return a canonical username, so reuse the provided one instead
+		canonical = username
+		if d := s.Discovery(); canonical == "" && d != nil {
+			canonical = d.LoginBlob().Username
+		}
+	}
+	reusableAuthBlob := welcome.GetReusableAuthCredentials()
+	s.setUser(canonical, reusableAuthBlob)
+	if store := s.config.CredentialStore; store != nil && len(reusableAuthBlob) > 0 {
+		err := store.Put(Credentials{
+			Username: canonical,
+			AuthType: welcome.GetReusableAuthCredentialsType(),
+			AuthData: reusableAuthBlob,
+		})
+		if err != nil {
+			s.Logger().Println("Failed to store the credentials:", err)
+		}
+	}
+
+	if s.config.Language != "" {
+		if err := s.sendPreferredLocale(s.config.Language); err != nil {
+			return err
+		}
 	}
-	s.reusableAuthBlob = welcome.GetReusableAuthCredentials()
 
 	// Poll for acknowledge before loading - needed for gopherjs
 	// s.poll()
-	go s.runPollLoop()
+	now := time.Now().UnixNano()
+	atomic.StoreInt64(&s.lastPacket, now)
+	atomic.StoreInt64(&s.lastPing, now)
+	s.startPollLoop()
+
+	s.setState(StateConnected)
+	s.startResumeWatcher()
+	s.startReporting()
+	if !s.config.SkipCacheVerification {
+		go s.verifyCaches()
+	}
 
 	return nil
 }
 
+// authenticate sends the login packet, and waits for the response of the server, within the limits of the login
+// limiter. The logins without username, e.g. with an access token, are not limited, as they can't be told apart.
+func (s *Session) authenticate(packet []byte, username string) (*Spotify.APWelcome, error) {
+	limiter := s.loginLimiter()
+	limited := username != ""
+	if limited {
+		if err := limiter.Allow(username); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.currentStream().SendPacket(connection.PacketLogin, packet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send login packet: %v", err)
+	}
+
+	// Poll once for authentication response
+	welcome, err := s.handleLogin()
+	if errors.Is(err, ErrAuthenticationFailed) {
+		// The refusals of the access point are not caused by the credentials
+		if authErr, ok := err.(*AuthError); (!ok || !authErr.Retryable()) && limited {
+			limiter.Failure(username)
+		}
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	if limited {
+		limiter.Reset(username)
+	}
+	return welcome, nil
+}
+
 func (s *Session) handleLogin() (*Spotify.APWelcome, error) {
-	cmd, data, err := s.stream.RecvPacket()
+	cmd, data, err := s.currentStream().RecvPacket()
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %v", err)
 	}
 
 	if cmd == connection.PacketAuthFailure {
-		return nil, fmt.Errorf("authentication failed")
+		return nil, parseLoginFailed(data)
 	} else if cmd == connection.PacketAPWelcome {
 		welcome := &Spotify.APWelcome{}
 		err := proto.Unmarshal(data, welcome)
 		if err != nil {
 			return nil, fmt.Errorf("authentication failed: %v", err)
 		}
-		fmt.Println("Authentication succeeded: Welcome,", welcome.GetCanonicalUsername())
-		fmt.Println("Blob type:", welcome.GetReusableAuthCredentialsType())
+		s.Logger().Println("Authentication succeeded: Welcome,", welcome.GetCanonicalUsername())
+		s.Logger().Println("Blob type:", welcome.GetReusableAuthCredentialsType())
 		return welcome, nil
 	} else {
 		return nil, fmt.Errorf("authentication failed: unexpected cmd %v", cmd)
 	}
 }
 
-func (s *Session) getLoginBlobPacket(blob utils.BlobInfo) []byte {
-	data, _ := base64.StdEncoding.DecodeString(blob.DecodedBlob)
+// sendPreferredLocale tells the servers which language to use for the metadata, e.g. "en"
+func (s *Session) sendPreferredLocale(locale string) error {
+	buf := new(bytes.Buffer)
+	buf.Write([]byte{0x00, 0x00, 0x10, 0x00, 0x02})
+	buf.WriteString("preferred-locale")
+	buf.WriteString(locale)
+
+	err := s.currentStream().SendPacket(connection.PacketPreferredLocale, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to send preferred locale: %v", err)
+	}
+	return nil
+}
+
+func (s *Session) getLoginBlobPacket(blob utils.BlobInfo) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(blob.DecodedBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode login blob: %v", err)
+	}
 
 	buffer := bytes.NewBuffer(data)
 	buffer.ReadByte()
@@ -170,18 +312,16 @@ func (s *Session) getLoginBlobPacket(blob utils.BlobInfo) []byte {
 	buffer.ReadByte()
 	authData := readBytes(buffer)
 
-	return makeLoginBlobPacket(blob.Username, authData, &authType, s.deviceId)
+	return s.makeLoginBlobPacket(blob.Username, authData, &authType)
 }
 
-func makeLoginPasswordPacket(username string, password string, deviceId string) []byte {
-	return makeLoginBlobPacket(username, []byte(password),
-		Spotify.AuthenticationType_AUTHENTICATION_USER_PASS.Enum(), deviceId)
+func (s *Session) makeLoginPasswordPacket(username string, password string) ([]byte, error) {
+	return s.makeLoginBlobPacket(username, []byte(password),
+		Spotify.AuthenticationType_AUTHENTICATION_USER_PASS.Enum())
 }
 
-func makeLoginBlobPacket(username string, authData []byte,
-	authType *Spotify.AuthenticationType, deviceId string) []byte {
-	versionString := "librespot-golang_" + Version + "_" + BuildID
-
+func (s *Session) makeLoginBlobPacket(username string, authData []byte,
+	authType *Spotify.AuthenticationType) ([]byte, error) {
 	packet := &Spotify.ClientResponseEncrypted{
 		LoginCredentials: &Spotify.LoginCredentials{
 			Username: proto.String(username),
@@ -189,17 +329,17 @@ func makeLoginBlobPacket(username string, authData []byte,
 			AuthData: authData,
 		},
 		SystemInfo: &Spotify.SystemInfo{
-			CpuFamily:               Spotify.CpuFamily_CPU_UNKNOWN.Enum(),
-			Os:                      Spotify.Os_OS_UNKNOWN.Enum(),
-			SystemInformationString: proto.String("librespot-golang"),
-			DeviceId:                proto.String(deviceId),
+			CpuFamily:               s.config.CpuFamily.Enum(),
+			Os:                      s.config.Os.Enum(),
+			SystemInformationString: proto.String(s.config.SystemInformation),
+			DeviceId:                proto.String(s.deviceId),
 		},
-		VersionString: proto.String(versionString),
+		VersionString: proto.String(s.config.VersionString),
 	}
 
 	packetData, err := proto.Marshal(packet)
 	if err != nil {
-		log.Fatal("login marshaling error: ", err)
+		return nil, fmt.Errorf("failed to marshal login packet: %v", err)
 	}
-	return packetData
+	return packetData, nil
 }
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoginCooldownError is returned when logging in a user whose previous attempts failed too many times, until the
+// cooldown expires. No request is sent to Spotify meanwhile.
+type LoginCooldownError struct {
+	Username  string
+	Failures  int
+	Remaining time.Duration
+}
+
+func (e *LoginCooldownError) Error() string {
+	return fmt.Sprintf("login of %q blocked for %v after %d failed attempts", e.Username,
+		e.Remaining.Round(time.Second), e.Failures)
+}
+
+// LoginLimitPolicy controls the cooldowns applied after failed logins. The first cooldown lasts Cooldown, and doubles
+// at every further failure, up to MaxCooldown.
+type LoginLimitPolicy struct {
+	// FreeAttempts is the number of consecutive failures allowed before applying the cooldowns
+	FreeAttempts int
+	Cooldown     time.Duration
+	MaxCooldown  time.Duration
+}
+
+// DefaultLoginLimitPolicy allows 3 failed attempts, then waits from 30 seconds up to 1 hour between the attempts
+var DefaultLoginLimitPolicy = LoginLimitPolicy{
+	FreeAttempts: 3,
+	Cooldown:     30 * time.Second,
+	MaxCooldown:  1 * time.Hour,
+}
+
+// loginFailures are the consecutive failed logins of a user
+type loginFailures struct {
+	count int
+	until time.Time
+}
+
+// LoginLimiter tracks the failed logins of each user, and blocks the new attempts during the cooldowns, so that a
+// misconfigured application doesn't get the account locked by retrying bad credentials. It can be shared between
+// sessions.
+type LoginLimiter struct {
+	policy LoginLimitPolicy
+	now    func() time.Time
+
+	lock     sync.Mutex
+	failures map[string]*loginFailures
+}
+
+// DefaultLoginLimiter is shared by the sessions without a LoginLimiter configured
+var DefaultLoginLimiter = NewLoginLimiter(DefaultLoginLimitPolicy)
+
+// NewLoginLimiter creates a limiter applying the policy
+func NewLoginLimiter(policy LoginLimitPolicy) *LoginLimiter {
+	return &LoginLimiter{
+		policy:   policy,
+		now:      time.Now,
+		failures: map[string]*loginFailures{},
+	}
+}
+
+// Allow returns a *LoginCooldownError if the user must wait before logging in again
+func (l *LoginLimiter) Allow(username string) error {
+	if remaining := l.Cooldown(username); remaining > 0 {
+		return &LoginCooldownError{Username: username, Failures: l.Failures(username), Remaining: remaining}
+	}
+	return nil
+}
+
+// Failure records a failed login of the user, and starts a cooldown if it failed too many times
+func (l *LoginLimiter) Failure(username string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	f, ok := l.failures[username]
+	if !ok {
+		f = &loginFailures{}
+		l.failures[username] = f
+	}
+	f.count++
+
+	excess := f.count - l.policy.FreeAttempts
+	if excess <= 0 {
+		return
+	}
+	cooldown := l.policy.Cooldown
+	for i := 1; i < excess && cooldown < l.policy.MaxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > l.policy.MaxCooldown {
+		cooldown = l.policy.MaxCooldown
+	}
+	f.until = l.now().Add(cooldown)
+}
+
+// Reset forgets the failed logins of the user. It is called after every successful login, and can be called once
+// the credentials have been fixed.
+func (l *LoginLimiter) Reset(username string) {
+	l.lock.Lock()
+	delete(l.failures, username)
+	l.lock.Unlock()
+}
+
+// Failures returns the number of consecutive failed logins of the user
+func (l *LoginLimiter) Failures(username string) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if f, ok := l.failures[username]; ok {
+		return f.count
+	}
+	return 0
+}
+
+// Cooldown returns how long the user must wait before logging in again, zero if it can log in now
+func (l *LoginLimiter) Cooldown(username string) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	f, ok := l.failures[username]
+	if !ok {
+		return 0
+	}
+	if remaining := f.until.Sub(l.now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// loginLimiter returns the limiter of the session
+func (s *Session) loginLimiter() *LoginLimiter {
+	if s.config.LoginLimiter == nil {
+		return DefaultLoginLimiter
+	}
+	return s.config.LoginLimiter
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewLoginLimiter(LoginLimitPolicy{FreeAttempts: 2, Cooldown: 10 * time.Second, MaxCooldown: 30 * time.Second})
+	l.now = func() time.Time { return now }
+
+	l.Failure("user")
+	l.Failure("user")
+	if err := l.Allow("user"); err != nil {
+		t.Fatalf("login blocked after the free attempts: %v", err)
+	}
+
+	for _, want := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second} {
+		l.Failure("user")
+		if cooldown := l.Cooldown("user"); cooldown != want {
+			t.Errorf("got cooldown %v after %d failures, want %v", cooldown, l.Failures("user"), want)
+		}
+	}
+
+	err, ok := l.Allow("user").(*LoginCooldownError)
+	if !ok || err.Failures != 6 || err.Remaining != 30*time.Second {
+		t.Errorf("got %v, want a cooldown error", err)
+	}
+	if l.Allow("other") != nil {
+		t.Error("the cooldown applies to other users")
+	}
+
+	now = now.Add(30 * time.Second)
+	if err := l.Allow("user"); err != nil {
+		t.Errorf("login blocked after the cooldown: %v", err)
+	}
+	l.Reset("user")
+	if l.Failures("user") != 0 {
+		t.Error("failures not reset")
+	}
+}
+
+func TestLoginDuringCooldown(t *testing.T) {
+	l := NewLoginLimiter(LoginLimitPolicy{Cooldown: time.Minute, MaxCooldown: time.Minute})
+	l.Failure("user")
+
+	// The session has no connection: the login must fail before sending anything
+	s := &Session{config: SessionConfig{LoginLimiter: l}}
+	if _, ok := s.doLogin(nil, "user").(*LoginCooldownError); !ok {
+		t.Error("login attempted during the cooldown")
+	}
+}
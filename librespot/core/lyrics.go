@@ -0,0 +1,17 @@
+package core
+
+import (
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/lyrics"
+)
+
+// GetLyrics returns the lyrics of a track, from its URI, open.spotify.com URL or base62 id, with the start times of
+// the lines if they are synced, which lyrics.Sync follows during the playback. spclient.ErrNoLyrics is returned if
+// the track has none.
+func (s *Session) GetLyrics(trackID string) (*lyrics.Lyrics, error) {
+	id, err := ids.ParseKind(ids.KindTrack, trackID)
+	if err != nil {
+		return nil, err
+	}
+	return s.SpClient().Lyrics(id.Base62())
+}
@@ -2,21 +2,50 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
 )
 
+// ErrOAuthBrowserDisabled is returned by LoginOAuth in builds with the nooauth tag, which leave out the local server
+// receiving the OAuth callback. Access tokens obtained otherwise can still be used.
+var ErrOAuthBrowserDisabled = errors.New("OAuth browser login is disabled in this build")
+
 type OAuth struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	Scope        string `json:"scope"`
-	Error        string
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int `json:"expires_in"`
+	Error     string
+}
+
+// AccessTokenAt returns the access token with its metadata, the token having been issued at the specified time
+func (o *OAuth) AccessTokenAt(issued time.Time) AccessToken {
+	token := AccessToken{Token: o.AccessToken}
+	if o.ExpiresIn > 0 {
+		token.Expiry = issued.Add(time.Duration(o.ExpiresIn) * time.Second)
+	}
+	if o.Scope != "" {
+		token.Scopes = strings.Fields(o.Scope)
+	}
+	return token
 }
 
 func GetOauthAccessToken(code string, redirectUri string, clientId string, clientSecret string) (*OAuth, error) {
+	return GetOauthAccessTokenWithClient(connection.NewDialer().HTTPClient(), code, redirectUri, clientId, clientSecret)
+}
+
+// GetOauthAccessTokenWithClient is similar to GetOauthAccessToken, but performs the request with the specified HTTP
+// client, e.g. the one of a SessionConfig
+func GetOauthAccessTokenWithClient(client *http.Client, code string, redirectUri string, clientId string,
+	clientSecret string) (*OAuth, error) {
 	val := url.Values{}
 	val.Set("grant_type", "authorization_code")
 	val.Set("code", code)
@@ -24,12 +53,12 @@ func GetOauthAccessToken(code string, redirectUri string, clientId string, clien
 	val.Set("client_id", clientId)
 	val.Set("client_secret", clientSecret)
 
-	resp, err := http.PostForm("https://accounts.spotify.com/api/token", val)
+	resp, err := client.PostForm("https://accounts.spotify.com/api/token", val)
 	if err != nil {
 		// Retry since there is an nginx bug that causes http2 streams to get
 		// an initial REFUSED_STREAM response
 		// https://github.com/curl/curl/issues/804
-		resp, err = http.PostForm("https://accounts.spotify.com/api/token", val)
+		resp, err = client.PostForm("https://accounts.spotify.com/api/token", val)
 		if err != nil {
 			return nil, err
 		}
@@ -49,32 +78,3 @@ func GetOauthAccessToken(code string, redirectUri string, clientId string, clien
 	}
 	return &auth, nil
 }
-
-func getOAuthToken(clientId string, clientSecret string) OAuth {
-	ch := make(chan OAuth)
-
-	fmt.Println("go to this url")
-	urlPath := "https://accounts.spotify.com/authorize?" +
-		"client_id=" + clientId +
-		"&response_type=code" +
-		"&redirect_uri=http://localhost:8888/callback" +
-		"&scope=streaming"
-	fmt.Println(urlPath)
-
-	http.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		params := r.URL.Query()
-		auth, err := GetOauthAccessToken(params.Get("code"), "http://localhost:8888/callback", clientId, clientSecret)
-		if err != nil {
-			fmt.Fprintf(w, "Error getting token %q", err)
-			return
-		}
-		fmt.Fprintf(w, "Got token, loggin in")
-		ch <- *auth
-	})
-
-	go func() {
-		log.Fatal(http.ListenAndServe(":8888", nil))
-	}()
-
-	return <-ch
-}
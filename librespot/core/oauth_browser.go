@@ -0,0 +1,55 @@
+//go:build !nooauth
+// +build !nooauth
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// getOAuthToken asks the user to authorize the application in a browser, and waits for the callback on a local server,
+// which is shut down once the token is received
+func getOAuthToken(clientId string, clientSecret string) (OAuth, error) {
+	// The callbacks after the first one don't block their handler
+	ch := make(chan OAuth, 1)
+	errCh := make(chan error, 1)
+
+	fmt.Println("go to this url")
+	urlPath := "https://accounts.spotify.com/authorize?" +
+		"client_id=" + clientId +
+		"&response_type=code" +
+		"&redirect_uri=http://localhost:8888/callback" +
+		"&scope=streaming"
+	fmt.Println(urlPath)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		auth, err := GetOauthAccessToken(params.Get("code"), "http://localhost:8888/callback", clientId, clientSecret)
+		if err != nil {
+			fmt.Fprintf(w, "Error getting token %q", err)
+			return
+		}
+		fmt.Fprintf(w, "Got token, loggin in")
+		select {
+		case ch <- *auth:
+		default:
+		}
+	})
+
+	server := &http.Server{Addr: ":8888", Handler: mux}
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case auth := <-ch:
+		// Shutdown waits for the response to the callback, and frees the port for the next login
+		server.Shutdown(context.Background())
+		return auth, nil
+	case err := <-errCh:
+		return OAuth{}, fmt.Errorf("failed to start OAuth callback server: %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build nooauth
+// +build nooauth
+
+package core
+
+func getOAuthToken(clientId string, clientSecret string) (OAuth, error) {
+	return OAuth{}, ErrOAuthBrowserDisabled
+}
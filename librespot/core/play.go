@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// Playback is a track loaded by PlayURI, whose audio is being downloaded
+type Playback struct {
+	// Track is the metadata of the track played, one of its alternatives if it is not available in the country of
+	// the user
+	Track *metadata.TrackInfo
+	// Format is the audio file selected, and why it was
+	Format *player.FormatSelection
+	// Audio is the decrypted Ogg Vorbis stream of the track, which can be passed to a decoder right away
+	Audio *player.AudioFile
+}
+
+// PlayURI loads a track from its URI, open.spotify.com URL or base62 id in one call: its metadata is fetched, an
+// Ogg Vorbis file is selected with the quality of the session, and its audio key and data are requested. The
+// returned audio stream is ready to be decoded and played. The track goes through the content filters of the player
+// first, and player.ErrFiltered is returned if it is vetoed.
+//
+// If the context is done before the audio key is received, PlayURI returns its error.
+func (s *Session) PlayURI(ctx context.Context, uri string) (*Playback, error) {
+	id, err := ids.ParseKind(ids.KindTrack, uri)
+	if err != nil {
+		return nil, err
+	}
+	p := s.Player()
+	if p == nil {
+		return nil, ErrNotConnected
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		playback *Playback
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		playback, err := s.loadPlayback(p, id)
+		done <- result{playback, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.playback, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// loadPlayback applies the content filters of the player to the track, fetches its metadata, or the one of a playable
+// alternative, and loads its audio
+func (s *Session) loadPlayback(p *player.Player, id ids.Id) (*Playback, error) {
+	id, err := p.FilterContent(id)
+	if err != nil {
+		return nil, err
+	}
+	track, err := metadata.NewCatalog(s.Mercury(), s.Country()).PlayableTrack(id.Base62())
+	if err != nil {
+		return nil, err
+	}
+
+	// The Ogg Vorbis files are the only ones the player can parse the header and normalize
+	format, err := player.SelectQuality(track.Files, p.Quality(), p.MaxBitrate(), "OGG")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select the audio file of %s: %v", track.Uri, err)
+	}
+
+	audio, err := p.LoadTrack(format.File, track.Raw.GetGid())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %v", track.Uri, err)
+	}
+	audio.SetOrigin(player.PlayOrigin{ContextUri: track.Uri})
+
+	return &Playback{Track: track, Format: format, Audio: audio}, nil
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+func TestPlayURI(t *testing.T) {
+	s := &Session{mercuryConstructor: mercury.CreateMercury, dialer: connection.NewDialer()}
+	if _, err := s.PlayURI(context.Background(), "spotify:track:4uLU6hMCjMI75M1A2tKUQC"); err != ErrNotConnected {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+
+	stream := &fakeStream{recvPackets: make(chan shanPacket), sendPackets: make(chan shanPacket, 10)}
+	if err := s.setStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PlayURI(context.Background(), "spotify:album:4uLU6hMCjMI75M1A2tKUQC"); err == nil {
+		t.Error("Album URI accepted")
+	}
+
+	// The metadata request is never answered
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := s.PlayURI(ctx, "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"); err != context.DeadlineExceeded {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+	if packet := <-stream.sendPackets; packet.cmd != connection.PacketMercuryReq {
+		t.Errorf("Metadata not requested: %v", packet.cmd)
+	}
+	s.Mercury().CancelPending()
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// ErrPremiumRequired is returned by the features which need a Spotify Premium account
+var ErrPremiumRequired = errors.New("a Spotify Premium account is required")
+
+// ProductInfo holds the attributes of the subscription of the user, sent by the server after the login, e.g. "type"
+// ("premium", "free", ...), "catalogue" or "ads"
+type ProductInfo map[string]string
+
+// Capabilities tell what the account of the user can do, derived from its ProductInfo
+type Capabilities struct {
+	// Premium is set for the paid accounts
+	Premium bool
+	// OnDemand is set if any track can be played, in any order. Free accounts on mobile devices are restricted to
+	// the shuffled playback of playlists, albums and artists.
+	OnDemand bool
+	// ShuffleOnly is set if the tracks can only be played shuffled
+	ShuffleOnly bool
+	// Ads is set if ads are inserted between the tracks
+	Ads bool
+	// MaxBitrate is the largest bitrate of the files whose key can be requested, in kbps
+	MaxBitrate int
+}
+
+// premiumCapabilities are the capabilities assumed until the product info is received
+var premiumCapabilities = Capabilities{Premium: true, OnDemand: true, MaxBitrate: 320}
+
+// Capabilities returns the capabilities of an account with the product attributes
+func (p ProductInfo) Capabilities() Capabilities {
+	if p == nil {
+		return premiumCapabilities
+	}
+
+	c := Capabilities{
+		Premium: p["type"] == "premium",
+		Ads:     p["ads"] == "1",
+	}
+	c.OnDemand = c.Premium
+	if onDemand, ok := p["on-demand"]; ok {
+		c.OnDemand = onDemand == "1"
+	}
+	c.ShuffleOnly = !c.OnDemand
+
+	c.MaxBitrate = 160
+	if c.Premium {
+		c.MaxBitrate = 320
+	}
+	return c
+}
+
+// parseProductInfo parses the XML document of a PacketProductInfo packet, of the form
+// <products><product><type>premium</type>...</product></products>
+func parseProductInfo(data []byte) (ProductInfo, error) {
+	var doc struct {
+		Products []struct {
+			Attributes []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"product"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid product info: %v", err)
+	}
+
+	info := ProductInfo{}
+	for _, product := range doc.Products {
+		for _, attribute := range product.Attributes {
+			info[attribute.XMLName.Local] = attribute.Value
+		}
+	}
+	return info, nil
+}
+
+// ProductInfo returns the subscription attributes of the user, nil until received after the login
+func (s *Session) ProductInfo() ProductInfo {
+	s.listenersLock.Lock()
+	defer s.listenersLock.Unlock()
+	return s.productInfo
+}
+
+// Capabilities returns what the account of the user can do. The capabilities of a premium account are assumed until
+// the product info is received.
+func (s *Session) Capabilities() Capabilities {
+	return s.ProductInfo().Capabilities()
+}
+
+// RequirePremium returns ErrPremiumRequired if the user doesn't have a premium account
+func (s *Session) RequirePremium() error {
+	if !s.Capabilities().Premium {
+		return ErrPremiumRequired
+	}
+	return nil
+}
+
+// setProductInfo records the product info, and restricts the player to the capabilities of the account, so that it
+// doesn't request the files the account can't play
+func (s *Session) setProductInfo(info ProductInfo) {
+	s.listenersLock.Lock()
+	s.productInfo = info
+	s.listenersLock.Unlock()
+
+	capabilities := info.Capabilities()
+	maxBitrate := 0
+	if !capabilities.Premium {
+		maxBitrate = capabilities.MaxBitrate
+	}
+	if p := s.Player(); p != nil {
+		p.SetMaxBitrate(maxBitrate)
+		p.SetShuffleOnly(capabilities.ShuffleOnly)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+const freeProductInfo = `<?xml version="1.0" encoding="utf-8" ?>
+<products>
+  <product>
+    <type>free</type>
+    <ads>1</ads>
+    <catalogue>free</catalogue>
+    <head-files-url>https://heads-fa.spotify.com/head/{file_id}</head-files-url>
+  </product>
+</products>`
+
+func TestProductInfo(t *testing.T) {
+	stream := &fakeStream{}
+	s := &Session{player: player.CreatePlayer(stream, mercury.CreateMercury(stream))}
+	if err := s.RequirePremium(); err != nil {
+		t.Errorf("premium assumed before the product info, got %v", err)
+	}
+
+	if err := s.handle(connection.PacketProductInfo, []byte(freeProductInfo)); err != nil {
+		t.Fatal(err)
+	}
+	if s.ProductInfo()["catalogue"] != "free" {
+		t.Errorf("got product info %v", s.ProductInfo())
+	}
+	want := Capabilities{ShuffleOnly: true, Ads: true, MaxBitrate: 160}
+	if got := s.Capabilities(); got != want {
+		t.Errorf("got capabilities %+v, want %+v", got, want)
+	}
+	if err := s.RequirePremium(); err != ErrPremiumRequired {
+		t.Errorf("got %v, want ErrPremiumRequired", err)
+	}
+	if s.player.MaxBitrate() != 160 || !s.player.ShuffleOnly() {
+		t.Errorf("player not restricted")
+	}
+
+	premium := ProductInfo{"type": "premium", "ads": "0"}
+	want = Capabilities{Premium: true, OnDemand: true, MaxBitrate: 320}
+	if got := premium.Capabilities(); got != want {
+		t.Errorf("got capabilities %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// ConnectionState is the state of the connection between the session and the Spotify servers
+type ConnectionState int
+
+const (
+	// StateConnected means the session is connected and authenticated
+	StateConnected ConnectionState = iota
+	// StateReconnecting means the connection dropped, and the session is trying to establish a new one
+	StateReconnecting
+	// StateDisconnected means the session is not connected anymore, and won't try to reconnect by itself
+	StateDisconnected
+)
+
+func (c ConnectionState) String() string {
+	switch c {
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateDisconnected:
+		return "disconnected"
+	default:
+		return fmt.Sprintf("ConnectionState(%d)", int(c))
+	}
+}
+
+// StateCallback is called whenever the connection state of a session changes
+type StateCallback func(state ConnectionState)
+
+// ReconnectPolicy controls how a session tries to reconnect after the connection dropped. The delay between two
+// attempts starts at InitialDelay and doubles at every failed attempt, up to MaxDelay.
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of attempts before giving up and switching to StateDisconnected. Zero means
+	// retrying forever.
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// DefaultReconnectPolicy retries forever, with delays from 1 second up to 1 minute
+var DefaultReconnectPolicy = ReconnectPolicy{
+	MaxAttempts:  0,
+	InitialDelay: 1 * time.Second,
+	MaxDelay:     1 * time.Minute,
+}
+
+// State returns the current connection state of the session
+func (s *Session) State() ConnectionState {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.state
+}
+
+// OnStateChange registers a callback notified of every connection state change
+func (s *Session) OnStateChange(cb StateCallback) {
+	s.stateLock.Lock()
+	s.stateCallbacks = append(s.stateCallbacks, cb)
+	s.stateLock.Unlock()
+}
+
+// SetReconnectPolicy changes how the session reconnects when the connection drops
+func (s *Session) SetReconnectPolicy(policy ReconnectPolicy) {
+	s.stateLock.Lock()
+	s.reconnectPolicy = policy
+	s.stateLock.Unlock()
+}
+
+func (s *Session) setState(state ConnectionState) {
+	s.stateLock.Lock()
+	if s.state == state {
+		s.stateLock.Unlock()
+		return
+	}
+	s.state = state
+	callbacks := append([]StateCallback{}, s.stateCallbacks...)
+	s.stateLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(state)
+	}
+}
+
+// doReconnect resolves a new access point, and authenticates again using the reusable credentials obtained during
+// the initial login. Mercury subscriptions are then sent again on the new connection.
+func (s *Session) doReconnect() error {
+	s.disconnect()
+
+	err := s.doConnect()
+	if err != nil {
+		return err
+	}
+
+	err = s.startConnection()
+	if err != nil {
+		return err
+	}
+
+	username := s.Username()
+	packet, err := s.makeLoginBlobPacket(username, s.ReusableAuthBlob(),
+		Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum())
+	if err != nil {
+		return err
+	}
+
+	err = s.doLogin(packet, username)
+	if err != nil {
+		return err
+	}
+
+	return s.Mercury().Resubscribe()
+}
+
+// planReconnect starts reconnecting in the background, following the reconnect policy of the session
+func (s *Session) planReconnect() {
+	s.setState(StateReconnecting)
+
+	s.stateLock.Lock()
+	policy := s.reconnectPolicy
+	s.stateLock.Unlock()
+
+	go func() {
+		delay := policy.InitialDelay
+
+		for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+			time.Sleep(delay)
+
+			if s.isClosed() {
+				return
+			}
+
+			err := s.doReconnect()
+			if err == nil {
+				s.setState(StateConnected)
+				return
+			}
+
+			s.Logger().Printf("Reconnection attempt %d failed: %v\n", attempt, err)
+			if authErr, ok := err.(*AuthError); ok {
+				if !authErr.Retryable() {
+					// The credentials won't be accepted on the next attempts either
+					break
+				}
+				if authErr.RetryDelay > delay {
+					delay = authErr.RetryDelay
+				}
+			}
+
+			delay *= 2
+			if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+				delay = policy.MaxDelay
+			}
+		}
+
+		s.disconnect()
+		s.setState(StateDisconnected)
+	}()
+}
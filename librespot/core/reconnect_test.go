@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/aptest"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+// faultyTransport injects faults into the first connection of a transport, the next ones being left alone
+type faultyTransport struct {
+	connection.Transport
+	faults connection.Faults
+
+	lock  sync.Mutex
+	first *connection.FaultyConn
+}
+
+func (t *faultyTransport) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := t.Transport.DialContext(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.first == nil {
+		t.first = connection.NewFaultyConn(conn, t.faults)
+		return t.first, nil
+	}
+	return conn, nil
+}
+
+func TestReconnectAfterFailure(t *testing.T) {
+	server, err := aptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Users["user"] = "password"
+	server.HandleMercury("hm://test/", func(_ string, req mercury.Request) mercury.Response {
+		return mercury.Response{StatusCode: 200}
+	})
+
+	// The first connection is slow, returns short reads, and fails once the session logged in and sent a few requests
+	transport := &faultyTransport{
+		Transport: server.Transport(),
+		faults: connection.Faults{
+			Seed:          5,
+			Jitter:        time.Millisecond,
+			ShortReadRate: 0.3,
+			FailAfter:     100,
+		},
+	}
+	s, err := NewSession(SessionConfig{
+		ApAddress:      aptest.Address,
+		Transport:      transport,
+		ApServerKey:    server.PublicKey(),
+		LoginLimiter:   NewLoginLimiter(LoginLimitPolicy{}),
+		MercuryTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close(context.Background())
+	s.SetReconnectPolicy(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond})
+
+	states := make(chan ConnectionState, 10)
+	s.OnStateChange(func(state ConnectionState) {
+		states <- state
+	})
+	if err := s.Login("user", "password"); err != nil {
+		t.Fatal(err)
+	}
+	<-states
+
+	// The requests keep the first connection busy until it fails
+	deadline := time.After(5 * time.Second)
+	reconnecting := false
+wait:
+	for {
+		select {
+		case state := <-states:
+			switch {
+			case state == StateReconnecting:
+				reconnecting = true
+			case state == StateConnected && reconnecting:
+				break wait
+			default:
+				t.Fatalf("Unexpected state %v", state)
+			}
+		case <-deadline:
+			t.Fatal("The session didn't reconnect")
+		default:
+			if !reconnecting {
+				s.Mercury().Do(mercury.Request{Method: "GET", Uri: "hm://test/request"})
+			} else {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+
+	if stats := transport.first.Stats(); stats.Failed == 0 || stats.ShortReads == 0 {
+		t.Errorf("Faults not injected: %+v", stats)
+	}
+	if _, err := s.Mercury().Do(mercury.Request{Method: "GET", Uri: "hm://test/request"}); err != nil {
+		t.Errorf("Request failed after the reconnection: %v", err)
+	}
+	if dialed := server.Transport().Dialed(); len(dialed) != 2 {
+		t.Errorf("Dialed %v", dialed)
+	}
+}
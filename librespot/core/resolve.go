@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// resolveParallelism is the number of tracks of a context whose metadata is fetched at once
+const resolveParallelism = 8
+
+// ResolvedTrack is a track of a context, resolved to the track to play
+type ResolvedTrack struct {
+	// Uri is the track as listed by the context
+	Uri string
+	// Track is the metadata of the track to play: the listed one, its substitute chosen by the content filters, or
+	// its alternative available in the country of the user
+	Track *metadata.TrackInfo
+}
+
+// Relinked returns true if the track to play isn't the one listed by the context
+func (t ResolvedTrack) Relinked() bool {
+	return t.Track.Uri != t.Uri
+}
+
+// SkippedItem is an item of a context which can't be played
+type SkippedItem struct {
+	Uri string
+	// Err is why the item can't be played, e.g. player.ErrFiltered or metadata.ErrUnavailable
+	Err error
+}
+
+// ResolvedContext is the list of the playable tracks of a context, resolved upfront
+type ResolvedContext struct {
+	Uri string
+	// Tracks are the playable tracks, in the order of the context
+	Tracks []ResolvedTrack
+	// Skipped are the items left out, in the order of the context
+	Skipped []SkippedItem
+}
+
+// ResolveProgress is called by ResolveContext every time an item is resolved, with the number of items resolved so
+// far and the number of items of the context
+type ResolveProgress func(resolved int, total int)
+
+// ResolveContext resolves the whole list of tracks of a playlist, an album, the top tracks of an artist or a single
+// track, e.g. for the jukeboxes which need it upfront rather than paging through the context as it plays. The tracks
+// go through the content filters of the player, and are relinked to their alternative if they aren't available in
+// the country of the user. The items which can't be played, including the episodes and the local files, are listed
+// in Skipped instead.
+//
+// The progress is called from the calling goroutine, and may be nil. If the context is done before all the items
+// are resolved, ResolveContext returns its error.
+func (s *Session) ResolveContext(ctx context.Context, uri string, progress ResolveProgress) (*ResolvedContext, error) {
+	id, err := ids.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	p := s.Player()
+	if p == nil {
+		return nil, ErrNotConnected
+	}
+	catalog := metadata.NewCatalog(s.Mercury(), s.Country())
+
+	items, err := s.contextItems(catalog, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		index int
+		track *metadata.TrackInfo
+		err   error
+	}
+	// The results are buffered, so that the workers never block once the context is done
+	results := make(chan result, len(items))
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range items {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	workers := resolveParallelism
+	if len(items) < workers {
+		workers = len(items)
+	}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				track, err := resolveTrack(p, catalog, items[i])
+				results <- result{i, track, err}
+			}
+		}()
+	}
+
+	resolved := make([]result, len(items))
+	for n := range items {
+		select {
+		case r := <-results:
+			resolved[r.index] = r
+			if progress != nil {
+				progress(n+1, len(items))
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	res := &ResolvedContext{Uri: id.Uri()}
+	for i, r := range resolved {
+		if r.err != nil {
+			res.Skipped = append(res.Skipped, SkippedItem{Uri: items[i], Err: r.err})
+		} else {
+			res.Tracks = append(res.Tracks, ResolvedTrack{Uri: items[i], Track: r.track})
+		}
+	}
+	return res, nil
+}
+
+// contextItems returns the URIs of the items of a context
+func (s *Session) contextItems(catalog *metadata.Catalog, id ids.Id) ([]string, error) {
+	var refs []metadata.Ref
+	switch id.Kind {
+	case ids.KindTrack:
+		return []string{id.Uri()}, nil
+	case ids.KindPlaylist:
+		playlist, err := s.Playlists().Get(id.Base62())
+		if err != nil {
+			return nil, err
+		}
+		items := make([]string, 0, len(playlist.Items))
+		for _, item := range playlist.Items {
+			items = append(items, item.Uri)
+		}
+		return items, nil
+	case ids.KindAlbum:
+		album, err := catalog.Album(id.Base62())
+		if err != nil {
+			return nil, err
+		}
+		for _, disc := range album.Discs {
+			refs = append(refs, disc...)
+		}
+	case ids.KindArtist:
+		artist, err := catalog.Artist(id.Base62())
+		if err != nil {
+			return nil, err
+		}
+		refs = artist.TopTracks
+	default:
+		return nil, fmt.Errorf("cannot resolve the tracks of %s", id.Uri())
+	}
+
+	items := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		items = append(items, ref.Uri)
+	}
+	return items, nil
+}
+
+// resolveTrack applies the content filters to the track, and fetches its metadata, or the one of a playable
+// alternative
+func resolveTrack(p *player.Player, catalog *metadata.Catalog, uri string) (*metadata.TrackInfo, error) {
+	id, err := ids.ParseKind(ids.KindTrack, uri)
+	if err != nil {
+		return nil, err
+	}
+	id, err = p.FilterContent(id)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.PlayableTrack(id.Base62())
+}
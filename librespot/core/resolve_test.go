@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/aptest"
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/golang/protobuf/proto"
+)
+
+func trackId(b byte) ids.Id {
+	id := ids.Id{Kind: ids.KindTrack}
+	for i := range id.Gid {
+		id.Gid[i] = b
+	}
+	return id
+}
+
+func TestResolveContext(t *testing.T) {
+	playable, restricted, alternative, unavailable, vetoed := trackId(1), trackId(2), trackId(3), trackId(4), trackId(5)
+	files := []*Spotify.AudioFile{{FileId: []byte{1}}}
+	tracks := map[string]*Spotify.Track{
+		playable.Hex(): {Gid: playable.Gid[:], File: files},
+		restricted.Hex(): {
+			Gid:         restricted.Gid[:],
+			File:        files,
+			Restriction: []*Spotify.Restriction{{CountriesForbidden: proto.String("US")}},
+			Alternative: []*Spotify.Track{{Gid: alternative.Gid[:]}},
+		},
+		alternative.Hex(): {Gid: alternative.Gid[:], File: files},
+		unavailable.Hex(): {Gid: unavailable.Gid[:]},
+		vetoed.Hex():      {Gid: vetoed.Gid[:], File: files},
+	}
+
+	server, err := aptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Users["user"] = "password"
+	server.HandleMercury("hm://metadata/4/track/", func(_ string, req mercury.Request) mercury.Response {
+		track, ok := tracks[strings.TrimPrefix(req.Uri, "hm://metadata/4/track/")]
+		if !ok {
+			return mercury.Response{StatusCode: 404}
+		}
+		data, _ := proto.Marshal(track)
+		return mercury.Response{StatusCode: 200, Payload: [][]byte{data}}
+	})
+	items := []string{playable.Uri(), restricted.Uri(), "spotify:episode:4uLU6hMCjMI75M1A2tKUQC", unavailable.Uri(),
+		vetoed.Uri()}
+	server.HandleMercury("hm://playlist/v2/playlist/", func(_ string, req mercury.Request) mercury.Response {
+		content := &Spotify.SelectedListContent{Contents: &Spotify.ListItems{}}
+		for _, uri := range items {
+			content.Contents.Items = append(content.Contents.Items, &Spotify.Item{Uri: proto.String(uri)})
+		}
+		data, _ := proto.Marshal(content)
+		return mercury.Response{StatusCode: 200, Payload: [][]byte{data}}
+	})
+
+	s, err := NewSession(SessionConfig{
+		ApAddress:    aptest.Address,
+		Transport:    server.Transport(),
+		ApServerKey:  server.PublicKey(),
+		LoginLimiter: NewLoginLimiter(LoginLimitPolicy{}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close(context.Background())
+	if err := s.Login("user", "password"); err != nil {
+		t.Fatal(err)
+	}
+	// The restrictions apply to the country, which is received after the welcome
+	deadline := time.Now().Add(time.Second)
+	for s.Country() != "US" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.Player().AddContentFilter(func(candidate player.Candidate) player.FilterDecision {
+		return player.FilterDecision{Skip: candidate.Id == vetoed}
+	})
+
+	var progress []int
+	resolved, err := s.ResolveContext(context.Background(), "spotify:playlist:4uLU6hMCjMI75M1A2tKUQC",
+		func(resolved int, total int) {
+			if total != len(items) {
+				t.Errorf("Got a total of %d items", total)
+			}
+			progress = append(progress, resolved)
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != len(items) || progress[len(progress)-1] != len(items) {
+		t.Errorf("Got progress %v", progress)
+	}
+
+	if len(resolved.Tracks) != 2 || resolved.Tracks[0].Relinked() || resolved.Tracks[0].Uri != playable.Uri() ||
+		!resolved.Tracks[1].Relinked() || resolved.Tracks[1].Track.Uri != alternative.Uri() {
+		t.Errorf("Got tracks %+v", resolved.Tracks)
+	}
+	if len(resolved.Skipped) != 3 || resolved.Skipped[0].Uri != items[2] ||
+		resolved.Skipped[1].Err != metadata.ErrUnavailable || resolved.Skipped[2].Err != player.ErrFiltered {
+		t.Errorf("Got skipped items %+v", resolved.Skipped)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.ResolveContext(ctx, playable.Uri(), nil); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := s.ResolveContext(context.Background(), "spotify:show:4uLU6hMCjMI75M1A2tKUQC", nil); err == nil {
+		t.Error("Show resolved")
+	}
+}
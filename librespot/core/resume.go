@@ -0,0 +1,105 @@
+package core
+
+import (
+	"time"
+)
+
+// ResumeDetection controls the detection of system suspends (e.g. laptops going to sleep). After a resume, the
+// connection to the Spotify servers is most likely dead without the socket knowing it yet, so the session
+// proactively reconnects instead of waiting for a timeout.
+type ResumeDetection struct {
+	Enabled bool
+	// CheckInterval is the interval at which the clocks are compared
+	CheckInterval time.Duration
+	// Threshold is the minimum time the system must have been suspended for before reconnecting
+	Threshold time.Duration
+}
+
+// DefaultResumeDetection checks the clocks every 5 seconds, and reconnects after suspends of 30 seconds or more
+var DefaultResumeDetection = ResumeDetection{
+	Enabled:       true,
+	CheckInterval: 5 * time.Second,
+	Threshold:     30 * time.Second,
+}
+
+// ResumeCallback is called when the session detects the system resumed from a suspend, with the estimated duration
+// of the suspend
+type ResumeCallback func(suspended time.Duration)
+
+// SetResumeDetection changes how system suspends are detected. It must be called before logging in.
+func (s *Session) SetResumeDetection(detection ResumeDetection) {
+	s.stateLock.Lock()
+	s.resumeDetection = detection
+	s.stateLock.Unlock()
+}
+
+// OnResume registers a callback notified when the system resumed from a suspend. The session reconnects right after
+// the callbacks are called.
+func (s *Session) OnResume(cb ResumeCallback) {
+	s.stateLock.Lock()
+	s.resumeCallbacks = append(s.resumeCallbacks, cb)
+	s.stateLock.Unlock()
+}
+
+// startResumeWatcher starts the goroutine watching for suspends, if enabled and not already started
+func (s *Session) startResumeWatcher() {
+	s.stateLock.Lock()
+	detection := s.resumeDetection
+	if !detection.Enabled || s.resumeWatching {
+		s.stateLock.Unlock()
+		return
+	}
+	s.resumeWatching = true
+	s.stateLock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(detection.CheckInterval)
+		defer ticker.Stop()
+
+		last := time.Now()
+		for now := range ticker.C {
+			if s.isClosed() {
+				return
+			}
+
+			// time.Sub uses the monotonic clock, which stops during suspends on most systems, while the wall clock
+			// keeps going. On systems where the monotonic clock keeps going, the tick simply arrives late.
+			suspended := suspendedFor(now.Round(0).Sub(last.Round(0)), now.Sub(last), detection.CheckInterval)
+			last = now
+
+			if suspended >= detection.Threshold {
+				s.handleResume(suspended)
+			}
+		}
+	}()
+}
+
+func (s *Session) handleResume(suspended time.Duration) {
+	s.Logger().Printf("System resumed after %v, reconnecting\n", suspended)
+
+	s.stateLock.Lock()
+	callbacks := append([]ResumeCallback{}, s.resumeCallbacks...)
+	s.stateLock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(suspended)
+	}
+
+	// Closing the socket makes the poll loop fail, which triggers the reconnection
+	if s.State() == StateConnected {
+		s.disconnect()
+	}
+}
+
+// suspendedFor estimates how long the system has been suspended between two checks, from the elapsed wall clock
+// and monotonic clock durations
+func suspendedFor(wall time.Duration, monotonic time.Duration, interval time.Duration) time.Duration {
+	suspended := wall - monotonic
+	if late := monotonic - interval; late > suspended {
+		suspended = late
+	}
+	if suspended < 0 {
+		return 0
+	}
+	return suspended
+}
@@ -0,0 +1,27 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSuspendedFor(t *testing.T) {
+	interval := 5 * time.Second
+
+	// Monotonic clock stopped during a 10 minutes suspend
+	if d := suspendedFor(10*time.Minute+interval, interval, interval); d != 10*time.Minute {
+		t.Errorf("expected 10m, got %v", d)
+	}
+	// Monotonic clock kept going, the tick arrived late
+	if d := suspendedFor(2*time.Minute, 2*time.Minute, interval); d != 2*time.Minute-interval {
+		t.Errorf("expected %v, got %v", 2*time.Minute-interval, d)
+	}
+	// Regular tick
+	if d := suspendedFor(interval, interval, interval); d != 0 {
+		t.Errorf("expected no suspend, got %v", d)
+	}
+	// Wall clock adjusted backwards (NTP)
+	if d := suspendedFor(-time.Hour, interval, interval); d != 0 {
+		t.Errorf("expected no suspend, got %v", d)
+	}
+}
@@ -2,24 +2,49 @@ package core
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/collection"
 	"github.com/fischerling/librespot-golang/librespot/connection"
 	"github.com/fischerling/librespot-golang/librespot/crypto"
+	"github.com/fischerling/librespot-golang/librespot/dealer"
 	"github.com/fischerling/librespot-golang/librespot/discovery"
+	"github.com/fischerling/librespot-golang/librespot/history"
 	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/ops"
 	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/playlist"
+	"github.com/fischerling/librespot-golang/librespot/tokens"
 	"github.com/fischerling/librespot-golang/librespot/utils"
 )
 
-// Session represents an active Spotify connection
+// dealerScopes are the scopes of the token used to authenticate to the dealer
+var dealerScopes = []string{"playlist-read"}
+
+// Session represents an active Spotify connection.
+//
+// The methods of a Session are safe for concurrent use. The packets are read and dispatched by a single poll loop
+// goroutine, and the state it shares with the callers is protected by connLock, userLock, stateLock and
+// listenersLock. The login methods must not be called concurrently with each other.
 type Session struct {
+	// lastPacket is the time the last packet was received, in unix nanoseconds. It is first for 64-bit alignment.
+	lastPacket int64
+	// lastPing is the time the last ping was received from the access point, in unix nanoseconds
+	lastPing int64
+	// clockSkew is the time of the access point minus the local time, in nanoseconds, valid if clockSkewKnown is 1
+	clockSkew      int64
+	clockSkewKnown int32
+
 	/// Constructor references
 	// mercuryConstructor is the constructor that should be used to build a mercury connection
 	mercuryConstructor func(conn connection.PacketStream) *mercury.Client
@@ -27,6 +52,8 @@ type Session struct {
 	shannonConstructor func(keys crypto.SharedKeys, conn connection.PlainConnection) connection.PacketStream
 
 	/// Managers and helpers
+	// connLock protects tcpCon, stream, mercury, tokens, discovery and player, which are set when (re)connecting
+	connLock sync.RWMutex
 	// stream is the encrypted connection to the Spotify server
 	stream connection.PacketStream
 	// mercury is the mercury client associated with this session
@@ -35,42 +62,156 @@ type Session struct {
 	discovery *discovery.Discovery
 	// player is the player service used to load the audio data
 	player *player.Player
+	// tokens provides the access tokens used to call the Web API
+	tokens *tokens.Provider
+	// dealer is the WebSocket connection receiving push messages, nil until first used
+	dealer     *dealer.Dealer
+	dealerLock sync.Mutex
+	// collection is notified of the changes of the library by the dealer, nil until first used. It is protected by
+	// dealerLock.
+	collection *collection.Client
+	// ops tracks the long-running operations in flight
+	ops *ops.Registry
 	// tcpCon is the plain I/O network connection to the server
 	tcpCon io.ReadWriter
+	// dialer is used to establish the network connection to the server
+	dialer *connection.Dialer
+	// endpoints are the servers resolved for this session, and accessPoint the one it is connected to
+	endpoints     utils.Endpoints
+	accessPoint   string
+	endpointsLock sync.Mutex
 	// keys are the encryption keys used to communicate with the server
 	keys crypto.PrivateKeys
 
 	/// State and variables
+	// config holds the settings of the session, with the defaults filled in
+	config SessionConfig
 	// deviceId is the device identifier (computer name, Android serial number, ...) sent during auth to the Spotify
 	// servers for this session
 	deviceId string
 	// deviceName is the device name (Android device model) sent during auth to the Spotify servers for this session
 	deviceName string
+	// userLock protects username, reusableAuthBlob and country, which are set by the login and the poll loop
+	userLock sync.RWMutex
 	// username is the currently authenticated canonical username
 	username string
 	// reusableAuthBlob is the reusable authentication blob for Spotify Connect devices
 	reusableAuthBlob []byte
 	// country is the user country returned by the Spotify servers
 	country string
+	// closed is set to 1 once Close has been called, so that the poll loop stops instead of reconnecting
+	closed int32
+
+	/// Connection lifecycle
+	// stateLock protects the connection state and its callbacks
+	stateLock sync.Mutex
+	// state is the current connection state
+	state ConnectionState
+	// stateCallbacks are notified of every connection state change
+	stateCallbacks []StateCallback
+	// reconnectPolicy controls how reconnection attempts are made when the connection drops
+	reconnectPolicy ReconnectPolicy
+	// resumeDetection controls the detection of system suspends
+	resumeDetection ResumeDetection
+	// resumeCallbacks are notified when the system resumes from a suspend
+	resumeCallbacks []ResumeCallback
+	// resumeWatching is set once the suspend detection goroutine is running
+	resumeWatching bool
+	// pollGeneration is incremented to stop the poll loop without reconnecting, e.g. when switching users
+	pollGeneration int
+	// pollDone is closed when the running poll loop returns
+	pollDone chan struct{}
+	// supervisor restarts the stalled subsystems, nil unless Supervise was called
+	supervisor *Supervisor
+	// debugServer serves the debug handler, nil unless ServeDebug was called
+	debugServer *http.Server
+	// reporting reports the playbacks of the player, nil unless enabled by the configuration
+	reporting *history.Pipeline
+
+	/// Protocol events
+	// listenersLock protects listeners, licenseVersion and productInfo
+	listenersLock sync.Mutex
+	// listeners are notified of the protocol activity
+	listeners sessionListeners
+	// licenseVersion is the license version sent by the server
+	licenseVersion string
+	// productInfo holds the subscription attributes of the user sent by the server
+	productInfo ProductInfo
 }
 
+// Stream returns the encrypted connection to the Spotify server. The returned stream stays valid across reconnections,
+// it always uses the current connection, and fails with ErrNotConnected until the session first connected.
 func (s *Session) Stream() connection.PacketStream {
+	return sessionStream{s}
+}
+
+// currentStream returns the connection established last, nil if there is none yet
+func (s *Session) currentStream() connection.PacketStream {
+	s.connLock.RLock()
+	defer s.connLock.RUnlock()
 	return s.stream
 }
 
 func (s *Session) Discovery() *discovery.Discovery {
+	s.connLock.RLock()
+	defer s.connLock.RUnlock()
 	return s.discovery
 }
 
+// Mercury returns the mercury client of the session. It is kept across reconnections, nil until the session first
+// connected.
 func (s *Session) Mercury() *mercury.Client {
+	s.connLock.RLock()
+	defer s.connLock.RUnlock()
 	return s.mercury
 }
 
+// Subscribe subscribes to the mercury events published on uri, which are delivered on the C channel of the returned
+// subscription. Several subscriptions can be made to the same uri.
+func (s *Session) Subscribe(uri string) (*mercury.Subscription, error) {
+	return s.Mercury().NewSubscription(uri)
+}
+
+// Unsubscribe stops the delivery of the events to the subscription and closes its channel. The server-side
+// subscription is cancelled along with the last subscription to its uri.
+func (s *Session) Unsubscribe(subscription *mercury.Subscription) error {
+	return s.Mercury().Unsubscribe(subscription)
+}
+
+// Player returns the player of the session. It is kept across reconnections, nil until the session first connected.
 func (s *Session) Player() *player.Player {
+	s.connLock.RLock()
+	defer s.connLock.RUnlock()
 	return s.player
 }
 
+// ErrNotConnected is returned by the stream of a session which never connected
+var ErrNotConnected = errors.New("session not connected")
+
+// sessionStream forwards the packets to the current connection of the session
+type sessionStream struct {
+	session *Session
+}
+
+func (s sessionStream) SendPacket(cmd connection.PacketType, data []byte) error {
+	stream := s.session.currentStream()
+	if stream == nil {
+		return ErrNotConnected
+	}
+	return stream.SendPacket(cmd, data)
+}
+
+func (s sessionStream) RecvPacket() (connection.PacketType, []byte, error) {
+	stream := s.session.currentStream()
+	if stream == nil {
+		return 0, nil, ErrNotConnected
+	}
+	return stream.RecvPacket()
+}
+
 func (s *Session) Username() string {
+	s.userLock.RLock()
+	defer s.userLock.RUnlock()
 	return s.username
 }
 
@@ -78,40 +219,214 @@ func (s *Session) DeviceId() string {
 	return s.deviceId
 }
 
+// Channels returns the manager of the channels through which audio chunks and cover art can be requested, nil until
+// the session is authenticated
+func (s *Session) Channels() *player.ChannelManager {
+	p := s.Player()
+	if p == nil {
+		return nil
+	}
+	return p.Channels()
+}
+
+// Tokens returns the provider of the access tokens of the logged in user and of the client token, shared by the
+// dealer, the spclient and the Web API requests
+func (s *Session) Tokens() *tokens.Provider {
+	s.connLock.RLock()
+	defer s.connLock.RUnlock()
+	return s.tokens
+}
+
+// Operations returns the registry of the operations in flight (audio downloads, mercury requests, token
+// refreshes), through which they can be listed and cancelled
+func (s *Session) Operations() *ops.Registry {
+	return s.ops
+}
+
+// Dealer returns the dealer connection, through which modern clients send their push messages and Connect
+// commands. It is connected on first use.
+func (s *Session) Dealer() (*dealer.Dealer, error) {
+	s.dealerLock.Lock()
+	defer s.dealerLock.Unlock()
+
+	if s.dealer != nil {
+		return s.dealer, nil
+	}
+
+	host := dealer.DefaultHost
+	if dealers := s.Endpoints().Dealers; len(dealers) > 0 {
+		host = dealers[0]
+	}
+
+	d := dealer.New(host, func() (string, error) {
+		token, err := s.Tokens().Get(dealerScopes...)
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+	})
+	d.Dialer = s.dialer
+	d.SetLogger(s.Logger())
+	if err := d.Connect(); err != nil {
+		return nil, err
+	}
+
+	s.dealer = d
+	return d, nil
+}
+
+// Collection returns a client reading and modifying the library of the logged in user. The changes of the library
+// are notified through the dealer, which is connected on first use.
+func (s *Session) Collection() (*collection.Client, error) {
+	d, err := s.Dealer()
+	if err != nil {
+		return nil, err
+	}
+
+	s.dealerLock.Lock()
+	defer s.dealerLock.Unlock()
+	if s.collection == nil {
+		s.collection = collection.NewClient(s.SpClient(), s.Username())
+		s.collection.SetLogger(s.Logger())
+		d.Handle(collection.DealerPrefix, s.collection.HandleMessage)
+	}
+	return s.collection, nil
+}
+
+// History returns a client reading the recently played list of the logged in user, and reporting the plays to their
+// listening history
+func (s *Session) History() *history.Client {
+	return history.NewClient(s.Mercury(), s.Username())
+}
+
+// Reporting returns the pipeline reporting the playbacks of the player, to which more reporters can be added, or nil
+// if neither SessionConfig.ReportPlays nor SessionConfig.Reporters are set
+func (s *Session) Reporting() *history.Pipeline {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.reporting
+}
+
+// startReporting starts reporting the playbacks of the player once logged in, if enabled by the configuration and
+// not started yet
+func (s *Session) startReporting() {
+	if s.Reporting() != nil {
+		return
+	}
+	reporters := append([]history.Reporter{}, s.config.Reporters...)
+	if s.config.ReportPlays {
+		reporter := history.NewEventServiceReporter(s.mercury)
+		reporter.SetLogger(s.Logger())
+		reporters = append(reporters, reporter)
+	}
+	if len(reporters) == 0 {
+		return
+	}
+
+	reporting := history.NewPipeline(reporters...)
+	reporting.SetLogger(s.Logger())
+	s.player.OnEvent(reporting.Listener())
+	reporting.Start()
+
+	s.stateLock.Lock()
+	s.reporting = reporting
+	s.stateLock.Unlock()
+}
+
+// Playlists returns a client reading and modifying the playlists of the logged in user
+func (s *Session) Playlists() *playlist.Client {
+	return playlist.NewClient(s.Mercury(), s.Username())
+}
+
 func (s *Session) ReusableAuthBlob() []byte {
+	s.userLock.RLock()
+	defer s.userLock.RUnlock()
 	return s.reusableAuthBlob
 }
 
 func (s *Session) Country() string {
+	s.userLock.RLock()
+	defer s.userLock.RUnlock()
 	return s.country
 }
 
+// setUser records the user authenticated by the login
+func (s *Session) setUser(username string, reusableAuthBlob []byte) {
+	s.userLock.Lock()
+	s.username = username
+	s.reusableAuthBlob = reusableAuthBlob
+	s.userLock.Unlock()
+}
+
+// setCountry records the country of the user sent by the server
+func (s *Session) setCountry(country string) {
+	s.userLock.Lock()
+	s.country = country
+	s.userLock.Unlock()
+}
+
+// Search searches the catalog for tracks, albums, artists, playlists, etc., returning at most limit results of every
+// category
+func (s *Session) Search(query string, limit int) (*metadata.SearchResponse, error) {
+	return s.SearchPage(query, limit, 0)
+}
+
+// Matcher returns a matcher finding the tracks of the catalog by ISRC, or by artist, title and duration
+func (s *Session) Matcher() *metadata.Matcher {
+	return metadata.NewMatcher(s.Search, metadata.NewCatalog(s.Mercury(), s.Country()))
+}
+
+// SearchPage returns the page of search results starting at offset. SearchResult.HasMore tells whether there is a
+// next page.
+func (s *Session) SearchPage(query string, limit int, offset int) (*metadata.SearchResponse, error) {
+	res, err := s.Mercury().SearchWithOffset(query, limit, offset, s.Country(), s.Username())
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+	return res, nil
+}
+
 func (s *Session) startConnection() error {
 	// First, start by performing a plaintext connection and send the Hello message
-	conn := connection.MakePlainConnection(s.tcpCon, s.tcpCon)
+	s.connLock.RLock()
+	tcpCon := s.tcpCon
+	s.connLock.RUnlock()
+	if tcpCon == nil {
+		return ErrNotConnected
+	}
+	conn := connection.MakePlainConnection(tcpCon, tcpCon)
 
-	helloMessage := makeHelloMessage(s.keys.PubKey(), s.keys.ClientNonce())
-	initClientPacket, err := conn.SendPrefixPacket([]byte{0, 4}, helloMessage)
+	helloMessage, err := s.makeHelloMessage(s.keys.PubKey(), s.keys.ClientNonce())
 	if err != nil {
-		log.Fatal("Error writing client hello", err)
 		return err
 	}
 
+	initClientPacket, err := conn.SendPrefixPacket([]byte{0, 4}, helloMessage)
+	if err != nil {
+		return fmt.Errorf("error writing client hello: %v", err)
+	}
+
 	// Wait and read the hello reply
 	initServerPacket, err := conn.RecvPacket()
 	if err != nil {
-		log.Fatal("Error receving packet for hello: ", err)
-		return err
+		return fmt.Errorf("error receiving packet for hello: %v", err)
 	}
 
 	response := Spotify.APResponseMessage{}
 	err = proto.Unmarshal(initServerPacket[4:], &response)
 	if err != nil {
-		log.Fatal("Failed to unmarshal server hello", err)
+		return fmt.Errorf("failed to unmarshal server hello: %v", err)
+	}
+	if response.GetChallenge().GetLoginCryptoChallenge().GetDiffieHellman() == nil {
+		return fmt.Errorf("server hello has no Diffie-Hellman challenge")
+	}
+
+	challenge := response.Challenge.LoginCryptoChallenge.DiffieHellman
+	if err := crypto.VerifyServerSignature(s.config.ApServerKey, challenge.Gs, challenge.GsSignature); err != nil {
 		return err
 	}
 
-	remoteKey := response.Challenge.LoginCryptoChallenge.DiffieHellman.Gs
+	remoteKey := challenge.Gs
 	sharedKeys := s.keys.AddRemoteKey(remoteKey, initClientPacket, initServerPacket)
 
 	plainResponse := &Spotify.ClientResponsePlaintext{
@@ -126,155 +441,351 @@ func (s *Session) startConnection() error {
 
 	plainResponseMessage, err := proto.Marshal(plainResponse)
 	if err != nil {
-		log.Fatal("marshaling error: ", err)
-		return err
+		return fmt.Errorf("failed to marshal client plain response: %v", err)
 	}
 
 	_, err = conn.SendPrefixPacket([]byte{}, plainResponseMessage)
 	if err != nil {
-		log.Fatal("error writing client plain response ", err)
-		return err
+		return fmt.Errorf("error writing client plain response: %v", err)
 	}
 
-	s.stream = s.shannonConstructor(sharedKeys, conn)
-	s.mercury = s.mercuryConstructor(s.stream)
+	stream := s.shannonConstructor(sharedKeys, conn)
+	if s.config.WireLog != nil {
+		stream = connection.NewLoggingStream(stream, s.config.WireLog)
+	}
+	if s.config.Recorder != nil {
+		stream = s.config.Recorder.Stream(stream)
+	}
+	return s.setStream(stream)
+}
 
-	s.player = player.CreatePlayer(s.stream, s.mercury)
+// setStream makes the stream the connection of the session, and creates the clients using it on the first connection
+func (s *Session) setStream(stream connection.PacketStream) error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	s.stream = stream
+
+	// When reconnecting, keep the existing mercury client and player so that subscriptions and references held by
+	// the application stay valid
+	if s.mercury == nil {
+		s.mercury = s.mercuryConstructor(s.stream)
+		s.mercury.SetRegistry(s.ops)
+		s.mercury.SetWireLog(s.config.WireLog)
+		s.mercury.OnEvent(s.emitMercuryEvent)
+		if s.config.Cache != nil {
+			s.mercury.SetCache(s.config.Cache)
+		}
+		if s.config.MercuryTimeout != 0 {
+			s.mercury.SetRequestTimeout(s.config.MercuryTimeout)
+		}
+		if s.config.MaxMercuryRequests != 0 {
+			s.mercury.SetMaxInFlight(s.config.MaxMercuryRequests)
+		}
+		if s.config.MercuryRateLimit > 0 {
+			s.mercury.SetRateLimit(s.config.MercuryRateLimit, s.config.MercuryBurst)
+		}
+	} else {
+		// The requests sent on the previous connection won't be answered
+		s.mercury.CancelPending()
+		s.mercury.SetStream(s.stream)
+	}
+
+	if s.tokens == nil {
+		s.tokens = tokens.NewProvider(s.mercury, "")
+		s.tokens.SetClock(s.Now)
+		s.tokens.SetRegistry(s.ops)
+		s.tokens.SetClientTokenFetcher(s.fetchClientToken)
+	}
+
+	if s.player == nil {
+		s.player = player.CreatePlayer(s.stream, s.mercury)
+		s.player.SetLogger(s.Logger())
+		s.player.SetRegistry(s.ops)
+		s.player.SetChunkCache(s.config.ChunkCache)
+		if s.config.Recorder != nil {
+			s.player.OnEvent(s.config.Recorder.Listener())
+		}
+		s.player.SetOfflineStore(s.config.OfflineStore)
+		s.player.SetNormalization(s.config.Normalization)
+		s.player.SetQuality(s.config.Quality)
+		s.player.SetDecryptionBackend(s.config.DecryptionBackend)
+		s.player.SetHTTPClient(s.HTTPClient())
+		s.player.SetStorageResolver(s.resolveStorage)
+		s.player.SetAudioSource(s.config.AudioSource)
+		if s.config.ChunkSize != 0 {
+			if err := s.player.SetChunkSize(s.config.ChunkSize); err != nil {
+				return err
+			}
+		}
+	} else {
+		s.player.SetStream(s.stream)
+	}
 
 	return nil
 }
 
-func setupSession() (*Session, error) {
+// NewSession creates a session with the specified configuration, and connects it to an access point. The session
+// must then be authenticated with one of its Login methods.
+func NewSession(config SessionConfig) (*Session, error) {
+	var identityErr error
+	if config.DeviceId == "" && config.CredentialStore != nil {
+		// Keep the identity of the device across restarts, even if it is renamed
+		var identity DeviceIdentity
+		identity, identityErr = LoadDeviceIdentity(config.CredentialStore, config.DeviceName)
+		config.DeviceId = identity.DeviceId
+	}
+	config = config.withDefaults()
 	session := &Session{
+		config:             config,
+		deviceId:           config.DeviceId,
+		deviceName:         config.DeviceName,
 		keys:               crypto.GenerateKeys(),
 		mercuryConstructor: mercury.CreateMercury,
 		shannonConstructor: crypto.CreateStream,
+		dialer:             config.Dialer,
+		ops:                ops.NewRegistry(),
+		state:              StateDisconnected,
+		reconnectPolicy:    *config.ReconnectPolicy,
+		resumeDetection:    DefaultResumeDetection,
+	}
+	if identityErr != nil {
+		session.Logger().Println("Failed to load the device identity, using the one of the device name:", identityErr)
 	}
 	err := session.doConnect()
+	if err != nil {
+		return nil, err
+	}
 
-	return session, err
+	return session, nil
 }
 
-func sessionFromDiscovery(d *discovery.Discovery) (*Session, error) {
-	s, err := setupSession()
+func sessionFromDiscovery(d *discovery.Discovery, store CredentialStore) (*Session, error) {
+	s, err := NewSession(SessionConfig{
+		DeviceName:      d.DeviceName(),
+		DeviceId:        d.DeviceId(),
+		CredentialStore: store,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.connLock.Lock()
 	s.discovery = d
-	s.deviceId = d.DeviceId()
-	s.deviceName = d.DeviceName()
+	s.connLock.Unlock()
 
 	err = s.startConnection()
 	if err != nil {
-		return s, err
+		s.disconnect()
+		return nil, err
 	}
 
-	loginPacket := s.getLoginBlobPacket(d.LoginBlob())
-	return s, s.doLogin(loginPacket, d.LoginBlob().Username)
-}
-
-func (s *Session) doConnect() error {
-	apUrl, err := utils.APResolve()
+	loginPacket, err := s.getLoginBlobPacket(d.LoginBlob())
 	if err != nil {
-		log.Println("Failed to get ap url", err)
-		return err
+		s.disconnect()
+		return nil, err
 	}
 
-	s.tcpCon, err = net.Dial("tcp", apUrl)
+	err = s.doLogin(loginPacket, d.LoginBlob().Username)
 	if err != nil {
-		log.Println("Failed to connect:", err)
-		return err
+		s.disconnect()
+		return nil, err
 	}
 
-	return err
+	go s.reportActiveUser()
+
+	return s, nil
 }
 
-func (s *Session) disconnect() {
-	if s.tcpCon != nil {
-		conn := s.tcpCon.(net.Conn)
-		err := conn.Close()
-		if err != nil {
-			log.Println("Failed to close tcp connection", err)
-		}
-		s.tcpCon = nil
+// reportActiveUser fetches the profile of the logged in user, so that the discovery service passes its display name
+// and avatar to its callbacks, and the Spirc controllers publish its display name
+func (s *Session) reportActiveUser() {
+	user := discovery.ActiveUser{Username: s.Username()}
+
+	profile, err := s.Mercury().GetUserProfile(user.Username)
+	if err != nil {
+		s.Logger().Println("Failed to get user profile:", err)
+	} else {
+		user.DisplayName = profile.Name
+		user.ImageUrl = profile.ImageUrl
 	}
+
+	s.Discovery().SetActiveUser(user)
 }
 
-func (s *Session) doReconnect() error {
-	s.disconnect()
+func (s *Session) doConnect() error {
+	endpoints := s.resolveEndpoints()
+	return s.connectAccessPoint(endpoints.AccessPoints)
+}
 
-	err := s.doConnect()
+func (s *Session) disconnect() error {
+	s.connLock.Lock()
+	tcpCon := s.tcpCon
+	s.tcpCon = nil
+	s.connLock.Unlock()
+
+	var err error
+	if conn, ok := tcpCon.(io.Closer); ok {
+		err = conn.Close()
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to close tcp connection: %v", err)
 	}
+	return nil
+}
 
-	err = s.startConnection()
-	if err != nil {
-		return err
+// ErrSessionClosed is returned by the operations attempted on a closed session
+var ErrSessionClosed = errors.New("session is closed")
+
+// Close tears down the session: the mercury subscriptions are cancelled, the connection to the Spotify servers is
+// closed and the poll loop stopped, the pending mercury requests fail with mercury.ErrRequestCancelled, the debug
+// server is closed, the playback reporting is stopped, and the discovery service, if any, is deregistered from mDNS
+// and shut down. Close waits for the poll loop and the requests in progress on the discovery server until the
+// context is done. The session cannot be used anymore afterwards, and calling Close again does nothing.
+func (s *Session) Close(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		return nil
 	}
 
-	packet := makeLoginBlobPacket(s.username, s.reusableAuthBlob,
-		Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum(), s.deviceId)
-	return s.doLogin(packet, s.username)
-}
+	s.stateLock.Lock()
+	if s.supervisor != nil {
+		s.supervisor.Stop()
+	}
+	if s.debugServer != nil {
+		s.debugServer.Close()
+	}
+	if s.reporting != nil {
+		s.reporting.Stop()
+	}
+	pollDone := s.pollDone
+	s.stateLock.Unlock()
 
-func (s *Session) planReconnect() {
-	go func() {
-		time.Sleep(1 * time.Second)
+	m := s.Mercury()
+	if m != nil {
+		m.UnsubscribeAll()
+	}
+	err := s.disconnect()
+
+	if pollDone != nil {
+		select {
+		case <-pollDone:
+		case <-ctx.Done():
+			if err == nil {
+				err = fmt.Errorf("poll loop still running: %v", ctx.Err())
+			}
+		}
+	}
+	if m != nil {
+		m.CancelPending()
+	}
 
-		if err := s.doReconnect(); err != nil {
-			// Try to reconnect again in a second
-			s.planReconnect()
+	if d := s.Discovery(); d != nil {
+		if dErr := d.Shutdown(ctx); dErr != nil && err == nil {
+			err = dErr
 		}
+	}
+
+	s.dealerLock.Lock()
+	if s.dealer != nil {
+		if dErr := s.dealer.Close(); dErr != nil && err == nil {
+			err = dErr
+		}
+	}
+	s.dealerLock.Unlock()
+
+	s.setState(StateDisconnected)
+
+	return err
+}
+
+// isClosed returns true once Close has been called
+func (s *Session) isClosed() bool {
+	return atomic.LoadInt32(&s.closed) != 0
+}
+
+// startPollLoop starts the goroutine reading the packets of the current connection
+func (s *Session) startPollLoop() {
+	done := make(chan struct{})
+	s.stateLock.Lock()
+	generation := s.pollGeneration
+	s.pollDone = done
+	s.stateLock.Unlock()
+
+	go func() {
+		defer close(done)
+		s.runPollLoop(generation)
 	}()
+	go s.watchKeepalive(generation, done)
 }
 
-func (s *Session) runPollLoop() {
+func (s *Session) runPollLoop(generation int) {
 	for {
-		cmd, data, err := s.stream.RecvPacket()
-		if err != nil {
-			log.Println("Error during RecvPacket: ", err)
+		cmd, data, err := s.currentStream().RecvPacket()
+		if s.isClosed() || !s.isPollGeneration(generation) {
+			return
+		}
+		atomic.StoreInt64(&s.lastPacket, time.Now().UnixNano())
 
-			if err == io.EOF {
-				// We've been disconnected, reconnect
-				s.planReconnect()
-				break
-			}
-		} else {
-			s.handle(cmd, data)
+		if err != nil {
+			// The connection dropped (EOF, reset, timeout, ...), try to establish a new one
+			s.Logger().Println("Error during RecvPacket: ", err)
+			s.planReconnect()
+			return
+		} else if err := s.handle(cmd, data); err != nil {
+			s.Logger().Println("Error handling packet:", err)
 		}
 	}
 }
 
-func (s *Session) handle(cmd uint8, data []byte) {
+// stopPollLoop makes the running poll loop return without reconnecting once the connection is closed
+func (s *Session) stopPollLoop() {
+	s.stateLock.Lock()
+	s.pollGeneration++
+	s.stateLock.Unlock()
+}
+
+func (s *Session) currentPollGeneration() int {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.pollGeneration
+}
+
+func (s *Session) isPollGeneration(generation int) bool {
+	return s.currentPollGeneration() == generation
+}
+
+func (s *Session) handle(cmd connection.PacketType, data []byte) error {
 	//fmt.Printf("handle, cmd=0x%x data=%x\n", cmd, data)
 
 	switch {
 	case cmd == connection.PacketPing:
 		// Ping
-		err := s.stream.SendPacket(connection.PacketPong, data)
+		atomic.StoreInt64(&s.lastPing, time.Now().UnixNano())
+		s.recordServerTime(data)
+		err := s.currentStream().SendPacket(connection.PacketPong, data)
 		if err != nil {
-			log.Fatal("Error handling PacketPing", err)
+			return fmt.Errorf("error handling ping: %v", err)
 		}
 
 	case cmd == connection.PacketPongAck:
-		// Pong reply, ignore
+		// Pong reply
+		s.emitPong()
 
 	case cmd == connection.PacketAesKey || cmd == connection.PacketAesKeyError ||
-		cmd == connection.PacketStreamChunkRes:
-		// Audio key and data responses
-		s.player.HandleCmd(cmd, data)
+		cmd == connection.PacketStreamChunkRes || cmd == connection.PacketChannelError:
+		// Audio key and channel responses
+		s.Player().HandleCmd(cmd, data)
 
 	case cmd == connection.PacketCountryCode:
 		// Handle country code
-		s.country = fmt.Sprintf("%s", data)
+		country := fmt.Sprintf("%s", data)
+		s.setCountry(country)
+		s.emitCountry(country)
 
-	case 0xb2 <= cmd && cmd <= 0xb6:
+	case cmd.IsMercury():
 		// Mercury responses
-		err := s.mercury.Handle(cmd, bytes.NewReader(data))
+		err := s.Mercury().Handle(cmd, bytes.NewReader(data))
 		if err != nil {
-			log.Fatal("Handle 0xbx", err)
+			return fmt.Errorf("error handling mercury packet %v: %v", cmd, err)
 		}
 
 	case cmd == connection.PacketSecretBlock:
@@ -285,25 +796,34 @@ func (s *Session) handle(cmd uint8, data []byte) {
 
 	case cmd == connection.PacketProductInfo:
 		// Has some info about A/B testing status, product setup, etc... in an XML fashion.
+		info, err := parseProductInfo(data)
+		if err != nil {
+			s.Logger().Println(err)
+			break
+		}
+		s.setProductInfo(info)
 
-	case cmd == 0x1f:
+	case cmd == connection.PacketUnknownDataAllZeros:
 		// Unknown, data is zeroes only
 
 	case cmd == connection.PacketLicenseVersion:
 		// This is a simple blob containing the current Spotify license version (e.g. 1.0.1-FR). Format of the blob
 		// is [ uint16 id (= 0x001), uint8 len, string license ]
+		s.emitLicenseVersion(parseLicenseVersion(data))
 
 	default:
-		fmt.Printf("Unhandled cmd 0x%x\n", cmd)
+		s.emitUnknownPacket(cmd, data)
 	}
+
+	return nil
 }
 
-func (s *Session) poll() {
-	cmd, data, err := s.stream.RecvPacket()
+func (s *Session) poll() error {
+	cmd, data, err := s.currentStream().RecvPacket()
 	if err != nil {
-		log.Fatal("poll error", err)
+		return fmt.Errorf("poll error: %v", err)
 	}
-	s.handle(cmd, data)
+	return s.handle(cmd, data)
 }
 
 func readInt(b *bytes.Buffer) uint32 {
@@ -326,12 +846,51 @@ func readBytes(b *bytes.Buffer) []byte {
 	return data
 }
 
-func makeHelloMessage(publicKey []byte, nonce []byte) []byte {
+// helloPlatforms maps the operating systems and CPU families of the configuration to the platforms of the
+// ClientHello
+var helloPlatforms = map[Spotify.Os]map[Spotify.CpuFamily]Spotify.Platform{
+	Spotify.Os_OS_LINUX: {
+		Spotify.CpuFamily_CPU_X86:      Spotify.Platform_PLATFORM_LINUX_X86,
+		Spotify.CpuFamily_CPU_X86_64:   Spotify.Platform_PLATFORM_LINUX_X86_64,
+		Spotify.CpuFamily_CPU_ARM:      Spotify.Platform_PLATFORM_LINUX_ARM,
+		Spotify.CpuFamily_CPU_MIPS:     Spotify.Platform_PLATFORM_LINUX_MIPS,
+		Spotify.CpuFamily_CPU_SH:       Spotify.Platform_PLATFORM_LINUX_SH,
+		Spotify.CpuFamily_CPU_BLACKFIN: Spotify.Platform_PLATFORM_LINUX_BLACKFIN,
+	},
+	Spotify.Os_OS_OSX: {
+		Spotify.CpuFamily_CPU_X86:    Spotify.Platform_PLATFORM_OSX_X86,
+		Spotify.CpuFamily_CPU_X86_64: Spotify.Platform_PLATFORM_OSX_X86_64,
+		Spotify.CpuFamily_CPU_PPC:    Spotify.Platform_PLATFORM_OSX_PPC,
+	},
+	Spotify.Os_OS_WINDOWS: {
+		Spotify.CpuFamily_CPU_X86:    Spotify.Platform_PLATFORM_WIN32_X86,
+		Spotify.CpuFamily_CPU_X86_64: Spotify.Platform_PLATFORM_WIN32_X86,
+	},
+	Spotify.Os_OS_FREEBSD: {
+		Spotify.CpuFamily_CPU_X86:    Spotify.Platform_PLATFORM_FREEBSD_X86,
+		Spotify.CpuFamily_CPU_X86_64: Spotify.Platform_PLATFORM_FREEBSD_X86_64,
+	},
+	Spotify.Os_OS_ANDROID: {Spotify.CpuFamily_CPU_ARM: Spotify.Platform_PLATFORM_ANDROID_ARM},
+	Spotify.Os_OS_IPHONE:  {Spotify.CpuFamily_CPU_ARM: Spotify.Platform_PLATFORM_IPHONE_ARM},
+}
+
+// helloPlatform returns the platform of the ClientHello matching the operating system and CPU family, and
+// PLATFORM_LINUX_X86 for the unknown ones
+func helloPlatform(os Spotify.Os, cpu Spotify.CpuFamily) Spotify.Platform {
+	if platform, ok := helloPlatforms[os][cpu]; ok {
+		return platform
+	}
+	return Spotify.Platform_PLATFORM_LINUX_X86
+}
+
+// makeHelloMessage returns the ClientHello starting the handshake, describing the platform and the version of the
+// configuration
+func (s *Session) makeHelloMessage(publicKey []byte, nonce []byte) ([]byte, error) {
 	hello := &Spotify.ClientHello{
 		BuildInfo: &Spotify.BuildInfo{
 			Product:  Spotify.Product_PRODUCT_PARTNER.Enum(),
-			Platform: Spotify.Platform_PLATFORM_LINUX_X86.Enum(),
-			Version:  proto.Uint64(0x10800000000),
+			Platform: helloPlatform(s.config.Os, s.config.CpuFamily).Enum(),
+			Version:  proto.Uint64(s.config.BuildVersion),
 		},
 		CryptosuitesSupported: []Spotify.Cryptosuite{
 			Spotify.Cryptosuite_CRYPTO_SUITE_SHANNON},
@@ -350,8 +909,8 @@ func makeHelloMessage(publicKey []byte, nonce []byte) []byte {
 
 	packetData, err := proto.Marshal(hello)
 	if err != nil {
-		log.Fatal("login marshaling error: ", err)
+		return nil, fmt.Errorf("failed to marshal client hello: %v", err)
 	}
 
-	return packetData
+	return packetData, nil
 }
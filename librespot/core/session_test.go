@@ -2,20 +2,23 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/aptest"
 	"github.com/fischerling/librespot-golang/librespot/connection"
 	"github.com/fischerling/librespot-golang/librespot/crypto"
+	"github.com/fischerling/librespot-golang/librespot/history"
 	"github.com/fischerling/librespot-golang/librespot/mercury"
-	"github.com/fischerling/librespot-golang/librespot/spirc"
 	"io"
 	"math/big"
 	"testing"
+	"time"
 )
 
 type shanPacket struct {
-	cmd uint8
+	cmd connection.PacketType
 	buf []byte
 }
 
@@ -24,12 +27,12 @@ type fakeStream struct {
 	sendPackets chan shanPacket
 }
 
-func (f *fakeStream) SendPacket(cmd uint8, data []byte) (err error) {
+func (f *fakeStream) SendPacket(cmd connection.PacketType, data []byte) (err error) {
 	f.sendPackets <- shanPacket{cmd: cmd, buf: data}
 	return nil
 }
 
-func (f *fakeStream) RecvPacket() (cmd uint8, buf []byte, err error) {
+func (f *fakeStream) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
 	p := <-f.recvPackets
 	return p.cmd, p.buf, nil
 }
@@ -47,21 +50,6 @@ func readPlainPart(reader io.Reader, prefixSize uint32) ([]byte, error) {
 	return buf, err
 }
 
-func checkHead(t *testing.T, buf io.Reader) {
-	handleHead(buf)
-	headerData, _ := parsePart(buf)
-	header := &Spotify.Header{}
-	proto.Unmarshal(headerData, header)
-
-	if *header.Uri != "hm://remote/user/fakeUser/" {
-		t.Errorf("Wrong username  Got %q, ", header.Uri)
-	}
-
-	if *header.Method != "SEND" {
-		t.Errorf("Wrong method")
-	}
-}
-
 type fakeCon struct {
 	reader *bytes.Buffer
 	writer *bytes.Buffer
@@ -86,22 +74,21 @@ func TestLogin(t *testing.T) {
 		sendPackets: make(chan shanPacket),
 	}
 
+	store := NewMemoryCredentialStore()
 	s := &Session{
+		config:             SessionConfig{CredentialStore: store, ApServerKey: &testServerKey.PublicKey},
 		deviceId:           "testDevice",
 		keys:               crypto.GenerateKeysFromPrivate(big.NewInt(20.0), make([]byte, 10)),
 		tcpCon:             conn,
 		shannonConstructor: func(keys crypto.SharedKeys, conn connection.PlainConnection) connection.PacketStream { return fakeShan },
 		mercuryConstructor: mercury.CreateMercury,
+		dialer:             connection.NewDialer(),
 	}
 
 	serverResponse := &Spotify.APResponseMessage{
 		Challenge: &Spotify.APChallenge{
 			LoginCryptoChallenge: &Spotify.LoginCryptoChallengeUnion{
-				DiffieHellman: &Spotify.LoginCryptoDiffieHellmanChallenge{
-					Gs:                 []byte{25},
-					ServerSignatureKey: proto.Int32(5),
-					GsSignature:        []byte{5},
-				},
+				DiffieHellman: signedChallenge([]byte{25}),
 			},
 			FingerprintChallenge: &Spotify.FingerprintChallengeUnion{},
 			PowChallenge:         &Spotify.PoWChallengeUnion{},
@@ -117,7 +104,7 @@ func TestLogin(t *testing.T) {
 
 	result := make(chan []byte, 2)
 	go func() {
-		err := s.loginSession("testUser", "123", "myDevice")
+		err := s.Login("testUser", "123")
 		if err != nil {
 			t.Errorf("bad return values")
 		}
@@ -137,14 +124,20 @@ func TestLogin(t *testing.T) {
 	}
 
 	plainClientRes := &Spotify.ClientResponsePlaintext{}
-	// Discard original hello message
-	readPlainPart(conn.writer, 2)
+	// Keep the original hello message, needed to compute the expected challenge
+	helloData, _ := readPlainPart(bytes.NewReader(conn.writer.Bytes()), 2)
+	helloPacket := conn.writer.Next(2 + 4 + len(helloData))
 	// Get plain client response from plain connection
 	plainData, _ := readPlainPart(conn.writer, 0)
 	proto.Unmarshal(plainData, plainClientRes)
-	hmac := []byte{226, 239, 29, 188, 200, 160, 193, 245, 71, 39, 15, 82, 156, 34, 168, 224, 134, 149, 128, 222}
+
+	serverPacket := make([]byte, 4, len(serverResponseData)+4)
+	binary.BigEndian.PutUint32(serverPacket, uint32(len(serverResponseData)+4))
+	serverPacket = append(serverPacket, serverResponseData...)
+	sharedKeys := s.keys.AddRemoteKey([]byte{25}, helloPacket, serverPacket)
+	hmac := sharedKeys.Challenge()
 	if !bytes.Equal(plainClientRes.LoginCryptoResponse.DiffieHellman.Hmac, hmac) {
-		t.Errorf("failed hmac comparison", plainClientRes.LoginCryptoResponse.DiffieHellman.Hmac)
+		t.Errorf("failed hmac comparison: %v", plainClientRes.LoginCryptoResponse.DiffieHellman.Hmac)
 	}
 
 	welcome := &Spotify.APWelcome{
@@ -159,50 +152,237 @@ func TestLogin(t *testing.T) {
 	fakeShan.recvPackets <- shanPacket{cmd: 0xac, buf: welcomeData}
 	// country code
 	fakeShan.recvPackets <- shanPacket{cmd: 0x1b, buf: []byte{0, 1}}
-	// ignore subscribe
-	<-fakeShan.sendPackets
 	welcomeRes := <-result
 	if !bytes.Equal(welcomeRes, []byte{0, 1, 2}) {
 		t.Errorf("Wrong authdata returned.  Got %v", welcomeRes)
 	}
-}
-
-func TestHello(t *testing.T) {
-	stream := fakeStream{
-		recvPackets: make(chan shanPacket),
-		sendPackets: make(chan shanPacket, 2),
+	stored, err := store.Get("testUserCanonical")
+	if err != nil || !bytes.Equal(stored.AuthData, []byte{0, 1, 2}) ||
+		stored.AuthType != Spotify.AuthenticationType_AUTHENTICATION_USER_PASS {
+		t.Errorf("Wrong credentials stored: %v %v", stored, err)
 	}
+}
 
+func TestLoginPacketConfig(t *testing.T) {
 	s := &Session{
-		stream:   &stream,
 		deviceId: "testDevice",
+		config: SessionConfig{
+			Os:                Spotify.Os_OS_LINUX,
+			SystemInformation: "test system",
+			VersionString:     "test_1.0",
+		},
+	}
+
+	data, err := s.makeLoginPasswordPacket("testUser", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet := &Spotify.ClientResponseEncrypted{}
+	proto.Unmarshal(data, packet)
+
+	info := packet.GetSystemInfo()
+	if info.GetOs() != Spotify.Os_OS_LINUX || info.GetCpuFamily() != Spotify.CpuFamily_CPU_UNKNOWN ||
+		info.GetSystemInformationString() != "test system" || info.GetDeviceId() != "testDevice" {
+		t.Errorf("Bad system info: %v", info)
 	}
-	s.mercury = mercury.CreateMercury(&stream)
-	controller := spirc.CreateController(s, []byte{})
+	if packet.GetVersionString() != "test_1.0" {
+		t.Errorf("Bad version string: %v", packet.GetVersionString())
+	}
+}
 
-	go controller.SendHello()
+func TestHelloMessageConfig(t *testing.T) {
+	s := &Session{config: SessionConfig{Os: Spotify.Os_OS_OSX, CpuFamily: Spotify.CpuFamily_CPU_X86_64}.withDefaults()}
+	data, err := s.makeHelloMessage([]byte{1}, []byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hello := &Spotify.ClientHello{}
+	proto.Unmarshal(data, hello)
+	info := hello.GetBuildInfo()
+	if info.GetPlatform() != Spotify.Platform_PLATFORM_OSX_X86_64 || info.GetVersion() != DefaultBuildVersion {
+		t.Errorf("Bad build info: %v", info)
+	}
 
-	//ignore subscribe packet
-	<-stream.sendPackets
+	// The unknown platforms are announced as Linux
+	s.config.Os, s.config.BuildVersion = Spotify.Os_OS_UNKNOWN, 42
+	data, _ = s.makeHelloMessage([]byte{1}, []byte{2})
+	proto.Unmarshal(data, hello)
+	if info := hello.GetBuildInfo(); info.GetPlatform() != Spotify.Platform_PLATFORM_LINUX_X86 || info.GetVersion() != 42 {
+		t.Errorf("Bad build info: %v", info)
+	}
+}
 
-	packet := <-stream.sendPackets
+func TestAccessorsDuringReconnect(t *testing.T) {
+	s := &Session{mercuryConstructor: mercury.CreateMercury, dialer: connection.NewDialer()}
 
-	if packet.cmd != 0xb2 {
-		t.Errorf("Wrong cmd code.  Got %q, want %q", packet.cmd, 0xb2)
+	// The stream returned before the first connection keeps working afterwards
+	stream := s.Stream()
+	if err := stream.SendPacket(connection.PacketPing, nil); err != ErrNotConnected {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
 	}
 
-	buf := bytes.NewBuffer(packet.buf)
-	checkHead(t, buf)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			s.Mercury()
+			s.Player()
+			s.Discovery()
+			s.Channels()
+		}
+	}()
 
-	frameData, _ := parsePart(buf)
-	frame := &Spotify.Frame{}
-	proto.Unmarshal(frameData, frame)
+	var last *fakeStream
+	for i := 0; i < 10; i++ {
+		last = &fakeStream{sendPackets: make(chan shanPacket, 1)}
+		if err := s.setStream(last); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
 
-	if frame.GetTyp() != Spotify.MessageType_kMessageTypeHello {
-		t.Errorf("Wrong message type")
+	if err := stream.SendPacket(connection.PacketPing, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p := <-last.sendPackets; p.cmd != connection.PacketPing {
+		t.Errorf("Packet sent on the wrong stream: %v", p.cmd)
 	}
+}
+
+type closingCon struct {
+	fakeCon
+	onClose func()
+}
 
-	if *frame.Ident != "testDevice" {
-		t.Errorf("Wrong ident. Got %q, want %q", *frame.Ident, "testDevice")
+func (c *closingCon) Close() error {
+	c.onClose()
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	s := &Session{mercuryConstructor: mercury.CreateMercury, dialer: connection.NewDialer()}
+	stream := &fakeStream{recvPackets: make(chan shanPacket), sendPackets: make(chan shanPacket, 10)}
+	if err := s.setStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	closes := 0
+	s.tcpCon = &closingCon{onClose: func() {
+		closes++
+		close(stream.recvPackets)
+	}}
+	s.startPollLoop()
+
+	responses := make(chan mercury.Response, 1)
+	err := s.Mercury().Request(mercury.Request{Method: "GET", Uri: "hm://test"}, func(res mercury.Response) {
+		responses <- res
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.pollDone:
+	default:
+		t.Error("Poll loop still running after Close")
+	}
+	if res := <-responses; res.StatusCode != mercury.StatusCodeCancelled {
+		t.Errorf("Pending request not cancelled: %v", res.StatusCode)
+	}
+	if s.State() != StateDisconnected {
+		t.Errorf("Bad state after Close: %v", s.State())
+	}
+
+	if err := s.Close(ctx); err != nil || closes != 1 {
+		t.Errorf("Second Close: %v, connection closed %d times", err, closes)
+	}
+	if err := s.SwitchUser(Credentials{}); err != ErrSessionClosed {
+		t.Errorf("Expected ErrSessionClosed, got %v", err)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	s := &Session{mercuryConstructor: mercury.CreateMercury, dialer: connection.NewDialer()}
+	stream := &fakeStream{recvPackets: make(chan shanPacket), sendPackets: make(chan shanPacket, 10)}
+	if err := s.setStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	s.tcpCon = &closingCon{onClose: func() {
+		close(stream.recvPackets)
+	}}
+	s.startPollLoop()
+
+	// The poll loop updates the country while the callers read the state of the session
+	countries := make(chan string, 100)
+	s.OnCountry(func(country string) {
+		countries <- country
+	})
+	go func() {
+		for i := 0; i < 100; i++ {
+			stream.recvPackets <- shanPacket{cmd: connection.PacketCountryCode, buf: []byte("FR")}
+		}
+	}()
+
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			for j := 0; j < 100; j++ {
+				s.setUser("user", []byte{1})
+				if s.Username() != "user" || s.Tokens() == nil || len(s.ReusableAuthBlob()) != 1 {
+					t.Error("Bad session state")
+				}
+				s.Country()
+			}
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	for i := 0; i < 100; i++ {
+		<-countries
+	}
+	if s.Country() != "FR" {
+		t.Errorf("Bad country %q", s.Country())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReportingStartsOnLogin(t *testing.T) {
+	server, err := aptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Users["user"] = "password"
+
+	s, err := NewSession(SessionConfig{
+		ApAddress:    aptest.Address,
+		Transport:    server.Transport(),
+		ApServerKey:  server.PublicKey(),
+		LoginLimiter: NewLoginLimiter(LoginLimitPolicy{}),
+		Reporters:    []history.Reporter{history.ReporterFuncs{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close(context.Background())
+	if s.Reporting() != nil {
+		t.Errorf("Reporting started before the login")
+	}
+	if err := s.Login("user", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Reporting() == nil {
+		t.Error("Reporting not started by the login")
 	}
 }
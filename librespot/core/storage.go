@@ -0,0 +1,74 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/spclient"
+)
+
+// SpClient returns a client of the spclient service, authenticated with the tokens of the session. It sends its
+// requests to the first spclient resolved through apresolve.
+func (s *Session) SpClient() *spclient.Client {
+	host := ""
+	if spclients := s.Endpoints().SpClients; len(spclients) > 0 {
+		host = spclients[0]
+	}
+	client := spclient.New(host, s.Tokens(), s.HTTPClient(), s.config.VersionString)
+	if s.config.Language != "" {
+		client.Headers.Set("Accept-Language", s.config.Language)
+	}
+	return client
+}
+
+// resolveStorage returns the CDN URLs of an audio file, requested from the spclient. It is the StorageResolver of
+// the player.
+func (s *Session) resolveStorage(fileId []byte) ([]string, error) {
+	return s.SpClient().StorageResolve(fileId)
+}
+
+// verifiedCaches holds the caches verified by the sessions of the process, which may share them, so that they are
+// only verified once
+var verifiedCaches = struct {
+	sync.Mutex
+	caches map[player.Verifier]bool
+}{caches: map[player.Verifier]bool{}}
+
+// verifyCaches verifies the entries of the chunk cache and the offline store, if they can be verified and weren't by
+// another session, evicting the corrupt ones
+func (s *Session) verifyCaches() {
+	if verifier, ok := s.config.ChunkCache.(player.Verifier); ok {
+		s.verifyCache("chunk cache", verifier)
+	}
+	if s.config.OfflineStore != nil {
+		s.verifyCache("offline store", s.config.OfflineStore)
+	}
+}
+
+// firstVerification returns true if the cache wasn't verified yet, and marks it as verified. The caches which can't
+// be told apart, not being comparable, are verified every time.
+func firstVerification(verifier player.Verifier) bool {
+	if !reflect.TypeOf(verifier).Comparable() {
+		return true
+	}
+	verifiedCaches.Lock()
+	defer verifiedCaches.Unlock()
+	if verifiedCaches.caches[verifier] {
+		return false
+	}
+	verifiedCaches.caches[verifier] = true
+	return true
+}
+
+func (s *Session) verifyCache(name string, verifier player.Verifier) {
+	if !firstVerification(verifier) {
+		return
+	}
+	report, err := verifier.Verify()
+	if err != nil {
+		s.Logger().Printf("Unable to verify the %s: %v\n", name, err)
+	} else if report.Evicted > 0 {
+		s.Logger().Printf("Evicted %d corrupt entries of the %s, out of %d\n", report.Evicted, name, report.Checked)
+	}
+}
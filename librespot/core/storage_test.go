@@ -0,0 +1,31 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// verifiedCache is a chunk cache counting its verifications
+type verifiedCache struct {
+	*player.MemoryChunkCache
+	verifications int
+}
+
+func (c *verifiedCache) Verify() (player.CacheReport, error) {
+	c.verifications++
+	return player.CacheReport{}, nil
+}
+
+func TestVerifyCachesOnce(t *testing.T) {
+	shared := &verifiedCache{MemoryChunkCache: player.NewMemoryChunkCache(1 << 20)}
+	other := &verifiedCache{MemoryChunkCache: player.NewMemoryChunkCache(1 << 20)}
+
+	for _, cache := range []*verifiedCache{shared, shared, other} {
+		s := &Session{config: SessionConfig{ChunkCache: cache}}
+		s.verifyCaches()
+	}
+	if shared.verifications != 1 || other.verifications != 1 {
+		t.Errorf("Verified the caches %d and %d times", shared.verifications, other.verifications)
+	}
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Names of the subsystems watched by the supervisor of a session
+const (
+	SubsystemSession   = "session"
+	SubsystemDealer    = "dealer"
+	SubsystemDiscovery = "discovery"
+	SubsystemPlayer    = "player"
+)
+
+// DefaultCheckInterval is how often the supervisor checks the heartbeats by default
+const DefaultCheckInterval = 10 * time.Second
+
+// DefaultStallTimeouts are the times without heartbeat after which the subsystems are restarted by default. The
+// access point pings the session every 2 minutes, and the dealer is pinged every 30 seconds.
+var DefaultStallTimeouts = map[string]time.Duration{
+	SubsystemSession:   5 * time.Minute,
+	SubsystemDealer:    3 * time.Minute,
+	SubsystemDiscovery: time.Minute,
+	SubsystemPlayer:    time.Minute,
+}
+
+// Heartbeat returns the last time a subsystem showed it was working, and whether it is active. Inactive subsystems
+// (disconnected session, player not downloading, ...) are not considered stalled.
+type Heartbeat func() (last time.Time, active bool)
+
+// SupervisorEvent is emitted when a stalled subsystem is restarted
+type SupervisorEvent struct {
+	Subsystem     string
+	LastHeartbeat time.Time
+	// Err is set if the restart failed
+	Err error
+}
+
+// SupervisorCallback is called with the events of a supervisor
+type SupervisorCallback func(event SupervisorEvent)
+
+// SupervisorConfig holds the settings of a supervisor
+type SupervisorConfig struct {
+	// CheckInterval is how often the heartbeats are checked, DefaultCheckInterval if zero
+	CheckInterval time.Duration
+	// StallTimeouts overrides DefaultStallTimeouts for some subsystems
+	StallTimeouts map[string]time.Duration
+}
+
+type supervised struct {
+	name      string
+	timeout   time.Duration
+	heartbeat Heartbeat
+	restart   func() error
+	// restarted is the time of the last restart, which counts as a heartbeat so that the subsystem is given time to
+	// recover
+	restarted time.Time
+	// last and active are the heartbeat observed by the last check, and restarts counts the restarts. They are
+	// protected by the lock of the supervisor, as restarted.
+	last     time.Time
+	active   bool
+	restarts int
+}
+
+// SubsystemHealth is the state of a supervised subsystem, as observed by the last check of its heartbeat
+type SubsystemHealth struct {
+	Name          string        `json:"name"`
+	Active        bool          `json:"active"`
+	LastHeartbeat time.Time     `json:"last_heartbeat"`
+	StallTimeout  time.Duration `json:"stall_timeout"`
+	Restarts      int           `json:"restarts"`
+	LastRestart   time.Time     `json:"last_restart"`
+}
+
+// Supervisor monitors the heartbeats of subsystems, and restarts those which stall. Appliances can't rely on a
+// human restarting the process when a goroutine gets stuck.
+type Supervisor struct {
+	config SupervisorConfig
+
+	lock       sync.Mutex
+	subsystems []*supervised
+	callbacks  []SupervisorCallback
+	stop       chan struct{}
+}
+
+// NewSupervisor creates a supervisor watching nothing yet
+func NewSupervisor(config SupervisorConfig) *Supervisor {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultCheckInterval
+	}
+	return &Supervisor{config: config}
+}
+
+// Watch starts monitoring a subsystem, which is restarted with restart once its heartbeat is older than the stall
+// timeout of the subsystem
+func (s *Supervisor) Watch(name string, heartbeat Heartbeat, restart func() error) {
+	timeout, ok := s.config.StallTimeouts[name]
+	if !ok {
+		timeout = DefaultStallTimeouts[name]
+	}
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+
+	s.lock.Lock()
+	s.subsystems = append(s.subsystems, &supervised{
+		name:      name,
+		timeout:   timeout,
+		heartbeat: heartbeat,
+		restart:   restart,
+	})
+	s.lock.Unlock()
+}
+
+// OnRestart registers a callback notified whenever a subsystem is restarted
+func (s *Supervisor) OnRestart(cb SupervisorCallback) {
+	s.lock.Lock()
+	s.callbacks = append(s.callbacks, cb)
+	s.lock.Unlock()
+}
+
+// Start checks the heartbeats periodically in the background, until Stop is called
+func (s *Supervisor) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(s.config.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				s.check(now)
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops checking the heartbeats
+func (s *Supervisor) Stop() {
+	s.lock.Lock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	s.lock.Unlock()
+}
+
+// check restarts the subsystems whose heartbeat is older than their stall timeout
+func (s *Supervisor) check(now time.Time) {
+	s.lock.Lock()
+	subsystems := append([]*supervised{}, s.subsystems...)
+	s.lock.Unlock()
+
+	for _, sub := range subsystems {
+		last, active := sub.heartbeat()
+		s.lock.Lock()
+		sub.last, sub.active = last, active
+		restarted := sub.restarted
+		s.lock.Unlock()
+		if !active {
+			continue
+		}
+		if restarted.After(last) {
+			last = restarted
+		}
+		if now.Sub(last) <= sub.timeout {
+			continue
+		}
+
+		event := SupervisorEvent{Subsystem: sub.name, LastHeartbeat: last, Err: sub.restart()}
+
+		s.lock.Lock()
+		sub.restarted = now
+		sub.restarts++
+		callbacks := append([]SupervisorCallback{}, s.callbacks...)
+		s.lock.Unlock()
+		for _, cb := range callbacks {
+			cb(event)
+		}
+	}
+}
+
+// Health returns the state of the watched subsystems, as observed by the last check of their heartbeats
+func (s *Supervisor) Health() []SubsystemHealth {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := make([]SubsystemHealth, len(s.subsystems))
+	for i, sub := range s.subsystems {
+		res[i] = SubsystemHealth{
+			Name:          sub.name,
+			Active:        sub.active,
+			LastHeartbeat: sub.last,
+			StallTimeout:  sub.timeout,
+			Restarts:      sub.restarts,
+			LastRestart:   sub.restarted,
+		}
+	}
+	return res
+}
+
+// Supervise starts a supervisor restarting the subsystems of the session which stall: the connection to the access
+// point, the dealer, the discovery server and the audio downloads of the player. It is stopped when the session is
+// closed.
+func (s *Session) Supervise(config SupervisorConfig) *Supervisor {
+	supervisor := NewSupervisor(config)
+
+	supervisor.Watch(SubsystemSession, func() (time.Time, bool) {
+		return time.Unix(0, atomic.LoadInt64(&s.lastPacket)), s.State() == StateConnected
+	}, func() error {
+		s.Logger().Println("Connection stalled, reconnecting")
+		s.stopPollLoop()
+		s.planReconnect()
+		return nil
+	})
+
+	supervisor.Watch(SubsystemDealer, func() (time.Time, bool) {
+		s.dealerLock.Lock()
+		d := s.dealer
+		s.dealerLock.Unlock()
+		if d == nil {
+			return time.Time{}, false
+		}
+		return d.LastActivity()
+	}, func() error {
+		s.dealerLock.Lock()
+		d := s.dealer
+		s.dealerLock.Unlock()
+		if d == nil {
+			return nil
+		}
+		return d.Reconnect()
+	})
+
+	if d := s.Discovery(); d != nil {
+		lastCheck := time.Now()
+		supervisor.Watch(SubsystemDiscovery, func() (time.Time, bool) {
+			if err := d.Check(); err != nil {
+				s.Logger().Println(err)
+			} else {
+				lastCheck = time.Now()
+			}
+			return lastCheck, true
+		}, d.Restart)
+	}
+
+	if p := s.Player(); p != nil {
+		supervisor.Watch(SubsystemPlayer, p.LastActivity, func() error {
+			p.ResetDownloads()
+			return nil
+		})
+	}
+
+	s.stateLock.Lock()
+	if s.supervisor != nil {
+		s.supervisor.Stop()
+	}
+	s.supervisor = supervisor
+	s.stateLock.Unlock()
+
+	supervisor.Start()
+	return supervisor
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSupervisorRestartsStalled(t *testing.T) {
+	now := time.Unix(1000, 0)
+	supervisor := NewSupervisor(SupervisorConfig{StallTimeouts: map[string]time.Duration{"a": time.Minute}})
+
+	var events []SupervisorEvent
+	supervisor.OnRestart(func(event SupervisorEvent) {
+		events = append(events, event)
+	})
+
+	lastA, restartsA := now, 0
+	supervisor.Watch("a", func() (time.Time, bool) { return lastA, true }, func() error {
+		restartsA++
+		return nil
+	})
+	restartsIdle := 0
+	supervisor.Watch(SubsystemPlayer, func() (time.Time, bool) { return time.Time{}, false }, func() error {
+		restartsIdle++
+		return nil
+	})
+	supervisor.Watch(SubsystemDealer, func() (time.Time, bool) { return now, true }, func() error {
+		return errors.New("failed")
+	})
+
+	supervisor.check(now.Add(30 * time.Second))
+	if restartsA != 0 || len(events) != 0 {
+		t.Fatalf("Nothing should be restarted yet, got %v", events)
+	}
+
+	supervisor.check(now.Add(2 * time.Minute))
+	if restartsA != 1 || len(events) != 1 || events[0].Subsystem != "a" || !events[0].LastHeartbeat.Equal(lastA) {
+		t.Fatalf("Expected a restart of a, got %v", events)
+	}
+
+	// The subsystem is given time to recover after a restart
+	supervisor.check(now.Add(150 * time.Second))
+	if restartsA != 1 {
+		t.Errorf("Restarted again right after a restart")
+	}
+	supervisor.check(now.Add(4 * time.Minute))
+	if restartsA != 2 {
+		t.Errorf("Expected another restart, got %d", restartsA)
+	}
+	if restartsIdle != 0 {
+		t.Errorf("Inactive subsystems should not be restarted")
+	}
+
+	supervisor.check(now.Add(time.Hour))
+	last := events[len(events)-1]
+	if last.Subsystem != SubsystemDealer || last.Err == nil {
+		t.Errorf("Expected a failed restart of the dealer, got %v", last)
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// Credentials identify the user to log in with
+type Credentials struct {
+	Username string
+	AuthType Spotify.AuthenticationType
+	AuthData []byte
+}
+
+// PasswordCredentials returns the credentials of a user logging in with a password
+func PasswordCredentials(username string, password string) Credentials {
+	return Credentials{
+		Username: username,
+		AuthType: Spotify.AuthenticationType_AUTHENTICATION_USER_PASS,
+		AuthData: []byte(password),
+	}
+}
+
+// StoredCredentials returns the credentials of a user logging in with a reusable auth blob, see ReusableAuthBlob
+func StoredCredentials(username string, authData []byte) Credentials {
+	return Credentials{
+		Username: username,
+		AuthType: Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS,
+		AuthData: authData,
+	}
+}
+
+// SwitchUser logs out the current user, and logs in the user with the specified credentials on a new connection.
+// The discovery service, the player and the callbacks registered on the session are kept, while the state of the
+// previous user (cached mercury responses and subscriptions, access tokens, dealer connection) is cleared. The Spirc
+// controllers of the previous user must be created again.
+func (s *Session) SwitchUser(credentials Credentials) error {
+	if s.isClosed() {
+		return ErrSessionClosed
+	}
+
+	s.stopPollLoop()
+	s.disconnect()
+	s.setState(StateReconnecting)
+	s.clearUser()
+
+	err := s.doConnect()
+	if err == nil {
+		err = s.loginBlob(credentials.Username, credentials.AuthData, credentials.AuthType.Enum())
+	}
+	if err != nil {
+		s.disconnect()
+		s.setState(StateDisconnected)
+		return fmt.Errorf("failed to switch to user %s: %v", credentials.Username, err)
+	}
+
+	if s.Discovery() != nil {
+		go s.reportActiveUser()
+	}
+	return nil
+}
+
+// clearUser forgets everything about the logged in user
+func (s *Session) clearUser() {
+	if m := s.Mercury(); m != nil {
+		m.ClearSubscriptions()
+		m.Cache().Clear()
+	}
+	if t := s.Tokens(); t != nil {
+		t.Clear()
+	}
+
+	s.dealerLock.Lock()
+	if s.dealer != nil {
+		s.dealer.Close()
+		s.dealer = nil
+	}
+	s.dealerLock.Unlock()
+
+	s.setUser("", nil)
+	s.setCountry("")
+}
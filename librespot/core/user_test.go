@@ -0,0 +1,49 @@
+package core
+
+import (
+	"net"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/tokens"
+)
+
+func TestSwitchUserClearsUser(t *testing.T) {
+	// Nobody listens on the access point, so that the login of the new user fails
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	client := mercury.CreateMercury(nil)
+	client.Cache().Put("hm://test", mercury.Response{StatusCode: 200})
+	s := &Session{
+		config:           SessionConfig{ApAddress: closed.Addr().String()},
+		dialer:           connection.NewDialer(),
+		mercury:          client,
+		tokens:           tokens.NewProvider(client, ""),
+		username:         "previous",
+		reusableAuthBlob: []byte{1, 2, 3},
+		state:            StateConnected,
+	}
+
+	var states []ConnectionState
+	s.OnStateChange(func(state ConnectionState) {
+		states = append(states, state)
+	})
+
+	if err := s.SwitchUser(PasswordCredentials("next", "password")); err == nil {
+		t.Fatal("SwitchUser should fail without access point")
+	}
+	if s.Username() != "" || s.ReusableAuthBlob() != nil {
+		t.Errorf("The previous user was not cleared")
+	}
+	if _, ok := client.Cache().Get("hm://test"); ok {
+		t.Errorf("The mercury cache was not cleared")
+	}
+	if len(states) != 2 || states[0] != StateReconnecting || states[1] != StateDisconnected {
+		t.Errorf("Bad state changes %v", states)
+	}
+}
@@ -0,0 +1,41 @@
+package core
+
+// ValidateCredentials checks the credentials with a login on a new connection to an access point, closed right
+// after, without starting a session: nothing is stored in the CredentialStore of the config, and neither the
+// playback reporting nor the verification of the caches is started. It returns an AuthError if the credentials are
+// rejected, and otherwise the reusable credentials of the user, with the canonical username, which can be saved to
+// log in later.
+func ValidateCredentials(config SessionConfig, credentials Credentials) (Credentials, error) {
+	config.CredentialStore = nil
+	s, err := NewSession(config)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return s.validateCredentials(credentials)
+}
+
+func (s *Session) validateCredentials(credentials Credentials) (Credentials, error) {
+	defer s.disconnect()
+
+	if err := s.startConnection(); err != nil {
+		return Credentials{}, err
+	}
+	packet, err := s.makeLoginBlobPacket(credentials.Username, credentials.AuthData, credentials.AuthType.Enum())
+	if err != nil {
+		return Credentials{}, err
+	}
+	welcome, err := s.authenticate(packet, credentials.Username)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	validated := Credentials{
+		Username: welcome.GetCanonicalUsername(),
+		AuthType: welcome.GetReusableAuthCredentialsType(),
+		AuthData: welcome.GetReusableAuthCredentials(),
+	}
+	if validated.Username == "" {
+		validated.Username = credentials.Username
+	}
+	return validated, nil
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/crypto"
+	"github.com/fischerling/librespot-golang/librespot/history"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/golang/protobuf/proto"
+)
+
+// testServerKey signs the Diffie-Hellman keys of the fake access points, see signedChallenge
+var testServerKey, _ = rsa.GenerateKey(rand.Reader, 1024)
+
+// signedChallenge returns the Diffie-Hellman challenge of a fake access point, its key gs being signed by
+// testServerKey
+func signedChallenge(gs []byte) *Spotify.LoginCryptoDiffieHellmanChallenge {
+	signature, _ := crypto.SignServerKey(testServerKey, gs)
+	return &Spotify.LoginCryptoDiffieHellmanChallenge{
+		Gs:                 gs,
+		ServerSignatureKey: proto.Int32(5),
+		GsSignature:        signature,
+	}
+}
+
+// newHandshakeSession returns a session whose access point answers the hello, and the fake stream through which
+// the login packets are exchanged
+func newHandshakeSession(config SessionConfig) (*Session, *fakeStream) {
+	conn := &fakeCon{
+		reader: bytes.NewBuffer(make([]byte, 0)),
+		writer: bytes.NewBuffer(make([]byte, 0)),
+	}
+	serverResponse := &Spotify.APResponseMessage{
+		Challenge: &Spotify.APChallenge{
+			LoginCryptoChallenge: &Spotify.LoginCryptoChallengeUnion{
+				DiffieHellman: signedChallenge([]byte{25}),
+			},
+			FingerprintChallenge: &Spotify.FingerprintChallengeUnion{},
+			PowChallenge:         &Spotify.PoWChallengeUnion{},
+			CryptoChallenge:      &Spotify.CryptoChallengeUnion{},
+			ServerNonce:          []byte{5},
+		},
+	}
+	serverResponseData, _ := proto.Marshal(serverResponse)
+	binary.Write(conn.reader, binary.BigEndian, uint32(len(serverResponseData)+4))
+	conn.reader.Write(serverResponseData)
+
+	fakeShan := &fakeStream{
+		recvPackets: make(chan shanPacket, 1),
+		sendPackets: make(chan shanPacket, 1),
+	}
+	if config.ApServerKey == nil {
+		config.ApServerKey = &testServerKey.PublicKey
+	}
+	s := &Session{
+		config:   config,
+		deviceId: "testDevice",
+		keys:     crypto.GenerateKeysFromPrivate(big.NewInt(20.0), make([]byte, 10)),
+		tcpCon:   conn,
+		shannonConstructor: func(keys crypto.SharedKeys, conn connection.PlainConnection) connection.PacketStream {
+			return fakeShan
+		},
+		mercuryConstructor: mercury.CreateMercury,
+		dialer:             connection.NewDialer(),
+	}
+	return s, fakeShan
+}
+
+func TestValidateCredentials(t *testing.T) {
+	store := NewMemoryCredentialStore()
+	cache := &verifiedCache{MemoryChunkCache: player.NewMemoryChunkCache(1 << 20)}
+	s, stream := newHandshakeSession(SessionConfig{
+		CredentialStore: store,
+		Reporters:       []history.Reporter{history.ReporterFuncs{}},
+		ChunkCache:      cache,
+	})
+
+	welcome, _ := proto.Marshal(&Spotify.APWelcome{
+		CanonicalUsername:           proto.String("canonical"),
+		AccountTypeLoggedIn:         Spotify.AccountType_Spotify.Enum(),
+		CredentialsTypeLoggedIn:     Spotify.AccountType_Spotify.Enum(),
+		ReusableAuthCredentialsType: Spotify.AuthenticationType_AUTHENTICATION_STORED_SPOTIFY_CREDENTIALS.Enum(),
+		ReusableAuthCredentials:     []byte{1, 2, 3},
+	})
+	stream.recvPackets <- shanPacket{cmd: connection.PacketAPWelcome, buf: welcome}
+
+	credentials, err := s.validateCredentials(PasswordCredentials("user", "password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials.Username != "canonical" || !bytes.Equal(credentials.AuthData, []byte{1, 2, 3}) {
+		t.Errorf("Bad credentials: %v", credentials)
+	}
+	if (<-stream.sendPackets).cmd != connection.PacketLogin {
+		t.Errorf("No login packet sent")
+	}
+	if s.tcpCon != nil {
+		t.Errorf("Session left connected")
+	}
+	if _, err := store.Get("canonical"); err != ErrNoCredentials {
+		t.Errorf("Credentials stored: %v", err)
+	}
+	if s.Reporting() != nil {
+		t.Errorf("Reporting started")
+	}
+	if cache.verifications != 0 {
+		t.Errorf("Chunk cache verified")
+	}
+}
+
+func TestValidateCredentialsRejected(t *testing.T) {
+	s, stream := newHandshakeSession(SessionConfig{LoginLimiter: NewLoginLimiter(DefaultLoginLimitPolicy)})
+	stream.recvPackets <- shanPacket{cmd: connection.PacketAuthFailure}
+
+	if _, err := s.validateCredentials(PasswordCredentials("user", "wrong")); err != ErrAuthenticationFailed {
+		t.Errorf("Expected ErrAuthenticationFailed, got %v", err)
+	}
+}
+
+func TestValidateCredentialsAuthError(t *testing.T) {
+	limiter := NewLoginLimiter(LoginLimitPolicy{Cooldown: time.Hour, MaxCooldown: time.Hour})
+	failure := func(code Spotify.ErrorCode) error {
+		s, stream := newHandshakeSession(SessionConfig{LoginLimiter: limiter})
+		failed, _ := proto.Marshal(&Spotify.APLoginFailed{
+			ErrorCode:        code.Enum(),
+			RetryDelay:       proto.Int32(5),
+			ErrorDescription: proto.String("description"),
+		})
+		stream.recvPackets <- shanPacket{cmd: connection.PacketAuthFailure, buf: failed}
+		_, err := s.validateCredentials(PasswordCredentials("user", "password"))
+		return err
+	}
+
+	// Refusals of the access point don't count as failed logins
+	err := failure(Spotify.ErrorCode_TryAnotherAP)
+	authErr, ok := err.(*AuthError)
+	if !ok || !authErr.Retryable() || authErr.RetryDelay != 5*time.Second || !errors.Is(err, ErrTryAnotherAP) {
+		t.Errorf("Bad error for TryAnotherAP: %#v", err)
+	}
+
+	err = failure(Spotify.ErrorCode_BadCredentials)
+	if !errors.Is(err, ErrBadCredentials) || !errors.Is(err, ErrAuthenticationFailed) || errors.Is(err, ErrTryAnotherAP) {
+		t.Errorf("Bad error for BadCredentials: %v", err)
+	}
+	if err.(*AuthError).Retryable() {
+		t.Errorf("Bad credentials are retryable")
+	}
+
+	if _, ok := failure(Spotify.ErrorCode_BadCredentials).(*LoginCooldownError); !ok {
+		t.Errorf("Login not limited after bad credentials")
+	}
+
+	limiter = NewLoginLimiter(DefaultLoginLimitPolicy)
+	if err := failure(Spotify.ErrorCode_PremiumAccountRequired); !errors.Is(err, ErrPremiumRequired) {
+		t.Errorf("Bad error for PremiumAccountRequired: %v", err)
+	}
+}
+
+func TestHandshakeBadSignature(t *testing.T) {
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newHandshakeSession(SessionConfig{ApServerKey: &other.PublicKey})
+	if err := s.startConnection(); err != crypto.ErrBadSignature {
+		t.Errorf("Expected ErrBadSignature, got %v", err)
+	}
+}
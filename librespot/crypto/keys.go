@@ -143,3 +143,13 @@ func (p *PrivateKeys) ClientNonce() []byte {
 func (s *SharedKeys) Challenge() []byte {
 	return s.challenge
 }
+
+// Reversed returns the keys of the other end of the connection, whose send key is the receive key, e.g. to accept
+// the connection of a client as a server
+func (s *SharedKeys) Reversed() SharedKeys {
+	return SharedKeys{
+		challenge: s.challenge,
+		sendKey:   s.recvKey,
+		recvKey:   s.sendKey,
+	}
+}
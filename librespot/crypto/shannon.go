@@ -43,7 +43,7 @@ func CreateStream(keys SharedKeys, conn connection.PlainConnection) connection.P
 	return s
 }
 
-func (s *shannonStream) SendPacket(cmd uint8, data []byte) (err error) {
+func (s *shannonStream) SendPacket(cmd connection.PacketType, data []byte) (err error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -55,7 +55,7 @@ func (s *shannonStream) SendPacket(cmd uint8, data []byte) (err error) {
 	return
 }
 
-func cipherPacket(cmd uint8, data []byte) []byte {
+func cipherPacket(cmd connection.PacketType, data []byte) []byte {
 	buf := new(bytes.Buffer)
 	binary.Write(buf, binary.BigEndian, cmd)
 	binary.Write(buf, binary.BigEndian, uint16(len(data)))
@@ -88,7 +88,8 @@ func (s *shannonStream) WrapWriter(writer io.Writer) {
 
 func (s *shannonStream) Read(p []byte) (n int, err error) {
 	n, err = s.reader.Read(p)
-	p = s.Decrypt(p)
+	// Only the bytes read are decrypted, the cipher would get out of step on a short read otherwise
+	s.Decrypt(p[:n])
 	return n, err
 }
 
@@ -130,7 +131,7 @@ func (s *shannonStream) finishRecv() {
 	shn_nonce(&s.recvCipher, nonce, len(nonce))
 }
 
-func (s *shannonStream) RecvPacket() (cmd uint8, buf []byte, err error) {
+func (s *shannonStream) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
 	err = binary.Read(s, binary.BigEndian, &cmd)
 	if err != nil {
 		return
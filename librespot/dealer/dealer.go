@@ -0,0 +1,528 @@
+package dealer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"golang.org/x/net/websocket"
+)
+
+// DefaultHost is the dealer used when none could be resolved
+const DefaultHost = "dealer.spotify.com:443"
+
+const (
+	// DefaultPingInterval is how often a ping is sent to keep the connection alive
+	DefaultPingInterval = 30 * time.Second
+	// DefaultPongTimeout is how long to wait for the pong answering a ping before considering the connection dead
+	DefaultPongTimeout = 10 * time.Second
+
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+const connectionIdUri = "hm://pusher/v1/connections/"
+
+// ErrClosed is returned when using a closed dealer
+var ErrClosed = errors.New("dealer closed")
+
+// TokenSource returns the access token used to authenticate to the dealer
+type TokenSource func() (string, error)
+
+// Message is a push message sent by the dealer
+type Message struct {
+	Uri     string
+	Headers map[string]string
+	// Payloads are the decoded payloads of the message, usually protobuf messages
+	Payloads [][]byte
+}
+
+// MessageHandler is called for every message whose uri starts with the prefix it was registered for
+type MessageHandler func(msg Message)
+
+// Request is a command sent by another device through the dealer, which must be acknowledged
+type Request struct {
+	Key          string
+	MessageIdent string
+	// Payload is the decoded JSON content of the request
+	Payload json.RawMessage
+}
+
+// RequestHandler handles a request and returns whether it succeeded, which is sent back to the sender
+type RequestHandler func(req Request) bool
+
+type messageHandler struct {
+	prefix  string
+	handler MessageHandler
+}
+
+type requestHandler struct {
+	prefix  string
+	handler RequestHandler
+}
+
+// rawMessage is the JSON structure of the messages exchanged with the dealer
+type rawMessage struct {
+	Type         string            `json:"type"`
+	Uri          string            `json:"uri,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Payloads     []json.RawMessage `json:"payloads,omitempty"`
+	Key          string            `json:"key,omitempty"`
+	MessageIdent string            `json:"message_ident,omitempty"`
+	Message      json.RawMessage   `json:"message,omitempty"`
+	Payload      interface{}       `json:"payload,omitempty"`
+}
+
+// Dealer maintains the WebSocket connection to the dealer, which delivers the push messages (Connect state, playlist
+// updates, ...) of modern Spotify clients. The connection is kept alive with pings, and reestablished whenever it
+// drops, until Close is called.
+type Dealer struct {
+	// lastActivity is the time a message was last received or a connection attempted, in unix nanoseconds. It is
+	// first for 64-bit alignment.
+	lastActivity int64
+
+	// PingInterval is how often a ping is sent to keep the connection alive
+	PingInterval time.Duration
+	// PongTimeout is how long to wait for a pong before reconnecting
+	PongTimeout time.Duration
+	// Dialer is used to establish the TCP connections
+	Dialer *connection.Dialer
+
+	host   string
+	tokens TokenSource
+
+	lock            sync.Mutex
+	conn            *websocket.Conn
+	closed          bool
+	connectionId    string
+	handlers        []messageHandler
+	requestHandlers []requestHandler
+	idCallbacks     []func(id string)
+
+	// lastPong is the time the last pong was received, in unix nanoseconds
+	lastPong int64
+
+	log *log.Logger
+}
+
+// New creates a dealer connecting to host (host:port, or a ws:// or wss:// url), authenticated with the tokens
+// returned by the token source. An empty host uses DefaultHost.
+func New(host string, tokens TokenSource) *Dealer {
+	if host == "" {
+		host = DefaultHost
+	}
+	return &Dealer{
+		PingInterval: DefaultPingInterval,
+		PongTimeout:  DefaultPongTimeout,
+		Dialer:       connection.NewDialer(),
+		host:         host,
+		tokens:       tokens,
+	}
+}
+
+// SetLogger sets the logger receiving the messages of the dealer, the standard logger being used if nil. It must be
+// called before Connect.
+func (d *Dealer) SetLogger(logger *log.Logger) {
+	d.log = logger
+}
+
+// Logger returns the logger of the dealer, see SetLogger
+func (d *Dealer) Logger() *log.Logger {
+	if d.log == nil {
+		return log.Default()
+	}
+	return d.log
+}
+
+// Handle registers a handler for the messages whose uri starts with prefix
+func (d *Dealer) Handle(prefix string, handler MessageHandler) {
+	d.lock.Lock()
+	d.handlers = append(d.handlers, messageHandler{prefix, handler})
+	d.lock.Unlock()
+}
+
+// HandleRequest registers a handler for the requests whose message ident starts with prefix. Requests not handled
+// by any handler are acknowledged as failed.
+func (d *Dealer) HandleRequest(prefix string, handler RequestHandler) {
+	d.lock.Lock()
+	d.requestHandlers = append(d.requestHandlers, requestHandler{prefix, handler})
+	d.lock.Unlock()
+}
+
+// OnConnectionId registers a callback called with the connection id sent by the dealer, on every (re)connection.
+// The connection id identifies this client when registering its Connect state.
+func (d *Dealer) OnConnectionId(cb func(id string)) {
+	d.lock.Lock()
+	d.idCallbacks = append(d.idCallbacks, cb)
+	id := d.connectionId
+	d.lock.Unlock()
+
+	if id != "" {
+		cb(id)
+	}
+}
+
+// ConnectionId returns the id of the current connection, or an empty string if not received yet
+func (d *Dealer) ConnectionId() string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.connectionId
+}
+
+// Connect establishes the connection to the dealer. Once connected, the connection is reestablished automatically
+// whenever it drops.
+func (d *Dealer) Connect() error {
+	conn, err := d.dial()
+	if err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		conn.Close()
+		return ErrClosed
+	}
+	d.conn = conn
+	d.lock.Unlock()
+
+	go d.run(conn)
+	return nil
+}
+
+// Close closes the connection to the dealer, and stops reconnecting
+func (d *Dealer) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	if d.conn != nil {
+		return d.conn.Close()
+	}
+	return nil
+}
+
+// Reconnect drops the current connection, which is then reestablished as if it had failed
+func (d *Dealer) Reconnect() error {
+	d.lock.Lock()
+	conn, closed := d.conn, d.closed
+	d.lock.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// LastActivity returns the time a message was last received from the dealer, or a connection attempted, and
+// whether the dealer is still in use
+func (d *Dealer) LastActivity() (time.Time, bool) {
+	return time.Unix(0, atomic.LoadInt64(&d.lastActivity)), !d.isClosed()
+}
+
+func (d *Dealer) isClosed() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.closed
+}
+
+func (d *Dealer) dial() (*websocket.Conn, error) {
+	token, err := d.tokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dealer token: %v", err)
+	}
+
+	base := d.host
+	if !strings.Contains(base, "://") {
+		base = "wss://" + base
+	}
+	location, err := url.Parse(base + "/?access_token=" + url.QueryEscape(token))
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := websocket.NewConfig(location.String(), "https://open.spotify.com")
+	if err != nil {
+		return nil, err
+	}
+
+	address := location.Host
+	if location.Port() == "" {
+		if location.Scheme == "wss" {
+			address = net.JoinHostPort(location.Hostname(), "443")
+		} else {
+			address = net.JoinHostPort(location.Hostname(), "80")
+		}
+	}
+
+	conn, err := d.Dialer.Dial(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to dealer %s: %v", address, err)
+	}
+	if location.Scheme == "wss" {
+		tlsConn := d.Dialer.TLSClient(conn, location.Hostname())
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("dealer tls handshake failed: %v", err)
+		}
+		conn = tlsConn
+	}
+
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("dealer handshake failed: %v", err)
+	}
+	return ws, nil
+}
+
+// run serves the connection, and reconnects with an exponential backoff whenever it drops
+func (d *Dealer) run(conn *websocket.Conn) {
+	for {
+		d.serve(conn)
+		if d.isClosed() {
+			return
+		}
+		d.Logger().Println("Dealer connection lost, reconnecting")
+
+		conn = nil
+		for delay := minReconnectDelay; conn == nil; delay *= 2 {
+			if delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+			time.Sleep(delay)
+			if d.isClosed() {
+				return
+			}
+
+			atomic.StoreInt64(&d.lastActivity, time.Now().UnixNano())
+			var err error
+			conn, err = d.dial()
+			if err != nil {
+				d.Logger().Println("Failed to reconnect to the dealer:", err)
+			}
+		}
+
+		d.lock.Lock()
+		if d.closed {
+			d.lock.Unlock()
+			conn.Close()
+			return
+		}
+		d.conn = conn
+		d.lock.Unlock()
+	}
+}
+
+// serve reads the messages of the connection until it is closed or stops answering pings
+func (d *Dealer) serve(conn *websocket.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+
+	atomic.StoreInt64(&d.lastPong, time.Now().UnixNano())
+	atomic.StoreInt64(&d.lastActivity, time.Now().UnixNano())
+	go d.keepAlive(conn, done)
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			conn.Close()
+			return
+		}
+
+		atomic.StoreInt64(&d.lastActivity, time.Now().UnixNano())
+
+		msg := &rawMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			d.Logger().Println("Bad dealer message:", err)
+			continue
+		}
+		d.dispatch(conn, msg)
+	}
+}
+
+// keepAlive sends pings periodically, and closes the connection if the previous one was not answered in time
+func (d *Dealer) keepAlive(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(d.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			lastPong := time.Unix(0, atomic.LoadInt64(&d.lastPong))
+			if time.Since(lastPong) > d.PingInterval+d.PongTimeout {
+				d.Logger().Println("Dealer did not answer ping")
+				conn.Close()
+				return
+			}
+			if err := websocket.JSON.Send(conn, &rawMessage{Type: "ping"}); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (d *Dealer) dispatch(conn *websocket.Conn, msg *rawMessage) {
+	switch msg.Type {
+	case "pong":
+		atomic.StoreInt64(&d.lastPong, time.Now().UnixNano())
+
+	case "ping":
+		websocket.JSON.Send(conn, &rawMessage{Type: "pong"})
+
+	case "message":
+		d.handleMessage(msg)
+
+	case "request":
+		success := d.handleRequest(msg)
+		websocket.JSON.Send(conn, &rawMessage{
+			Type:    "reply",
+			Key:     msg.Key,
+			Payload: map[string]bool{"success": success},
+		})
+	}
+}
+
+func (d *Dealer) handleMessage(msg *rawMessage) {
+	if strings.HasPrefix(msg.Uri, connectionIdUri) {
+		d.setConnectionId(msg.Headers["Spotify-Connection-Id"])
+		return
+	}
+
+	payloads, err := decodePayloads(msg.Headers, msg.Payloads)
+	if err != nil {
+		d.Logger().Printf("Bad payload for dealer message %s: %v\n", msg.Uri, err)
+		return
+	}
+
+	d.lock.Lock()
+	handlers := make([]messageHandler, len(d.handlers))
+	copy(handlers, d.handlers)
+	d.lock.Unlock()
+
+	for _, h := range handlers {
+		if strings.HasPrefix(msg.Uri, h.prefix) {
+			h.handler(Message{
+				Uri:      msg.Uri,
+				Headers:  msg.Headers,
+				Payloads: payloads,
+			})
+		}
+	}
+}
+
+func (d *Dealer) handleRequest(msg *rawMessage) bool {
+	payload, err := decodeRequest(msg.Message)
+	if err != nil {
+		d.Logger().Printf("Bad payload for dealer request %s: %v\n", msg.MessageIdent, err)
+		return false
+	}
+
+	d.lock.Lock()
+	var handler RequestHandler
+	for _, h := range d.requestHandlers {
+		if strings.HasPrefix(msg.MessageIdent, h.prefix) {
+			handler = h.handler
+			break
+		}
+	}
+	d.lock.Unlock()
+
+	if handler == nil {
+		return false
+	}
+	return handler(Request{
+		Key:          msg.Key,
+		MessageIdent: msg.MessageIdent,
+		Payload:      payload,
+	})
+}
+
+func (d *Dealer) setConnectionId(id string) {
+	if id == "" {
+		return
+	}
+
+	d.lock.Lock()
+	d.connectionId = id
+	callbacks := make([]func(string), len(d.idCallbacks))
+	copy(callbacks, d.idCallbacks)
+	d.lock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(id)
+	}
+}
+
+// decodePayloads decodes the payloads of a message: JSON strings are base64 encoded data, gzipped if the
+// Transfer-Encoding header says so, and other JSON values are kept as is
+func decodePayloads(headers map[string]string, raw []json.RawMessage) ([][]byte, error) {
+	payloads := make([][]byte, 0, len(raw))
+	for _, r := range raw {
+		var encoded string
+		if err := json.Unmarshal(r, &encoded); err != nil {
+			payloads = append(payloads, r)
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if headers["Transfer-Encoding"] == "gzip" {
+			data, err = gunzip(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+		payloads = append(payloads, data)
+	}
+	return payloads, nil
+}
+
+// decodeRequest returns the JSON content of a request, which may be sent gzipped and base64 encoded in a
+// "compressed" field
+func decodeRequest(raw json.RawMessage) (json.RawMessage, error) {
+	var compressed struct {
+		Compressed string `json:"compressed"`
+	}
+	if err := json.Unmarshal(raw, &compressed); err != nil || compressed.Compressed == "" {
+		return raw, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(compressed.Compressed)
+	if err != nil {
+		return nil, err
+	}
+	return gunzip(data)
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
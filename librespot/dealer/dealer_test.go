@@ -0,0 +1,191 @@
+package dealer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// fakeDealer is a dealer server passing its connections to the test
+type fakeDealer struct {
+	server *httptest.Server
+	conns  chan *websocket.Conn
+	tokens chan string
+	done   chan struct{}
+}
+
+func newFakeDealer() *fakeDealer {
+	f := &fakeDealer{
+		conns:  make(chan *websocket.Conn, 5),
+		tokens: make(chan string, 5),
+		done:   make(chan struct{}),
+	}
+	f.server = httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		f.tokens <- conn.Request().URL.Query().Get("access_token")
+		f.conns <- conn
+		// Keep the connection open until the end of the test
+		<-f.done
+	}))
+	return f
+}
+
+func (f *fakeDealer) Close() {
+	close(f.done)
+	f.server.Close()
+}
+
+func (f *fakeDealer) accept(t *testing.T) *websocket.Conn {
+	select {
+	case conn := <-f.conns:
+		return conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("no connection to the dealer")
+		return nil
+	}
+}
+
+func newTestDealer(f *fakeDealer) *Dealer {
+	return New(strings.Replace(f.server.URL, "http://", "ws://", 1), func() (string, error) {
+		return "secret", nil
+	})
+}
+
+func gzipBase64(data []byte) string {
+	buf := new(bytes.Buffer)
+	writer := gzip.NewWriter(buf)
+	writer.Write(data)
+	writer.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDealerMessages(t *testing.T) {
+	f := newFakeDealer()
+	defer f.Close()
+
+	d := newTestDealer(f)
+	if err := d.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	server := f.accept(t)
+	if token := <-f.tokens; token != "secret" {
+		t.Errorf("Bad access token %q", token)
+	}
+
+	ids := make(chan string, 1)
+	d.OnConnectionId(func(id string) { ids <- id })
+	messages := make(chan Message, 1)
+	d.Handle("hm://connect-state/v1/cluster", func(msg Message) { messages <- msg })
+
+	websocket.JSON.Send(server, &rawMessage{
+		Type:    "message",
+		Uri:     "hm://pusher/v1/connections/abc",
+		Headers: map[string]string{"Spotify-Connection-Id": "conn-id"},
+	})
+	select {
+	case id := <-ids:
+		if id != "conn-id" || d.ConnectionId() != "conn-id" {
+			t.Errorf("Bad connection id %q", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection id not received")
+	}
+
+	payload, _ := json.Marshal(gzipBase64([]byte("cluster update")))
+	websocket.JSON.Send(server, &rawMessage{
+		Type:     "message",
+		Uri:      "hm://connect-state/v1/cluster",
+		Headers:  map[string]string{"Transfer-Encoding": "gzip"},
+		Payloads: []json.RawMessage{payload},
+	})
+	select {
+	case msg := <-messages:
+		if len(msg.Payloads) != 1 || string(msg.Payloads[0]) != "cluster update" {
+			t.Errorf("Bad payloads %q", msg.Payloads)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+}
+
+func TestDealerRequest(t *testing.T) {
+	f := newFakeDealer()
+	defer f.Close()
+
+	d := newTestDealer(f)
+	d.HandleRequest("hm://connect-state/v1/player/command", func(req Request) bool {
+		return string(req.Payload) == `{"command":{"endpoint":"pause"}}`
+	})
+	if err := d.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	server := f.accept(t)
+
+	replies := make(chan map[string]interface{}, 2)
+	go func() {
+		for {
+			var reply map[string]interface{}
+			if websocket.JSON.Receive(server, &reply) != nil {
+				return
+			}
+			replies <- reply
+		}
+	}()
+
+	compressed, _ := json.Marshal(map[string]string{
+		"compressed": gzipBase64([]byte(`{"command":{"endpoint":"pause"}}`)),
+	})
+	websocket.JSON.Send(server, &rawMessage{
+		Type:         "request",
+		Key:          "key1",
+		MessageIdent: "hm://connect-state/v1/player/command",
+		Message:      compressed,
+	})
+	websocket.JSON.Send(server, &rawMessage{
+		Type:         "request",
+		Key:          "key2",
+		MessageIdent: "hm://unknown",
+		Message:      json.RawMessage(`{}`),
+	})
+
+	for _, want := range []struct {
+		key     string
+		success bool
+	}{{"key1", true}, {"key2", false}} {
+		select {
+		case reply := <-replies:
+			payload, _ := reply["payload"].(map[string]interface{})
+			if reply["type"] != "reply" || reply["key"] != want.key || payload["success"] != want.success {
+				t.Errorf("Bad reply %v, want %s %v", reply, want.key, want.success)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no reply")
+		}
+	}
+}
+
+func TestDealerReconnectsWithoutPong(t *testing.T) {
+	f := newFakeDealer()
+	defer f.Close()
+
+	d := newTestDealer(f)
+	d.PingInterval = 20 * time.Millisecond
+	d.PongTimeout = 20 * time.Millisecond
+	if err := d.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// The fake server never answers the pings, so the dealer eventually reconnects
+	f.accept(t)
+	f.accept(t)
+}
@@ -1,24 +1,35 @@
 package discovery
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/badfortrains/mdns"
 	"log"
-	"math/rand"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fischerling/librespot-golang/librespot/crypto"
 	"github.com/fischerling/librespot-golang/librespot/utils"
 	"net"
 )
 
+// ErrDisabled is returned when using the mdns features of a build with the nodiscovery tag
+var ErrDisabled = errors.New("discovery is disabled in this build")
+
+// mdnsServer is the mdns responder advertising the device, only available without the nodiscovery tag
+type mdnsServer interface {
+	Shutdown() error
+}
+
+// checkTimeout is how long Check waits for the http server to answer
+const checkTimeout = 5 * time.Second
+
 // connectInfo stores the information about Spotify Connect connection
 type connectInfo struct {
 	DeviceID  string `json:"deviceID"`
@@ -48,6 +59,13 @@ type connectGetInfo struct {
 	ModelDisplayName string `json:"modelDisplayName"`
 }
 
+// ActiveUser describes the user currently logged in through this device
+type ActiveUser struct {
+	Username    string
+	DisplayName string
+	ImageUrl    string
+}
+
 // Discovery stores the information about Spotify Connect Discovery Request
 type Discovery struct {
 	keys       crypto.PrivateKeys
@@ -56,14 +74,29 @@ type Discovery struct {
 	deviceId   string
 	deviceName string
 
-	mdnsServer  *mdns.Server
-	httpServer  *http.Server
+	mdnsServer mdnsServer
+	mdnsLock   sync.Mutex
+	// httpServer answers the Spotify Connect requests on listenAddr, until the discovery is closed
+	httpServer   *http.Server
+	httpLock     sync.Mutex
+	listenAddr   string
+	serverConfig ServerConfig
+	// loggedIn is closed once a user connected through the http server
+	loggedIn  chan struct{}
+	loginOnce sync.Once
+
+	callbacks     []EventCallback
+	callbacksLock sync.Mutex
+
 	devices     []connectDeviceMdns
 	devicesLock sync.RWMutex
+
+	activeUser     ActiveUser
+	activeUserLock sync.RWMutex
 }
 
 // makeConnectGetInfo builds a connectGetInfo structure with the provided values
-func makeConnectGetInfo(deviceId string, deviceName string, publicKey string) connectGetInfo {
+func makeConnectGetInfo(deviceId string, deviceName string, publicKey string, activeUser string) connectGetInfo {
 	return connectGetInfo{
 		Status:           101,
 		StatusError:      "ERROR-OK",
@@ -71,7 +104,7 @@ func makeConnectGetInfo(deviceId string, deviceName string, publicKey string) co
 		Version:          "1.3.0",
 		DeviceID:         deviceId,
 		RemoteName:       deviceName,
-		ActiveUser:       "",
+		ActiveUser:       activeUser,
 		PublicKey:        publicKey,
 		DeviceType:       "UNKNOWN",
 		LibraryVersion:   "0.1.0",
@@ -81,60 +114,125 @@ func makeConnectGetInfo(deviceId string, deviceName string, publicKey string) co
 	}
 }
 
-func blobFromDiscovery(deviceName string) *utils.BlobInfo {
+func blobFromDiscovery(deviceName string) (*utils.BlobInfo, error) {
 	deviceId := utils.GenerateDeviceId(deviceName)
-	d := LoginFromConnect("", deviceId, deviceName)
-	return &d.loginBlob
+	d, err := LoginFromConnect("", deviceId, deviceName)
+	if err != nil {
+		return nil, err
+	}
+	return &d.loginBlob, nil
 }
 
 // Advertises a Spotify service via mdns. It waits for the user to connect to 'librespot' device, extracts login data
-// and returns the resulting login BlobInfo.
-func LoginFromConnect(cachePath string, deviceId string, deviceName string) *Discovery {
-	d := Discovery{
-		keys:       crypto.GenerateKeys(),
-		cachePath:  cachePath,
-		deviceId:   deviceId,
-		deviceName: deviceName,
-	}
-
-	done := make(chan int)
-
-	l, err := net.Listen("tcp", ":8000")
+// and returns the resulting login BlobInfo. See Listen to configure the server, or not to wait for the user.
+func LoginFromConnect(cachePath string, deviceId string, deviceName string) (*Discovery, error) {
+	// The server is kept running after the login, so that the apps can still get the device info and its active
+	// user. It is closed with the discovery.
+	d, err := Listen(ServerConfig{}, cachePath, deviceId, deviceName)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	defer l.Close()
-	go d.startHttp(done, l)
-	d.startDiscoverable()
-
-	<-done
 
-	return &d
+	d.WaitLogin(context.Background())
+	return d, nil
 }
 
-func CreateFromBlob(blob utils.BlobInfo, cachePath, deviceId string, deviceName string) *Discovery {
+func CreateFromBlob(blob utils.BlobInfo, cachePath, deviceId string, deviceName string) (*Discovery, error) {
 	d := Discovery{
 		keys:       crypto.GenerateKeys(),
 		cachePath:  cachePath,
 		deviceId:   deviceId,
 		loginBlob:  blob,
 		deviceName: deviceName,
+		loggedIn:   make(chan struct{}),
 	}
+	d.loginOnce.Do(func() {
+		close(d.loggedIn)
+	})
 
-	d.FindDevices()
+	// Without mdns support, the session can still be used, only not to control the other devices
+	err := d.FindDevices()
+	if err != nil && err != ErrDisabled {
+		return nil, err
+	}
 
-	return &d
+	return &d, nil
 }
 
-func CreateFromFile(cachePath, deviceId string, deviceName string) *Discovery {
+func CreateFromFile(cachePath, deviceId string, deviceName string) (*Discovery, error) {
 	blob, err := utils.BlobFromFile(cachePath)
 	if err != nil {
-		log.Fatal("failed to get blob from file")
+		return nil, fmt.Errorf("failed to get blob from file: %v", err)
 	}
 
 	return CreateFromBlob(blob, cachePath, deviceId, deviceName)
 }
 
+// Close shuts down the mdns and http servers immediately, if they are still running. See Shutdown to wait for the
+// requests in progress.
+func (d *Discovery) Close() error {
+	d.stopDiscoverable()
+
+	d.httpLock.Lock()
+	defer d.httpLock.Unlock()
+	if d.httpServer != nil {
+		err := d.httpServer.Close()
+		d.httpServer = nil
+		return err
+	}
+
+	return nil
+}
+
+// Check verifies that the http server still answers the Spotify Connect requests, if it is running
+func (d *Discovery) Check() error {
+	d.httpLock.Lock()
+	running, addr := d.httpServer != nil, d.listenAddr
+	d.httpLock.Unlock()
+	if !running {
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: checkTimeout}
+	scheme := "http"
+	if d.serverConfig.TLSConfig != nil {
+		// The server is reached by its loopback address, which its certificate doesn't have to cover
+		scheme = "https"
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Get(scheme + "://" + net.JoinHostPort("127.0.0.1", port) + "/?action=connectGetInfo")
+	if err != nil {
+		return fmt.Errorf("discovery server does not answer: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("discovery server answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Restart closes the http server, and starts it again on the same address
+func (d *Discovery) Restart() error {
+	d.httpLock.Lock()
+	defer d.httpLock.Unlock()
+	if d.httpServer == nil {
+		return errors.New("discovery server is not running")
+	}
+
+	d.httpServer.Close()
+	l, err := d.listen(d.listenAddr)
+	if err != nil {
+		d.httpServer = nil
+		return err
+	}
+	d.serveHttp(l)
+	return nil
+}
+
 func (d *Discovery) DeviceId() string {
 	return d.deviceId
 }
@@ -147,68 +245,122 @@ func (d *Discovery) LoginBlob() utils.BlobInfo {
 	return d.loginBlob
 }
 
+// ActiveUser returns the user currently logged in through this device. Only the username is known until
+// SetActiveUser is called.
+func (d *Discovery) ActiveUser() ActiveUser {
+	d.activeUserLock.RLock()
+	user := d.activeUser
+	d.activeUserLock.RUnlock()
+
+	if user.Username == "" {
+		user.Username = d.loginBlob.Username
+	}
+	return user
+}
+
+// SetActiveUser sets the user logged in through this device, e.g. once its profile has been fetched, and emits an
+// EventActiveUser. Its username is reported to the Spotify apps through getInfo, and its display name by the Spirc
+// controllers of the session through the connect-state service.
+func (d *Discovery) SetActiveUser(user ActiveUser) {
+	d.activeUserLock.Lock()
+	d.activeUser = user
+	d.activeUserLock.Unlock()
+
+	d.emit(Event{Type: EventActiveUser, Username: user.Username, User: user})
+}
+
 // Devices return an immutable copy of the current MDNS-discovered devices, thread-safe
 func (d *Discovery) Devices() []connectDeviceMdns {
+	d.devicesLock.RLock()
+	defer d.devicesLock.RUnlock()
 	res := make([]connectDeviceMdns, 0, len(d.devices))
 	return append(res, d.devices...)
 }
 
-func (d *Discovery) FindDevices() {
-	ch := make(chan *mdns.ServiceEntry, 10)
-
-	d.devices = make([]connectDeviceMdns, 0)
-	go func() {
-		for entry := range ch {
-			cPath := findCpath(entry.InfoFields)
-			path := fmt.Sprintf("http://%v:%v%v", entry.AddrV4, entry.Port, cPath)
-			fmt.Println("Found a device", entry)
-			d.devicesLock.Lock()
-			d.devices = append(d.devices, connectDeviceMdns{
-				Path: path,
-				Name: strings.Replace(entry.Name, "._spotify-connect._tcp.local.", "", 1),
-			})
-			fmt.Println("devices", d.devices)
-			d.devicesLock.Unlock()
+// updateDevices replaces the devices found, and notifies the callbacks of the devices added and removed
+func (d *Discovery) updateDevices(found []connectDeviceMdns) {
+	// A device may answer the lookup several times
+	current := map[string]bool{}
+	var devices []connectDeviceMdns
+	for _, device := range found {
+		if !current[device.Path] {
+			current[device.Path] = true
+			devices = append(devices, device)
 		}
-		fmt.Println("closed")
-	}()
+	}
 
-	err := mdns.Lookup("_spotify-connect._tcp.", ch)
-	if err != nil {
-		log.Fatal("lookup error", err)
+	d.devicesLock.Lock()
+	previous := d.devices
+	d.devices = devices
+	d.devicesLock.Unlock()
+
+	known := map[string]bool{}
+	for _, device := range previous {
+		known[device.Path] = true
+	}
+	for _, device := range devices {
+		if !known[device.Path] {
+			d.emit(Event{Type: EventDeviceAdded, DeviceName: device.Name, DevicePath: device.Path})
+		}
+	}
+	for _, device := range previous {
+		if !current[device.Path] {
+			d.emit(Event{Type: EventDeviceRemoved, DeviceName: device.Name, DevicePath: device.Path})
+		}
 	}
 }
 
-func (d *Discovery) ConnectToDevice(address string) {
-	resp, err := http.Get(address + "?action=connectGetInfo")
-	resp, err = http.Get(address + "?action=resetUsers")
-	resp, err = http.Get(address + "?action=connectGetInfo")
+func (d *Discovery) ConnectToDevice(address string) error {
+	for _, action := range []string{"connectGetInfo", "resetUsers"} {
+		resp, err := http.Get(address + "?action=" + action)
+		if err != nil {
+			return fmt.Errorf("%s request failed: %v", action, err)
+		}
+		resp.Body.Close()
+	}
 
-	fmt.Println("start get")
+	resp, err := http.Get(address + "?action=connectGetInfo")
+	if err != nil {
+		return fmt.Errorf("connectGetInfo request failed: %v", err)
+	}
 	defer resp.Body.Close()
+
 	decoder := json.NewDecoder(resp.Body)
 	info := connectInfo{}
 	err = decoder.Decode(&info)
 	if err != nil {
-		panic("bad json")
+		return fmt.Errorf("bad connectGetInfo json: %v", err)
 	}
-	fmt.Println("resposne", resp)
 
 	client64 := base64.StdEncoding.EncodeToString(d.keys.PubKey())
 	blob, err := d.loginBlob.MakeAuthBlob(info.DeviceID,
 		info.PublicKey, d.keys)
 	if err != nil {
-		panic("bad blob")
+		return fmt.Errorf("failed to make auth blob: %v", err)
 	}
 
 	body := makeAddUserRequest(d.loginBlob.Username, blob, client64, d.deviceId, d.deviceName)
-	resp, err = http.PostForm(address, body)
-	defer resp.Body.Close()
-	decoder = json.NewDecoder(resp.Body)
-	var f interface{}
-	err = decoder.Decode(&f)
+	addResp, err := http.PostForm(address, body)
+	if err != nil {
+		return fmt.Errorf("addUser request failed: %v", err)
+	}
+	defer addResp.Body.Close()
 
-	fmt.Println("got", f, resp, err)
+	if addResp.StatusCode < 200 || addResp.StatusCode >= 300 {
+		return fmt.Errorf("addUser request failed: %s", addResp.Status)
+	}
+	var added struct {
+		Status       int    `json:"status"`
+		StatusString string `json:"statusString"`
+		SpotifyError int    `json:"spotifyError"`
+	}
+	if err := json.NewDecoder(addResp.Body).Decode(&added); err != nil {
+		return fmt.Errorf("bad addUser json: %v", err)
+	}
+	if added.SpotifyError != 0 {
+		return fmt.Errorf("addUser refused: %s (%d)", added.StatusString, added.SpotifyError)
+	}
+	return nil
 }
 
 func makeAddUserRequest(username string, blob string, key string, deviceId string, deviceName string) url.Values {
@@ -242,7 +394,7 @@ func (d *Discovery) handleAddUser(r *http.Request) error {
 	blob64 := r.FormValue("blob")
 
 	if username == "" || client64 == "" || blob64 == "" {
-		log.Println("Bad Request, addUser")
+		d.logger().Println("Bad Request, addUser")
 		return errors.New("bad username Request")
 	}
 
@@ -252,24 +404,59 @@ func (d *Discovery) handleAddUser(r *http.Request) error {
 		return errors.New("failed to decode blob")
 	}
 
-	err = blob.SaveToFile(d.cachePath)
-	if err != nil {
-		log.Println("failed to cache login info")
+	if d.cachePath != "" {
+		err = blob.SaveToFile(d.cachePath)
+		if err != nil {
+			d.logger().Printf("failed to cache login info: %v", err)
+		}
 	}
 
 	d.loginBlob = blob
-	d.mdnsServer.Shutdown()
+	d.stopDiscoverable()
 	return nil
 }
 
-func (d *Discovery) startHttp(done chan int, l net.Listener) {
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+// logger returns the logger of the server, see ServerConfig.Logger
+func (d *Discovery) logger() *log.Logger {
+	if d.serverConfig.Logger == nil {
+		return log.Default()
+	}
+	return d.serverConfig.Logger
+}
+
+// userConnected releases WaitLogin and notifies the callbacks once a user connected
+func (d *Discovery) userConnected() {
+	d.loginOnce.Do(func() {
+		close(d.loggedIn)
+		d.emit(Event{Type: EventUserConnected, Username: d.loginBlob.Username})
+	})
+}
+
+// stopDiscoverable stops the mdns advertisement, if it is running
+func (d *Discovery) stopDiscoverable() {
+	d.mdnsLock.Lock()
+	defer d.mdnsLock.Unlock()
+	if d.mdnsServer != nil {
+		d.mdnsServer.Shutdown()
+		d.mdnsServer = nil
+	}
+}
+
+func (d *Discovery) startHttp(l net.Listener) {
+	d.httpLock.Lock()
+	defer d.httpLock.Unlock()
+	d.serveHttp(l)
+}
+
+// serveHttp starts serving the Spotify Connect requests on the listener, httpLock must be held
+func (d *Discovery) serveHttp(l net.Listener) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		action := r.FormValue("action")
-		fmt.Println("got Request: ", action)
 		switch {
 		case "connectGetInfo" == action || "resetUsers" == action:
 			client64 := base64.StdEncoding.EncodeToString(d.keys.PubKey())
-			info := makeConnectGetInfo(d.deviceId, d.deviceName, client64)
+			info := makeConnectGetInfo(d.deviceId, d.deviceName, client64, d.ActiveUser().Username)
 
 			js, err := json.Marshal(info)
 			if err != nil {
@@ -280,53 +467,22 @@ func (d *Discovery) startHttp(done chan int, l net.Listener) {
 			w.Header().Set("Content-Type", "application/json")
 			w.Write(js)
 		case "addUser" == action:
-			err := d.handleAddUser(r)
-			if err == nil {
-				done <- 1
+			if err := d.handleAddUser(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
 			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":101,"statusString":"OK","spotifyError":0}`))
+			d.userConnected()
 		}
 	})
 
-	d.httpServer = &http.Server{}
-	err := d.httpServer.Serve(l)
-	if err != nil {
-		fmt.Println("got an error", err)
-	}
-}
-
-func (d *Discovery) startDiscoverable() {
-	fmt.Println("start discoverable")
-	info := []string{"VERSION=1.0", "CPath=/"}
-
-	ifaces, err := net.Interfaces()
-	// Handle err
-	ips := make([]net.IP, 0)
-	for _, i := range ifaces {
-		addrs, _ := i.Addrs()
-		// Handle err
-		for _, addr := range addrs {
-			switch v := addr.(type) {
-			case *net.IPNet:
-				ips = append(ips, v.IP)
-			case *net.IPAddr:
-				ips = append(ips, v.IP)
-			}
-			fmt.Println("found ip ", ips)
-			// process IP address
+	d.listenAddr = l.Addr().String()
+	d.httpServer = &http.Server{Handler: mux}
+	go func(server *http.Server) {
+		err := server.Serve(l)
+		if err != nil && err != http.ErrServerClosed {
+			d.logger().Printf("discovery server failed: %v", err)
 		}
-	}
-
-	service, err := mdns.NewMDNSService("librespot"+strconv.Itoa(rand.Intn(200)),
-		"_spotify-connect._tcp", "", "", 8000, ips, info)
-	if err != nil {
-		fmt.Println(err)
-		log.Fatal("error starting Discovery")
-	}
-	server, err := mdns.NewServer(&mdns.Config{
-		Zone: service,
-	})
-	if err != nil {
-		log.Fatal("error starting Discovery")
-	}
-	d.mdnsServer = server
+	}(d.httpServer)
 }
@@ -0,0 +1,172 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/crypto"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+func TestUpdateDevicesEvents(t *testing.T) {
+	d := &Discovery{}
+	var events []Event
+	d.OnEvent(func(event Event) {
+		events = append(events, event)
+	})
+
+	a := connectDeviceMdns{Path: "http://10.0.0.1:8000/", Name: "a"}
+	b := connectDeviceMdns{Path: "http://10.0.0.2:8000/", Name: "b"}
+	d.updateDevices([]connectDeviceMdns{a, a})
+	d.updateDevices([]connectDeviceMdns{b})
+
+	if len(d.Devices()) != 1 || d.Devices()[0].Name != "b" {
+		t.Errorf("Bad devices %v", d.Devices())
+	}
+	expected := []Event{
+		{Type: EventDeviceAdded, DeviceName: "a", DevicePath: a.Path},
+		{Type: EventDeviceAdded, DeviceName: "b", DevicePath: b.Path},
+		{Type: EventDeviceRemoved, DeviceName: "a", DevicePath: a.Path},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Event %d: expected %v, got %v", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestSetActiveUser(t *testing.T) {
+	d := &Discovery{}
+	var events []Event
+	d.OnEvent(func(event Event) {
+		events = append(events, event)
+	})
+
+	user := ActiveUser{Username: "user", DisplayName: "User", ImageUrl: "https://i.scdn.co/image/user"}
+	d.SetActiveUser(user)
+	if d.ActiveUser() != user {
+		t.Errorf("Got active user %+v", d.ActiveUser())
+	}
+	if len(events) != 1 || events[0].Type != EventActiveUser || events[0].User != user {
+		t.Errorf("Got events %v", events)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	var logs bytes.Buffer
+	d := &Discovery{
+		keys:         crypto.GenerateKeys(),
+		loggedIn:     make(chan struct{}),
+		serverConfig: ServerConfig{Logger: log.New(&logs, "", 0)},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.startHttp(l)
+
+	if err := d.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bad requests are logged to the logger of the server
+	resp, err := http.PostForm("http://"+l.Addr().String()+"/", url.Values{"action": {"addUser"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(logs.String(), "Bad Request, addUser") {
+		t.Errorf("Bad addUser answered with %s, logged %q", resp.Status, logs.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := d.WaitLogin(ctx); err != context.Canceled {
+		t.Errorf("Expected the wait to be cancelled, got %v", err)
+	}
+
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Dial("tcp", l.Addr().String()); err == nil {
+		t.Errorf("The server still accepts connections")
+	}
+}
+
+func TestConnectToDevice(t *testing.T) {
+	device := &Discovery{keys: crypto.GenerateKeys(), deviceId: "device", loggedIn: make(chan struct{})}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	device.startHttp(l)
+	defer device.Shutdown(context.Background())
+
+	d := &Discovery{
+		keys:      crypto.GenerateKeys(),
+		loginBlob: utils.BlobInfo{Username: "user", DecodedBlob: base64.StdEncoding.EncodeToString(make([]byte, 16))},
+	}
+	if err := d.ConnectToDevice("http://" + l.Addr().String() + "/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := device.WaitLogin(context.Background()); err != nil || device.loginBlob.Username != "user" {
+		t.Errorf("User %q not connected: %v", device.loginBlob.Username, err)
+	}
+
+	// The refusals of the device are returned
+	refusing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("action") == "addUser" {
+			http.Error(w, "bad blob", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"deviceID":"device","publicKey":""}`))
+	}))
+	defer refusing.Close()
+	if err := d.ConnectToDevice(refusing.URL); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected the refusal, got %v", err)
+	}
+}
+
+func TestTxtRecords(t *testing.T) {
+	config := ServerConfig{TxtRecords: map[string]string{"room": "Kitchen", "fleet": "42"}}
+	info, err := config.txtRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(info, ";") != "VERSION=1.0;CPath=/;fleet=42;room=Kitchen" {
+		t.Errorf("Bad TXT record %v", info)
+	}
+
+	for _, records := range []map[string]string{{"cpath": "/x"}, {"a=b": "c"}, {"long": strings.Repeat("x", 255)}} {
+		if _, err := (ServerConfig{TxtRecords: records}).txtRecords(); err == nil {
+			t.Errorf("Expected an error for %v", records)
+		}
+	}
+
+	if name := (ServerConfig{InstanceName: "librespot-kitchen"}).instanceName(); name != "librespot-kitchen" {
+		t.Errorf("Bad instance name %s", name)
+	}
+}
+
+func TestServerKeys(t *testing.T) {
+	config := ServerConfig{PrivateKey: crypto.RandomVec(95)}
+	a, b := config.keys(), config.keys()
+	if !bytes.Equal(a.PubKey(), b.PubKey()) {
+		t.Errorf("The public key of the private key changed")
+	}
+	c, d := ServerConfig{}.keys(), ServerConfig{}.keys()
+	if bytes.Equal(c.PubKey(), d.PubKey()) {
+		t.Errorf("The generated keys are the same")
+	}
+}
@@ -0,0 +1,74 @@
+//go:build !nodiscovery
+// +build !nodiscovery
+
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/badfortrains/mdns"
+)
+
+// FindDevices looks up the Spotify Connect devices of the local network, listed by Devices. It can be called again
+// to refresh the list, the callbacks are notified of the devices added and removed since the previous lookup.
+func (d *Discovery) FindDevices() error {
+	ch := make(chan *mdns.ServiceEntry, 10)
+	found := make(chan []connectDeviceMdns)
+
+	go func() {
+		var devices []connectDeviceMdns
+		for entry := range ch {
+			cPath := findCpath(entry.InfoFields)
+			devices = append(devices, connectDeviceMdns{
+				Path: fmt.Sprintf("http://%v:%v%v", entry.AddrV4, entry.Port, cPath),
+				Name: strings.Replace(entry.Name, "._spotify-connect._tcp.local.", "", 1),
+			})
+		}
+		found <- devices
+	}()
+
+	params := mdns.DefaultParams("_spotify-connect._tcp.")
+	params.Entries = ch
+	if d.serverConfig.Interface != "" {
+		iface, err := net.InterfaceByName(d.serverConfig.Interface)
+		if err != nil {
+			close(ch)
+			<-found
+			return fmt.Errorf("unknown interface %s: %v", d.serverConfig.Interface, err)
+		}
+		params.Interface = iface
+	}
+
+	// The query returns once its timeout elapsed, no entry is sent afterwards
+	err := mdns.Query(params)
+	close(ch)
+	devices := <-found
+	if err != nil {
+		return fmt.Errorf("mdns lookup error: %v", err)
+	}
+
+	d.updateDevices(devices)
+	return nil
+}
+
+func (d *Discovery) startDiscoverable(instance string, info []string, port int, iface *net.Interface,
+	ips []net.IP) error {
+	service, err := mdns.NewMDNSService(instance, "_spotify-connect._tcp", "", "", port, ips, info)
+	if err != nil {
+		return fmt.Errorf("error starting discovery: %v", err)
+	}
+	server, err := mdns.NewServer(&mdns.Config{
+		Zone:  service,
+		Iface: iface,
+	})
+	if err != nil {
+		return fmt.Errorf("error starting discovery: %v", err)
+	}
+
+	d.mdnsLock.Lock()
+	d.mdnsServer = server
+	d.mdnsLock.Unlock()
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build nodiscovery
+// +build nodiscovery
+
+package discovery
+
+import "net"
+
+// FindDevices is not available in builds with the nodiscovery tag
+func (d *Discovery) FindDevices() error {
+	return ErrDisabled
+}
+
+func (d *Discovery) startDiscoverable(instance string, info []string, port int, iface *net.Interface,
+	ips []net.IP) error {
+	return ErrDisabled
+}
@@ -0,0 +1,279 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"math/big"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fischerling/librespot-golang/librespot/crypto"
+)
+
+// DefaultPort is the port the discovery server listens on when none is configured
+const DefaultPort = 8000
+
+// ServerConfig controls the http server answering the Spotify Connect requests, and its mdns advertisement
+type ServerConfig struct {
+	// Port is the port to listen on, DefaultPort if zero
+	Port int
+	// Interface is the name of the network interface to listen on and to advertise the device on. All the
+	// interfaces are used if empty.
+	Interface string
+	// TLSConfig serves the requests over HTTPS if set
+	TLSConfig *tls.Config
+	// InstanceName is the name of the advertised mdns service instance, e.g. to include the room of the device. It
+	// defaults to "librespot" followed by a random number.
+	InstanceName string
+	// TxtRecords are added to the TXT record of the service, e.g. to identify the device in a fleet. The VERSION and
+	// CPath keys are reserved.
+	TxtRecords map[string]string
+	// PrivateKey is the private Diffie-Hellman key whose public key is advertised to the apps, so that it stays the
+	// same across restarts. A random one is generated if empty.
+	PrivateKey []byte
+	// Logger receives the failures of the server, the standard logger being used if nil
+	Logger *log.Logger
+}
+
+// keys returns the Diffie-Hellman keys of the server
+func (c ServerConfig) keys() crypto.PrivateKeys {
+	if len(c.PrivateKey) == 0 {
+		return crypto.GenerateKeys()
+	}
+	return crypto.GenerateKeysFromPrivate(new(big.Int).SetBytes(c.PrivateKey), crypto.RandomVec(0x10))
+}
+
+// maxTxtLength is the maximum length of a key=value string of a TXT record
+const maxTxtLength = 255
+
+// instanceName returns the name of the advertised service instance
+func (c ServerConfig) instanceName() string {
+	if c.InstanceName != "" {
+		return c.InstanceName
+	}
+	return "librespot" + strconv.Itoa(rand.Intn(200))
+}
+
+// txtRecords returns the strings of the TXT record of the service, the custom ones sorted by key
+func (c ServerConfig) txtRecords() ([]string, error) {
+	info := []string{"VERSION=1.0", "CPath=/"}
+
+	keys := make([]string, 0, len(c.TxtRecords))
+	for key := range c.TxtRecords {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "" || strings.Contains(key, "=") {
+			return nil, fmt.Errorf("invalid TXT record key %q", key)
+		}
+		if strings.EqualFold(key, "VERSION") || strings.EqualFold(key, "CPath") {
+			return nil, fmt.Errorf("TXT record key %s is reserved", key)
+		}
+		record := key + "=" + c.TxtRecords[key]
+		if len(record) > maxTxtLength {
+			return nil, fmt.Errorf("TXT record %s longer than %d bytes", key, maxTxtLength)
+		}
+		info = append(info, record)
+	}
+	return info, nil
+}
+
+// EventType is the type of a discovery event
+type EventType int
+
+const (
+	// EventDeviceAdded is emitted when FindDevices finds a new Spotify Connect device
+	EventDeviceAdded EventType = iota
+	// EventDeviceRemoved is emitted when FindDevices doesn't find a device anymore
+	EventDeviceRemoved
+	// EventUserConnected is emitted when a user connects to this device from a Spotify app
+	EventUserConnected
+	// EventActiveUser is emitted when the profile of the user logged in through this device is known, see
+	// SetActiveUser
+	EventActiveUser
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventDeviceAdded:
+		return "device_added"
+	case EventDeviceRemoved:
+		return "device_removed"
+	case EventUserConnected:
+		return "user_connected"
+	case EventActiveUser:
+		return "active_user"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a discovery event
+type Event struct {
+	Type EventType
+	// DeviceName and DevicePath describe the device added or removed
+	DeviceName string
+	DevicePath string
+	// Username is the user who connected, for EventUserConnected and EventActiveUser
+	Username string
+	// User is the profile of the user, for EventActiveUser
+	User ActiveUser
+}
+
+// EventCallback is called for every discovery event, from the goroutine which detected it. It must not block.
+type EventCallback func(event Event)
+
+// Listen starts the http server and advertises the device via mdns, without waiting for a user to connect, see
+// WaitLogin. The server runs until the discovery is closed or shut down.
+func Listen(config ServerConfig, cachePath string, deviceId string, deviceName string) (*Discovery, error) {
+	d := &Discovery{
+		keys:         config.keys(),
+		cachePath:    cachePath,
+		deviceId:     deviceId,
+		deviceName:   deviceName,
+		serverConfig: config,
+		loggedIn:     make(chan struct{}),
+	}
+
+	info, err := config.txtRecords()
+	if err != nil {
+		return nil, err
+	}
+	iface, ips, err := interfaceAddrs(config.Interface)
+	if err != nil {
+		return nil, err
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	host := ""
+	if iface != nil {
+		host = ips[0].String()
+	}
+
+	l, err := d.listen(net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	d.startHttp(l)
+
+	err = d.startDiscoverable(config.instanceName(), info, port, iface, ips)
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// WaitLogin waits until a user connects to this device from a Spotify app, or the context is done
+func (d *Discovery) WaitLogin(ctx context.Context) error {
+	select {
+	case <-d.loggedIn:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops the mdns advertisement, and shuts the http server down gracefully, waiting for the requests in
+// progress until the context is done
+func (d *Discovery) Shutdown(ctx context.Context) error {
+	d.stopDiscoverable()
+
+	d.httpLock.Lock()
+	server := d.httpServer
+	d.httpServer = nil
+	d.httpLock.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// OnEvent registers a callback notified of the discovery events
+func (d *Discovery) OnEvent(callback EventCallback) {
+	d.callbacksLock.Lock()
+	d.callbacks = append(d.callbacks, callback)
+	d.callbacksLock.Unlock()
+}
+
+func (d *Discovery) emit(event Event) {
+	d.callbacksLock.Lock()
+	callbacks := append([]EventCallback{}, d.callbacks...)
+	d.callbacksLock.Unlock()
+
+	for _, callback := range callbacks {
+		callback(event)
+	}
+}
+
+// listen opens the listener of the http server on the address, wrapped in TLS if configured
+func (d *Discovery) listen(address string) (net.Listener, error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for discovery requests: %v", err)
+	}
+	if d.serverConfig.TLSConfig != nil {
+		l = tls.NewListener(l, d.serverConfig.TLSConfig)
+	}
+	return l, nil
+}
+
+// interfaceAddrs returns the interface with the specified name and its addresses, IPv4 first, or the addresses of
+// all the interfaces and a nil interface if name is empty
+func interfaceAddrs(name string) (*net.Interface, []net.IP, error) {
+	var ifaces []net.Interface
+	var iface *net.Interface
+	if name == "" {
+		all, err := net.Interfaces()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list the network interfaces: %v", err)
+		}
+		ifaces = all
+	} else {
+		i, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unknown interface %s: %v", name, err)
+		}
+		iface = i
+		ifaces = []net.Interface{*i}
+	}
+
+	var v4, v6 []net.IP
+	for _, i := range ifaces {
+		addrs, err := i.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			var ip net.IP
+			switch v := addr.(type) {
+			case *net.IPNet:
+				ip = v.IP
+			case *net.IPAddr:
+				ip = v.IP
+			}
+			if ip.To4() != nil {
+				v4 = append(v4, ip)
+			} else if ip != nil {
+				v6 = append(v6, ip)
+			}
+		}
+	}
+
+	ips := append(v4, v6...)
+	if iface != nil && len(ips) == 0 {
+		return nil, nil, fmt.Errorf("interface %s has no address", name)
+	}
+	return iface, ips, nil
+}
@@ -0,0 +1,189 @@
+// Package history reads the recently played list of a user, and reports the plays of this library to the event
+// service, so that they show up in the listening history of the user on all their devices.
+package history
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/analytics"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+// DefaultLimit is the number of recently played contexts returned when no limit is specified
+const DefaultLimit = 50
+
+// eventsUri is the mercury endpoint of the event service
+const eventsUri = "hm://event-service/v1/events"
+
+// eventTrackPlayed is the type and the version of the event reporting a play
+var eventTrackPlayed = [2]string{"372", "1"}
+
+// PlayedContext is a context (album, playlist, artist, show, ...) the user played recently
+type PlayedContext struct {
+	Uri        string
+	LastPlayed time.Time
+	// LastTrackUri is the last track played in the context, if known
+	LastTrackUri string
+}
+
+// Interval is a part of a track which was played, between two positions
+type Interval struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Play is the playback of a track, reported to the event service
+type Play struct {
+	// TrackUri is the spotify:track:... or spotify:episode:... uri of the item played
+	TrackUri string
+	// PlaybackId identifies the playback, a random one is generated if empty
+	PlaybackId string
+	// Intervals are the parts of the track which were played
+	Intervals []Interval
+}
+
+// Client reads the recently played list of a user and reports the plays, through mercury
+type Client struct {
+	mercury  *mercury.Client
+	username string
+}
+
+// NewClient creates a client acting as the specified user
+func NewClient(m *mercury.Client, username string) *Client {
+	return &Client{
+		mercury:  m,
+		username: username,
+	}
+}
+
+// RecentlyPlayed fetches the contexts the user played most recently on any device, the most recent first, up to
+// limit or DefaultLimit if limit is 0
+func (c *Client) RecentlyPlayed(limit int) ([]PlayedContext, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	uri := fmt.Sprintf("hm://recently-played/v2/user/%s/recently-played?format=json&offset=0&limit=%d"+
+		"&filter=default,collection-new-episodes", url.PathEscape(c.username), limit)
+
+	// The list changes with every play, it is never cached
+	res, err := c.mercury.Do(mercury.Request{Method: "GET", Uri: uri, Payload: [][]byte{}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently played: %v", err)
+	}
+	return parseRecentlyPlayed(res.CombinePayload())
+}
+
+// ReportPlay sends the play of a track to the event service, adding it to the listening history of the user
+func (c *Client) ReportPlay(play Play) error {
+	if play.PlaybackId == "" {
+		play.PlaybackId = NewPlaybackId()
+	}
+	if err := c.sendEvent(trackPlayedEvent(play)); err != nil {
+		return fmt.Errorf("failed to report the play of %s: %v", play.TrackUri, err)
+	}
+	return nil
+}
+
+// sendEvent sends an event to the event service
+func (c *Client) sendEvent(event []byte) error {
+	_, err := c.mercury.Do(mercury.Request{
+		Method:  "POST",
+		Uri:     eventsUri,
+		Payload: [][]byte{event},
+	})
+	return err
+}
+
+// ReportRecords returns an analytics record callback reporting the plays of the tracks, to be registered with
+// analytics.Recorder.OnRecord. The plays are reported in the background, the failures being passed to onError if
+// not nil.
+func (c *Client) ReportRecords(onError func(err error)) analytics.RecordCallback {
+	return func(record analytics.Record) {
+		play, ok := PlayOfRecord(record)
+		if !ok {
+			return
+		}
+		go func() {
+			if err := c.ReportPlay(play); err != nil && onError != nil {
+				onError(err)
+			}
+		}()
+	}
+}
+
+// PlayOfRecord returns the play of an analytics record, from its start for the time it played, and false if the
+// record isn't the playback of a track
+func PlayOfRecord(record analytics.Record) (Play, bool) {
+	if record.TrackId == "" || record.MsPlayed <= 0 {
+		return Play{}, false
+	}
+	return Play{
+		TrackUri:  "spotify:track:" + record.TrackId,
+		Intervals: []Interval{{End: time.Duration(record.MsPlayed) * time.Millisecond}},
+	}, true
+}
+
+// NewPlaybackId returns a random playback id
+func NewPlaybackId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// recentlyPlayed is the JSON response of the recently-played endpoint
+type recentlyPlayed struct {
+	PlayContexts []struct {
+		Uri                string `json:"uri"`
+		LastPlayedTime     int64  `json:"lastPlayedTime"`
+		LastPlayedTrackUri string `json:"lastPlayedTrackUri"`
+	} `json:"playContexts"`
+}
+
+// parseRecentlyPlayed returns the contexts of a recently-played response
+func parseRecentlyPlayed(body []byte) ([]PlayedContext, error) {
+	var res recentlyPlayed
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("invalid recently played: %v", err)
+	}
+	contexts := make([]PlayedContext, len(res.PlayContexts))
+	for i, context := range res.PlayContexts {
+		contexts[i] = PlayedContext{
+			Uri:          context.Uri,
+			LastPlayed:   time.Unix(0, context.LastPlayedTime*int64(time.Millisecond)),
+			LastTrackUri: context.LastPlayedTrackUri,
+		}
+	}
+	return contexts, nil
+}
+
+// trackPlayedEvent returns the event reporting a play
+func trackPlayedEvent(play Play) []byte {
+	intervals := make([]string, len(play.Intervals))
+	for i, interval := range play.Intervals {
+		intervals[i] = "[" + strconv.FormatInt(interval.Start.Milliseconds(), 10) + "," +
+			strconv.FormatInt(interval.End.Milliseconds(), 10) + "]"
+	}
+	return buildEvent(eventTrackPlayed, play.PlaybackId, play.TrackUri, "0", "["+strings.Join(intervals, ",")+"]")
+}
+
+// buildEvent returns the payload of an event of the event service: its type, its version and its fields, separated
+// by tabs
+func buildEvent(eventType [2]string, fields ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString(eventType[0])
+	b.WriteByte('\t')
+	b.WriteString(eventType[1])
+	for _, field := range fields {
+		b.WriteByte('\t')
+		b.WriteString(field)
+	}
+	return b.Bytes()
+}
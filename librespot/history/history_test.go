@@ -0,0 +1,50 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/analytics"
+)
+
+func TestParseRecentlyPlayed(t *testing.T) {
+	body := []byte(`{"playContexts":[{"uri":"spotify:album:a","lastPlayedTime":1600000000123,
+		"lastPlayedTrackUri":"spotify:track:t"},{"uri":"spotify:playlist:p","lastPlayedTime":1500000000000}]}`)
+	contexts, err := parseRecentlyPlayed(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contexts) != 2 {
+		t.Fatalf("Got contexts %v", contexts)
+	}
+	if c := contexts[0]; c.Uri != "spotify:album:a" || c.LastTrackUri != "spotify:track:t" ||
+		!c.LastPlayed.Equal(time.Unix(1600000000, 123000000)) {
+		t.Errorf("Got context %+v", c)
+	}
+	if c := contexts[1]; c.Uri != "spotify:playlist:p" || c.LastTrackUri != "" {
+		t.Errorf("Got context %+v", c)
+	}
+
+	if _, err := parseRecentlyPlayed([]byte("<html>")); err == nil {
+		t.Errorf("Invalid responses should fail")
+	}
+}
+
+func TestTrackPlayedEvent(t *testing.T) {
+	play, ok := PlayOfRecord(analytics.Record{TrackId: "4uLU6hMCjMI75M1A2tKUQC", MsPlayed: 183500})
+	if !ok {
+		t.Fatal("The record should be a play")
+	}
+	play.PlaybackId = "0123"
+	event := string(trackPlayedEvent(play))
+	if expected := "372\t1\t0123\tspotify:track:4uLU6hMCjMI75M1A2tKUQC\t0\t[[0,183500]]"; event != expected {
+		t.Errorf("Got event %q, expected %q", event, expected)
+	}
+
+	if _, ok := PlayOfRecord(analytics.Record{FileId: "00", MsPlayed: 1000}); ok {
+		t.Errorf("Records without track are not plays")
+	}
+	if _, ok := PlayOfRecord(analytics.Record{TrackId: "4uLU6hMCjMI75M1A2tKUQC"}); ok {
+		t.Errorf("Records which didn't play are not plays")
+	}
+}
@@ -0,0 +1,366 @@
+package history
+
+import (
+	"encoding/hex"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// DefaultProgressInterval is how often the progress of the playing track is reported by default
+const DefaultProgressInterval = 30 * time.Second
+
+// queueSize is the number of reports waiting for the reporters, the next ones being dropped
+const queueSize = 64
+
+// eventNewPlaybackId is the type and the version of the event announcing a playback
+var eventNewPlaybackId = [2]string{"558", "1"}
+
+// EndReason is why the playback of a track ended, named like the reasons of the Spotify apps
+type EndReason string
+
+const (
+	// EndTrackDone means the track played until its end
+	EndTrackDone EndReason = "trackdone"
+	// EndSkipped means another track started before the end of this one
+	EndSkipped EndReason = "fwdbtn"
+	// EndError means the track failed to play
+	EndError EndReason = "trackerror"
+	// EndStopped means the reporting stopped, e.g. because the session was closed
+	EndStopped EndReason = "endplay"
+)
+
+// Playback is the playback of a track, as reported to the reporters
+type Playback struct {
+	// PlaybackId identifies the playback
+	PlaybackId string
+	// TrackId is the base62 id of the track, empty if unknown
+	TrackId    string
+	FileId     string
+	ContextUri string
+	Started    time.Time
+	// Played is the time the track played so far, buffering excluded
+	Played time.Duration
+	// Err is the error which ended the playback, for EndError
+	Err error
+}
+
+// TrackUri returns the spotify:track:... uri of the track, or "" if unknown
+func (p Playback) TrackUri() string {
+	if p.TrackId == "" {
+		return ""
+	}
+	return "spotify:track:" + p.TrackId
+}
+
+// Reporter is notified of the playback of the tracks, e.g. to report them to the event service or to scrobble them
+// to Last.fm. Its methods are called in order, from the goroutine of the pipeline.
+type Reporter interface {
+	TrackStarted(p Playback)
+	// TrackProgress is called periodically while the track plays
+	TrackProgress(p Playback)
+	TrackEnded(p Playback, reason EndReason)
+}
+
+// ReporterFuncs is a Reporter calling its functions, those which are nil being skipped
+type ReporterFuncs struct {
+	Started  func(p Playback)
+	Progress func(p Playback)
+	Ended    func(p Playback, reason EndReason)
+}
+
+func (r ReporterFuncs) TrackStarted(p Playback) {
+	if r.Started != nil {
+		r.Started(p)
+	}
+}
+
+func (r ReporterFuncs) TrackProgress(p Playback) {
+	if r.Progress != nil {
+		r.Progress(p)
+	}
+}
+
+func (r ReporterFuncs) TrackEnded(p Playback, reason EndReason) {
+	if r.Ended != nil {
+		r.Ended(p, reason)
+	}
+}
+
+// report is a notification waiting for the reporters
+type report struct {
+	playback Playback
+	ended    bool
+	reason   EndReason
+}
+
+// Pipeline turns the events of a player into the playback reports of its reporters. The reporters are called from
+// the goroutine of the pipeline, so that they can block without delaying the playback.
+type Pipeline struct {
+	// ProgressInterval is how often the progress of the playing track is reported, DefaultProgressInterval if zero.
+	// It must be set before Start.
+	ProgressInterval time.Duration
+
+	lock      sync.Mutex
+	reporters []Reporter
+	current   *Playback
+	// resumed is the time the current track started playing since it last buffered, zero while buffering
+	resumed time.Time
+	queue   chan report
+	stop    chan struct{}
+	log     *log.Logger
+}
+
+// NewPipeline creates a pipeline reporting to the reporters. The reports are only sent once it is started.
+func NewPipeline(reporters ...Reporter) *Pipeline {
+	return &Pipeline{reporters: reporters}
+}
+
+// SetLogger sets the logger receiving the messages of the pipeline, the standard logger being used if nil. It must be
+// called before Start.
+func (p *Pipeline) SetLogger(logger *log.Logger) {
+	p.log = logger
+}
+
+// logger returns the logger of the pipeline, see SetLogger
+func (p *Pipeline) logger() *log.Logger {
+	if p.log == nil {
+		return log.Default()
+	}
+	return p.log
+}
+
+// Add registers another reporter
+func (p *Pipeline) Add(reporter Reporter) {
+	p.lock.Lock()
+	p.reporters = append(p.reporters, reporter)
+	p.lock.Unlock()
+}
+
+// Listener returns a player event listener feeding the pipeline, to be registered with Player.OnEvent
+func (p *Pipeline) Listener() player.EventListener {
+	return p.Handle
+}
+
+// Handle updates the playback with a player event, queueing the reports
+func (p *Pipeline) Handle(event player.Event) {
+	fileId := hex.EncodeToString(event.FileId)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	current := p.current != nil && p.current.FileId == fileId
+	switch event.Type {
+	case player.EventTrackStart:
+		if p.current != nil {
+			// Only one track plays at a time
+			p.end(event.Time, EndSkipped, nil)
+		}
+		playback := &Playback{
+			PlaybackId: NewPlaybackId(),
+			FileId:     fileId,
+			ContextUri: event.Origin.ContextUri,
+			Started:    event.Time,
+		}
+		if event.TrackId != nil {
+			playback.TrackId = utils.ConvertTo62(event.TrackId)
+		}
+		p.current, p.resumed = playback, event.Time
+		p.enqueue(report{playback: *playback})
+
+	case player.EventBufferingStart:
+		if current && !p.resumed.IsZero() {
+			p.current.Played += event.Time.Sub(p.resumed)
+			p.resumed = time.Time{}
+		}
+
+	case player.EventBufferingEnd:
+		if current && p.resumed.IsZero() {
+			p.resumed = event.Time
+		}
+
+	case player.EventTrackEnd:
+		if current {
+			p.end(event.Time, EndTrackDone, nil)
+		}
+
+	case player.EventError:
+		if current {
+			p.end(event.Time, EndError, event.Err)
+		}
+	}
+}
+
+// Start starts reporting in the background, until Stop is called
+func (p *Pipeline) Start() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.stop != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.queue = make(chan report, queueSize)
+
+	interval := p.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	go p.run(p.stop, p.queue, interval)
+}
+
+// Stop ends the playback in progress with EndStopped, and stops reporting once its end is reported
+func (p *Pipeline) Stop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.stop == nil {
+		return
+	}
+	if p.current != nil {
+		p.end(time.Now(), EndStopped, nil)
+	}
+	close(p.stop)
+	p.stop, p.queue = nil, nil
+}
+
+// end queues the end of the current playback, it must be called with the lock held
+func (p *Pipeline) end(now time.Time, reason EndReason, err error) {
+	playback := *p.current
+	if !p.resumed.IsZero() {
+		playback.Played += now.Sub(p.resumed)
+	}
+	if playback.Played < 0 {
+		playback.Played = 0
+	}
+	playback.Err = err
+	p.current, p.resumed = nil, time.Time{}
+	p.enqueue(report{playback: playback, ended: true, reason: reason})
+}
+
+// enqueue queues a report, it must be called with the lock held. The report is dropped if the pipeline isn't
+// started, or if the reporters are too slow.
+func (p *Pipeline) enqueue(r report) {
+	if p.queue == nil {
+		return
+	}
+	select {
+	case p.queue <- r:
+	default:
+		p.logger().Printf("Playback reporters too slow, dropped the report of %s", r.playback.PlaybackId)
+	}
+}
+
+func (p *Pipeline) run(stop chan struct{}, queue chan report, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-queue:
+			p.dispatch(r)
+		case now := <-ticker.C:
+			p.progress(now)
+		case <-stop:
+			// Deliver the reports queued before stopping, e.g. the end of the last track
+			for {
+				select {
+				case r := <-queue:
+					p.dispatch(r)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// progress reports the progress of the playing track
+func (p *Pipeline) progress(now time.Time) {
+	p.lock.Lock()
+	if p.current == nil || p.resumed.IsZero() {
+		p.lock.Unlock()
+		return
+	}
+	playback := *p.current
+	playback.Played += now.Sub(p.resumed)
+	reporters := append([]Reporter{}, p.reporters...)
+	p.lock.Unlock()
+
+	for _, reporter := range reporters {
+		reporter.TrackProgress(playback)
+	}
+}
+
+func (p *Pipeline) dispatch(r report) {
+	p.lock.Lock()
+	reporters := append([]Reporter{}, p.reporters...)
+	p.lock.Unlock()
+
+	for _, reporter := range reporters {
+		if r.ended {
+			reporter.TrackEnded(r.playback, r.reason)
+		} else {
+			reporter.TrackStarted(r.playback)
+		}
+	}
+}
+
+// EventServiceReporter reports the playbacks to the event service, so that the plays count toward the listening
+// history and the statistics of the user
+type EventServiceReporter struct {
+	client    *Client
+	sessionId string
+	log       *log.Logger
+}
+
+// NewEventServiceReporter creates a reporter sending its events through mercury
+func NewEventServiceReporter(m *mercury.Client) *EventServiceReporter {
+	return &EventServiceReporter{
+		client:    &Client{mercury: m},
+		sessionId: NewPlaybackId(),
+	}
+}
+
+// SetLogger sets the logger receiving the failures of the reports, the standard logger being used if nil. It must be
+// called before reporting.
+func (r *EventServiceReporter) SetLogger(logger *log.Logger) {
+	r.log = logger
+}
+
+// logger returns the logger of the reporter, see SetLogger
+func (r *EventServiceReporter) logger() *log.Logger {
+	if r.log == nil {
+		return log.Default()
+	}
+	return r.log
+}
+
+// TrackStarted announces the playback
+func (r *EventServiceReporter) TrackStarted(p Playback) {
+	event := buildEvent(eventNewPlaybackId, p.PlaybackId, r.sessionId,
+		strconv.FormatInt(p.Started.UnixNano()/int64(time.Millisecond), 10))
+	if err := r.client.sendEvent(event); err != nil {
+		r.logger().Printf("Failed to report the playback of %s: %v", p.TrackUri(), err)
+	}
+}
+
+// TrackProgress does nothing, the event service is only told about the plays once they end
+func (r *EventServiceReporter) TrackProgress(p Playback) {}
+
+// TrackEnded reports the play of the track, unless it didn't play at all
+func (r *EventServiceReporter) TrackEnded(p Playback, reason EndReason) {
+	if p.TrackId == "" || p.Played <= 0 {
+		return
+	}
+	err := r.client.ReportPlay(Play{
+		TrackUri:   p.TrackUri(),
+		PlaybackId: p.PlaybackId,
+		Intervals:  []Interval{{End: p.Played}},
+	})
+	if err != nil {
+		r.logger().Println(err)
+	}
+}
@@ -0,0 +1,94 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+type reported struct {
+	event    string
+	playback Playback
+	reason   EndReason
+}
+
+func TestPipeline(t *testing.T) {
+	reports := make(chan reported, 16)
+	pipeline := NewPipeline(ReporterFuncs{
+		Started: func(p Playback) { reports <- reported{"started", p, ""} },
+		Ended:   func(p Playback, reason EndReason) { reports <- reported{"ended", p, reason} },
+	})
+	pipeline.Add(ReporterFuncs{
+		Progress: func(p Playback) { reports <- reported{"progress", p, ""} },
+	})
+	pipeline.ProgressInterval = time.Hour
+	pipeline.Start()
+
+	start := time.Unix(1000, 0)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+	first, second, third := []byte{0x1}, []byte{0x2}, []byte{0x3}
+	origin := player.PlayOrigin{ContextUri: "spotify:album:a"}
+
+	pipeline.Handle(player.Event{Type: player.EventTrackStart, Time: at(0), TrackId: first, FileId: first,
+		Origin: origin})
+	pipeline.Handle(player.Event{Type: player.EventBufferingStart, Time: at(10), FileId: first})
+	pipeline.Handle(player.Event{Type: player.EventBufferingEnd, Time: at(15), FileId: first})
+	pipeline.progress(at(20))
+	pipeline.Handle(player.Event{Type: player.EventTrackEnd, Time: at(180), FileId: first})
+
+	pipeline.Handle(player.Event{Type: player.EventTrackStart, Time: at(200), TrackId: second, FileId: second})
+	pipeline.Handle(player.Event{Type: player.EventTrackStart, Time: at(230), TrackId: third, FileId: third})
+	pipeline.Handle(player.Event{Type: player.EventError, Time: at(240), FileId: third, Err: errors.New("failed")})
+	pipeline.Handle(player.Event{Type: player.EventTrackStart, Time: at(250), TrackId: first, FileId: first})
+	pipeline.Stop()
+
+	expected := []struct {
+		event  string
+		played time.Duration
+		reason EndReason
+	}{
+		{"progress", 15 * time.Second, ""},
+		{"started", 0, ""},
+		{"ended", 175 * time.Second, EndTrackDone},
+		{"started", 0, ""},
+		{"ended", 30 * time.Second, EndSkipped},
+		{"started", 0, ""},
+		{"ended", 10 * time.Second, EndError},
+		{"started", 0, ""},
+		{"ended", 0, EndStopped},
+	}
+	var got []reported
+	for i := range expected {
+		select {
+		case r := <-reports:
+			got = append(got, r)
+		case <-time.After(time.Second):
+			t.Fatalf("Got %d reports, expected %d", i, len(expected))
+		}
+	}
+
+	// The progress is reported synchronously, before the queued reports. The last track is stopped now, so its
+	// played time isn't checked.
+	for i, e := range expected {
+		r := got[i]
+		checkPlayed := e.event != "started" && e.reason != EndStopped
+		if r.event != e.event || r.reason != e.reason || (checkPlayed && r.playback.Played != e.played) {
+			t.Errorf("Report %d: got %s %v after %v, expected %s %v after %v", i, r.event, r.reason,
+				r.playback.Played, e.event, e.reason, e.played)
+		}
+	}
+	if p := got[1].playback; p.TrackUri() != "spotify:track:"+p.TrackId || p.TrackId == "" ||
+		p.ContextUri != "spotify:album:a" || p.PlaybackId == "" || !p.Started.Equal(at(0)) {
+		t.Errorf("Got playback %+v", p)
+	}
+	if got[2].playback.PlaybackId != got[1].playback.PlaybackId {
+		t.Errorf("The end of a playback should have the id of its start")
+	}
+	if got[6].playback.Err == nil {
+		t.Errorf("The error should be reported")
+	}
+}
@@ -0,0 +1,223 @@
+// Package ids parses and converts the identifiers of the Spotify items: URIs (spotify:track:...), open.spotify.com
+// URLs, base62 ids and hex GIDs.
+package ids
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// InvalidIdError is returned when parsing a malformed identifier
+type InvalidIdError struct {
+	Id     string
+	Reason string
+}
+
+func (e *InvalidIdError) Error() string {
+	return fmt.Sprintf("invalid spotify id %q: %s", e.Id, e.Reason)
+}
+
+func invalid(id string, format string, args ...interface{}) error {
+	return &InvalidIdError{Id: id, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Kind is the type of item an Id refers to, as it appears in its URI
+type Kind string
+
+const (
+	KindTrack    Kind = "track"
+	KindEpisode  Kind = "episode"
+	KindAlbum    Kind = "album"
+	KindArtist   Kind = "artist"
+	KindShow     Kind = "show"
+	KindPlaylist Kind = "playlist"
+)
+
+// knownKinds are the kinds accepted in URIs and URLs
+var knownKinds = map[Kind]bool{
+	KindTrack:    true,
+	KindEpisode:  true,
+	KindAlbum:    true,
+	KindArtist:   true,
+	KindShow:     true,
+	KindPlaylist: true,
+}
+
+// Base62Length is the length of a base62 id, and HexLength the one of a hex GID
+const (
+	Base62Length = 22
+	HexLength    = 32
+)
+
+// Id identifies a Spotify item. The Kind is empty for the ids parsed from a raw base62 id or GID. Ids are
+// comparable, and can be used as map keys.
+type Id struct {
+	Kind Kind
+	Gid  [16]byte
+}
+
+// maxGid is the largest value of a 128 bits GID, beyond which a base62 id is invalid
+var maxGid = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// Parse parses a URI (spotify:track:<id>, or spotify:user:<name>:playlist:<id>), an open.spotify.com URL, a base62
+// id or a hex GID
+func Parse(s string) (Id, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "spotify:"):
+		return parseUri(s)
+	case strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "open.spotify.com/"):
+		return parseUrl(s)
+	case len(s) == HexLength:
+		return FromHex("", s)
+	default:
+		return FromBase62("", s)
+	}
+}
+
+// ParseKind is like Parse, but also checks that the item has the kind. The kind is set for raw base62 ids and GIDs.
+func ParseKind(kind Kind, s string) (Id, error) {
+	id, err := Parse(s)
+	if err != nil {
+		return Id{}, err
+	}
+	if id.Kind == "" {
+		id.Kind = kind
+	} else if id.Kind != kind {
+		return Id{}, invalid(s, "not a %s", kind)
+	}
+	return id, nil
+}
+
+// FromBase62 returns the id of the item of the kind with the base62 id
+func FromBase62(kind Kind, b62 string) (Id, error) {
+	if len(b62) != Base62Length {
+		return Id{}, invalid(b62, "not %d characters long", Base62Length)
+	}
+	n := new(big.Int)
+	base := big.NewInt(62)
+	for i := 0; i < len(b62); i++ {
+		digit := strings.IndexByte(alphabet, b62[i])
+		if digit < 0 {
+			return Id{}, invalid(b62, "not base62")
+		}
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	if n.Cmp(maxGid) > 0 {
+		return Id{}, invalid(b62, "larger than 128 bits")
+	}
+
+	id := Id{Kind: kind}
+	n.FillBytes(id.Gid[:])
+	return id, nil
+}
+
+// alphabet is the alphabet of the base62 ids, see utils.ConvertTo62
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// FromHex returns the id of the item of the kind with the hex GID
+func FromHex(kind Kind, gid string) (Id, error) {
+	if len(gid) != HexLength {
+		return Id{}, invalid(gid, "not %d characters long", HexLength)
+	}
+	raw, err := hex.DecodeString(gid)
+	if err != nil {
+		return Id{}, invalid(gid, "not hexadecimal")
+	}
+	return FromGid(kind, raw)
+}
+
+// FromGid returns the id of the item of the kind with the GID, as found in the metadata protobuf messages
+func FromGid(kind Kind, gid []byte) (Id, error) {
+	if len(gid) != 16 {
+		return Id{}, invalid(hex.EncodeToString(gid), "GID of %d bytes", len(gid))
+	}
+	id := Id{Kind: kind}
+	copy(id.Gid[:], gid)
+	return id, nil
+}
+
+// parseUri parses spotify:<kind>:<id> and spotify:user:<name>:<kind>:<id> URIs
+func parseUri(uri string) (Id, error) {
+	parts := strings.Split(uri, ":")
+	if len(parts) == 5 && parts[1] == "user" {
+		parts = append(parts[:1], parts[3:]...)
+	}
+	if len(parts) != 3 {
+		return Id{}, invalid(uri, "malformed uri")
+	}
+	return fromKindAndBase62(parts[1], parts[2], uri)
+}
+
+// parseUrl parses open.spotify.com URLs, e.g. https://open.spotify.com/intl-fr/track/<id>?si=...
+func parseUrl(s string) (Id, error) {
+	if strings.HasPrefix(s, "open.spotify.com/") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host != "open.spotify.com" {
+		return Id{}, invalid(s, "not an open.spotify.com url")
+	}
+
+	var parts []string
+	for _, part := range strings.Split(u.Path, "/") {
+		// The localized and embedded links insert a segment before the kind
+		if part != "" && part != "embed" && !strings.HasPrefix(part, "intl-") {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 4 && parts[0] == "user" {
+		parts = parts[2:]
+	}
+	if len(parts) != 2 {
+		return Id{}, invalid(s, "malformed url")
+	}
+	return fromKindAndBase62(parts[0], parts[1], s)
+}
+
+func fromKindAndBase62(kind string, b62 string, s string) (Id, error) {
+	if !knownKinds[Kind(kind)] {
+		return Id{}, invalid(s, "unknown kind %q", kind)
+	}
+	return FromBase62(Kind(kind), b62)
+}
+
+// Base62 returns the base62 id, as found in the URIs
+func (id Id) Base62() string {
+	return utils.ConvertTo62(id.Gid[:])
+}
+
+// Hex returns the hex GID, as used in the mercury metadata URIs
+func (id Id) Hex() string {
+	return hex.EncodeToString(id.Gid[:])
+}
+
+// Uri returns the URI of the item, e.g. spotify:track:<id>
+func (id Id) Uri() string {
+	return "spotify:" + string(id.Kind) + ":" + id.Base62()
+}
+
+// Url returns the link to the item on open.spotify.com
+func (id Id) Url() string {
+	return "https://open.spotify.com/" + string(id.Kind) + "/" + id.Base62()
+}
+
+// String returns the URI of the item, or its base62 id if its kind is unknown
+func (id Id) String() string {
+	if id.Kind == "" {
+		return id.Base62()
+	}
+	return id.Uri()
+}
+
+// Valid returns true if the string can be parsed by Parse
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
@@ -0,0 +1,87 @@
+package ids
+
+import (
+	"testing"
+)
+
+const (
+	testBase62 = "0065zxtT6XKaQww7cLne0h"
+	testHex    = "000d536535864e0f99761f9da900b1c1"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  Kind
+	}{
+		{"spotify:track:" + testBase62, KindTrack},
+		{"spotify:user:someone:playlist:" + testBase62, KindPlaylist},
+		{"https://open.spotify.com/album/" + testBase62 + "?si=abcdef", KindAlbum},
+		{"https://open.spotify.com/intl-fr/artist/" + testBase62, KindArtist},
+		{"https://open.spotify.com/embed/episode/" + testBase62, KindEpisode},
+		{"open.spotify.com/show/" + testBase62, KindShow},
+		{" " + testBase62 + "\n", ""},
+		{testHex, ""},
+	}
+	for _, test := range tests {
+		id, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", test.input, err)
+			continue
+		}
+		if id.Kind != test.kind || id.Hex() != testHex || id.Base62() != testBase62 {
+			t.Errorf("Bad id for %q: %v %s", test.input, id, id.Hex())
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"spotify:track",
+		"spotify:unknown:" + testBase62,
+		"spotify:track:" + testBase62[1:],
+		"spotify:track:" + testBase62[1:] + "-",
+		// Larger than 2^128
+		"spotify:track:zzzzzzzzzzzzzzzzzzzzzz",
+		"https://example.com/track/" + testBase62,
+		"https://open.spotify.com/track",
+		testHex[1:] + "x",
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parsed invalid id %q", input)
+		} else if _, ok := err.(*InvalidIdError); !ok {
+			t.Errorf("Bad error for %q: %v", input, err)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	id, err := ParseKind(KindTrack, testBase62)
+	if err != nil || id.Uri() != "spotify:track:"+testBase62 {
+		t.Errorf("Bad id %v: %v", id, err)
+	}
+	if _, err := ParseKind(KindTrack, "spotify:album:"+testBase62); err == nil {
+		t.Errorf("Album parsed as a track")
+	}
+}
+
+func TestConversions(t *testing.T) {
+	gid := []byte{0x00, 0x0d, 0x53, 0x65, 0x35, 0x86, 0x4e, 0x0f, 0x99, 0x76, 0x1f, 0x9d, 0xa9, 0x00, 0xb1, 0xc1}
+	id, err := FromGid(KindEpisode, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.String() != "spotify:episode:"+testBase62 || id.Url() != "https://open.spotify.com/episode/"+testBase62 {
+		t.Errorf("Bad conversions: %s %s", id, id.Url())
+	}
+	if parsed, _ := Parse(id.Url()); parsed != id {
+		t.Errorf("Ids not equal: %v %v", parsed, id)
+	}
+	if _, err := FromGid(KindTrack, gid[1:]); err == nil {
+		t.Errorf("Accepted a GID of 15 bytes")
+	}
+	if !Valid(testHex) || Valid("spotify:") {
+		t.Errorf("Bad validation")
+	}
+}
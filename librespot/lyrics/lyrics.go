@@ -0,0 +1,163 @@
+// Package lyrics follows the playback position through the lines of synced lyrics, so that displays only render the
+// line given by every event.
+package lyrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// Line is a line of lyrics
+type Line struct {
+	// Start is the position of the track the line is sung at, zero if the lyrics are not synced
+	Start time.Duration
+	Words string
+}
+
+// Lyrics are the lyrics of a track
+type Lyrics struct {
+	// Synced is set if the lines have start times
+	Synced bool
+	Lines  []Line
+	// Provider is the name of the provider of the lyrics, which should be credited when they are displayed
+	Provider string
+	// Language is the language code of the lyrics, e.g. "en"
+	Language string
+}
+
+// PositionFunc returns the current playback position of the track, and whether it is playing
+type PositionFunc func() (position time.Duration, playing bool)
+
+// StatePosition returns the playback position of a Spirc state, extrapolated from when it was measured
+func StatePosition(state *Spotify.State) (time.Duration, bool) {
+	position := time.Duration(state.GetPositionMs()) * time.Millisecond
+	if state.GetStatus() != Spotify.PlayStatus_kPlayStatusPlay {
+		return position, false
+	}
+	if measuredAt := state.GetPositionMeasuredAt(); measuredAt > 0 {
+		elapsed := time.Since(time.Unix(0, int64(measuredAt)*int64(time.Millisecond)))
+		if elapsed > 0 {
+			position += elapsed
+		}
+	}
+	return position, true
+}
+
+// Event tells that a line is now heard
+type Event struct {
+	// Index is the index of the line in the lyrics, -1 before the first line
+	Index int
+	Line  Line
+	// Position is the playback position heard when the event was emitted
+	Position time.Duration
+}
+
+// EventListener is called with the line heard, whenever it changes
+type EventListener func(event Event)
+
+// Sync emits an event whenever the line heard changes, following the playback position. It is rescheduled from
+// the position at every line, so it doesn't drift, but must be told of the seeks and pauses with Resync.
+type Sync struct {
+	lyrics   *Lyrics
+	position PositionFunc
+	// latency is the delay between the position and its audio being heard, e.g. the buffer of the audio sink
+	latency time.Duration
+
+	lock      sync.Mutex
+	current   int
+	timer     *time.Timer
+	stopped   bool
+	listeners []EventListener
+}
+
+// NewSync creates a Sync of the lines of lyrics following position. The events are delayed by latency, the delay
+// between the position and the audio being heard. Lyrics which are not synced emit no events.
+func NewSync(lyrics *Lyrics, position PositionFunc, latency time.Duration) *Sync {
+	return &Sync{
+		lyrics:   lyrics,
+		position: position,
+		latency:  latency,
+		current:  -1,
+		stopped:  true,
+	}
+}
+
+// OnLine registers a listener called whenever the line heard changes
+func (s *Sync) OnLine(listener EventListener) {
+	s.lock.Lock()
+	s.listeners = append(s.listeners, listener)
+	s.lock.Unlock()
+}
+
+func (s *Sync) emit(event Event) {
+	s.lock.Lock()
+	listeners := append([]EventListener{}, s.listeners...)
+	s.lock.Unlock()
+
+	for _, l := range listeners {
+		l(event)
+	}
+}
+
+// Start emits the line heard at the current position, and the following lines as the position reaches them
+func (s *Sync) Start() {
+	s.lock.Lock()
+	s.stopped = false
+	s.lock.Unlock()
+
+	s.Resync()
+}
+
+// Resync emits the line heard at the current position if it changed, and reschedules the following ones. It must be
+// called after a seek, a pause, or a resume.
+func (s *Sync) Resync() {
+	if !s.lyrics.Synced || len(s.lyrics.Lines) == 0 {
+		return
+	}
+	position, playing := s.position()
+	heard := position - s.latency
+
+	s.lock.Lock()
+	if s.stopped {
+		s.lock.Unlock()
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	lines := s.lyrics.Lines
+	index := sort.Search(len(lines), func(i int) bool { return lines[i].Start > heard }) - 1
+	changed := index != s.current
+	s.current = index
+	if playing && index+1 < len(lines) {
+		s.timer = time.AfterFunc(lines[index+1].Start-heard, s.Resync)
+	}
+	s.lock.Unlock()
+
+	if !changed {
+		return
+	}
+	event := Event{Index: index, Position: heard}
+	if index >= 0 {
+		event.Line = lines[index]
+	}
+	s.emit(event)
+}
+
+// Stop stops emitting the events, until Start is called again
+func (s *Sync) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stopped = true
+	s.current = -1
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
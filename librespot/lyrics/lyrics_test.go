@@ -0,0 +1,95 @@
+package lyrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePosition is a playback position advancing with the clock while playing
+type fakePosition struct {
+	lock    sync.Mutex
+	start   time.Time
+	offset  time.Duration
+	playing bool
+}
+
+func (p *fakePosition) position() (time.Duration, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.playing {
+		return p.offset, false
+	}
+	return p.offset + time.Since(p.start), true
+}
+
+func (p *fakePosition) seek(offset time.Duration, playing bool) {
+	p.lock.Lock()
+	p.start = time.Now()
+	p.offset = offset
+	p.playing = playing
+	p.lock.Unlock()
+}
+
+func nextEvent(t *testing.T, events chan Event) Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+		return Event{}
+	}
+}
+
+func TestSync(t *testing.T) {
+	lyrics := &Lyrics{Synced: true, Lines: []Line{
+		{Start: 20 * time.Millisecond, Words: "one"},
+		{Start: 60 * time.Millisecond, Words: "two"},
+		{Start: 100 * time.Millisecond, Words: "three"},
+	}}
+	position := &fakePosition{}
+	position.seek(0, true)
+
+	started := time.Now()
+	s := NewSync(lyrics, position.position, 10*time.Millisecond)
+	events := make(chan Event, 10)
+	s.OnLine(func(event Event) { events <- event })
+	s.Start()
+
+	for i, line := range lyrics.Lines {
+		event := nextEvent(t, events)
+		if event.Index != i || event.Line.Words != line.Words {
+			t.Fatalf("Bad event %v, want line %d", event, i)
+		}
+		// The lines are heard latency after their start
+		if elapsed := time.Since(started); elapsed < line.Start+10*time.Millisecond {
+			t.Errorf("Line %d emitted after %v", i, elapsed)
+		}
+		if event.Position < line.Start {
+			t.Errorf("Line %d emitted at %v", i, event.Position)
+		}
+	}
+
+	// A seek back emits the line at the new position
+	position.seek(70*time.Millisecond, false)
+	s.Resync()
+	if event := nextEvent(t, events); event.Index != 1 {
+		t.Errorf("Bad event after seek %v", event)
+	}
+
+	// Paused, no line follows
+	select {
+	case event := <-events:
+		t.Errorf("Unexpected event while paused %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.Stop()
+	position.seek(0, true)
+	s.Resync()
+	select {
+	case event := <-events:
+		t.Errorf("Unexpected event once stopped %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -1,6 +1,7 @@
 package mercury
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -8,31 +9,96 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/ops"
 )
 
-func (m *Client) mercuryGet(url string) []byte {
-	done := make(chan []byte)
-	go m.Request(Request{
+// Client provides the raw metadata used by metadata.Catalog
+var _ metadata.Source = (*Client)(nil)
+
+// Do sends the request and waits for its response. Responses with a non-2xx status code are returned as a
+// *RequestError, and requests without response before the request timeout as a *TimeoutError.
+func (m *Client) Do(req Request) (*Response, error) {
+	return m.DoContext(context.Background(), req)
+}
+
+// DoContext is similar to Do, but the request is abandoned when the context is done
+func (m *Client) DoContext(ctx context.Context, req Request) (*Response, error) {
+	opCtx, finish := m.currentRegistry().Start(ops.KindMercuryRequest, req.Method+" "+req.Uri)
+	defer finish()
+
+	type result struct {
+		res Response
+		err error
+	}
+	done := make(chan result, 1)
+	seqKey, err := m.start(req, func(res Response, err error) {
+		done <- result{res, err}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		m.cancel(seqKey, ctx.Err())
+		r = <-done
+	case <-opCtx.Done():
+		m.cancel(seqKey, opCtx.Err())
+		r = <-done
+	}
+	if _, ok := r.err.(*TimeoutError); ok {
+		return nil, r.err
+	} else if r.err != nil {
+		return nil, fmt.Errorf("mercury %s %s: %v", req.Method, req.Uri, r.err)
+	}
+
+	if r.res.StatusCode < 200 || r.res.StatusCode >= 300 {
+		return nil, &RequestError{
+			Method:     req.Method,
+			Uri:        req.Uri,
+			StatusCode: r.res.StatusCode,
+			RequestId:  r.res.RequestId,
+		}
+	}
+	return &r.res, nil
+}
+
+func (m *Client) mercuryGet(url string) ([]byte, error) {
+	cache := m.Cache()
+	if cached, ok := cache.Get(url); ok {
+		return cached.CombinePayload(), nil
+	}
+
+	result, err := m.Do(Request{
 		Method:  "GET",
 		Uri:     url,
 		Payload: [][]byte{},
-	}, func(res Response) {
-		done <- res.CombinePayload()
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	result := <-done
-	return result
+	cache.Put(url, *result)
+	return result.CombinePayload(), nil
 }
 
 func (m *Client) mercuryGetJson(url string, result interface{}) (err error) {
-	data := m.mercuryGet(url)
+	data, err := m.mercuryGet(url)
+	if err != nil {
+		return err
+	}
 	// fmt.Printf("%s", data)
 	err = json.Unmarshal(data, result)
 	return
 }
 
 func (m *Client) mercuryGetProto(url string, result proto.Message) (err error) {
-	data := m.mercuryGet(url)
+	data, err := m.mercuryGet(url)
+	if err != nil {
+		return err
+	}
 	// ioutil.WriteFile("/tmp/proto.blob", data, 0644)
 	err = proto.Unmarshal(data, result)
 	return
@@ -47,7 +113,7 @@ func (m *Client) GetRootPlaylist(username string) (*Spotify.SelectedListContent,
 }
 
 func (m *Client) GetPlaylist(id string) (*Spotify.SelectedListContent, error) {
-// 	uri := fmt.Sprintf("hm://playlist/%s", id) // old non-functional endpoint
+	// 	uri := fmt.Sprintf("hm://playlist/%s", id) // old non-functional endpoint
 	uri := fmt.Sprintf("hm://playlist/v2/playlist/%s", id)
 
 	result := &Spotify.SelectedListContent{}
@@ -64,10 +130,27 @@ func (m *Client) GetToken(clientId string, scopes string) (*metadata.Token, erro
 	return token, err
 }
 
+// GetUserProfile fetches the public profile (display name, avatar) of a user
+func (m *Client) GetUserProfile(username string) (*metadata.UserProfile, error) {
+	uri := fmt.Sprintf("hm://user-profile-view/v2/desktop/profile/%s", url.PathEscape(username))
+
+	profile := &metadata.UserProfile{}
+	err := m.mercuryGetJson(uri, profile)
+	profile.Username = username
+	return profile, err
+}
+
 func (m *Client) Search(search string, limit int, country string, username string) (*metadata.SearchResponse, error) {
+	return m.SearchWithOffset(search, limit, 0, country, username)
+}
+
+// SearchWithOffset searches the catalog, skipping the first offset results of every category
+func (m *Client) SearchWithOffset(search string, limit int, offset int, country string,
+	username string) (*metadata.SearchResponse, error) {
 	v := url.Values{}
 	v.Set("entityVersion", "2")
 	v.Set("limit", fmt.Sprintf("%d", limit))
+	v.Set("offset", fmt.Sprintf("%d", offset))
 	v.Set("imageSize", "large")
 	v.Set("catalogue", "")
 	v.Set("country", country)
@@ -83,9 +166,12 @@ func (m *Client) Search(search string, limit int, country string, username strin
 
 func (m *Client) Suggest(search string) (*metadata.SuggestResult, error) {
 	uri := "hm://searchview/km/v3/suggest/" + url.QueryEscape(search) + "?limit=3&intent=2516516747764520149&sequence=0&catalogue=&country=&locale=&platform=zelda&username="
-	data := m.mercuryGet(uri)
+	data, err := m.mercuryGet(uri)
+	if err != nil {
+		return nil, err
+	}
 
-	return parseSuggest(data)
+	return metadata.ParseSuggest(data)
 }
 
 func (m *Client) GetTrack(id string) (*Spotify.Track, error) {
@@ -122,28 +208,3 @@ func (m *Client) GetShow(id string) (*Spotify.Show, error) {
 	err := m.mercuryGetProto(uri, result)
 	return result, err
 }
-
-func parseSuggest(body []byte) (*metadata.SuggestResult, error) {
-	result := &metadata.SuggestResult{}
-	err := json.Unmarshal(body, result)
-	if err != nil {
-		fmt.Println("err", err)
-	}
-
-	for _, s := range result.Sections {
-		switch s.Typ {
-		case "top-results":
-			err = json.Unmarshal(s.RawItems, &result.TopHits)
-		case "album-results":
-			err = json.Unmarshal(s.RawItems, &result.Albums)
-		case "artist-results":
-			err = json.Unmarshal(s.RawItems, &result.Artists)
-		case "track-results":
-			err = json.Unmarshal(s.RawItems, &result.Tracks)
-		}
-		if err != nil {
-			return nil, err
-		}
-	}
-	return result, nil
-}
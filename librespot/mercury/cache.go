@@ -0,0 +1,182 @@
+package mercury
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hermes responses carry their caching policy either as a standard Cache-Control user field, or as the MC-TTL and
+// MC-Cache-Policy fields mirroring the MercuryReply proto.
+const (
+	headerCacheControl = "Cache-Control"
+	headerMCTTL        = "MC-TTL"
+	headerMCPolicy     = "MC-Cache-Policy"
+)
+
+// DefaultCacheEntries is the number of responses kept by a Cache without MaxEntries
+const DefaultCacheEntries = 1024
+
+type cacheEntry struct {
+	uri      string
+	response Response
+	expires  time.Time
+}
+
+// Cache holds successful GET responses for as long as the server allows them to be cached, as indicated by the
+// cache-control/ttl fields of the hermes response header. Once full, the expired responses are dropped, then the
+// least recently used ones.
+type Cache struct {
+	// DefaultTTL is used for responses that do not carry any caching information. Zero disables caching them.
+	DefaultTTL time.Duration
+	// MaxEntries is the number of responses kept, DefaultCacheEntries if zero
+	MaxEntries int
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	// lru holds the entries, the most recently used first
+	lru *list.List
+	now func() time.Time
+}
+
+// NewCache creates an empty response cache
+func NewCache() *Cache {
+	return &Cache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached response for the specified uri, if any and still fresh
+func (c *Cache) Get(uri string) (Response, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[uri]
+	if !ok {
+		return Response{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return Response{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.response, true
+}
+
+// Put stores the response, honoring the caching policy found in its header
+func (c *Cache) Put(uri string, response Response) {
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return
+	}
+
+	ttl, ok := response.CacheTTL()
+	if !ok {
+		ttl = c.DefaultTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	entry := &cacheEntry{uri: uri, response: response, expires: now.Add(ttl)}
+	if elem, ok := c.entries[uri]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[uri] = c.lru.PushFront(entry)
+
+	max := c.MaxEntries
+	if max <= 0 {
+		max = DefaultCacheEntries
+	}
+	if c.lru.Len() <= max {
+		return
+	}
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if !now.Before(elem.Value.(*cacheEntry).expires) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+	for c.lru.Len() > max {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove drops a cached response, it must be called with lock held
+func (c *Cache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).uri)
+}
+
+// Remove drops the cached response for the specified uri, e.g. after the resource was modified
+func (c *Cache) Remove(uri string) {
+	c.lock.Lock()
+	if elem, ok := c.entries[uri]; ok {
+		c.remove(elem)
+	}
+	c.lock.Unlock()
+}
+
+// Clear drops all the cached responses
+func (c *Cache) Clear() {
+	c.lock.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.lock.Unlock()
+}
+
+// CacheTTL returns how long the response may be cached, according to its header. The boolean is false if the
+// response does not carry any caching information.
+func (res *Response) CacheTTL() (time.Duration, bool) {
+	if cc, ok := res.UserFields[headerCacheControl]; ok {
+		return parseCacheControl(cc)
+	}
+
+	if policy, ok := res.UserFields[headerMCPolicy]; ok {
+		if strings.EqualFold(policy, "no") || strings.EqualFold(policy, "CACHE_NO") {
+			return 0, true
+		}
+	}
+
+	if ttl, ok := res.UserFields[headerMCTTL]; ok {
+		seconds, err := strconv.Atoi(strings.TrimSpace(ttl))
+		if err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	return 0, false
+}
+
+func parseCacheControl(value string) (time.Duration, bool) {
+	ttl := time.Duration(0)
+	found := false
+
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			return 0, true
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err == nil {
+				ttl = time.Duration(seconds) * time.Second
+				found = true
+			}
+		}
+	}
+
+	return ttl, found
+}
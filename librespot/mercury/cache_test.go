@@ -0,0 +1,72 @@
+package mercury
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheHonorsHeaders(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := NewCache()
+	cache.now = func() time.Time { return now }
+
+	cache.Put("hm://a", Response{StatusCode: 200, UserFields: map[string]string{"Cache-Control": "public, max-age=60"}})
+	cache.Put("hm://b", Response{StatusCode: 200, UserFields: map[string]string{"MC-TTL": "10"}})
+	cache.Put("hm://c", Response{StatusCode: 200, UserFields: map[string]string{"Cache-Control": "no-cache"}})
+	cache.Put("hm://d", Response{StatusCode: 200})
+	cache.Put("hm://e", Response{StatusCode: 404, UserFields: map[string]string{"MC-TTL": "10"}})
+
+	cached := map[string]bool{"hm://a": true, "hm://b": true, "hm://c": false, "hm://d": false, "hm://e": false}
+	for uri, expected := range cached {
+		if _, ok := cache.Get(uri); ok != expected {
+			t.Errorf("%s: expected cached=%v", uri, expected)
+		}
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, ok := cache.Get("hm://a"); !ok {
+		t.Errorf("hm://a should still be cached")
+	}
+	if _, ok := cache.Get("hm://b"); ok {
+		t.Errorf("hm://b should have expired")
+	}
+}
+
+func TestCacheDefaultTTL(t *testing.T) {
+	cache := NewCache()
+	cache.DefaultTTL = time.Minute
+
+	cache.Put("hm://d", Response{StatusCode: 200})
+	if _, ok := cache.Get("hm://d"); !ok {
+		t.Errorf("expected response to be cached with the default TTL")
+	}
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := NewCache()
+	cache.now = func() time.Time { return now }
+	cache.MaxEntries = 2
+	ttl := func(seconds string) Response {
+		return Response{StatusCode: 200, UserFields: map[string]string{"MC-TTL": seconds}}
+	}
+
+	// The least recently used response is evicted
+	cache.Put("hm://a", ttl("10"))
+	cache.Put("hm://b", ttl("60"))
+	cache.Get("hm://a")
+	cache.Put("hm://c", ttl("60"))
+	if _, ok := cache.Get("hm://b"); ok {
+		t.Errorf("hm://b should have been evicted")
+	}
+
+	// The expired responses are evicted first
+	now = now.Add(30 * time.Second)
+	cache.Put("hm://d", ttl("60"))
+	if _, ok := cache.Get("hm://c"); !ok {
+		t.Errorf("hm://c should still be cached")
+	}
+	if len(cache.entries) != 2 || cache.lru.Len() != 2 {
+		t.Errorf("%d entries cached, expected 2", len(cache.entries))
+	}
+}
@@ -0,0 +1,315 @@
+package mercury
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxInFlight is the number of requests sent without waiting for their responses by default. The requests
+// beyond are queued, so that bursts of requests don't congest the connection shared with the audio data.
+const DefaultMaxInFlight = 32
+
+// queuedRequest is a request waiting for a slot to be sent
+type queuedRequest struct {
+	seqKey   string
+	caller   string
+	send     func() error
+	queuedAt time.Time
+}
+
+// LimiterStats describes the activity of the request limiter of a client
+type LimiterStats struct {
+	// InFlight is the number of requests sent and waiting for their responses
+	InFlight int
+	// Queued is the number of requests waiting to be sent, and QueuedByCaller their number for every caller
+	Queued         int
+	QueuedByCaller map[string]int
+	// PeakQueued is the largest number of requests queued at once
+	PeakQueued int
+	// Delayed is the number of requests which were queued before being sent, and Wait the total time they waited
+	Delayed uint64
+	Wait    time.Duration
+}
+
+// requestLimiter limits the number of requests in flight and the rate at which they are sent, and queues the
+// others. The queued requests of the different callers are sent in turn, so that a caller bursting requests doesn't
+// delay the others; the requests of a caller are sent in order.
+type requestLimiter struct {
+	lock sync.Mutex
+	// max is the number of requests in flight allowed, zero meaning no limit
+	max      int
+	inFlight map[string]bool
+	// queues holds the queued requests of every caller, and callers the callers with queued requests, in turn order
+	queues  map[string][]queuedRequest
+	callers []string
+
+	// rate is the number of requests sent per second once the burst is exhausted, zero meaning no limit. tokens are
+	// the requests which can be sent right away, up to burst, refilled at rate.
+	rate   float64
+	burst  int
+	tokens float64
+	filled time.Time
+	// timer sends the queued requests once tokens are available, nil if not armed
+	timer *time.Timer
+	// send sends the requests taken from the queue by the timer
+	send func([]queuedRequest)
+	now  func() time.Time
+
+	stats LimiterStats
+}
+
+func newRequestLimiter(max int) *requestLimiter {
+	return &requestLimiter{
+		max:      max,
+		inFlight: make(map[string]bool),
+		queues:   make(map[string][]queuedRequest),
+		now:      time.Now,
+	}
+}
+
+// requestCaller returns the caller the requests of a uri are accounted to when no caller is specified, which is the
+// service it is sent to, e.g. hm://metadata
+func requestCaller(uri string) string {
+	service := uri
+	if i := strings.Index(uri, "://"); i >= 0 {
+		service = uri[i+3:]
+		if j := strings.Index(service, "/"); j >= 0 {
+			service = service[:j]
+		}
+		service = uri[:i+3] + service
+	}
+	return service
+}
+
+// acquire returns true if the request can be sent now, and otherwise queues it to be sent once a slot is released
+// and the rate allows it
+func (l *requestLimiter) acquire(seqKey string, caller string, send func() error) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.callers) == 0 && l.hasSlot() && l.takeToken() {
+		l.inFlight[seqKey] = true
+		return true
+	}
+
+	if len(l.queues[caller]) == 0 {
+		l.callers = append(l.callers, caller)
+	}
+	l.queues[caller] = append(l.queues[caller], queuedRequest{
+		seqKey:   seqKey,
+		caller:   caller,
+		send:     send,
+		queuedAt: l.now(),
+	})
+	l.stats.Delayed++
+	if queued := l.queuedLocked(); queued > l.stats.PeakQueued {
+		l.stats.PeakQueued = queued
+	}
+	l.armTimer()
+	return false
+}
+
+func (l *requestLimiter) hasSlot() bool {
+	return l.max <= 0 || len(l.inFlight) < l.max
+}
+
+// takeToken refills the bucket, and takes a token from it if one is available
+func (l *requestLimiter) takeToken() bool {
+	if l.rate <= 0 {
+		return true
+	}
+
+	now := l.now()
+	l.tokens += now.Sub(l.filled).Seconds() * l.rate
+	l.filled = now
+	if burst := float64(l.maxTokens()); l.tokens > burst {
+		l.tokens = burst
+	}
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+func (l *requestLimiter) maxTokens() int {
+	if l.burst < 1 {
+		return 1
+	}
+	return l.burst
+}
+
+// armTimer schedules the sending of the queued requests once a token is available, if the rate is what delays them
+func (l *requestLimiter) armTimer() {
+	if l.rate <= 0 || l.timer != nil || len(l.callers) == 0 || !l.hasSlot() {
+		return
+	}
+
+	wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	l.timer = time.AfterFunc(wait, func() {
+		l.lock.Lock()
+		l.timer = nil
+		ready := l.next()
+		send := l.send
+		l.lock.Unlock()
+
+		if send != nil {
+			send(ready)
+		}
+	})
+}
+
+// release frees the slot of a completed request, or removes it from the queue if it wasn't sent yet. It returns
+// the queued requests which can be sent in the slots available.
+func (l *requestLimiter) release(seqKey string) []queuedRequest {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.inFlight[seqKey] {
+		delete(l.inFlight, seqKey)
+	} else {
+		l.remove(seqKey)
+	}
+	return l.next()
+}
+
+// remove removes a request from the queue
+func (l *requestLimiter) remove(seqKey string) {
+	for caller, queue := range l.queues {
+		for i, q := range queue {
+			if q.seqKey != seqKey {
+				continue
+			}
+			l.queues[caller] = append(queue[:i], queue[i+1:]...)
+			if len(l.queues[caller]) == 0 {
+				delete(l.queues, caller)
+				for j, c := range l.callers {
+					if c == caller {
+						l.callers = append(l.callers[:j], l.callers[j+1:]...)
+						break
+					}
+				}
+			}
+			return
+		}
+	}
+}
+
+// next takes the queued requests fitting in the available slots and allowed by the rate, taking the requests of the
+// callers in turn
+func (l *requestLimiter) next() []queuedRequest {
+	var ready []queuedRequest
+	for len(l.callers) > 0 && l.hasSlot() && l.takeToken() {
+		caller := l.callers[0]
+		l.callers = l.callers[1:]
+		q := l.queues[caller][0]
+		if rest := l.queues[caller][1:]; len(rest) > 0 {
+			l.queues[caller] = rest
+			l.callers = append(l.callers, caller)
+		} else {
+			delete(l.queues, caller)
+		}
+
+		l.inFlight[q.seqKey] = true
+		l.stats.Wait += l.now().Sub(q.queuedAt)
+		ready = append(ready, q)
+	}
+	l.armTimer()
+	return ready
+}
+
+func (l *requestLimiter) setMax(max int) []queuedRequest {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.max = max
+	return l.next()
+}
+
+func (l *requestLimiter) setRate(rate float64, burst int) []queuedRequest {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.rate = rate
+	l.burst = burst
+	l.tokens = float64(l.maxTokens())
+	l.filled = l.now()
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	return l.next()
+}
+
+// drop empties the queue, e.g. before cancelling the queued requests so that they aren't sent meanwhile
+func (l *requestLimiter) drop() {
+	l.lock.Lock()
+	l.queues = make(map[string][]queuedRequest)
+	l.callers = nil
+	l.lock.Unlock()
+}
+
+func (l *requestLimiter) queued() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.queuedLocked()
+}
+
+func (l *requestLimiter) queuedLocked() int {
+	queued := 0
+	for _, queue := range l.queues {
+		queued += len(queue)
+	}
+	return queued
+}
+
+func (l *requestLimiter) currentStats() LimiterStats {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	stats := l.stats
+	stats.InFlight = len(l.inFlight)
+	stats.Queued = l.queuedLocked()
+	stats.QueuedByCaller = make(map[string]int, len(l.queues))
+	for caller, queue := range l.queues {
+		stats.QueuedByCaller[caller] = len(queue)
+	}
+	return stats
+}
+
+// SetMaxInFlight changes the number of requests sent without waiting for their responses, zero meaning no limit.
+// The requests beyond are queued, and sent in order as the responses come.
+func (m *Client) SetMaxInFlight(max int) {
+	m.sendQueued(m.limiter.setMax(max))
+}
+
+// SetRateLimit limits the rate at which the requests are sent with a token bucket: burst requests can be sent at
+// once, and then rate requests per second. The requests beyond are queued. A zero rate removes the limit, which is
+// the default.
+func (m *Client) SetRateLimit(rate float64, burst int) {
+	m.sendQueued(m.limiter.setRate(rate, burst))
+}
+
+// QueuedRequests returns the number of requests waiting to be sent, because MaxInFlight requests are already
+// waiting for their responses or the rate limit is reached
+func (m *Client) QueuedRequests() int {
+	return m.limiter.queued()
+}
+
+// LimiterStats returns the activity of the request limiter, e.g. to monitor the depth of its queue
+func (m *Client) LimiterStats() LimiterStats {
+	return m.limiter.currentStats()
+}
+
+// releaseSlot frees the slot of a completed request, and sends the next queued requests
+func (m *Client) releaseSlot(seqKey string) {
+	m.sendQueued(m.limiter.release(seqKey))
+}
+
+func (m *Client) sendQueued(ready []queuedRequest) {
+	for _, q := range ready {
+		if err := q.send(); err != nil {
+			m.cancel(q.seqKey, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package mercury
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	stream := &fakeStream{sendPackets: make(chan shanPacket, 10)}
+	client := CreateMercury(stream)
+	client.SetMaxInFlight(2)
+
+	statuses := make(chan int32, 5)
+	for i := 0; i < 5; i++ {
+		client.Request(Request{Method: "GET", Uri: "hm://metadata/4/track/0"}, func(res Response) {
+			statuses <- res.StatusCode
+		})
+	}
+	if len(stream.sendPackets) != 2 || client.QueuedRequests() != 3 || client.PendingRequests() != 5 {
+		t.Fatalf("%d requests sent and %d queued, want 2 and 3", len(stream.sendPackets), client.QueuedRequests())
+	}
+
+	// Every response lets a queued request be sent, in order
+	first := <-stream.sendPackets
+	client.Handle(connection.PacketMercuryReq, bytes.NewReader(headerPacket(requestSeq(first), "hm://x", 200)))
+	if status := <-statuses; status != 200 {
+		t.Errorf("Bad status %d", status)
+	}
+	second := <-stream.sendPackets
+	third := <-stream.sendPackets
+	if bytes.Compare(requestSeq(second), requestSeq(third)) >= 0 || client.QueuedRequests() != 2 {
+		t.Errorf("Queued request not sent in order")
+	}
+
+	// The queued requests are cancelled too
+	client.CancelPending()
+	for i := 0; i < 4; i++ {
+		if status := <-statuses; status != StatusCodeCancelled {
+			t.Errorf("Bad status %d", status)
+		}
+	}
+	if client.QueuedRequests() != 0 || client.PendingRequests() != 0 || len(stream.sendPackets) != 0 {
+		t.Errorf("Requests left after cancelling: %d queued, %d sent", client.QueuedRequests(),
+			len(stream.sendPackets))
+	}
+
+	// Raising the limit sends the queued requests
+	client.SetMaxInFlight(1)
+	for i := 0; i < 3; i++ {
+		client.Request(Request{Method: "GET", Uri: "hm://metadata/4/track/0"}, nil)
+	}
+	client.SetMaxInFlight(0)
+	if len(stream.sendPackets) != 3 || client.QueuedRequests() != 0 {
+		t.Errorf("%d requests sent after removing the limit", len(stream.sendPackets))
+	}
+}
+
+func TestLimiterFairness(t *testing.T) {
+	stream := &fakeStream{sendPackets: make(chan shanPacket, 10)}
+	client := CreateMercury(stream)
+	client.SetMaxInFlight(1)
+
+	// A burst of metadata requests doesn't delay the playlist request queued after it
+	for _, uri := range []string{"hm://metadata/1", "hm://metadata/2", "hm://metadata/3", "hm://playlist/1"} {
+		client.Request(Request{Method: "GET", Uri: uri}, nil)
+	}
+	stats := client.LimiterStats()
+	if stats.InFlight != 1 || stats.Queued != 3 || stats.QueuedByCaller["hm://metadata"] != 2 ||
+		stats.QueuedByCaller["hm://playlist"] != 1 || stats.PeakQueued != 3 || stats.Delayed != 3 {
+		t.Errorf("Bad stats %+v", stats)
+	}
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		packet := <-stream.sendPackets
+		order = append(order, requestUri(packet))
+		client.Handle(connection.PacketMercuryReq, bytes.NewReader(headerPacket(requestSeq(packet), "hm://x", 200)))
+	}
+	want := []string{"hm://metadata/1", "hm://metadata/2", "hm://playlist/1", "hm://metadata/3"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Requests sent in order %v, want %v", order, want)
+		}
+	}
+	if stats := client.LimiterStats(); stats.InFlight != 0 || stats.Queued != 0 {
+		t.Errorf("Requests left %+v", stats)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	stream := &fakeStream{sendPackets: make(chan shanPacket, 10)}
+	client := CreateMercury(stream)
+	var lock sync.Mutex
+	now := time.Unix(0, 0)
+	client.limiter.now = func() time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		return now
+	}
+	client.SetRateLimit(100, 2)
+
+	for i := 0; i < 4; i++ {
+		client.Request(Request{Method: "GET", Uri: "hm://metadata/4/track/0"}, nil)
+	}
+	if len(stream.sendPackets) != 2 || client.QueuedRequests() != 2 {
+		t.Fatalf("%d requests sent in the burst, want 2", len(stream.sendPackets))
+	}
+
+	// The timer sends a request once a token is available
+	lock.Lock()
+	now = now.Add(10 * time.Millisecond)
+	lock.Unlock()
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("Queued request not sent")
+	case <-waitPackets(stream, 3):
+	}
+	if client.QueuedRequests() != 1 {
+		t.Errorf("%d requests queued, want 1", client.QueuedRequests())
+	}
+
+	// Removing the limit sends the rest
+	client.SetRateLimit(0, 0)
+	if len(stream.sendPackets) != 4 || client.QueuedRequests() != 0 {
+		t.Errorf("%d requests sent after removing the limit", len(stream.sendPackets))
+	}
+}
+
+// waitPackets returns a channel closed once n packets were sent
+func waitPackets(stream *fakeStream, n int) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for len(stream.sendPackets) < n {
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,109 @@
+package mercury
+
+import (
+	"time"
+)
+
+// SilenceListener is called when a subscription stayed silent longer than expected and was requested again
+type SilenceListener func(uri string, silence time.Duration)
+
+// livenessCheck watches the events of a subscribed URI
+type livenessCheck struct {
+	interval time.Duration
+	// since is when the URI was last subscribed to, the silence being counted from then if no event came after
+	since time.Time
+	timer *time.Timer
+}
+
+// ExpectEvents declares that the events of the subscribed uri come at least every interval, e.g. the frames the
+// Connect devices of the user broadcast. Once the URI stays silent longer, the server is assumed to have lost the
+// subscription, which is requested again, and the SilenceListeners are called. A zero interval removes the check,
+// which is also removed once the URI is unsubscribed from.
+func (m *Client) ExpectEvents(uri string, interval time.Duration) {
+	m.subLock.Lock()
+	defer m.subLock.Unlock()
+
+	if check := m.liveness[uri]; check != nil {
+		check.timer.Stop()
+		delete(m.liveness, uri)
+	}
+	if interval <= 0 {
+		return
+	}
+
+	check := &livenessCheck{interval: interval, since: time.Now()}
+	check.timer = time.AfterFunc(interval, func() { m.checkLiveness(uri, check) })
+	m.liveness[uri] = check
+}
+
+// OnSilence registers a listener called when a subscription is requested again after a silence, see ExpectEvents
+func (m *Client) OnSilence(listener SilenceListener) {
+	m.subLock.Lock()
+	m.silenceListeners = append(m.silenceListeners, listener)
+	m.subLock.Unlock()
+}
+
+// checkLiveness subscribes to uri again if no event came during the interval of the check, and schedules the next
+// check
+func (m *Client) checkLiveness(uri string, check *livenessCheck) {
+	m.subLock.Lock()
+	if m.liveness[uri] != check {
+		// Replaced or removed meanwhile
+		m.subLock.Unlock()
+		return
+	}
+	if !m.subscribed[uri] {
+		delete(m.liveness, uri)
+		m.subLock.Unlock()
+		return
+	}
+
+	now := time.Now()
+	last := check.since
+	for _, u := range append([]string{uri}, m.aliases[uri]...) {
+		if t := m.lastEvent[u]; t.After(last) {
+			last = t
+		}
+	}
+	silence := now.Sub(last)
+	if silence < check.interval {
+		check.timer.Reset(check.interval - silence)
+		m.subLock.Unlock()
+		return
+	}
+	check.since = now
+	check.timer.Reset(check.interval)
+	listeners := append([]SilenceListener{}, m.silenceListeners...)
+	m.subLock.Unlock()
+
+	m.subscribe(uri, nil)
+	for _, l := range listeners {
+		l(uri, silence)
+	}
+}
+
+// recordEvent notes the time of an event received on uri, it must be called with subLock held
+func (m *Client) recordEvent(uri string) {
+	if len(m.liveness) > 0 {
+		m.lastEvent[uri] = time.Now()
+	}
+}
+
+// resetLiveness restarts the silence of the checks from now, e.g. after all the URIs were subscribed to again. It
+// must be called with subLock held.
+func (m *Client) resetLiveness() {
+	now := time.Now()
+	for _, check := range m.liveness {
+		check.since = now
+	}
+}
+
+// stopLiveness removes the checks of the URIs, it must be called with subLock held
+func (m *Client) stopLiveness(uris ...string) {
+	for _, uri := range uris {
+		if check := m.liveness[uri]; check != nil {
+			check.timer.Stop()
+			delete(m.liveness, uri)
+		}
+	}
+}
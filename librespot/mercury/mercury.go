@@ -7,8 +7,10 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/ops"
 	"io"
 	"sync"
+	"time"
 )
 
 // Mercury is the protocol implementation for Spotify Connect playback control and metadata fetching.It works as a
@@ -21,6 +23,10 @@ type Response struct {
 	Payload    [][]byte
 	StatusCode int32
 	SeqKey     string
+	UserFields map[string]string
+	// RequestId identifies the request for debugging purposes: it is the id sent by the server if any, or a
+	// locally generated correlation id
+	RequestId string
 }
 
 type Request struct {
@@ -28,97 +34,425 @@ type Request struct {
 	Uri         string
 	ContentType string
 	Payload     [][]byte
+	// Caller groups the requests queued by the limiter of the client, whose callers are served in turn. The service
+	// of the uri is used if empty, e.g. hm://metadata.
+	Caller string
 }
 
+// DefaultRequestTimeout is how long to wait for the response of a request by default
+const DefaultRequestTimeout = 30 * time.Second
+
+// Status codes of the responses passed to the callbacks of the requests which didn't complete
+const (
+	StatusCodeTimeout   = 408
+	StatusCodeCancelled = 499
+)
+
 type Callback func(Response)
 
+type pendingRequest struct {
+	handle func(res Response, err error)
+	timer  *time.Timer
+}
+
 type Pending struct {
 	parts   [][]byte
 	partial []byte
 }
 
+// Internal holds the state of the connection shared by the requests: the sequence counter, protected by seqLock, the
+// responses being received, protected by pendingLock, and the stream, protected by streamLock
 type Internal struct {
-	seqLock sync.Mutex
-	nextSeq uint32
-	pending map[string]Pending
-	stream  connection.PacketStream
+	seqLock     sync.Mutex
+	nextSeq     uint32
+	pending     map[string]Pending
+	pendingLock sync.Mutex
+	streamLock  sync.RWMutex
+	stream      connection.PacketStream
 }
 
+// Client sends mercury requests and dispatches the responses and events, which are passed to Handle by the goroutine
+// reading the packets of the connection. All its methods are safe for concurrent use: subLock protects the
+// subscriptions, and cbMu the callbacks of the pending requests and the settings.
 type Client struct {
-	subscriptions map[string][]chan Response
-	callbacks     map[string]Callback
-	internal      *Internal
-	cbMu          sync.Mutex
+	subLock       sync.Mutex
+	subscriptions map[string][]*subscriber
+	// subscribed holds the URIs explicitly subscribed to, so that they can be subscribed again after a reconnection
+	subscribed map[string]bool
+	// pendingSubs holds the SUB requests in flight of NewSubscription, which the concurrent subscriptions to the same
+	// URI wait for
+	pendingSubs map[string]*pendingSubscription
+	// aliases holds the other URIs the server delivers the events of a subscribed URI on
+	aliases map[string][]string
+	// eventListeners are called for every event, whether subscribed to or not
+	eventListeners []Callback
+	// liveness holds the URIs whose events are expected regularly, and lastEvent when the events were last received
+	liveness         map[string]*livenessCheck
+	lastEvent        map[string]time.Time
+	silenceListeners []SilenceListener
+
+	callbacks map[string]*pendingRequest
+	timeout   time.Duration
+	internal  *Internal
+	cbMu      sync.Mutex
+	cache     *Cache
+	traceHook TraceHook
+	registry  *ops.Registry
+	wireLog   *connection.WireLog
+	limiter   *requestLimiter
 }
 
 type Connection interface {
 	Subscribe(uri string, recv chan Response, cb Callback) error
 	Request(req Request, cb Callback) (err error)
-	Handle(cmd uint8, reader io.Reader) (err error)
+	Handle(cmd connection.PacketType, reader io.Reader) (err error)
 }
 
 // CreateMercury initializes a Connection for the specified session.
 func CreateMercury(stream connection.PacketStream) *Client {
 	client := &Client{
-		callbacks:     make(map[string]Callback),
-		subscriptions: make(map[string][]chan Response),
+		callbacks:     make(map[string]*pendingRequest),
+		subscriptions: make(map[string][]*subscriber),
+		subscribed:    make(map[string]bool),
+		pendingSubs:   make(map[string]*pendingSubscription),
+		aliases:       make(map[string][]string),
+		liveness:      make(map[string]*livenessCheck),
+		lastEvent:     make(map[string]time.Time),
 		internal: &Internal{
 			pending: make(map[string]Pending),
 			stream:  stream,
 		},
+		cache:   NewCache(),
+		timeout: DefaultRequestTimeout,
+		limiter: newRequestLimiter(DefaultMaxInFlight),
 	}
+	client.limiter.send = client.sendQueued
 	return client
 }
 
+// SetRegistry sets the registry tracking the requests in flight, allowing to cancel them
+func (m *Client) SetRegistry(registry *ops.Registry) {
+	m.cbMu.Lock()
+	m.registry = registry
+	m.cbMu.Unlock()
+}
+
+func (m *Client) currentRegistry() *ops.Registry {
+	m.cbMu.Lock()
+	defer m.cbMu.Unlock()
+	return m.registry
+}
+
+// SetCache replaces the cache holding the responses of GET requests, e.g. to share it between several clients
+func (m *Client) SetCache(cache *Cache) {
+	m.cbMu.Lock()
+	m.cache = cache
+	m.cbMu.Unlock()
+}
+
+// Cache returns the cache holding the responses of GET requests made through this client
+func (m *Client) Cache() *Cache {
+	m.cbMu.Lock()
+	defer m.cbMu.Unlock()
+	return m.cache
+}
+
 // Subscribe subscribes the specified receiving channel to the specified URI, and calls the callback function
 // whenever there's an event happening.
 func (m *Client) Subscribe(uri string, recv chan Response, cb Callback) error {
-	m.addChannelSubscriber(uri, recv)
+	m.subLock.Lock()
+	m.addSubscriber(uri, &subscriber{ch: recv})
+	m.subscribed[uri] = true
+	m.subLock.Unlock()
+	return m.subscribe(uri, cb)
+}
+
+// OnEvent registers a listener called for every event received, including the events of the URIs nobody
+// subscribed to
+func (m *Client) OnEvent(listener Callback) {
+	m.subLock.Lock()
+	m.eventListeners = append(m.eventListeners, listener)
+	m.subLock.Unlock()
+}
+
+func (m *Client) subscribe(uri string, cb Callback) error {
 	err := m.Request(Request{
 		Method: "SUB",
 		Uri:    uri,
 	}, func(response Response) {
-		for _, part := range response.Payload {
-			sub := &Spotify.Subscription{}
-			err := proto.Unmarshal(part, sub)
-			if err == nil && *sub.Uri != uri {
-				m.addChannelSubscriber(*sub.Uri, recv)
-			}
+		m.addAliases(uri, response)
+		if cb != nil {
+			cb(response)
 		}
-		cb(response)
 	})
 
 	return err
 }
 
-func (m *Client) addChannelSubscriber(uri string, recv chan Response) {
-	chList, ok := m.subscriptions[uri]
-	if !ok {
-		chList = make([]chan Response, 0)
+// addAliases registers the subscribers of uri to the other URIs listed in the response to its subscription
+func (m *Client) addAliases(uri string, response Response) {
+	m.subLock.Lock()
+	defer m.subLock.Unlock()
+
+	for _, part := range response.Payload {
+		sub := &Spotify.Subscription{}
+		err := proto.Unmarshal(part, sub)
+		if err != nil || sub.GetUri() == uri {
+			continue
+		}
+
+		alias := sub.GetUri()
+		known := false
+		for _, a := range m.aliases[uri] {
+			known = known || a == alias
+		}
+		if !known {
+			m.aliases[uri] = append(m.aliases[uri], alias)
+		}
+		for _, s := range m.subscriptions[uri] {
+			m.addSubscriber(alias, s)
+		}
+	}
+}
+
+// Resubscribe sends the subscription requests for all the URIs previously subscribed to again. It is used after
+// a reconnection, as the server forgets about the subscriptions of the previous connection. The subscribed
+// channels are kept and keep receiving the events.
+func (m *Client) Resubscribe() error {
+	m.subLock.Lock()
+	var uris []string
+	for uri := range m.subscribed {
+		if len(m.subscriptions[uri]) > 0 {
+			uris = append(uris, uri)
+		}
 	}
+	m.resetLiveness()
+	m.subLock.Unlock()
 
-	chList = append(chList, recv)
-	m.subscriptions[uri] = chList
+	for _, uri := range uris {
+		err := m.subscribe(uri, nil)
+		if err != nil {
+			return fmt.Errorf("failed to resubscribe to %s: %v", uri, err)
+		}
+	}
+	return nil
 }
 
+// ClearSubscriptions forgets all the subscriptions, so that they are not sent again by Resubscribe, e.g. when
+// another user logs in. The subscribed channels stop receiving events, and the channels of the Subscriptions are
+// closed.
+func (m *Client) ClearSubscriptions() {
+	m.subLock.Lock()
+	subscriptions := m.subscriptions
+	m.subscriptions = make(map[string][]*subscriber)
+	m.subscribed = make(map[string]bool)
+	m.aliases = make(map[string][]string)
+	for uri := range m.liveness {
+		m.stopLiveness(uri)
+	}
+	m.lastEvent = make(map[string]time.Time)
+	m.subLock.Unlock()
+
+	for _, subs := range subscriptions {
+		for _, s := range subs {
+			s.close()
+		}
+	}
+}
+
+// SetStream replaces the connection used to send requests, e.g. after a reconnection
+func (m *Client) SetStream(stream connection.PacketStream) {
+	m.internal.streamLock.Lock()
+	m.internal.stream = stream
+	m.internal.streamLock.Unlock()
+}
+
+// addSubscriber adds the subscriber to the ones of uri, it must be called with subLock held
+func (m *Client) addSubscriber(uri string, s *subscriber) {
+	for _, sub := range m.subscriptions[uri] {
+		if sub.ch == s.ch {
+			// Already subscribed, e.g. when subscribing again after a reconnection
+			return
+		}
+	}
+
+	m.subscriptions[uri] = append(m.subscriptions[uri], s)
+}
+
+// Request sends the request, and calls the callback with its response. If the request can't be sent, or doesn't get
+// a response before the request timeout, the callback is called with StatusCodeTimeout; if it is cancelled with
+// CancelPending, with StatusCodeCancelled.
 func (m *Client) Request(req Request, cb Callback) (err error) {
-	seq, err := m.internal.request(req)
+	_, err = m.start(req, func(res Response, err error) {
+		if err != nil {
+			status := int32(StatusCodeCancelled)
+			if _, ok := err.(*TimeoutError); ok {
+				status = StatusCodeTimeout
+			}
+			res = Response{Uri: req.Uri, StatusCode: status, RequestId: res.RequestId}
+		}
+		if cb != nil {
+			cb(res)
+		}
+	})
 	if err != nil {
 		// Call the callback with a 500 error-code so that the request doesn't remain pending in case of error
 		if cb != nil {
 			cb(Response{
+				Uri:        req.Uri,
 				StatusCode: 500,
 			})
 		}
-
 		return err
 	}
+	return nil
+}
+
+// start sends the request, and calls handle once with its response, or the error which ended it: a *TimeoutError
+// if no response came in time, ErrRequestCancelled if it was cancelled. It returns the sequence of the request.
+func (m *Client) start(req Request, handle func(res Response, err error)) (string, error) {
+	correlationId := newCorrelationId()
+	start := time.Now()
+
+	// The callback is registered before sending the request, so that a fast response can't be missed
+	m.cbMu.Lock()
+	seq, seqKey := m.freeSeq()
+	pending := &pendingRequest{}
+	pending.handle = func(res Response, err error) {
+		m.releaseSlot(seqKey)
+		if err == nil {
+			res.RequestId = serverRequestId(res.UserFields)
+		}
+		if res.RequestId == "" {
+			res.RequestId = correlationId
+		}
+
+		m.trace(TraceEvent{
+			RequestId:  res.RequestId,
+			Seq:        fmt.Sprintf("%x", seq),
+			Method:     req.Method,
+			Uri:        req.Uri,
+			StatusCode: res.StatusCode,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+
+		handle(res, err)
+	}
 
+	timeout := m.timeout
+	m.callbacks[seqKey] = pending
+	if timeout > 0 {
+		pending.timer = time.AfterFunc(timeout, func() {
+			if m.takeCallback(seqKey) != nil {
+				m.internal.forget(seqKey)
+				pending.handle(Response{}, &TimeoutError{
+					Method:    req.Method,
+					Uri:       req.Uri,
+					RequestId: correlationId,
+					After:     timeout,
+				})
+			}
+		})
+	}
+	m.cbMu.Unlock()
+
+	send := func() error {
+		return m.internal.request(seq, req)
+	}
+	caller := req.Caller
+	if caller == "" {
+		caller = requestCaller(req.Uri)
+	}
+	if !m.limiter.acquire(seqKey, caller, send) {
+		// Sent once the responses of the requests in flight free a slot
+		return seqKey, nil
+	}
+
+	err := send()
+	if err != nil {
+		m.takeCallback(seqKey)
+		m.releaseSlot(seqKey)
+		m.trace(TraceEvent{
+			RequestId:  correlationId,
+			Method:     req.Method,
+			Uri:        req.Uri,
+			StatusCode: 500,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+		return "", fmt.Errorf("mercury %s %s failed (request id %s): %v", req.Method, req.Uri, correlationId, err)
+	}
+
+	return seqKey, nil
+}
+
+// freeSeq returns the next sequence which isn't used by a request in flight, and its key. Once the sequence wraps
+// around, e.g. in a session living for years, a subscription or a request queued by the limiter may still use it. It
+// must be called with cbMu held.
+func (m *Client) freeSeq() ([]byte, string) {
+	for {
+		_, seq := m.internal.NextSeq()
+		seqKey, _ := normalizeSeq(seq)
+		if _, used := m.callbacks[seqKey]; !used {
+			return seq, seqKey
+		}
+	}
+}
+
+// takeCallback removes the callback of a pending request, and stops its timeout
+func (m *Client) takeCallback(seqKey string) *pendingRequest {
 	m.cbMu.Lock()
-	m.callbacks[string(seq)] = cb
+	pending, ok := m.callbacks[seqKey]
+	delete(m.callbacks, seqKey)
 	m.cbMu.Unlock()
 
-	return nil
+	if !ok {
+		return nil
+	}
+	if pending.timer != nil {
+		pending.timer.Stop()
+	}
+	return pending
+}
+
+// cancel ends a pending request with the error, ignoring its response if it comes later
+func (m *Client) cancel(seqKey string, err error) {
+	if pending := m.takeCallback(seqKey); pending != nil {
+		m.internal.forget(seqKey)
+		pending.handle(Response{}, err)
+	}
+}
+
+// SetRequestTimeout changes how long to wait for the response of a request, zero meaning forever
+func (m *Client) SetRequestTimeout(timeout time.Duration) {
+	m.cbMu.Lock()
+	m.timeout = timeout
+	m.cbMu.Unlock()
+}
+
+// CancelPending ends all the requests waiting for their response with ErrRequestCancelled, e.g. when the connection
+// is closed
+func (m *Client) CancelPending() {
+	m.limiter.drop()
+
+	m.cbMu.Lock()
+	seqKeys := make([]string, 0, len(m.callbacks))
+	for seqKey := range m.callbacks {
+		seqKeys = append(seqKeys, seqKey)
+	}
+	m.cbMu.Unlock()
+
+	for _, seqKey := range seqKeys {
+		m.cancel(seqKey, ErrRequestCancelled)
+	}
+}
+
+// PendingRequests returns the number of requests waiting for their response
+func (m *Client) PendingRequests() int {
+	m.cbMu.Lock()
+	defer m.cbMu.Unlock()
+	return len(m.callbacks)
 }
 
 func (m *Client) NextSeq() []byte {
@@ -130,6 +464,8 @@ func (m *Client) NextSeqWithInt() (uint32, []byte) {
 	return m.internal.NextSeq()
 }
 
+// NextSeq returns the next sequence of the requests, in 4 bytes. The sequence wraps around to 0 after 2^32 requests,
+// see Client.start for how the sequences still in flight are skipped.
 func (m *Internal) NextSeq() (uint32, []byte) {
 	m.seqLock.Lock()
 
@@ -142,29 +478,34 @@ func (m *Internal) NextSeq() (uint32, []byte) {
 	return seqInt, seq
 }
 
-func (m *Internal) request(req Request) (seqKey string, err error) {
-	_, seq := m.NextSeq()
+func (m *Internal) request(seq []byte, req Request) error {
 	data, err := encodeRequest(seq, req)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var cmd uint8
+	var cmd connection.PacketType
 	switch {
 	case req.Method == "SUB":
-		cmd = 0xb3
+		cmd = connection.PacketMercurySub
 	case req.Method == "UNSUB":
-		cmd = 0xb4
+		cmd = connection.PacketMercuryUnsub
 	default:
-		cmd = 0xb2
+		cmd = connection.PacketMercuryReq
 	}
 
-	err = m.stream.SendPacket(cmd, data)
-	if err != nil {
-		return "", err
-	}
+	m.streamLock.RLock()
+	stream := m.stream
+	m.streamLock.RUnlock()
+
+	return stream.SendPacket(cmd, data)
+}
 
-	return string(seq), nil
+// forget drops the parts received so far for a request which was cancelled
+func (m *Internal) forget(seqKey string) {
+	m.pendingLock.Lock()
+	delete(m.pending, seqKey)
+	m.pendingLock.Unlock()
 }
 
 func encodeMercuryHead(seq []byte, partsLength uint16, flags uint8) (*bytes.Buffer, error) {
@@ -205,6 +546,9 @@ func encodeRequest(seq []byte, req Request) ([]byte, error) {
 	}
 
 	headerData, err := proto.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mercury header: %v", err)
+	}
 	err = binary.Write(buf, binary.BigEndian, uint16(len(headerData)))
 	if err != nil {
 		return nil, err
@@ -228,6 +572,28 @@ func encodeRequest(seq []byte, req Request) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// normalizeSeq returns the key of a sequence, which is the same whatever its width: the server may answer or send
+// events with sequences of 2, 4 or 8 bytes, while the requests are sent with 4 bytes. The events may also have no
+// sequence. The other widths are invalid.
+func normalizeSeq(seq []byte) (string, error) {
+	var value uint64
+	switch len(seq) {
+	case 0:
+		return "", nil
+	case 2:
+		value = uint64(binary.BigEndian.Uint16(seq))
+	case 4:
+		value = uint64(binary.BigEndian.Uint32(seq))
+	case 8:
+		value = binary.BigEndian.Uint64(seq)
+	default:
+		return "", fmt.Errorf("bad mercury seq length %d", len(seq))
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, value)
+	return string(key), nil
+}
+
 func handleHead(reader io.Reader) (seq []byte, flags uint8, count uint16, err error) {
 	var seqLength uint16
 	err = binary.Read(reader, binary.BigEndian, &seqLength)
@@ -237,44 +603,47 @@ func handleHead(reader io.Reader) (seq []byte, flags uint8, count uint16, err er
 	seq = make([]byte, seqLength)
 	_, err = io.ReadFull(reader, seq)
 	if err != nil {
-		fmt.Println("read seq")
+		err = fmt.Errorf("failed to read mercury seq: %v", err)
 		return
 	}
 
 	err = binary.Read(reader, binary.BigEndian, &flags)
 	if err != nil {
-		fmt.Println("read flags")
+		err = fmt.Errorf("failed to read mercury flags: %v", err)
 		return
 	}
 	err = binary.Read(reader, binary.BigEndian, &count)
 	if err != nil {
-		fmt.Println("read count")
+		err = fmt.Errorf("failed to read mercury part count: %v", err)
 		return
 	}
 
 	return
 }
 
-func (m *Client) Handle(cmd uint8, reader io.Reader) (err error) {
+func (m *Client) Handle(cmd connection.PacketType, reader io.Reader) (err error) {
 	response, err := m.internal.parseResponse(cmd, reader)
 	if err != nil {
 		return
 	}
 	if response != nil {
-		if cmd == 0xb5 {
-			chList, ok := m.subscriptions[response.Uri]
-			if ok {
-				for _, ch := range chList {
-					ch <- *response
-				}
+		if cmd == connection.PacketMercuryEvent {
+			m.currentWireLog().Printf("mercury event %s (%d parts)", response.Uri, len(response.Payload))
+			m.subLock.Lock()
+			subs := append([]*subscriber{}, m.subscriptions[response.Uri]...)
+			listeners := append([]Callback{}, m.eventListeners...)
+			m.recordEvent(response.Uri)
+			m.subLock.Unlock()
+
+			for _, l := range listeners {
+				l(*response)
+			}
+			for _, s := range subs {
+				s.deliver(*response)
 			}
 		} else {
-			m.cbMu.Lock()
-			cb, ok := m.callbacks[response.SeqKey]
-			delete(m.callbacks, response.SeqKey) // no-op if element does not exist
-			m.cbMu.Unlock()
-			if ok {
-				cb(*response)
+			if pending := m.takeCallback(response.SeqKey); pending != nil {
+				pending.handle(*response, nil)
 			}
 		}
 	}
@@ -282,17 +651,21 @@ func (m *Client) Handle(cmd uint8, reader io.Reader) (err error) {
 
 }
 
-func (m *Internal) parseResponse(cmd uint8, reader io.Reader) (response *Response, err error) {
+func (m *Internal) parseResponse(cmd connection.PacketType, reader io.Reader) (response *Response, err error) {
 	seq, flags, count, err := handleHead(reader)
 	if err != nil {
-		fmt.Println("error handling response", err)
 		return
 	}
 
-	seqKey := string(seq)
+	seqKey, err := normalizeSeq(seq)
+	if err != nil {
+		return
+	}
+	m.pendingLock.Lock()
+	defer m.pendingLock.Unlock()
 	pending, ok := m.pending[seqKey]
 
-	if !ok && cmd == 0xb5 {
+	if !ok && cmd == connection.PacketMercuryEvent {
 		pending = Pending{}
 	} else if !ok {
 		//log.Print("ignoring seq ", SeqKey)
@@ -301,8 +674,7 @@ func (m *Internal) parseResponse(cmd uint8, reader io.Reader) (response *Respons
 	for i := uint16(0); i < count; i++ {
 		part, err := parsePart(reader)
 		if err != nil {
-			fmt.Println("read part")
-			return nil, err
+			return nil, fmt.Errorf("failed to read mercury part %d: %v", i, err)
 		}
 
 		if pending.partial != nil {
@@ -326,29 +698,42 @@ func (m *Internal) parseResponse(cmd uint8, reader io.Reader) (response *Respons
 	return nil, nil
 }
 
-func (m *Internal) completeRequest(cmd uint8, pending Pending, seqKey string) (response *Response, err error) {
+func (m *Internal) completeRequest(cmd connection.PacketType, pending Pending, seqKey string) (response *Response, err error) {
+	if len(pending.parts) == 0 {
+		return nil, fmt.Errorf("mercury response without header")
+	}
+
 	headerData := pending.parts[0]
 	header := &Spotify.Header{}
 	err = proto.Unmarshal(headerData, header)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to unmarshal mercury header: %v", err)
+	}
+
+	userFields := make(map[string]string, len(header.GetUserFields()))
+	for _, field := range header.GetUserFields() {
+		userFields[field.GetKey()] = string(field.GetValue())
 	}
 
 	return &Response{
 		HeaderData: headerData,
-		Uri:        *header.Uri,
+		Uri:        header.GetUri(),
 		Payload:    pending.parts[1:],
 		StatusCode: header.GetStatusCode(),
 		SeqKey:     seqKey,
+		UserFields: userFields,
 	}, nil
 
 }
 
 func parsePart(reader io.Reader) ([]byte, error) {
 	var size uint16
-	binary.Read(reader, binary.BigEndian, &size)
+	err := binary.Read(reader, binary.BigEndian, &size)
+	if err != nil {
+		return nil, err
+	}
 	buf := make([]byte, size)
-	_, err := io.ReadFull(reader, buf)
+	_, err = io.ReadFull(reader, buf)
 	return buf, err
 }
 
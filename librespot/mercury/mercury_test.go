@@ -1,22 +1,35 @@
 package mercury
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/connection"
-	"github.com/fischerling/librespot-golang/librespot/core"
-	"github.com/fischerling/librespot-golang/librespot/spirc"
 	"testing"
+	"time"
 )
 
-func setupTestController(stream connection.PacketStream) *spirc.Controller {
-	s := &core.Session{
-		stream:   stream,
-		deviceId: "testDevice",
-	}
-	s.mercury = CreateMercury(s)
-	return setupController(s, "fakeUser", []byte{})
+type shanPacket struct {
+	cmd connection.PacketType
+	buf []byte
+}
+
+type fakeStream struct {
+	recvPackets chan shanPacket
+	sendPackets chan shanPacket
+}
+
+func (f *fakeStream) SendPacket(cmd connection.PacketType, data []byte) (err error) {
+	f.sendPackets <- shanPacket{cmd: cmd, buf: data}
+	return nil
+}
+
+func (f *fakeStream) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
+	p := <-f.recvPackets
+	return p.cmd, p.buf, nil
 }
 
 func TestMultiPart(t *testing.T) {
@@ -25,7 +38,7 @@ func TestMultiPart(t *testing.T) {
 		sendPackets: make(chan shanPacket, 5),
 	}
 
-	controller := setupTestController(stream)
+	client := CreateMercury(stream)
 
 	subHeader := &Spotify.Header{
 		Uri: proto.String("hm://searchview/km/v2/search/Future"),
@@ -40,9 +53,9 @@ func TestMultiPart(t *testing.T) {
 	body := []byte("{searchResults: {tracks: [], albums: [], tracks: []}}")
 
 	headerData, _ := proto.Marshal(header)
-	seq := []byte{0, 0, 0, 1}
+	seq := []byte{0, 0, 0, 0}
 
-	p0, _ := encodeMercuryHead([]byte{0, 0, 0, 0}, 1, 1)
+	p0, _ := encodeMercuryHead([]byte{0, 0, 0, 1}, 1, 1)
 	binary.Write(p0, binary.BigEndian, uint16(len(subHeaderData)))
 	p0.Write(subHeaderData)
 
@@ -54,8 +67,13 @@ func TestMultiPart(t *testing.T) {
 	binary.Write(p2, binary.BigEndian, uint16(len(body)))
 	p2.Write(body)
 
+	var traced TraceEvent
+	client.SetTraceHook(func(event TraceEvent) {
+		traced = event
+	})
+
 	didRecieveCallback := false
-	controller.session.mercurySendRequest(Request{
+	client.Request(Request{
 		Method:  "SEND",
 		Uri:     "hm://searchview/km/v2/search/Future",
 		Payload: [][]byte{},
@@ -64,18 +82,453 @@ func TestMultiPart(t *testing.T) {
 		if string(res.Payload[0]) != string(body) {
 			t.Errorf("bad body received")
 		}
+		if res.RequestId == "" {
+			t.Errorf("response has no request id")
+		}
 	})
 
-	stream.recvPackets <- shanPacket{cmd: 0xb2, buf: p0.Bytes()}
-	stream.recvPackets <- shanPacket{cmd: 0xb2, buf: p1.Bytes()}
-	stream.recvPackets <- shanPacket{cmd: 0xb2, buf: p2.Bytes()}
+	// Ignore the request itself
+	<-stream.sendPackets
 
-	controller.session.poll()
-	controller.session.poll()
-	controller.session.poll()
+	for _, p := range [][]byte{p0.Bytes(), p1.Bytes(), p2.Bytes()} {
+		if err := client.Handle(0xb2, bytes.NewReader(p)); err != nil {
+			t.Fatalf("failed to handle packet: %v", err)
+		}
+	}
 
 	if !didRecieveCallback {
 		t.Errorf("never received callback")
 	}
+	if traced.StatusCode != 200 || traced.RequestId == "" || traced.Method != "SEND" {
+		t.Errorf("bad trace event: %+v", traced)
+	}
+
+}
+
+func TestResubscribe(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+
+	client := CreateMercury(stream)
+	ch := make(chan Response)
+	client.Subscribe("hm://remote/user/fakeUser/", ch, nil)
+
+	sub := <-stream.sendPackets
+	if sub.cmd != 0xb3 {
+		t.Fatalf("expected a SUB packet, got 0x%x", sub.cmd)
+	}
+
+	newStream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client.SetStream(newStream)
+
+	if err := client.Resubscribe(); err != nil {
+		t.Fatal(err)
+	}
+
+	resub := <-newStream.sendPackets
+	if resub.cmd != 0xb3 {
+		t.Errorf("expected a SUB packet on the new stream, got 0x%x", resub.cmd)
+	}
+	if len(client.subscriptions["hm://remote/user/fakeUser/"]) != 1 {
+		t.Errorf("subscriber channel should not be duplicated")
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+	client.SetRequestTimeout(20 * time.Millisecond)
+
+	_, err := client.Do(Request{Method: "GET", Uri: "hm://metadata/4/track/0"})
+	if _, ok := err.(*TimeoutError); !ok {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+
+	status := make(chan int32, 1)
+	client.Request(Request{Method: "GET", Uri: "hm://metadata/4/track/1"}, func(res Response) {
+		status <- res.StatusCode
+	})
+	if s := <-status; s != StatusCodeTimeout {
+		t.Errorf("Expected a timeout status, got %d", s)
+	}
 
+	if n := client.PendingRequests(); n != 0 {
+		t.Errorf("%d requests still pending", n)
+	}
+}
+
+func TestRequestCancel(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stream.sendPackets
+		cancel()
+	}()
+	if _, err := client.DoContext(ctx, Request{Method: "GET", Uri: "hm://metadata/4/track/0"}); err == nil {
+		t.Errorf("Expected the request to be cancelled")
+	}
+
+	status := make(chan int32, 1)
+	client.Request(Request{Method: "GET", Uri: "hm://metadata/4/track/1"}, func(res Response) {
+		status <- res.StatusCode
+	})
+	client.CancelPending()
+	if s := <-status; s != StatusCodeCancelled {
+		t.Errorf("Expected a cancelled status, got %d", s)
+	}
+	if n := client.PendingRequests(); n != 0 {
+		t.Errorf("%d requests still pending", n)
+	}
+}
+
+// headerPacket builds a single part packet holding only a header, answering the request sent with seq
+func headerPacket(seq []byte, uri string, status int32) []byte {
+	headerData, _ := proto.Marshal(&Spotify.Header{Uri: proto.String(uri), StatusCode: proto.Int32(status)})
+	p, _ := encodeMercuryHead(seq, 1, 1)
+	binary.Write(p, binary.BigEndian, uint16(len(headerData)))
+	p.Write(headerData)
+	return p.Bytes()
+}
+
+func requestSeq(packet shanPacket) []byte {
+	length := binary.BigEndian.Uint16(packet.buf)
+	return packet.buf[2 : 2+length]
+}
+
+func TestSubscriptions(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+	uri := "hm://remote/user/fakeUser/"
+
+	go func() {
+		sub := <-stream.sendPackets
+		client.Handle(connection.PacketMercurySub, bytes.NewReader(headerPacket(requestSeq(sub), uri, 200)))
+	}()
+	first, err := client.NewSubscription(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server-side subscription is shared
+	second, err := client.NewSubscription(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case p := <-stream.sendPackets:
+		t.Fatalf("unexpected packet %v", p.cmd)
+	default:
+	}
+
+	event := headerPacket([]byte{0, 0, 0, 9}, uri, 200)
+	if err := client.Handle(connection.PacketMercuryEvent, bytes.NewReader(event)); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*Subscription{first, second} {
+		if res := <-sub.C; res.Uri != uri {
+			t.Errorf("bad event uri %s", res.Uri)
+		}
+	}
+
+	if err := client.Unsubscribe(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-first.C; ok {
+		t.Errorf("channel of the subscription not closed")
+	}
+
+	go func() {
+		unsub := <-stream.sendPackets
+		if unsub.cmd != connection.PacketMercuryUnsub {
+			t.Errorf("expected an UNSUB packet, got %v", unsub.cmd)
+		}
+		client.Handle(connection.PacketMercuryUnsub, bytes.NewReader(headerPacket(requestSeq(unsub), uri, 200)))
+	}()
+	if err := client.Unsubscribe(second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-second.C; ok {
+		t.Errorf("channel of the subscription not closed")
+	}
+	if len(client.subscriptions) != 0 || len(client.subscribed) != 0 {
+		t.Errorf("subscriptions left after unsubscribing")
+	}
+}
+
+func TestConcurrentSubscriptionFailure(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+	uri := "hm://remote/user/fakeUser/"
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := client.NewSubscription(uri)
+		errs <- err
+	}()
+	sub := <-stream.sendPackets
+
+	// The second subscription waits for the SUB of the first one
+	go func() {
+		_, err := client.NewSubscription(uri)
+		errs <- err
+	}()
+	select {
+	case err := <-errs:
+		t.Fatalf("subscription returned before the SUB response: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	client.Handle(connection.PacketMercurySub, bytes.NewReader(headerPacket(requestSeq(sub), uri, 500)))
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil {
+			t.Errorf("expected the failed SUB to fail the subscription")
+		}
+	}
+	if len(client.subscriptions) != 0 || len(client.subscribed) != 0 {
+		t.Errorf("subscriptions left after the failed SUB")
+	}
+
+	// The next subscription sends the SUB again
+	go func() {
+		sub := <-stream.sendPackets
+		client.Handle(connection.PacketMercurySub, bytes.NewReader(headerPacket(requestSeq(sub), uri, 200)))
+	}()
+	if _, err := client.NewSubscription(uri); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+
+	var events []Response
+	client.OnEvent(func(res Response) {
+		events = append(events, res)
+	})
+
+	// Events are reported even without subscription
+	uri := "hm://connect-state/v1/unsubscribed"
+	event := headerPacket([]byte{0, 0, 0, 7}, uri, 200)
+	if err := client.Handle(connection.PacketMercuryEvent, bytes.NewReader(event)); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Uri != uri {
+		t.Errorf("got events %+v", events)
+	}
+}
+
+// requestUri returns the uri of the header of a request packet
+func requestUri(packet shanPacket) string {
+	offset := 2 + len(requestSeq(packet)) + 3
+	length := int(binary.BigEndian.Uint16(packet.buf[offset:]))
+	header := &Spotify.Header{}
+	proto.Unmarshal(packet.buf[offset+2:offset+2+length], header)
+	return header.GetUri()
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	const requests = 100
+	stream := &fakeStream{sendPackets: make(chan shanPacket, requests)}
+	client := CreateMercury(stream)
+	client.SetMaxInFlight(8)
+
+	// The responses are handled by one goroutine, like the poll loop of the session, while the requests are sent
+	// and the settings changed from others
+	go func() {
+		for packet := range stream.sendPackets {
+			uri := requestUri(packet)
+			client.Handle(connection.PacketMercuryReq, bytes.NewReader(headerPacket(requestSeq(packet), uri, 200)))
+		}
+	}()
+	defer close(stream.sendPackets)
+
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		go func(i int) {
+			uri := fmt.Sprintf("hm://metadata/4/track/%d", i)
+			res, err := client.Do(Request{Method: "GET", Uri: uri})
+			if err == nil && res.Uri != uri {
+				err = fmt.Errorf("got the response of %s for %s", res.Uri, uri)
+			}
+			errs <- err
+		}(i)
+		go client.SetRequestTimeout(time.Minute)
+		go client.SetCache(NewCache())
+	}
+
+	for i := 0; i < requests; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if client.PendingRequests() != 0 || client.QueuedRequests() != 0 {
+		t.Errorf("%d requests pending and %d queued", client.PendingRequests(), client.QueuedRequests())
+	}
+}
+
+func TestSubscriptionLiveness(t *testing.T) {
+	stream := &fakeStream{sendPackets: make(chan shanPacket, 5)}
+	client := CreateMercury(stream)
+	uri := "hm://remote/user/fakeUser/"
+	client.Subscribe(uri, make(chan Response, 20), nil)
+	<-stream.sendPackets
+
+	silences := make(chan time.Duration, 1)
+	client.OnSilence(func(silent string, silence time.Duration) {
+		if silent == uri {
+			silences <- silence
+		}
+	})
+	interval := 100 * time.Millisecond
+	client.ExpectEvents(uri, interval)
+
+	// The subscription is kept while the events come
+	for i := 0; i < 15; i++ {
+		event := headerPacket([]byte{0, 0, 0, byte(i)}, uri, 200)
+		if err := client.Handle(connection.PacketMercuryEvent, bytes.NewReader(event)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(interval / 5)
+	}
+	select {
+	case p := <-stream.sendPackets:
+		t.Fatalf("unexpected packet %v while the events come", p.cmd)
+	default:
+	}
+
+	// And requested again once they stop
+	select {
+	case p := <-stream.sendPackets:
+		if p.cmd != connection.PacketMercurySub || requestUri(p) != uri {
+			t.Errorf("got packet %v for %s, want a SUB for %s", p.cmd, requestUri(p), uri)
+		}
+	case <-time.After(10 * interval):
+		t.Fatal("not subscribed again after the silence")
+	}
+	if silence := <-silences; silence < interval {
+		t.Errorf("got a silence of %v, want at least %v", silence, interval)
+	}
+
+	client.ClearSubscriptions()
+	client.subLock.Lock()
+	defer client.subLock.Unlock()
+	if len(client.liveness) != 0 {
+		t.Error("liveness check left after clearing the subscriptions")
+	}
+}
+
+func TestSeqWidths(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+	client.internal.nextSeq = 0x1234
+
+	// The server may answer with sequences of any supported width
+	for _, seq := range [][]byte{{0x12, 0x34}, {0, 0, 0x12, 0x35}, {0, 0, 0, 0, 0, 0, 0x12, 0x36}} {
+		responses := make(chan Response, 1)
+		client.Request(Request{Method: "GET", Uri: "hm://x"}, func(res Response) {
+			responses <- res
+		})
+		<-stream.sendPackets
+		if err := client.Handle(connection.PacketMercuryReq, bytes.NewReader(headerPacket(seq, "hm://x", 200))); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case res := <-responses:
+			if res.StatusCode != 200 {
+				t.Errorf("Bad response for seq %x: %+v", seq, res)
+			}
+		default:
+			t.Errorf("No response for seq %x", seq)
+		}
+	}
+
+	packet := headerPacket([]byte{1, 2, 3}, "hm://x", 200)
+	if err := client.Handle(connection.PacketMercuryReq, bytes.NewReader(packet)); err == nil {
+		t.Error("Accepted a seq of 3 bytes")
+	}
+}
+
+func TestSeqWrap(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	client := CreateMercury(stream)
+	client.SetRequestTimeout(0)
+
+	client.Request(Request{Method: "SUB", Uri: "hm://x"}, nil)
+	client.internal.nextSeq = 0xffffffff
+	client.Request(Request{Method: "GET", Uri: "hm://x"}, nil)
+	client.Request(Request{Method: "GET", Uri: "hm://x"}, nil)
+
+	// Once wrapped, the sequence of the subscription still pending is skipped
+	for _, expected := range []uint32{0, 0xffffffff, 1} {
+		if seq := binary.BigEndian.Uint32(requestSeq(<-stream.sendPackets)); seq != expected {
+			t.Errorf("Expected seq %x, got %x", expected, seq)
+		}
+	}
+	if n := client.PendingRequests(); n != 3 {
+		t.Errorf("Expected 3 pending requests, got %d", n)
+	}
+}
+
+func TestRetryDroppedRequests(t *testing.T) {
+	stream := &fakeStream{
+		recvPackets: make(chan shanPacket, 5),
+		sendPackets: make(chan shanPacket, 5),
+	}
+	faulty := connection.NewFaultyStream(stream, connection.Faults{Seed: 3, DropRate: 0.4})
+	client := CreateMercury(faulty)
+	client.SetRequestTimeout(20 * time.Millisecond)
+
+	// The server answers the requests which aren't dropped on the way
+	go func() {
+		for packet := range stream.sendPackets {
+			client.Handle(packet.cmd, bytes.NewReader(headerPacket(requestSeq(packet), "hm://x", 200)))
+		}
+	}()
+
+	// The requests timing out are retried until they get through
+	for i := 0; i < 10; i++ {
+		for attempt := 1; ; attempt++ {
+			_, err := client.Do(Request{Method: "GET", Uri: fmt.Sprintf("hm://x/%d", i)})
+			if err == nil {
+				break
+			}
+			if _, ok := err.(*TimeoutError); !ok || attempt == 10 {
+				t.Fatalf("Request %d failed after %d attempts: %v", i, attempt, err)
+			}
+		}
+	}
+	if stats := faulty.Stats(); stats.Dropped == 0 {
+		t.Errorf("No request dropped: %+v", stats)
+	}
+	if n := client.PendingRequests(); n != 0 {
+		t.Errorf("%d requests still pending", n)
+	}
 }
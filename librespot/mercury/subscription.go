@@ -0,0 +1,178 @@
+package mercury
+
+import (
+	"sync"
+)
+
+// subscriptionBuffer is the number of events buffered by a Subscription before the delivery blocks
+const subscriptionBuffer = 16
+
+// subscriber receives the events published on the URIs it is subscribed to. The subscribers created through
+// Subscribe are never closed, the ones of the Subscriptions are closed by Unsubscribe.
+type subscriber struct {
+	ch       chan Response
+	done     chan struct{}
+	doneOnce sync.Once
+
+	lock   sync.RWMutex
+	closed bool
+}
+
+// deliver sends the event to the subscriber, waiting for it to be received unless the subscriber is closed
+func (s *subscriber) deliver(res Response) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- res:
+	case <-s.done:
+	}
+}
+
+func (s *subscriber) close() {
+	if s.done == nil {
+		return
+	}
+
+	// done unblocks a pending delivery, which holds the read lock, before the channel can be closed
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// pendingSubscription is the SUB request of the first subscription to a URI, done being closed once err is set
+type pendingSubscription struct {
+	done chan struct{}
+	err  error
+}
+
+// Subscription receives the events published on a URI on C, until it is unsubscribed from. C is then closed.
+type Subscription struct {
+	Uri string
+	C   <-chan Response
+
+	sub *subscriber
+}
+
+// NewSubscription subscribes to the events published on uri. Several subscriptions can be made to the same URI,
+// each of them receives all the events, and the server-side subscription is only requested by the first one. The
+// subscriptions made while that request is in flight wait for its result, and fail with it.
+func (m *Client) NewSubscription(uri string) (*Subscription, error) {
+	s := &subscriber{
+		ch:   make(chan Response, subscriptionBuffer),
+		done: make(chan struct{}),
+	}
+
+	m.subLock.Lock()
+	first := !m.subscribed[uri]
+	pending := m.pendingSubs[uri]
+	if first {
+		pending = &pendingSubscription{done: make(chan struct{})}
+		m.pendingSubs[uri] = pending
+	}
+	m.subscribed[uri] = true
+	m.addSubscriber(uri, s)
+	for _, alias := range m.aliases[uri] {
+		m.addSubscriber(alias, s)
+	}
+	m.subLock.Unlock()
+
+	subscription := &Subscription{Uri: uri, C: s.ch, sub: s}
+	if first {
+		res, err := m.Do(Request{Method: "SUB", Uri: uri})
+		if err == nil {
+			m.addAliases(uri, *res)
+		}
+
+		m.subLock.Lock()
+		if m.pendingSubs[uri] == pending {
+			delete(m.pendingSubs, uri)
+		}
+		if err != nil {
+			// The next subscription requests the server-side subscription again
+			m.stopLiveness(uri)
+			delete(m.subscribed, uri)
+			delete(m.aliases, uri)
+		}
+		m.subLock.Unlock()
+		pending.err = err
+		close(pending.done)
+	} else if pending != nil {
+		<-pending.done
+	}
+
+	if pending != nil && pending.err != nil {
+		m.remove(subscription)
+		return nil, pending.err
+	}
+	return subscription, nil
+}
+
+// Unsubscribe stops the delivery of the events to the subscription, and closes its channel. Once the last
+// subscription to its URI is gone, the server-side subscription is cancelled too.
+func (m *Client) Unsubscribe(subscription *Subscription) error {
+	if !m.remove(subscription) {
+		return nil
+	}
+
+	_, err := m.Do(Request{Method: "UNSUB", Uri: subscription.Uri})
+	return err
+}
+
+// remove closes the subscription, and returns whether it was the last one to its URI
+func (m *Client) remove(subscription *Subscription) bool {
+	uri := subscription.Uri
+
+	m.subLock.Lock()
+	for _, u := range append([]string{uri}, m.aliases[uri]...) {
+		subs := m.subscriptions[u]
+		for i, s := range subs {
+			if s == subscription.sub {
+				subs = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(subs) == 0 {
+			delete(m.subscriptions, u)
+		} else {
+			m.subscriptions[u] = subs
+		}
+	}
+
+	last := m.subscribed[uri] && len(m.subscriptions[uri]) == 0
+	if last {
+		m.stopLiveness(uri)
+		delete(m.subscribed, uri)
+		delete(m.aliases, uri)
+	}
+	m.subLock.Unlock()
+
+	subscription.sub.close()
+	return last
+}
+
+// UnsubscribeAll cancels the server-side subscriptions to all the URIs subscribed to, without waiting for the
+// responses, and forgets them like ClearSubscriptions. It is used when the session is closed.
+func (m *Client) UnsubscribeAll() {
+	m.subLock.Lock()
+	var uris []string
+	for uri := range m.subscribed {
+		uris = append(uris, uri)
+	}
+	m.subLock.Unlock()
+
+	for _, uri := range uris {
+		m.Request(Request{Method: "UNSUB", Uri: uri}, nil)
+	}
+	m.ClearSubscriptions()
+}
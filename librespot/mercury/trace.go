@@ -0,0 +1,120 @@
+package mercury
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/crypto"
+)
+
+// Header fields the server may use to identify a request on its side
+var requestIdHeaders = []string{"X-Request-Id", "X-Spotify-Request-Id"}
+
+// TraceEvent describes a completed mercury request, for debugging purposes
+type TraceEvent struct {
+	// RequestId is the server request id if provided, or the locally generated correlation id otherwise
+	RequestId  string
+	Seq        string
+	Method     string
+	Uri        string
+	StatusCode int32
+	Duration   time.Duration
+	Err        error
+}
+
+// TraceHook is called for every completed (or failed) mercury request
+type TraceHook func(event TraceEvent)
+
+// RequestError is returned when a mercury request completes with a non-successful status code. The RequestId
+// allows to reference the precise request when reporting server-side issues.
+type RequestError struct {
+	Method     string
+	Uri        string
+	StatusCode int32
+	RequestId  string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("mercury %s %s failed with status %d (request id %s)", e.Method, e.Uri, e.StatusCode,
+		e.RequestId)
+}
+
+// TimeoutError is returned when a mercury request gets no response in time. The request can be retried.
+type TimeoutError struct {
+	Method    string
+	Uri       string
+	RequestId string
+	After     time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("mercury %s %s timed out after %v (request id %s)", e.Method, e.Uri, e.After, e.RequestId)
+}
+
+// Timeout returns true, like the timeout errors of the net package
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// ErrRequestCancelled is returned for the requests cancelled before getting their response
+var ErrRequestCancelled = errors.New("mercury request cancelled")
+
+// SetTraceHook registers a hook called for every completed mercury request. Pass nil to remove it.
+func (m *Client) SetTraceHook(hook TraceHook) {
+	m.cbMu.Lock()
+	m.traceHook = hook
+	m.cbMu.Unlock()
+}
+
+// SetWireLog makes the client log its requests, their responses and the events received to the wire log
+func (m *Client) SetWireLog(log *connection.WireLog) {
+	m.cbMu.Lock()
+	m.wireLog = log
+	m.cbMu.Unlock()
+}
+
+func (m *Client) currentWireLog() *connection.WireLog {
+	m.cbMu.Lock()
+	defer m.cbMu.Unlock()
+	return m.wireLog
+}
+
+func (m *Client) trace(event TraceEvent) {
+	m.cbMu.Lock()
+	hook := m.traceHook
+	wireLog := m.wireLog
+	m.cbMu.Unlock()
+
+	if event.Err != nil {
+		wireLog.Printf("mercury %s %s seq %s: %v (request id %s)", event.Method, event.Uri, event.Seq, event.Err,
+			event.RequestId)
+	} else {
+		wireLog.Printf("mercury %s %s seq %s: %d in %v (request id %s)", event.Method, event.Uri, event.Seq,
+			event.StatusCode, event.Duration, event.RequestId)
+	}
+
+	if hook != nil {
+		hook(event)
+	}
+}
+
+// newCorrelationId generates a random id used to correlate a request with its response and errors
+func newCorrelationId() string {
+	return hex.EncodeToString(crypto.RandomVec(8))
+}
+
+// serverRequestId returns the request id sent by the server in the response header, if any
+func serverRequestId(fields map[string]string) string {
+	for key, value := range fields {
+		for _, h := range requestIdHeaders {
+			if strings.EqualFold(key, h) {
+				return value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,488 @@
+package metadata
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// ErrUnavailable is returned when a track is restricted in the user country, and no alternative could be found
+var ErrUnavailable = errors.New("track is not available in this country")
+
+// Source provides the raw metadata protobufs, from their hexadecimal id. *mercury.Client implements it.
+type Source interface {
+	GetTrack(id string) (*Spotify.Track, error)
+	GetAlbum(id string) (*Spotify.Album, error)
+	GetArtist(id string) (*Spotify.Artist, error)
+	GetEpisode(id string) (*Spotify.Episode, error)
+	GetShow(id string) (*Spotify.Show, error)
+}
+
+// Catalog fetches the metadata of tracks, albums, artists, episodes and shows as typed structures. Items are
+// identified by their base62 Spotify id, their URI or their open.spotify.com URL, see ids.Parse.
+type Catalog struct {
+	source  Source
+	country string
+}
+
+// NewCatalog creates a catalog fetching the metadata from the source. The country, usually the one of the session,
+// is used to resolve region-restricted content.
+func NewCatalog(source Source, country string) *Catalog {
+	return &Catalog{
+		source:  source,
+		country: country,
+	}
+}
+
+// Ref is a reference to another item, with the information included in the parent item metadata
+type Ref struct {
+	Id   string
+	Uri  string
+	Name string
+}
+
+// Image is a picture (album cover, artist portrait, ...) in one of its available sizes
+type Image struct {
+	// FileId is the hexadecimal id of the image file
+	FileId string
+	Size   Spotify.Image_Size
+	Width  int
+	Height int
+}
+
+// TrackInfo is the metadata of a track
+type TrackInfo struct {
+	Id          string
+	Uri         string
+	Name        string
+	Album       Ref
+	Artists     []Ref
+	Number      int
+	DiscNumber  int
+	Duration    time.Duration
+	Popularity  int
+	Explicit    bool
+	ExternalIds map[string]string
+	Files       []*Spotify.AudioFile
+	// Restricted is set if the track cannot be played in the catalog country
+	Restricted bool
+	// Alternatives are the ids of the same recording released under other ids, possibly available in other countries
+	Alternatives []string
+	Raw          *Spotify.Track
+}
+
+// AlbumInfo is the metadata of an album
+type AlbumInfo struct {
+	Id         string
+	Uri        string
+	Name       string
+	Artists    []Ref
+	Type       Spotify.Album_Type
+	Label      string
+	Year       int
+	Genres     []string
+	Popularity int
+	Covers     []Image
+	// Discs holds the tracks of every disc of the album
+	Discs      [][]Ref
+	Copyrights []string
+	Restricted bool
+	Raw        *Spotify.Album
+}
+
+// ArtistInfo is the metadata of an artist
+type ArtistInfo struct {
+	Id         string
+	Uri        string
+	Name       string
+	Popularity int
+	Genres     []string
+	// TopTracks are the most popular tracks of the artist in the catalog country
+	TopTracks    []Ref
+	Albums       []Ref
+	Singles      []Ref
+	Compilations []Ref
+	AppearsOn    []Ref
+	Portraits    []Image
+	Related      []Ref
+	Raw          *Spotify.Artist
+}
+
+// EpisodeInfo is the metadata of a podcast episode
+type EpisodeInfo struct {
+	Id          string
+	Uri         string
+	Name        string
+	Description string
+	Show        Ref
+	Number      int
+	Duration    time.Duration
+	Explicit    bool
+	PublishTime time.Time
+	Covers      []Image
+	Files       []*Spotify.AudioFile
+	// ExternalUrl is the location of the audio of the episodes not hosted by Spotify, which have no Files
+	ExternalUrl string
+	Restricted  bool
+	Raw         *Spotify.Episode
+}
+
+// ShowInfo is the metadata of a podcast show
+type ShowInfo struct {
+	Id          string
+	Uri         string
+	Name        string
+	Description string
+	Publisher   string
+	Language    string
+	Explicit    bool
+	Covers      []Image
+	Episodes    []Ref
+	Restricted  bool
+	Raw         *Spotify.Show
+}
+
+// Track fetches the metadata of a track
+func (c *Catalog) Track(id string) (*TrackInfo, error) {
+	gid, err := hexGid(ids.KindTrack, id)
+	if err != nil {
+		return nil, err
+	}
+	track, err := c.source.GetTrack(gid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track %s: %v", id, err)
+	}
+	return c.trackInfo(track), nil
+}
+
+// PlayableTrack fetches the metadata of a track, and if it cannot be played in the catalog country, returns the
+// first of its alternatives which can. ErrUnavailable is returned if there is none.
+func (c *Catalog) PlayableTrack(id string) (*TrackInfo, error) {
+	track, err := c.Track(id)
+	if err != nil {
+		return nil, err
+	}
+	if !track.Restricted && len(track.Files) > 0 {
+		return track, nil
+	}
+
+	for _, altId := range track.Alternatives {
+		alt, err := c.Track(altId)
+		if err != nil {
+			continue
+		}
+		if !alt.Restricted && len(alt.Files) > 0 {
+			return alt, nil
+		}
+	}
+
+	return nil, ErrUnavailable
+}
+
+// Album fetches the metadata of an album
+func (c *Catalog) Album(id string) (*AlbumInfo, error) {
+	gid, err := hexGid(ids.KindAlbum, id)
+	if err != nil {
+		return nil, err
+	}
+	album, err := c.source.GetAlbum(gid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album %s: %v", id, err)
+	}
+
+	info := &AlbumInfo{
+		Id:         utils.ConvertTo62(album.GetGid()),
+		Name:       album.GetName(),
+		Artists:    artistRefs(album.GetArtist()),
+		Type:       album.GetTyp(),
+		Label:      album.GetLabel(),
+		Year:       int(album.GetDate().GetYear()),
+		Genres:     album.GetGenre(),
+		Popularity: int(album.GetPopularity()),
+		Covers:     images(album.GetCover(), album.GetCoverGroup()),
+		Restricted: c.isRestricted(album.GetRestriction()),
+		Raw:        album,
+	}
+	info.Uri = "spotify:album:" + info.Id
+
+	for _, disc := range album.GetDisc() {
+		tracks := make([]Ref, 0, len(disc.GetTrack()))
+		for _, t := range disc.GetTrack() {
+			tracks = append(tracks, trackRef(t))
+		}
+		info.Discs = append(info.Discs, tracks)
+	}
+	for _, copyright := range album.GetCopyright() {
+		info.Copyrights = append(info.Copyrights, copyright.GetText())
+	}
+
+	return info, nil
+}
+
+// Artist fetches the metadata of an artist
+func (c *Catalog) Artist(id string) (*ArtistInfo, error) {
+	gid, err := hexGid(ids.KindArtist, id)
+	if err != nil {
+		return nil, err
+	}
+	artist, err := c.source.GetArtist(gid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist %s: %v", id, err)
+	}
+
+	info := &ArtistInfo{
+		Id:           utils.ConvertTo62(artist.GetGid()),
+		Name:         artist.GetName(),
+		Popularity:   int(artist.GetPopularity()),
+		Genres:       artist.GetGenre(),
+		Albums:       albumGroupRefs(artist.GetAlbumGroup()),
+		Singles:      albumGroupRefs(artist.GetSingleGroup()),
+		Compilations: albumGroupRefs(artist.GetCompilationGroup()),
+		AppearsOn:    albumGroupRefs(artist.GetAppearsOnGroup()),
+		Portraits:    images(artist.GetPortrait(), artist.GetPortraitGroup()),
+		Related:      artistRefs(artist.GetRelated()),
+		Raw:          artist,
+	}
+	info.Uri = "spotify:artist:" + info.Id
+
+	for _, top := range artist.GetTopTrack() {
+		if top.GetCountry() != c.country {
+			continue
+		}
+		for _, t := range top.GetTrack() {
+			info.TopTracks = append(info.TopTracks, trackRef(t))
+		}
+	}
+
+	return info, nil
+}
+
+// Episode fetches the metadata of a podcast episode
+func (c *Catalog) Episode(id string) (*EpisodeInfo, error) {
+	gid, err := hexGid(ids.KindEpisode, id)
+	if err != nil {
+		return nil, err
+	}
+	episode, err := c.source.GetEpisode(gid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episode %s: %v", id, err)
+	}
+
+	info := &EpisodeInfo{
+		Id:          utils.ConvertTo62(episode.GetGid()),
+		Name:        episode.GetName(),
+		Description: episode.GetDescription(),
+		Number:      int(episode.GetNumber()),
+		Duration:    time.Duration(episode.GetDuration()) * time.Millisecond,
+		Explicit:    episode.GetExplicit(),
+		PublishTime: date(episode.GetPublishTime()),
+		Covers:      images(nil, episode.GetCovers()),
+		Files:       episode.GetFile(),
+		ExternalUrl: episode.GetExternalUrl(),
+		Restricted:  c.isRestricted(episode.GetRestriction()),
+		Raw:         episode,
+	}
+	info.Uri = "spotify:episode:" + info.Id
+
+	if show := episode.GetShow(); show != nil {
+		info.Show = ref("show", show.GetGid(), show.GetName())
+	}
+
+	return info, nil
+}
+
+// Show fetches the metadata of a podcast show
+func (c *Catalog) Show(id string) (*ShowInfo, error) {
+	gid, err := hexGid(ids.KindShow, id)
+	if err != nil {
+		return nil, err
+	}
+	show, err := c.source.GetShow(gid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show %s: %v", id, err)
+	}
+
+	info := &ShowInfo{
+		Id:          utils.ConvertTo62(show.GetGid()),
+		Name:        show.GetName(),
+		Description: show.GetDescription(),
+		Publisher:   show.GetPublisher(),
+		Language:    show.GetLanguage(),
+		Explicit:    show.GetExplicit(),
+		Covers:      images(nil, show.GetCovers()),
+		Restricted:  c.isRestricted(show.GetRestriction()),
+		Raw:         show,
+	}
+	info.Uri = "spotify:show:" + info.Id
+
+	for _, e := range show.GetEpisode() {
+		info.Episodes = append(info.Episodes, ref("episode", e.GetGid(), e.GetName()))
+	}
+
+	return info, nil
+}
+
+func (c *Catalog) trackInfo(track *Spotify.Track) *TrackInfo {
+	info := &TrackInfo{
+		Id:          utils.ConvertTo62(track.GetGid()),
+		Name:        track.GetName(),
+		Artists:     artistRefs(track.GetArtist()),
+		Number:      int(track.GetNumber()),
+		DiscNumber:  int(track.GetDiscNumber()),
+		Duration:    time.Duration(track.GetDuration()) * time.Millisecond,
+		Popularity:  int(track.GetPopularity()),
+		Explicit:    track.GetExplicit(),
+		ExternalIds: make(map[string]string),
+		Files:       track.GetFile(),
+		Restricted:  c.isRestricted(track.GetRestriction()),
+		Raw:         track,
+	}
+	info.Uri = "spotify:track:" + info.Id
+
+	if album := track.GetAlbum(); album != nil {
+		info.Album = ref("album", album.GetGid(), album.GetName())
+	}
+	for _, ext := range track.GetExternalId() {
+		info.ExternalIds[ext.GetTyp()] = ext.GetId()
+	}
+	for _, alt := range track.GetAlternative() {
+		info.Alternatives = append(info.Alternatives, utils.ConvertTo62(alt.GetGid()))
+	}
+
+	return info
+}
+
+// isRestricted returns true if the restrictions forbid the catalog country
+func (c *Catalog) isRestricted(restrictions []*Spotify.Restriction) bool {
+	if c.country == "" {
+		return false
+	}
+
+	for _, r := range restrictions {
+		if r.CountriesAllowed != nil && !hasCountry(r.GetCountriesAllowed(), c.country) {
+			return true
+		}
+		if hasCountry(r.GetCountriesForbidden(), c.country) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCountry checks if the country is in the list, which is a concatenation of two letters country codes
+func hasCountry(list string, country string) bool {
+	country = strings.ToUpper(country)
+	for i := 0; i+2 <= len(list); i += 2 {
+		if list[i:i+2] == country {
+			return true
+		}
+	}
+	return false
+}
+
+// hexGid returns the hex GID of the item of the kind, identified by its base62 id, URI or open.spotify.com URL
+func hexGid(kind ids.Kind, id string) (string, error) {
+	parsed, err := ids.ParseKind(kind, id)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Hex(), nil
+}
+
+func ref(kind string, gid []byte, name string) Ref {
+	id := utils.ConvertTo62(gid)
+	return Ref{
+		Id:   id,
+		Uri:  "spotify:" + kind + ":" + id,
+		Name: name,
+	}
+}
+
+func trackRef(track *Spotify.Track) Ref {
+	return ref("track", track.GetGid(), track.GetName())
+}
+
+func artistRefs(artists []*Spotify.Artist) []Ref {
+	res := make([]Ref, 0, len(artists))
+	for _, a := range artists {
+		res = append(res, ref("artist", a.GetGid(), a.GetName()))
+	}
+	return res
+}
+
+func albumGroupRefs(groups []*Spotify.AlbumGroup) []Ref {
+	var res []Ref
+	for _, g := range groups {
+		for _, a := range g.GetAlbum() {
+			res = append(res, ref("album", a.GetGid(), a.GetName()))
+		}
+	}
+	return res
+}
+
+// imageSizeRank orders the image sizes from the smallest to the largest
+var imageSizeRank = map[Spotify.Image_Size]int{
+	Spotify.Image_SMALL:   0,
+	Spotify.Image_DEFAULT: 1,
+	Spotify.Image_LARGE:   2,
+	Spotify.Image_XLARGE:  3,
+}
+
+// SelectImage returns the image of the specified size, or if there is none, the closest larger one, or the largest
+// one. It returns false if there is no image.
+func SelectImage(images []Image, size Spotify.Image_Size) (Image, bool) {
+	want := imageSizeRank[size]
+	var best Image
+	found := false
+	for _, img := range images {
+		if !found || closerSize(imageSizeRank[img.Size], imageSizeRank[best.Size], want) {
+			best, found = img, true
+		}
+	}
+	return best, found
+}
+
+// closerSize returns true if the rank is closer to the wanted one than the best rank so far: the larger sizes are
+// preferred to the smaller ones, so that images are scaled down rather than up
+func closerSize(rank, best, want int) bool {
+	if (rank >= want) != (best >= want) {
+		return rank >= want
+	}
+	if rank >= want {
+		return rank < best
+	}
+	return rank > best
+}
+
+func images(list []*Spotify.Image, group *Spotify.ImageGroup) []Image {
+	res := make([]Image, 0, len(list)+len(group.GetImage()))
+	for _, img := range append(append([]*Spotify.Image{}, list...), group.GetImage()...) {
+		res = append(res, Image{
+			FileId: hex.EncodeToString(img.GetFileId()),
+			Size:   img.GetSize(),
+			Width:  int(img.GetWidth()),
+			Height: int(img.GetHeight()),
+		})
+	}
+	return res
+}
+
+func date(d *Spotify.Date) time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	month, day := time.Month(d.GetMonth()), int(d.GetDay())
+	if month == 0 {
+		month = time.January
+	}
+	if day == 0 {
+		day = 1
+	}
+	return time.Date(int(d.GetYear()), month, day, int(d.GetHour()), int(d.GetMinute()), 0, 0, time.UTC)
+}
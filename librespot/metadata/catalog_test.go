@@ -0,0 +1,117 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+type fakeSource struct {
+	tracks map[string]*Spotify.Track
+}
+
+func (f *fakeSource) GetTrack(id string) (*Spotify.Track, error) {
+	return f.tracks[id], nil
+}
+func (f *fakeSource) GetAlbum(id string) (*Spotify.Album, error)     { return &Spotify.Album{}, nil }
+func (f *fakeSource) GetArtist(id string) (*Spotify.Artist, error)   { return &Spotify.Artist{}, nil }
+func (f *fakeSource) GetEpisode(id string) (*Spotify.Episode, error) { return &Spotify.Episode{}, nil }
+func (f *fakeSource) GetShow(id string) (*Spotify.Show, error)       { return &Spotify.Show{}, nil }
+
+func gid(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 16)
+}
+
+func TestPlayableTrackAlternative(t *testing.T) {
+	restricted := &Spotify.Track{
+		Gid:  gid(1),
+		Name: proto.String("Restricted"),
+		Restriction: []*Spotify.Restriction{
+			{CountriesForbidden: proto.String("DEFR")},
+		},
+		File:        []*Spotify.AudioFile{{FileId: []byte{1}}},
+		Alternative: []*Spotify.Track{{Gid: gid(2)}},
+	}
+	alternative := &Spotify.Track{
+		Gid:         gid(2),
+		Name:        proto.String("Alternative"),
+		Restriction: []*Spotify.Restriction{{CountriesAllowed: proto.String("FRDEUS")}},
+		File:        []*Spotify.AudioFile{{FileId: []byte{2}}},
+	}
+	source := &fakeSource{tracks: map[string]*Spotify.Track{
+		hex.EncodeToString(gid(1)): restricted,
+		hex.EncodeToString(gid(2)): alternative,
+	}}
+
+	id := utils.ConvertTo62(gid(1))
+
+	track, err := NewCatalog(source, "DE").PlayableTrack(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.Name != "Alternative" || track.Uri != "spotify:track:"+utils.ConvertTo62(gid(2)) {
+		t.Errorf("expected the alternative, got %s (%s)", track.Name, track.Uri)
+	}
+
+	track, err = NewCatalog(source, "SE").PlayableTrack(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.Name != "Restricted" {
+		t.Errorf("expected the original track, got %s", track.Name)
+	}
+
+	alternative.Restriction[0].CountriesAllowed = proto.String("US")
+	if _, err := NewCatalog(source, "DE").PlayableTrack(id); err != ErrUnavailable {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestCatalogIds(t *testing.T) {
+	track := &Spotify.Track{Gid: gid(1), Name: proto.String("Track")}
+	source := &fakeSource{tracks: map[string]*Spotify.Track{hex.EncodeToString(gid(1)): track}}
+	catalog := NewCatalog(source, "DE")
+
+	id := utils.ConvertTo62(gid(1))
+	for _, input := range []string{id, "spotify:track:" + id, "https://open.spotify.com/track/" + id + "?si=x"} {
+		if info, err := catalog.Track(input); err != nil || info.Name != "Track" {
+			t.Errorf("Failed to get track %q: %v", input, err)
+		}
+	}
+	if _, err := catalog.Track("spotify:album:" + id); err == nil {
+		t.Errorf("Album fetched as a track")
+	}
+	if _, err := catalog.Track("not an id"); err == nil {
+		t.Errorf("Invalid id accepted")
+	}
+}
+
+func TestSelectImage(t *testing.T) {
+	small := Image{FileId: "small", Size: Spotify.Image_SMALL}
+	normal := Image{FileId: "default", Size: Spotify.Image_DEFAULT}
+	xlarge := Image{FileId: "xlarge", Size: Spotify.Image_XLARGE}
+
+	tests := []struct {
+		images   []Image
+		size     Spotify.Image_Size
+		expected string
+	}{
+		{[]Image{small, normal, xlarge}, Spotify.Image_DEFAULT, "default"},
+		{[]Image{xlarge, small, normal}, Spotify.Image_SMALL, "small"},
+		{[]Image{small, xlarge, normal}, Spotify.Image_LARGE, "xlarge"},
+		{[]Image{normal, small}, Spotify.Image_XLARGE, "default"},
+		{[]Image{xlarge, normal}, Spotify.Image_SMALL, "default"},
+	}
+	for _, test := range tests {
+		if img, ok := SelectImage(test.images, test.size); !ok || img.FileId != test.expected {
+			t.Errorf("Selected %s for %v, want %s", img.FileId, test.size, test.expected)
+		}
+	}
+	if _, ok := SelectImage(nil, Spotify.Image_DEFAULT); ok {
+		t.Errorf("Image selected in an empty list")
+	}
+}
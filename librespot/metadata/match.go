@@ -0,0 +1,243 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrNoMatch is returned by Matcher.Match when no track of the catalog matches the query
+var ErrNoMatch = errors.New("no matching track")
+
+// SearchFunc searches the catalog, e.g. core.Session.Search
+type SearchFunc func(query string, limit int) (*SearchResponse, error)
+
+// TrackQuery describes a track to find in the catalog, e.g. from an imported playlist or a scrobble. Any field can
+// be empty.
+type TrackQuery struct {
+	Isrc     string
+	Name     string
+	Artists  []string
+	Duration time.Duration
+}
+
+// Match is a track of the catalog matching a TrackQuery
+type Match struct {
+	Uri string
+	// Score is the similarity between the query and the track, between 0 and 1. Matches by ISRC score 1.
+	Score float64
+	// Track is the metadata of the track when it was fetched to verify its ISRC, nil otherwise
+	Track *TrackInfo
+}
+
+// DefaultMatchSearchLimit is the number of search results considered by a Matcher for each query
+const DefaultMatchSearchLimit = 10
+
+// DefaultMinMatchScore is the lowest score of the matches returned by a Matcher
+const DefaultMinMatchScore = 0.75
+
+// DefaultMatchDurationTolerance is the difference of durations beyond which a track doesn't match, whatever its
+// name and artists
+const DefaultMatchDurationTolerance = 10 * time.Second
+
+// Matcher finds the Spotify tracks corresponding to an ISRC, or to an artist, title and duration. The tracks are
+// looked up by ISRC first, then searched by artist and title, and the search results are scored by the similarity
+// of their names, artists and durations with the query.
+type Matcher struct {
+	// SearchLimit is the number of results considered per search
+	SearchLimit int
+	// MinScore is the lowest score of the fuzzy matches
+	MinScore float64
+	// DurationTolerance is the largest difference of durations of a match
+	DurationTolerance time.Duration
+
+	search  SearchFunc
+	catalog *Catalog
+}
+
+// NewMatcher creates a matcher searching the tracks with the search function. If catalog is not nil, the ISRC of
+// the search results is verified through their metadata; otherwise the results of an ISRC search are scored like
+// the others.
+func NewMatcher(search SearchFunc, catalog *Catalog) *Matcher {
+	return &Matcher{
+		SearchLimit:       DefaultMatchSearchLimit,
+		MinScore:          DefaultMinMatchScore,
+		DurationTolerance: DefaultMatchDurationTolerance,
+		search:            search,
+		catalog:           catalog,
+	}
+}
+
+// Match returns the best match of the query, ErrNoMatch if there is none
+func (m *Matcher) Match(q TrackQuery) (*Match, error) {
+	if q.Isrc != "" {
+		match, err := m.matchIsrc(q)
+		if err != ErrNoMatch {
+			return match, err
+		}
+	}
+	if q.Name == "" {
+		return nil, ErrNoMatch
+	}
+
+	hits, err := m.searchTracks(strings.TrimSpace(strings.Join(q.Artists, " ") + " " + q.Name))
+	if err != nil {
+		return nil, err
+	}
+	return m.best(q, hits)
+}
+
+// matchIsrc searches the tracks with the ISRC of the query
+func (m *Matcher) matchIsrc(q TrackQuery) (*Match, error) {
+	hits, err := m.searchTracks("isrc:" + q.Isrc)
+	if err != nil {
+		return nil, err
+	}
+	if m.catalog == nil {
+		return m.best(q, hits)
+	}
+
+	for _, hit := range hits {
+		track, err := m.catalog.Track(hit.Uri)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(track.ExternalIds["isrc"], q.Isrc) {
+			return &Match{Uri: hit.Uri, Score: 1, Track: track}, nil
+		}
+	}
+	return nil, ErrNoMatch
+}
+
+func (m *Matcher) searchTracks(query string) ([]Track, error) {
+	res, err := m.search(query, m.SearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %q: %v", query, err)
+	}
+	return res.Results.Tracks.Hits, nil
+}
+
+// best returns the search result with the highest score, if it reaches MinScore
+func (m *Matcher) best(q TrackQuery, hits []Track) (*Match, error) {
+	var best *Match
+	for _, hit := range hits {
+		score, ok := m.score(q, hit)
+		if ok && score >= m.MinScore && (best == nil || score > best.Score) {
+			best = &Match{Uri: hit.Uri, Score: score}
+		}
+	}
+	if best == nil {
+		return nil, ErrNoMatch
+	}
+	return best, nil
+}
+
+// score returns the similarity of the track with the query. The name counts for half of the score, the artists and
+// the duration for a quarter each, and the fields missing from the query or the track are left out. ok is false
+// if the durations are too far apart.
+func (m *Matcher) score(q TrackQuery, hit Track) (score float64, ok bool) {
+	var total, weights float64
+	add := func(weight, similarity float64) {
+		total += weight * similarity
+		weights += weight
+	}
+
+	if q.Name != "" {
+		add(2, similarity(normalizeTitle(q.Name), normalizeTitle(hit.Name)))
+	}
+	if len(q.Artists) > 0 && len(hit.Artists) > 0 {
+		add(1, artistSimilarity(q.Artists, hit.Artists))
+	}
+	if q.Duration > 0 && hit.Duration > 0 {
+		diff := time.Duration(hit.Duration)*time.Millisecond - q.Duration
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > m.DurationTolerance {
+			return 0, false
+		}
+		add(1, 1-float64(diff)/float64(m.DurationTolerance+time.Second))
+	}
+
+	if weights == 0 {
+		return 0, false
+	}
+	return total / weights, true
+}
+
+// artistSimilarity returns the best similarity between the names of the artists
+func artistSimilarity(names []string, artists []Artist) float64 {
+	best := 0.0
+	for _, name := range names {
+		for _, artist := range artists {
+			if s := similarity(normalize(name), normalize(artist.Name)); s > best {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// titleSuffixes are the separators of the versions and featured artists in track names, e.g. "Song - Remastered
+// 2011" or "Song (feat. Artist)"
+var titleSuffixes = []string{" - ", " (", " [", " feat. ", " ft. "}
+
+// normalizeTitle normalizes a track name, dropping its version and featured artists
+func normalizeTitle(title string) string {
+	for _, suffix := range titleSuffixes {
+		if i := strings.Index(strings.ToLower(title), suffix); i > 0 {
+			title = title[:i]
+		}
+	}
+	return normalize(title)
+}
+
+// normalize lowercases the string, and keeps only its letters and digits, separated by single spaces
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// similarity returns 1 minus the Levenshtein distance of the strings relative to the longest one
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
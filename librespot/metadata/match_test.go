@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+	"github.com/golang/protobuf/proto"
+)
+
+func searchHits(queries *[]string, hits ...Track) SearchFunc {
+	return func(query string, limit int) (*SearchResponse, error) {
+		*queries = append(*queries, query)
+		res := &SearchResponse{}
+		res.Results.Tracks.Hits = hits
+		return res, nil
+	}
+}
+
+func TestMatcherIsrc(t *testing.T) {
+	source := &fakeSource{tracks: map[string]*Spotify.Track{
+		hex.EncodeToString(gid(1)): {Gid: gid(1), ExternalId: []*Spotify.ExternalId{
+			{Typ: proto.String("isrc"), Id: proto.String("GBAAA0000001")},
+		}},
+		hex.EncodeToString(gid(2)): {Gid: gid(2), ExternalId: []*Spotify.ExternalId{
+			{Typ: proto.String("isrc"), Id: proto.String("GBAAA0000002")},
+		}},
+	}}
+	uri1, uri2 := "spotify:track:"+utils.ConvertTo62(gid(1)), "spotify:track:"+utils.ConvertTo62(gid(2))
+
+	var queries []string
+	matcher := NewMatcher(searchHits(&queries, Track{Uri: uri1}, Track{Uri: uri2}), NewCatalog(source, "DE"))
+	match, err := matcher.Match(TrackQuery{Isrc: "gbaaa0000002"})
+	if err != nil || match.Uri != uri2 || match.Score != 1 || match.Track == nil {
+		t.Errorf("got %+v (%v), want %s", match, err, uri2)
+	}
+	if len(queries) != 1 || queries[0] != "isrc:gbaaa0000002" {
+		t.Errorf("unexpected queries %q", queries)
+	}
+
+	if _, err := matcher.Match(TrackQuery{Isrc: "GBAAA0000003"}); err != ErrNoMatch {
+		t.Errorf("got %v for an unknown ISRC, want ErrNoMatch", err)
+	}
+}
+
+func TestMatcherFuzzy(t *testing.T) {
+	var queries []string
+	matcher := NewMatcher(searchHits(&queries,
+		Track{Name: "Song", Uri: "spotify:track:cover", Artists: []Artist{{Name: "Cover Band"}}, Duration: 200000},
+		Track{Name: "Song - Remastered 2011", Uri: "spotify:track:remaster", Artists: []Artist{{Name: "The Artist"}},
+			Duration: 201000},
+		Track{Name: "Song (Live)", Uri: "spotify:track:live", Artists: []Artist{{Name: "The Artist"}},
+			Duration: 260000},
+	), nil)
+
+	match, err := matcher.Match(TrackQuery{Name: "Song", Artists: []string{"The Artist"}, Duration: 200 * time.Second})
+	if err != nil || match.Uri != "spotify:track:remaster" {
+		t.Errorf("got %+v (%v), want the remaster", match, err)
+	}
+	if queries[0] != "The Artist Song" {
+		t.Errorf("unexpected query %q", queries[0])
+	}
+
+	if _, err := matcher.Match(TrackQuery{Name: "Another Song", Artists: []string{"Someone"}}); err != ErrNoMatch {
+		t.Errorf("got %v, want ErrNoMatch", err)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"song", "song", 1},
+		{"song", "", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+	} {
+		if got := similarity(test.a, test.b); got != test.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+	if got := normalizeTitle("Don't Stop (feat. Someone) - Remix"); got != "don t stop" {
+		t.Errorf("got normalized title %q", got)
+	}
+}
@@ -42,6 +42,15 @@ type Profile struct {
 	FollowersCount int    `json:"followersCount"`
 }
 
+// UserProfile is the public profile of a user, as returned by the user-profile-view endpoint
+type UserProfile struct {
+	Username       string `json:"-"`
+	Name           string `json:"name"`
+	ImageUrl       string `json:"image_url"`
+	FollowersCount int    `json:"followers_count"`
+	FollowingCount int    `json:"following_count"`
+}
+
 type Genre struct {
 	Name  string `json:"name"`
 	Uri   string `json:"uri"`
@@ -140,13 +149,25 @@ type SearchResult struct {
 	Error error
 }
 
+// HasMore returns true if any category has more results than the ones already fetched, up to offset+limit
+func (r *SearchResult) HasMore(offset int, limit int) bool {
+	end := offset + limit
+	for _, total := range []int{r.Tracks.Total, r.Albums.Total, r.Artists.Total, r.Playlists.Total,
+		r.Profiles.Total, r.Genres.Total, r.Shows.Total, r.VideoEpisodes.Total} {
+		if total > end {
+			return true
+		}
+	}
+	return false
+}
+
 type SuggestResult struct {
 	Sections []struct {
 		RawItems json.RawMessage `json:"items"`
 		Typ      string          `json:"type"`
 	} `json:"sections"`
-	Albums  []Artist
-	Artists []Album
+	Albums  []Album
+	Artists []Artist
 	Tracks  []Track
 	TopHits []TopHit
 	Error   error
@@ -158,3 +179,29 @@ type Token struct {
 	TokenType   string   `json:"tokenType"`
 	Scope       []string `json:"scope"`
 }
+
+// ParseSuggest parses the JSON response of the suggest endpoint
+func ParseSuggest(body []byte) (*SuggestResult, error) {
+	result := &SuggestResult{}
+	err := json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range result.Sections {
+		switch s.Typ {
+		case "top-results":
+			err = json.Unmarshal(s.RawItems, &result.TopHits)
+		case "album-results":
+			err = json.Unmarshal(s.RawItems, &result.Albums)
+		case "artist-results":
+			err = json.Unmarshal(s.RawItems, &result.Artists)
+		case "track-results":
+			err = json.Unmarshal(s.RawItems, &result.Tracks)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+// Package oggtest builds Ogg Vorbis streams, to test the seeking and decoding of the audio files without real ones:
+//
+//	stream, ends := oggtest.Stream(300)
+//	offset, granule, err := player.OggSeekPage(bytes.NewReader(stream), ends[0], int64(len(stream)), 151500)
+//
+// The pages are checksummed independently from the player, so that its checksum is checked too.
+package oggtest
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Checksum returns the CRC-32 of an Ogg page, which has no initial value, no final xor, and isn't reflected
+func Checksum(data []byte) uint32 {
+	var crc uint32
+	for _, b := range data {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Page returns an Ogg page holding the payload
+func Page(granule int64, payload []byte) []byte {
+	var segments []byte
+	for n := len(payload); ; n -= 255 {
+		if n < 255 {
+			segments = append(segments, byte(n))
+			break
+		}
+		segments = append(segments, 255)
+	}
+	data := make([]byte, 27, 27+len(segments)+len(payload))
+	copy(data, "OggS")
+	binary.LittleEndian.PutUint64(data[6:], uint64(granule))
+	data[26] = byte(len(segments))
+	data = append(append(data, segments...), payload...)
+	binary.LittleEndian.PutUint32(data[22:], Checksum(data))
+	return data
+}
+
+// Stream builds an Ogg Vorbis stream of pages of 1000 samples at 44100Hz after its headers, returning the offset of
+// the end of each page by its granule position, which is also the beginning of the next page. The end of the headers
+// is at granule 0. If the stream is long enough, the 10th page ends no packet, and the 20th one holds a false capture
+// pattern.
+func Stream(pages int) ([]byte, map[int64]int64) {
+	id := new(bytes.Buffer)
+	id.WriteString("\x01vorbis")
+	binary.Write(id, binary.LittleEndian, uint32(0))
+	id.WriteByte(2)
+	binary.Write(id, binary.LittleEndian, uint32(44100))
+	id.Write(make([]byte, 12))
+	id.Write([]byte{0xb8, 1})
+
+	stream := append(Page(0, id.Bytes()), Page(0, []byte("\x03vorbis comments and setup"))...)
+	ends := map[int64]int64{0: int64(len(stream))}
+	payload := bytes.Repeat([]byte{0xaa}, 1000)
+	for i := int64(1); i <= int64(pages); i++ {
+		granule := i * 1000
+		if i == 10 {
+			granule = -1
+		}
+		data := payload
+		if i == 20 {
+			data = append([]byte("OggS\x00garbage"), payload[13:]...)
+		}
+		stream = append(stream, Page(granule, data)...)
+		ends[i*1000] = int64(len(stream))
+	}
+	return stream, ends
+}
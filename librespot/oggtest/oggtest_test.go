@@ -0,0 +1,13 @@
+package oggtest_test
+
+import (
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/oggtest"
+)
+
+func TestChecksum(t *testing.T) {
+	if crc := oggtest.Checksum([]byte("123456789")); crc != 0x89a1897f {
+		t.Errorf("Got checksum %08x", crc)
+	}
+}
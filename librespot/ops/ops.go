@@ -0,0 +1,119 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of operations tracked by the session
+const (
+	KindAudioDownload  = "audio_download"
+	KindMercuryRequest = "mercury_request"
+	KindTokenRefresh   = "token_refresh"
+)
+
+// ErrUnknownOperation is returned when cancelling an operation which is not in flight
+var ErrUnknownOperation = errors.New("unknown operation")
+
+// Operation describes an operation in flight
+type Operation struct {
+	Id          uint64
+	Kind        string
+	Description string
+	Started     time.Time
+}
+
+type entry struct {
+	op     Operation
+	cancel context.CancelFunc
+}
+
+// Registry keeps track of the long-running operations in flight (audio downloads, mercury requests, token
+// refreshes), so that they can be listed and cancelled, e.g. from the admin interface of a hosted bridge.
+//
+// A nil *Registry is valid, and tracks nothing.
+type Registry struct {
+	lock   sync.Mutex
+	nextId uint64
+	ops    map[uint64]*entry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		ops: make(map[uint64]*entry),
+	}
+}
+
+// Start registers a new operation. The returned context is cancelled when the operation is cancelled, and the
+// returned function must be called once the operation is over.
+func (r *Registry) Start(kind string, description string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if r == nil {
+		return ctx, cancel
+	}
+
+	r.lock.Lock()
+	r.nextId++
+	id := r.nextId
+	r.ops[id] = &entry{
+		op: Operation{
+			Id:          id,
+			Kind:        kind,
+			Description: description,
+			Started:     time.Now(),
+		},
+		cancel: cancel,
+	}
+	r.lock.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			r.lock.Lock()
+			delete(r.ops, id)
+			r.lock.Unlock()
+			cancel()
+		})
+	}
+}
+
+// List returns the operations in flight, oldest first
+func (r *Registry) List() []Operation {
+	if r == nil {
+		return nil
+	}
+
+	r.lock.Lock()
+	res := make([]Operation, 0, len(r.ops))
+	for _, e := range r.ops {
+		res = append(res, e.op)
+	}
+	r.lock.Unlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Id < res[j].Id
+	})
+	return res
+}
+
+// Cancel cancels the operation with the specified id. The operation fails with context.Canceled.
+func (r *Registry) Cancel(id uint64) error {
+	if r == nil {
+		return ErrUnknownOperation
+	}
+
+	r.lock.Lock()
+	e, ok := r.ops[id]
+	delete(r.ops, id)
+	r.lock.Unlock()
+
+	if !ok {
+		return ErrUnknownOperation
+	}
+	e.cancel()
+	return nil
+}
@@ -0,0 +1,44 @@
+package ops
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	ctx1, done1 := r.Start(KindAudioDownload, "file 1")
+	_, done2 := r.Start(KindTokenRefresh, "streaming")
+
+	list := r.List()
+	if len(list) != 2 || list[0].Description != "file 1" || list[1].Kind != KindTokenRefresh {
+		t.Fatalf("Bad operations %v", list)
+	}
+
+	if err := r.Cancel(list[0].Id); err != nil {
+		t.Errorf("Cancel failed: %v", err)
+	}
+	if ctx1.Err() != context.Canceled {
+		t.Errorf("Operation context not cancelled")
+	}
+	if err := r.Cancel(list[0].Id); err != ErrUnknownOperation {
+		t.Errorf("Cancelled twice: %v", err)
+	}
+	done1()
+
+	done2()
+	done2()
+	if len(r.List()) != 0 {
+		t.Errorf("Finished operations still listed: %v", r.List())
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	ctx, done := r.Start(KindMercuryRequest, "GET hm://test")
+	if ctx.Err() != nil || r.List() != nil {
+		t.Errorf("Nil registry should not track anything")
+	}
+	done()
+}
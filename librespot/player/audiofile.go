@@ -1,13 +1,14 @@
 package player
 
 import (
-	"bytes"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/binary"
 	"fmt"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/ops"
 	"io"
 	"math"
 	"sync"
@@ -15,6 +16,13 @@ import (
 
 const kChunkSize = 32768 // In number of words (so actual byte size is kChunkSize*4, aka. kChunkByteSize)
 const kChunkByteSize = kChunkSize * 4
+
+// DefaultChunkSize is the default size of the audio chunks requested to the server, in bytes
+const DefaultChunkSize = kChunkByteSize
+
+// ChunkAlignment is the alignment chunk sizes must respect, in bytes. The audio data is encrypted by blocks of 4096
+// bytes, each with its own IV, so a chunk must start at the beginning of a block.
+const ChunkAlignment = 4096
 const kOggSkipBytes = 167 // Number of bytes to skip at the beginning of the file
 
 // min helper function for integers
@@ -31,34 +39,81 @@ type AudioFile struct {
 	lock           sync.RWMutex
 	format         Spotify.AudioFile_Format
 	fileId         []byte
+	trackId        []byte
 	player         *Player
 	cipher         cipher.Block
+	keyReady       chan struct{}
+	keyErr         error
+	header         *OggHeader
+	headerErr      error
+	headerReady    chan struct{}
 	decrypter      *AudioFileDecrypter
-	responseChan   chan []byte
+	ctx            context.Context
+	finish         func()
 	chunkLock      sync.RWMutex
+	chunkCond      *sync.Cond
 	chunkLoadOrder []int
 	data           []byte
 	cursor         int
+	chunkSize      int
 	chunks         map[int]bool
 	chunksLoading  bool
+	origin         PlayOrigin
+	loadErr        error
+	emitted        map[EventType]bool
+
+	// prefetching limits the download to the first chunk, until startDownload is called
+	prefetching bool
+	// urls are the HTTP locations of the file, which is downloaded through the channels if empty. They are protected
+	// by lock.
+	urls []string
+	// resolve resolves the CDN URLs of the file before its first chunk is downloaded, nil once done. If cdnOnly is
+	// set, the file doesn't fall back to the channels when the CDN fails.
+	resolve StorageResolver
+	cdnOnly bool
+	// plain is set for the files which are not encrypted
+	plain bool
+	// normalization is the normalization configuration of the player when the file was loaded
+	normalization *NormalizationConfig
+	// loadingChunk is the chunk being downloaded, whose download is cancelled by cancelChunk when a seek abandons
+	// its position. They are protected by chunkLock.
+	loadingChunk int
+	cancelChunk  context.CancelFunc
+	// cancelSeek cancels the seek in progress, superseded by a later one. It is protected by seekLock.
+	seekLock   sync.Mutex
+	cancelSeek context.CancelFunc
 }
 
+// AudioFile can be fed directly to any decoder expecting a seekable stream
+var _ io.ReadSeeker = (*AudioFile)(nil)
+
 func newAudioFile(file *Spotify.AudioFile, player *Player) *AudioFile {
 	return newAudioFileWithIdAndFormat(file.GetFileId(), file.GetFormat(), player)
 }
 
 func newAudioFileWithIdAndFormat(fileId []byte, format Spotify.AudioFile_Format, player *Player) *AudioFile {
-	return &AudioFile{
+	a := &AudioFile{
 		player:        player,
 		fileId:        fileId,
 		format:        format,
-		decrypter:     NewAudioFileDecrypter(),
-		size:          kChunkSize, // Set an initial size to fetch the first chunk regardless of the actual size
-		responseChan:  make(chan []byte),
+		keyReady:      make(chan struct{}),
+		headerReady:   make(chan struct{}),
+		decrypter:     NewAudioFileDecrypterWithBackend(player.DecryptionBackend()),
+		chunkSize:     player.ChunkSize(),
+		size:          uint32(player.ChunkSize()), // Set an initial size to fetch the first chunk regardless of the actual size
 		chunks:        map[int]bool{},
 		chunkLock:     sync.RWMutex{},
 		chunksLoading: false,
+		emitted:       map[EventType]bool{},
+		normalization: player.Normalization(),
+	}
+	a.chunkCond = sync.NewCond(&a.chunkLock)
+	a.ctx, a.finish = player.registry.Start(ops.KindAudioDownload, fmt.Sprintf("file %x", fileId))
+	if a.headerOffset() == 0 {
+		a.headerErr = ErrNotOgg
+		close(a.headerReady)
 	}
+	return a
 }
 
 // Size returns the size, in bytes, of the final audio file
@@ -66,9 +121,33 @@ func (a *AudioFile) Size() uint32 {
 	return a.size - uint32(a.headerOffset())
 }
 
-// Read is an implementation of the io.Reader interface. Note that due to the nature of the streaming, we may return
-// zero bytes when we are waiting for audio data from the Spotify servers, so make sure to wait for the io.EOF error
-// before stopping playback.
+// Format returns the encoding format of this audio file
+func (a *AudioFile) Format() Spotify.AudioFile_Format {
+	return a.format
+}
+
+// FormatInfo returns the human-readable codec and bitrate of this audio file
+func (a *AudioFile) FormatInfo() FormatInfo {
+	return DescribeFormat(a.format)
+}
+
+// Origin returns where the playback of this audio file comes from
+func (a *AudioFile) Origin() PlayOrigin {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.origin
+}
+
+// SetOrigin sets where the playback of this audio file comes from, used to attribute the play when reporting it
+func (a *AudioFile) SetOrigin(origin PlayOrigin) {
+	a.lock.Lock()
+	a.origin = origin
+	a.lock.Unlock()
+}
+
+// Read is an implementation of the io.Reader interface. If the data at the current position has not been downloaded
+// yet, Read blocks until it is available. It may return less than len(buf) bytes when the data spans over a chunk
+// that is still downloading.
 func (a *AudioFile) Read(buf []byte) (int, error) {
 	length := len(buf)
 	outBufCursor := 0
@@ -78,11 +157,14 @@ func (a *AudioFile) Read(buf []byte) (int, error) {
 	a.lock.RLock()
 	size := a.size
 	a.lock.RUnlock()
+	a.emit(EventTrackStart, nil)
+
 	// Offset the data start by the header, if needed
 	if a.cursor == 0 {
 		a.cursor += a.headerOffset()
 	} else if uint32(a.cursor) >= size {
 		// We're at the end
+		a.emit(EventTrackEnd, nil)
 		return 0, io.EOF
 	}
 
@@ -100,19 +182,32 @@ func (a *AudioFile) Read(buf []byte) (int, error) {
 			eof = true
 			break
 		} else if !a.hasChunk(chunkIdx) {
-			// A chunk we are looking to read is unavailable, request it so that we can return it on the next Read call
+			// A chunk we are looking to read is unavailable, request it
 			a.requestChunk(chunkIdx)
 			// fmt.Printf("[audiofile] Doesn't have chunk %d yet, queuing\n", chunkIdx)
-			break
+
+			if totalWritten > 0 {
+				// Return what we already have, the chunk will hopefully be there for the next Read call
+				break
+			}
+
+			// Nothing to return yet, wait for the chunk to be downloaded
+			a.emit(EventBufferingStart, nil)
+			err := a.waitChunk(chunkIdx)
+			a.emit(EventBufferingEnd, err)
+			if err != nil {
+				a.emit(EventError, err)
+				return 0, err
+			}
 		} else {
 			// cursorEnd is the ending position in the output buffer. It is either the current outBufCursor + the size
 			// of a chunk, in bytes, or the length of the buffer, whichever is smallest.
-			cursorEnd := min(outBufCursor+kChunkByteSize, length)
+			cursorEnd := min(outBufCursor+a.chunkSize, length)
 			writtenLen := cursorEnd - outBufCursor
 
 			// Calculate where our data cursor will end: either at the boundary of the current chunk, or the end
 			// of the song itself
-			dataCursorEnd := min(a.cursor+writtenLen, (chunkIdx+1)*kChunkByteSize)
+			dataCursorEnd := min(a.cursor+writtenLen, (chunkIdx+1)*a.chunkSize)
 			dataCursorEnd = min(dataCursorEnd, int(a.size))
 
 			writtenLen = dataCursorEnd - a.cursor
@@ -137,6 +232,7 @@ func (a *AudioFile) Read(buf []byte) (int, error) {
 	// The only error we can return here, is if we reach the end of the stream
 	var err error
 	if eof {
+		a.emit(EventTrackEnd, nil)
 		err = io.EOF
 	}
 
@@ -145,25 +241,37 @@ func (a *AudioFile) Read(buf []byte) (int, error) {
 
 // Seek implements the io.Seeker interface
 func (a *AudioFile) Seek(offset int64, whence int) (int64, error) {
+	a.lock.RLock()
+	size := int64(a.size)
+	a.lock.RUnlock()
+
+	var cursor int64
 	switch whence {
 	case io.SeekStart:
-		a.cursor = int(offset) + a.headerOffset()
+		cursor = offset + int64(a.headerOffset())
 
 	case io.SeekEnd:
-		a.cursor = int(int64(a.size) + offset)
+		cursor = size + offset
 
 	case io.SeekCurrent:
-		a.cursor += int(offset)
+		cursor = int64(a.cursor) + offset
+
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+
+	if cursor < int64(a.headerOffset()) {
+		return 0, fmt.Errorf("negative position")
 	}
 
-	return int64(a.cursor - a.headerOffset()), nil
+	a.cursor = int(cursor)
+	return cursor - int64(a.headerOffset()), nil
 }
 
 func (a *AudioFile) headerOffset() int {
-	// If the file format is an OGG, we skip the first kOggSkipBytes (167) bytes. We could implement despotify's
-	// SpotifyOggHeader (https://sourceforge.net/p/despotify/code/HEAD/tree/java/trunk/src/main/java/se/despotify/client/player/SpotifyOggHeader.java)
-	// to read Spotify's metadata (samples, length, gain, ...). For now, we simply skip the custom header to the actual
-	// OGG/Vorbis data.
+	// If the file format is an OGG, we skip the first kOggSkipBytes (167) bytes. This custom header holds Spotify's
+	// metadata (normalization gain, ...), exposed through OggHeader, and is skipped to get to the actual OGG/Vorbis
+	// data.
 	switch {
 	case a.format == Spotify.AudioFile_OGG_VORBIS_96 || a.format == Spotify.AudioFile_OGG_VORBIS_160 ||
 		a.format == Spotify.AudioFile_OGG_VORBIS_320:
@@ -175,7 +283,7 @@ func (a *AudioFile) headerOffset() int {
 }
 
 func (a *AudioFile) chunkIndexAtByte(byteIndex int) int {
-	return int(math.Floor(float64(byteIndex) / float64(kChunkSize) / 4.0))
+	return byteIndex / a.chunkSize
 }
 
 func (a *AudioFile) hasChunk(index int) bool {
@@ -186,15 +294,32 @@ func (a *AudioFile) hasChunk(index int) bool {
 	return has && ok
 }
 
+// waitChunk blocks until the specified chunk is available, or an error occurred while downloading the file
+func (a *AudioFile) waitChunk(index int) error {
+	a.chunkLock.Lock()
+	defer a.chunkLock.Unlock()
+
+	for !a.chunks[index] && a.loadErr == nil {
+		a.chunkCond.Wait()
+	}
+	return a.loadErr
+}
+
+// loadKey requests the audio key of the file. The chunks downloaded meanwhile are decrypted once it is received.
 func (a *AudioFile) loadKey(trackId []byte) error {
-	key, err := a.player.loadTrackKey(trackId, a.fileId)
+	defer close(a.keyReady)
+
+	key, err := a.player.GetAudioKey(trackId, a.fileId)
 	if err != nil {
-		fmt.Printf("[audiofile] Unable to load key: %s\n", err)
+		a.player.logger().Printf("[audiofile] Unable to load key: %s\n", err)
+		a.keyErr = err
+		a.emit(EventError, err)
 		return err
 	}
 
 	a.cipher, err = aes.NewCipher(key)
 	if err != nil {
+		a.keyErr = err
 		return err
 	}
 
@@ -205,7 +330,7 @@ func (a *AudioFile) totalChunks() int {
 	a.lock.RLock()
 	size := a.size
 	a.lock.RUnlock()
-	return int(math.Ceil(float64(size) / float64(kChunkSize) / 4.0))
+	return int(math.Ceil(float64(size) / float64(a.chunkSize)))
 }
 
 func (a *AudioFile) loadChunks() {
@@ -213,6 +338,14 @@ func (a *AudioFile) loadChunks() {
 	// we will prepend the chunks needed so that we load them as soon as possible. Since loadNextChunk will check
 	// if a chunk is already loaded (using hasChunk), we won't be downloading the same chunk multiple times.
 
+	// If the file is cached, we already know its size and can schedule all the chunks right away.
+	if cache := a.player.chunkCache; cache != nil && !a.plain {
+		if size, ok := cache.GetSize(a.fileId); ok {
+			a.setSize(size)
+			return
+		}
+	}
+
 	// We can however only download the first chunk for now, as we have no idea how many chunks this track has. The
 	// remaining chunks will be added once we get the headers with the file size.
 	a.chunkLoadOrder = append(a.chunkLoadOrder, 0)
@@ -220,6 +353,60 @@ func (a *AudioFile) loadChunks() {
 	go a.loadNextChunk()
 }
 
+// setSize sets the actual size of the file, in bytes, and schedules the loading of all its chunks
+func (a *AudioFile) setSize(size uint32) {
+	if a.size == size && a.data != nil {
+		return
+	}
+
+	a.lock.Lock()
+	a.size = size
+	if a.data == nil {
+		a.data = make([]byte, size)
+	}
+	a.lock.Unlock()
+
+	// Recalculate the number of chunks pending for load
+	a.chunkLock.Lock()
+	if a.prefetching {
+		a.chunkLoadOrder = append(a.chunkLoadOrder, 0)
+	} else {
+		for i := 0; i < a.totalChunks(); i++ {
+			a.chunkLoadOrder = append(a.chunkLoadOrder, i)
+		}
+	}
+	a.chunkLock.Unlock()
+
+	// Re-launch the chunk loading system. It will check itself if another goroutine is already loading chunks.
+	go a.loadNextChunk()
+}
+
+// startDownload schedules the download of all the chunks of a prefetched file
+func (a *AudioFile) startDownload() {
+	a.chunkLock.Lock()
+	if !a.prefetching {
+		a.chunkLock.Unlock()
+		return
+	}
+	a.prefetching = false
+	a.chunkLock.Unlock()
+
+	a.lock.RLock()
+	sizeKnown := a.data != nil
+	a.lock.RUnlock()
+	if !sizeKnown {
+		// setSize schedules all the chunks once the first one is received
+		return
+	}
+
+	a.chunkLock.Lock()
+	for i := 0; i < a.totalChunks(); i++ {
+		a.chunkLoadOrder = append(a.chunkLoadOrder, i)
+	}
+	a.chunkLock.Unlock()
+	go a.loadNextChunk()
+}
+
 func (a *AudioFile) requestChunk(chunkIndex int) {
 	a.chunkLock.RLock()
 
@@ -240,18 +427,53 @@ func (a *AudioFile) requestChunk(chunkIndex int) {
 	}
 
 	a.chunkLock.Unlock()
+
+	// Make sure the chunk loading system runs. It will check itself if another goroutine is already loading chunks.
+	go a.loadNextChunk()
 }
 
-func (a *AudioFile) loadChunk(chunkIndex int) error {
-	chunkData := make([]byte, kChunkByteSize)
+// loadChunk downloads a chunk, until the context is done
+func (a *AudioFile) loadChunk(ctx context.Context, chunkIndex int) error {
+	cache := a.player.chunkCache
+	if cache != nil && !a.plain {
+		if data, ok := cache.GetChunk(a.fileId, chunkIndex); ok {
+			return a.putEncryptedChunk(chunkIndex, data)
+		}
+	}
+	if err := a.resolveURLs(); err != nil {
+		return err
+	}
+	if len(a.cdnURLs()) > 0 {
+		err := a.loadChunkHTTP(ctx, chunkIndex)
+		if err == nil || a.plain || a.cdnOnly || ctx.Err() != nil {
+			return err
+		}
+		a.player.logger().Printf("[audiofile] Unable to download chunk %d from the CDN, using the channels: %s\n",
+			chunkIndex, err)
+		a.setURLs(nil)
+	}
+
+	chunkData := make([]byte, a.chunkSize)
 
 	channel := a.player.AllocateChannel()
 	channel.onHeader = a.onChannelHeader
-	channel.onData = a.onChannelData
+	responses := make(chan []byte)
+	channel.onData = func(channel *Channel, data []byte) {
+		// An empty slice signals the end of the chunk
+		if data == nil {
+			data = []byte{}
+		}
+		responses <- data
+	}
+	chunkErr := make(chan error, 1)
+	channel.onError = func(channel *Channel, err error) {
+		chunkErr <- err
+	}
 
-	chunkOffsetStart := uint32(chunkIndex * kChunkSize)
-	chunkOffsetEnd := uint32((chunkIndex + 1) * kChunkSize)
-	err := a.player.stream.SendPacket(connection.PacketStreamChunk, buildAudioChunkRequest(channel.num, a.fileId, chunkOffsetStart, chunkOffsetEnd))
+	// Offsets are expressed in 4-bytes words
+	chunkOffsetStart := uint32(chunkIndex * a.chunkSize / 4)
+	chunkOffsetEnd := uint32((chunkIndex + 1) * a.chunkSize / 4)
+	err := a.player.getStream().SendPacket(connection.PacketStreamChunk, buildAudioChunkRequest(channel.num, a.fileId, chunkOffsetStart, chunkOffsetEnd))
 
 	if err != nil {
 		return err
@@ -259,14 +481,39 @@ func (a *AudioFile) loadChunk(chunkIndex int) error {
 
 	chunkSz := 0
 
+	// While the header of the file is pending, also wait for the audio key, to parse the header from the data
+	// received so far as soon as the key arrives
+	var keyReady chan struct{}
+	if chunkIndex == 0 && a.headerPending() {
+		keyReady = a.keyReady
+	}
+
 	for {
-		chunk := <-a.responseChan
+		var chunk []byte
+		select {
+		case chunk = <-responses:
+		case err := <-chunkErr:
+			return err
+		case <-ctx.Done():
+			// Consume the rest of the chunk, so that the channel doesn't block the connection
+			go drainChunk(responses, chunkErr)
+			return ctx.Err()
+		case <-keyReady:
+			keyReady = nil
+			a.parsePartialHeader(chunkData[:chunkSz])
+			continue
+		}
 		chunkLen := len(chunk)
 
 		if chunkLen > 0 {
 			copy(chunkData[chunkSz:chunkSz+chunkLen], chunk)
 			chunkSz += chunkLen
 
+			// Parse the header as soon as possible, without waiting for the rest of the chunk
+			if chunkIndex == 0 {
+				a.parsePartialHeader(chunkData[:chunkSz])
+			}
+
 			// fmt.Printf("Read %d/%d of chunk %d\n", sz, expSize, i)
 		} else {
 			break
@@ -275,17 +522,30 @@ func (a *AudioFile) loadChunk(chunkIndex int) error {
 
 	// fmt.Printf("[AudioFile] Got encrypted chunk %d, len=%d...\n", i, len(wholeData))
 
-	a.putEncryptedChunk(chunkIndex, chunkData[0:chunkSz])
+	return a.storeChunk(chunkIndex, chunkData[0:chunkSz])
+}
 
-	return nil
+// storeChunk makes a downloaded chunk available to the readers, and caches it
+func (a *AudioFile) storeChunk(chunkIndex int, data []byte) error {
+	err := a.putEncryptedChunk(chunkIndex, data)
+	if err != nil {
+		return err
+	}
 
+	if cache := a.player.chunkCache; cache != nil && !a.plain {
+		if err := cache.PutChunk(a.fileId, chunkIndex, data); err != nil {
+			a.player.logger().Printf("[audiofile] Unable to cache chunk %d: %s\n", chunkIndex, err)
+		}
+	}
+
+	return nil
 }
 
 func (a *AudioFile) loadNextChunk() {
 	a.chunkLock.Lock()
 
-	if a.chunksLoading {
-		// We are already loading a chunk, don't need to start another goroutine
+	if a.chunksLoading || len(a.chunkLoadOrder) == 0 || a.ctx.Err() != nil {
+		// We are already loading a chunk, there is nothing left to load, or the download was cancelled
 		a.chunkLock.Unlock()
 		return
 	}
@@ -293,78 +553,104 @@ func (a *AudioFile) loadNextChunk() {
 	a.chunksLoading = true
 	chunkIndex := a.chunkLoadOrder[0]
 	a.chunkLoadOrder = a.chunkLoadOrder[1:]
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.loadingChunk, a.cancelChunk = chunkIndex, cancel
 
 	a.chunkLock.Unlock()
 
 	if !a.hasChunk(chunkIndex) {
-		a.loadChunk(chunkIndex)
+		err := a.loadChunk(ctx, chunkIndex)
+		if err != nil && a.ctx.Err() == nil && ctx.Err() != nil {
+			// The chunk was abandoned by a seek, which queued it again after the new position
+			err = nil
+		}
+		if err != nil {
+			cancel()
+			// Wake up the readers waiting for data, they will get the error
+			a.chunkLock.Lock()
+			a.loadErr = fmt.Errorf("failed to load chunk %d: %v", chunkIndex, err)
+			a.chunksLoading = false
+			a.cancelChunk = nil
+			a.chunkCond.Broadcast()
+			a.chunkLock.Unlock()
+			a.failHeader(a.loadErr)
+			a.finish()
+			return
+		}
 	}
 
+	cancel()
 	a.chunkLock.Lock()
 	a.chunksLoading = false
+	a.cancelChunk = nil
 
 	if len(a.chunkLoadOrder) > 0 {
 		a.chunkLock.Unlock()
 		a.loadNextChunk()
 	} else {
+		// The download is over once all the chunks are there
+		total := a.totalChunks()
+		complete := total > 0 && len(a.chunks) >= total
 		a.chunkLock.Unlock()
+		if complete {
+			a.finish()
+		}
 	}
 }
 
-func (a *AudioFile) putEncryptedChunk(index int, data []byte) {
-	byteIndex := index * kChunkByteSize
-	a.decrypter.DecryptAudioWithBlock(index, a.cipher, data, a.data[byteIndex:byteIndex+len(data)])
+// putEncryptedChunk decrypts the chunk and makes it available to readers, waiting for the audio key if needed
+func (a *AudioFile) putEncryptedChunk(index int, data []byte) error {
+	<-a.keyReady
+	if a.keyErr != nil {
+		return fmt.Errorf("no audio key: %v", a.keyErr)
+	}
+
+	byteIndex := index * a.chunkSize
+	a.decrypt(byteIndex, data, a.data[byteIndex:byteIndex+len(data)])
+	if index == 0 && !a.parseHeader(a.data[:len(data)]) {
+		a.failHeader(io.ErrUnexpectedEOF)
+	}
 
 	a.chunkLock.Lock()
 	a.chunks[index] = true
+	a.chunkCond.Broadcast()
 	a.chunkLock.Unlock()
+	return nil
 }
 
-func (a *AudioFile) onChannelHeader(channel *Channel, id byte, data *bytes.Reader) uint16 {
-	read := uint16(0)
+// decrypt decrypts data starting at the byte offset of the file into plain, or copies it if the file isn't encrypted
+func (a *AudioFile) decrypt(offset int, data []byte, plain []byte) []byte {
+	if a.plain {
+		return plain[:copy(plain, data)]
+	}
+	return a.decrypter.DecryptAudioAtOffset(offset, a.cipher, data, plain)
+}
 
-	if id == 0x3 {
-		var size uint32
-		binary.Read(data, binary.BigEndian, &size)
-		size *= 4
+func (a *AudioFile) onChannelHeader(channel *Channel, id byte, data []byte) {
+	if id == 0x3 && len(data) >= 4 {
+		size := binary.BigEndian.Uint32(data) * 4
 		// fmt.Printf("[AudioFile] Audio file size: %d bytes\n", size)
 
-		if a.size != size {
-			a.lock.Lock()
-			a.size = size
-			a.lock.Unlock()
-			if a.data == nil {
-				a.data = make([]byte, size)
+		if cache := a.player.chunkCache; cache != nil {
+			if err := cache.PutSize(a.fileId, size); err != nil {
+				a.player.logger().Printf("[audiofile] Unable to cache file size: %s\n", err)
 			}
-
-			// Recalculate the number of chunks pending for load
-			a.chunkLock.Lock()
-			for i := 0; i < a.totalChunks(); i++ {
-				a.chunkLoadOrder = append(a.chunkLoadOrder, i)
-			}
-			a.chunkLock.Unlock()
-
-			// Re-launch the chunk loading system. It will check itself if another goroutine is already loading chunks.
-			go a.loadNextChunk()
 		}
 
-		// Return 4 bytes read
-		read = 4
+		a.setSize(size)
 	}
-
-	return read
 }
 
-func (a *AudioFile) onChannelData(channel *Channel, data []byte) uint16 {
-	if data != nil {
-		a.responseChan <- data
-
-		return 0 // uint16(len(data))
-	} else {
-		// fmt.Printf("[AudioFile] Got EOF (nil) audio data on channel %d!\n", channel.num)
-		a.responseChan <- []byte{}
-
-		return 0
+// drainChunk discards the data of a chunk until its end, or an error
+func drainChunk(responses chan []byte, errs chan error) {
+	for {
+		select {
+		case data := <-responses:
+			if len(data) == 0 {
+				return
+			}
+		case <-errs:
+			return
+		}
 	}
-
 }
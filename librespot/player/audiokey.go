@@ -0,0 +1,115 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+)
+
+// DefaultAudioKeyTimeout is how long to wait for the audio key of a file by default
+const DefaultAudioKeyTimeout = 10 * time.Second
+
+// ErrAudioKeyTimeout is returned when the server didn't answer an audio key request in time
+var ErrAudioKeyTimeout = errors.New("audio key request timed out")
+
+// AudioKeyError is returned when the server refuses to send the key of a file, e.g. because the track is not
+// available in the country of the user
+type AudioKeyError struct {
+	Code uint16
+}
+
+func (e *AudioKeyError) Error() string {
+	return fmt.Sprintf("audio key error 0x%04x", e.Code)
+}
+
+type audioKeyResult struct {
+	key []byte
+	err error
+}
+
+// audioKeyManager sends the audio key requests, and matches the responses with the pending requests through their
+// sequence number, so that several keys can be requested concurrently
+type audioKeyManager struct {
+	stream func() connection.PacketStream
+
+	lock    sync.Mutex
+	seq     uint32
+	pending map[uint32]chan audioKeyResult
+}
+
+func newAudioKeyManager(stream func() connection.PacketStream) *audioKeyManager {
+	return &audioKeyManager{
+		stream:  stream,
+		pending: make(map[uint32]chan audioKeyResult),
+	}
+}
+
+// request sends a key request, and waits for its response until the timeout expires
+func (m *audioKeyManager) request(trackGid []byte, fileId []byte, timeout time.Duration) ([]byte, error) {
+	result := make(chan audioKeyResult, 1)
+
+	m.lock.Lock()
+	seq := m.seq
+	m.seq++
+	m.pending[seq] = result
+	m.lock.Unlock()
+
+	defer func() {
+		m.lock.Lock()
+		delete(m.pending, seq)
+		m.lock.Unlock()
+	}()
+
+	seqBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(seqBytes, seq)
+	err := m.stream().SendPacket(connection.PacketRequestKey, buildKeyRequest(seqBytes, trackGid, fileId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send audio key request: %v", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-result:
+		return res.key, res.err
+	case <-timer.C:
+		return nil, ErrAudioKeyTimeout
+	}
+}
+
+// handle passes the key, or key error, packet to the matching request
+func (m *audioKeyManager) handle(cmd connection.PacketType, data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("audio key packet too short: %d bytes", len(data))
+	}
+	seq := binary.BigEndian.Uint32(data[:4])
+
+	var res audioKeyResult
+	switch {
+	case cmd == connection.PacketAesKey && len(data) >= 20:
+		res.key = append([]byte{}, data[4:20]...)
+	case cmd == connection.PacketAesKeyError && len(data) >= 6:
+		res.err = &AudioKeyError{Code: binary.BigEndian.Uint16(data[4:6])}
+	default:
+		return fmt.Errorf("invalid audio key packet %v of %d bytes", cmd, len(data))
+	}
+
+	m.lock.Lock()
+	result, ok := m.pending[seq]
+	m.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("no pending audio key request for seq %d", seq)
+	}
+
+	// The channel is buffered, and only one response is expected per request
+	select {
+	case result <- res:
+	default:
+	}
+	return nil
+}
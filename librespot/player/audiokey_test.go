@@ -0,0 +1,108 @@
+package player_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// keyServer answers the audio key requests in the reverse order, the key being the track gid, refusing the tracks
+// starting with 0xee and ignoring the ones starting with 0xff
+type keyServer struct {
+	player *player.Player
+	wait   int
+
+	lock     sync.Mutex
+	requests [][]byte
+}
+
+func (k *keyServer) SendPacket(cmd connection.PacketType, data []byte) error {
+	if cmd != connection.PacketRequestKey {
+		return nil
+	}
+
+	k.lock.Lock()
+	k.requests = append(k.requests, append([]byte{}, data...))
+	if len(k.requests) < k.wait {
+		k.lock.Unlock()
+		return nil
+	}
+	requests := k.requests
+	k.requests = nil
+	k.lock.Unlock()
+
+	go func() {
+		for i := len(requests) - 1; i >= 0; i-- {
+			req := requests[i]
+			trackGid := req[len(testFileId) : len(testFileId)+16]
+			seq := req[len(testFileId)+16 : len(testFileId)+20]
+			switch trackGid[0] {
+			case 0xee:
+				k.player.HandleCmd(connection.PacketAesKeyError, append(append([]byte{}, seq...), 0x00, 0x01))
+			case 0xff:
+			default:
+				k.player.HandleCmd(connection.PacketAesKey, append(append([]byte{}, seq...), trackGid...))
+			}
+		}
+	}()
+	return nil
+}
+
+func (k *keyServer) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
+	select {}
+}
+
+func newKeyServer(wait int) *keyServer {
+	server := &keyServer{wait: wait}
+	server.player = player.CreatePlayer(server, mercury.CreateMercury(server))
+	return server
+}
+
+func TestGetAudioKeyConcurrent(t *testing.T) {
+	const n = 8
+	server := newKeyServer(n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trackGid := bytes.Repeat([]byte{byte(i)}, 16)
+			key, err := server.player.GetAudioKey(trackGid, testFileId)
+			if err != nil {
+				t.Error(err)
+			} else if !bytes.Equal(key, trackGid) {
+				t.Errorf("Request %d got key %x", i, key)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestGetAudioKeyError(t *testing.T) {
+	server := newKeyServer(1)
+
+	_, err := server.player.GetAudioKey(bytes.Repeat([]byte{0xee}, 16), testFileId)
+	if keyErr, ok := err.(*player.AudioKeyError); !ok || keyErr.Code != 1 {
+		t.Errorf("Expected key error 1, got %v", err)
+	}
+}
+
+func TestGetAudioKeyTimeout(t *testing.T) {
+	server := newKeyServer(1)
+	server.player.SetAudioKeyTimeout(50 * time.Millisecond)
+
+	if _, err := server.player.GetAudioKey(bytes.Repeat([]byte{0xff}, 16), testFileId); err != player.ErrAudioKeyTimeout {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	// Later requests are not affected
+	if _, err := server.player.GetAudioKey(testTrackId, testFileId); err != nil {
+		t.Error(err)
+	}
+}
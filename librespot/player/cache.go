@@ -0,0 +1,282 @@
+package player
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/fischerling/librespot-golang/librespot/storage"
+)
+
+// ChunkCache stores downloaded audio chunks, so that playing a track again or seeking into it doesn't download the
+// same data again. Chunks are stored encrypted, as received from the Spotify servers, and keyed by file id and chunk
+// index. The total size of the file is stored alongside, as it is only known once the first chunk is received.
+//
+// Implementations must be safe for concurrent use. Custom implementations can be used to store chunks on any
+// storage (S3, bolt, ...).
+type ChunkCache interface {
+	GetChunk(fileId []byte, index int) ([]byte, bool)
+	PutChunk(fileId []byte, index int, data []byte) error
+	GetSize(fileId []byte) (uint32, bool)
+	PutSize(fileId []byte, size uint32) error
+}
+
+type chunkKey struct {
+	fileId string
+	index  int
+}
+
+type memoryChunk struct {
+	key  chunkKey
+	data []byte
+}
+
+// MemoryChunkCache is an in-memory ChunkCache, evicting the least recently used chunks once it holds more than
+// maxBytes of data.
+type MemoryChunkCache struct {
+	lock     sync.Mutex
+	maxBytes int
+	curBytes int
+	order    *list.List
+	chunks   map[chunkKey]*list.Element
+	sizes    map[string]uint32
+}
+
+// NewMemoryChunkCache creates an in-memory cache holding at most maxBytes of audio data
+func NewMemoryChunkCache(maxBytes int) *MemoryChunkCache {
+	return &MemoryChunkCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		chunks:   make(map[chunkKey]*list.Element),
+		sizes:    make(map[string]uint32),
+	}
+}
+
+func (c *MemoryChunkCache) GetChunk(fileId []byte, index int) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.chunks[chunkKey{hex.EncodeToString(fileId), index}]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryChunk).data, true
+}
+
+func (c *MemoryChunkCache) PutChunk(fileId []byte, index int, data []byte) error {
+	if len(data) > c.maxBytes {
+		return nil
+	}
+
+	key := chunkKey{hex.EncodeToString(fileId), index}
+	stored := make([]byte, len(data))
+	copy(stored, data)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.chunks[key]; ok {
+		chunk := elem.Value.(*memoryChunk)
+		c.curBytes += len(stored) - len(chunk.data)
+		chunk.data = stored
+		c.order.MoveToFront(elem)
+	} else {
+		c.chunks[key] = c.order.PushFront(&memoryChunk{key: key, data: stored})
+		c.curBytes += len(stored)
+	}
+
+	for c.curBytes > c.maxBytes {
+		oldest := c.order.Back()
+		chunk := oldest.Value.(*memoryChunk)
+		c.order.Remove(oldest)
+		delete(c.chunks, chunk.key)
+		c.curBytes -= len(chunk.data)
+	}
+
+	return nil
+}
+
+func (c *MemoryChunkCache) GetSize(fileId []byte) (uint32, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	size, ok := c.sizes[hex.EncodeToString(fileId)]
+	return size, ok
+}
+
+func (c *MemoryChunkCache) PutSize(fileId []byte, size uint32) error {
+	c.lock.Lock()
+	c.sizes[hex.EncodeToString(fileId)] = size
+	c.lock.Unlock()
+	return nil
+}
+
+// BlobChunkCache is a ChunkCache storing every chunk as a blob, keyed by the hex file id and the chunk index, e.g.
+// in the database of the embedder. The entries are stored with a checksum, and the corrupt ones, e.g. written during
+// a power loss, are evicted once read or verified.
+type BlobChunkCache struct {
+	blobs storage.Blobs
+	log   *log.Logger
+}
+
+// NewBlobChunkCache creates a cache storing the chunks in blobs
+func NewBlobChunkCache(blobs storage.Blobs) *BlobChunkCache {
+	return &BlobChunkCache{blobs: blobs}
+}
+
+// SetLogger sets the logger receiving the evictions of the corrupt entries, the standard logger being used if nil. It
+// must be called before using the cache.
+func (c *BlobChunkCache) SetLogger(logger *log.Logger) {
+	c.log = logger
+}
+
+// logger returns the logger of the cache, see SetLogger
+func (c *BlobChunkCache) logger() *log.Logger {
+	if c.log == nil {
+		return log.Default()
+	}
+	return c.log
+}
+
+// entryMagic starts the entries of a BlobChunkCache, followed by the CRC-32 of the data
+const entryMagic = "LSC1"
+
+// encodeEntry returns the data of an entry with its header
+func encodeEntry(data []byte) []byte {
+	entry := make([]byte, 8+len(data))
+	copy(entry, entryMagic)
+	binary.BigEndian.PutUint32(entry[4:], crc32.ChecksumIEEE(data))
+	copy(entry[8:], data)
+	return entry
+}
+
+// decodeEntry returns the data of an entry, and false if it is corrupt
+func decodeEntry(entry []byte) ([]byte, bool) {
+	if len(entry) < 8 || string(entry[:4]) != entryMagic {
+		return nil, false
+	}
+	data := entry[8:]
+	return data, binary.BigEndian.Uint32(entry[4:]) == crc32.ChecksumIEEE(data)
+}
+
+// get returns the data of an entry, evicting it if it is corrupt
+func (c *BlobChunkCache) get(key string) ([]byte, bool) {
+	r, err := c.blobs.Open(key)
+	if err != nil {
+		return nil, false
+	}
+	entry, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, false
+	}
+	data, ok := decodeEntry(entry)
+	if !ok {
+		c.logger().Printf("[cache] Evicting the corrupt entry %s", key)
+		c.blobs.Remove(key)
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *BlobChunkCache) put(key string, data []byte) error {
+	return c.blobs.Write(key, bytes.NewReader(encodeEntry(data)))
+}
+
+func (c *BlobChunkCache) GetChunk(fileId []byte, index int) ([]byte, bool) {
+	return c.get(fmt.Sprintf("%x/%d.chunk", fileId, index))
+}
+
+func (c *BlobChunkCache) PutChunk(fileId []byte, index int, data []byte) error {
+	return c.put(fmt.Sprintf("%x/%d.chunk", fileId, index), data)
+}
+
+func (c *BlobChunkCache) GetSize(fileId []byte) (uint32, bool) {
+	key := fmt.Sprintf("%x/size", fileId)
+	data, ok := c.get(key)
+	if !ok {
+		return 0, false
+	} else if len(data) != 4 {
+		c.blobs.Remove(key)
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(data), true
+}
+
+func (c *BlobChunkCache) PutSize(fileId []byte, size uint32) error {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, size)
+	return c.put(fmt.Sprintf("%x/size", fileId), data)
+}
+
+// CacheReport is the result of the verification of a cache
+type CacheReport struct {
+	// Checked is the number of entries verified
+	Checked int
+	// Evicted is the number of corrupt entries removed
+	Evicted int
+}
+
+// Verifier is implemented by the caches which can verify their entries, e.g. on startup
+type Verifier interface {
+	Verify() (CacheReport, error)
+}
+
+// ErrNotListable is returned when verifying a cache whose store can't list its keys, see storage.Lister
+var ErrNotListable = errors.New("the keys of the store can't be listed")
+
+// Verify reads all the entries, and evicts the corrupt ones. The blobs must implement storage.Lister.
+func (c *BlobChunkCache) Verify() (CacheReport, error) {
+	var report CacheReport
+	lister, ok := c.blobs.(storage.Lister)
+	if !ok {
+		return report, ErrNotListable
+	}
+	keys, err := lister.Keys("")
+	if err != nil {
+		return report, err
+	}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".chunk") && !strings.HasSuffix(key, "/size") {
+			continue
+		}
+		report.Checked++
+		var valid bool
+		if strings.HasSuffix(key, "/size") {
+			data, ok := c.get(key)
+			valid = ok && len(data) == 4
+			if ok && !valid {
+				c.blobs.Remove(key)
+			}
+		} else {
+			_, valid = c.get(key)
+		}
+		if !valid {
+			report.Evicted++
+		}
+	}
+	return report, nil
+}
+
+// DiskChunkCache is a ChunkCache storing chunks as files, in a directory per audio file
+type DiskChunkCache struct {
+	BlobChunkCache
+}
+
+// NewDiskChunkCache creates a cache storing the chunks in the specified directory, creating it if needed
+func NewDiskChunkCache(dir string) (*DiskChunkCache, error) {
+	blobs, err := storage.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &DiskChunkCache{BlobChunkCache{blobs: blobs}}, nil
+}
@@ -0,0 +1,124 @@
+package player_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+func TestMemoryChunkCacheEviction(t *testing.T) {
+	cache := player.NewMemoryChunkCache(8)
+	id := []byte{1, 2, 3}
+
+	cache.PutChunk(id, 0, []byte{0, 0, 0, 0})
+	cache.PutChunk(id, 1, []byte{1, 1, 1, 1})
+	// Use chunk 0, so that chunk 1 becomes the least recently used one
+	if _, ok := cache.GetChunk(id, 0); !ok {
+		t.Fatal("chunk 0 missing")
+	}
+	cache.PutChunk(id, 2, []byte{2, 2, 2, 2})
+
+	if _, ok := cache.GetChunk(id, 1); ok {
+		t.Errorf("chunk 1 should have been evicted")
+	}
+	if data, ok := cache.GetChunk(id, 2); !ok || !bytes.Equal(data, []byte{2, 2, 2, 2}) {
+		t.Errorf("chunk 2: got %v, %v", data, ok)
+	}
+	if _, ok := cache.GetChunk([]byte{4}, 0); ok {
+		t.Errorf("chunk of another file should not be found")
+	}
+}
+
+func TestDiskChunkCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunkcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := player.NewDiskChunkCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := []byte{0xca, 0xfe}
+
+	if _, ok := cache.GetSize(id); ok {
+		t.Errorf("size of an unknown file should not be found")
+	}
+	if err := cache.PutSize(id, 123456); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.PutChunk(id, 3, []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+
+	if size, ok := cache.GetSize(id); !ok || size != 123456 {
+		t.Errorf("size: got %d, %v", size, ok)
+	}
+	if data, ok := cache.GetChunk(id, 3); !ok || string(data) != "data" {
+		t.Errorf("chunk 3: got %q, %v", data, ok)
+	}
+}
+
+func TestDiskChunkCacheVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunkcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := player.NewDiskChunkCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	cache.SetLogger(log.New(&logs, "", 0))
+	id := []byte{0xca, 0xfe}
+	for i := 0; i < 3; i++ {
+		if err := cache.PutChunk(id, i, []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.PutSize(id, 12); err != nil {
+		t.Fatal(err)
+	}
+
+	// A chunk is corrupted, and another one truncated by a power loss
+	chunk := filepath.Join(dir, "cafe", "1.chunk")
+	data, _ := ioutil.ReadFile(chunk)
+	data[len(data)-1] ^= 0xff
+	if err := ioutil.WriteFile(chunk, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(filepath.Join(dir, "cafe", "2.chunk"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := cache.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 4 || report.Evicted != 2 {
+		t.Errorf("Got report %+v", report)
+	}
+	if !strings.Contains(logs.String(), "Evicting the corrupt entry cafe/1.chunk") {
+		t.Errorf("Eviction not logged: %q", logs.String())
+	}
+	if _, ok := cache.GetChunk(id, 0); !ok {
+		t.Error("Valid chunk evicted")
+	}
+	for _, name := range []string{"1.chunk", "2.chunk"} {
+		if _, err := os.Stat(filepath.Join(dir, "cafe", name)); !os.IsNotExist(err) {
+			t.Errorf("Corrupt chunk %s kept: %v", name, err)
+		}
+	}
+	if size, ok := cache.GetSize(id); !ok || size != 12 {
+		t.Errorf("Size: got %d, %v", size, ok)
+	}
+}
@@ -1,19 +1,43 @@
 package player
 
 import (
-	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
 )
 
-type headerFunc func(channel *Channel, id byte, data *bytes.Reader) uint16
-type dataFunc func(channel *Channel, data []byte) uint16
+// ErrChannelReset is returned by the channels closed by ChannelManager.Reset
+var ErrChannelReset = errors.New("channel reset")
+
+// ChannelError is returned when the server aborts a channel, e.g. because the requested resource doesn't exist
+type ChannelError struct {
+	Channel uint16
+	Code    uint16
+}
+
+func (e *ChannelError) Error() string {
+	return fmt.Sprintf("channel %d error 0x%04x", e.Channel, e.Code)
+}
+
+type headerFunc func(channel *Channel, id byte, data []byte)
+type dataFunc func(channel *Channel, data []byte)
+type errorFunc func(channel *Channel, err error)
 type releaseFunc func(channel *Channel)
 
+// Channel receives the response to a request sent over the channel protocol: a first packet of headers, followed by
+// data packets, and an empty packet at the end. onData is called with nil data at the end of the channel.
 type Channel struct {
 	num       uint16
 	dataMode  bool
 	onHeader  headerFunc
 	onData    dataFunc
+	onError   errorFunc
 	onRelease releaseFunc
 }
 
@@ -25,60 +49,269 @@ func NewChannel(num uint16, release releaseFunc) *Channel {
 	}
 }
 
-func (c *Channel) handlePacket(data []byte) {
-	dataReader := bytes.NewReader(data)
+// Num returns the number identifying the channel in the requests
+func (c *Channel) Num() uint16 {
+	return c.num
+}
 
+func (c *Channel) handlePacket(data []byte) {
 	if !c.dataMode {
-		// Read the header
-		// fmt.Printf("[channel] Reading in header mode, size=%d\n", dataReader.Len())
-
-		length := uint16(0)
-		var err error = nil
-		for err == nil {
-			err = binary.Read(dataReader, binary.BigEndian, &length)
-
-			if err != nil {
+		// The headers are made of a 2 bytes length, including the header id byte, and are terminated by the end of
+		// the packet or an empty header
+		for len(data) >= 2 {
+			length := int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+			if length == 0 {
 				break
 			}
+			if length > len(data) {
+				c.fail(fmt.Errorf("channel %d header of %d bytes truncated to %d", c.num, length, len(data)))
+				return
+			}
 
-			// fmt.Printf("[channel] Header part length: %d\n", length)
-
-			if length > 0 {
-				var headerId uint8
-				binary.Read(dataReader, binary.BigEndian, &headerId)
-
-				// fmt.Printf("[channel] Header ID: 0x%x\n", headerId)
-
-				read := uint16(0)
-				if c.onHeader != nil {
-					read = c.onHeader(c, headerId, dataReader)
-				}
-
-				// Consume the remaining un-read data
-				dataReader.Read(make([]byte, length-read))
+			if c.onHeader != nil {
+				c.onHeader(c, data[0], data[1:length])
 			}
+			data = data[length:]
 		}
 
 		if c.onData != nil {
-			// fmt.Printf("[channel] Switching channel to dataMode\n")
 			c.dataMode = true
 		} else {
 			c.onRelease(c)
 		}
-	} else {
-		// fmt.Printf("[channel] Reading in dataMode\n")
+	} else if len(data) == 0 {
+		if c.onData != nil {
+			c.onData(c, nil)
+		}
+		c.onRelease(c)
+	} else if c.onData != nil {
+		c.onData(c, data)
+	}
+}
 
-		if len(data) == 0 {
-			if c.onData != nil {
-				c.onData(c, nil)
-			}
+// fail releases the channel, and reports the error
+func (c *Channel) fail(err error) {
+	c.onRelease(c)
+	if c.onError != nil {
+		c.onError(c, err)
+	}
+}
 
-			c.onRelease(c)
-		} else {
-			if c.onData != nil {
-				c.onData(c, data)
+// ChannelManager allocates the channels through which the server streams resources (audio chunks, cover art), and
+// dispatches the packets it receives to them
+type ChannelManager struct {
+	// lastData is the time data was last received, in unix nanoseconds. It is first for 64-bit alignment.
+	lastData int64
+
+	stream func() connection.PacketStream
+
+	lock     sync.Mutex
+	channels map[uint16]*Channel
+	nextChan uint16
+}
+
+func newChannelManager(stream func() connection.PacketStream) *ChannelManager {
+	return &ChannelManager{
+		stream:   stream,
+		channels: map[uint16]*Channel{},
+	}
+}
+
+// Allocate reserves a new channel number. The channel is released once it received its last packet.
+func (m *ChannelManager) Allocate() *Channel {
+	m.lock.Lock()
+	channel := NewChannel(m.nextChan, m.release)
+	m.nextChan++
+	m.channels[channel.num] = channel
+	m.lock.Unlock()
+
+	atomic.StoreInt64(&m.lastData, time.Now().UnixNano())
+	return channel
+}
+
+func (m *ChannelManager) release(channel *Channel) {
+	m.lock.Lock()
+	if m.channels[channel.num] == channel {
+		delete(m.channels, channel.num)
+	}
+	m.lock.Unlock()
+}
+
+// Handle dispatches a channel data (0x09) or error (0x0a) packet to its channel
+func (m *ChannelManager) Handle(cmd connection.PacketType, data []byte) error {
+	if len(data) < 2 {
+		return fmt.Errorf("channel packet too short: %d bytes", len(data))
+	}
+	num := binary.BigEndian.Uint16(data)
+	atomic.StoreInt64(&m.lastData, time.Now().UnixNano())
+
+	m.lock.Lock()
+	channel, ok := m.channels[num]
+	m.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown channel %d", num)
+	}
+
+	switch cmd {
+	case connection.PacketStreamChunkRes:
+		channel.handlePacket(data[2:])
+	case connection.PacketChannelError:
+		var code uint16
+		if len(data) >= 4 {
+			code = binary.BigEndian.Uint16(data[2:])
+		}
+		channel.fail(&ChannelError{Channel: num, Code: code})
+	default:
+		return fmt.Errorf("unexpected channel packet %v", cmd)
+	}
+	return nil
+}
+
+// Reset fails all the open channels with ErrChannelReset, e.g. when their responses won't come
+func (m *ChannelManager) Reset() {
+	m.lock.Lock()
+	channels := m.channels
+	m.channels = map[uint16]*Channel{}
+	m.lock.Unlock()
+
+	for _, channel := range channels {
+		if channel.onError != nil {
+			channel.onError(channel, ErrChannelReset)
+		}
+	}
+}
+
+// lastActivity returns the time data was last received, and whether channels are open
+func (m *ChannelManager) lastActivity() (time.Time, bool) {
+	m.lock.Lock()
+	busy := len(m.channels) > 0
+	m.lock.Unlock()
+
+	return time.Unix(0, atomic.LoadInt64(&m.lastData)), busy
+}
+
+// RequestAudioChunk requests the encrypted audio data of a file between start and end, expressed in 4-bytes words.
+// The first header of id 0x3 holds the size of the whole file in words.
+func (m *ChannelManager) RequestAudioChunk(fileId []byte, start uint32, end uint32) (*ChannelReader, error) {
+	return m.Request(connection.PacketStreamChunk, func(num uint16) []byte {
+		return buildAudioChunkRequest(num, fileId, start, end)
+	})
+}
+
+// RequestImage requests the cover art with the specified file id
+func (m *ChannelManager) RequestImage(fileId []byte) (*ChannelReader, error) {
+	return m.Request(connection.PacketImage, func(num uint16) []byte {
+		return buildImageRequest(num, fileId)
+	})
+}
+
+// Request allocates a channel, and sends the request built for its number as a packet of type cmd. It lets the
+// storage requests the server answers over a channel, other than the audio chunks and cover art, be sent: the
+// request must start with the channel number, big-endian, and the response is read from the returned reader.
+func (m *ChannelManager) Request(cmd connection.PacketType, build func(num uint16) []byte) (*ChannelReader, error) {
+	channel := m.Allocate()
+	reader := newChannelReader(channel)
+
+	if err := m.stream().SendPacket(cmd, build(channel.num)); err != nil {
+		m.release(channel)
+		return nil, fmt.Errorf("failed to send channel request: %v", err)
+	}
+	return reader, nil
+}
+
+// ChannelReader reads the data received on a channel. The data is buffered as it arrives, so that a slow reader
+// doesn't block the connection.
+type ChannelReader struct {
+	channel *Channel
+
+	lock        sync.Mutex
+	cond        *sync.Cond
+	headers     map[byte][]byte
+	headersDone bool
+	pending     []byte
+	err         error
+}
+
+func newChannelReader(channel *Channel) *ChannelReader {
+	r := &ChannelReader{
+		channel: channel,
+		headers: map[byte][]byte{},
+	}
+	r.cond = sync.NewCond(&r.lock)
+
+	channel.onHeader = func(channel *Channel, id byte, data []byte) {
+		r.lock.Lock()
+		r.headers[id] = append([]byte{}, data...)
+		r.lock.Unlock()
+	}
+	channel.onData = func(channel *Channel, data []byte) {
+		r.lock.Lock()
+		r.headersDone = true
+		if data == nil {
+			if r.err == nil {
+				r.err = io.EOF
 			}
+		} else if r.err == nil {
+			r.pending = append(r.pending, data...)
 		}
+		r.cond.Broadcast()
+		r.lock.Unlock()
+	}
+	channel.onError = func(channel *Channel, err error) {
+		r.setErr(err)
 	}
+	return r
+}
+
+func (r *ChannelReader) setErr(err error) {
+	r.lock.Lock()
+	r.headersDone = true
+	if r.err == nil || r.err == io.EOF && len(r.pending) == 0 {
+		r.err = err
+	}
+	r.cond.Broadcast()
+	r.lock.Unlock()
+}
+
+// Header waits for the headers of the channel, and returns the one with the specified id
+func (r *ChannelReader) Header(id byte) ([]byte, bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for !r.headersDone {
+		r.cond.Wait()
+	}
+	if r.err != nil && r.err != io.EOF {
+		return nil, false, r.err
+	}
+	header, ok := r.headers[id]
+	return header, ok, nil
+}
+
+// Read reads the data of the channel, returning io.EOF at its end, or the error which aborted the channel
+func (r *ChannelReader) Read(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for len(r.pending) == 0 && r.err == nil {
+		r.cond.Wait()
+	}
+	if len(r.pending) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Close stops receiving the data of the channel, the rest of it is discarded
+func (r *ChannelReader) Close() error {
+	r.lock.Lock()
+	r.pending = nil
+	r.lock.Unlock()
 
+	r.setErr(io.ErrClosedPipe)
+	return nil
 }
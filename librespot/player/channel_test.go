@@ -0,0 +1,124 @@
+package player_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// channelServer records the channel requests, answered by the tests
+type channelServer struct {
+	cmds     []connection.PacketType
+	requests [][]byte
+}
+
+func (c *channelServer) SendPacket(cmd connection.PacketType, data []byte) error {
+	c.cmds = append(c.cmds, cmd)
+	c.requests = append(c.requests, data)
+	return nil
+}
+
+func (c *channelServer) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
+	select {}
+}
+
+func channelPacket(num []byte, data ...byte) []byte {
+	return append(append([]byte{}, num...), data...)
+}
+
+func TestChannelReader(t *testing.T) {
+	server := &channelServer{}
+	p := player.CreatePlayer(server, mercury.CreateMercury(server))
+
+	reader, err := p.Channels().RequestImage(testFileId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.cmds[0] != connection.PacketImage || !bytes.Equal(server.requests[0][4:], testFileId) {
+		t.Fatalf("Bad image request 0x%x %x", server.cmds[0], server.requests[0])
+	}
+	num := server.requests[0][:2]
+
+	// Two headers, terminated by an empty one, then the data in two packets
+	p.HandleCmd(connection.PacketStreamChunkRes, channelPacket(num, 0, 3, 0x1, 0xa, 0xb, 0, 2, 0x2, 0xc, 0, 0))
+	p.HandleCmd(connection.PacketStreamChunkRes, channelPacket(num, []byte("hello ")...))
+	p.HandleCmd(connection.PacketStreamChunkRes, channelPacket(num, []byte("world")...))
+	p.HandleCmd(connection.PacketStreamChunkRes, num)
+
+	if header, ok, err := reader.Header(0x1); err != nil || !ok || !bytes.Equal(header, []byte{0xa, 0xb}) {
+		t.Errorf("Bad header 0x1: %x %v %v", header, ok, err)
+	}
+	if header, ok, _ := reader.Header(0x2); !ok || !bytes.Equal(header, []byte{0xc}) {
+		t.Errorf("Bad header 0x2: %x", header)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("Read %q, %v", data, err)
+	}
+
+	// The channel was released
+	if err := p.Channels().Handle(connection.PacketStreamChunkRes, num); err == nil {
+		t.Errorf("Expected an unknown channel")
+	}
+}
+
+func TestChannelError(t *testing.T) {
+	server := &channelServer{}
+	p := player.CreatePlayer(server, mercury.CreateMercury(server))
+
+	reader, err := p.Channels().RequestAudioChunk(testFileId, 0, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	num := server.requests[0][:2]
+	if server.cmds[0] != connection.PacketStreamChunk || binary.BigEndian.Uint32(server.requests[0][len(server.requests[0])-4:]) != 16 {
+		t.Fatalf("Bad chunk request 0x%x %x", server.cmds[0], server.requests[0])
+	}
+
+	p.HandleCmd(connection.PacketChannelError, channelPacket(num, 0, 2))
+
+	_, err = ioutil.ReadAll(reader)
+	if chanErr, ok := err.(*player.ChannelError); !ok || chanErr.Code != 2 {
+		t.Errorf("Expected channel error 2, got %v", err)
+	}
+	if _, _, err := reader.Header(0x3); err == nil {
+		t.Errorf("Expected the channel error from Header")
+	}
+}
+
+func TestChannelRequest(t *testing.T) {
+	server := &channelServer{}
+	p := player.CreatePlayer(server, mercury.CreateMercury(server))
+
+	const cmd = connection.PacketType(0x0f)
+	reader, err := p.Channels().Request(cmd, func(num uint16) []byte {
+		req := make([]byte, 2, 6)
+		binary.BigEndian.PutUint16(req, num)
+		return append(req, "blob"...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.cmds[0] != cmd || string(server.requests[0][2:]) != "blob" {
+		t.Fatalf("Bad request 0x%x %x", server.cmds[0], server.requests[0])
+	}
+	num := server.requests[0][:2]
+
+	// No headers
+	p.HandleCmd(connection.PacketStreamChunkRes, channelPacket(num, 0, 0))
+	p.HandleCmd(connection.PacketStreamChunkRes, channelPacket(num, []byte("response")...))
+	p.HandleCmd(connection.PacketStreamChunkRes, num)
+
+	if _, ok, err := reader.Header(0x3); err != nil || ok {
+		t.Errorf("Unexpected header: %v %v", ok, err)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil || string(data) != "response" {
+		t.Errorf("Read %q, %v", data, err)
+	}
+}
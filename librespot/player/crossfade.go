@@ -0,0 +1,205 @@
+package player
+
+import (
+	"io"
+	"time"
+)
+
+// FrameDecoder decodes an audio file to PCM frames, each sample holding a value between -1 and 1 per channel, e.g. a
+// vorbis.Decoder
+type FrameDecoder interface {
+	// ReadFrame returns the next samples, and io.EOF at the end of the file
+	ReadFrame() ([][]float32, error)
+	Close() error
+}
+
+// DecoderFunc opens a decoder of an audio file of a queue, e.g. with vorbis.NewDecoder
+type DecoderFunc func(file *AudioFile) (FrameDecoder, error)
+
+// Crossfader reads the tracks of a queue as PCM frames, mixing the end of each track with the start of the next one
+// over the crossfade duration of the queue, see Queue.SetCrossfade. The output lags behind the decoders by that
+// duration, as the end of a track is only known once it is decoded. Without crossfade, the tracks are delivered back
+// to back, like by Queue.Read.
+//
+// The queue must only be read through the crossfader. It is not safe for concurrent use.
+type Crossfader struct {
+	queue      *Queue
+	decode     DecoderFunc
+	sampleRate int
+
+	file    *AudioFile
+	decoder FrameDecoder
+	// err ends the current track once its buffered samples are played, if the decoder failed while mixing
+	err error
+	// tail holds the last samples of the current track, up to the crossfade duration, mixed with the start of the
+	// next track once it ends
+	tail [][]float32
+}
+
+// NewCrossfader creates a crossfader reading the tracks of the queue with the decoders opened by decode. The
+// sampleRate of the tracks converts the crossfade duration to samples.
+func (q *Queue) NewCrossfader(decode DecoderFunc, sampleRate int) *Crossfader {
+	return &Crossfader{
+		queue:      q,
+		decode:     decode,
+		sampleRate: sampleRate,
+	}
+}
+
+// ReadFrame returns the next samples of the queue, and io.EOF after the last track. The tracks failing to load or
+// to decode are skipped, until too many fail in a row and a FailuresError is returned, like by Queue.Read.
+func (c *Crossfader) ReadFrame() ([][]float32, error) {
+	for {
+		if c.decoder == nil {
+			if err := c.start(); err == io.EOF && len(c.tail) > 0 {
+				// The end of the last track isn't mixed with anything
+				tail := c.tail
+				c.tail = nil
+				return tail, nil
+			} else if err != nil {
+				c.tail = nil
+				return nil, err
+			}
+		}
+
+		frame, err := c.read()
+		if err == nil {
+			c.queue.succeeded()
+			if out := c.buffer(frame); len(out) > 0 {
+				return out, nil
+			}
+			continue
+		}
+
+		c.closeDecoder()
+		if err == io.EOF {
+			err = c.queue.Advance()
+		} else {
+			err = c.queue.skip(c.file, err)
+		}
+		if err == ErrEndOfQueue {
+			continue
+		} else if err != nil {
+			c.tail = nil
+			return nil, err
+		}
+		if len(c.tail) == 0 {
+			continue
+		}
+		if err := c.start(); err != nil && err != io.EOF {
+			c.tail = nil
+			return nil, err
+		} else if err == nil {
+			return c.mix(), nil
+		}
+	}
+}
+
+// Close closes the decoder of the current track
+func (c *Crossfader) Close() error {
+	if c.decoder == nil {
+		return nil
+	}
+	err := c.decoder.Close()
+	c.decoder = nil
+	return err
+}
+
+// start opens the decoder of the current track of the queue, starting the playback if needed, and skipping the tracks
+// failing to decode. It returns io.EOF at the end of the queue.
+func (c *Crossfader) start() error {
+	for {
+		file := c.queue.Current()
+		if file == nil {
+			err := c.queue.move(func() int { return c.queue.following(false) }, false)
+			if err == ErrEndOfQueue {
+				return io.EOF
+			} else if err != nil {
+				return err
+			}
+			continue
+		}
+
+		decoder, err := c.decode(file)
+		if err == nil {
+			c.file, c.decoder, c.err = file, decoder, nil
+			return nil
+		}
+		if err := c.queue.skip(file, err); err == ErrEndOfQueue {
+			return io.EOF
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// read returns the next samples of the current track
+func (c *Crossfader) read() ([][]float32, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.decoder.ReadFrame()
+}
+
+func (c *Crossfader) closeDecoder() {
+	c.decoder.Close()
+	c.decoder = nil
+}
+
+// samples returns the number of samples of the crossfade
+func (c *Crossfader) samples() int {
+	return int(int64(c.queue.Crossfade()) * int64(c.sampleRate) / int64(time.Second))
+}
+
+// buffer adds the samples to the tail of the current track, and returns the samples which don't fit in it anymore
+func (c *Crossfader) buffer(frame [][]float32) [][]float32 {
+	n := c.samples()
+	if n <= 0 && len(c.tail) == 0 {
+		return frame
+	}
+
+	// The decoders may reuse their buffers, the samples kept are copied
+	for _, sample := range frame {
+		c.tail = append(c.tail, append([]float32{}, sample...))
+	}
+	if len(c.tail) <= n {
+		return nil
+	}
+	out := c.tail[:len(c.tail)-n]
+	c.tail = append([][]float32{}, c.tail[len(c.tail)-n:]...)
+	return out
+}
+
+// mix returns the tail of the previous track faded out, mixed with the start of the current one faded in. The samples
+// of the current track following the mix start its own tail.
+func (c *Crossfader) mix() [][]float32 {
+	tail := c.tail
+	c.tail = nil
+
+	var head [][]float32
+	for len(head) < len(tail) {
+		frame, err := c.decoder.ReadFrame()
+		if err != nil {
+			c.err = err
+			break
+		}
+		for _, sample := range frame {
+			head = append(head, append([]float32{}, sample...))
+		}
+	}
+	if len(head) > len(tail) {
+		c.tail = head[len(tail):]
+		head = head[:len(tail)]
+	}
+
+	for i, sample := range tail {
+		fade := float32(i) / float32(len(tail))
+		for ch := range sample {
+			sample[ch] *= 1 - fade
+			if i < len(head) && ch < len(head[i]) {
+				sample[ch] += head[i][ch] * fade
+			}
+		}
+	}
+	return tail
+}
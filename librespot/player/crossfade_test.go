@@ -0,0 +1,114 @@
+package player_test
+
+import (
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// constantDecoder decodes a file to mono samples of the same value, one per byte
+type constantDecoder struct {
+	file  *player.AudioFile
+	value float32
+	buf   []byte
+}
+
+func (d *constantDecoder) ReadFrame() ([][]float32, error) {
+	n, err := d.file.Read(d.buf)
+	if n == 0 {
+		if err == nil {
+			err = io.ErrNoProgress
+		}
+		return nil, err
+	}
+	frame := make([][]float32, n)
+	for i := range frame {
+		frame[i] = []float32{d.value}
+	}
+	return frame, nil
+}
+
+func (d *constantDecoder) Close() error {
+	return nil
+}
+
+// constantDecoders returns a DecoderFunc whose decoders return the values 1, 2, 3... for the tracks, in the order
+// they are opened
+func constantDecoders() player.DecoderFunc {
+	var lock sync.Mutex
+	var opened int
+	return func(file *player.AudioFile) (player.FrameDecoder, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		opened++
+		return &constantDecoder{file: file, value: float32(opened), buf: make([]byte, 1000)}, nil
+	}
+}
+
+func readFrames(t *testing.T, c *player.Crossfader) []float32 {
+	var samples []float32
+	for {
+		frame, err := c.ReadFrame()
+		if err == io.EOF {
+			return samples
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, sample := range frame {
+			samples = append(samples, sample[0])
+		}
+	}
+}
+
+func TestCrossfader(t *testing.T) {
+	plain := make([]byte, player.ChunkAlignment)
+	server := newFakeAudioServer(time.Millisecond, plain)
+	queue := server.player.NewQueue()
+	queue.Add(queueItems(3)...)
+	// 100 samples at 1000Hz
+	queue.SetCrossfade(100 * time.Millisecond)
+
+	c := queue.NewCrossfader(constantDecoders(), 1000)
+	defer c.Close()
+	samples := readFrames(t, c)
+
+	length := len(plain)
+	if len(samples) != 3*length-2*100 {
+		t.Fatalf("Got %d samples, want %d", len(samples), 3*length-2*100)
+	}
+	expected := map[int]float32{
+		0:                 1,
+		length - 101:      1,
+		length - 100:      1,
+		length - 50:       1.5,
+		length:            2,
+		2*length - 200:    2,
+		2*length - 150:    2.5,
+		len(samples) - 1:  3,
+		len(samples) - 50: 3,
+	}
+	for i, value := range expected {
+		if math.Abs(float64(samples[i]-value)) > 1e-6 {
+			t.Errorf("Sample %d is %v, want %v", i, samples[i], value)
+		}
+	}
+}
+
+func TestCrossfaderGapless(t *testing.T) {
+	plain := make([]byte, player.ChunkAlignment)
+	server := newFakeAudioServer(time.Millisecond, plain)
+	queue := server.player.NewQueue()
+	queue.Add(queueItems(2)...)
+
+	c := queue.NewCrossfader(constantDecoders(), 1000)
+	defer c.Close()
+	samples := readFrames(t, c)
+
+	if len(samples) != 2*len(plain) || samples[len(plain)-1] != 1 || samples[len(plain)] != 2 {
+		t.Errorf("Got %d samples, the tracks are not back to back", len(samples))
+	}
+}
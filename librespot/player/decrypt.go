@@ -3,15 +3,75 @@ package player
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"math"
-	"math/big"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/cpu"
 )
 
 var AUDIO_AESIV = []byte{0x72, 0xe0, 0x67, 0xfb, 0xdd, 0xcb, 0xcf, 0x77, 0xeb, 0xe8, 0xbc, 0x64, 0x3f, 0x63, 0x0d, 0x93}
 
+// DecryptionBackend selects how the audio files are decrypted. The audio files are encrypted with AES-128 in CTR
+// mode, and decrypting them is a measurable cost on the boards without hardware AES, e.g. ARMv6.
+type DecryptionBackend int
+
+const (
+	// DecryptionAuto uses DecryptionHardware where the CPU supports AES, DecryptionPureGo otherwise
+	DecryptionAuto DecryptionBackend = iota
+	// DecryptionHardware uses the CTR mode of the standard library, which relies on the AES instructions of the CPU
+	// (AES-NI, ARMv8 Cryptography Extensions) when available
+	DecryptionHardware
+	// DecryptionPureGo generates the key stream itself, by batches of counter blocks, and XORs it word by word. It
+	// is faster than the standard library where AES is computed in software.
+	DecryptionPureGo
+)
+
+func (b DecryptionBackend) String() string {
+	switch b {
+	case DecryptionAuto:
+		return "auto"
+	case DecryptionHardware:
+		return "hardware"
+	case DecryptionPureGo:
+		return "pure-go"
+	default:
+		return fmt.Sprintf("DecryptionBackend(%d)", int(b))
+	}
+}
+
+// HardwareAES tells whether the CPU has AES instructions, used by the standard library
+func HardwareAES() bool {
+	switch runtime.GOARCH {
+	case "amd64", "386":
+		return cpu.X86.HasAES
+	case "arm64":
+		return cpu.ARM64.HasAES
+	case "s390x":
+		return cpu.S390X.HasAESCTR
+	default:
+		return false
+	}
+}
+
+// resolve returns the backend to use for DecryptionAuto
+func (b DecryptionBackend) resolve() DecryptionBackend {
+	if b != DecryptionAuto {
+		return b
+	}
+	if HardwareAES() {
+		return DecryptionHardware
+	}
+	return DecryptionPureGo
+}
+
+// keyStreamBatch is the number of AES blocks of key stream generated at once by DecryptionPureGo
+const keyStreamBatch = 32
+
 type AudioFileDecrypter struct {
-	ivDiff *big.Int
-	ivInt  *big.Int
+	backend DecryptionBackend
+	// keyStream is the buffer of the key stream of DecryptionPureGo
+	keyStream [keyStreamBatch * aes.BlockSize]byte
 }
 
 func CreateCipher(key []byte) cipher.Block {
@@ -23,39 +83,77 @@ func CreateCipher(key []byte) cipher.Block {
 	return block
 }
 
+// NewAudioFileDecrypter creates a decrypter using the DecryptionAuto backend
 func NewAudioFileDecrypter() *AudioFileDecrypter {
-	return &AudioFileDecrypter{
-		ivDiff: new(big.Int),
-		ivInt:  new(big.Int),
-	}
+	return NewAudioFileDecrypterWithBackend(DecryptionAuto)
 }
 
-func (afd *AudioFileDecrypter) DecryptAudioWithBlock(index int, block cipher.Block, ciphertext []byte, plaintext []byte) []byte {
-	length := len(ciphertext)
-	// plaintext := bufferPool.Get().([]byte) // make([]byte, length)
-	byteBaseOffset := index * kChunkSize * 4
+// NewAudioFileDecrypterWithBackend creates a decrypter using the specified backend
+func NewAudioFileDecrypterWithBackend(backend DecryptionBackend) *AudioFileDecrypter {
+	return &AudioFileDecrypter{backend: backend.resolve()}
+}
 
-	// The actual IV is the base IV + index*0x100, where index is the chunk index sized 1024 words (so each 4096 bytes
-	// block has its own IV). As we are retrieving 32768 words (131072 bytes) to speed up network operations, we need
-	// to process the data by 4096 bytes blocks to decrypt with the correct key.
+// Backend returns the backend used by the decrypter, never DecryptionAuto
+func (afd *AudioFileDecrypter) Backend() DecryptionBackend {
+	return afd.backend
+}
 
-	// We pre-calculate the base IV for the first chunk we are processing, then just proceed to add 0x100 at
-	// every iteration.
-	afd.ivInt.SetBytes(AUDIO_AESIV)
-	afd.ivDiff.SetInt64(int64((byteBaseOffset / 4096) * 0x100))
-	afd.ivInt.Add(afd.ivInt, afd.ivDiff)
+func (afd *AudioFileDecrypter) DecryptAudioWithBlock(index int, block cipher.Block, ciphertext []byte, plaintext []byte) []byte {
+	return afd.DecryptAudioAtOffset(index*kChunkByteSize, block, ciphertext, plaintext)
+}
 
-	afd.ivDiff.SetInt64(int64(0x100))
+// DecryptAudioAtOffset decrypts data starting at the specified byte offset of the audio file. The offset must be a
+// multiple of the AES block size, 16 bytes.
+func (afd *AudioFileDecrypter) DecryptAudioAtOffset(byteBaseOffset int, block cipher.Block, ciphertext []byte, plaintext []byte) []byte {
+	length := len(ciphertext)
 
-	for i := 0; i < length; i += 4096 {
-		// fmt.Printf("IV (chunk index %d): %x\n", chunkIndex, ivBytes)
-		endBytes := int(math.Min(float64(i+4096), float64(length)))
+	// The files are encrypted as a single CTR stream, so the counter of the block at an offset is the IV plus the
+	// number of blocks before it
+	hi := binary.BigEndian.Uint64(AUDIO_AESIV[:8])
+	lo := binary.BigEndian.Uint64(AUDIO_AESIV[8:])
+	blocks := uint64(byteBaseOffset / aes.BlockSize)
+	if lo+blocks < lo {
+		hi++
+	}
+	lo += blocks
 
-		stream := cipher.NewCTR(block, afd.ivInt.Bytes())
-		stream.XORKeyStream(plaintext[i:endBytes], ciphertext[i:endBytes])
+	if afd.backend == DecryptionHardware {
+		iv := make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(iv[:8], hi)
+		binary.BigEndian.PutUint64(iv[8:], lo)
+		cipher.NewCTR(block, iv).XORKeyStream(plaintext[:length], ciphertext)
+		return plaintext[:length]
+	}
 
-		afd.ivInt.Add(afd.ivInt, afd.ivDiff)
+	for done := 0; done < length; {
+		// Encrypt a batch of counter blocks, then XOR it with the data
+		n := min(len(afd.keyStream), length-done)
+		for i := 0; i < n; i += aes.BlockSize {
+			counter := afd.keyStream[i : i+aes.BlockSize]
+			binary.BigEndian.PutUint64(counter[:8], hi)
+			binary.BigEndian.PutUint64(counter[8:], lo)
+			block.Encrypt(counter, counter)
+			lo++
+			if lo == 0 {
+				hi++
+			}
+		}
+		xorWords(plaintext[done:done+n], ciphertext[done:done+n], afd.keyStream[:n])
+		done += n
 	}
 
-	return plaintext[0:length]
+	return plaintext[:length]
+}
+
+// xorWords sets dst to a XOR b, 8 bytes at a time
+func xorWords(dst, a, b []byte) {
+	n := len(dst)
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		binary.LittleEndian.PutUint64(dst[i:],
+			binary.LittleEndian.Uint64(a[i:])^binary.LittleEndian.Uint64(b[i:]))
+	}
+	for ; i < n; i++ {
+		dst[i] = a[i] ^ b[i]
+	}
 }
@@ -27,3 +27,87 @@ func BenchmarkDecryptAudioWithBlock(b *testing.B) {
 		dec.DecryptAudioWithBlock(0, block, kTestData, output)
 	}
 }
+
+func TestDecryptAudioAtOffset(t *testing.T) {
+	block := player.CreateCipher(kTestKey)
+	dec := player.NewAudioFileDecrypter()
+
+	data := make([]byte, 4*player.ChunkAlignment)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	whole := make([]byte, len(data))
+	dec.DecryptAudioAtOffset(0, block, data, whole)
+
+	// Decrypting by smaller aligned chunks must give the same result
+	parts := make([]byte, len(data))
+	for off := 0; off < len(data); off += player.ChunkAlignment {
+		dec.DecryptAudioAtOffset(off, block, data[off:off+player.ChunkAlignment], parts[off:off+player.ChunkAlignment])
+	}
+
+	for i := range whole {
+		if whole[i] != parts[i] {
+			t.Fatalf("mismatch at byte %d", i)
+		}
+	}
+}
+
+func TestSetChunkSize(t *testing.T) {
+	p := player.CreatePlayer(nil, nil)
+	if p.ChunkSize() != player.DefaultChunkSize {
+		t.Errorf("unexpected default chunk size %d", p.ChunkSize())
+	}
+	if err := p.SetChunkSize(1000); err == nil {
+		t.Errorf("unaligned chunk size accepted")
+	}
+	if err := p.SetChunkSize(2 * player.ChunkAlignment); err != nil || p.ChunkSize() != 2*player.ChunkAlignment {
+		t.Errorf("valid chunk size refused: %v", err)
+	}
+}
+
+func TestDecryptionBackends(t *testing.T) {
+	block := player.CreateCipher(kTestKey)
+	data := make([]byte, 3*player.ChunkAlignment+100)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	hardware := player.NewAudioFileDecrypterWithBackend(player.DecryptionHardware)
+	pureGo := player.NewAudioFileDecrypterWithBackend(player.DecryptionPureGo)
+	if auto := player.NewAudioFileDecrypter().Backend(); auto == player.DecryptionAuto {
+		t.Errorf("auto backend not resolved")
+	}
+
+	for _, offset := range []int{0, 16, 4096, 123 * 16} {
+		want := make([]byte, len(data))
+		hardware.DecryptAudioAtOffset(offset, block, data, want)
+		got := make([]byte, len(data))
+		pureGo.DecryptAudioAtOffset(offset, block, data, got)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("mismatch at byte %d of offset %d", i, offset)
+			}
+		}
+	}
+}
+
+func benchmarkDecryption(b *testing.B, backend player.DecryptionBackend) {
+	block := player.CreateCipher(kTestKey)
+	data := make([]byte, player.DefaultChunkSize)
+	output := make([]byte, len(data))
+	dec := player.NewAudioFileDecrypterWithBackend(backend)
+
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		dec.DecryptAudioAtOffset(0, block, data, output)
+	}
+}
+
+func BenchmarkDecryptionHardware(b *testing.B) {
+	benchmarkDecryption(b, player.DecryptionHardware)
+}
+
+func BenchmarkDecryptionPureGo(b *testing.B) {
+	benchmarkDecryption(b, player.DecryptionPureGo)
+}
@@ -0,0 +1,109 @@
+package player
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// EventType is the type of a playback event
+type EventType int
+
+const (
+	// EventTrackStart is emitted when the playback of an audio file starts, i.e. when it is first read
+	EventTrackStart EventType = iota
+	// EventTrackEnd is emitted when an audio file has been read until its end
+	EventTrackEnd
+	// EventError is emitted when an audio file cannot be loaded
+	EventError
+	// EventBufferingStart is emitted when a read has to wait for audio data which isn't downloaded yet
+	EventBufferingStart
+	// EventBufferingEnd is emitted when the data a read waited for is available, or failed to download
+	EventBufferingEnd
+	// EventTrackChanged is emitted when a queue moves to another track, the event describing the new track
+	EventTrackChanged
+	// EventEndOfTrack is emitted when a queue has played a track until its end, before moving to the next one
+	EventEndOfTrack
+	// EventTrackSkipped is emitted when a queue skips a track which failed to load, Err being its error
+	EventTrackSkipped
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventTrackStart:
+		return "track_start"
+	case EventTrackEnd:
+		return "track_end"
+	case EventError:
+		return "error"
+	case EventBufferingStart:
+		return "buffering_start"
+	case EventBufferingEnd:
+		return "buffering_end"
+	case EventTrackChanged:
+		return "track_changed"
+	case EventEndOfTrack:
+		return "end_of_track"
+	case EventTrackSkipped:
+		return "track_skipped"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a playback event emitted by the player
+type Event struct {
+	Type    EventType
+	Time    time.Time
+	TrackId []byte
+	FileId  []byte
+	Format  Spotify.AudioFile_Format
+	Origin  PlayOrigin
+	// Err is the error which occurred, for EventError and EventTrackSkipped
+	Err error
+}
+
+// EventListener is called for every playback event. It is called synchronously from the playback path, and must
+// not block.
+type EventListener func(event Event)
+
+// OnEvent registers a listener notified of the playback events of all the audio files loaded by the player
+func (p *Player) OnEvent(listener EventListener) {
+	p.listenersLock.Lock()
+	p.listeners = append(p.listeners, listener)
+	p.listenersLock.Unlock()
+}
+
+func (p *Player) emit(event Event) {
+	p.listenersLock.Lock()
+	listeners := append([]EventListener{}, p.listeners...)
+	p.listenersLock.Unlock()
+
+	for _, l := range listeners {
+		l(event)
+	}
+}
+
+// emit sends an event about this audio file, once for every event type except the buffering ones
+func (a *AudioFile) emit(eventType EventType, err error) {
+	if eventType != EventBufferingStart && eventType != EventBufferingEnd {
+		a.lock.Lock()
+		if a.emitted[eventType] {
+			a.lock.Unlock()
+			return
+		}
+		a.emitted[eventType] = true
+		a.lock.Unlock()
+	}
+
+	a.player.emit(Event{
+		Type:    eventType,
+		Time:    time.Now(),
+		TrackId: a.trackId,
+		FileId:  a.fileId,
+		Format:  a.format,
+		Origin:  a.Origin(),
+		Err:     err,
+	})
+}
@@ -0,0 +1,145 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+)
+
+// ErrFiltered is returned for the tracks vetoed by a content filter
+var ErrFiltered = errors.New("rejected by the content filter")
+
+// maxFilterSubstitutions is the number of substitutions after which the filters are assumed to loop
+const maxFilterSubstitutions = 8
+
+// Candidate is a track or an episode about to be played, passed to the content filters
+type Candidate struct {
+	Id ids.Id
+	// Track is the metadata of the track, nil for an episode
+	Track *metadata.TrackInfo
+	// Episode is the metadata of the episode, nil for a track
+	Episode *metadata.EpisodeInfo
+}
+
+// FilterDecision is the decision of a content filter about a candidate. The zero value lets it play.
+type FilterDecision struct {
+	// Skip vetoes the candidate
+	Skip bool
+	// Substitute is the track or episode to play instead of the candidate, if not zero
+	Substitute ids.Id
+}
+
+// ContentFilter decides whether a track or an episode can be played, e.g. to skip the podcasts or the explicit
+// tracks. It is called before the playback starts, and must not block.
+type ContentFilter func(candidate Candidate) FilterDecision
+
+// AddContentFilter registers a filter applied to the tracks before they play: by Queue.Add, by FilterContent, and
+// through it by the playback started with core.Session.PlayURI or from Connect. The filters are applied in the
+// order they were added, and the substitutes of a filter go through all of them again.
+func (p *Player) AddContentFilter(filter ContentFilter) {
+	p.listenersLock.Lock()
+	p.contentFilters = append(p.contentFilters, filter)
+	p.listenersLock.Unlock()
+}
+
+// FilterContent applies the content filters to a track or an episode, returning the id to play instead, which is
+// the same id unless it was substituted. It returns ErrFiltered if a filter vetoed it. The metadata of the candidates
+// is only fetched if filters are registered.
+func (p *Player) FilterContent(id ids.Id) (ids.Id, error) {
+	candidate, err := p.filter(id)
+	if err != nil {
+		return ids.Id{}, err
+	}
+	return candidate.Id, nil
+}
+
+func (p *Player) currentContentFilters() []ContentFilter {
+	p.listenersLock.Lock()
+	defer p.listenersLock.Unlock()
+	return append([]ContentFilter{}, p.contentFilters...)
+}
+
+// filter applies the content filters to the id, returning the candidate to play. The metadata of the candidate is
+// nil if no filter is registered.
+func (p *Player) filter(id ids.Id) (Candidate, error) {
+	filters := p.currentContentFilters()
+	if len(filters) == 0 {
+		return Candidate{Id: id}, nil
+	}
+
+	for substitutions := 0; substitutions <= maxFilterSubstitutions; substitutions++ {
+		candidate, err := p.candidate(id)
+		if err != nil {
+			return Candidate{}, err
+		}
+
+		substituted := false
+		for _, filter := range filters {
+			decision := filter(candidate)
+			if decision.Skip {
+				return Candidate{}, ErrFiltered
+			}
+			if decision.Substitute != (ids.Id{}) && decision.Substitute != id {
+				id = decision.Substitute
+				substituted = true
+				break
+			}
+		}
+		if !substituted {
+			return candidate, nil
+		}
+	}
+	return Candidate{}, fmt.Errorf("more than %d substitutions by the content filters", maxFilterSubstitutions)
+}
+
+// candidate fetches the metadata of the track or episode passed to the filters
+func (p *Player) candidate(id ids.Id) (Candidate, error) {
+	catalog := metadata.NewCatalog(p.mercury, "")
+	candidate := Candidate{Id: id}
+
+	var err error
+	switch id.Kind {
+	case ids.KindTrack:
+		candidate.Track, err = catalog.Track(id.Base62())
+	case ids.KindEpisode:
+		candidate.Episode, err = catalog.Episode(id.Base62())
+	default:
+		return Candidate{}, fmt.Errorf("cannot play %s", id)
+	}
+	return candidate, err
+}
+
+// filterQueueItem applies the content filters to a queue item, selecting the file of the substitute if it was
+// substituted
+func (p *Player) filterQueueItem(item QueueItem) (QueueItem, error) {
+	if len(p.currentContentFilters()) == 0 {
+		return item, nil
+	}
+	id, err := ids.FromGid(ids.KindTrack, item.TrackId)
+	if err != nil {
+		return item, err
+	}
+	candidate, err := p.filter(id)
+	if err != nil || candidate.Id == id {
+		return item, err
+	}
+
+	var files []*Spotify.AudioFile
+	if candidate.Track != nil {
+		files = candidate.Track.Files
+	} else if candidate.Episode != nil {
+		files = candidate.Episode.Files
+	}
+	selection, err := p.SelectAudioFile(files)
+	if err != nil {
+		return item, fmt.Errorf("failed to select the audio file of %s: %v", candidate.Id, err)
+	}
+
+	item.TrackId = candidate.Id.Gid[:]
+	item.FileId = selection.File.GetFileId()
+	item.Format = selection.File.GetFormat()
+	return item, nil
+}
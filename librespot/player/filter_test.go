@@ -0,0 +1,80 @@
+package player_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/golang/protobuf/proto"
+)
+
+func trackId(n byte) ids.Id {
+	id, _ := ids.FromGid(ids.KindTrack, bytes.Repeat([]byte{n}, 16))
+	return id
+}
+
+// filterPlayer creates a player whose mercury cache holds the metadata of the tracks, of the duration in minutes
+func filterPlayer(durations ...int32) *player.Player {
+	client := mercury.CreateMercury(nil)
+	client.Cache().DefaultTTL = time.Hour
+	for i, minutes := range durations {
+		id := trackId(byte(i + 1))
+		data, _ := proto.Marshal(&Spotify.Track{
+			Gid:      id.Gid[:],
+			Duration: proto.Int32(minutes * 60000),
+			File: []*Spotify.AudioFile{{
+				FileId: bytes.Repeat([]byte{byte(i + 1)}, 20),
+				Format: Spotify.AudioFile_OGG_VORBIS_160.Enum(),
+			}},
+		})
+		client.Cache().Put("hm://metadata/4/track/"+id.Hex(), mercury.Response{StatusCode: 200, Payload: [][]byte{data}})
+	}
+	return player.CreatePlayer(nil, client)
+}
+
+func TestContentFilter(t *testing.T) {
+	p := filterPlayer(3, 20, 3, 4)
+	if id, err := p.FilterContent(trackId(9)); err != nil || id != trackId(9) {
+		t.Errorf("Track filtered without filters: %v %v", id, err)
+	}
+
+	p.AddContentFilter(func(c player.Candidate) player.FilterDecision {
+		return player.FilterDecision{Skip: c.Track.Duration > 10*time.Minute}
+	})
+	p.AddContentFilter(func(c player.Candidate) player.FilterDecision {
+		if c.Id == trackId(3) {
+			return player.FilterDecision{Substitute: trackId(4)}
+		}
+		return player.FilterDecision{}
+	})
+
+	if id, err := p.FilterContent(trackId(1)); err != nil || id != trackId(1) {
+		t.Errorf("Track 1 filtered: %v %v", id, err)
+	}
+	if _, err := p.FilterContent(trackId(2)); err != player.ErrFiltered {
+		t.Errorf("Track 2 not vetoed: %v", err)
+	}
+	if id, err := p.FilterContent(trackId(3)); err != nil || id != trackId(4) {
+		t.Errorf("Track 3 not substituted: %v %v", id, err)
+	}
+
+	queue := p.NewQueue()
+	queue.Add(queueItems(3)...)
+	items := queue.Items()
+	if len(items) != 2 || items[0].TrackId[0] != 1 || items[1].TrackId[0] != 4 || items[1].FileId[0] != 4 ||
+		items[1].Format != Spotify.AudioFile_OGG_VORBIS_160 {
+		t.Errorf("Bad filtered queue %v", items)
+	}
+
+	// Substitutes looping forever are given up on
+	p.AddContentFilter(func(c player.Candidate) player.FilterDecision {
+		return player.FilterDecision{Substitute: trackId(c.Id.Gid[0]%4 + 1)}
+	})
+	if _, err := p.FilterContent(trackId(1)); err == nil {
+		t.Error("Substitution loop not detected")
+	}
+}
@@ -0,0 +1,249 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// FormatInfo describes an audio file format in human-readable terms (codec and bitrate), suitable for display
+// in UIs (e.g. "320kbps OGG").
+type FormatInfo struct {
+	Format  Spotify.AudioFile_Format
+	Codec   string
+	Bitrate int // In kbps, 0 if unknown
+}
+
+func (f FormatInfo) String() string {
+	if f.Bitrate == 0 {
+		return f.Codec
+	}
+	return fmt.Sprintf("%dkbps %s", f.Bitrate, f.Codec)
+}
+
+// SelectionReason explains why a specific audio file was picked among the ones available for a track
+type SelectionReason int
+
+const (
+	// ReasonPreferred means the first preferred format was available and allowed
+	ReasonPreferred SelectionReason = iota
+	// ReasonUnavailable means a more preferred format was not available for this track
+	ReasonUnavailable
+	// ReasonAccountLimit means a more preferred format was available, but exceeds what the account may stream
+	ReasonAccountLimit
+)
+
+func (r SelectionReason) String() string {
+	switch r {
+	case ReasonPreferred:
+		return "preferred"
+	case ReasonUnavailable:
+		return "preferred format unavailable"
+	case ReasonAccountLimit:
+		return "account limit"
+	default:
+		return fmt.Sprintf("SelectionReason(%d)", int(r))
+	}
+}
+
+// FormatSelection holds the result of an audio file selection: the chosen file, the reason it was chosen, and all
+// the formats that were available for the track.
+type FormatSelection struct {
+	File      *Spotify.AudioFile
+	Selected  FormatInfo
+	Reason    SelectionReason
+	Available []FormatInfo
+}
+
+// ErrNoPlayableFormat is returned when none of the files of a track match the requested formats and limits
+var ErrNoPlayableFormat = errors.New("no playable audio format available")
+
+// DescribeFormat returns the codec and bitrate information of the specified format
+func DescribeFormat(format Spotify.AudioFile_Format) FormatInfo {
+	info := FormatInfo{Format: format}
+
+	switch format {
+	case Spotify.AudioFile_OGG_VORBIS_96:
+		info.Codec, info.Bitrate = "OGG", 96
+	case Spotify.AudioFile_OGG_VORBIS_160:
+		info.Codec, info.Bitrate = "OGG", 160
+	case Spotify.AudioFile_OGG_VORBIS_320:
+		info.Codec, info.Bitrate = "OGG", 320
+	case Spotify.AudioFile_MP3_96:
+		info.Codec, info.Bitrate = "MP3", 96
+	case Spotify.AudioFile_MP3_160, Spotify.AudioFile_MP3_160_ENC:
+		info.Codec, info.Bitrate = "MP3", 160
+	case Spotify.AudioFile_MP3_256:
+		info.Codec, info.Bitrate = "MP3", 256
+	case Spotify.AudioFile_MP3_320:
+		info.Codec, info.Bitrate = "MP3", 320
+	case Spotify.AudioFile_AAC_160:
+		info.Codec, info.Bitrate = "AAC", 160
+	case Spotify.AudioFile_AAC_320:
+		info.Codec, info.Bitrate = "AAC", 320
+	case Spotify.AudioFile_MP4_128, Spotify.AudioFile_MP4_128_DUAL:
+		info.Codec, info.Bitrate = "MP4", 128
+	case FormatExternal:
+		info.Codec = "external"
+	default:
+		info.Codec = format.String()
+	}
+
+	return info
+}
+
+// AvailableFormats lists the formats of all the audio files attached to a track
+func AvailableFormats(files []*Spotify.AudioFile) []FormatInfo {
+	res := make([]FormatInfo, 0, len(files))
+	for _, file := range files {
+		res = append(res, DescribeFormat(file.GetFormat()))
+	}
+	return res
+}
+
+// SelectAudioFile picks the first file matching the preferred formats, in order of preference. Formats with a
+// bitrate above maxBitrate (if non-zero) are skipped, as they cannot be streamed with the current account. The
+// returned FormatSelection tells which file was chosen and why.
+func SelectAudioFile(files []*Spotify.AudioFile, preferred []Spotify.AudioFile_Format, maxBitrate int) (*FormatSelection, error) {
+	selection := &FormatSelection{
+		Available: AvailableFormats(files),
+		Reason:    ReasonPreferred,
+	}
+
+	for _, format := range preferred {
+		info := DescribeFormat(format)
+
+		file := findFormat(files, format)
+		if file == nil {
+			if selection.Reason == ReasonPreferred {
+				selection.Reason = ReasonUnavailable
+			}
+			continue
+		}
+
+		if maxBitrate > 0 && info.Bitrate > maxBitrate {
+			selection.Reason = ReasonAccountLimit
+			continue
+		}
+
+		selection.File = file
+		selection.Selected = info
+		return selection, nil
+	}
+
+	return selection, ErrNoPlayableFormat
+}
+
+func findFormat(files []*Spotify.AudioFile, format Spotify.AudioFile_Format) *Spotify.AudioFile {
+	for _, file := range files {
+		if file.GetFormat() == format {
+			return file
+		}
+	}
+	return nil
+}
+
+// Quality is the audio quality preference, with the same levels as in the Spotify apps
+type Quality int
+
+const (
+	// QualityLow selects the lowest bitrate available
+	QualityLow Quality = iota + 1
+	// QualityNormal selects 96kbps files
+	QualityNormal
+	// QualityHigh selects 160kbps files
+	QualityHigh
+	// QualityVeryHigh selects 320kbps files
+	QualityVeryHigh
+)
+
+// DefaultQuality is the quality used when none is set
+const DefaultQuality = QualityHigh
+
+func (q Quality) String() string {
+	switch q {
+	case QualityLow:
+		return "low"
+	case QualityNormal:
+		return "normal"
+	case QualityHigh:
+		return "high"
+	case QualityVeryHigh:
+		return "very high"
+	default:
+		return fmt.Sprintf("Quality(%d)", int(q))
+	}
+}
+
+// Bitrate returns the bitrate targeted by the quality, in kbps
+func (q Quality) Bitrate() int {
+	switch q {
+	case QualityLow:
+		return 24
+	case QualityNormal:
+		return 96
+	case QualityHigh:
+		return 160
+	case QualityVeryHigh:
+		return 320
+	default:
+		return DefaultQuality.Bitrate()
+	}
+}
+
+// qualityFormats are the formats selectable by quality, the preferred codec first for each bitrate
+var qualityFormats = []Spotify.AudioFile_Format{
+	Spotify.AudioFile_OGG_VORBIS_96,
+	Spotify.AudioFile_MP3_96,
+	Spotify.AudioFile_MP4_128,
+	Spotify.AudioFile_OGG_VORBIS_160,
+	Spotify.AudioFile_AAC_160,
+	Spotify.AudioFile_MP3_160,
+	Spotify.AudioFile_MP3_256,
+	Spotify.AudioFile_OGG_VORBIS_320,
+	Spotify.AudioFile_AAC_320,
+	Spotify.AudioFile_MP3_320,
+}
+
+// Formats returns the formats to use for the quality, in order of preference: the formats with the targeted bitrate,
+// then the lower bitrates, the closest first, and finally the higher bitrates, the closest first. If codecs are
+// specified (e.g. "OGG" for a player only decoding Vorbis), the formats of other codecs are left out.
+func (q Quality) Formats(codecs ...string) []Spotify.AudioFile_Format {
+	target := q.Bitrate()
+
+	var res []Spotify.AudioFile_Format
+	for _, format := range qualityFormats {
+		if len(codecs) == 0 || containsCodec(codecs, DescribeFormat(format).Codec) {
+			res = append(res, format)
+		}
+	}
+
+	// The sort is stable, keeping the codec preference among the formats of the same bitrate
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := DescribeFormat(res[i]).Bitrate, DescribeFormat(res[j]).Bitrate
+		if (a <= target) != (b <= target) {
+			return a <= target
+		} else if a <= target {
+			return a > b
+		}
+		return a < b
+	})
+	return res
+}
+
+func containsCodec(codecs []string, codec string) bool {
+	for _, c := range codecs {
+		if c == codec {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectQuality picks the file of a track best matching the quality, see Quality.Formats. Files with a bitrate above
+// maxBitrate (if non-zero) are skipped. The returned FormatSelection tells which format was actually chosen.
+func SelectQuality(files []*Spotify.AudioFile, quality Quality, maxBitrate int, codecs ...string) (*FormatSelection, error) {
+	return SelectAudioFile(files, quality.Formats(codecs...), maxBitrate)
+}
@@ -0,0 +1,103 @@
+package player_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+func makeFiles(formats ...Spotify.AudioFile_Format) []*Spotify.AudioFile {
+	files := make([]*Spotify.AudioFile, 0, len(formats))
+	for _, f := range formats {
+		files = append(files, &Spotify.AudioFile{Format: f.Enum()})
+	}
+	return files
+}
+
+func TestSelectAudioFile(t *testing.T) {
+	files := makeFiles(Spotify.AudioFile_OGG_VORBIS_96, Spotify.AudioFile_OGG_VORBIS_160, Spotify.AudioFile_OGG_VORBIS_320)
+	prefs := []Spotify.AudioFile_Format{Spotify.AudioFile_OGG_VORBIS_320, Spotify.AudioFile_OGG_VORBIS_160}
+
+	sel, err := player.SelectAudioFile(files, prefs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.Selected.String() != "320kbps OGG" || sel.Reason != player.ReasonPreferred {
+		t.Errorf("bad selection: %v (%v)", sel.Selected, sel.Reason)
+	}
+
+	sel, err = player.SelectAudioFile(files, prefs, 160)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.Selected.Bitrate != 160 || sel.Reason != player.ReasonAccountLimit {
+		t.Errorf("bad selection: %v (%v)", sel.Selected, sel.Reason)
+	}
+
+	sel, err = player.SelectAudioFile(files[:2], prefs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.Selected.Bitrate != 160 || sel.Reason != player.ReasonUnavailable {
+		t.Errorf("bad selection: %v (%v)", sel.Selected, sel.Reason)
+	}
+	if len(sel.Available) != 2 {
+		t.Errorf("expected 2 available formats, got %d", len(sel.Available))
+	}
+
+	_, err = player.SelectAudioFile(files[:1], prefs, 0)
+	if err != player.ErrNoPlayableFormat {
+		t.Errorf("expected ErrNoPlayableFormat, got %v", err)
+	}
+}
+
+func TestQualityFormats(t *testing.T) {
+	formats := player.QualityHigh.Formats("OGG")
+	want := []Spotify.AudioFile_Format{Spotify.AudioFile_OGG_VORBIS_160, Spotify.AudioFile_OGG_VORBIS_96,
+		Spotify.AudioFile_OGG_VORBIS_320}
+	if len(formats) != len(want) {
+		t.Fatalf("got formats %v, want %v", formats, want)
+	}
+	for i := range want {
+		if formats[i] != want[i] {
+			t.Fatalf("got formats %v, want %v", formats, want)
+		}
+	}
+
+	formats = player.QualityVeryHigh.Formats()
+	if formats[0] != Spotify.AudioFile_OGG_VORBIS_320 || formats[3] != Spotify.AudioFile_MP3_256 {
+		t.Errorf("bad very high formats %v", formats)
+	}
+
+	// The lowest bitrate available is used for the low quality
+	files := makeFiles(Spotify.AudioFile_OGG_VORBIS_320, Spotify.AudioFile_OGG_VORBIS_160)
+	sel, err := player.SelectQuality(files, player.QualityLow, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.Selected.Format != Spotify.AudioFile_OGG_VORBIS_160 || sel.Reason != player.ReasonUnavailable {
+		t.Errorf("bad selection: %v (%v)", sel.Selected, sel.Reason)
+	}
+
+	_, err = player.SelectQuality(makeFiles(Spotify.AudioFile_AAC_320), player.QualityHigh, 0, "OGG")
+	if err != player.ErrNoPlayableFormat {
+		t.Errorf("expected ErrNoPlayableFormat, got %v", err)
+	}
+}
+
+func TestPlayerMaxBitrate(t *testing.T) {
+	server := newFakeAudioServer(time.Millisecond, nil)
+	server.player.SetQuality(player.QualityVeryHigh, "OGG")
+	server.player.SetMaxBitrate(160)
+
+	files := makeFiles(Spotify.AudioFile_OGG_VORBIS_320, Spotify.AudioFile_OGG_VORBIS_160)
+	sel, err := server.player.SelectAudioFile(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.Selected.Format != Spotify.AudioFile_OGG_VORBIS_160 {
+		t.Errorf("got %v, want the 160 kbps file", sel.Selected)
+	}
+}
@@ -0,0 +1,329 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// FormatExternal is the format of the audio files hosted outside of Spotify, e.g. podcast episodes served by their
+// publisher. Their codec is not known in advance, MP3 in most cases.
+const FormatExternal Spotify.AudioFile_Format = -1
+
+// StorageResolver returns the HTTPS URLs of an audio file on the Spotify CDN, the preferred first
+type StorageResolver func(fileId []byte) ([]string, error)
+
+// AudioSource selects where the audio files are downloaded from
+type AudioSource int
+
+const (
+	// SourceAuto downloads the files from the CDN if a StorageResolver is set, and through the channels of the
+	// connection if it isn't, if the file can't be resolved, or once the CDN fails
+	SourceAuto AudioSource = iota
+	// SourceChannels always downloads the files through the channels of the connection
+	SourceChannels
+	// SourceCDN always downloads the files from the CDN, the files which can't be resolved failing to load
+	SourceCDN
+)
+
+// DefaultCDNParallelism is the number of range requests a chunk is split into when downloaded from the CDN
+const DefaultCDNParallelism = 4
+
+// SetHTTPClient sets the client downloading the audio files served over HTTP, http.DefaultClient by default
+func (p *Player) SetHTTPClient(client *http.Client) {
+	p.streamLock.Lock()
+	p.httpClient = client
+	p.streamLock.Unlock()
+}
+
+// SetStorageResolver sets the resolver of the CDN URLs of the audio files, through which the tracks and episodes are
+// downloaded from the CDN, see SetAudioSource. Without resolver, they are downloaded through the channels.
+func (p *Player) SetStorageResolver(resolver StorageResolver) {
+	p.streamLock.Lock()
+	p.storageResolver = resolver
+	p.streamLock.Unlock()
+}
+
+// SetAudioSource selects where the audio files loaded afterwards are downloaded from, SourceAuto by default
+func (p *Player) SetAudioSource(source AudioSource) {
+	p.streamLock.Lock()
+	p.audioSource = source
+	p.streamLock.Unlock()
+}
+
+// AudioSource returns where the audio files are downloaded from, see SetAudioSource
+func (p *Player) AudioSource() AudioSource {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.audioSource
+}
+
+// SetCDNParallelism changes the number of range requests sent in parallel to download a chunk from the CDN. Each
+// request covers at least ChunkAlignment bytes.
+func (p *Player) SetCDNParallelism(parallelism int) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	p.streamLock.Lock()
+	p.cdnParallelism = parallelism
+	p.streamLock.Unlock()
+}
+
+func (p *Player) getCDNParallelism() int {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.cdnParallelism
+}
+
+func (p *Player) getHTTPClient() *http.Client {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	if p.httpClient == nil {
+		return http.DefaultClient
+	}
+	return p.httpClient
+}
+
+func (p *Player) getStorageResolver() StorageResolver {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.storageResolver
+}
+
+// LoadEpisode loads a podcast episode. The episodes hosted by Spotify are encrypted like the tracks, and downloaded
+// the same way, see SetAudioSource; the file is selected with SelectAudioFile. The episodes only available from an
+// external URL are streamed from it as is, in FormatExternal.
+func (p *Player) LoadEpisode(episode *Spotify.Episode) (*AudioFile, error) {
+	if len(episode.GetFile()) == 0 && episode.GetExternalUrl() != "" {
+		return p.LoadExternal(episode.GetExternalUrl(), episode.GetGid())
+	}
+
+	selection, err := p.SelectAudioFile(episode.GetFile())
+	if err != nil {
+		return nil, err
+	}
+	return p.LoadTrackWithIdAndFormat(selection.File.GetFileId(), selection.File.GetFormat(), episode.GetGid())
+}
+
+// useCDN makes an audio file resolve its CDN URLs before its first chunk, unless the audio source is the channels
+func (p *Player) useCDN(a *AudioFile) {
+	source := p.AudioSource()
+	resolver := p.getStorageResolver()
+	if source == SourceChannels || (resolver == nil && source == SourceAuto) {
+		return
+	}
+	if resolver == nil {
+		resolver = func(fileId []byte) ([]string, error) {
+			return nil, errors.New("no storage resolver")
+		}
+	}
+	a.resolve = resolver
+	a.cdnOnly = source == SourceCDN
+}
+
+// LoadExternal loads an unencrypted audio file from an HTTP URL, in FormatExternal. The id, if any, is reported in
+// the TrackId of the playback events.
+func (p *Player) LoadExternal(url string, id []byte) (*AudioFile, error) {
+	audioFile := newAudioFileWithIdAndFormat(nil, FormatExternal, p)
+	audioFile.trackId = id
+	audioFile.urls = []string{url}
+	audioFile.plain = true
+	close(audioFile.keyReady)
+
+	audioFile.loadChunks()
+	return audioFile, nil
+}
+
+// resolveURLs resolves the CDN URLs of the file, if it wasn't done yet. Unless the file is restricted to the CDN, the
+// file is downloaded through the channels if it can't be resolved.
+func (a *AudioFile) resolveURLs() error {
+	a.lock.Lock()
+	resolve := a.resolve
+	a.resolve = nil
+	a.lock.Unlock()
+	if resolve == nil {
+		return nil
+	}
+
+	urls, err := resolve(a.fileId)
+	if err == nil && len(urls) == 0 {
+		err = errors.New("no CDN URL")
+	}
+	if err != nil {
+		if a.cdnOnly {
+			return fmt.Errorf("failed to resolve the file on the CDN: %v", err)
+		}
+		a.player.logger().Printf("[audiofile] Unable to resolve file %x on the CDN, using the channels: %v\n", a.fileId, err)
+		return nil
+	}
+	a.setURLs(urls)
+	return nil
+}
+
+func (a *AudioFile) cdnURLs() []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.urls
+}
+
+func (a *AudioFile) setURLs(urls []string) {
+	a.lock.Lock()
+	a.urls = urls
+	a.lock.Unlock()
+}
+
+// loadChunkHTTP downloads a chunk from the URLs of the file. The chunks of the encrypted files are split into
+// parallel range requests.
+func (a *AudioFile) loadChunkHTTP(ctx context.Context, chunkIndex int) error {
+	start := chunkIndex * a.chunkSize
+	parallelism := a.player.getCDNParallelism()
+	if a.plain {
+		// The external hosts may not support range requests, each request would then download the whole file
+		parallelism = 1
+	}
+	data, size, err := a.fetchHTTPParallel(ctx, start, start+a.chunkSize, parallelism)
+	if err != nil {
+		return err
+	}
+
+	a.lock.RLock()
+	sizeKnown, knownSize := a.data != nil, int(a.size)
+	a.lock.RUnlock()
+	if sizeKnown && size != knownSize {
+		return fmt.Errorf("file size changed from %d to %d bytes", knownSize, size)
+	}
+	a.setSize(uint32(size))
+	return a.storeChunk(chunkIndex, data)
+}
+
+// fetchHTTPParallel downloads the bytes between start and end with up to parallelism range requests of at least
+// ChunkAlignment bytes. It verifies that the parts agree on the size of the file, and that none was truncated.
+func (a *AudioFile) fetchHTTPParallel(ctx context.Context, start int, end int, parallelism int) ([]byte, int, error) {
+	partSize := (end - start + parallelism - 1) / parallelism
+	partSize = alignDown(partSize + ChunkAlignment - 1)
+	if partSize < ChunkAlignment {
+		partSize = ChunkAlignment
+	}
+
+	type part struct {
+		start int
+		data  []byte
+		size  int
+		err   error
+	}
+	var parts []*part
+	var wg sync.WaitGroup
+	for partStart := start; partStart < end; partStart += partSize {
+		p := &part{start: partStart}
+		parts = append(parts, p)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.data, p.size, p.err = a.fetchHTTP(ctx, p.start, min(p.start+partSize, end))
+		}()
+	}
+	wg.Wait()
+
+	size := -1
+	for _, p := range parts {
+		if p.err != nil {
+			return nil, 0, p.err
+		}
+		if size >= 0 && p.size != size {
+			return nil, 0, fmt.Errorf("inconsistent file sizes %d and %d", size, p.size)
+		}
+		size = p.size
+	}
+
+	data := make([]byte, 0, end-start)
+	for _, p := range parts {
+		if want := min(min(p.start+partSize, end), size) - p.start; len(p.data) != want && want > 0 {
+			return nil, 0, fmt.Errorf("truncated range at %d: got %d bytes, want %d", p.start, len(p.data), want)
+		}
+		data = append(data, p.data...)
+	}
+	return data, size, nil
+}
+
+// fetchHTTP downloads the bytes between start and end with a range request, trying the URLs in turn. The returned
+// data is shorter at the end of the file, whose size is returned along with it.
+func (a *AudioFile) fetchHTTP(ctx context.Context, start int, end int) ([]byte, int, error) {
+	var lastErr error
+	for _, url := range a.cdnURLs() {
+		data, size, err := a.fetchHTTPRange(ctx, url, start, end)
+		if err == nil {
+			return data, size, nil
+		} else if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		lastErr = err
+	}
+	return nil, 0, lastErr
+}
+
+func (a *AudioFile) fetchHTTPRange(ctx context.Context, url string, start int, end int) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	res, err := a.player.getHTTPClient().Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	var size int
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		size, err = parseContentRangeSize(res.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, 0, err
+		}
+	case http.StatusOK:
+		// The server ignored the range, skip to its start
+		size = int(res.ContentLength)
+		if _, err := io.CopyN(io.Discard, res.Body, int64(start)); err != nil {
+			return nil, 0, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		size, err = parseContentRangeSize(res.Header.Get("Content-Range"))
+		return nil, size, err
+	default:
+		return nil, 0, fmt.Errorf("%s: %s", url, res.Status)
+	}
+	if size < 0 {
+		return nil, 0, fmt.Errorf("%s: unknown file size", url)
+	}
+
+	if end > size {
+		end = size
+	}
+	data := make([]byte, end-start)
+	n, err := io.ReadFull(res.Body, data)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, 0, err
+	}
+	return data[:n], size, nil
+}
+
+// parseContentRangeSize returns the complete size of a Content-Range header, "bytes <start>-<end>/<size>"
+func parseContentRangeSize(contentRange string) (int, error) {
+	slash := strings.LastIndex(contentRange, "/")
+	if slash < 0 {
+		return 0, errors.New("no size in the content range")
+	}
+	size, err := strconv.Atoi(contentRange[slash+1:])
+	if err != nil {
+		return 0, fmt.Errorf("bad content range %q", contentRange)
+	}
+	return size, nil
+}
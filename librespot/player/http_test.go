@@ -0,0 +1,170 @@
+package player_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/golang/protobuf/proto"
+)
+
+func serveFile(data []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+}
+
+func TestLoadEpisodeCDN(t *testing.T) {
+	plain := make([]byte, 3*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i * 7)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	cdn := serveFile(server.encrypted)
+	defer cdn.Close()
+	var resolved []byte
+	server.player.SetStorageResolver(func(fileId []byte) ([]string, error) {
+		resolved = fileId
+		// The first URL fails, the file is downloaded from the second one
+		return []string{cdn.URL + "/missing", cdn.URL}, nil
+	})
+
+	episode := &Spotify.Episode{
+		Gid: testTrackId,
+		File: []*Spotify.AudioFile{
+			{FileId: testFileId, Format: Spotify.AudioFile_MP3_160.Enum()},
+		},
+	}
+	audioFile, err := server.player.LoadEpisode(episode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(audioFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(resolved, testFileId) {
+		t.Errorf("resolved file %x, want %x", resolved, testFileId)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("episode data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+}
+
+func TestLoadEpisodeExternal(t *testing.T) {
+	plain := make([]byte, 2*player.ChunkAlignment+10)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	external := serveFile(plain)
+	defer external.Close()
+
+	episode := &Spotify.Episode{Gid: testTrackId, ExternalUrl: proto.String(external.URL + "/episode.mp3")}
+	audioFile, err := server.player.LoadEpisode(episode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audioFile.Format() != player.FormatExternal {
+		t.Errorf("got format %v, want FormatExternal", audioFile.Format())
+	}
+	data, err := ioutil.ReadAll(audioFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("episode data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+}
+
+func TestLoadTrackCDN(t *testing.T) {
+	plain := make([]byte, 5*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i * 3)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(4 * player.ChunkAlignment)
+	server.player.SetCDNParallelism(4)
+
+	var lock sync.Mutex
+	ranges := 0
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		ranges++
+		lock.Unlock()
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(server.encrypted))
+	}))
+	defer cdn.Close()
+	server.player.SetStorageResolver(func(fileId []byte) ([]string, error) {
+		return []string{cdn.URL}, nil
+	})
+
+	audioFile, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(audioFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("track data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+
+	// Each of the 2 chunks is split into 4 ranges
+	lock.Lock()
+	defer lock.Unlock()
+	if ranges != 8 {
+		t.Errorf("got %d range requests, want 8", ranges)
+	}
+}
+
+func TestLoadTrackCDNFallback(t *testing.T) {
+	plain := make([]byte, 2*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+	server.player.SetStorageResolver(func(fileId []byte) ([]string, error) {
+		return []string{broken.URL}, nil
+	})
+
+	// The file is downloaded through the channels once the CDN fails
+	audioFile, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(audioFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("track data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+
+	// Unless it is restricted to the CDN
+	server.player.SetAudioSource(player.SourceCDN)
+	audioFile, err = server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(audioFile); err == nil {
+		t.Error("no error without the CDN")
+	}
+}
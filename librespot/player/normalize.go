@@ -0,0 +1,165 @@
+package player
+
+import (
+	"math"
+	"time"
+)
+
+// NormalizationType selects the ReplayGain values applied by a Normalizer
+type NormalizationType int
+
+const (
+	// NormalizationTrack makes all the tracks play at the same loudness
+	NormalizationTrack NormalizationType = iota
+	// NormalizationAlbum keeps the loudness differences between the tracks of an album, for albums played in order
+	NormalizationAlbum
+)
+
+// DefaultLimiterThresholdDb is the level above which the limiter reduces the gain, in dBFS
+const DefaultLimiterThresholdDb = -1
+
+// DefaultLimiterRelease is the time the limiter takes to restore the gain after a peak
+const DefaultLimiterRelease = 100 * time.Millisecond
+
+// NormalizationConfig controls the volume normalization, see Normalizer
+type NormalizationConfig struct {
+	Type NormalizationType
+	// PreGainDb is added to the ReplayGain of the tracks, e.g. to normalize to a louder target level
+	PreGainDb float64
+	// Limiter enables the limiter, which lowers the gain on the peaks which would clip instead of lowering the gain
+	// of the whole track
+	Limiter bool
+	// LimiterThresholdDb is the level above which the limiter reduces the gain, DefaultLimiterThresholdDb if zero
+	LimiterThresholdDb float64
+	// LimiterRelease is the time the limiter takes to restore the gain, DefaultLimiterRelease if zero
+	LimiterRelease time.Duration
+}
+
+// Normalizer applies the ReplayGain values of a track, found in its OggHeader, to its decoded samples so that the
+// tracks play at a consistent loudness. Without limiter, the gain is lowered if needed so that the peak of the track
+// doesn't clip.
+//
+// A Normalizer keeps the state of the limiter, and must only be used for one track at a time.
+type Normalizer struct {
+	factor    float64
+	limiter   bool
+	threshold float64
+	// release is the fraction of the gain reduction recovered at every sample
+	release float64
+	// gain is the current gain of the limiter, at most 1
+	gain float64
+}
+
+// NewNormalizer creates a normalizer for the track with the header
+func NewNormalizer(header *OggHeader, config NormalizationConfig) *Normalizer {
+	gainDb, peak := float64(header.TrackGainDb), float64(header.TrackPeak)
+	if config.Type == NormalizationAlbum && (header.AlbumGainDb != 0 || header.AlbumPeak != 0) {
+		gainDb, peak = float64(header.AlbumGainDb), float64(header.AlbumPeak)
+	}
+
+	n := &Normalizer{
+		factor:  math.Pow(10, (gainDb+config.PreGainDb)/20),
+		limiter: config.Limiter,
+		gain:    1,
+	}
+	if !n.limiter && peak > 0 && n.factor*peak > 1 {
+		n.factor = 1 / peak
+	}
+
+	thresholdDb := config.LimiterThresholdDb
+	if thresholdDb == 0 {
+		thresholdDb = DefaultLimiterThresholdDb
+	}
+	n.threshold = math.Pow(10, thresholdDb/20)
+
+	release := config.LimiterRelease
+	if release <= 0 {
+		release = DefaultLimiterRelease
+	}
+	sampleRate := header.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 44100
+	}
+	n.release = 1 - math.Exp(-1/(release.Seconds()*float64(sampleRate)))
+	return n
+}
+
+// Factor returns the linear gain applied to the samples, before the limiter
+func (n *Normalizer) Factor() float64 {
+	return n.factor
+}
+
+// GainDb returns the gain applied to the samples, before the limiter, in dB
+func (n *Normalizer) GainDb() float64 {
+	return 20 * math.Log10(n.factor)
+}
+
+// Process normalizes interleaved samples of the specified number of channels, in place
+func (n *Normalizer) Process(samples []float32, channels int) {
+	if channels <= 0 {
+		channels = 1
+	}
+	for i := 0; i < len(samples); i += channels {
+		n.processFrame(samples[i:min(i+channels, len(samples))])
+	}
+}
+
+// ProcessFrames normalizes the samples of every channel of the frames in place, as output by the Vorbis decoder
+func (n *Normalizer) ProcessFrames(frames [][]float32) {
+	for _, frame := range frames {
+		n.processFrame(frame)
+	}
+}
+
+// processFrame normalizes the samples of all the channels at one point in time, so that the limiter applies the
+// same gain to all of them
+func (n *Normalizer) processFrame(frame []float32) {
+	if !n.limiter {
+		for i, sample := range frame {
+			frame[i] = float32(float64(sample) * n.factor)
+		}
+		return
+	}
+
+	var peak float64
+	for _, sample := range frame {
+		peak = math.Max(peak, math.Abs(float64(sample)*n.factor))
+	}
+	// The gain drops instantly on the peaks, and is restored progressively
+	n.gain += (1 - n.gain) * n.release
+	if peak*n.gain > n.threshold {
+		n.gain = n.threshold / peak
+	}
+
+	for i, sample := range frame {
+		frame[i] = float32(float64(sample) * n.factor * n.gain)
+	}
+}
+
+// SetNormalization enables the volume normalization of the tracks loaded afterwards, see AudioFile.Normalizer. Pass
+// nil to disable it, which is the default.
+func (p *Player) SetNormalization(config *NormalizationConfig) {
+	p.streamLock.Lock()
+	p.normalization = config
+	p.streamLock.Unlock()
+}
+
+// Normalization returns the normalization configuration, nil if disabled
+func (p *Player) Normalization() *NormalizationConfig {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.normalization
+}
+
+// Normalizer returns the normalizer to apply to the decoded samples of the file, waiting for its OggHeader if
+// needed. It returns nil if the normalization is disabled, or the file is not an Ogg Vorbis file.
+func (a *AudioFile) Normalizer() *Normalizer {
+	if a.normalization == nil {
+		return nil
+	}
+	header, err := a.OggHeader()
+	if err != nil {
+		return nil
+	}
+	return NewNormalizer(header, *a.normalization)
+}
@@ -0,0 +1,61 @@
+package player_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+func TestNormalizer(t *testing.T) {
+	header := &player.OggHeader{TrackGainDb: -6, TrackPeak: 0.5, AlbumGainDb: 6, AlbumPeak: 0.9, SampleRate: 44100}
+
+	track := player.NewNormalizer(header, player.NormalizationConfig{})
+	if math.Abs(track.GainDb()+6) > 1e-9 {
+		t.Errorf("got track gain %v dB, want -6", track.GainDb())
+	}
+	frames := [][]float32{{0.5, -0.5}}
+	track.ProcessFrames(frames)
+	if math.Abs(float64(frames[0][0])-0.5*track.Factor()) > 1e-6 || frames[0][1] != -frames[0][0] {
+		t.Errorf("got samples %v", frames[0])
+	}
+
+	// Without limiter, the album gain is lowered so that the peak doesn't clip
+	album := player.NewNormalizer(header, player.NormalizationConfig{Type: player.NormalizationAlbum})
+	if math.Abs(album.Factor()-1/0.9) > 1e-6 {
+		t.Errorf("got album factor %v, want %v", album.Factor(), 1/0.9)
+	}
+
+	// The pre-gain is applied on top of the ReplayGain
+	pregain := player.NewNormalizer(header, player.NormalizationConfig{PreGainDb: 3})
+	if math.Abs(pregain.GainDb()+3) > 1e-9 {
+		t.Errorf("got gain %v dB with pre-gain, want -3", pregain.GainDb())
+	}
+}
+
+func TestNormalizerLimiter(t *testing.T) {
+	header := &player.OggHeader{TrackGainDb: 6, TrackPeak: 1, SampleRate: 44100}
+	n := player.NewNormalizer(header, player.NormalizationConfig{Limiter: true})
+	threshold := math.Pow(10, player.DefaultLimiterThresholdDb/20.0)
+
+	samples := make([]float32, 2*22050)
+	for i := range samples {
+		samples[i] = 0.1
+	}
+	samples[1000], samples[1001] = 0.9, -0.9
+	n.Process(samples, 2)
+
+	if math.Abs(float64(samples[0])-0.1*n.Factor()) > 1e-6 {
+		t.Errorf("quiet sample got %v, want the full gain", samples[0])
+	}
+	if math.Abs(float64(samples[1000])) > threshold+1e-6 || math.Abs(float64(samples[1001])) > threshold+1e-6 {
+		t.Errorf("peak got %v, %v, above the threshold %v", samples[1000], samples[1001], threshold)
+	}
+	// The gain is reduced after the peak, then restored
+	if samples[1002] >= samples[0] {
+		t.Errorf("no gain reduction after the peak")
+	}
+	if last := samples[len(samples)-1]; math.Abs(float64(last)-float64(samples[0])) > 1e-3 {
+		t.Errorf("gain not restored after the release, got %v, want %v", last, samples[0])
+	}
+}
@@ -0,0 +1,438 @@
+package player
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/storage"
+)
+
+// ErrNotPinned is returned for the audio files which are not stored for offline playback
+var ErrNotPinned = errors.New("audio file not pinned")
+
+// ErrNoOfflineStore is returned when pinning a track on a player without OfflineStore
+var ErrNoOfflineStore = errors.New("no offline store")
+
+// offlineIndexName is the file listing the tracks of an OfflineStore
+const offlineIndexName = "index.json"
+
+// OfflineTrack describes a track stored for offline playback
+type OfflineTrack struct {
+	FileId  []byte
+	TrackId []byte
+	Format  Spotify.AudioFile_Format
+	// Size is the size of the stored file, in bytes
+	Size     int64
+	PinnedAt time.Time
+	LastUsed time.Time
+}
+
+// OfflineStore keeps complete audio files on disk, so that pinned tracks can be played without a network connection.
+// The files are stored decrypted from the Spotify key, which would not be available offline, and encrypted at rest
+// with AES-GCM using a local key instead. Once the store holds more than its maximum size, the least recently played
+// tracks are evicted.
+//
+// It is safe for concurrent use.
+type OfflineStore struct {
+	index    storage.KV
+	files    storage.Blobs
+	aead     cipher.AEAD
+	maxBytes int64
+	now      func() time.Time
+
+	lock   sync.Mutex
+	tracks map[string]*OfflineTrack
+	log    *log.Logger
+}
+
+// NewOfflineKey generates a random key for an OfflineStore. It must be kept, e.g. in the keyring, to read the pinned
+// tracks again.
+func NewOfflineKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewOfflineStore opens the store in the directory, creating it if needed. The key is an AES key of 16, 24 or 32
+// bytes, see NewOfflineKey. The store holds at most maxBytes of audio files, or is unlimited if maxBytes is zero.
+func NewOfflineStore(dir string, key []byte, maxBytes int64) (*OfflineStore, error) {
+	d, err := storage.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create offline directory: %v", err)
+	}
+	return NewOfflineStoreWith(d, d, key, maxBytes)
+}
+
+// NewOfflineStoreWith opens a store keeping its index in the index store and the audio files in files, like
+// NewOfflineStore does in a directory
+func NewOfflineStoreWith(index storage.KV, files storage.Blobs, key []byte, maxBytes int64) (*OfflineStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid offline key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &OfflineStore{
+		index:    index,
+		files:    files,
+		aead:     aead,
+		maxBytes: maxBytes,
+		now:      time.Now,
+		tracks:   map[string]*OfflineTrack{},
+	}
+
+	data, err := index.Get(offlineIndexName)
+	if err != nil && err != storage.ErrNotFound {
+		return nil, err
+	} else if err == nil {
+		var tracks []*OfflineTrack
+		if err := json.Unmarshal(data, &tracks); err != nil {
+			return nil, fmt.Errorf("invalid offline index: %v", err)
+		}
+		for _, track := range tracks {
+			s.tracks[hex.EncodeToString(track.FileId)] = track
+		}
+	}
+	return s, nil
+}
+
+// SetLogger sets the logger receiving the evictions of the store, the standard logger being used if nil
+func (s *OfflineStore) SetLogger(logger *log.Logger) {
+	s.lock.Lock()
+	s.log = logger
+	s.lock.Unlock()
+}
+
+// loggerLocked returns the logger of the store, see SetLogger. It must be called with lock held.
+func (s *OfflineStore) loggerLocked() *log.Logger {
+	if s.log == nil {
+		return log.Default()
+	}
+	return s.log
+}
+
+func trackKey(fileId []byte) string {
+	return hex.EncodeToString(fileId) + ".track"
+}
+
+// Put stores the decrypted audio file of a track, evicting the least recently played tracks if needed
+func (s *OfflineStore) Put(track OfflineTrack, data []byte) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	// The file id is authenticated, so that a file can't be swapped for another one
+	sealed := s.aead.Seal(nonce, nonce, data, track.FileId)
+	if s.maxBytes > 0 && int64(len(sealed)) > s.maxBytes {
+		return fmt.Errorf("file of %d bytes larger than the offline store", len(sealed))
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := hex.EncodeToString(track.FileId)
+	if err := s.files.Write(trackKey(track.FileId), bytes.NewReader(sealed)); err != nil {
+		return err
+	}
+	track.Size = int64(len(sealed))
+	track.PinnedAt = s.now()
+	track.LastUsed = track.PinnedAt
+	s.tracks[id] = &track
+
+	s.evict(id)
+	return s.saveIndex()
+}
+
+// evict removes the least recently played tracks, except the one specified, until the store fits in its maximum size
+func (s *OfflineStore) evict(keep string) {
+	if s.maxBytes <= 0 {
+		return
+	}
+	for _, track := range s.sortedTracks() {
+		if s.sizeLocked() <= s.maxBytes {
+			return
+		}
+		id := hex.EncodeToString(track.FileId)
+		if id == keep {
+			continue
+		}
+		if err := s.files.Remove(trackKey(track.FileId)); err != nil {
+			s.loggerLocked().Printf("[offline] Unable to evict %s: %s", id, err)
+			continue
+		}
+		delete(s.tracks, id)
+	}
+}
+
+// Get returns the decrypted audio file of a pinned track, ErrNotPinned if it isn't stored
+func (s *OfflineStore) Get(fileId []byte) ([]byte, *OfflineTrack, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	track, ok := s.tracks[hex.EncodeToString(fileId)]
+	if !ok {
+		return nil, nil, ErrNotPinned
+	}
+	data, err := s.read(fileId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	track.LastUsed = s.now()
+	if err := s.saveIndex(); err != nil {
+		s.loggerLocked().Printf("[offline] Unable to save the index: %s", err)
+	}
+	copied := *track
+	return data, &copied, nil
+}
+
+// read returns the decrypted audio file of a track, it must be called with lock held
+func (s *OfflineStore) read(fileId []byte) ([]byte, error) {
+	r, err := s.files.Open(trackKey(fileId))
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("truncated offline file %x", fileId)
+	}
+	nonceSize := s.aead.NonceSize()
+	data, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], fileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt offline file %x: %v", fileId, err)
+	}
+	return data, nil
+}
+
+// Verify decrypts all the pinned tracks, and removes the ones which are missing or corrupt, which must be pinned
+// again. The files left out of the index, e.g. by a power loss during a Put, are removed too if the files can be
+// listed.
+func (s *OfflineStore) Verify() (CacheReport, error) {
+	var report CacheReport
+	for _, track := range s.Tracks() {
+		s.lock.Lock()
+		id := hex.EncodeToString(track.FileId)
+		if _, ok := s.tracks[id]; !ok {
+			// Removed meanwhile
+			s.lock.Unlock()
+			continue
+		}
+		report.Checked++
+		if _, err := s.read(track.FileId); err != nil {
+			s.loggerLocked().Printf("[offline] Removing the corrupt track %s: %s", id, err)
+			report.Evicted++
+			s.files.Remove(trackKey(track.FileId))
+			delete(s.tracks, id)
+			if err := s.saveIndex(); err != nil {
+				s.lock.Unlock()
+				return report, err
+			}
+		}
+		s.lock.Unlock()
+	}
+
+	lister, ok := s.files.(storage.Lister)
+	if !ok {
+		return report, nil
+	}
+	keys, err := lister.Keys("")
+	if err != nil {
+		return report, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".track") {
+			continue
+		}
+		if _, ok := s.tracks[strings.TrimSuffix(key, ".track")]; !ok {
+			report.Evicted++
+			s.files.Remove(key)
+		}
+	}
+	return report, nil
+}
+
+// Has returns true if the audio file is pinned
+func (s *OfflineStore) Has(fileId []byte) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.tracks[hex.EncodeToString(fileId)]
+	return ok
+}
+
+// Remove deletes a pinned audio file
+func (s *OfflineStore) Remove(fileId []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := hex.EncodeToString(fileId)
+	if _, ok := s.tracks[id]; !ok {
+		return ErrNotPinned
+	}
+	if err := s.files.Remove(trackKey(fileId)); err != nil {
+		return err
+	}
+	delete(s.tracks, id)
+	return s.saveIndex()
+}
+
+// Tracks returns the pinned tracks, the most recently played first
+func (s *OfflineStore) Tracks() []OfflineTrack {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sorted := s.sortedTracks()
+	tracks := make([]OfflineTrack, len(sorted))
+	for i := range sorted {
+		tracks[len(sorted)-1-i] = *sorted[i]
+	}
+	return tracks
+}
+
+// Size returns the total size of the stored files, in bytes
+func (s *OfflineStore) Size() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sizeLocked()
+}
+
+func (s *OfflineStore) sizeLocked() int64 {
+	var size int64
+	for _, track := range s.tracks {
+		size += track.Size
+	}
+	return size
+}
+
+// sortedTracks returns the tracks, the least recently played first
+func (s *OfflineStore) sortedTracks() []*OfflineTrack {
+	tracks := make([]*OfflineTrack, 0, len(s.tracks))
+	for _, track := range s.tracks {
+		tracks = append(tracks, track)
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].LastUsed.Before(tracks[j].LastUsed)
+	})
+	return tracks
+}
+
+func (s *OfflineStore) saveIndex() error {
+	data, err := json.Marshal(s.sortedTracks())
+	if err != nil {
+		return err
+	}
+	return s.index.Put(offlineIndexName, data)
+}
+
+// SetOfflineStore sets the store of the pinned tracks, which are then played from it without network access. Pass
+// nil to disable offline playback, which is the default.
+func (p *Player) SetOfflineStore(store *OfflineStore) {
+	p.streamLock.Lock()
+	p.offlineStore = store
+	p.streamLock.Unlock()
+}
+
+// OfflineStore returns the store of the pinned tracks, nil if not set
+func (p *Player) OfflineStore() *OfflineStore {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.offlineStore
+}
+
+// Pin downloads an audio file completely and stores it for offline playback
+func (p *Player) Pin(fileId []byte, format Spotify.AudioFile_Format, trackId []byte) error {
+	store := p.OfflineStore()
+	if store == nil {
+		return ErrNoOfflineStore
+	} else if store.Has(fileId) {
+		return nil
+	}
+
+	audioFile, err := p.LoadTrackWithIdAndFormat(fileId, format, trackId)
+	if err != nil {
+		return err
+	}
+	data, err := audioFile.downloadAll()
+	if err != nil {
+		return err
+	}
+	return store.Put(OfflineTrack{FileId: fileId, TrackId: trackId, Format: format}, data)
+}
+
+// Unpin removes an audio file from the offline store
+func (p *Player) Unpin(fileId []byte) error {
+	store := p.OfflineStore()
+	if store == nil {
+		return ErrNoOfflineStore
+	}
+	return store.Remove(fileId)
+}
+
+// loadPinned returns the audio file from the offline store, or nil if it isn't pinned
+func (p *Player) loadPinned(fileId []byte, format Spotify.AudioFile_Format, trackId []byte) *AudioFile {
+	store := p.OfflineStore()
+	if store == nil || !store.Has(fileId) {
+		return nil
+	}
+	data, _, err := store.Get(fileId)
+	if err != nil {
+		p.logger().Printf("[player] Unable to load pinned file %x, downloading it: %s\n", fileId, err)
+		return nil
+	}
+
+	audioFile := newAudioFileWithIdAndFormat(fileId, format, p)
+	audioFile.trackId = trackId
+	audioFile.plain = true
+	close(audioFile.keyReady)
+
+	audioFile.size = uint32(len(data))
+	audioFile.data = data
+	for i := 0; i < audioFile.totalChunks(); i++ {
+		audioFile.chunks[i] = true
+	}
+	if !audioFile.parseHeader(data) {
+		audioFile.failHeader(io.ErrUnexpectedEOF)
+	}
+	audioFile.finish()
+	return audioFile
+}
+
+// downloadAll waits for all the chunks of the file, and returns its decrypted data
+func (a *AudioFile) downloadAll() ([]byte, error) {
+	a.startDownload()
+	if err := a.waitChunk(0); err != nil {
+		return nil, err
+	}
+	for i := 1; i < a.totalChunks(); i++ {
+		if err := a.waitChunk(i); err != nil {
+			return nil, err
+		}
+	}
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.data[:a.size], nil
+}
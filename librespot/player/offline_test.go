@@ -0,0 +1,187 @@
+package player_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// offlineStream is the connection of a player without network access
+type offlineStream struct{}
+
+func (offlineStream) SendPacket(cmd connection.PacketType, data []byte) error {
+	return errors.New("offline")
+}
+
+func (offlineStream) RecvPacket() (connection.PacketType, []byte, error) {
+	select {}
+}
+
+func TestOfflinePin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key, err := player.NewOfflineKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := make([]byte, 3*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i * 3)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+	if err := server.player.Pin(testFileId, Spotify.AudioFile_MP3_320, testTrackId); err != player.ErrNoOfflineStore {
+		t.Errorf("got %v without offline store, want ErrNoOfflineStore", err)
+	}
+
+	store, err := player.NewOfflineStore(dir, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.player.SetOfflineStore(store)
+	if err := server.player.Pin(testFileId, Spotify.AudioFile_MP3_320, testTrackId); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := ioutil.ReadFile(filepath.Join(dir, "f1f1f1f1f1f1f1f1f1f1f1f1f1f1f1f1f1f1f1f1.track"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, plain[:64]) {
+		t.Error("the pinned file is stored in clear")
+	}
+
+	// The pinned track is played by a player without network access, after reopening the store
+	store, err = player.NewOfflineStore(dir, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := offlineStream{}
+	offline := player.CreatePlayer(stream, mercury.CreateMercury(stream))
+	offline.SetOfflineStore(store)
+	file, err := offline.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("pinned data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+
+	// The store can't be read with another key
+	otherKey, _ := player.NewOfflineKey()
+	other, err := player.NewOfflineStore(dir, otherKey, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := other.Get(testFileId); err == nil {
+		t.Error("pinned file decrypted with another key")
+	}
+
+	if err := offline.Unpin(testFileId); err != nil {
+		t.Fatal(err)
+	}
+	if store.Has(testFileId) || len(store.Tracks()) != 0 {
+		t.Error("file still pinned")
+	}
+}
+
+func TestOfflineEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key, _ := player.NewOfflineKey()
+
+	// Each file takes 100 bytes, plus the nonce and tag of AES-GCM
+	store, err := player.NewOfflineStore(dir, key, 2*(100+28))
+	if err != nil {
+		t.Fatal(err)
+	}
+	put := func(id byte) {
+		err := store.Put(player.OfflineTrack{FileId: []byte{id}}, make([]byte, 100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	put(1)
+	put(2)
+	// Play the first file, so that the second one becomes the least recently played
+	if _, _, err := store.Get([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	put(3)
+
+	if !store.Has([]byte{1}) || store.Has([]byte{2}) || !store.Has([]byte{3}) {
+		t.Errorf("got %d files, want the second one evicted", len(store.Tracks()))
+	}
+	if store.Size() != 2*(100+28) {
+		t.Errorf("got size %d, want %d", store.Size(), 2*(100+28))
+	}
+	if tracks := store.Tracks(); tracks[0].FileId[0] != 3 {
+		t.Errorf("got file %d first, want the most recent one", tracks[0].FileId[0])
+	}
+	if err := store.Put(player.OfflineTrack{FileId: []byte{4}}, make([]byte, 1000)); err == nil {
+		t.Error("file larger than the store pinned")
+	}
+}
+
+func TestOfflineVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key, _ := player.NewOfflineKey()
+
+	store, err := player.NewOfflineStore(dir, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []byte{1, 2} {
+		if err := store.Put(player.OfflineTrack{FileId: []byte{id}}, make([]byte, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The second file is truncated by a power loss, and a third one is written but missing from the index
+	if err := os.Truncate(filepath.Join(dir, "02.track"), 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "03.track"), make([]byte, 128), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := store.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 2 || report.Evicted != 2 {
+		t.Errorf("Got report %+v", report)
+	}
+	if !store.Has([]byte{1}) || store.Has([]byte{2}) {
+		t.Errorf("Got %d files, want the second one removed", len(store.Tracks()))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "03.track")); !os.IsNotExist(err) {
+		t.Errorf("File missing from the index kept: %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// The Spotify Ogg files start with a custom Ogg page of kOggSkipBytes bytes, holding the normalization data, followed
+// by the standard Vorbis stream.
+const (
+	oggNormalizationOffset = 144
+	oggPageHeaderSize      = 27
+	vorbisIdHeaderSize     = 30
+)
+
+// ErrNotOgg is returned when requesting the Ogg header of a file in another format
+var ErrNotOgg = errors.New("not an Ogg Vorbis file")
+
+// ErrSeekSuperseded is returned by SeekTime when another seek of the same file starts before it completes
+var ErrSeekSuperseded = errors.New("seek superseded by a later one")
+
+// OggHeader holds the information found at the beginning of a Spotify Ogg Vorbis file: the normalization data
+// from the Spotify header, and the stream parameters from the Vorbis identification header.
+type OggHeader struct {
+	TrackGainDb float32
+	TrackPeak   float32
+	AlbumGainDb float32
+	AlbumPeak   float32
+
+	Channels   int
+	SampleRate int
+	// NominalBitrate is the average bitrate of the stream, in bits per second. It may be 0 if unknown.
+	NominalBitrate int
+}
+
+// EstimatedDuration estimates the duration of a file of the specified size (header excluded) from its nominal
+// bitrate. It returns 0 if the bitrate is unknown.
+func (h *OggHeader) EstimatedDuration(size uint32) time.Duration {
+	if h.NominalBitrate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(size) * 8 / float64(h.NominalBitrate) * float64(time.Second))
+}
+
+// ParseOggHeader parses the header of a decrypted Spotify Ogg Vorbis file. Only the first few hundred bytes of the
+// file are needed: if data is too short, io.ErrUnexpectedEOF is returned and parsing can be retried once more data
+// has arrived.
+func ParseOggHeader(data []byte) (*OggHeader, error) {
+	if len(data) < 4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if !bytes.Equal(data[:4], []byte("OggS")) {
+		return nil, errors.New("missing Ogg page header")
+	}
+
+	if len(data) < kOggSkipBytes+oggPageHeaderSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	header := &OggHeader{
+		TrackGainDb: readFloat32(data, oggNormalizationOffset),
+		TrackPeak:   readFloat32(data, oggNormalizationOffset+4),
+		AlbumGainDb: readFloat32(data, oggNormalizationOffset+8),
+		AlbumPeak:   readFloat32(data, oggNormalizationOffset+12),
+	}
+
+	page := data[kOggSkipBytes:]
+	if !bytes.Equal(page[:4], []byte("OggS")) {
+		return nil, errors.New("missing Vorbis page header")
+	}
+	segments := int(page[oggPageHeaderSize-1])
+	if len(page) < oggPageHeaderSize+segments+vorbisIdHeaderSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	id := page[oggPageHeaderSize+segments:]
+	if id[0] != 1 || !bytes.Equal(id[1:7], []byte("vorbis")) {
+		return nil, errors.New("missing Vorbis identification header")
+	}
+	header.Channels = int(id[11])
+	header.SampleRate = int(binary.LittleEndian.Uint32(id[12:16]))
+	header.NominalBitrate = int(int32(binary.LittleEndian.Uint32(id[20:24])))
+
+	return header, nil
+}
+
+func readFloat32(data []byte, offset int) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(data[offset : offset+4]))
+}
+
+// OggHeader returns the header of the file, waiting for it if needed. It is parsed as soon as enough bytes of the
+// first chunk have been received, before the whole chunk is downloaded.
+func (a *AudioFile) OggHeader() (*OggHeader, error) {
+	<-a.headerReady
+	return a.header, a.headerErr
+}
+
+// SeekTime moves the read position of an Ogg Vorbis file to the page from which the decoding reaches position, found
+// by bisecting the granule positions of the pages, and returns the position at the beginning of that page. The decoder
+// must be restarted there, with the headers of the stream. The download resumes from the new read position, the
+// chunks before it being downloaded last.
+//
+// Successive seeks are coalesced into the last one, e.g. while scrubbing: a seek still in progress when another one
+// starts gives up with ErrSeekSuperseded without moving the read position, and the chunk downloads it started are
+// cancelled.
+func (a *AudioFile) SeekTime(position time.Duration) (time.Duration, error) {
+	if position < 0 {
+		return 0, fmt.Errorf("negative position")
+	}
+	ctx, done := a.startSeek()
+	defer done()
+
+	var header *OggHeader
+	select {
+	case <-a.headerReady:
+		header = a.header
+		if a.headerErr != nil {
+			return 0, a.headerErr
+		}
+	case <-ctx.Done():
+		return 0, ErrSeekSuperseded
+	}
+	if header.SampleRate <= 0 {
+		return 0, fmt.Errorf("invalid sample rate %d", header.SampleRate)
+	}
+
+	// The pages are read without changing the download order, see ReadAt
+	r := seekReader{file: a, ctx: ctx}
+	start, err := OggAudioStart(r)
+	if ctx.Err() != nil {
+		return 0, ErrSeekSuperseded
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to read the Vorbis headers: %v", err)
+	}
+	granule := int64(position) * int64(header.SampleRate) / int64(time.Second)
+	a.lock.RLock()
+	size := int64(a.size) - int64(a.headerOffset())
+	a.lock.RUnlock()
+	offset, before, err := OggSeekPage(r, start, size, granule)
+	if ctx.Err() != nil {
+		return 0, ErrSeekSuperseded
+	} else if err != nil {
+		return 0, err
+	}
+
+	// Only the last seek moves the read position
+	a.seekLock.Lock()
+	defer a.seekLock.Unlock()
+	if ctx.Err() != nil {
+		return 0, ErrSeekSuperseded
+	}
+	if _, err := a.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	a.prioritize(a.chunkIndexAtByte(int(offset) + a.headerOffset()))
+	return time.Duration(before * int64(time.Second) / int64(header.SampleRate)), nil
+}
+
+// startSeek cancels the seek in progress, if any, and returns the context of a new one, which is cancelled in turn by
+// the next seek, and the function to call once it is over
+func (a *AudioFile) startSeek() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.seekLock.Lock()
+	if a.cancelSeek != nil {
+		a.cancelSeek()
+	}
+	a.cancelSeek = cancel
+	a.seekLock.Unlock()
+	return ctx, cancel
+}
+
+// seekReader reads a file for a seek, the chunks being fetched until the seek is superseded
+type seekReader struct {
+	file *AudioFile
+	ctx  context.Context
+}
+
+func (r seekReader) ReadAt(buf []byte, off int64) (int, error) {
+	return r.file.readAt(r.ctx, buf, off)
+}
+
+// prioritize moves the chunks from index on to the front of the download order, so that the download resumes there.
+// The download in progress is cancelled if it isn't one of the next chunks, and queued again in order.
+func (a *AudioFile) prioritize(index int) {
+	a.chunkLock.Lock()
+	order := a.chunkLoadOrder
+	if a.cancelChunk != nil && (a.loadingChunk < index || a.loadingChunk > index+1) {
+		a.cancelChunk()
+		a.cancelChunk = nil
+		order = append([]int{a.loadingChunk}, order...)
+	}
+	var after, before []int
+	for _, i := range order {
+		if i >= index {
+			after = append(after, i)
+		} else {
+			before = append(before, i)
+		}
+	}
+	a.chunkLoadOrder = append(after, before...)
+	a.chunkLock.Unlock()
+
+	go a.loadNextChunk()
+}
+
+// parseHeader tries to parse the header from the decrypted beginning of the file, returning true once done
+func (a *AudioFile) parseHeader(data []byte) bool {
+	select {
+	case <-a.headerReady:
+		return true
+	default:
+	}
+
+	header, err := ParseOggHeader(data)
+	if err == io.ErrUnexpectedEOF {
+		return false
+	}
+
+	a.header, a.headerErr = header, err
+	close(a.headerReady)
+	return true
+}
+
+// parsePartialHeader tries to parse the header from the encrypted beginning of the first chunk, if the audio key
+// is available
+func (a *AudioFile) parsePartialHeader(encrypted []byte) {
+	if !a.headerPending() || !a.keyAvailable() || len(encrypted) == 0 {
+		return
+	}
+
+	prefix := encrypted[:min(len(encrypted), ChunkAlignment)]
+	a.parseHeader(a.decrypt(0, prefix, make([]byte, len(prefix))))
+}
+
+// failHeader makes the pending OggHeader calls return the error, if the header was not parsed yet
+func (a *AudioFile) failHeader(err error) {
+	if a.headerPending() {
+		a.headerErr = err
+		close(a.headerReady)
+	}
+}
+
+// headerPending returns true while the header has not been parsed
+func (a *AudioFile) headerPending() bool {
+	select {
+	case <-a.headerReady:
+		return false
+	default:
+		return true
+	}
+}
+
+// keyAvailable returns true if the audio key was received
+func (a *AudioFile) keyAvailable() bool {
+	select {
+	case <-a.keyReady:
+		return a.keyErr == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package player_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// makeOggFile builds the beginning of a Spotify Ogg Vorbis file
+func makeOggFile(size int) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString("OggS")
+	buf.Write(make([]byte, 140))
+	for _, v := range []float32{-6.5, 0.9, -7.5, 0.95} {
+		binary.Write(buf, binary.LittleEndian, math.Float32bits(v))
+	}
+	buf.Write(make([]byte, 167-buf.Len()))
+
+	// Vorbis page, with a single segment holding the identification header
+	buf.WriteString("OggS")
+	buf.Write(make([]byte, 22))
+	buf.WriteByte(1)
+	buf.WriteByte(30)
+	buf.WriteByte(1)
+	buf.WriteString("vorbis")
+	binary.Write(buf, binary.LittleEndian, uint32(0))
+	buf.WriteByte(2)
+	binary.Write(buf, binary.LittleEndian, uint32(44100))
+	binary.Write(buf, binary.LittleEndian, uint32(0))
+	binary.Write(buf, binary.LittleEndian, uint32(160000))
+	binary.Write(buf, binary.LittleEndian, uint32(0))
+	buf.Write([]byte{0xb8, 1})
+
+	return append(buf.Bytes(), make([]byte, size-buf.Len())...)
+}
+
+func TestParseOggHeader(t *testing.T) {
+	data := makeOggFile(300)
+
+	if _, err := player.ParseOggHeader(data[:200]); err != io.ErrUnexpectedEOF {
+		t.Errorf("Short header not detected: %v", err)
+	}
+
+	header, err := player.ParseOggHeader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.TrackGainDb != -6.5 || header.AlbumPeak != 0.95 || header.Channels != 2 ||
+		header.SampleRate != 44100 || header.NominalBitrate != 160000 {
+		t.Errorf("Bad header %+v", header)
+	}
+	if d := header.EstimatedDuration(200000); d != 10*time.Second {
+		t.Errorf("Bad estimated duration %v", d)
+	}
+}
+
+func TestOggHeaderBeforeFirstChunk(t *testing.T) {
+	server := newFakeAudioServer(time.Millisecond, makeOggFile(2*player.ChunkAlignment))
+	server.hold = make(chan struct{})
+	defer close(server.hold)
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_OGG_VORBIS_160, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first bytes of the first chunk are sent until the end of the test
+	headers := make(chan *player.OggHeader, 1)
+	go func() {
+		header, err := file.OggHeader()
+		if err != nil {
+			t.Error(err)
+		}
+		headers <- header
+	}()
+
+	select {
+	case header := <-headers:
+		if header == nil || header.SampleRate != 44100 {
+			t.Errorf("Bad header %+v", header)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("header not parsed from the partial chunk")
+	}
+}
@@ -0,0 +1,209 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// oggPageSearchSize is the size of the blocks read while looking for the next page
+	oggPageSearchSize = 8 << 10
+	// oggBisectionLimit is the size of the range below which the pages are scanned one by one rather than bisected
+	oggBisectionLimit = 64 << 10
+)
+
+var oggCapturePattern = []byte("OggS")
+
+var (
+	errInvalidOggPage = errors.New("invalid Ogg page")
+	errNoOggPage      = errors.New("no Ogg page found")
+)
+
+// oggPage is the position of an Ogg page in a stream
+type oggPage struct {
+	offset int64
+	size   int64
+	// granule is the number of samples decoded at the end of the last packet of the page, or -1 if no packet ends
+	// in it
+	granule int64
+}
+
+// end returns the offset of the page following this one
+func (p oggPage) end() int64 {
+	return p.offset + p.size
+}
+
+// oggCrcTable is the table of the CRC-32 of the Ogg pages, with the polynomial 0x04c11db7, not reflected
+var oggCrcTable = func() (table [256]uint32) {
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggPageChecksum returns the checksum of a page, computed with its checksum field zeroed
+func oggPageChecksum(data []byte) uint32 {
+	var crc uint32
+	for i, b := range data {
+		if i >= 22 && i < 26 {
+			b = 0
+		}
+		crc = crc<<8 ^ oggCrcTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// readFullAt reads exactly len(buf) bytes at an offset, returning io.EOF if there are none, and
+// io.ErrUnexpectedEOF if there are fewer
+func readFullAt(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return nil
+	} else if err == io.EOF && n > 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readOggPage reads the page at an offset, returning errInvalidOggPage if there is none or it is corrupt
+func readOggPage(r io.ReaderAt, offset int64) (oggPage, error) {
+	header := make([]byte, oggPageHeaderSize, oggPageHeaderSize+255)
+	if err := readFullAt(r, header, offset); err != nil {
+		return oggPage{}, err
+	}
+	if !bytes.Equal(header[:4], oggCapturePattern) || header[4] != 0 {
+		return oggPage{}, errInvalidOggPage
+	}
+	segments := header[oggPageHeaderSize:][:header[26]]
+	if err := readFullAt(r, segments, offset+oggPageHeaderSize); err != nil {
+		return oggPage{}, err
+	}
+	var size int
+	for _, s := range segments {
+		size += int(s)
+	}
+	headerSize := oggPageHeaderSize + len(segments)
+	data := append(header[:headerSize], make([]byte, size)...)
+	if err := readFullAt(r, data[headerSize:], offset+int64(headerSize)); err != nil {
+		return oggPage{}, err
+	}
+	if oggPageChecksum(data) != binary.LittleEndian.Uint32(data[22:26]) {
+		return oggPage{}, errInvalidOggPage
+	}
+	return oggPage{
+		offset:  offset,
+		size:    int64(len(data)),
+		granule: int64(binary.LittleEndian.Uint64(data[6:14])),
+	}, nil
+}
+
+// nextOggPage returns the first valid page starting between from and end, or errNoOggPage
+func nextOggPage(r io.ReaderAt, from, end int64) (oggPage, error) {
+	buf := make([]byte, oggPageSearchSize)
+	for from < end {
+		block := buf
+		if end-from < int64(len(block)) {
+			block = block[:end-from]
+		}
+		n, err := r.ReadAt(block, from)
+		if err != nil && err != io.EOF {
+			return oggPage{}, err
+		}
+		block = block[:n]
+
+		for i := 0; ; i++ {
+			j := bytes.Index(block[i:], oggCapturePattern)
+			if j < 0 {
+				break
+			}
+			i += j
+			p, err := readOggPage(r, from+int64(i))
+			if err == nil {
+				return p, nil
+			} else if err != errInvalidOggPage && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return oggPage{}, err
+			}
+		}
+
+		if n < len(buf) {
+			break
+		}
+		// The capture pattern may span over two blocks
+		from += int64(n - len(oggCapturePattern) + 1)
+	}
+	return oggPage{}, errNoOggPage
+}
+
+// OggAudioStart returns the offset of the first audio page of an Ogg Vorbis stream, following the pages of its three
+// headers
+func OggAudioStart(r io.ReaderAt) (int64, error) {
+	var offset int64
+	for {
+		p, err := readOggPage(r, offset)
+		if err != nil {
+			return 0, err
+		}
+		// The headers end their page, and have no granule position
+		if p.granule != 0 {
+			return offset, nil
+		}
+		offset = p.end()
+	}
+}
+
+// OggSeekPage returns the offset of the page to start decoding an Ogg stream from to reach a granule position, i.e.
+// a number of samples, and the granule position at the beginning of that page. The pages between start, the first
+// audio page, and end are bisected until the range is small enough to be scanned. The corrupt pages are skipped.
+func OggSeekPage(r io.ReaderAt, start, end, granule int64) (int64, int64, error) {
+	offset, before := start, int64(0)
+
+	// low is always at the beginning of a page, while high may be in the middle of one
+	low, high := start, end
+	for high-low > oggBisectionLimit {
+		middle := low + (high-low)/2
+		p, err := nextOggPage(r, middle, high)
+		for err == nil && p.granule == -1 {
+			p, err = nextOggPage(r, p.end(), high)
+		}
+		if err == errNoOggPage {
+			high = middle
+			continue
+		} else if err != nil {
+			return 0, 0, err
+		}
+
+		if p.granule <= granule {
+			offset, before = p.end(), p.granule
+			low = offset
+		} else {
+			high = middle
+		}
+	}
+
+	for low < end {
+		p, err := nextOggPage(r, low, end)
+		if err == errNoOggPage {
+			break
+		} else if err != nil {
+			return 0, 0, err
+		}
+		if p.granule > granule {
+			break
+		}
+		if p.granule != -1 {
+			offset, before = p.end(), p.granule
+		}
+		low = p.end()
+	}
+	return offset, before, nil
+}
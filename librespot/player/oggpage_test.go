@@ -0,0 +1,141 @@
+package player_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/oggtest"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+func TestOggSeekPage(t *testing.T) {
+	stream, ends := oggtest.Stream(300)
+	r := bytes.NewReader(stream)
+
+	start, err := player.OggAudioStart(r)
+	if err != nil || start != ends[0] {
+		t.Fatalf("Got audio start %d, %v, expected %d", start, err, ends[0])
+	}
+
+	for _, test := range []struct {
+		granule, before int64
+	}{
+		{0, 0},
+		{999, 0},
+		{1000, 1000},
+		{9500, 9000},
+		{10500, 9000},
+		{11000, 11000},
+		{20500, 20000},
+		{150999, 150000},
+		{299999, 299000},
+		{400000, 300000},
+	} {
+		offset, before, err := player.OggSeekPage(r, start, int64(len(stream)), test.granule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if before != test.before || offset != ends[test.before] {
+			t.Errorf("Seeking to %d: got page at %d after %d samples, expected %d after %d", test.granule, offset,
+				before, ends[test.before], test.before)
+		}
+	}
+
+	// The corrupt pages are skipped
+	corrupt := append([]byte{}, stream...)
+	corrupt[ends[150000]+30] ^= 0xff
+	offset, before, err := player.OggSeekPage(bytes.NewReader(corrupt), start, int64(len(corrupt)), 151500)
+	if err != nil || before != 150000 || offset != ends[150000] {
+		t.Errorf("Got page at %d after %d samples, %v", offset, before, err)
+	}
+}
+
+func TestSeekTime(t *testing.T) {
+	stream, ends := oggtest.Stream(300)
+	// The size of the files is a multiple of 4 bytes
+	plain := append(makeOggFile(300)[:167], stream...)
+	plain = append(plain, make([]byte, (4-len(plain)%4)%4)...)
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_OGG_VORBIS_160, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1000 samples are about 22.7ms at 44.1kHz
+	position, err := file.SeekTime(3 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 132000*time.Second/44100 {
+		t.Errorf("Got position %v", position)
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if expected := plain[167+ends[132000]:]; err != nil || !bytes.Equal(data, expected) {
+		t.Errorf("Read %d bytes after seeking, %v, expected %d", len(data), err, len(expected))
+	}
+}
+
+func TestSeekTimeCoalescing(t *testing.T) {
+	stream, ends := oggtest.Stream(300)
+	plain := append(makeOggFile(300)[:167], stream...)
+	plain = append(plain, make([]byte, (4-len(plain)%4)%4)...)
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+	server.hold = make(chan struct{})
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_OGG_VORBIS_160, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first seek waits for the pages it bisects, until the second one supersedes it
+	superseded := make(chan error, 1)
+	go func() {
+		_, err := file.SeekTime(time.Second)
+		superseded <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	type result struct {
+		position time.Duration
+		err      error
+	}
+	last := make(chan result, 1)
+	go func() {
+		position, err := file.SeekTime(3 * time.Second)
+		last <- result{position, err}
+	}()
+	select {
+	case err := <-superseded:
+		if err != player.ErrSeekSuperseded {
+			t.Errorf("Expected ErrSeekSuperseded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The first seek wasn't superseded")
+	}
+
+	close(server.hold)
+	r := <-last
+	if r.err != nil || r.position != 132000*time.Second/44100 {
+		t.Fatalf("Got position %v, %v", r.position, r.err)
+	}
+	data, err := ioutil.ReadAll(file)
+	if expected := plain[167+ends[132000]:]; err != nil || !bytes.Equal(data, expected) {
+		t.Errorf("Read %d bytes after seeking, %v, expected %d", len(data), err, len(expected))
+	}
+
+	// The chunks whose download was abandoned are downloaded after the ones following the position
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadAll(file)
+	if err != nil || !bytes.Equal(data, plain[167:]) {
+		t.Errorf("Read %d bytes from the start, %v, expected %d", len(data), err, len(plain)-167)
+	}
+}
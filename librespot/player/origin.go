@@ -0,0 +1,58 @@
+package player
+
+import (
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// PlayOrigin describes where a play comes from, so that it can be attributed correctly when reported to Spotify
+// (playback events, Connect state). The field names follow the official clients semantics.
+type PlayOrigin struct {
+	// ContextUri is the URI of the context being played (playlist, album, artist, ...)
+	ContextUri string
+	// ReferrerIdentifier identifies the feature the user came from before starting the playback (e.g. "search")
+	ReferrerIdentifier string
+	// FeatureIdentifier identifies the feature that started the playback (e.g. "librespot-golang")
+	FeatureIdentifier string
+	// FeatureVersion is the version of the feature that started the playback
+	FeatureVersion string
+	// ViewUri is the URI of the view the playback has been started from
+	ViewUri string
+	// License is the licensing mode the track is played with (e.g. "premium", "on-demand", "shuffle")
+	License string
+}
+
+// DefaultFeatureIdentifier is used when no feature identifier is set on a PlayOrigin
+const DefaultFeatureIdentifier = "librespot-golang"
+
+// Metadata returns the origin as Spirc metadata entries. Empty fields are omitted.
+func (o PlayOrigin) Metadata() []*Spotify.Metadata {
+	featureIdentifier := o.FeatureIdentifier
+	if featureIdentifier == "" {
+		featureIdentifier = DefaultFeatureIdentifier
+	}
+
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"context_uri", o.ContextUri},
+		{"referrer_identifier", o.ReferrerIdentifier},
+		{"feature_identifier", featureIdentifier},
+		{"feature_version", o.FeatureVersion},
+		{"view_uri", o.ViewUri},
+		{"license", o.License},
+	}
+
+	res := make([]*Spotify.Metadata, 0, len(fields))
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		res = append(res, &Spotify.Metadata{
+			Type:     proto.String(f.key),
+			Metadata: proto.String(f.value),
+		})
+	}
+	return res
+}
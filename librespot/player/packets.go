@@ -31,3 +31,12 @@ func buildKeyRequest(seq []byte, trackId []byte, fileId []byte) []byte {
 
 	return buf.Bytes()
 }
+
+func buildImageRequest(channel uint16, fileId []byte) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, channel)
+	binary.Write(buf, binary.BigEndian, uint16(0x0000))
+	buf.Write(fileId)
+
+	return buf.Bytes()
+}
@@ -1,38 +1,180 @@
 package player
 
 import (
-	"bytes"
-	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/connection"
 	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/ops"
 	"log"
+	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Player struct {
-	stream   connection.PacketStream
-	mercury  *mercury.Client
-	seq      uint32
-	audioKey []byte
+	stream     connection.PacketStream
+	streamLock sync.RWMutex
+	mercury    *mercury.Client
+	seq        uint32
+	audioKey   []byte
+	chunkCache ChunkCache
+	// chunkSize is accessed atomically, the tracks being loaded concurrently with SetChunkSize
+	chunkSize  int32
+	quality    Quality
+	decryption DecryptionBackend
+	codecs     []string
+	registry   *ops.Registry
 
-	chanLock    sync.Mutex
-	seqChanLock sync.Mutex
-	channels    map[uint16]*Channel
-	seqChans    sync.Map
-	nextChan    uint16
+	httpClient      *http.Client
+	storageResolver StorageResolver
+	audioSource     AudioSource
+	cdnParallelism  int
+	offlineStore    *OfflineStore
+	normalization   *NormalizationConfig
+	maxBitrate      int
+	shuffleOnly     bool
+
+	// listenersLock protects listeners and contentFilters
+	listenersLock  sync.Mutex
+	listeners      []EventListener
+	contentFilters []ContentFilter
+
+	keys            *audioKeyManager
+	audioKeyTimeout time.Duration
+
+	channels *ChannelManager
+
+	log *log.Logger
 }
 
 func CreatePlayer(conn connection.PacketStream, client *mercury.Client) *Player {
-	return &Player{
-		stream:   conn,
-		mercury:  client,
-		channels: map[uint16]*Channel{},
-		seqChans: sync.Map{},
-		chanLock: sync.Mutex{},
-		nextChan: 0,
+	p := &Player{
+		stream:  conn,
+		mercury: client,
+
+		chunkSize:       DefaultChunkSize,
+		audioKeyTimeout: DefaultAudioKeyTimeout,
+		cdnParallelism:  DefaultCDNParallelism,
+	}
+	p.keys = newAudioKeyManager(p.getStream)
+	p.channels = newChannelManager(p.getStream)
+	return p
+}
+
+// SetAudioKeyTimeout changes how long to wait for the audio keys. It must be called before loading tracks.
+func (p *Player) SetAudioKeyTimeout(timeout time.Duration) {
+	p.audioKeyTimeout = timeout
+}
+
+// SetLogger sets the logger receiving the messages of the player, the standard logger being used if nil. It must be
+// called before loading tracks.
+func (p *Player) SetLogger(logger *log.Logger) {
+	p.log = logger
+}
+
+// logger returns the logger of the player, see SetLogger
+func (p *Player) logger() *log.Logger {
+	if p.log == nil {
+		return log.Default()
+	}
+	return p.log
+}
+
+// GetAudioKey requests the key decrypting the audio file of a track. Several keys can be requested concurrently. An
+// *AudioKeyError is returned if the server refuses to send the key, and ErrAudioKeyTimeout if it doesn't answer in
+// time.
+func (p *Player) GetAudioKey(trackGid []byte, fileId []byte) ([]byte, error) {
+	return p.keys.request(trackGid, fileId, p.audioKeyTimeout)
+}
+
+// SetChunkSize changes the size of the audio chunks requested to the server, in bytes. Small chunks reduce the
+// memory used while downloading on low-memory devices, large chunks reduce the number of requests on fast
+// connections. The size must be a multiple of ChunkAlignment. It only applies to the tracks loaded afterwards.
+//
+// The chunk cache, if any, must not be shared between players using different chunk sizes, as chunks are cached by
+// index.
+func (p *Player) SetChunkSize(size int) error {
+	if size <= 0 || size%ChunkAlignment != 0 {
+		return fmt.Errorf("invalid chunk size %d, must be a positive multiple of %d", size, ChunkAlignment)
 	}
+
+	atomic.StoreInt32(&p.chunkSize, int32(size))
+	return nil
+}
+
+// ChunkSize returns the size of the audio chunks requested to the server, in bytes
+func (p *Player) ChunkSize() int {
+	return int(atomic.LoadInt32(&p.chunkSize))
+}
+
+// SetQuality sets the quality used by SelectAudioFile, and the codecs the application can decode (all if empty)
+func (p *Player) SetQuality(quality Quality, codecs ...string) {
+	p.streamLock.Lock()
+	p.quality = quality
+	p.codecs = codecs
+	p.streamLock.Unlock()
+}
+
+// Quality returns the quality used by SelectAudioFile
+func (p *Player) Quality() Quality {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	if p.quality == 0 {
+		return DefaultQuality
+	}
+	return p.quality
+}
+
+// SelectAudioFile picks the file of a track matching the quality, codecs and maximum bitrate of the player, see
+// SelectQuality
+func (p *Player) SelectAudioFile(files []*Spotify.AudioFile) (*FormatSelection, error) {
+	p.streamLock.RLock()
+	codecs := p.codecs
+	p.streamLock.RUnlock()
+	return SelectQuality(files, p.Quality(), p.MaxBitrate(), codecs...)
+}
+
+// SetDecryptionBackend selects how the audio files are decrypted, DecryptionAuto by default. It only applies to the
+// tracks loaded afterwards.
+func (p *Player) SetDecryptionBackend(backend DecryptionBackend) {
+	p.streamLock.Lock()
+	p.decryption = backend
+	p.streamLock.Unlock()
+}
+
+// DecryptionBackend returns the backend decrypting the audio files, resolving DecryptionAuto
+func (p *Player) DecryptionBackend() DecryptionBackend {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.decryption.resolve()
+}
+
+// SetStream replaces the connection used to request audio keys and data, e.g. after a reconnection
+func (p *Player) SetStream(stream connection.PacketStream) {
+	p.streamLock.Lock()
+	p.stream = stream
+	p.streamLock.Unlock()
+}
+
+// SetChunkCache sets the cache used to store the downloaded audio chunks. It must be called before loading tracks.
+// Pass nil to disable caching, which is the default.
+func (p *Player) SetChunkCache(cache ChunkCache) {
+	p.chunkCache = cache
+}
+
+// SetRegistry sets the registry tracking the audio downloads, so that they can be cancelled. It must be called
+// before loading tracks.
+func (p *Player) SetRegistry(registry *ops.Registry) {
+	p.registry = registry
+}
+
+func (p *Player) getStream() connection.PacketStream {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.stream
 }
 
 func (p *Player) LoadTrack(file *Spotify.AudioFile, trackId []byte) (*AudioFile, error) {
@@ -42,86 +184,103 @@ func (p *Player) LoadTrack(file *Spotify.AudioFile, trackId []byte) (*AudioFile,
 func (p *Player) LoadTrackWithIdAndFormat(fileId []byte, format Spotify.AudioFile_Format, trackId []byte) (*AudioFile, error) {
 	// fmt.Printf("[player] Loading track audio key, fileId: %s, trackId: %s\n", utils.ConvertTo62(fileId), utils.ConvertTo62(trackId))
 
+	if audioFile := p.loadPinned(fileId, format, trackId); audioFile != nil {
+		return audioFile, nil
+	}
+
 	// Allocate an AudioFile and a channel
 	audioFile := newAudioFileWithIdAndFormat(fileId, format, p)
+	audioFile.trackId = trackId
+	p.useCDN(audioFile)
 
-	// Start loading the audio key
-	err := audioFile.loadKey(trackId)
-
-	// Then start loading the audio itself
+	// Start downloading the audio right away, so that the first chunk is requested concurrently with the audio key.
+	// The downloaded chunks are decrypted as soon as the key is received.
 	audioFile.loadChunks()
 
+	err := audioFile.loadKey(trackId)
+
 	return audioFile, err
 }
 
-func (p *Player) loadTrackKey(trackId []byte, fileId []byte) ([]byte, error) {
-	seqInt, seq := p.mercury.NextSeqWithInt()
+// prefetchTrack starts loading a track in the background: its audio key and first chunk are requested, and the rest
+// of the file is only downloaded once it is read, or startDownload is called
+func (p *Player) prefetchTrack(fileId []byte, format Spotify.AudioFile_Format, trackId []byte) *AudioFile {
+	if audioFile := p.loadPinned(fileId, format, trackId); audioFile != nil {
+		return audioFile
+	}
 
-	p.seqChans.Store(seqInt, make(chan []byte))
+	audioFile := newAudioFileWithIdAndFormat(fileId, format, p)
+	audioFile.trackId = trackId
+	audioFile.prefetching = true
+	p.useCDN(audioFile)
 
-	req := buildKeyRequest(seq, trackId, fileId)
-	err := p.stream.SendPacket(connection.PacketRequestKey, req)
-	if err != nil {
-		log.Println("Error while sending packet", err)
-		return nil, err
+	audioFile.loadChunks()
+	go audioFile.loadKey(trackId)
+	return audioFile
+}
+
+// LoadTrackWithMetadata loads a track like LoadTrackWithIdAndFormat, fetching its metadata concurrently with the
+// audio key and the first chunk of audio, so that the metadata doesn't delay the start of the playback
+func (p *Player) LoadTrackWithMetadata(fileId []byte, format Spotify.AudioFile_Format, trackId []byte) (*AudioFile, *Spotify.Track, error) {
+	type metadataResult struct {
+		track *Spotify.Track
+		err   error
 	}
 
-	channel, _ := p.seqChans.Load(seqInt)
-	key := <-channel.(chan []byte)
-	p.seqChans.Delete(seqInt)
+	done := make(chan metadataResult, 1)
+	go func() {
+		track, err := p.mercury.GetTrack(hex.EncodeToString(trackId))
+		done <- metadataResult{track, err}
+	}()
+
+	audioFile, err := p.LoadTrackWithIdAndFormat(fileId, format, trackId)
+	res := <-done
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.err != nil {
+		return nil, nil, fmt.Errorf("failed to get track metadata: %v", res.err)
+	}
+	return audioFile, res.track, nil
+}
 
-	return key, nil
+// Channels returns the manager of the channels through which the audio data and cover art are received
+func (p *Player) Channels() *ChannelManager {
+	return p.channels
 }
 
 func (p *Player) AllocateChannel() *Channel {
-	p.chanLock.Lock()
-	channel := NewChannel(p.nextChan, p.releaseChannel)
-	p.nextChan++
+	return p.channels.Allocate()
+}
 
-	p.channels[channel.num] = channel
-	p.chanLock.Unlock()
+// LastActivity returns the time audio data was last received, and whether audio data is being downloaded
+func (p *Player) LastActivity() (time.Time, bool) {
+	return p.channels.lastActivity()
+}
 
-	return channel
+// ResetDownloads aborts the audio downloads in flight, and releases their channels. The aborted audio files report
+// an error, and must be loaded again.
+func (p *Player) ResetDownloads() {
+	for _, op := range p.registry.List() {
+		if op.Kind == ops.KindAudioDownload {
+			p.registry.Cancel(op.Id)
+		}
+	}
+	p.channels.Reset()
 }
 
-func (p *Player) HandleCmd(cmd byte, data []byte) {
+func (p *Player) HandleCmd(cmd connection.PacketType, data []byte) {
 	switch {
-	case cmd == connection.PacketAesKey:
-		// Audio key response
-		dataReader := bytes.NewReader(data)
-		var seqNum uint32
-		binary.Read(dataReader, binary.BigEndian, &seqNum)
-
-		if channel, ok := p.seqChans.Load(seqNum); ok {
-			channel.(chan []byte) <- data[4:20]
-		} else {
-			fmt.Printf("[player] Unknown channel for audio key seqNum %d\n", seqNum)
+	case cmd == connection.PacketAesKey, cmd == connection.PacketAesKeyError:
+		// Audio key response or error
+		if err := p.keys.handle(cmd, data); err != nil {
+			p.logger().Printf("[player] %v\n", err)
 		}
 
-	case cmd == connection.PacketAesKeyError:
-		// Audio key error
-		fmt.Println("[player] Audio key error!")
-		fmt.Printf("%x\n", data)
-
-	case cmd == connection.PacketStreamChunkRes:
-		// Audio data response
-		var channel uint16
-		dataReader := bytes.NewReader(data)
-		binary.Read(dataReader, binary.BigEndian, &channel)
-
-		// fmt.Printf("[player] Data on channel %d: %d bytes\n", channel, len(data[2:]))
-
-		if val, ok := p.channels[channel]; ok {
-			val.handlePacket(data[2:])
-		} else {
-			fmt.Printf("Unknown channel!\n")
+	case cmd == connection.PacketStreamChunkRes, cmd == connection.PacketChannelError:
+		// Audio data response or channel error
+		if err := p.channels.Handle(cmd, data); err != nil {
+			p.logger().Printf("[player] %v\n", err)
 		}
 	}
 }
-
-func (p *Player) releaseChannel(channel *Channel) {
-	p.chanLock.Lock()
-	delete(p.channels, channel.num)
-	p.chanLock.Unlock()
-	// fmt.Printf("[player] Released channel %d\n", channel.num)
-}
@@ -0,0 +1,233 @@
+package player_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/ops"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+var testFileId = bytes.Repeat([]byte{0xf1}, 20)
+var testTrackId = bytes.Repeat([]byte{0x71}, 16)
+
+// fakeAudioServer answers the audio key and chunk requests of a player after a fixed latency
+type fakeAudioServer struct {
+	player    *player.Player
+	latency   time.Duration
+	encrypted []byte
+	// hold, if set, delays the data following the first 512 bytes of each chunk until closed
+	hold chan struct{}
+	// refuse, if set, lists the first bytes of the track gids whose audio key is refused
+	refuse map[byte]bool
+}
+
+func newFakeAudioServer(latency time.Duration, plain []byte) *fakeAudioServer {
+	encrypted := make([]byte, len(plain))
+	player.NewAudioFileDecrypter().DecryptAudioWithBlock(0, player.CreateCipher(kTestKey), plain, encrypted)
+
+	server := &fakeAudioServer{latency: latency, encrypted: encrypted}
+	server.player = player.CreatePlayer(server, mercury.CreateMercury(server))
+	return server
+}
+
+func (f *fakeAudioServer) SendPacket(cmd connection.PacketType, data []byte) error {
+	switch cmd {
+	case connection.PacketRequestKey:
+		seq := data[len(testFileId)+len(testTrackId) : len(testFileId)+len(testTrackId)+4]
+		refused := f.refuse[data[len(testFileId)]]
+		go func() {
+			time.Sleep(f.latency)
+			if refused {
+				f.player.HandleCmd(connection.PacketAesKeyError, append(append([]byte{}, seq...), 0x00, 0x01))
+				return
+			}
+			f.player.HandleCmd(connection.PacketAesKey, append(append([]byte{}, seq...), kTestKey...))
+		}()
+
+	case connection.PacketStreamChunk:
+		channel := data[:2]
+		start := binary.BigEndian.Uint32(data[len(data)-8:]) * 4
+		end := binary.BigEndian.Uint32(data[len(data)-4:]) * 4
+		if int(end) > len(f.encrypted) {
+			end = uint32(len(f.encrypted))
+		}
+
+		go func() {
+			time.Sleep(f.latency)
+
+			header := new(bytes.Buffer)
+			header.Write(channel)
+			binary.Write(header, binary.BigEndian, uint16(5))
+			header.WriteByte(0x3)
+			binary.Write(header, binary.BigEndian, uint32(len(f.encrypted)/4))
+			f.player.HandleCmd(connection.PacketStreamChunkRes, header.Bytes())
+
+			for pos := start; pos < end; pos += 512 {
+				if pos > start && f.hold != nil {
+					<-f.hold
+				}
+				f.player.HandleCmd(connection.PacketStreamChunkRes, append(append([]byte{}, channel...),
+					f.encrypted[pos:min(pos+512, end)]...))
+			}
+			f.player.HandleCmd(connection.PacketStreamChunkRes, channel)
+		}()
+	}
+	return nil
+}
+
+func min(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (f *fakeAudioServer) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
+	select {}
+}
+
+func TestLoadTrack(t *testing.T) {
+	plain := make([]byte, 3*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("decrypted data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+}
+
+func TestLoadTrackWithJitter(t *testing.T) {
+	plain := make([]byte, 3*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+
+	// Delay the requests of the player by a random duration, so that the key and chunks arrive in various orders
+	server := newFakeAudioServer(time.Millisecond, plain)
+	faulty := connection.NewFaultyStream(server, connection.Faults{Seed: 7, Jitter: 3 * time.Millisecond})
+	server.player = player.CreatePlayer(faulty, mercury.CreateMercury(faulty))
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("decrypted data mismatch, got %d bytes, want %d", len(data), len(plain))
+	}
+}
+
+func TestCancelDownload(t *testing.T) {
+	plain := make([]byte, 3*player.ChunkAlignment)
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.hold = make(chan struct{})
+	defer close(server.hold)
+
+	registry := ops.NewRegistry()
+	server.player.SetRegistry(registry)
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := registry.List()
+	if len(list) != 1 || list[0].Kind != ops.KindAudioDownload {
+		t.Fatalf("Bad operations %v", list)
+	}
+	if err := registry.Cancel(list[0].Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ioutil.ReadAll(file); err == nil {
+		t.Errorf("Reading a cancelled download should fail")
+	}
+}
+
+// BenchmarkTrackStart measures the time between loading a track and getting its first bytes of audio, with a 5ms
+// latency to the server
+func BenchmarkTrackStart(b *testing.B) {
+	plain := make([]byte, player.DefaultChunkSize+100)
+	server := newFakeAudioServer(5*time.Millisecond, plain)
+	buf := make([]byte, 4096)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := file.Read(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadAtDetached(t *testing.T) {
+	plain := make([]byte, 6*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i * 7)
+	}
+
+	// Hold the main download, so that the data is fetched separately
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.hold = make(chan struct{})
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	file, err := server.player.LoadTrackWithIdAndFormat(testFileId, Spotify.AudioFile_MP3_320, testTrackId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 3000)
+	offset := int64(4*player.ChunkAlignment + 1234)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err := file.ReadAt(buf, offset)
+		if err != nil || n != len(buf) {
+			t.Errorf("ReadAt returned %d, %v", n, err)
+		} else if !bytes.Equal(buf, plain[offset:offset+int64(n)]) {
+			t.Errorf("Detached data mismatch")
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(server.hold)
+	<-done
+
+	n, err := file.ReadAt(buf, int64(len(plain)-100))
+	if err != io.EOF || n != 100 || !bytes.Equal(buf[:n], plain[len(plain)-100:]) {
+		t.Errorf("ReadAt at the end returned %d, %v", n, err)
+	}
+
+	// The read position didn't move
+	data, err := ioutil.ReadAll(file)
+	if err != nil || !bytes.Equal(data, plain) {
+		t.Errorf("Read after ReadAt mismatch, got %d bytes, %v", len(data), err)
+	}
+}
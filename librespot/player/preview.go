@@ -0,0 +1,178 @@
+package player
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrUnknownBitrate is returned when previewing a file whose format doesn't have a known bitrate
+var ErrUnknownBitrate = errors.New("unknown bitrate")
+
+// AudioFile supports detached range reads
+var _ io.ReaderAt = (*AudioFile)(nil)
+
+// ReadAt reads the audio data at the specified offset, like Read after a Seek, but without moving the read position
+// nor changing the order in which the chunks are downloaded. The chunks not downloaded yet are fetched separately,
+// and not kept. It can be called concurrently with Read.
+func (a *AudioFile) ReadAt(buf []byte, off int64) (int, error) {
+	return a.readAt(context.Background(), buf, off)
+}
+
+// readAt implements ReadAt, the chunks being fetched until the context is done
+func (a *AudioFile) readAt(ctx context.Context, buf []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset")
+	}
+	start := int(off) + a.headerOffset()
+	end := start + len(buf)
+
+	a.lock.RLock()
+	size, fileData := int(a.size), a.data
+	a.lock.RUnlock()
+	if fileData != nil && end > size {
+		end = size
+	}
+	if start >= end {
+		return 0, io.EOF
+	}
+
+	if fileData != nil && a.hasRange(start, end) {
+		n := copy(buf, fileData[start:end])
+		return n, a.readAtErr(n, len(buf))
+	}
+
+	data, fileSize, err := a.fetchRange(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+	if end > fileSize {
+		end = fileSize
+	}
+	if start >= end {
+		return 0, io.EOF
+	}
+
+	n := copy(buf, data[start-alignDown(start):end-alignDown(start)])
+	return n, a.readAtErr(n, len(buf))
+}
+
+func (a *AudioFile) readAtErr(n int, requested int) error {
+	if n < requested {
+		return io.EOF
+	}
+	return nil
+}
+
+// hasRange returns whether all the chunks holding the bytes between start and end are downloaded
+func (a *AudioFile) hasRange(start int, end int) bool {
+	for i := a.chunkIndexAtByte(start); i <= a.chunkIndexAtByte(end-1); i++ {
+		if !a.hasChunk(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchRange downloads and decrypts the bytes between start and end, without storing them, until the context is
+// done. The returned data starts at start aligned down to ChunkAlignment, and the size of the whole file is returned
+// along with it.
+func (a *AudioFile) fetchRange(ctx context.Context, start int, end int) ([]byte, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	alignedStart := alignDown(start)
+	if len(a.cdnURLs()) > 0 {
+		encrypted, fileSize, err := a.fetchHTTP(ctx, alignedStart, end)
+		if err != nil {
+			return nil, 0, err
+		}
+		data, err := a.decryptDetached(alignedStart, encrypted)
+		return data, fileSize, err
+	}
+
+	// Offsets are expressed in 4-bytes words
+	reader, err := a.player.channels.RequestAudioChunk(a.fileId, uint32(alignedStart/4), uint32((end+3)/4))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer reader.Close()
+	if ctx.Done() != nil {
+		// Closing the reader aborts the reads waiting for the data
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				reader.Close()
+			case <-done:
+			}
+		}()
+	}
+
+	header, ok, err := reader.Header(0x3)
+	if err != nil {
+		return nil, 0, err
+	} else if !ok || len(header) < 4 {
+		return nil, 0, errors.New("no file size in the chunk headers")
+	}
+	fileSize := int(binary.BigEndian.Uint32(header)) * 4
+
+	encrypted := make([]byte, end-alignedStart)
+	n, err := io.ReadFull(reader, encrypted)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, 0, err
+	}
+	encrypted = encrypted[:n]
+
+	data, err := a.decryptDetached(alignedStart, encrypted)
+	return data, fileSize, err
+}
+
+// decryptDetached decrypts data starting at the byte offset of the file, with its own decrypter so that it can be
+// called concurrently with the download
+func (a *AudioFile) decryptDetached(offset int, encrypted []byte) ([]byte, error) {
+	<-a.keyReady
+	if a.keyErr != nil {
+		return nil, fmt.Errorf("no audio key: %v", a.keyErr)
+	}
+	data := make([]byte, len(encrypted))
+	if a.plain {
+		copy(data, encrypted)
+		return data, nil
+	}
+	decrypter := NewAudioFileDecrypterWithBackend(a.player.DecryptionBackend())
+	decrypter.DecryptAudioAtOffset(offset, a.cipher, encrypted, data)
+	return data, nil
+}
+
+// Preview fetches about window of audio around position, estimated from the bitrate of the file, for scrub previews.
+// Like ReadAt, it doesn't change the read position nor the download order. The data doesn't start on a page boundary,
+// and must be decoded with the headers of the stream.
+func (a *AudioFile) Preview(position time.Duration, window time.Duration) ([]byte, error) {
+	bitrate := a.FormatInfo().Bitrate
+	if bitrate == 0 {
+		return nil, ErrUnknownBitrate
+	}
+	bytesPerSecond := float64(bitrate) * 1000 / 8
+
+	start := int64((position - window/2).Seconds() * bytesPerSecond)
+	if start < 0 {
+		start = 0
+	}
+	buf := make([]byte, int(window.Seconds()*bytesPerSecond))
+
+	n, err := a.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// alignDown returns the offset rounded down to ChunkAlignment, where the data can be decrypted from
+func alignDown(offset int) int {
+	return offset - offset%ChunkAlignment
+}
@@ -0,0 +1,466 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+// ErrEndOfQueue is returned when moving past the last track of a queue which doesn't repeat
+var ErrEndOfQueue = errors.New("end of queue")
+
+// DefaultMaxConsecutiveFailures is the number of tracks in a row which can fail to load before a queue stops the
+// playback by default
+const DefaultMaxConsecutiveFailures = 5
+
+// FailuresError is returned by Queue.Read when too many tracks in a row failed to load
+type FailuresError struct {
+	// Failures is the number of consecutive tracks which failed
+	Failures int
+	// Err is the error of the last one
+	Err error
+}
+
+func (e *FailuresError) Error() string {
+	return fmt.Sprintf("%d consecutive tracks failed to load: %v", e.Failures, e.Err)
+}
+
+// RepeatMode is the repeat mode of a queue
+type RepeatMode int
+
+const (
+	// RepeatOff stops the playback after the last track
+	RepeatOff RepeatMode = iota
+	// RepeatAll plays the queue again from its first track after the last one
+	RepeatAll
+	// RepeatOne plays the current track again after its end. Skipping with Next still moves to the next track.
+	RepeatOne
+)
+
+// QueueItem is a track of a queue
+type QueueItem struct {
+	TrackId []byte
+	FileId  []byte
+	Format  Spotify.AudioFile_Format
+	Origin  PlayOrigin
+}
+
+// Queue plays a list of tracks back to back. While a track plays, the audio key and the first chunk of the next one
+// are prefetched, so that the transition is gapless.
+//
+// The queue can be read as a single stream with Read, which moves to the next track at the end of each one, or as PCM
+// frames with a Crossfader, which mixes the tracks over the crossfade duration. Other outputs mixing the tracks can
+// read Current and Peek separately, and call Advance at the end of the current track.
+type Queue struct {
+	player *Player
+
+	lock sync.Mutex
+	rand *rand.Rand
+	// items are the tracks in the order they were added, order the indexes of the items in play order
+	items     []QueueItem
+	order     []int
+	position  int
+	shuffle   bool
+	repeat    RepeatMode
+	crossfade time.Duration
+	// failures is the number of tracks in a row which failed to load, up to maxFailures
+	failures    int
+	maxFailures int
+
+	current *AudioFile
+	// next is the prefetched file of the item nextItem
+	next     *AudioFile
+	nextItem int
+}
+
+// NewQueue creates an empty queue playing through this player
+func (p *Player) NewQueue() *Queue {
+	return &Queue{
+		player:      p,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		position:    -1,
+		nextItem:    -1,
+		shuffle:     p.ShuffleOnly(),
+		maxFailures: DefaultMaxConsecutiveFailures,
+	}
+}
+
+// Add appends tracks to the queue. With shuffle enabled, they are inserted at random positions after the current
+// track. The tracks go through the content filters of the player first: the vetoed ones are left out, as well as the
+// ones whose metadata couldn't be fetched.
+func (q *Queue) Add(items ...QueueItem) {
+	allowed := make([]QueueItem, 0, len(items))
+	for _, item := range items {
+		item, err := q.player.filterQueueItem(item)
+		if err != nil {
+			if err != ErrFiltered {
+				q.player.logger().Printf("[player] Unable to filter track %x: %s\n", item.TrackId, err)
+			}
+			continue
+		}
+		allowed = append(allowed, item)
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, item := range allowed {
+		q.items = append(q.items, item)
+		index := len(q.items) - 1
+
+		if !q.shuffle {
+			q.order = append(q.order, index)
+			continue
+		}
+		at := q.position + 1 + q.rand.Intn(len(q.order)-q.position)
+		q.order = append(q.order, 0)
+		copy(q.order[at+1:], q.order[at:])
+		q.order[at] = index
+	}
+	q.prefetch()
+}
+
+// Clear removes all the tracks of the queue, and cancels the download of the prefetched one
+func (q *Queue) Clear() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.items = nil
+	q.order = nil
+	q.position = -1
+	q.current = nil
+	q.failures = 0
+	q.dropNext()
+}
+
+// Items returns the tracks of the queue in play order
+func (q *Queue) Items() []QueueItem {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	items := make([]QueueItem, len(q.order))
+	for i, index := range q.order {
+		items[i] = q.items[index]
+	}
+	return items
+}
+
+// Position returns the position of the current track in Items, or -1 if the playback hasn't started
+func (q *Queue) Position() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.position
+}
+
+// SetShuffle enables or disables the shuffle mode. When enabled, the tracks are played in a random order starting
+// with the current one; when disabled, the tracks following the current one are played in the order they were added.
+// The shuffle mode stays enabled if the player is restricted to the shuffled playback, see Player.SetShuffleOnly.
+func (q *Queue) SetShuffle(shuffle bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.player.ShuffleOnly() {
+		shuffle = true
+	}
+	q.shuffle = shuffle
+	currentItem := -1
+	if q.position >= 0 {
+		currentItem = q.order[q.position]
+	}
+
+	q.order = make([]int, len(q.items))
+	for i := range q.order {
+		q.order[i] = i
+	}
+	if shuffle {
+		q.rand.Shuffle(len(q.order), func(i, j int) {
+			q.order[i], q.order[j] = q.order[j], q.order[i]
+		})
+		if currentItem >= 0 {
+			// Keep the current track first, so that all the others are played next
+			for i, index := range q.order {
+				if index == currentItem {
+					q.order[0], q.order[i] = q.order[i], q.order[0]
+				}
+			}
+			q.position = 0
+		}
+	} else {
+		q.position = currentItem
+	}
+	q.prefetch()
+}
+
+// SetRepeat changes the repeat mode of the queue
+func (q *Queue) SetRepeat(mode RepeatMode) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.repeat = mode
+	q.prefetch()
+}
+
+// SetCrossfade sets how long the end of a track and the start of the next one overlap when the queue is read with a
+// Crossfader, zero by default. Read always delivers the tracks back to back. It applies from the next transition.
+func (q *Queue) SetCrossfade(duration time.Duration) {
+	q.lock.Lock()
+	q.crossfade = duration
+	q.lock.Unlock()
+}
+
+// Crossfade returns the overlap between the tracks, see SetCrossfade
+func (q *Queue) Crossfade() time.Duration {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.crossfade
+}
+
+// SetMaxConsecutiveFailures changes the number of tracks in a row which can fail to load, e.g. because their audio
+// key is refused, before Read stops the playback with a FailuresError. Read skips the tracks failing before, emitting
+// TrackSkipped. Zero stops the playback at the first failure.
+func (q *Queue) SetMaxConsecutiveFailures(max int) {
+	q.lock.Lock()
+	q.maxFailures = max
+	q.lock.Unlock()
+}
+
+// Current returns the audio file of the current track, or nil if the playback hasn't started or is over
+func (q *Queue) Current() *AudioFile {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.current
+}
+
+// Peek returns the prefetched audio file of the track played after the current one, or nil if there is none or if it
+// is the current track again. Reading it doesn't change the queue: once the current track ends, Advance continues
+// with the file where the reads left it.
+func (q *Queue) Peek() *AudioFile {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.next
+}
+
+// Next skips to the next track, even in RepeatOne mode. ErrEndOfQueue is returned after the last track, unless the
+// queue repeats.
+func (q *Queue) Next() error {
+	return q.move(func() int { return q.following(true) }, false)
+}
+
+// Advance moves to the next track once the current one has been played until its end, applying the repeat mode. It
+// emits EndOfTrack, and is called by Read.
+func (q *Queue) Advance() error {
+	return q.move(func() int { return q.following(false) }, true)
+}
+
+// Previous goes back to the previous track. At the start of the queue, the current track is restarted, unless the
+// queue repeats. ErrShuffleOnly is returned if the player is restricted to the shuffled playback.
+func (q *Queue) Previous() error {
+	if q.player.ShuffleOnly() {
+		return ErrShuffleOnly
+	}
+	return q.move(func() int {
+		if q.position > 0 {
+			return q.position - 1
+		} else if q.repeat == RepeatAll {
+			return len(q.order) - 1
+		}
+		return q.position
+	}, false)
+}
+
+// Read is an implementation of the io.Reader interface, reading the tracks of the queue one after the other. It
+// returns io.EOF after the last track. The tracks failing to load are skipped, until too many fail in a row and a
+// FailuresError is returned, see SetMaxConsecutiveFailures.
+func (q *Queue) Read(buf []byte) (int, error) {
+	for {
+		current := q.Current()
+		if current == nil {
+			// Start the playback, or continue it with the tracks added after the end of the queue
+			if err := q.move(func() int { return q.following(false) }, false); err == ErrEndOfQueue {
+				return 0, io.EOF
+			} else if err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		n, err := current.Read(buf)
+		if err != nil && err != io.EOF {
+			if err := q.skip(current, err); err == ErrEndOfQueue {
+				return n, io.EOF
+			} else if err != nil {
+				return n, err
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		if n > 0 {
+			q.succeeded()
+		}
+		if err == nil {
+			return n, nil
+		}
+		if err := q.Advance(); err == ErrEndOfQueue {
+			return n, io.EOF
+		} else if err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// skip moves past the current track which failed to load, emitting TrackSkipped, unless too many tracks failed in a
+// row. The next track is played even in RepeatOne mode.
+func (q *Queue) skip(failed *AudioFile, err error) error {
+	q.lock.Lock()
+	if q.current != failed {
+		// The queue moved meanwhile, the failure doesn't concern the current track anymore
+		q.lock.Unlock()
+		return nil
+	}
+	q.failures++
+	if q.failures > q.maxFailures {
+		failures := q.failures
+		q.failures = 0
+		q.lock.Unlock()
+		return &FailuresError{Failures: failures, Err: err}
+	}
+	event := q.event(EventTrackSkipped, q.order[q.position])
+	event.Err = err
+	q.lock.Unlock()
+
+	q.player.logger().Printf("[player] Skipping track %x: %s\n", event.TrackId, err)
+	q.emit([]Event{event})
+	return q.move(func() int { return q.following(true) }, false)
+}
+
+// succeeded resets the count of consecutive failures once a track plays
+func (q *Queue) succeeded() {
+	q.lock.Lock()
+	q.failures = 0
+	q.lock.Unlock()
+}
+
+// following returns the position of the track after the current one, or -1 at the end of the queue. It must be
+// called with the lock held.
+func (q *Queue) following(skip bool) int {
+	if len(q.order) == 0 {
+		return -1
+	}
+	if q.repeat == RepeatOne && !skip && q.position >= 0 {
+		return q.position
+	}
+	if q.position+1 < len(q.order) {
+		return q.position + 1
+	}
+	if q.repeat != RepeatOff {
+		return 0
+	}
+	return -1
+}
+
+// move makes the track at the position returned by next current, emitting EndOfTrack first if the previous one
+// ended. next is called with the lock held.
+func (q *Queue) move(next func() int, ended bool) error {
+	q.lock.Lock()
+	position := next()
+
+	var events []Event
+	if ended && q.current != nil {
+		events = append(events, q.event(EventEndOfTrack, q.order[q.position]))
+	}
+
+	if position < 0 || position >= len(q.order) {
+		q.current = nil
+		q.dropNext()
+		q.lock.Unlock()
+		q.emit(events)
+		return ErrEndOfQueue
+	}
+
+	item := q.order[position]
+	switch {
+	case position == q.position && q.current != nil:
+		// Same track again, restart it
+		q.current.Seek(0, io.SeekStart)
+	case item == q.nextItem && q.next != nil:
+		q.current = q.next
+		q.next = nil
+		q.nextItem = -1
+		q.current.startDownload()
+	default:
+		i := q.items[item]
+		q.current = q.player.prefetchTrack(i.FileId, i.Format, i.TrackId)
+		q.current.SetOrigin(i.Origin)
+		q.current.startDownload()
+	}
+	q.position = position
+	events = append(events, q.event(EventTrackChanged, item))
+	q.prefetch()
+	q.lock.Unlock()
+
+	q.emit(events)
+	return nil
+}
+
+// prefetch starts loading the track following the current one, if it isn't already, it must be called with the lock
+// held
+func (q *Queue) prefetch() {
+	if q.current == nil {
+		// Nothing plays yet, the first track is loaded when the playback starts
+		return
+	}
+
+	position := q.following(false)
+	if position < 0 || position == q.position {
+		q.dropNext()
+		return
+	}
+	item := q.order[position]
+	if item == q.nextItem && q.next != nil {
+		return
+	}
+
+	q.dropNext()
+	i := q.items[item]
+	q.next = q.player.prefetchTrack(i.FileId, i.Format, i.TrackId)
+	q.next.SetOrigin(i.Origin)
+	q.nextItem = item
+}
+
+// dropNext cancels the download of the prefetched file, it must be called with the lock held
+func (q *Queue) dropNext() {
+	if q.next != nil {
+		q.next.finish()
+	}
+	q.next = nil
+	q.nextItem = -1
+}
+
+// event builds an event about an item, it must be called with the lock held
+func (q *Queue) event(eventType EventType, item int) Event {
+	i := q.items[item]
+	return Event{
+		Type:    eventType,
+		Time:    time.Now(),
+		TrackId: i.TrackId,
+		FileId:  i.FileId,
+		Format:  i.Format,
+		Origin:  i.Origin,
+	}
+}
+
+func (q *Queue) emit(events []Event) {
+	for _, event := range events {
+		q.player.emit(event)
+	}
+}
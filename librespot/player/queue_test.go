@@ -0,0 +1,198 @@
+package player_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+func queueItems(n int) []player.QueueItem {
+	items := make([]player.QueueItem, n)
+	for i := range items {
+		items[i] = player.QueueItem{
+			TrackId: bytes.Repeat([]byte{byte(i + 1)}, 16),
+			FileId:  testFileId,
+			Format:  Spotify.AudioFile_MP3_320,
+		}
+	}
+	return items
+}
+
+func TestQueueGapless(t *testing.T) {
+	plain := make([]byte, 2*player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.player.SetChunkSize(player.ChunkAlignment)
+
+	var lock sync.Mutex
+	var events []player.Event
+	server.player.OnEvent(func(event player.Event) {
+		if event.Type == player.EventTrackChanged || event.Type == player.EventEndOfTrack {
+			lock.Lock()
+			events = append(events, event)
+			lock.Unlock()
+		}
+	})
+
+	queue := server.player.NewQueue()
+	queue.Add(queueItems(3)...)
+
+	data, err := ioutil.ReadAll(queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, bytes.Repeat(plain, 3)) {
+		t.Errorf("queue data mismatch, got %d bytes, want %d", len(data), 3*len(plain))
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(events) != 6 {
+		t.Fatalf("got %d events, want 6", len(events))
+	}
+	for i, event := range events {
+		wantType := player.EventTrackChanged
+		if i%2 == 1 {
+			wantType = player.EventEndOfTrack
+		}
+		if event.Type != wantType || event.TrackId[0] != byte(i/2+1) {
+			t.Errorf("event %d is %s of track %d, want %s of track %d", i, event.Type, event.TrackId[0], wantType, i/2+1)
+		}
+	}
+}
+
+func TestQueueRepeat(t *testing.T) {
+	plain := make([]byte, player.ChunkAlignment)
+	server := newFakeAudioServer(time.Millisecond, plain)
+	queue := server.player.NewQueue()
+	queue.Add(queueItems(2)...)
+
+	if err := queue.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if queue.Peek() == nil {
+		t.Error("next track not prefetched")
+	}
+	if err := queue.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Next(); err != player.ErrEndOfQueue {
+		t.Errorf("got %v after the last track, want ErrEndOfQueue", err)
+	}
+
+	queue.SetRepeat(player.RepeatAll)
+	if err := queue.Next(); err != nil || queue.Position() != 0 {
+		t.Errorf("got position %d (%v), want to wrap around to 0", queue.Position(), err)
+	}
+
+	queue.SetRepeat(player.RepeatOne)
+	if queue.Peek() != nil {
+		t.Error("track prefetched with RepeatOne")
+	}
+	current := queue.Current()
+	if err := queue.Advance(); err != nil || queue.Current() != current {
+		t.Errorf("the track didn't repeat (%v)", err)
+	}
+	if err := queue.Next(); err != nil || queue.Position() != 1 {
+		t.Errorf("got position %d (%v), want to skip to 1", queue.Position(), err)
+	}
+}
+
+func TestQueueShuffle(t *testing.T) {
+	server := newFakeAudioServer(time.Millisecond, make([]byte, player.ChunkAlignment))
+	queue := server.player.NewQueue()
+	queue.Add(queueItems(10)...)
+	queue.Next()
+	queue.Next()
+	current := queue.Items()[queue.Position()]
+
+	queue.SetShuffle(true)
+	items := queue.Items()
+	if queue.Position() != 0 || !bytes.Equal(items[0].TrackId, current.TrackId) {
+		t.Error("the current track isn't first after shuffling")
+	}
+	seen := map[byte]bool{}
+	for _, item := range items {
+		seen[item.TrackId[0]] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("got %d distinct tracks after shuffling, want 10", len(seen))
+	}
+
+	queue.SetShuffle(false)
+	if !bytes.Equal(queue.Items()[queue.Position()].TrackId, current.TrackId) || queue.Position() != 1 {
+		t.Errorf("got position %d after unshuffling, want 1", queue.Position())
+	}
+}
+
+func TestQueueShuffleOnly(t *testing.T) {
+	server := newFakeAudioServer(time.Millisecond, make([]byte, player.ChunkAlignment))
+	server.player.SetShuffleOnly(true)
+	queue := server.player.NewQueue()
+	queue.Add(queueItems(10)...)
+
+	queue.SetShuffle(false)
+	if err := queue.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Previous(); err != player.ErrShuffleOnly {
+		t.Errorf("got %v going back, want ErrShuffleOnly", err)
+	}
+
+	// The tracks are shuffled, whatever SetShuffle asked
+	inOrder := true
+	for i, item := range queue.Items() {
+		inOrder = inOrder && item.TrackId[0] == byte(i+1)
+	}
+	if inOrder {
+		t.Error("the tracks are not shuffled")
+	}
+}
+
+func TestQueueSkipFailures(t *testing.T) {
+	plain := make([]byte, player.ChunkAlignment+100)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+	server := newFakeAudioServer(time.Millisecond, plain)
+	server.refuse = map[byte]bool{2: true, 4: true, 5: true}
+
+	var lock sync.Mutex
+	var skipped []byte
+	server.player.OnEvent(func(event player.Event) {
+		if event.Type == player.EventTrackSkipped {
+			lock.Lock()
+			skipped = append(skipped, event.TrackId[0])
+			lock.Unlock()
+			if event.Err == nil {
+				t.Error("track skipped without an error")
+			}
+		}
+	})
+
+	queue := server.player.NewQueue()
+	queue.SetMaxConsecutiveFailures(1)
+	queue.Add(queueItems(6)...)
+
+	// Track 2 is skipped, while tracks 4 and 5 fail in a row
+	data, err := ioutil.ReadAll(queue)
+	if failures, ok := err.(*player.FailuresError); !ok || failures.Failures != 2 {
+		t.Fatalf("got %v, want a FailuresError after 2 failures", err)
+	}
+	if !bytes.Equal(data, bytes.Repeat(plain, 2)) {
+		t.Errorf("got %d bytes, want the %d bytes of tracks 1 and 3", len(data), 2*len(plain))
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if !bytes.Equal(skipped, []byte{2, 4}) {
+		t.Errorf("got tracks %v skipped, want [2 4]", skipped)
+	}
+}
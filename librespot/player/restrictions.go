@@ -0,0 +1,37 @@
+package player
+
+import "errors"
+
+// ErrShuffleOnly is returned when picking the tracks to play on an account which can only play them shuffled, e.g. a
+// free account on mobile devices
+var ErrShuffleOnly = errors.New("the account can only play shuffled")
+
+// SetMaxBitrate limits the bitrate of the files selected by SelectAudioFile, in kbps, e.g. to the 160 kbps of the free
+// accounts which can't get the key of the 320 kbps files. Zero removes the limit, which is the default.
+func (p *Player) SetMaxBitrate(kbps int) {
+	p.streamLock.Lock()
+	p.maxBitrate = kbps
+	p.streamLock.Unlock()
+}
+
+// MaxBitrate returns the largest bitrate of the selected files, zero if unlimited
+func (p *Player) MaxBitrate() int {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.maxBitrate
+}
+
+// SetShuffleOnly restricts the queues to the shuffled playback: they are always shuffled, and don't go back to the
+// previous track
+func (p *Player) SetShuffleOnly(shuffleOnly bool) {
+	p.streamLock.Lock()
+	p.shuffleOnly = shuffleOnly
+	p.streamLock.Unlock()
+}
+
+// ShuffleOnly returns whether the queues are restricted to the shuffled playback
+func (p *Player) ShuffleOnly() bool {
+	p.streamLock.RLock()
+	defer p.streamLock.RUnlock()
+	return p.shuffleOnly
+}
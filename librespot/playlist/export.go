@@ -0,0 +1,372 @@
+package playlist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+)
+
+// ErrTrackNotFound is returned by a Resolver when no track matches
+var ErrTrackNotFound = errors.New("no matching track found")
+
+// Format is a file format of the exported playlists
+type Format string
+
+const (
+	// FormatJSON is the Export structure encoded as JSON, keeping all the metadata
+	FormatJSON Format = "json"
+	// FormatM3U is an extended M3U playlist of spotify URIs, with the duration, artists and names of the tracks
+	FormatM3U Format = "m3u"
+	// FormatCSV has a header line, then a line per track with its URI, name, artists (separated by ";"), album,
+	// duration in milliseconds, ISRC, the user who added it and the addition time (RFC 3339)
+	FormatCSV Format = "csv"
+)
+
+// csvHeader are the columns of FormatCSV
+var csvHeader = []string{"uri", "name", "artists", "album", "duration_ms", "isrc", "added_by", "added_at"}
+
+// ExportedTrack is an item of an exported playlist. Only Uri is set for the items which are not tracks, e.g. episodes.
+type ExportedTrack struct {
+	Uri      string        `json:"uri,omitempty"`
+	Name     string        `json:"name,omitempty"`
+	Artists  []string      `json:"artists,omitempty"`
+	Album    string        `json:"album,omitempty"`
+	Duration time.Duration `json:"duration_ms,omitempty"`
+	Isrc     string        `json:"isrc,omitempty"`
+	AddedBy  string        `json:"added_by,omitempty"`
+	AddedAt  time.Time     `json:"added_at,omitempty"`
+}
+
+// MarshalJSON encodes the duration in milliseconds
+func (t ExportedTrack) MarshalJSON() ([]byte, error) {
+	type plain ExportedTrack
+	p := plain(t)
+	p.Duration /= time.Millisecond
+	return json.Marshal(p)
+}
+
+// UnmarshalJSON decodes the duration from milliseconds
+func (t *ExportedTrack) UnmarshalJSON(data []byte) error {
+	type plain ExportedTrack
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*t = ExportedTrack(p)
+	t.Duration *= time.Millisecond
+	return nil
+}
+
+// Export is a playlist with the metadata of its tracks, for backups and migrations
+type Export struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Uri         string          `json:"uri,omitempty"`
+	Tracks      []ExportedTrack `json:"tracks"`
+}
+
+// TrackSource provides the metadata of the tracks, *metadata.Catalog implements it
+type TrackSource interface {
+	Track(id string) (*metadata.TrackInfo, error)
+}
+
+// NewExport builds the export of a playlist, fetching the metadata of its tracks from tracks
+func NewExport(p *Playlist, tracks TrackSource) (*Export, error) {
+	e := &Export{
+		Name:        p.Name,
+		Description: p.Description,
+		Uri:         p.Uri(),
+		Tracks:      make([]ExportedTrack, 0, len(p.Items)),
+	}
+
+	for _, item := range p.Items {
+		track := ExportedTrack{
+			Uri:     item.Uri,
+			AddedBy: item.AddedBy,
+			AddedAt: item.AddedAt,
+		}
+		if strings.HasPrefix(item.Uri, "spotify:track:") {
+			info, err := tracks.Track(ParseId(item.Uri))
+			if err != nil {
+				return nil, err
+			}
+			track.Name = info.Name
+			track.Album = info.Album.Name
+			track.Duration = info.Duration
+			track.Isrc = info.ExternalIds["isrc"]
+			for _, artist := range info.Artists {
+				track.Artists = append(track.Artists, artist.Name)
+			}
+		}
+		e.Tracks = append(e.Tracks, track)
+	}
+	return e, nil
+}
+
+// Write writes the export in the specified format
+func (e *Export) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(e)
+	case FormatM3U:
+		return e.writeM3U(w)
+	case FormatCSV:
+		return e.writeCSV(w)
+	default:
+		return fmt.Errorf("unknown playlist format %q", format)
+	}
+}
+
+func (e *Export) writeM3U(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintln(buf, "#EXTM3U")
+	if e.Name != "" {
+		fmt.Fprintf(buf, "#PLAYLIST:%s\n", e.Name)
+	}
+	for _, track := range e.Tracks {
+		if track.Name != "" {
+			seconds := -1
+			if track.Duration > 0 {
+				seconds = int(track.Duration / time.Second)
+			}
+			title := track.Name
+			if len(track.Artists) > 0 {
+				title = strings.Join(track.Artists, ", ") + " - " + title
+			}
+			fmt.Fprintf(buf, "#EXTINF:%d,%s\n", seconds, title)
+		}
+		fmt.Fprintln(buf, track.Uri)
+	}
+	return buf.Flush()
+}
+
+func (e *Export) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, track := range e.Tracks {
+		addedAt := ""
+		if !track.AddedAt.IsZero() {
+			addedAt = track.AddedAt.Format(time.RFC3339)
+		}
+		writer.Write([]string{
+			track.Uri,
+			track.Name,
+			strings.Join(track.Artists, ";"),
+			track.Album,
+			strconv.FormatInt(int64(track.Duration/time.Millisecond), 10),
+			track.Isrc,
+			track.AddedBy,
+			addedAt,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadExport reads a playlist written in the specified format. Only the URIs are required, the other fields are
+// used to resolve the tracks which don't have one, e.g. the entries of an M3U playlist of local files.
+func ReadExport(r io.Reader, format Format) (*Export, error) {
+	switch format {
+	case FormatJSON:
+		e := &Export{}
+		if err := json.NewDecoder(r).Decode(e); err != nil {
+			return nil, fmt.Errorf("bad JSON playlist: %v", err)
+		}
+		return e, nil
+	case FormatM3U:
+		return readM3U(r)
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown playlist format %q", format)
+	}
+}
+
+func readM3U(r io.Reader) (*Export, error) {
+	e := &Export{}
+	var info ExportedTrack
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line == "#EXTM3U":
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			e.Name = strings.TrimPrefix(line, "#PLAYLIST:")
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info = parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+		case strings.HasPrefix(line, "#"):
+			// Other directives are not supported
+		default:
+			if strings.HasPrefix(line, "spotify:") {
+				info.Uri = line
+			}
+			e.Tracks = append(e.Tracks, info)
+			info = ExportedTrack{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("bad M3U playlist: %v", err)
+	}
+	return e, nil
+}
+
+// parseExtInf parses the "<seconds>,<artists> - <name>" value of an EXTINF directive
+func parseExtInf(value string) ExportedTrack {
+	var track ExportedTrack
+	comma := strings.Index(value, ",")
+	if comma < 0 {
+		return track
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value[:comma])); err == nil && seconds > 0 {
+		track.Duration = time.Duration(seconds) * time.Second
+	}
+
+	title := strings.TrimSpace(value[comma+1:])
+	if dash := strings.Index(title, " - "); dash >= 0 {
+		for _, artist := range strings.Split(title[:dash], ",") {
+			track.Artists = append(track.Artists, strings.TrimSpace(artist))
+		}
+		title = title[dash+3:]
+	}
+	track.Name = title
+	return track
+}
+
+func readCSV(r io.Reader) (*Export, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("bad CSV playlist: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("bad CSV playlist: no header")
+	}
+
+	// The columns are found by name, so that files edited by hand may omit or reorder them
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	e := &Export{}
+	for _, record := range records[1:] {
+		track := ExportedTrack{
+			Uri:     field(record, "uri"),
+			Name:    field(record, "name"),
+			Album:   field(record, "album"),
+			Isrc:    field(record, "isrc"),
+			AddedBy: field(record, "added_by"),
+		}
+		if artists := field(record, "artists"); artists != "" {
+			track.Artists = strings.Split(artists, ";")
+		}
+		if ms, err := strconv.ParseInt(field(record, "duration_ms"), 10, 64); err == nil {
+			track.Duration = time.Duration(ms) * time.Millisecond
+		}
+		if addedAt, err := time.Parse(time.RFC3339, field(record, "added_at")); err == nil {
+			track.AddedAt = addedAt
+		}
+		e.Tracks = append(e.Tracks, track)
+	}
+	return e, nil
+}
+
+// Resolver finds the URI of an exported track
+type Resolver interface {
+	Resolve(track ExportedTrack) (string, error)
+}
+
+// SearchFunc searches the catalog, e.g. core.Session.Search
+type SearchFunc = metadata.SearchFunc
+
+// searchResolver resolves the tracks through a metadata.Matcher
+type searchResolver struct {
+	matcher *metadata.Matcher
+}
+
+// NewSearchResolver creates a resolver keeping the spotify:track: URIs of the tracks, and searching the other tracks
+// by ISRC, or by artist and name, with a metadata.Matcher
+func NewSearchResolver(search SearchFunc) Resolver {
+	return &searchResolver{matcher: metadata.NewMatcher(search, nil)}
+}
+
+func (r *searchResolver) Resolve(track ExportedTrack) (string, error) {
+	if strings.HasPrefix(track.Uri, "spotify:track:") {
+		return track.Uri, nil
+	}
+
+	match, err := r.matcher.Match(metadata.TrackQuery{
+		Isrc:     track.Isrc,
+		Name:     track.Name,
+		Artists:  track.Artists,
+		Duration: track.Duration,
+	})
+	if err == metadata.ErrNoMatch {
+		return "", ErrTrackNotFound
+	} else if err != nil {
+		return "", err
+	}
+	return match.Uri, nil
+}
+
+// ImportResult reports the outcome of Client.Import
+type ImportResult struct {
+	// Added are the URIs added to the playlist
+	Added []string
+	// Missing are the tracks which could not be resolved
+	Missing []ExportedTrack
+}
+
+// importBatchSize is the number of tracks added to the playlist at once
+const importBatchSize = 100
+
+// Import resolves the tracks of the export, and appends them to the playlist. The tracks which can't be resolved are
+// reported in the result, and skipped. The playlist is renamed after the export if it has no name.
+func (c *Client) Import(p *Playlist, e *Export, resolver Resolver) (*ImportResult, error) {
+	result := &ImportResult{}
+	for _, track := range e.Tracks {
+		uri, err := resolver.Resolve(track)
+		if err == ErrTrackNotFound {
+			result.Missing = append(result.Missing, track)
+			continue
+		} else if err != nil {
+			return result, err
+		}
+		result.Added = append(result.Added, uri)
+	}
+
+	for start := 0; start < len(result.Added); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(result.Added) {
+			end = len(result.Added)
+		}
+		if err := c.Add(p, -1, result.Added[start:end]...); err != nil {
+			result.Added = result.Added[:start]
+			return result, err
+		}
+	}
+
+	if p.Name == "" && e.Name != "" {
+		if err := c.Rename(p, e.Name); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
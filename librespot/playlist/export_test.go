@@ -0,0 +1,113 @@
+package playlist
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+)
+
+type fakeTracks map[string]*metadata.TrackInfo
+
+func (f fakeTracks) Track(id string) (*metadata.TrackInfo, error) {
+	if info, ok := f[id]; ok {
+		return info, nil
+	}
+	return nil, errors.New("unknown track")
+}
+
+func testExport(t *testing.T) *Export {
+	p := &Playlist{
+		Id:   "list",
+		Name: "Road trip",
+		Items: []Item{
+			{Uri: "spotify:track:one", AddedBy: "user", AddedAt: time.Unix(1600000000, 0).UTC()},
+			{Uri: "spotify:episode:two"},
+		},
+	}
+	tracks := fakeTracks{"one": {
+		Name:        "Song, Part 1",
+		Album:       metadata.Ref{Name: "Album"},
+		Artists:     []metadata.Ref{{Name: "Artist A"}, {Name: "Artist B"}},
+		Duration:    185 * time.Second,
+		ExternalIds: map[string]string{"isrc": "USABC1234567"},
+	}}
+
+	e, err := NewExport(p, tracks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	e := testExport(t)
+	if e.Uri != "spotify:playlist:list" || len(e.Tracks) != 2 || e.Tracks[0].Isrc != "USABC1234567" {
+		t.Fatalf("bad export %+v", e)
+	}
+
+	for _, format := range []Format{FormatJSON, FormatCSV} {
+		buf := new(bytes.Buffer)
+		if err := e.Write(buf, format); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadExport(buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read.Tracks[0], e.Tracks[0]) {
+			t.Errorf("%s: got %+v, want %+v", format, read.Tracks[0], e.Tracks[0])
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	e.Write(buf, FormatM3U)
+	read, err := ReadExport(buf, FormatM3U)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ExportedTrack{Uri: "spotify:track:one", Name: "Song, Part 1", Artists: []string{"Artist A", "Artist B"},
+		Duration: 185 * time.Second}
+	if read.Name != "Road trip" || len(read.Tracks) != 2 || !reflect.DeepEqual(read.Tracks[0], want) {
+		t.Errorf("m3u: got %+v", read)
+	}
+}
+
+func TestSearchResolver(t *testing.T) {
+	var queries []string
+	resolver := NewSearchResolver(func(query string, limit int) (*metadata.SearchResponse, error) {
+		queries = append(queries, query)
+		res := &metadata.SearchResponse{}
+		res.Results.Tracks.Hits = []metadata.Track{
+			{Name: "Other", Uri: "spotify:track:other", Duration: 185000},
+			{Name: "song", Uri: "spotify:track:long", Duration: 300000},
+			{Name: "Song", Uri: "spotify:track:found", Duration: 187000},
+		}
+		return res, nil
+	})
+
+	uri, err := resolver.Resolve(ExportedTrack{Uri: "spotify:track:kept"})
+	if err != nil || uri != "spotify:track:kept" || len(queries) != 0 {
+		t.Errorf("got %s (%v), want the track uri without searching", uri, err)
+	}
+
+	uri, err = resolver.Resolve(ExportedTrack{Name: "Song", Artists: []string{"Artist"}, Duration: 185 * time.Second,
+		Isrc: "ISRC"})
+	if err != nil || uri != "spotify:track:found" {
+		t.Errorf("got %s (%v), want spotify:track:found", uri, err)
+	}
+	if len(queries) != 1 || queries[0] != "isrc:ISRC" {
+		t.Errorf("unexpected queries %q", queries)
+	}
+
+	_, err = resolver.Resolve(ExportedTrack{Name: "Missing", Artists: []string{"Artist"}})
+	if err != ErrTrackNotFound {
+		t.Errorf("got %v, want ErrTrackNotFound", err)
+	}
+	if queries[len(queries)-1] != "Artist Missing" {
+		t.Errorf("unexpected query %q", queries[len(queries)-1])
+	}
+}
@@ -0,0 +1,335 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+)
+
+// ErrConflict is returned when a playlist was modified since it was fetched. The playlist must be fetched again
+// before retrying the modification.
+var ErrConflict = errors.New("playlist was modified concurrently")
+
+// Item is an entry of a playlist. In the rootlist, items are playlists and folder markers
+// (spotify:start-group:... and spotify:end-group:...).
+type Item struct {
+	Uri     string
+	AddedBy string
+	AddedAt time.Time
+}
+
+// Playlist is a snapshot of a playlist, at a given revision. Modifications made through Client update it in place.
+type Playlist struct {
+	Id            string
+	Revision      []byte
+	Name          string
+	Description   string
+	Collaborative bool
+	Items         []Item
+
+	// uri is the mercury uri the playlist was fetched from
+	uri string
+}
+
+// Uri returns the spotify:playlist:... uri of the playlist
+func (p *Playlist) Uri() string {
+	return "spotify:playlist:" + p.Id
+}
+
+// Client reads and modifies the playlists of a user through mercury
+type Client struct {
+	mercury  *mercury.Client
+	username string
+}
+
+// NewClient creates a client acting as the specified user
+func NewClient(m *mercury.Client, username string) *Client {
+	return &Client{
+		mercury:  m,
+		username: username,
+	}
+}
+
+// Rootlist fetches the list of playlists of the user
+func (c *Client) Rootlist() (*Playlist, error) {
+	content, err := c.mercury.GetRootPlaylist(c.username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rootlist: %v", err)
+	}
+	return fromContent("", fmt.Sprintf("hm://playlist/user/%s/rootlist", c.username), content), nil
+}
+
+// Get fetches the playlist with the specified id or uri
+func (c *Client) Get(id string) (*Playlist, error) {
+	id = ParseId(id)
+	content, err := c.mercury.GetPlaylist(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist %s: %v", id, err)
+	}
+	return fromContent(id, fmt.Sprintf("hm://playlist/v2/playlist/%s", id), content), nil
+}
+
+// Add inserts the uris at the specified index. A negative index appends them at the end of the playlist.
+func (c *Client) Add(p *Playlist, index int, uris ...string) error {
+	return c.Apply(p, AddOp(index, c.username, uris...))
+}
+
+// Remove removes length items, starting at index
+func (c *Client) Remove(p *Playlist, index int, length int) error {
+	return c.Apply(p, RemoveOp(index, length))
+}
+
+// Move moves length items starting at from, so that they are inserted before the item currently at index to
+func (c *Client) Move(p *Playlist, from int, length int, to int) error {
+	return c.Apply(p, MoveOp(from, length, to))
+}
+
+// Rename changes the name of the playlist
+func (c *Client) Rename(p *Playlist, name string) error {
+	return c.Apply(p, RenameOp(name))
+}
+
+// Apply sends the operations as a single change, based on the revision of the playlist. On success, the
+// operations are applied to the playlist, and its revision is updated. ErrConflict is returned if the playlist was
+// modified since it was fetched.
+func (c *Client) Apply(p *Playlist, ops ...*Spotify.Op) error {
+	items := p.Items
+	for _, op := range ops {
+		var err error
+		items, err = applyOp(items, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := proto.Marshal(buildChanges(p.Revision, c.username, time.Now(), ops))
+	if err != nil {
+		return err
+	}
+
+	res, err := c.mercury.Do(mercury.Request{
+		Method:      "POST",
+		Uri:         p.uri + "/changes",
+		ContentType: "vnd.spotify/playlist4-listchanges",
+		Payload:     [][]byte{data},
+	})
+
+	// The cached content is outdated, whatever the outcome
+	c.mercury.Cache().Remove(p.uri)
+
+	if err != nil {
+		if reqErr, ok := err.(*mercury.RequestError); ok && (reqErr.StatusCode == 409 || reqErr.StatusCode == 412) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to modify playlist %s: %v", p.uri, err)
+	}
+
+	result := &Spotify.SelectedListContent{}
+	err = proto.Unmarshal(res.CombinePayload(), result)
+	if err != nil {
+		return fmt.Errorf("bad playlist changes response: %v", err)
+	}
+
+	for _, op := range ops {
+		if op.GetKind() == Spotify.Op_UPDATE_LIST_ATTRIBUTES {
+			applyAttributes(p, op.GetUpdateListAttributes().GetNewAttributes().GetValues())
+		}
+	}
+	p.Items = items
+	if revisions := result.GetResultingRevisions(); len(revisions) > 0 {
+		p.Revision = revisions[len(revisions)-1]
+	} else if result.GetRevision() != nil {
+		p.Revision = result.GetRevision()
+	}
+	return nil
+}
+
+// ParseId returns the id of a playlist, given either its id or its uri
+func ParseId(uri string) string {
+	if idx := strings.LastIndex(uri, ":"); idx >= 0 {
+		return uri[idx+1:]
+	}
+	return uri
+}
+
+// AddOp builds an operation inserting the uris at index, or at the end of the playlist if index is negative
+func AddOp(index int, username string, uris ...string) *Spotify.Op {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	items := make([]*Spotify.Item, len(uris))
+	for i, uri := range uris {
+		items[i] = &Spotify.Item{
+			Uri: proto.String(uri),
+			Attributes: &Spotify.ItemAttributes{
+				AddedBy:   proto.String(username),
+				Timestamp: proto.Int64(now),
+			},
+		}
+	}
+
+	add := &Spotify.Add{Items: items}
+	if index < 0 {
+		add.AddLast = proto.Bool(true)
+	} else {
+		add.FromIndex = proto.Int32(int32(index))
+	}
+	return &Spotify.Op{
+		Kind: Spotify.Op_ADD.Enum(),
+		Add:  add,
+	}
+}
+
+// RemoveOp builds an operation removing length items, starting at index
+func RemoveOp(index int, length int) *Spotify.Op {
+	return &Spotify.Op{
+		Kind: Spotify.Op_REM.Enum(),
+		Rem: &Spotify.Rem{
+			FromIndex: proto.Int32(int32(index)),
+			Length:    proto.Int32(int32(length)),
+		},
+	}
+}
+
+// MoveOp builds an operation moving length items starting at from before the item at index to
+func MoveOp(from int, length int, to int) *Spotify.Op {
+	return &Spotify.Op{
+		Kind: Spotify.Op_MOV.Enum(),
+		Mov: &Spotify.Mov{
+			FromIndex: proto.Int32(int32(from)),
+			Length:    proto.Int32(int32(length)),
+			ToIndex:   proto.Int32(int32(to)),
+		},
+	}
+}
+
+// RenameOp builds an operation changing the name of the playlist
+func RenameOp(name string) *Spotify.Op {
+	return &Spotify.Op{
+		Kind: Spotify.Op_UPDATE_LIST_ATTRIBUTES.Enum(),
+		UpdateListAttributes: &Spotify.UpdateListAttributes{
+			NewAttributes: &Spotify.ListAttributesPartialState{
+				Values: &Spotify.ListAttributes{Name: proto.String(name)},
+			},
+		},
+	}
+}
+
+func buildChanges(revision []byte, username string, now time.Time, ops []*Spotify.Op) *Spotify.ListChanges {
+	return &Spotify.ListChanges{
+		BaseRevision: revision,
+		Deltas: []*Spotify.Delta{{
+			Ops: ops,
+			Info: &Spotify.ChangeInfo{
+				User:      proto.String(username),
+				Timestamp: proto.Int32(int32(now.Unix())),
+			},
+		}},
+		WantResultingRevisions: proto.Bool(true),
+	}
+}
+
+// applyOp returns the items resulting from the operation, without modifying the original slice
+func applyOp(items []Item, op *Spotify.Op) ([]Item, error) {
+	switch op.GetKind() {
+	case Spotify.Op_ADD:
+		add := op.GetAdd()
+		index := int(add.GetFromIndex())
+		if add.GetAddLast() {
+			index = len(items)
+		} else if add.GetAddFirst() {
+			index = 0
+		}
+		if index < 0 || index > len(items) {
+			return nil, fmt.Errorf("add index %d out of range", index)
+		}
+
+		res := make([]Item, 0, len(items)+len(add.Items))
+		res = append(res, items[:index]...)
+		for _, item := range add.Items {
+			res = append(res, fromItem(item))
+		}
+		return append(res, items[index:]...), nil
+
+	case Spotify.Op_REM:
+		from, length := int(op.GetRem().GetFromIndex()), int(op.GetRem().GetLength())
+		if from < 0 || length < 0 || from+length > len(items) {
+			return nil, fmt.Errorf("remove range [%d, %d) out of range", from, from+length)
+		}
+
+		res := make([]Item, 0, len(items)-length)
+		res = append(res, items[:from]...)
+		return append(res, items[from+length:]...), nil
+
+	case Spotify.Op_MOV:
+		mov := op.GetMov()
+		from, length, to := int(mov.GetFromIndex()), int(mov.GetLength()), int(mov.GetToIndex())
+		if from < 0 || length < 0 || from+length > len(items) || to < 0 || to > len(items) {
+			return nil, fmt.Errorf("move of [%d, %d) to %d out of range", from, from+length, to)
+		}
+		if to > from && to < from+length {
+			return nil, fmt.Errorf("move destination %d inside the moved range", to)
+		}
+
+		moved := items[from : from+length]
+		rest := make([]Item, 0, len(items)-length)
+		rest = append(rest, items[:from]...)
+		rest = append(rest, items[from+length:]...)
+		if to > from {
+			to -= length
+		}
+
+		res := make([]Item, 0, len(items))
+		res = append(res, rest[:to]...)
+		res = append(res, moved...)
+		return append(res, rest[to:]...), nil
+
+	case Spotify.Op_UPDATE_LIST_ATTRIBUTES:
+		return items, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported playlist operation %v", op.GetKind())
+	}
+}
+
+func fromItem(item *Spotify.Item) Item {
+	res := Item{
+		Uri:     item.GetUri(),
+		AddedBy: item.GetAttributes().GetAddedBy(),
+	}
+	if ts := item.GetAttributes().GetTimestamp(); ts > 0 {
+		res.AddedAt = time.Unix(0, ts*int64(time.Millisecond))
+	}
+	return res
+}
+
+func fromContent(id string, uri string, content *Spotify.SelectedListContent) *Playlist {
+	p := &Playlist{
+		Id:       id,
+		Revision: content.GetRevision(),
+		uri:      uri,
+	}
+	applyAttributes(p, content.GetAttributes())
+	for _, item := range content.GetContents().GetItems() {
+		p.Items = append(p.Items, fromItem(item))
+	}
+	return p
+}
+
+func applyAttributes(p *Playlist, attributes *Spotify.ListAttributes) {
+	if attributes == nil {
+		return
+	}
+	if attributes.Name != nil {
+		p.Name = attributes.GetName()
+	}
+	if attributes.Description != nil {
+		p.Description = attributes.GetDescription()
+	}
+	if attributes.Collaborative != nil {
+		p.Collaborative = attributes.GetCollaborative()
+	}
+}
@@ -0,0 +1,80 @@
+package playlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+)
+
+func makeItems(uris ...string) []Item {
+	items := make([]Item, len(uris))
+	for i, uri := range uris {
+		items[i] = Item{Uri: uri}
+	}
+	return items
+}
+
+func itemUris(items []Item) string {
+	res := ""
+	for _, item := range items {
+		res += item.Uri
+	}
+	return res
+}
+
+func TestApplyOp(t *testing.T) {
+	tests := []struct {
+		op   *Spotify.Op
+		want string
+	}{
+		{AddOp(1, "user", "x", "y"), "axybcde"},
+		{AddOp(-1, "user", "x"), "abcdex"},
+		{RemoveOp(1, 2), "ade"},
+		{MoveOp(0, 2, 4), "cdabe"},
+		{MoveOp(3, 2, 0), "deabc"},
+		{MoveOp(1, 1, 2), "abcde"},
+		{RenameOp("new name"), "abcde"},
+	}
+
+	for _, test := range tests {
+		items := makeItems("a", "b", "c", "d", "e")
+		res, err := applyOp(items, test.op)
+		if err != nil {
+			t.Errorf("%v failed: %v", test.op, err)
+			continue
+		}
+		if got := itemUris(res); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.op, got, test.want)
+		}
+		if itemUris(items) != "abcde" {
+			t.Errorf("%v modified the original items", test.op)
+		}
+	}
+
+	for _, op := range []*Spotify.Op{AddOp(6, "user", "x"), RemoveOp(4, 2), MoveOp(0, 3, 1)} {
+		if _, err := applyOp(makeItems("a", "b", "c", "d", "e"), op); err == nil {
+			t.Errorf("%v should fail", op)
+		}
+	}
+}
+
+func TestBuildChanges(t *testing.T) {
+	changes := buildChanges([]byte{1, 2}, "user", time.Unix(1000, 0), []*Spotify.Op{RemoveOp(0, 1)})
+	if string(changes.GetBaseRevision()) != "\x01\x02" || !changes.GetWantResultingRevisions() {
+		t.Errorf("Bad changes %v", changes)
+	}
+	if len(changes.Deltas) != 1 || len(changes.Deltas[0].Ops) != 1 ||
+		changes.Deltas[0].GetInfo().GetUser() != "user" || changes.Deltas[0].GetInfo().GetTimestamp() != 1000 {
+		t.Errorf("Bad delta %v", changes.Deltas)
+	}
+}
+
+func TestParseId(t *testing.T) {
+	for _, uri := range []string{"37i9dQZF1DXcBWIGoYBM5M", "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+		"spotify:user:someone:playlist:37i9dQZF1DXcBWIGoYBM5M"} {
+		if id := ParseId(uri); id != "37i9dQZF1DXcBWIGoYBM5M" {
+			t.Errorf("ParseId(%q) = %q", uri, id)
+		}
+	}
+}
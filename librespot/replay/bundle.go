@@ -0,0 +1,177 @@
+// Package replay records a listening session to a bundle, and replays it deterministically: the Connect commands,
+// the packets received from the access point and the playback events are recorded with their timing, so that a
+// report like "it glitched at minute 34" can be reproduced by driving a player and a command handler through the
+// same sequence.
+//
+// The secrets are redacted from the bundles, which can be attached to bug reports: the credentials and keys are
+// dropped, the tokens are masked, and the audio data is only kept if Recorder.KeepAudio is set.
+package replay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/spirc"
+	"github.com/golang/protobuf/proto"
+)
+
+// BundleVersion is the version of the bundles written by the recorder
+const BundleVersion = 1
+
+// Packet directions
+const (
+	DirectionSend = "send"
+	DirectionRecv = "recv"
+)
+
+// Bundle is a recorded listening session
+type Bundle struct {
+	Version int       `json:"version"`
+	Started time.Time `json:"started"`
+	Entries []Entry   `json:"entries"`
+}
+
+// Entry is a command, an event or a packet of a bundle. Exactly one of them is set.
+type Entry struct {
+	// Offset is the time since the start of the recording
+	Offset  time.Duration  `json:"offset"`
+	Command *CommandRecord `json:"command,omitempty"`
+	Event   *EventRecord   `json:"event,omitempty"`
+	Packet  *PacketRecord  `json:"packet,omitempty"`
+}
+
+// CommandRecord is a Connect command sent to the device
+type CommandRecord struct {
+	Type     Spotify.MessageType `json:"type"`
+	From     string              `json:"from,omitempty"`
+	Position uint32              `json:"position,omitempty"`
+	Volume   uint32              `json:"volume,omitempty"`
+	// State is the Spotify.State of a load command, marshaled
+	State []byte `json:"state,omitempty"`
+}
+
+// EventRecord is a playback event of the player
+type EventRecord struct {
+	Type       player.EventType         `json:"type"`
+	TrackId    string                   `json:"track_id,omitempty"`
+	FileId     string                   `json:"file_id,omitempty"`
+	Format     Spotify.AudioFile_Format `json:"format,omitempty"`
+	ContextUri string                   `json:"context_uri,omitempty"`
+	Err        string                   `json:"error,omitempty"`
+}
+
+// PacketRecord is a packet exchanged with the access point
+type PacketRecord struct {
+	Direction string                `json:"direction"`
+	Cmd       connection.PacketType `json:"cmd"`
+	// Data is the redacted payload. The secret payloads are truncated, to their headers if they have some, and
+	// padded back with zeros to Size when replayed.
+	Data []byte `json:"data,omitempty"`
+	Size int    `json:"size"`
+}
+
+// commandRecord returns the record of a command
+func commandRecord(cmd spirc.Command) *CommandRecord {
+	record := &CommandRecord{
+		Type:     cmd.Type,
+		From:     cmd.From,
+		Position: cmd.Position,
+		Volume:   cmd.Volume,
+	}
+	if cmd.State != nil {
+		record.State, _ = proto.Marshal(cmd.State)
+	}
+	return record
+}
+
+// Command returns the recorded command
+func (r *CommandRecord) Command() (spirc.Command, error) {
+	cmd := spirc.Command{
+		Type:     r.Type,
+		From:     r.From,
+		Position: r.Position,
+		Volume:   r.Volume,
+	}
+	if r.State != nil {
+		cmd.State = &Spotify.State{}
+		if err := proto.Unmarshal(r.State, cmd.State); err != nil {
+			return cmd, fmt.Errorf("invalid state of %v command: %v", r.Type, err)
+		}
+	}
+	return cmd, nil
+}
+
+// eventRecord returns the record of an event
+func eventRecord(event player.Event) *EventRecord {
+	record := &EventRecord{
+		Type:       event.Type,
+		TrackId:    hex.EncodeToString(event.TrackId),
+		FileId:     hex.EncodeToString(event.FileId),
+		Format:     event.Format,
+		ContextUri: event.Origin.ContextUri,
+	}
+	if event.Err != nil {
+		record.Err = connection.Redact(event.Err.Error())
+	}
+	return record
+}
+
+// sameEvent returns whether two events are of the same type, for the same audio file
+func sameEvent(a, b *EventRecord) bool {
+	return a.Type == b.Type && a.TrackId == b.TrackId && a.FileId == b.FileId
+}
+
+// Export writes the bundle as a JSON document
+func (b *Bundle) Export(w io.Writer) error {
+	return json.NewEncoder(w).Encode(b)
+}
+
+// ExportFile writes the bundle to path. The file is replaced atomically, so that a reader never sees a partial
+// bundle.
+func (b *Bundle) ExportFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := b.Export(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadBundle reads a bundle written by Export
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %v", err)
+	}
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// ReadBundleFile reads a bundle written by ExportFile
+func ReadBundleFile(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBundle(f)
+}
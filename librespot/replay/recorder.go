@@ -0,0 +1,136 @@
+package replay
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/spirc"
+)
+
+// audioKeyHeaderSize is the size of the sequence number preceding the key in the PacketAesKey packets
+const audioKeyHeaderSize = 4
+
+// Recorder records the Connect commands, the packets and the playback events of a session to a bundle. It is safe
+// for concurrent use.
+type Recorder struct {
+	// KeepAudio records the audio data and the audio keys, so that the replay decodes the same audio. The bundles
+	// then hold copyrighted content and keys, and must not be shared publicly. Without it, the downloads are replayed
+	// with zeroed data, which reproduces their timing but not their decoding.
+	KeepAudio bool
+
+	lock    sync.Mutex
+	started time.Time
+	entries []Entry
+	// channels are the channels whose headers were received, the next packets holding their data
+	channels map[uint16]bool
+}
+
+// NewRecorder creates a recorder, the offsets of the entries being relative to its creation
+func NewRecorder() *Recorder {
+	return &Recorder{
+		started:  time.Now(),
+		channels: map[uint16]bool{},
+	}
+}
+
+// Handler returns a command handler recording the commands before passing them to handler, to be advertised with
+// spirc.Controller.Advertise
+func (r *Recorder) Handler(handler spirc.CommandHandler) spirc.CommandHandler {
+	return func(cmd spirc.Command) {
+		r.add(Entry{Command: commandRecord(cmd)})
+		handler(cmd)
+	}
+}
+
+// Listener returns a player event listener feeding the recorder, to be registered with Player.OnEvent
+func (r *Recorder) Listener() player.EventListener {
+	return func(event player.Event) {
+		r.add(Entry{Event: eventRecord(event)})
+	}
+}
+
+// Stream wraps the connection to the access point, recording its packets
+func (r *Recorder) Stream(stream connection.PacketStream) connection.PacketStream {
+	return &recordingStream{stream: stream, recorder: r}
+}
+
+// Bundle returns the entries recorded so far
+func (r *Recorder) Bundle() *Bundle {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return &Bundle{
+		Version: BundleVersion,
+		Started: r.started,
+		Entries: append([]Entry{}, r.entries...),
+	}
+}
+
+func (r *Recorder) add(entry Entry) {
+	r.lock.Lock()
+	entry.Offset = time.Since(r.started)
+	r.entries = append(r.entries, entry)
+	r.lock.Unlock()
+}
+
+// addPacket records a packet, with its secrets redacted
+func (r *Recorder) addPacket(direction string, cmd connection.PacketType, data []byte) {
+	record := &PacketRecord{Direction: direction, Cmd: cmd, Size: len(data)}
+
+	r.lock.Lock()
+	switch {
+	case cmd == connection.PacketAesKey && r.KeepAudio:
+		record.Data = append([]byte{}, data...)
+	case cmd == connection.PacketAesKey && len(data) >= audioKeyHeaderSize:
+		record.Data = append([]byte{}, data[:audioKeyHeaderSize]...)
+	case cmd.IsSecret():
+	case direction == DirectionRecv && (cmd == connection.PacketStreamChunkRes || cmd == connection.PacketChannelError):
+		record.Data = r.redactChannelPacket(cmd, data)
+	default:
+		record.Data = connection.RedactBytes(data)
+	}
+	r.lock.Unlock()
+
+	r.add(Entry{Packet: record})
+}
+
+// redactChannelPacket returns the recorded data of a channel packet: the first packet of a channel holds its
+// headers, which are kept, and the next ones hold the data, which is only kept with KeepAudio. The lock must be held.
+func (r *Recorder) redactChannelPacket(cmd connection.PacketType, data []byte) []byte {
+	if len(data) < 2 {
+		return append([]byte{}, data...)
+	}
+	num := binary.BigEndian.Uint16(data)
+
+	switch {
+	case cmd == connection.PacketChannelError, len(data) == 2:
+		// The channel ended, its number can be reused
+		delete(r.channels, num)
+	case !r.channels[num]:
+		r.channels[num] = true
+	case !r.KeepAudio:
+		return append([]byte{}, data[:2]...)
+	}
+	return append([]byte{}, data...)
+}
+
+// recordingStream records the packets of a stream
+type recordingStream struct {
+	stream   connection.PacketStream
+	recorder *Recorder
+}
+
+func (s *recordingStream) SendPacket(cmd connection.PacketType, data []byte) error {
+	s.recorder.addPacket(DirectionSend, cmd, data)
+	return s.stream.SendPacket(cmd, data)
+}
+
+func (s *recordingStream) RecvPacket() (connection.PacketType, []byte, error) {
+	cmd, data, err := s.stream.RecvPacket()
+	if err == nil {
+		s.recorder.addPacket(DirectionRecv, cmd, data)
+	}
+	return cmd, data, err
+}
@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/spirc"
+	"github.com/golang/protobuf/proto"
+)
+
+type packet struct {
+	cmd  connection.PacketType
+	data []byte
+}
+
+// fakeStream returns its packets, then io.EOF
+type fakeStream struct {
+	packets []packet
+	sent    []packet
+}
+
+func (s *fakeStream) SendPacket(cmd connection.PacketType, data []byte) error {
+	s.sent = append(s.sent, packet{cmd, data})
+	return nil
+}
+
+func (s *fakeStream) RecvPacket() (connection.PacketType, []byte, error) {
+	if len(s.packets) == 0 {
+		return 0, nil, io.EOF
+	}
+	p := s.packets[0]
+	s.packets = s.packets[1:]
+	return p.cmd, p.data, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	key := append([]byte{0, 0, 0, 1}, bytes.Repeat([]byte{0x42}, 16)...)
+	header := []byte{0, 3, 0, 5, 3, 0, 0, 1, 0, 0, 0}
+	audio := append([]byte{0, 3}, bytes.Repeat([]byte{0x55}, 100)...)
+	stream := &fakeStream{packets: []packet{
+		{connection.PacketAPWelcome, []byte("credentials")},
+		{connection.PacketMercuryReq, []byte(`{"access_token":"secret"}`)},
+		{connection.PacketAesKey, key},
+		{connection.PacketStreamChunkRes, header},
+		{connection.PacketStreamChunkRes, audio},
+		{connection.PacketStreamChunkRes, []byte{0, 3}},
+	}}
+
+	recorder := NewRecorder()
+	recorded := recorder.Stream(stream)
+	recorded.SendPacket(connection.PacketLogin, []byte("password"))
+	handle := recorder.Handler(func(cmd spirc.Command) {})
+	listener := recorder.Listener()
+	for i := 0; i < 3; i++ {
+		if _, _, err := recorded.RecvPacket(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handle(spirc.Command{
+		Type:  Spotify.MessageType_kMessageTypeLoad,
+		From:  "phone",
+		State: &Spotify.State{ContextUri: proto.String("spotify:album:a")},
+	})
+	for i := 0; i < 3; i++ {
+		if _, _, err := recorded.RecvPacket(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listener(player.Event{Type: player.EventTrackStart, TrackId: []byte{1}, FileId: []byte{2}})
+	listener(player.Event{Type: player.EventError, TrackId: []byte{1},
+		Err: errors.New("GET https://host/?token=abc failed")})
+
+	var buf bytes.Buffer
+	if err := recorder.Bundle().Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("abc")) {
+		t.Errorf("The bundle leaks secrets: %s", buf.Bytes())
+	}
+	bundle, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Entries) != 10 {
+		t.Fatalf("Got %d entries", len(bundle.Entries))
+	}
+	if login := bundle.Entries[0].Packet; login.Direction != DirectionSend || login.Data != nil || login.Size != 8 {
+		t.Errorf("Got login packet %+v", login)
+	}
+
+	var packets []packet
+	var commands []spirc.Command
+	replayer := NewReplayer(bundle)
+	replayer.Speed = 0
+	err = replayer.Run(context.Background(), Target{
+		Packets: func(cmd connection.PacketType, data []byte) {
+			packets = append(packets, packet{cmd, data})
+		},
+		Commands: func(cmd spirc.Command) {
+			if len(packets) != 3 {
+				t.Errorf("The command was replayed after %d packets, expected 3", len(packets))
+			}
+			commands = append(commands, cmd)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commands) != 1 || commands[0].From != "phone" || commands[0].State.GetContextUri() != "spotify:album:a" {
+		t.Errorf("Got commands %v", commands)
+	}
+	if len(packets) != 6 {
+		t.Fatalf("Got %d packets", len(packets))
+	}
+	for i, expected := range [][]byte{
+		make([]byte, 11),
+		[]byte(`{"access_token":"******"}`),
+		append([]byte{0, 0, 0, 1}, make([]byte, 16)...),
+		header,
+		append([]byte{0, 3}, make([]byte, 100)...),
+		{0, 3},
+	} {
+		if !bytes.Equal(packets[i].data, expected) {
+			t.Errorf("Replayed packet %d as %q, expected %q", i, packets[i].data, expected)
+		}
+	}
+
+	if _, _, err := replayer.Stream().RecvPacket(); err != io.EOF {
+		t.Errorf("The stream should be closed after the replay, got %v", err)
+	}
+
+	replayer.events = []*EventRecord{bundle.Entries[8].Event}
+	if d := replayer.Divergences(); len(d) != 1 || d[0].Index != 1 || d[0].Got != nil ||
+		d[0].Expected.Type != player.EventError {
+		t.Errorf("Got divergences %+v", d)
+	}
+}
+
+func TestRecordAudio(t *testing.T) {
+	key := append([]byte{0, 0, 0, 1}, bytes.Repeat([]byte{0x42}, 16)...)
+	audio := append([]byte{0, 3}, bytes.Repeat([]byte{0x55}, 100)...)
+	stream := &fakeStream{packets: []packet{
+		{connection.PacketAesKey, key},
+		{connection.PacketStreamChunkRes, []byte{0, 3, 0, 0}},
+		{connection.PacketStreamChunkRes, audio},
+	}}
+
+	recorder := NewRecorder()
+	recorder.KeepAudio = true
+	recorded := recorder.Stream(stream)
+	for i := 0; i < 3; i++ {
+		recorded.RecvPacket()
+	}
+
+	bundle := recorder.Bundle()
+	if data := bundle.Entries[0].Packet.Data; !bytes.Equal(data, key) {
+		t.Errorf("Got audio key %x", data)
+	}
+	if data := bundle.Entries[2].Packet.Data; !bytes.Equal(data, audio) {
+		t.Errorf("Got audio data %x", data)
+	}
+}
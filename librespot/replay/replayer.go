@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/spirc"
+)
+
+// PacketHandler handles a packet received from the access point
+type PacketHandler func(cmd connection.PacketType, data []byte)
+
+// Target is what a replay drives
+type Target struct {
+	// Packets receives the packets received from the access point, see Dispatcher
+	Packets PacketHandler
+	// Commands receives the Connect commands
+	Commands spirc.CommandHandler
+	// Player emits the events compared with the recorded ones, see Replayer.Divergences
+	Player *player.Player
+}
+
+// Dispatcher returns a packet handler passing the mercury packets to the mercury client, and the audio keys and
+// channel packets to the player, like a session does. The packets the mercury client fails to handle are logged to
+// logger, the standard logger if nil.
+func Dispatcher(m *mercury.Client, p *player.Player, logger *log.Logger) PacketHandler {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(cmd connection.PacketType, data []byte) {
+		switch {
+		case cmd.IsMercury() && m != nil:
+			if err := m.Handle(cmd, bytes.NewReader(data)); err != nil {
+				logger.Printf("[replay] error handling mercury packet %v: %v", cmd, err)
+			}
+		case p != nil:
+			p.HandleCmd(cmd, data)
+		}
+	}
+}
+
+// Divergence is an event of the replay which differs from the recorded one at the same index. Expected is nil for
+// the events which were not recorded, and Got for the ones which were not replayed.
+type Divergence struct {
+	Index    int
+	Expected *EventRecord
+	Got      *EventRecord
+}
+
+// Replayer replays a bundle. The mercury client and the player it drives are created with its Stream:
+//
+//	replayer := replay.NewReplayer(bundle)
+//	m := mercury.CreateMercury(replayer.Stream())
+//	p := player.CreatePlayer(replayer.Stream(), m)
+//	err := replayer.Run(ctx, replay.Target{Packets: replay.Dispatcher(m, p, nil), Commands: handler, Player: p})
+type Replayer struct {
+	// Speed is the factor applied to the pace of the recording, 1 by default. Zero replays the entries without
+	// waiting, which is only deterministic if the target handles them synchronously.
+	Speed float64
+
+	bundle *Bundle
+	done   chan struct{}
+
+	lock   sync.Mutex
+	events []*EventRecord
+}
+
+// NewReplayer creates a replayer of the bundle
+func NewReplayer(bundle *Bundle) *Replayer {
+	return &Replayer{
+		Speed:  1,
+		bundle: bundle,
+		done:   make(chan struct{}),
+	}
+}
+
+// Stream returns the connection to the access point of the replay: the packets sent are dropped, and the packets
+// received are passed to the target by Run, so that RecvPacket blocks until the end of the replay.
+func (r *Replayer) Stream() connection.PacketStream {
+	return replayStream{done: r.done}
+}
+
+// Run passes the recorded commands and received packets to the target, in their order and at their pace, until the
+// end of the bundle or until the context is done. It can only be called once.
+func (r *Replayer) Run(ctx context.Context, target Target) error {
+	defer close(r.done)
+	if target.Player != nil {
+		target.Player.OnEvent(func(event player.Event) {
+			r.lock.Lock()
+			r.events = append(r.events, eventRecord(event))
+			r.lock.Unlock()
+		})
+	}
+
+	start := time.Now()
+	for _, entry := range r.bundle.Entries {
+		if r.Speed > 0 {
+			delay := time.Duration(float64(entry.Offset)/r.Speed) - time.Since(start)
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		switch {
+		case entry.Command != nil && target.Commands != nil:
+			cmd, err := entry.Command.Command()
+			if err != nil {
+				return err
+			}
+			target.Commands(cmd)
+		case entry.Packet != nil && entry.Packet.Direction == DirectionRecv && target.Packets != nil:
+			target.Packets(entry.Packet.Cmd, entry.Packet.data())
+		}
+	}
+	return nil
+}
+
+// data returns the data of a packet, padded with zeros to its size
+func (p *PacketRecord) data() []byte {
+	if len(p.Data) >= p.Size {
+		return append([]byte{}, p.Data...)
+	}
+	data := make([]byte, p.Size)
+	copy(data, p.Data)
+	return data
+}
+
+// Divergences compares the events emitted by the player during the replay with the recorded ones, and returns those
+// which differ in type or audio file, the timing being ignored
+func (r *Replayer) Divergences() []Divergence {
+	var expected []*EventRecord
+	for _, entry := range r.bundle.Entries {
+		if entry.Event != nil {
+			expected = append(expected, entry.Event)
+		}
+	}
+	r.lock.Lock()
+	got := append([]*EventRecord{}, r.events...)
+	r.lock.Unlock()
+
+	var res []Divergence
+	for i := 0; i < len(expected) || i < len(got); i++ {
+		d := Divergence{Index: i}
+		if i < len(expected) {
+			d.Expected = expected[i]
+		}
+		if i < len(got) {
+			d.Got = got[i]
+		}
+		if d.Expected == nil || d.Got == nil || !sameEvent(d.Expected, d.Got) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// replayStream drops the packets sent, and receives none
+type replayStream struct {
+	done chan struct{}
+}
+
+func (s replayStream) SendPacket(cmd connection.PacketType, data []byte) error {
+	return nil
+}
+
+func (s replayStream) RecvPacket() (connection.PacketType, []byte, error) {
+	<-s.done
+	return 0, nil, io.EOF
+}
@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"sync"
+	"time"
+)
+
+// Analyzer is called with the PCM played by an AnalysisSink, e.g. to compute a loudness meter or a visualization.
+// The PCM holds whole frames, before the volume of the sink is applied, and is only valid until the analyzer returns.
+type Analyzer func(pcm []byte)
+
+// AnalysisSink passes the PCM written to a sink to an analyzer as well. The PCM is copied to a ring buffer, and the
+// analyzer is called from a goroutine of its own, so that a slow analyzer never delays the playback: the oldest
+// frames it didn't get to are dropped instead.
+type AnalysisSink struct {
+	AudioSink
+	analyzer  Analyzer
+	frameSize int
+
+	lock sync.Mutex
+	// ring holds the PCM not analyzed yet, the length bytes from start, wrapping around
+	ring    []byte
+	start   int
+	length  int
+	dropped int64
+
+	wake      chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAnalysisSink creates a sink playing the PCM of the format on s, and passing it to the analyzer. The ring buffer
+// holds the given duration of audio, one second if zero.
+func NewAnalysisSink(s AudioSink, format Format, buffer time.Duration, analyzer Analyzer) *AnalysisSink {
+	if buffer <= 0 {
+		buffer = time.Second
+	}
+	frames := int(int64(buffer) * int64(format.SampleRate) / int64(time.Second))
+	if frames < 1 {
+		frames = 1
+	}
+
+	a := &AnalysisSink{
+		AudioSink: s,
+		analyzer:  analyzer,
+		frameSize: format.frameSize(),
+		ring:      make([]byte, frames*format.frameSize()),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AnalysisSink) Write(pcm []byte) (int, error) {
+	a.push(pcm)
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+	return a.AudioSink.Write(pcm)
+}
+
+// Dropped returns the number of frames dropped so far because the analyzer lagged behind
+func (a *AnalysisSink) Dropped() int64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.dropped
+}
+
+// Close stops the analysis, dropping the PCM not analyzed yet, and closes the sink once the analyzer returned
+func (a *AnalysisSink) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.done)
+	})
+	<-a.stopped
+	return a.AudioSink.Close()
+}
+
+// push copies the PCM to the ring buffer, dropping the oldest frames if it is full
+func (a *AnalysisSink) push(pcm []byte) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if len(pcm) > len(a.ring) {
+		a.dropped += int64((len(pcm) - len(a.ring)) / a.frameSize)
+		pcm = pcm[len(pcm)-len(a.ring):]
+	}
+	if overflow := a.length + len(pcm) - len(a.ring); overflow > 0 {
+		a.dropped += int64(overflow / a.frameSize)
+		a.start = (a.start + overflow) % len(a.ring)
+		a.length -= overflow
+	}
+
+	end := (a.start + a.length) % len(a.ring)
+	n := copy(a.ring[end:], pcm)
+	copy(a.ring, pcm[n:])
+	a.length += len(pcm)
+}
+
+// take appends the PCM of the ring buffer to buf, and empties it
+func (a *AnalysisSink) take(buf []byte) []byte {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	end := a.start + a.length
+	if end <= len(a.ring) {
+		buf = append(buf, a.ring[a.start:end]...)
+	} else {
+		buf = append(buf, a.ring[a.start:]...)
+		buf = append(buf, a.ring[:end-len(a.ring)]...)
+	}
+	a.start, a.length = 0, 0
+	return buf
+}
+
+// run calls the analyzer with the PCM written, until the sink is closed
+func (a *AnalysisSink) run() {
+	defer close(a.stopped)
+
+	var buf []byte
+	for {
+		select {
+		case <-a.wake:
+		case <-a.done:
+			return
+		}
+		if buf = a.take(buf[:0]); len(buf) > 0 {
+			a.analyzer(buf)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package sink
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// CommandSink writes the PCM to the standard input of a command playing it, e.g. pacat or aplay. The command is
+// started by the first write, and stopped when the sink is paused, so that it doesn't hold the device meanwhile.
+type CommandSink struct {
+	name string
+	args []string
+
+	lock   sync.Mutex
+	volume float64
+	buf    []byte
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+}
+
+// NewCommandSink creates a sink running the command with the arguments to play the PCM
+func NewCommandSink(name string, args ...string) *CommandSink {
+	return &CommandSink{name: name, args: args, volume: 1}
+}
+
+// NewPulseAudioSink creates a sink playing the PCM on the default PulseAudio sink, through pacat
+func NewPulseAudioSink(format Format) (*CommandSink, error) {
+	if _, err := exec.LookPath("pacat"); err != nil {
+		return nil, err
+	}
+	return NewCommandSink("pacat", "--playback", "--raw", "--format=s16le",
+		"--rate="+strconv.Itoa(format.SampleRate), "--channels="+strconv.Itoa(format.Channels)), nil
+}
+
+// NewALSASink creates a sink playing the PCM on an ALSA device, e.g. "hw:0", or the default one if empty, through
+// aplay
+func NewALSASink(format Format, device string) (*CommandSink, error) {
+	if _, err := exec.LookPath("aplay"); err != nil {
+		return nil, err
+	}
+	args := []string{"-q", "-t", "raw", "-f", "S16_LE",
+		"-r", strconv.Itoa(format.SampleRate), "-c", strconv.Itoa(format.Channels)}
+	if device != "" {
+		args = append(args, "-D", device)
+	}
+	return NewCommandSink("aplay", args...), nil
+}
+
+func (s *CommandSink) Write(pcm []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cmd == nil {
+		if err := s.start(); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := s.stdin.Write(applyVolume(&s.buf, pcm, s.volume)); err != nil {
+		if stopErr := s.stop(); stopErr != nil {
+			err = stopErr
+		}
+		return 0, err
+	}
+	return len(pcm), nil
+}
+
+func (s *CommandSink) SetVolume(volume float64) {
+	s.lock.Lock()
+	s.volume = clampVolume(volume)
+	s.lock.Unlock()
+}
+
+// Pause stops the command, once it played the audio written
+func (s *CommandSink) Pause() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stop()
+}
+
+func (s *CommandSink) Close() error {
+	return s.Pause()
+}
+
+// start starts the command, it must be called with lock held
+func (s *CommandSink) start() error {
+	cmd := exec.Command(s.name, s.args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %v", s.name, err)
+	}
+	s.cmd = cmd
+	s.stdin = stdin
+	return nil
+}
+
+// stop closes the input of the command, and waits for it to exit. It must be called with lock held.
+func (s *CommandSink) stop() error {
+	if s.cmd == nil {
+		return nil
+	}
+	s.stdin.Close()
+	err := s.cmd.Wait()
+	s.cmd = nil
+	s.stdin = nil
+	if err != nil {
+		return fmt.Errorf("%s: %v", s.name, err)
+	}
+	return nil
+}
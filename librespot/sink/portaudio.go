@@ -0,0 +1,107 @@
+//go:build portaudio
+// +build portaudio
+
+package sink
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/xlab/portaudio-go/portaudio"
+)
+
+var initPortAudio sync.Once
+var initPortAudioErr error
+
+// PortAudioSink plays the PCM on the default PortAudio device
+type PortAudioSink struct {
+	format Format
+
+	lock    sync.Mutex
+	volume  float64
+	buf     []byte
+	stream  *portaudio.Stream
+	started bool
+}
+
+// NewPortAudioSink creates a sink playing the PCM on the default PortAudio device. It is only available when the
+// library is built with the portaudio tag, which needs the PortAudio headers, and returns ErrPortAudioDisabled
+// otherwise.
+func NewPortAudioSink(format Format) (AudioSink, error) {
+	initPortAudio.Do(func() {
+		if err := portaudio.Initialize(); paError(err) {
+			initPortAudioErr = paErrorText(err)
+		}
+	})
+	if initPortAudioErr != nil {
+		return nil, initPortAudioErr
+	}
+
+	s := &PortAudioSink{format: format, volume: 1}
+	if err := portaudio.OpenDefaultStream(&s.stream, 0, int32(format.Channels), portaudio.PaInt16,
+		float64(format.SampleRate), 0, nil, nil); paError(err) {
+		return nil, paErrorText(err)
+	}
+	return s, nil
+}
+
+func (s *PortAudioSink) Write(pcm []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.started {
+		if err := portaudio.StartStream(s.stream); paError(err) {
+			return 0, paErrorText(err)
+		}
+		s.started = true
+	}
+	frames := len(pcm) / s.format.frameSize()
+	if frames == 0 {
+		return len(pcm), nil
+	}
+	out := applyVolume(&s.buf, pcm, s.volume)
+	if err := portaudio.WriteStream(s.stream, unsafe.Pointer(&out[0]), uint(frames)); paError(err) {
+		return 0, paErrorText(err)
+	}
+	return len(pcm), nil
+}
+
+func (s *PortAudioSink) SetVolume(volume float64) {
+	s.lock.Lock()
+	s.volume = clampVolume(volume)
+	s.lock.Unlock()
+}
+
+// Pause aborts the stream, dropping the audio it buffered
+func (s *PortAudioSink) Pause() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.started {
+		return nil
+	}
+	s.started = false
+	if err := portaudio.AbortStream(s.stream); paError(err) {
+		return paErrorText(err)
+	}
+	return nil
+}
+
+func (s *PortAudioSink) Close() error {
+	if err := s.Pause(); err != nil {
+		return err
+	}
+	if err := portaudio.CloseStream(s.stream); paError(err) {
+		return paErrorText(err)
+	}
+	return nil
+}
+
+func paError(err portaudio.Error) bool {
+	return portaudio.ErrorCode(err) != portaudio.PaNoError
+}
+
+func paErrorText(err portaudio.Error) error {
+	return fmt.Errorf("PortAudio error: %s", portaudio.GetErrorText(err))
+}
@@ -0,0 +1,9 @@
+//go:build !portaudio
+// +build !portaudio
+
+package sink
+
+// NewPortAudioSink returns ErrPortAudioDisabled, the library being built without the portaudio tag
+func NewPortAudioSink(format Format) (AudioSink, error) {
+	return nil, ErrPortAudioDisabled
+}
@@ -0,0 +1,62 @@
+// Package sink plays PCM audio on an output: an audio device through PortAudio, PulseAudio or ALSA, or any writer,
+// e.g. the FIFO of a snapcast server.
+//
+// The PCM is signed 16-bit little-endian, with the samples of the channels interleaved.
+package sink
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrPortAudioDisabled is returned by NewPortAudioSink when the library is built without the portaudio tag
+var ErrPortAudioDisabled = errors.New("PortAudio is disabled in this build")
+
+// Format describes the PCM written to a sink
+type Format struct {
+	SampleRate int
+	Channels   int
+}
+
+// DefaultFormat is the format of the Spotify tracks
+var DefaultFormat = Format{SampleRate: 44100, Channels: 2}
+
+// frameSize returns the size of the samples of all the channels, in bytes
+func (f Format) frameSize() int {
+	return 2 * f.Channels
+}
+
+// AudioSink is an output of PCM audio. Its methods are safe for concurrent use.
+type AudioSink interface {
+	// Write plays the PCM, which holds whole frames, blocking until the output accepted it
+	Write(pcm []byte) (int, error)
+	// SetVolume sets the volume applied to the PCM, from 0 (muted) to 1 (unchanged), the default
+	SetVolume(volume float64)
+	// Pause stops the output, dropping the audio it buffered if possible. The next Write resumes it.
+	Pause() error
+	// Close stops the output and releases it
+	Close() error
+}
+
+// applyVolume returns the PCM scaled by the volume, in buf which grows as needed
+func applyVolume(buf *[]byte, pcm []byte, volume float64) []byte {
+	if volume >= 1 {
+		return pcm
+	}
+	if cap(*buf) < len(pcm) {
+		*buf = make([]byte, len(pcm))
+	}
+	out := (*buf)[:len(pcm)]
+	copy(out, pcm)
+	for i := 0; i+1 < len(out); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(out[i:])))
+		binary.LittleEndian.PutUint16(out[i:], uint16(int16(math.Round(sample*volume))))
+	}
+	return out
+}
+
+// clampVolume returns the volume within 0 and 1
+func clampVolume(volume float64) float64 {
+	return math.Max(0, math.Min(1, volume))
+}
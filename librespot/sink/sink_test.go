@@ -0,0 +1,126 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func pcm(samples ...int16) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+func TestWriterSinkVolume(t *testing.T) {
+	out := new(bytes.Buffer)
+	s := NewWriterSink(out)
+
+	input := pcm(1000, -1000, 32767, -32768)
+	if _, err := s.Write(input); err != nil {
+		t.Fatal(err)
+	}
+	s.SetVolume(0.5)
+	if _, err := s.Write(input); err != nil {
+		t.Fatal(err)
+	}
+	s.SetVolume(-1)
+	if _, err := s.Write(input); err != nil {
+		t.Fatal(err)
+	}
+
+	want := append(append(append([]byte{}, input...), pcm(500, -500, 16384, -16384)...), pcm(0, 0, 0, 0)...)
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("Got %v, want %v", out.Bytes(), want)
+	}
+	if !bytes.Equal(input, pcm(1000, -1000, 32767, -32768)) {
+		t.Errorf("Input modified: %v", input)
+	}
+}
+
+func TestCommandSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out")
+
+	// Every start of the command appends to the file
+	s := NewCommandSink("sh", "-c", "cat >> "+path)
+	if _, err := s.Write(pcm(1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(path)
+	if !bytes.Equal(data, pcm(1, 2)) {
+		t.Errorf("Got %v once paused", data)
+	}
+
+	// Written again, the command is restarted
+	if _, err := s.Write(pcm(3, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = ioutil.ReadFile(path)
+	if !bytes.Equal(data, pcm(1, 2, 3, 4)) {
+		t.Errorf("Got %v once closed", data)
+	}
+}
+
+func TestAnalysisSink(t *testing.T) {
+	out := new(bytes.Buffer)
+	analyzed := make(chan []byte)
+	release := make(chan bool)
+	// The ring buffer holds 4 frames of a mono format
+	s := NewAnalysisSink(NewWriterSink(out), Format{SampleRate: 4, Channels: 1}, time.Second, func(pcm []byte) {
+		analyzed <- append([]byte{}, pcm...)
+		<-release
+	})
+	defer s.Close()
+
+	if _, err := s.Write(pcm(1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-analyzed; !bytes.Equal(got, pcm(1, 2)) {
+		t.Errorf("Analyzed %v", got)
+	}
+
+	// The analyzer lags behind, the playback goes on and the oldest frames are dropped
+	for i := int16(3); i <= 8; i++ {
+		if _, err := s.Write(pcm(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release <- true
+	if got := <-analyzed; !bytes.Equal(got, pcm(5, 6, 7, 8)) {
+		t.Errorf("Analyzed %v", got)
+	}
+	release <- true
+	if s.Dropped() != 2 {
+		t.Errorf("Dropped %d frames", s.Dropped())
+	}
+	if !bytes.Equal(out.Bytes(), pcm(1, 2, 3, 4, 5, 6, 7, 8)) {
+		t.Errorf("Played %v", out.Bytes())
+	}
+
+	// A write larger than the ring buffer keeps its last frames
+	if _, err := s.Write(pcm(9, 10, 11, 12, 13, 14)); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-analyzed; !bytes.Equal(got, pcm(11, 12, 13, 14)) {
+		t.Errorf("Analyzed %v", got)
+	}
+	release <- true
+	if s.Dropped() != 4 {
+		t.Errorf("Dropped %d frames", s.Dropped())
+	}
+}
@@ -0,0 +1,48 @@
+package sink
+
+import (
+	"io"
+	"sync"
+)
+
+// WriterSink writes the PCM to a writer, e.g. a pipe or the FIFO of a snapcast server. The writer sets the pace of
+// the playback.
+type WriterSink struct {
+	lock   sync.Mutex
+	w      io.Writer
+	volume float64
+	buf    []byte
+}
+
+// NewWriterSink creates a sink writing the PCM to w, which is closed with the sink if it is an io.Closer
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w, volume: 1}
+}
+
+func (s *WriterSink) Write(pcm []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.w.Write(applyVolume(&s.buf, pcm, s.volume)); err != nil {
+		return 0, err
+	}
+	return len(pcm), nil
+}
+
+func (s *WriterSink) SetVolume(volume float64) {
+	s.lock.Lock()
+	s.volume = clampVolume(volume)
+	s.lock.Unlock()
+}
+
+// Pause does nothing, the audio written being out of reach of the sink
+func (s *WriterSink) Pause() error {
+	return nil
+}
+
+func (s *WriterSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package spclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// PutConnectState publishes the state of a Connect device, so that it appears in the device lists of the Spotify
+// apps. connectionId is the id of the dealer connection the commands to the device are sent through. It returns the
+// cluster describing all the devices of the user.
+func (c *Client) PutConnectState(deviceId, connectionId string,
+	request *Spotify.PutStateRequest) (*Spotify.Cluster, error) {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-protobuf")
+	header.Set("X-Spotify-Connection-Id", connectionId)
+	body, err = c.RequestWithHeader("PUT", "/connect-state/v1/devices/"+deviceId, body, header)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &Spotify.Cluster{}
+	if err := proto.Unmarshal(body, cluster); err != nil {
+		return nil, fmt.Errorf("invalid cluster: %v", err)
+	}
+	return cluster, nil
+}
+
+// DeleteConnectState removes a Connect device from the devices of the user
+func (c *Client) DeleteConnectState(deviceId, connectionId string) error {
+	header := http.Header{}
+	header.Set("X-Spotify-Connection-Id", connectionId)
+	_, err := c.RequestWithHeader("DELETE", "/connect-state/v1/devices/"+deviceId, nil, header)
+	return err
+}
@@ -0,0 +1,126 @@
+package spclient
+
+import (
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// ExtensionKind is a kind of metadata returned by the extended-metadata endpoint
+type ExtensionKind int
+
+// ExtensionKind values of the v4 metadata, whose data are the messages of the metadata API
+const (
+	// ExtensionArtist is a Spotify.Artist
+	ExtensionArtist ExtensionKind = 8
+	// ExtensionAlbum is a Spotify.Album
+	ExtensionAlbum ExtensionKind = 9
+	// ExtensionTrack is a Spotify.Track
+	ExtensionTrack ExtensionKind = 10
+	// ExtensionShow is a Spotify.Show
+	ExtensionShow ExtensionKind = 11
+	// ExtensionEpisode is a Spotify.Episode
+	ExtensionEpisode ExtensionKind = 12
+)
+
+// EntityRequest asks for kinds of metadata about an entity, e.g. a track and its audio files
+type EntityRequest struct {
+	// Uri is the URI of the entity, e.g. spotify:track:<base62 id>
+	Uri   string
+	Kinds []ExtensionKind
+}
+
+// ExtendedMetadata is a kind of metadata about an entity
+type ExtendedMetadata struct {
+	Uri  string
+	Kind ExtensionKind
+	// StatusCode is the HTTP status of this metadata, 200 if it was found
+	StatusCode int
+	ETag       string
+	// TypeUrl identifies the message of Data, e.g. type.googleapis.com/spotify.metadata.Track
+	TypeUrl string
+	Data    []byte
+}
+
+// Decode unmarshals the data of the metadata into msg, e.g. a Spotify.Track for ExtensionTrack
+func (m *ExtendedMetadata) Decode(msg proto.Message) error {
+	if m.StatusCode != 200 {
+		return fmt.Errorf("no %d metadata for %s: status %d", m.Kind, m.Uri, m.StatusCode)
+	}
+	if err := proto.Unmarshal(m.Data, msg); err != nil {
+		return fmt.Errorf("invalid %d metadata for %s: %v", m.Kind, m.Uri, err)
+	}
+	return nil
+}
+
+// ExtendedMetadata requests several kinds of metadata about several entities in one call, localized for the country
+// of the user. The metadata missing for an entity are returned with their status code, only the failure of the whole
+// request is an error.
+func (c *Client) ExtendedMetadata(country string, requests ...EntityRequest) ([]ExtendedMetadata, error) {
+	req, err := proto.Marshal(batchedEntityRequest(country, requests))
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Request("POST", "/extended-metadata/v0/extended-metadata", req, "application/protobuf")
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Spotify.BatchedExtensionResponse{}
+	if err := proto.Unmarshal(body, res); err != nil {
+		return nil, fmt.Errorf("invalid extended metadata: %v", err)
+	}
+	var metadata []ExtendedMetadata
+	for _, array := range res.GetExtendedMetadata() {
+		for _, data := range array.GetExtensionData() {
+			metadata = append(metadata, ExtendedMetadata{
+				Uri:        data.GetEntityUri(),
+				Kind:       ExtensionKind(array.GetExtensionKind()),
+				StatusCode: int(data.GetHeader().GetStatusCode()),
+				ETag:       data.GetHeader().GetEtag(),
+				TypeUrl:    data.GetExtensionData().GetTypeUrl(),
+				Data:       data.GetExtensionData().GetValue(),
+			})
+		}
+	}
+	return metadata, nil
+}
+
+// Tracks returns the metadata of several tracks in one call, by URI. The tracks whose metadata is missing are left
+// out.
+func (c *Client) Tracks(country string, uris ...string) (map[string]*Spotify.Track, error) {
+	requests := make([]EntityRequest, len(uris))
+	for i, uri := range uris {
+		requests[i] = EntityRequest{Uri: uri, Kinds: []ExtensionKind{ExtensionTrack}}
+	}
+	metadata, err := c.ExtendedMetadata(country, requests...)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make(map[string]*Spotify.Track, len(metadata))
+	for i := range metadata {
+		track := &Spotify.Track{}
+		if metadata[i].Kind != ExtensionTrack || metadata[i].Decode(track) != nil {
+			continue
+		}
+		tracks[metadata[i].Uri] = track
+	}
+	return tracks, nil
+}
+
+// batchedEntityRequest returns the BatchedEntityRequest of the requests
+func batchedEntityRequest(country string, requests []EntityRequest) *Spotify.BatchedEntityRequest {
+	req := &Spotify.BatchedEntityRequest{
+		Header: &Spotify.BatchedEntityRequestHeader{Country: proto.String(country)},
+	}
+	for _, r := range requests {
+		entity := &Spotify.EntityRequest{EntityUri: proto.String(r.Uri)}
+		for _, kind := range r.Kinds {
+			entity.Query = append(entity.Query, &Spotify.ExtensionQuery{ExtensionKind: proto.Int32(int32(kind))})
+		}
+		req.EntityRequest = append(req.EntityRequest, entity)
+	}
+	return req
+}
@@ -0,0 +1,67 @@
+package spclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// extensionData returns an EntityExtensionData message
+func extensionData(uri string, status int32, data []byte) *Spotify.EntityExtensionData {
+	return &Spotify.EntityExtensionData{
+		Header:    &Spotify.EntityExtensionDataHeader{StatusCode: proto.Int32(status)},
+		EntityUri: proto.String(uri),
+		ExtensionData: &anypb.Any{
+			TypeUrl: "type.googleapis.com/spotify.metadata.Track",
+			Value:   data,
+		},
+	}
+}
+
+func TestTracks(t *testing.T) {
+	found, missing := "spotify:track:found", "spotify:track:missing"
+	track, _ := proto.Marshal(&Spotify.Track{Name: proto.String("Song")})
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "POST" || !strings.Contains(string(body), found) {
+			t.Errorf("bad request %s %q", r.Method, body)
+		}
+
+		res, _ := proto.Marshal(&Spotify.BatchedExtensionResponse{
+			ExtendedMetadata: []*Spotify.EntityExtensionDataArray{{
+				ExtensionKind: proto.Int32(int32(ExtensionTrack)),
+				ExtensionData: []*Spotify.EntityExtensionData{
+					extensionData(found, 200, track),
+					extensionData(missing, 404, nil),
+				},
+			}},
+		})
+		w.Write(res)
+	}))
+	defer server.Close()
+
+	client := New(strings.TrimPrefix(server.URL, "https://"), &fakeAuthorizer{}, server.Client(), "")
+	tracks, err := client.Tracks("FR", found, missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 1 || tracks[found].GetName() != "Song" {
+		t.Errorf("got tracks %v, want the one of %s", tracks, found)
+	}
+
+	metadata, err := client.ExtendedMetadata("FR", EntityRequest{Uri: found, Kinds: []ExtensionKind{ExtensionTrack}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata) != 2 || metadata[1].Uri != missing || metadata[1].StatusCode != 404 ||
+		metadata[0].TypeUrl != "type.googleapis.com/spotify.metadata.Track" {
+		t.Errorf("got metadata %+v", metadata)
+	}
+}
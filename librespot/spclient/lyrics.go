@@ -0,0 +1,75 @@
+package spclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/lyrics"
+)
+
+// ErrNoLyrics is returned when a track has no lyrics
+var ErrNoLyrics = errors.New("no lyrics")
+
+// colorLyrics is the response of the color-lyrics API
+type colorLyrics struct {
+	Lyrics struct {
+		// SyncType is LINE_SYNCED, SYLLABLE_SYNCED or UNSYNCED
+		SyncType string `json:"syncType"`
+		Lines    []struct {
+			// StartTimeMs is the start of the line in milliseconds, as a string
+			StartTimeMs string `json:"startTimeMs"`
+			Words       string `json:"words"`
+		} `json:"lines"`
+		ProviderDisplayName string `json:"providerDisplayName"`
+		Language            string `json:"language"`
+	} `json:"lyrics"`
+}
+
+// Lyrics returns the lyrics of a track, by base62 id, from the color-lyrics API, with the start times of the lines
+// if they are synced. ErrNoLyrics is returned if the track has none.
+func (c *Client) Lyrics(trackId string) (*lyrics.Lyrics, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	body, err := c.RequestWithHeader("GET", "/color-lyrics/v2/track/"+trackId+"?format=json&market=from_token", nil,
+		header)
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		return nil, ErrNoLyrics
+	} else if err != nil {
+		return nil, err
+	}
+	return parseLyrics(body)
+}
+
+// parseLyrics returns the lyrics of a color-lyrics response
+func parseLyrics(body []byte) (*lyrics.Lyrics, error) {
+	var res colorLyrics
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("invalid lyrics: %v", err)
+	}
+	if len(res.Lyrics.Lines) == 0 {
+		return nil, ErrNoLyrics
+	}
+
+	l := &lyrics.Lyrics{
+		Synced:   res.Lyrics.SyncType != "" && res.Lyrics.SyncType != "UNSYNCED",
+		Provider: res.Lyrics.ProviderDisplayName,
+		Language: res.Lyrics.Language,
+		Lines:    make([]lyrics.Line, len(res.Lyrics.Lines)),
+	}
+	for i, line := range res.Lyrics.Lines {
+		l.Lines[i].Words = line.Words
+		if !l.Synced {
+			continue
+		}
+		start, err := strconv.ParseInt(line.StartTimeMs, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of lyrics line %d: %v", i, err)
+		}
+		l.Lines[i].Start = time.Duration(start) * time.Millisecond
+	}
+	return l, nil
+}
@@ -0,0 +1,35 @@
+package spclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLyrics(t *testing.T) {
+	l, err := parseLyrics([]byte(`{"lyrics":{"syncType":"LINE_SYNCED","lines":[
+		{"startTimeMs":"1500","words":"First line","syllables":[],"endTimeMs":"0"},
+		{"startTimeMs":"4020","words":"Second line","syllables":[],"endTimeMs":"0"}],
+		"providerDisplayName":"Musixmatch","language":"en"},"colors":{"background":-9211021}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.Synced || len(l.Lines) != 2 || l.Provider != "Musixmatch" || l.Language != "en" {
+		t.Fatalf("Bad lyrics %+v", l)
+	}
+	if l.Lines[1].Start != 4020*time.Millisecond || l.Lines[1].Words != "Second line" {
+		t.Errorf("Bad line %+v", l.Lines[1])
+	}
+
+	l, err = parseLyrics([]byte(`{"lyrics":{"syncType":"UNSYNCED","lines":[
+		{"startTimeMs":"0","words":"Unsynced line"}]}}`))
+	if err != nil || l.Synced || len(l.Lines) != 1 || l.Lines[0].Start != 0 {
+		t.Errorf("Bad unsynced lyrics %+v, %v", l, err)
+	}
+
+	if _, err := parseLyrics([]byte(`{"lyrics":{"lines":[]}}`)); err != ErrNoLyrics {
+		t.Errorf("Expected ErrNoLyrics, got %v", err)
+	}
+	if _, err := parseLyrics([]byte(`{"lyrics":{"syncType":"LINE_SYNCED","lines":[{"startTimeMs":"x"}]}}`)); err == nil {
+		t.Error("No error for an invalid start time")
+	}
+}
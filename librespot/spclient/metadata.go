@@ -0,0 +1,38 @@
+package spclient
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// Track returns the metadata of a track from the v4 metadata API, localized for the market of the access token
+func (c *Client) Track(gid []byte) (*Spotify.Track, error) {
+	track := &Spotify.Track{}
+	if err := c.getMetadata("track", gid, track); err != nil {
+		return nil, err
+	}
+	return track, nil
+}
+
+// Episode returns the metadata of a podcast episode from the v4 metadata API
+func (c *Client) Episode(gid []byte) (*Spotify.Episode, error) {
+	episode := &Spotify.Episode{}
+	if err := c.getMetadata("episode", gid, episode); err != nil {
+		return nil, err
+	}
+	return episode, nil
+}
+
+func (c *Client) getMetadata(kind string, gid []byte, msg proto.Message) error {
+	body, err := c.Get(fmt.Sprintf("/metadata/4/%s/%s?market=from_token", kind, hex.EncodeToString(gid)))
+	if err != nil {
+		return err
+	}
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return fmt.Errorf("invalid %s metadata: %v", kind, err)
+	}
+	return nil
+}
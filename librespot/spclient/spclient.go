@@ -0,0 +1,153 @@
+// Package spclient calls the spclient HTTPS service, which hosts the newer Spotify APIs not available through
+// mercury: the storage resolution of the CDN audio files, the v4 metadata, the Connect state, ...
+package spclient
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+)
+
+// DefaultHost is the spclient used when none could be resolved
+const DefaultHost = "spclient.wg.spotify.com:443"
+
+// DefaultScopes are the scopes of the access tokens authenticating the requests
+var DefaultScopes = []string{"playlist-read"}
+
+// Authorizer authenticates the requests with an access token, and a client token if available. *tokens.Provider
+// implements it.
+type Authorizer interface {
+	Authorize(req *http.Request, scopes ...string) error
+	// Invalidate drops the cached token of the scopes, after the spclient rejected it
+	Invalidate(scopes ...string)
+}
+
+// StatusError is returned when the spclient answers a request with an unexpected status
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("spclient: %s %s: %s", e.Method, e.Path, e.Status)
+}
+
+// Client sends authenticated requests to a spclient. It is safe for concurrent use once configured.
+type Client struct {
+	// Host is the spclient the requests are sent to, in the host:port form
+	Host string
+	// Scopes are the scopes of the access tokens sent, DefaultScopes if empty
+	Scopes []string
+	// Headers are sent with every request. New fills the ones identifying the client, App-Platform and
+	// Spotify-App-Version.
+	Headers http.Header
+
+	auth Authorizer
+	http *http.Client
+}
+
+// New creates a client sending its requests to host, or DefaultHost if empty, authenticated by auth. The requests
+// are sent with httpClient, http.DefaultClient if nil.
+func New(host string, auth Authorizer, httpClient *http.Client, appVersion string) *Client {
+	if host == "" {
+		host = DefaultHost
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	headers := http.Header{}
+	headers.Set("App-Platform", appPlatform())
+	if appVersion != "" {
+		headers.Set("Spotify-App-Version", appVersion)
+	}
+	return &Client{
+		Host:    host,
+		Headers: headers,
+		auth:    auth,
+		http:    httpClient,
+	}
+}
+
+// appPlatform returns the App-Platform header of the operating system
+func appPlatform() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "OSX"
+	case "windows":
+		return "Win32"
+	default:
+		return "Linux"
+	}
+}
+
+// Sign sets the headers of the client and the authentication headers of a request, e.g. for the requests built by
+// the caller to the APIs without a wrapper
+func (c *Client) Sign(req *http.Request) error {
+	for name, values := range c.Headers {
+		req.Header[name] = append([]string{}, values...)
+	}
+	if err := c.auth.Authorize(req, c.scopes()...); err != nil {
+		return fmt.Errorf("failed to get a token: %v", err)
+	}
+	return nil
+}
+
+func (c *Client) scopes() []string {
+	if len(c.Scopes) == 0 {
+		return DefaultScopes
+	}
+	return c.Scopes
+}
+
+// Request sends a request to path, e.g. "/metadata/4/track/<hex gid>", and returns the body of the response. The
+// body is sent with the content type if not nil. If the access token is rejected, a new one is requested and the
+// request is sent again once. A StatusError is returned for the statuses other than 2xx.
+func (c *Client) Request(method, path string, body []byte, contentType string) ([]byte, error) {
+	header := http.Header{}
+	if body != nil && contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return c.RequestWithHeader(method, path, body, header)
+}
+
+// RequestWithHeader sends a request like Request, with additional headers
+func (c *Client) RequestWithHeader(method, path string, body []byte, header http.Header) ([]byte, error) {
+	res, err := c.send(method, path, body, header)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		res.Body.Close()
+		c.auth.Invalidate(c.scopes()...)
+		res, err = c.send(method, path, body, header)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, &StatusError{Method: method, Path: path, StatusCode: res.StatusCode, Status: res.Status}
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// Get sends a GET request to path, and returns the body of the response
+func (c *Client) Get(path string) ([]byte, error) {
+	return c.Request("GET", path, nil, "")
+}
+
+func (c *Client) send(method, path string, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, "https://"+c.Host+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = append([]string{}, values...)
+	}
+	if err := c.Sign(req); err != nil {
+		return nil, err
+	}
+	return c.http.Do(req)
+}
@@ -0,0 +1,73 @@
+package spclient
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// fakeAuthorizer hands out the token "token-<n>", n being incremented by Invalidate
+type fakeAuthorizer struct {
+	lock       sync.Mutex
+	generation int
+}
+
+func (f *fakeAuthorizer) Authorize(req *http.Request, scopes ...string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	req.Header.Set("Authorization", "Bearer token-"+strconv.Itoa(f.generation))
+	req.Header.Set("client-token", "client")
+	return nil
+}
+
+func (f *fakeAuthorizer) Invalidate(scopes ...string) {
+	f.lock.Lock()
+	f.generation++
+	f.lock.Unlock()
+}
+
+func TestTrack(t *testing.T) {
+	gid := []byte{0x01, 0x02, 0x03}
+	name := "Song"
+	var requests int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			// The first token is rejected
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("client-token") != "client" || r.Header.Get("Spotify-App-Version") != "1.0" ||
+			r.Header.Get("App-Platform") == "" {
+			t.Errorf("missing headers in %v", r.Header)
+		}
+		if r.URL.Path != "/metadata/4/track/"+hex.EncodeToString(gid) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := proto.Marshal(&Spotify.Track{Gid: gid, Name: proto.String(name)})
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := New(strings.TrimPrefix(server.URL, "https://"), &fakeAuthorizer{}, server.Client(), "1.0")
+	track, err := client.Track(gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.GetName() != name || requests != 2 {
+		t.Errorf("got track %q after %d requests, want %q after 2", track.GetName(), requests, name)
+	}
+
+	_, err = client.Episode(gid)
+	if statusErr, ok := err.(*StatusError); !ok || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("got %v, want a StatusError with 404", err)
+	}
+}
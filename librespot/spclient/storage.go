@@ -0,0 +1,39 @@
+package spclient
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// StorageResolve returns the CDN URLs of an audio file, in the order they should be tried
+func (c *Client) StorageResolve(fileId []byte) ([]string, error) {
+	body, err := c.Get("/storage-resolve/files/audio/interactive/" + hex.EncodeToString(fileId))
+	if err != nil {
+		return nil, err
+	}
+	return parseStorageResolve(body)
+}
+
+// parseStorageResolve returns the CDN URLs of a StorageResolveResponse
+func parseStorageResolve(body []byte) ([]string, error) {
+	res := &Spotify.StorageResolveResponse{}
+	if err := proto.Unmarshal(body, res); err != nil {
+		return nil, err
+	}
+
+	switch res.GetResult() {
+	case Spotify.StorageResolveResponse_CDN:
+		if len(res.GetCdnurl()) == 0 {
+			return nil, errors.New("storage-resolve: no CDN URL")
+		}
+		return res.GetCdnurl(), nil
+	case Spotify.StorageResolveResponse_RESTRICTED:
+		return nil, errors.New("storage-resolve: file restricted")
+	default:
+		return nil, fmt.Errorf("storage-resolve: unsupported result %d", res.GetResult())
+	}
+}
@@ -0,0 +1,36 @@
+package spclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+func storageResponse(result Spotify.StorageResolveResponse_Result, urls ...string) []byte {
+	b, _ := proto.Marshal(&Spotify.StorageResolveResponse{
+		Result: result.Enum(),
+		Cdnurl: urls,
+		Fileid: []byte{1, 2, 3},
+	})
+	return b
+}
+
+func TestParseStorageResolve(t *testing.T) {
+	urls := []string{"https://audio-a.example/file", "https://audio-b.example/file"}
+	got, err := parseStorageResolve(storageResponse(Spotify.StorageResolveResponse_CDN, urls...))
+	if err != nil || !reflect.DeepEqual(got, urls) {
+		t.Errorf("got %v (%v), want %v", got, err, urls)
+	}
+
+	if _, err := parseStorageResolve(storageResponse(Spotify.StorageResolveResponse_RESTRICTED)); err == nil {
+		t.Error("no error for a restricted file")
+	}
+	if _, err := parseStorageResolve(storageResponse(Spotify.StorageResolveResponse_CDN)); err == nil {
+		t.Error("no error without URL")
+	}
+	if _, err := parseStorageResolve([]byte{0x08}); err == nil {
+		t.Error("no error for a truncated response")
+	}
+}
@@ -0,0 +1,219 @@
+package spirc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/dealer"
+	"github.com/golang/protobuf/proto"
+)
+
+const dealerClusterUri = "hm://connect-state/v1/cluster"
+
+// ClusterDevice is a device of the Connect cluster of the user, as reported by the connect-state service
+type ClusterDevice struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// Type is the Connect device type, e.g. 1 for a computer or 4 for a speaker
+	Type int64 `json:"type"`
+	// Volume is from 0 to 65535
+	Volume          uint32 `json:"volume"`
+	CanPlay         bool   `json:"can_play"`
+	Active          bool   `json:"active"`
+	PrivateSession  bool   `json:"private_session,omitempty"`
+	SoftwareVersion string `json:"software_version,omitempty"`
+	ClientId        string `json:"client_id,omitempty"`
+	Brand           string `json:"brand,omitempty"`
+	Model           string `json:"model,omitempty"`
+}
+
+// ClusterEventType is the kind of change of a ClusterEvent
+type ClusterEventType string
+
+const (
+	ClusterDeviceAdded   ClusterEventType = "device_added"
+	ClusterDeviceRemoved ClusterEventType = "device_removed"
+	// ClusterDeviceChanged is sent when the name, volume or capabilities of a device change
+	ClusterDeviceChanged ClusterEventType = "device_changed"
+	// ClusterActiveChanged is sent when another device becomes active, Device is empty if none is
+	ClusterActiveChanged ClusterEventType = "active_changed"
+)
+
+// ClusterEvent is a change of the Connect cluster
+type ClusterEvent struct {
+	Type   ClusterEventType
+	Device ClusterDevice
+	// Previous is the device before the change, for ClusterDeviceChanged and ClusterActiveChanged
+	Previous ClusterDevice
+}
+
+// ClusterListener is called for every change of the cluster
+type ClusterListener func(event ClusterEvent)
+
+// Cluster is the live state of the Connect devices of the user: which devices exist, which one is playing and their
+// volumes. It is updated from the cluster notifications of the connect-state service, received through the dealer.
+type Cluster struct {
+	lock     sync.Mutex
+	devices  map[string]ClusterDevice
+	activeId string
+	updated  time.Time
+
+	listenersLock sync.Mutex
+	listeners     []ClusterListener
+}
+
+// NewCluster creates an empty cluster, filled by the notifications once HandleDealer is called
+func NewCluster() *Cluster {
+	return &Cluster{devices: map[string]ClusterDevice{}}
+}
+
+// HandleDealer updates the cluster with the notifications received through the dealer
+func (c *Cluster) HandleDealer(d *dealer.Dealer) {
+	d.Handle(dealerClusterUri, func(msg dealer.Message) {
+		for _, payload := range msg.Payloads {
+			if err := c.Update(payload); err != nil {
+				d.Logger().Printf("Bad cluster update: %v\n", err)
+			}
+		}
+	})
+}
+
+// OnChange registers a listener called for every change of the cluster
+func (c *Cluster) OnChange(listener ClusterListener) {
+	c.listenersLock.Lock()
+	c.listeners = append(c.listeners, listener)
+	c.listenersLock.Unlock()
+}
+
+func (c *Cluster) emit(events []ClusterEvent) {
+	c.listenersLock.Lock()
+	listeners := append([]ClusterListener{}, c.listeners...)
+	c.listenersLock.Unlock()
+
+	for _, event := range events {
+		for _, l := range listeners {
+			l(event)
+		}
+	}
+}
+
+// Devices returns the devices of the cluster, sorted by name
+func (c *Cluster) Devices() []ClusterDevice {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	devices := make([]ClusterDevice, 0, len(c.devices))
+	for _, device := range c.devices {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Name != devices[j].Name {
+			return devices[i].Name < devices[j].Name
+		}
+		return devices[i].Id < devices[j].Id
+	})
+	return devices
+}
+
+// Device returns the device with the specified id
+func (c *Cluster) Device(id string) (ClusterDevice, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	device, ok := c.devices[id]
+	return device, ok
+}
+
+// ActiveDevice returns the device currently playing, if any
+func (c *Cluster) ActiveDevice() (ClusterDevice, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	device, ok := c.devices[c.activeId]
+	return device, ok
+}
+
+// Updated returns the time of the last update of the cluster, the zero time if it was never received
+func (c *Cluster) Updated() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.updated
+}
+
+// Update replaces the state of the cluster with a ClusterUpdate protobuf message, and notifies the changes
+func (c *Cluster) Update(payload []byte) error {
+	update := &Spotify.ClusterUpdate{}
+	if err := proto.Unmarshal(payload, update); err != nil {
+		return err
+	}
+	c.update(update)
+	return nil
+}
+
+// update replaces the state of the cluster with a ClusterUpdate, and notifies the changes
+func (c *Cluster) update(update *Spotify.ClusterUpdate) {
+	devices := map[string]ClusterDevice{}
+	for id, info := range update.GetCluster().GetDevice() {
+		device := clusterDevice(id, info)
+		devices[device.Id] = device
+	}
+	activeId := update.GetCluster().GetActiveDeviceId()
+	if device, ok := devices[activeId]; ok {
+		device.Active = true
+		devices[activeId] = device
+	}
+
+	c.lock.Lock()
+	var events []ClusterEvent
+	for id, device := range devices {
+		previous, ok := c.devices[id]
+		if !ok {
+			events = append(events, ClusterEvent{Type: ClusterDeviceAdded, Device: device})
+			continue
+		}
+		// The activity is reported by ClusterActiveChanged
+		previous.Active = device.Active
+		if previous != device {
+			events = append(events, ClusterEvent{Type: ClusterDeviceChanged, Device: device, Previous: previous})
+		}
+	}
+	for id, previous := range c.devices {
+		if _, ok := devices[id]; !ok {
+			events = append(events, ClusterEvent{Type: ClusterDeviceRemoved, Device: previous})
+		}
+	}
+	if activeId != c.activeId {
+		events = append(events, ClusterEvent{
+			Type:     ClusterActiveChanged,
+			Device:   devices[activeId],
+			Previous: c.devices[c.activeId],
+		})
+	}
+
+	c.devices = devices
+	c.activeId = activeId
+	c.updated = time.Now()
+	c.lock.Unlock()
+
+	c.emit(events)
+}
+
+// clusterDevice returns the device of a DeviceInfo of the cluster, whose key is the device id
+func clusterDevice(id string, info *Spotify.DeviceInfo) ClusterDevice {
+	device := ClusterDevice{
+		Id:              info.GetDeviceId(),
+		Name:            info.GetName(),
+		Type:            int64(info.GetDeviceType()),
+		Volume:          info.GetVolume(),
+		CanPlay:         info.GetCanPlay(),
+		PrivateSession:  info.GetIsPrivateSession(),
+		SoftwareVersion: info.GetDeviceSoftwareVersion(),
+		ClientId:        info.GetClientId(),
+		Brand:           info.GetBrand(),
+		Model:           info.GetModel(),
+	}
+	if device.Id == "" {
+		device.Id = id
+	}
+	return device
+}
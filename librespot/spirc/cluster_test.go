@@ -0,0 +1,88 @@
+package spirc
+
+import (
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// clusterUpdate builds a ClusterUpdate message with the devices, each one given by its id, name and volume
+func clusterUpdate(activeId string, devices ...ClusterDevice) []byte {
+	data, _ := proto.Marshal(&Spotify.ClusterUpdate{
+		Cluster:      clusterMessage(activeId, devices...),
+		UpdateReason: Spotify.ClusterUpdateReason_DEVICE_STATE_CHANGED.Enum(),
+	})
+	return data
+}
+
+// clusterMessage builds the Cluster message of clusterUpdate
+func clusterMessage(activeId string, devices ...ClusterDevice) *Spotify.Cluster {
+	cluster := &Spotify.Cluster{
+		Timestamp:      proto.Int64(1600000000000),
+		ActiveDeviceId: proto.String(activeId),
+		Device:         map[string]*Spotify.DeviceInfo{},
+	}
+	for _, device := range devices {
+		cluster.Device[device.Id] = &Spotify.DeviceInfo{
+			CanPlay:    proto.Bool(true),
+			Volume:     proto.Uint32(device.Volume),
+			Name:       proto.String(device.Name),
+			DeviceType: proto.Int32(4),
+			DeviceId:   proto.String(device.Id),
+		}
+	}
+	return cluster
+}
+
+func TestClusterUpdate(t *testing.T) {
+	cluster := NewCluster()
+	var events []ClusterEvent
+	cluster.OnChange(func(event ClusterEvent) {
+		events = append(events, event)
+	})
+
+	err := cluster.Update(clusterUpdate("phone",
+		ClusterDevice{Id: "phone", Name: "Phone", Volume: 65535},
+		ClusterDevice{Id: "speaker", Name: "Speaker", Volume: 30000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 2 devices added and the active device", len(events))
+	}
+	active, ok := cluster.ActiveDevice()
+	if !ok || active.Id != "phone" || !active.Active || !active.CanPlay || active.Type != 4 {
+		t.Errorf("got active device %+v", active)
+	}
+	if devices := cluster.Devices(); len(devices) != 2 || devices[1].Name != "Speaker" || devices[1].Volume != 30000 {
+		t.Errorf("got devices %+v", devices)
+	}
+
+	// The speaker becomes active with another volume, and the phone leaves
+	events = nil
+	err = cluster.Update(clusterUpdate("speaker", ClusterDevice{Id: "speaker", Name: "Speaker", Volume: 40000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[ClusterEventType]ClusterEvent{}
+	for _, event := range events {
+		got[event.Type] = event
+	}
+	if len(events) != 3 || got[ClusterDeviceRemoved].Device.Id != "phone" ||
+		got[ClusterDeviceChanged].Previous.Volume != 30000 || got[ClusterDeviceChanged].Device.Volume != 40000 ||
+		got[ClusterActiveChanged].Previous.Id != "phone" || got[ClusterActiveChanged].Device.Id != "speaker" {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	// Unchanged state, no event
+	events = nil
+	cluster.Update(clusterUpdate("speaker", ClusterDevice{Id: "speaker", Name: "Speaker", Volume: 40000}))
+	if len(events) != 0 {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	if err := cluster.Update([]byte{0x0a, 0x05}); err == nil {
+		t.Error("no error for a truncated update")
+	}
+}
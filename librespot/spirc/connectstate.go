@@ -0,0 +1,179 @@
+package spirc
+
+import (
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/spclient"
+	"github.com/golang/protobuf/proto"
+)
+
+// activeUserMetadata is the key of the metadata of the DeviceInfo holding the display name of the active user
+const activeUserMetadata = "active_user_display_name"
+
+// ConnectionIdSource provides the id of the dealer connection the Connect commands are received through.
+// *dealer.Dealer implements it.
+type ConnectionIdSource interface {
+	ConnectionId() string
+	OnConnectionId(cb func(id string))
+}
+
+// connectState publishes the state of this session to the connect-state service
+type connectState struct {
+	connection ConnectionIdSource
+	client     *spclient.Client
+	messageId  uint32
+}
+
+// PublishState publishes the device info and the playback state of this session to the connect-state service, so
+// that it appears as a Connect device in the official Spotify apps, which then send their commands through the
+// dealer, see HandleDealer. The state is published on every connection of the dealer, and whenever it changes with
+// UpdateState and UpdateVolume, while the session is advertised.
+func (c *Controller) PublishState(d ConnectionIdSource, client *spclient.Client) {
+	c.localLock.Lock()
+	c.connectState = &connectState{connection: d, client: client}
+	c.localLock.Unlock()
+
+	d.OnConnectionId(func(id string) {
+		go func() {
+			if err := c.putState(Spotify.PutStateReason_NEW_DEVICE); err != nil {
+				c.logger().Printf("Unable to publish the Connect state: %v\n", err)
+			}
+		}()
+	})
+}
+
+// SetActiveUser sets the display name of the user logged in through this device, which the Spotify apps show along
+// with the name of the device, and publishes it if the state is published. An empty name removes it.
+func (c *Controller) SetActiveUser(displayName string) error {
+	c.localLock.Lock()
+	changed := c.activeUser != displayName
+	c.activeUser = displayName
+	c.localLock.Unlock()
+	if !changed {
+		return nil
+	}
+	return c.putState(Spotify.PutStateReason_NEW_DEVICE)
+}
+
+// putState publishes the state of this session, if it is advertised and the connection id is known
+func (c *Controller) putState(reason Spotify.PutStateReason) error {
+	c.localLock.Lock()
+	cs := c.connectState
+	if cs == nil || c.local == nil {
+		c.localLock.Unlock()
+		return nil
+	}
+	cs.messageId++
+	request := putStateRequest(c.session.DeviceId(), c.local, c.restrictions, reason, cs.messageId)
+	if c.activeUser != "" {
+		request.Device.DeviceInfo.MetadataMap = map[string]string{activeUserMetadata: c.activeUser}
+	}
+	c.localLock.Unlock()
+
+	connectionId := cs.connection.ConnectionId()
+	if connectionId == "" {
+		// Published once the dealer sends it
+		return nil
+	}
+	cluster, err := cs.client.PutConnectState(c.session.DeviceId(), connectionId, request)
+	if err != nil {
+		return err
+	}
+
+	// The response is the cluster, which the notifications wrap in a ClusterUpdate
+	c.cluster.update(&Spotify.ClusterUpdate{Cluster: cluster})
+	return nil
+}
+
+// deleteState removes this session from the devices of the connect-state service, if its state was published
+func (c *Controller) deleteState() error {
+	c.localLock.Lock()
+	cs := c.connectState
+	c.localLock.Unlock()
+	if cs == nil {
+		return nil
+	}
+
+	connectionId := cs.connection.ConnectionId()
+	if connectionId == "" {
+		return nil
+	}
+	return cs.client.DeleteConnectState(c.session.DeviceId(), connectionId)
+}
+
+// putStateRequest returns the PutStateRequest describing the local device
+func putStateRequest(deviceId string, l *localDevice, restrictions Restrictions, reason Spotify.PutStateReason,
+	messageId uint32) *Spotify.PutStateRequest {
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	req := &Spotify.PutStateRequest{
+		Device: &Spotify.Device{
+			DeviceInfo:  deviceInfo(deviceId, l, restrictions),
+			PlayerState: playerState(l.state, restrictions),
+		},
+		MemberType:          Spotify.MemberType_CONNECT_STATE.Enum(),
+		IsActive:            proto.Bool(l.active),
+		PutStateReason:      reason.Enum(),
+		MessageId:           proto.Uint32(messageId),
+		ClientSideTimestamp: proto.Uint64(now),
+	}
+	if l.active {
+		req.StartedPlayingAt = proto.Uint64(uint64(l.becameActive))
+		req.HasBeenPlayingForMs = proto.Uint64(now - uint64(l.becameActive))
+	}
+	return req
+}
+
+// deviceInfo returns the DeviceInfo of the local device, with its capabilities: volume control, the types of items
+// it plays, and the commands it accepts through the dealer
+func deviceInfo(deviceId string, l *localDevice, restrictions Restrictions) *Spotify.DeviceInfo {
+	capabilities := &Spotify.Capabilities{
+		CanBePlayer:             proto.Bool(true),
+		GaiaEqConnectId:         proto.Bool(true),
+		IsObservable:            proto.Bool(true),
+		VolumeSteps:             proto.Int32(64),
+		SupportedTypes:          []string{"audio/track", "audio/episode"},
+		CommandAcks:             proto.Bool(true),
+		IsControllable:          proto.Bool(true),
+		SupportsTransferCommand: proto.Bool(true),
+		SupportsCommandRequest:  proto.Bool(true),
+	}
+	if !restrictions.Allows(RemoteVolume) {
+		capabilities.VolumeSteps = proto.Int32(0)
+		capabilities.DisableVolume = proto.Bool(true)
+	}
+
+	return &Spotify.DeviceInfo{
+		CanPlay:               proto.Bool(true),
+		Volume:                proto.Uint32(l.volume),
+		Name:                  proto.String(l.name),
+		Capabilities:          capabilities,
+		DeviceSoftwareVersion: proto.String("librespot-golang"),
+		DeviceType:            proto.Int32(int32(l.deviceType)),
+		SpircVersion:          proto.String("3.2.6"),
+		DeviceId:              proto.String(deviceId),
+	}
+}
+
+// playerState returns the PlayerState of the Spirc playback state, with the restrictions of the commands
+func playerState(state *Spotify.State, restrictions Restrictions) *Spotify.PlayerState {
+	s := &Spotify.PlayerState{Restrictions: playerRestrictions(restrictions)}
+	if state == nil {
+		return s
+	}
+	s.Timestamp = proto.Int64(int64(state.GetPositionMeasuredAt()))
+	s.ContextUri = proto.String(state.GetContextUri())
+	if tracks := state.GetTrack(); int(state.GetPlayingTrackIndex()) < len(tracks) {
+		if id, err := trackRefId(tracks[state.GetPlayingTrackIndex()]); err == nil {
+			s.Track = &Spotify.ProvidedTrack{Uri: proto.String(id.Uri())}
+		}
+	}
+	s.PositionAsOfTimestamp = proto.Int64(int64(state.GetPositionMs()))
+
+	status := state.GetStatus()
+	s.IsPlaying = proto.Bool(status == Spotify.PlayStatus_kPlayStatusPlay ||
+		status == Spotify.PlayStatus_kPlayStatusPause || status == Spotify.PlayStatus_kPlayStatusLoading)
+	s.IsPaused = proto.Bool(status == Spotify.PlayStatus_kPlayStatusPause)
+	s.IsBuffering = proto.Bool(status == Spotify.PlayStatus_kPlayStatusLoading)
+	return s
+}
@@ -0,0 +1,103 @@
+package spirc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/spclient"
+	"github.com/golang/protobuf/proto"
+)
+
+type fakeConnection struct{}
+
+func (fakeConnection) ConnectionId() string              { return "conn-id" }
+func (fakeConnection) OnConnectionId(cb func(id string)) {}
+
+type fakeAuthorizer struct{}
+
+func (fakeAuthorizer) Authorize(req *http.Request, scopes ...string) error {
+	req.Header.Set("Authorization", "Bearer token")
+	return nil
+}
+
+func (fakeAuthorizer) Invalidate(scopes ...string) {}
+
+func TestPublishState(t *testing.T) {
+	requests := make(chan *http.Request, 2)
+	bodies := make(chan []byte, 2)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+
+		cluster, _ := proto.Marshal(clusterMessage("testDevice",
+			ClusterDevice{Id: "testDevice", Name: "Living Room", Volume: 65535}))
+		w.Write(cluster)
+	}))
+	defer server.Close()
+
+	controller, mercuryServer := setupControllerAndServer(t)
+	mercuryServer.reply(mercuryServer.getRequest(t))
+
+	client := spclient.New(strings.TrimPrefix(server.URL, "https://"), fakeAuthorizer{}, server.Client(), "1.0")
+	controller.PublishState(fakeConnection{}, client)
+
+	done := make(chan error, 1)
+	go func() { done <- controller.Advertise("Living Room", func(cmd Command) {}) }()
+	mercuryServer.reply(mercuryServer.getRequest(t))
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	req, body := <-requests, <-bodies
+	if req.Method != "PUT" || req.URL.Path != "/connect-state/v1/devices/testDevice" {
+		t.Errorf("Bad request %s %s", req.Method, req.URL.Path)
+	}
+	if req.Header.Get("X-Spotify-Connection-Id") != "conn-id" {
+		t.Errorf("Bad connection id %q", req.Header.Get("X-Spotify-Connection-Id"))
+	}
+	put := &Spotify.PutStateRequest{}
+	if err := proto.Unmarshal(body, put); err != nil {
+		t.Fatal(err)
+	}
+	if put.GetPutStateReason() != Spotify.PutStateReason_NEW_DEVICE {
+		t.Errorf("Bad reason %v", put.GetPutStateReason())
+	}
+	info := put.GetDevice().GetDeviceInfo()
+	if info.GetName() != "Living Room" {
+		t.Errorf("Bad device name %q", info.GetName())
+	}
+	if steps := info.GetCapabilities().GetVolumeSteps(); steps != 64 {
+		t.Errorf("Bad volume steps %d", steps)
+	}
+
+	// The cluster is updated with the response
+	if device, ok := controller.Cluster().ActiveDevice(); !ok || device.Name != "Living Room" {
+		t.Errorf("Cluster not updated: %v", device)
+	}
+
+	// The display name of the active user is published with the device info
+	if err := controller.SetActiveUser("Anna"); err != nil {
+		t.Fatal(err)
+	}
+	<-requests
+	if err := proto.Unmarshal(<-bodies, put); err != nil {
+		t.Fatal(err)
+	}
+	if name := put.GetDevice().GetDeviceInfo().GetMetadataMap()[activeUserMetadata]; name != "Anna" {
+		t.Errorf("Bad active user %q", name)
+	}
+
+	go func() { done <- controller.StopAdvertising() }()
+	mercuryServer.reply(mercuryServer.getRequest(t))
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if req := <-requests; req.Method != "DELETE" {
+		t.Errorf("Bad request %s", req.Method)
+	}
+}
@@ -5,21 +5,79 @@ import (
 	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
-	"github.com/fischerling/librespot-golang/librespot/core"
+	"github.com/fischerling/librespot-golang/librespot/discovery"
 	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"github.com/fischerling/librespot-golang/librespot/player"
 	"github.com/fischerling/librespot-golang/librespot/utils"
+	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Session is the part of a core.Session used by the controller
+type Session interface {
+	Mercury() *mercury.Client
+	Discovery() *discovery.Discovery
+	Username() string
+	DeviceId() string
+}
+
+// deviceTypes maps the device types reported to the Spotify apps to their Connect capability value
+var deviceTypes = map[string]int64{
+	"UNKNOWN":      0,
+	"COMPUTER":     1,
+	"TABLET":       2,
+	"SMARTPHONE":   3,
+	"SPEAKER":      4,
+	"TV":           5,
+	"AVR":          6,
+	"STB":          7,
+	"AUDIO_DONGLE": 8,
+	"GAME_CONSOLE": 9,
+	"CAST_AUDIO":   10,
+	"CAST_VIDEO":   11,
+	"AUTOMOBILE":   12,
+	"SMARTWATCH":   13,
+	"CHROMEBOOK":   14,
+}
+
+// remoteSilence is how long the remote notifications can stay silent before their subscription is assumed lost.
+// The hello sent after resubscribing is broadcast back to this device, so that the silence is only resolved once the
+// subscription works again.
+const remoteSilence = 10 * time.Minute
+
 // Controller is a structure for Spotify Connect remote control interface.
 type Controller struct {
-	session     *core.Session
+	session     Session
 	seqNr       uint32
 	devices     map[string]ConnectDevice
 	devicesLock sync.RWMutex
 	updateChan  chan Spotify.Frame
 
+	// local is the state of this session as a Connect device, nil if not advertised
+	local     *localDevice
+	localLock sync.Mutex
+	// contentFilter filters the tracks of the load commands, it is protected by localLock
+	contentFilter ContentFilter
+	// connectState publishes the local device to the connect-state service, it is protected by localLock
+	connectState *connectState
+	// transferStart and transferConfirm set how the playback starts once transferred, and transfers counts the load
+	// commands, so that a confirmation doesn't start an older one. They are protected by localLock.
+	transferStart   TransferStart
+	transferConfirm TransferConfirmFunc
+	transfers       uint64
+	// restrictions are the remote commands this session disallows, they are protected by localLock
+	restrictions Restrictions
+	// log receives the messages of the controller, it is protected by localLock
+	log *log.Logger
+	// activeUser is the display name of the user logged in through the discovery of the session, published to the
+	// connect-state service. It is protected by localLock.
+	activeUser string
+
+	cluster *Cluster
+
 	SavedCredentials []byte
 }
 
@@ -31,20 +89,85 @@ type ConnectDevice struct {
 	Ident  string
 	Url    string
 	Volume int
+	// Active is set if the device is the one currently playing
+	Active bool
+	// State is the last playback state notified by the device
+	State *Spotify.State
+}
+
+// Command is a command sent to this session by another Connect device
+type Command struct {
+	Type Spotify.MessageType
+	// From is the ident of the device which sent the command
+	From string
+	// Position is the position to seek to, in milliseconds, for kMessageTypeSeek
+	Position uint32
+	// Volume is the new volume, from 0 to 65535, for kMessageTypeVolume
+	Volume uint32
+	// State is the playback state to load, for kMessageTypeLoad
+	State *Spotify.State
+}
+
+// CommandHandler is called for every command sent to this session, once advertised as a Connect device
+type CommandHandler func(cmd Command)
+
+type localDevice struct {
+	name          string
+	deviceType    int64
+	handler       CommandHandler
+	active        bool
+	becameActive  int64
+	volume        uint32
+	state         *Spotify.State
+	stateUpdateId int64
 }
 
 // CreateController creates a Spirc controller. Registers listeners for Spotify connect device
-// updates, and opens connection for sending commands
-func CreateController(userSession *core.Session, credentials []byte) *Controller {
+// updates, and opens connection for sending commands. The messages are logged with the logger of the session if it
+// has one, like a core.Session. The active user of the discovery of the session is published with the state of the
+// device, see SetActiveUser.
+func CreateController(userSession Session, credentials []byte) *Controller {
 	controller := &Controller{
 		devices:          make(map[string]ConnectDevice),
 		session:          userSession,
 		SavedCredentials: credentials,
+		cluster:          NewCluster(),
+	}
+	if s, ok := userSession.(interface{ Logger() *log.Logger }); ok {
+		controller.log = s.Logger()
+	}
+	if d := userSession.Discovery(); d != nil {
+		controller.activeUser = d.ActiveUser().DisplayName
+		d.OnEvent(func(event discovery.Event) {
+			if event.Type != discovery.EventActiveUser {
+				return
+			}
+			if err := controller.SetActiveUser(event.User.DisplayName); err != nil {
+				controller.logger().Printf("Unable to publish the active user: %v\n", err)
+			}
+		})
 	}
 	controller.subscribe()
 	return controller
 }
 
+// SetLogger sets the logger receiving the messages of the controller, the standard logger being used if nil
+func (c *Controller) SetLogger(logger *log.Logger) {
+	c.localLock.Lock()
+	c.log = logger
+	c.localLock.Unlock()
+}
+
+// logger returns the logger of the controller, see SetLogger
+func (c *Controller) logger() *log.Logger {
+	c.localLock.Lock()
+	defer c.localLock.Unlock()
+	if c.log == nil {
+		return log.Default()
+	}
+	return c.log
+}
+
 // Load comma seperated tracks
 func (c *Controller) LoadTrackIds(ident string, ids string) error {
 	return c.LoadTrack(ident, strings.Split(ids, ","))
@@ -53,14 +176,22 @@ func (c *Controller) LoadTrackIds(ident string, ids string) error {
 // Load given list of tracks on spotify connect device with given
 // ident.  Gids are formated base62 spotify ids.
 func (c *Controller) LoadTrack(ident string, gids []string) error {
-	c.seqNr += 1
+	return c.LoadTrackWithOrigin(ident, gids, player.PlayOrigin{})
+}
 
+// LoadTrackWithOrigin is similar to LoadTrack, but also sends where the playback comes from (context URI, referrer,
+// feature identifier, ...) so that the plays are attributed correctly.
+func (c *Controller) LoadTrackWithOrigin(ident string, gids []string, origin player.PlayOrigin) error {
 	tracks := make([]*Spotify.TrackRef, 0, len(gids))
 	for _, g := range gids {
-		tracks = append(tracks, &Spotify.TrackRef{
+		track := &Spotify.TrackRef{
 			Gid:    utils.Convert62(g),
 			Queued: proto.Bool(false),
-		})
+		}
+		if origin.ContextUri != "" {
+			track.Context = proto.String(origin.ContextUri)
+		}
+		tracks = append(tracks, track)
 	}
 
 	state := &Spotify.State{
@@ -69,24 +200,30 @@ func (c *Controller) LoadTrack(ident string, gids []string) error {
 		Status:            Spotify.PlayStatus_kPlayStatusStop.Enum(),
 		PlayingTrackIndex: proto.Uint32(0),
 	}
+	if origin.ContextUri != "" {
+		state.ContextUri = proto.String(origin.ContextUri)
+	}
 
-	frame := &Spotify.Frame{
-		Version:         proto.Uint32(1),
-		Ident:           proto.String(c.session.DeviceId()),
-		ProtocolVersion: proto.String("2.0.0"),
-		SeqNr:           proto.Uint32(c.seqNr),
-		Typ:             Spotify.MessageType_kMessageTypeLoad.Enum(),
-		Recipient:       []string{ident},
-		State:           state,
+	frame := c.newFrame(Spotify.MessageType_kMessageTypeLoad, []string{ident})
+	frame.State = state
+	frame.DeviceState = &Spotify.DeviceState{
+		Metadata: origin.Metadata(),
 	}
 
 	return c.sendFrame(frame)
 }
 
 // Sends a 'hello' command to all Spotify Connect devices. Active devices will respond with a 'notify' updating
-// their state.
+// their state. If this session is advertised as a Connect device, its state is sent along.
 func (c *Controller) SendHello() error {
-	return c.sendCmd(nil, Spotify.MessageType_kMessageTypeHello)
+	frame := c.newFrame(Spotify.MessageType_kMessageTypeHello, nil)
+	c.localLock.Lock()
+	if c.local != nil {
+		frame.DeviceState = c.local.deviceState(c.restrictions)
+	}
+	c.localLock.Unlock()
+
+	return c.sendFrame(frame)
 }
 
 // Sends a 'play' command to spotify connect device with given identity (recipient param).
@@ -99,26 +236,118 @@ func (c *Controller) SendPause(recipient string) error {
 	return c.sendCmd([]string{recipient}, Spotify.MessageType_kMessageTypePause)
 }
 
+// Sends a 'next' command to Spotify Connect device with given identity (recipient param).
+func (c *Controller) SendNext(recipient string) error {
+	return c.sendCmd([]string{recipient}, Spotify.MessageType_kMessageTypeNext)
+}
+
+// Sends a 'prev' command to Spotify Connect device with given identity (recipient param).
+func (c *Controller) SendPrev(recipient string) error {
+	return c.sendCmd([]string{recipient}, Spotify.MessageType_kMessageTypePrev)
+}
+
+// Sends a 'seek' command to Spotify Connect device with given identity (recipient param). The position is in
+// milliseconds from the start of the track.
+func (c *Controller) SendSeek(recipient string, positionMs uint32) error {
+	frame := c.newFrame(Spotify.MessageType_kMessageTypeSeek, []string{recipient})
+	frame.Position = proto.Uint32(positionMs)
+
+	return c.sendFrame(frame)
+}
+
+// Sends a 'volume' command to Spotify Connect device with given identity (recipient param). The volume ranges from
+// 0 to 65535.
 func (c *Controller) SendVolume(recipient string, volume int) error {
-	c.seqNr += 1
-	messageType := Spotify.MessageType_kMessageTypeVolume
-	frame := &Spotify.Frame{
-		Version:         proto.Uint32(1),
-		Ident:           proto.String(c.session.DeviceId()),
-		ProtocolVersion: proto.String("2.0.0"),
-		SeqNr:           proto.Uint32(c.seqNr),
-		Typ:             &messageType,
-		Recipient:       []string{recipient},
-		Volume:          proto.Uint32(uint32(volume)),
-	}
+	frame := c.newFrame(Spotify.MessageType_kMessageTypeVolume, []string{recipient})
+	frame.Volume = proto.Uint32(uint32(volume))
 
 	return c.sendFrame(frame)
 }
 
+// Advertise makes this session visible to the other Connect devices of the user under the given name. The commands
+// they send to it are passed to the handler, which is expected to report the resulting playback state with
+// UpdateState.
+func (c *Controller) Advertise(name string, handler CommandHandler) error {
+	c.localLock.Lock()
+	c.local = &localDevice{
+		name:       name,
+		deviceType: sessionDeviceType(c.session),
+		handler:    handler,
+		volume:     0xffff,
+	}
+	c.localLock.Unlock()
+
+	err := c.SendHello()
+	if putErr := c.putState(Spotify.PutStateReason_NEW_DEVICE); err == nil {
+		err = putErr
+	}
+	return err
+}
+
+// StopAdvertising removes this session from the Connect devices of the user
+func (c *Controller) StopAdvertising() error {
+	c.localLock.Lock()
+	advertised := c.local != nil
+	c.local = nil
+	c.localLock.Unlock()
+
+	if !advertised {
+		return nil
+	}
+	err := c.sendCmd(nil, Spotify.MessageType_kMessageTypeGoodbye)
+	if deleteErr := c.deleteState(); err == nil {
+		err = deleteErr
+	}
+	return err
+}
+
+// UpdateState notifies the other Connect devices of the playback state of this session. Active must be set while
+// this session is the one playing.
+func (c *Controller) UpdateState(active bool, state *Spotify.State) error {
+	c.localLock.Lock()
+	if c.local == nil {
+		c.localLock.Unlock()
+		return errors.New("session is not advertised as a Connect device")
+	}
+	if active && !c.local.active {
+		c.local.becameActive = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+	c.local.active = active
+	c.local.state = state
+	c.localLock.Unlock()
+
+	err := c.notify(nil)
+	if putErr := c.putState(Spotify.PutStateReason_PLAYER_STATE_CHANGED); err == nil {
+		err = putErr
+	}
+	return err
+}
+
+// UpdateVolume notifies the other Connect devices of the volume of this session, from 0 to 65535
+func (c *Controller) UpdateVolume(volume uint32) error {
+	c.localLock.Lock()
+	if c.local == nil {
+		c.localLock.Unlock()
+		return errors.New("session is not advertised as a Connect device")
+	}
+	c.local.volume = volume
+	c.localLock.Unlock()
+
+	err := c.notify(nil)
+	if putErr := c.putState(Spotify.PutStateReason_VOLUME_CHANGED); err == nil {
+		err = putErr
+	}
+	return err
+}
+
 // Connect to Spotify Connect device at address (local network path). Uses credentials from saved blob to authenticate
 // on the device automagically.
-func (c *Controller) ConnectToDevice(address string) {
-	c.session.Discovery().ConnectToDevice(address)
+func (c *Controller) ConnectToDevice(address string) error {
+	discovery := c.session.Discovery()
+	if discovery == nil {
+		return errors.New("no discovery service available to connect to the device")
+	}
+	return discovery.ConnectToDevice(address)
 }
 
 // Lists devices on local network advertising spotify connect
@@ -157,7 +386,7 @@ func (c *Controller) ListDevices() []ConnectDevice {
 func (c *Controller) sendFrame(frame *Spotify.Frame) error {
 	frameData, err := proto.Marshal(frame)
 	if err != nil {
-		return fmt.Errorf("could not Marshal spirc Request frame: ", err)
+		return fmt.Errorf("could not Marshal spirc Request frame: %v", err)
 	}
 
 	payload := make([][]byte, 1)
@@ -182,71 +411,193 @@ func (c *Controller) sendFrame(frame *Spotify.Frame) error {
 }
 
 func (c *Controller) sendCmd(recipient []string, messageType Spotify.MessageType) error {
-	c.seqNr += 1
-	frame := &Spotify.Frame{
+	return c.sendFrame(c.newFrame(messageType, recipient))
+}
+
+// newFrame creates a frame sent from this session, with the next sequence number
+func (c *Controller) newFrame(messageType Spotify.MessageType, recipient []string) *Spotify.Frame {
+	return &Spotify.Frame{
 		Version:         proto.Uint32(1),
 		Ident:           proto.String(c.session.DeviceId()),
 		ProtocolVersion: proto.String("2.0.0"),
-		SeqNr:           proto.Uint32(c.seqNr),
-		Typ:             &messageType,
+		SeqNr:           proto.Uint32(atomic.AddUint32(&c.seqNr, 1)),
+		Typ:             messageType.Enum(),
 		Recipient:       recipient,
 	}
+}
+
+// notify sends the state of this session to the recipients, or to all devices if nil
+func (c *Controller) notify(recipient []string) error {
+	frame := c.newFrame(Spotify.MessageType_kMessageTypeNotify, recipient)
+
+	c.localLock.Lock()
+	if c.local == nil {
+		c.localLock.Unlock()
+		return nil
+	}
+	c.local.stateUpdateId++
+	frame.DeviceState = c.local.deviceState(c.restrictions)
+	frame.State = c.local.state
+	frame.StateUpdateId = proto.Int64(c.local.stateUpdateId)
+	c.localLock.Unlock()
 
 	return c.sendFrame(frame)
 }
 
+// sessionDeviceType returns the Connect device type of the session, defaulting to a computer
+func sessionDeviceType(session Session) int64 {
+	if s, ok := session.(interface{ DeviceType() string }); ok {
+		if typ, ok := deviceTypes[s.DeviceType()]; ok && typ != 0 {
+			return typ
+		}
+	}
+	return deviceTypes["COMPUTER"]
+}
+
+// deviceState returns the Spirc state of the local device. The volume steps are 0 if the volume commands are
+// disallowed.
+func (l *localDevice) deviceState(restrictions Restrictions) *Spotify.DeviceState {
+	volumeSteps := int64(64)
+	if !restrictions.Allows(RemoteVolume) {
+		volumeSteps = 0
+	}
+	state := &Spotify.DeviceState{
+		SwVersion: proto.String("librespot-golang"),
+		IsActive:  proto.Bool(l.active),
+		CanPlay:   proto.Bool(true),
+		Volume:    proto.Uint32(l.volume),
+		Name:      proto.String(l.name),
+		Capabilities: []*Spotify.Capability{
+			{Typ: Spotify.CapabilityType_kCanBePlayer.Enum(), IntValue: []int64{1}},
+			{Typ: Spotify.CapabilityType_kDeviceType.Enum(), IntValue: []int64{l.deviceType}},
+			{Typ: Spotify.CapabilityType_kGaiaEqConnectId.Enum(), IntValue: []int64{1}},
+			{Typ: Spotify.CapabilityType_kSupportsLogout.Enum(), IntValue: []int64{0}},
+			{Typ: Spotify.CapabilityType_kIsObservable.Enum(), IntValue: []int64{1}},
+			{Typ: Spotify.CapabilityType_kVolumeSteps.Enum(), IntValue: []int64{volumeSteps}},
+			{Typ: Spotify.CapabilityType_kSupportedContexts.Enum(),
+				StringValue: []string{"album", "playlist", "search", "inbox", "toplist", "starred",
+					"publishedstarred", "track_set"}},
+			{Typ: Spotify.CapabilityType_kSupportedTypes.Enum(),
+				StringValue: []string{"audio/local", "audio/track", "local", "track"}},
+		},
+	}
+	if l.active {
+		state.BecameActiveAt = proto.Int64(l.becameActive)
+	}
+	return state
+}
+
 func (c *Controller) subscribe() {
 	ch := make(chan mercury.Response)
-	c.session.Mercury().Subscribe(fmt.Sprintf("hm://remote/user/%s/", c.session.Username()), ch, func(_ mercury.Response) {
+	uri := fmt.Sprintf("hm://remote/user/%s/", c.session.Username())
+	m := c.session.Mercury()
+	m.OnSilence(func(silent string, _ time.Duration) {
+		if silent == uri {
+			go c.SendHello()
+		}
+	})
+	m.Subscribe(uri, ch, func(_ mercury.Response) {
 		go c.run(ch)
 		go c.SendHello()
 	})
+	m.ExpectEvents(uri, remoteSilence)
 }
 
 func (c *Controller) run(ch chan mercury.Response) {
 	for {
 		response := <-ch
+		if len(response.Payload) == 0 {
+			continue
+		}
 
 		frame := &Spotify.Frame{}
 		err := proto.Unmarshal(response.Payload[0], frame)
 		if err != nil {
-			fmt.Println("error getting packet")
+			c.logger().Println("error getting packet")
 			continue
 		}
 
-		if frame.GetTyp() == Spotify.MessageType_kMessageTypeNotify ||
-			(frame.GetTyp() == Spotify.MessageType_kMessageTypeHello && frame.DeviceState.GetName() != "") {
-			c.devicesLock.Lock()
-			c.devices[*frame.Ident] = ConnectDevice{
-				Name:   frame.DeviceState.GetName(),
-				Ident:  *frame.Ident,
-				Volume: int(frame.DeviceState.GetVolume()),
-			}
-			c.devicesLock.Unlock()
-		} else if frame.GetTyp() == Spotify.MessageType_kMessageTypeGoodbye {
-			c.devicesLock.Lock()
-			delete(c.devices, *frame.Ident)
-			c.devicesLock.Unlock()
+		c.handleFrame(frame)
+	}
+}
+
+func (c *Controller) handleFrame(frame *Spotify.Frame) {
+	ident := frame.GetIdent()
+	if ident == c.session.DeviceId() {
+		// Our own frames are broadcast back to us
+		return
+	}
+
+	if frame.GetTyp() == Spotify.MessageType_kMessageTypeNotify ||
+		(frame.GetTyp() == Spotify.MessageType_kMessageTypeHello && frame.DeviceState.GetName() != "") {
+		c.devicesLock.Lock()
+		c.devices[ident] = ConnectDevice{
+			Name:   frame.DeviceState.GetName(),
+			Ident:  ident,
+			Volume: int(frame.DeviceState.GetVolume()),
+			Active: frame.DeviceState.GetIsActive(),
+			State:  frame.State,
 		}
+		c.devicesLock.Unlock()
+	} else if frame.GetTyp() == Spotify.MessageType_kMessageTypeGoodbye {
+		c.devicesLock.Lock()
+		delete(c.devices, ident)
+		c.devicesLock.Unlock()
+	}
 
-		if c.updateChan != nil {
-			select {
-			case c.updateChan <- *frame:
-				fmt.Println("sent update")
-			default:
-				fmt.Println("dropped update")
-			}
+	if frame.GetTyp() == Spotify.MessageType_kMessageTypeHello {
+		// New devices expect the others to introduce themselves
+		if err := c.notify([]string{ident}); err != nil {
+			c.logger().Println("failed to notify state:", err)
 		}
+	} else if c.isRecipient(frame) {
+		c.handleCommand(frame)
+	}
 
-		fmt.Printf("%v %v %v %v %v %v \n",
-			frame.Typ,
-			frame.DeviceState.GetName(),
-			*frame.Ident,
-			*frame.SeqNr,
-			frame.GetStateUpdateId(),
-			frame.Recipient,
-		)
+	if c.updateChan != nil {
+		select {
+		case c.updateChan <- *frame:
+		default:
+			c.logger().Println("dropped update")
+		}
+	}
+}
 
+// isRecipient returns true if the frame is addressed to this session
+func (c *Controller) isRecipient(frame *Spotify.Frame) bool {
+	for _, r := range frame.GetRecipient() {
+		if r == c.session.DeviceId() {
+			return true
+		}
 	}
+	return false
+}
 
+func (c *Controller) handleCommand(frame *Spotify.Frame) {
+	switch frame.GetTyp() {
+	case Spotify.MessageType_kMessageTypeLoad, Spotify.MessageType_kMessageTypePlay,
+		Spotify.MessageType_kMessageTypePause, Spotify.MessageType_kMessageTypePlayPause,
+		Spotify.MessageType_kMessageTypeSeek, Spotify.MessageType_kMessageTypePrev,
+		Spotify.MessageType_kMessageTypeNext, Spotify.MessageType_kMessageTypeVolume,
+		Spotify.MessageType_kMessageTypeVolumeUp, Spotify.MessageType_kMessageTypeVolumeDown,
+		Spotify.MessageType_kMessageTypeShuffle, Spotify.MessageType_kMessageTypeRepeat:
+		cmd := Command{
+			Type:     frame.GetTyp(),
+			From:     frame.GetIdent(),
+			Position: frame.GetPosition(),
+			Volume:   frame.GetVolume(),
+			State:    frame.GetState(),
+		}
+		if !c.allows(cmd) {
+			if err := c.reject(cmd); err != nil {
+				c.logger().Println("failed to notify the rejected command:", err)
+			}
+			return
+		}
+		if frame.GetTyp() == Spotify.MessageType_kMessageTypeLoad {
+			c.load(cmd)
+		} else {
+			c.dispatchCommand(cmd)
+		}
+	}
 }
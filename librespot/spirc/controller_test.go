@@ -2,88 +2,597 @@ package spirc
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"github.com/golang/protobuf/proto"
 	"github.com/fischerling/librespot-golang/Spotify"
 	"github.com/fischerling/librespot-golang/librespot/connection"
+	"github.com/fischerling/librespot-golang/librespot/dealer"
+	"github.com/fischerling/librespot-golang/librespot/discovery"
+	"github.com/fischerling/librespot-golang/librespot/ids"
 	"github.com/fischerling/librespot-golang/librespot/mercury"
+	"log"
+	"strings"
 	"testing"
+	"time"
 )
 
+type shanPacket struct {
+	cmd connection.PacketType
+	buf []byte
+}
+
+type fakeStream struct {
+	sendPackets chan shanPacket
+}
+
+func (f *fakeStream) SendPacket(cmd connection.PacketType, data []byte) (err error) {
+	f.sendPackets <- shanPacket{cmd: cmd, buf: data}
+	return nil
+}
+
+func (f *fakeStream) RecvPacket() (cmd connection.PacketType, buf []byte, err error) {
+	select {}
+}
+
+type fakeSession struct {
+	mercury *mercury.Client
+}
+
+func (s *fakeSession) Mercury() *mercury.Client        { return s.mercury }
+func (s *fakeSession) Discovery() *discovery.Discovery { return nil }
+func (s *fakeSession) Username() string                { return "fakeUser" }
+func (s *fakeSession) DeviceId() string                { return "testDevice" }
+
+// fakeServer decodes the mercury requests sent by the controller, and sends back responses and events
 type fakeServer struct {
-	stream    connection.PacketStream
-	responses []mercury.Response
-	mInternal *mercury.Internal
-}
-
-func setupFakeServer(stream connection.PacketStream) *fakeServer {
-	return &fakeServer{
-		stream:    stream,
-		responses: make([]mercury.Response, 5),
-		mInternal: &mercury.Internal{
-			pending: make(map[string]mercury.Pending),
-			stream:  stream,
-		},
+	stream *fakeStream
+	client *mercury.Client
+}
+
+type mercuryRequest struct {
+	seq     []byte
+	header  *Spotify.Header
+	payload [][]byte
+}
+
+func encodePacket(seq []byte, parts ...[]byte) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint16(len(seq)))
+	buf.Write(seq)
+	buf.WriteByte(1)
+	binary.Write(buf, binary.BigEndian, uint16(len(parts)))
+	for _, p := range parts {
+		binary.Write(buf, binary.BigEndian, uint16(len(p)))
+		buf.Write(p)
 	}
+	return buf
 }
 
-func (f *fakeServer) getResponse(t *testing.T) *mercury.Response {
-	cmd, data, err := f.stream.RecvPacket()
-	if err != nil {
-		t.Error("poll error", err)
+func (f *fakeServer) getRequest(t *testing.T) *mercuryRequest {
+	var packet shanPacket
+	select {
+	case packet = <-f.stream.sendPackets:
+	case <-time.After(time.Second):
+		t.Fatal("no request sent")
 	}
-	if 0xb2 <= cmd && cmd <= 0xb6 || cmd == 0x1b {
-		var response *mercuryResponse
-		var err error
-		for ; response == nil && err == nil; response, err = f.mInternal.parseResponse(cmd, bytes.NewReader(data)) {
-			if err != nil {
-				t.Errorf("Handle 0xbx %q ", err)
-			}
-		}
-		return response
-	} else {
-		t.Errorf("Non mercury command %q ", cmd)
+
+	reader := bytes.NewReader(packet.buf)
+	var seqLen, count uint16
+	binary.Read(reader, binary.BigEndian, &seqLen)
+	req := &mercuryRequest{seq: make([]byte, seqLen)}
+	reader.Read(req.seq)
+	reader.ReadByte()
+	binary.Read(reader, binary.BigEndian, &count)
+
+	parts := make([][]byte, count)
+	for i := range parts {
+		var size uint16
+		binary.Read(reader, binary.BigEndian, &size)
+		parts[i] = make([]byte, size)
+		reader.Read(parts[i])
 	}
-	return nil
+
+	req.header = &Spotify.Header{}
+	if err := proto.Unmarshal(parts[0], req.header); err != nil {
+		t.Fatal("bad mercury header", err)
+	}
+	req.payload = parts[1:]
+	return req
 }
 
-func (f *fakeServer) getResponseFrame(t *testing.T) (*Spotify.Frame, *mercuryResponse) {
-	response := f.getResponse(t)
+func (f *fakeServer) getRequestFrame(t *testing.T) (*Spotify.Frame, *mercuryRequest) {
+	req := f.getRequest(t)
 	frame := &Spotify.Frame{}
-	proto.Unmarshal(response.headerData, frame)
-	return frame, response
+	if err := proto.Unmarshal(req.payload[0], frame); err != nil {
+		t.Fatal("bad frame", err)
+	}
+	return frame, req
 }
 
-func setupContollerAndServer(t *testing.T) (*Controller, *fakeServer) {
-	sessionStream := &fakeStream{
-		recvPackets: make(chan shanPacket, 5),
-		sendPackets: make(chan shanPacket, 5),
-	}
+func (f *fakeServer) reply(req *mercuryRequest) {
+	header, _ := proto.Marshal(&Spotify.Header{
+		Uri:        proto.String(req.header.GetUri()),
+		StatusCode: proto.Int32(200),
+	})
+	f.client.Handle(0xb2, encodePacket(req.seq, header))
+}
 
-	serverStream := &fakeStream{
-		recvPackets: sessionStream.sendPackets,
-		sendPackets: sessionStream.recvPackets,
+func (f *fakeServer) push(frame *Spotify.Frame) {
+	header, _ := proto.Marshal(&Spotify.Header{
+		Uri: proto.String("hm://remote/user/fakeUser/"),
+	})
+	data, _ := proto.Marshal(frame)
+	go f.client.Handle(0xb5, encodePacket([]byte{0, 0, 0, 0}, header, data))
+}
+
+// setupControllerAndServer creates a controller, and acknowledges its subscription
+func setupControllerAndServer(t *testing.T) (*Controller, *fakeServer) {
+	stream := &fakeStream{sendPackets: make(chan shanPacket, 5)}
+	server := &fakeServer{
+		stream: stream,
+		client: mercury.CreateMercury(stream),
 	}
 
-	server := setupFakeServer(serverStream)
+	controller := CreateController(&fakeSession{mercury: server.client}, []byte{})
 
-	s := &session{
-		stream:   sessionStream,
-		deviceId: "testDevice",
+	req := server.getRequest(t)
+	if req.header.GetMethod() != "SUB" || req.header.GetUri() != "hm://remote/user/fakeUser/" {
+		t.Errorf("bad subscription %s %s", req.header.GetMethod(), req.header.GetUri())
 	}
-	s.mercury = setupMercury(s)
-	controller := CreateController(s, "fakeUser", []byte{})
+	server.reply(req)
+
 	return controller, server
 }
 
 func TestHelloCmd(t *testing.T) {
-	controller, server := setupContollerAndServer(t)
-	go controller.SendHello()
+	_, server := setupControllerAndServer(t)
 
-	frame, response := server.getResponseFrame(t)
-	if response.uri != "hm://remote/user/fakeUser/" {
-		t.Errorf("Bad response uri %q ", response.uri)
+	// A hello is sent as soon as the subscription is acknowledged
+	frame, req := server.getRequestFrame(t)
+	if req.header.GetUri() != "hm://remote/user/fakeUser/" {
+		t.Errorf("Bad request uri %q ", req.header.GetUri())
 	}
 	if frame.GetTyp() != Spotify.MessageType_kMessageTypeHello {
 		t.Errorf("Wrong message type")
 	}
+	if frame.GetIdent() != "testDevice" {
+		t.Errorf("Wrong ident. Got %q, want %q", frame.GetIdent(), "testDevice")
+	}
+	if frame.DeviceState != nil {
+		t.Errorf("Unexpected device state for a session not advertised")
+	}
+	server.reply(req)
+}
+
+func TestSeekCmd(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	go controller.SendSeek("phone", 42000)
+	frame, req := server.getRequestFrame(t)
+	if frame.GetTyp() != Spotify.MessageType_kMessageTypeSeek || frame.GetPosition() != 42000 {
+		t.Errorf("Bad seek frame %v", frame)
+	}
+	if len(frame.Recipient) != 1 || frame.Recipient[0] != "phone" {
+		t.Errorf("Bad recipient %v", frame.Recipient)
+	}
+	server.reply(req)
+}
+
+func TestAdvertise(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	commands := make(chan Command, 1)
+	go controller.Advertise("Living Room", func(cmd Command) {
+		commands <- cmd
+	})
+
+	frame, req := server.getRequestFrame(t)
+	if frame.GetTyp() != Spotify.MessageType_kMessageTypeHello || frame.DeviceState.GetName() != "Living Room" {
+		t.Errorf("Bad advertisement frame %v", frame)
+	}
+	server.reply(req)
+
+	// Other devices saying hello get our state
+	server.push(&Spotify.Frame{
+		Ident: proto.String("phone"),
+		Typ:   Spotify.MessageType_kMessageTypeHello.Enum(),
+	})
+	frame, req = server.getRequestFrame(t)
+	if frame.GetTyp() != Spotify.MessageType_kMessageTypeNotify || len(frame.Recipient) != 1 ||
+		frame.Recipient[0] != "phone" {
+		t.Errorf("Bad notify frame %v", frame)
+	}
+	server.reply(req)
+
+	// Commands addressed to us are passed to the handler
+	server.push(&Spotify.Frame{
+		Ident:     proto.String("phone"),
+		Typ:       Spotify.MessageType_kMessageTypeSeek.Enum(),
+		Recipient: []string{"testDevice"},
+		Position:  proto.Uint32(1234),
+	})
+	select {
+	case cmd := <-commands:
+		if cmd.Type != Spotify.MessageType_kMessageTypeSeek || cmd.From != "phone" || cmd.Position != 1234 {
+			t.Errorf("Bad command %v", cmd)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("command not received")
+	}
+}
+
+func TestDeviceTracking(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	controller.handleFrame(&Spotify.Frame{
+		Ident: proto.String("phone"),
+		Typ:   Spotify.MessageType_kMessageTypeNotify.Enum(),
+		DeviceState: &Spotify.DeviceState{
+			Name:     proto.String("Phone"),
+			IsActive: proto.Bool(true),
+			Volume:   proto.Uint32(100),
+		},
+		State: &Spotify.State{PositionMs: proto.Uint32(5000)},
+	})
+
+	devices := controller.ListDevices()
+	if len(devices) != 1 || devices[0].Name != "Phone" || !devices[0].Active ||
+		devices[0].State.GetPositionMs() != 5000 {
+		t.Errorf("Bad devices %v", devices)
+	}
+
+	controller.handleFrame(&Spotify.Frame{
+		Ident: proto.String("phone"),
+		Typ:   Spotify.MessageType_kMessageTypeGoodbye.Enum(),
+	})
+	if len(controller.ListDevices()) != 0 {
+		t.Errorf("Device not removed after goodbye")
+	}
+}
+
+func TestDealerCommand(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	if controller.handleDealerCommand(dealer.Request{Payload: []byte(`{"command":{"endpoint":"pause"}}`)}) {
+		t.Errorf("Command accepted while not advertised")
+	}
+
+	commands := make(chan Command, 1)
+	go controller.Advertise("Living Room", func(cmd Command) {
+		commands <- cmd
+	})
+	server.reply(server.getRequest(t))
+
+	ok := controller.handleDealerCommand(dealer.Request{
+		Payload: []byte(`{"sent_by_device_id":"phone","command":{"endpoint":"seek_to","value":4200}}`),
+	})
+	if !ok {
+		t.Fatal("Seek command refused")
+	}
+	if cmd := <-commands; cmd.Type != Spotify.MessageType_kMessageTypeSeek || cmd.From != "phone" ||
+		cmd.Position != 4200 {
+		t.Errorf("Bad command %v", cmd)
+	}
+}
+
+func TestDealerTransfer(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	commands := make(chan Command, 1)
+	go controller.Advertise("Living Room", func(cmd Command) {
+		commands <- cmd
+	})
+	server.reply(server.getRequest(t))
+
+	track := func(uri, uid string) *Spotify.ContextTrack {
+		return &Spotify.ContextTrack{Uri: proto.String(uri), Uid: proto.String(uid)}
+	}
+	data, _ := proto.Marshal(&Spotify.TransferState{
+		Options: &Spotify.ContextPlayerOptions{ShufflingContext: proto.Bool(true)},
+		Playback: &Spotify.Playback{
+			PositionAsOfTimestamp: proto.Int32(5000),
+			IsPaused:              proto.Bool(true),
+			CurrentTrack:          track("spotify:track:0000000000000000000002", "b"),
+		},
+		CurrentSession: &Spotify.Session{
+			Context: &Spotify.Context{
+				Uri: proto.String("spotify:album:1"),
+				Pages: []*Spotify.ContextPage{{Tracks: []*Spotify.ContextTrack{
+					track("spotify:track:0000000000000000000001", "a"),
+					track("spotify:track:0000000000000000000002", "b"),
+					track("spotify:track:0000000000000000000003", "c"),
+				}}},
+			},
+		},
+		Queue: &Spotify.Queue{Tracks: []*Spotify.ContextTrack{track("spotify:track:0000000000000000000004", "q")}},
+	})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"sent_by_device_id": "phone",
+		"command":           map[string]interface{}{"endpoint": "transfer", "data": data},
+	})
+	if !controller.handleDealerCommand(dealer.Request{Payload: payload}) {
+		t.Fatal("Transfer refused")
+	}
+
+	cmd := <-commands
+	state := cmd.State
+	if cmd.Type != Spotify.MessageType_kMessageTypeLoad || cmd.From != "phone" ||
+		state.GetContextUri() != "spotify:album:1" || state.GetPlayingTrackIndex() != 1 ||
+		state.GetStatus() != Spotify.PlayStatus_kPlayStatusPause || state.GetPositionMs() != 5000 || !state.GetShuffle() {
+		t.Errorf("Bad transfer %v", cmd)
+	}
+	if len(state.Track) != 4 || !state.Track[2].GetQueued() ||
+		state.Track[2].GetUri() != "spotify:track:0000000000000000000004" {
+		t.Errorf("Bad transferred tracks %v", state.Track)
+	}
+}
+
+func TestDealerPlay(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	commands := make(chan Command, 1)
+	go controller.Advertise("Living Room", func(cmd Command) {
+		commands <- cmd
+	})
+	server.reply(server.getRequest(t))
+
+	ok := controller.handleDealerCommand(dealer.Request{Payload: []byte(`{"sent_by_device_id":"phone","command":{
+		"endpoint":"play",
+		"context":{"uri":"spotify:playlist:1","restrictions":{},"pages":[{"tracks":[
+			{"uri":"spotify:track:0000000000000000000001","uid":"a"},
+			{"uri":"spotify:track:0000000000000000000002","uid":"b"}]}]},
+		"options":{"skip_to":{"track_uid":"b"},"seek_to":1000,"initially_paused":false}}}`)})
+	if !ok {
+		t.Fatal("Play command refused")
+	}
+	cmd := <-commands
+	if cmd.Type != Spotify.MessageType_kMessageTypeLoad || cmd.State.GetContextUri() != "spotify:playlist:1" ||
+		len(cmd.State.Track) != 2 || cmd.State.GetPlayingTrackIndex() != 1 || cmd.State.GetPositionMs() != 1000 ||
+		cmd.State.GetStatus() != Spotify.PlayStatus_kPlayStatusPlay {
+		t.Errorf("Bad play command %v", cmd)
+	}
+
+	if controller.handleDealerCommand(dealer.Request{Payload: []byte(`{"command":{"endpoint":"play","context":{}}}`)}) {
+		t.Errorf("Play command without context accepted")
+	}
+}
+
+func TestDeviceSnapshots(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	controller.handleFrame(&Spotify.Frame{
+		Ident: proto.String("phone"),
+		Typ:   Spotify.MessageType_kMessageTypeNotify.Enum(),
+		DeviceState: &Spotify.DeviceState{
+			Name:     proto.String("Phone"),
+			IsActive: proto.Bool(true),
+		},
+		State: &Spotify.State{
+			ContextUri:         proto.String("spotify:album:1"),
+			Status:             Spotify.PlayStatus_kPlayStatusPlay.Enum(),
+			PositionMs:         proto.Uint32(5000),
+			PositionMeasuredAt: proto.Uint64(1000000),
+			PlayingTrackIndex:  proto.Uint32(1),
+			Shuffle:            proto.Bool(true),
+			Track: []*Spotify.TrackRef{
+				{Uri: proto.String("spotify:track:first")},
+				{Gid: []byte{1}, Queued: proto.Bool(true)},
+			},
+		},
+	})
+
+	snapshots := controller.DeviceSnapshots()
+	if len(snapshots) != 1 {
+		t.Fatalf("Got %d snapshots", len(snapshots))
+	}
+	s := snapshots[0]
+	if s.Ident != "phone" || !s.Active || s.ContextUri != "spotify:album:1" || !s.Shuffle || s.Repeat {
+		t.Errorf("Bad snapshot %+v", s)
+	}
+	track, ok := s.Track()
+	if !ok || track.Uri != "spotify:track:0000000000000000000001" || !track.Queued {
+		t.Errorf("Bad current track %+v", track)
+	}
+	if position := s.PositionAt(time.Unix(1001, 0)); position != 6*time.Second {
+		t.Errorf("Bad position %v", position)
+	}
+
+	if _, err := controller.LocalSnapshot(); err == nil {
+		t.Errorf("LocalSnapshot should fail before advertising")
+	}
+}
+
+func TestFilterState(t *testing.T) {
+	ref := func(n byte) *Spotify.TrackRef {
+		return &Spotify.TrackRef{Gid: bytes.Repeat([]byte{n}, 16)}
+	}
+	state := &Spotify.State{
+		Track:             []*Spotify.TrackRef{ref(1), ref(2), ref(3), ref(4)},
+		PlayingTrackIndex: proto.Uint32(2),
+	}
+	state.Track[3].Uri = proto.String("spotify:track:0000000000000000000000")
+
+	// Tracks 2 and 3 are vetoed, and 4 substituted by 5
+	substitute, _ := ids.FromGid(ids.KindTrack, ref(5).Gid)
+	filtered := filterState(state, func(id ids.Id) (ids.Id, error) {
+		switch id.Gid[0] {
+		case 2, 3:
+			return ids.Id{}, errors.New("vetoed")
+		case 0:
+			return substitute, nil
+		}
+		return id, nil
+	}, log.Default())
+	if len(filtered.Track) != 2 || filtered.Track[0].Gid[0] != 1 || filtered.Track[1].Gid[0] != 5 ||
+		filtered.Track[1].GetUri() != substitute.Uri() {
+		t.Errorf("Bad filtered tracks %v", filtered.Track)
+	}
+	if filtered.GetPlayingTrackIndex() != 1 {
+		t.Errorf("Playing track index %d, want 1", filtered.GetPlayingTrackIndex())
+	}
+	if len(state.Track) != 4 || state.GetPlayingTrackIndex() != 2 {
+		t.Error("Original state modified")
+	}
+}
+
+func TestTransferStart(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+
+	commands := make(chan Command, 2)
+	go controller.Advertise("Meeting Room", func(cmd Command) {
+		commands <- cmd
+	})
+	server.reply(server.getRequest(t))
+
+	load := func() Command {
+		controller.handleFrame(&Spotify.Frame{
+			Ident:     proto.String("phone"),
+			Typ:       Spotify.MessageType_kMessageTypeLoad.Enum(),
+			Recipient: []string{"testDevice"},
+			State:     &Spotify.State{Status: Spotify.PlayStatus_kPlayStatusPlay.Enum()},
+		})
+		select {
+		case cmd := <-commands:
+			return cmd
+		case <-time.After(time.Second):
+			t.Fatal("load not received")
+			return Command{}
+		}
+	}
+
+	if cmd := load(); cmd.State.GetStatus() != Spotify.PlayStatus_kPlayStatusPlay {
+		t.Errorf("Transfer not started as requested: %v", cmd.State)
+	}
+
+	controller.SetTransferStart(TransferPaused, nil)
+	if cmd := load(); cmd.State.GetStatus() != Spotify.PlayStatus_kPlayStatusPause {
+		t.Errorf("Transfer not started paused: %v", cmd.State)
+	}
+
+	// The same goes for the transfers of the dealer
+	data, _ := proto.Marshal(&Spotify.TransferState{
+		Playback: &Spotify.Playback{
+			CurrentTrack: &Spotify.ContextTrack{Uri: proto.String("spotify:track:0000000000000000000001")},
+		},
+	})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"sent_by_device_id": "phone",
+		"command":           map[string]interface{}{"endpoint": "transfer", "data": data},
+	})
+	if !controller.handleDealerCommand(dealer.Request{Payload: payload}) {
+		t.Fatal("Transfer refused")
+	}
+	if cmd := <-commands; cmd.State.GetStatus() != Spotify.PlayStatus_kPlayStatusPause {
+		t.Errorf("Dealer transfer not started paused: %v", cmd.State)
+	}
+
+	// The confirmation of a transfer replaced by another one is ignored
+	confirmations := make(chan chan bool, 2)
+	controller.SetTransferStart(TransferConfirm, func(cmd Command) bool {
+		confirmed := make(chan bool)
+		confirmations <- confirmed
+		return <-confirmed
+	})
+	load()
+	first := <-confirmations
+	if cmd := load(); cmd.State.GetStatus() != Spotify.PlayStatus_kPlayStatusPause {
+		t.Errorf("Transfer to confirm not started paused: %v", cmd.State)
+	}
+	second := <-confirmations
+	first <- true
+	second <- true
+	select {
+	case cmd := <-commands:
+		if cmd.Type != Spotify.MessageType_kMessageTypePlay || cmd.From != "phone" {
+			t.Errorf("Bad command once confirmed %v", cmd)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("play not received once confirmed")
+	}
+	select {
+	case cmd := <-commands:
+		t.Errorf("Unexpected command %v", cmd)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestRestrictions(t *testing.T) {
+	controller, server := setupControllerAndServer(t)
+	server.reply(server.getRequest(t))
+	logs := new(bytes.Buffer)
+	controller.SetLogger(log.New(logs, "", 0))
+
+	if err := controller.SetRestrictions(Restrictions{Disallowed: RemoteVolume | RemoteSeek}); err != nil {
+		t.Fatal(err)
+	}
+	commands := make(chan Command, 1)
+	go controller.Advertise("Living Room", func(cmd Command) {
+		commands <- cmd
+	})
+	frame, req := server.getRequestFrame(t)
+	for _, capability := range frame.DeviceState.GetCapabilities() {
+		if capability.GetTyp() == Spotify.CapabilityType_kVolumeSteps && capability.GetIntValue()[0] != 0 {
+			t.Errorf("Volume steps advertised: %v", capability)
+		}
+	}
+	server.reply(req)
+
+	// The rejected Spirc commands are notified back to their sender with the error
+	server.push(&Spotify.Frame{
+		Ident:     proto.String("phone"),
+		Typ:       Spotify.MessageType_kMessageTypeVolume.Enum(),
+		Recipient: []string{"testDevice"},
+		Volume:    proto.Uint32(100),
+	})
+	frame, req = server.getRequestFrame(t)
+	if frame.GetTyp() != Spotify.MessageType_kMessageTypeNotify || len(frame.Recipient) != 1 ||
+		frame.Recipient[0] != "phone" || frame.DeviceState.GetErrorCode() != RestrictedErrorCode {
+		t.Errorf("Bad rejection frame %v", frame)
+	}
+	if !strings.Contains(logs.String(), `Rejecting the volume command of "phone"`) {
+		t.Errorf("Rejection not logged: %q", logs.String())
+	}
+	server.reply(req)
+
+	if controller.handleDealerVolume(dealer.Request{Payload: []byte(`{"volume":100}`)}) {
+		t.Errorf("Volume command accepted")
+	}
+	if controller.handleDealerCommand(dealer.Request{
+		Payload: []byte(`{"sent_by_device_id":"phone","command":{"endpoint":"seek_to","value":4200}}`),
+	}) {
+		t.Errorf("Seek command accepted")
+	}
+	if !controller.handleDealerCommand(dealer.Request{Payload: []byte(`{"command":{"endpoint":"pause"}}`)}) {
+		t.Errorf("Pause command refused")
+	}
+	if cmd := <-commands; cmd.Type != Spotify.MessageType_kMessageTypePause {
+		t.Errorf("Bad command %v", cmd)
+	}
+
+	// The restrictions are advertised to the connect-state service
+	restrictions := controller.Restrictions()
+	info := deviceInfo("testDevice", &localDevice{}, restrictions)
+	if steps := info.GetCapabilities().GetVolumeSteps(); steps != 0 {
+		t.Errorf("Bad volume steps %d", steps)
+	}
+	if !info.GetCapabilities().GetDisableVolume() {
+		t.Errorf("Volume not disabled")
+	}
+	restricted := playerState(nil, restrictions).GetRestrictions()
+	if seeking := restricted.GetDisallowSeekingReasons(); len(seeking) != 1 || seeking[0] != "not_supported" {
+		t.Errorf("Bad seeking restriction %q", seeking)
+	}
+	if pausing := restricted.GetDisallowPausingReasons(); pausing != nil {
+		t.Errorf("Pausing restricted: %q", pausing)
+	}
 }
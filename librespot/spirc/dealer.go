@@ -0,0 +1,258 @@
+package spirc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/dealer"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	dealerCommandIdent = "hm://connect-state/v1/player/command"
+	dealerVolumeIdent  = "hm://connect-state/v1/connect/volume"
+)
+
+// Connect state player commands, mapped to their Spirc equivalent
+var dealerCommands = map[string]Spotify.MessageType{
+	"resume":                Spotify.MessageType_kMessageTypePlay,
+	"pause":                 Spotify.MessageType_kMessageTypePause,
+	"skip_next":             Spotify.MessageType_kMessageTypeNext,
+	"skip_prev":             Spotify.MessageType_kMessageTypePrev,
+	"seek_to":               Spotify.MessageType_kMessageTypeSeek,
+	"set_shuffling_context": Spotify.MessageType_kMessageTypeShuffle,
+	"set_repeating_context": Spotify.MessageType_kMessageTypeRepeat,
+	"transfer":              Spotify.MessageType_kMessageTypeLoad,
+	"play":                  Spotify.MessageType_kMessageTypeLoad,
+}
+
+type dealerCommand struct {
+	SentByDeviceId string `json:"sent_by_device_id"`
+	Command        struct {
+		Endpoint string          `json:"endpoint"`
+		Value    json.RawMessage `json:"value"`
+		// Data is the TransferState of a transfer command
+		Data []byte `json:"data"`
+		// Context and Options are the context to play and how to start it, for a play command
+		Context json.RawMessage   `json:"context"`
+		Options dealerPlayOptions `json:"options"`
+	} `json:"command"`
+}
+
+type dealerPlayOptions struct {
+	SkipTo struct {
+		TrackIndex int    `json:"track_index"`
+		TrackUid   string `json:"track_uid"`
+		TrackUri   string `json:"track_uri"`
+	} `json:"skip_to"`
+	InitiallyPaused       bool   `json:"initially_paused"`
+	SeekTo                uint32 `json:"seek_to"`
+	PlayerOptionsOverride struct {
+		ShufflingContext bool `json:"shuffling_context"`
+		RepeatingContext bool `json:"repeating_context"`
+	} `json:"player_options_override"`
+}
+
+// HandleDealer passes the Connect state commands received through the dealer to the command handler of this
+// session, the same way as the Spirc commands. The transfers of the playback and the play commands are passed as load
+// commands, holding the tracks of the context sent along, or only the current track if the context isn't paged in.
+// The commands disallowed by the restrictions are acknowledged as failed. Modern clients only send their commands
+// through the dealer.
+func (c *Controller) HandleDealer(d *dealer.Dealer) {
+	d.HandleRequest(dealerCommandIdent, c.handleDealerCommand)
+	d.HandleRequest(dealerVolumeIdent, c.handleDealerVolume)
+	c.cluster.HandleDealer(d)
+}
+
+// Cluster returns the Connect devices of the user, as reported by the connect-state service. It is only updated once
+// HandleDealer is called.
+func (c *Controller) Cluster() *Cluster {
+	return c.cluster
+}
+
+func (c *Controller) handleDealerCommand(req dealer.Request) bool {
+	var cmd dealerCommand
+	if err := json.Unmarshal(req.Payload, &cmd); err != nil {
+		return false
+	}
+
+	typ, ok := dealerCommands[cmd.Command.Endpoint]
+	if !ok {
+		return false
+	}
+
+	command := Command{
+		Type: typ,
+		From: cmd.SentByDeviceId,
+	}
+	switch cmd.Command.Endpoint {
+	case "seek_to":
+		var position uint32
+		if err := json.Unmarshal(cmd.Command.Value, &position); err != nil {
+			return false
+		}
+		command.Position = position
+	case "transfer":
+		transfer := &Spotify.TransferState{}
+		if err := proto.Unmarshal(cmd.Command.Data, transfer); err != nil {
+			return false
+		}
+		command.State = transferredState(transfer, time.Now())
+	case "play":
+		context := &Spotify.Context{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(cmd.Command.Context, context); err != nil {
+			return false
+		}
+		command.State = playedState(context, cmd.Command.Options)
+	}
+	if command.State != nil && len(command.State.GetTrack()) == 0 && command.State.GetContextUri() == "" {
+		return false
+	}
+	if !c.allows(command) {
+		return false
+	}
+	if typ == Spotify.MessageType_kMessageTypeLoad {
+		return c.load(command)
+	}
+	return c.dispatchCommand(command)
+}
+
+// transferredState returns the Spirc state resuming the playback transferred from another device, at the position it
+// reached at the specified time
+func transferredState(transfer *Spotify.TransferState, now time.Time) *Spotify.State {
+	playback := transfer.GetPlayback()
+	current := playback.GetCurrentTrack()
+	uid := current.GetUid()
+	if uid == "" {
+		uid = transfer.GetCurrentSession().GetCurrentUid()
+	}
+	state, found := contextState(transfer.GetCurrentSession().GetContext(), uid, current.GetUri(), -1)
+	if !found && (current.GetUri() != "" || len(current.GetGid()) > 0) {
+		// The tracks of the context aren't paged in, only the current one is played
+		state.Track = []*Spotify.TrackRef{trackRef(current, state.GetContextUri())}
+		state.PlayingTrackIndex = proto.Uint32(0)
+	}
+
+	// The queued tracks are played after the current one
+	var queued []*Spotify.TrackRef
+	for _, track := range transfer.GetQueue().GetTracks() {
+		ref := trackRef(track, state.GetContextUri())
+		ref.Queued = proto.Bool(true)
+		queued = append(queued, ref)
+	}
+	if len(queued) > 0 && len(state.Track) > 0 {
+		next := int(state.GetPlayingTrackIndex()) + 1
+		state.Track = append(state.Track[:next], append(queued, state.Track[next:]...)...)
+	}
+
+	ms := now.UnixNano() / int64(time.Millisecond)
+	position := int64(playback.GetPositionAsOfTimestamp())
+	if playback.GetIsPaused() {
+		state.Status = Spotify.PlayStatus_kPlayStatusPause.Enum()
+	} else if playback.GetTimestamp() > 0 && ms > playback.GetTimestamp() {
+		position += ms - playback.GetTimestamp()
+	}
+	state.PositionMs = proto.Uint32(uint32(position))
+	state.PositionMeasuredAt = proto.Uint64(uint64(ms))
+	state.Shuffle = proto.Bool(transfer.GetOptions().GetShufflingContext())
+	state.Repeat = proto.Bool(transfer.GetOptions().GetRepeatingContext())
+	return state
+}
+
+// playedState returns the Spirc state playing a context as requested by a play command
+func playedState(context *Spotify.Context, options dealerPlayOptions) *Spotify.State {
+	state, _ := contextState(context, options.SkipTo.TrackUid, options.SkipTo.TrackUri, options.SkipTo.TrackIndex)
+	if len(state.Track) == 0 && options.SkipTo.TrackUri != "" {
+		state.Track = []*Spotify.TrackRef{trackRef(&Spotify.ContextTrack{Uri: proto.String(options.SkipTo.TrackUri)},
+			context.GetUri())}
+	}
+	if options.InitiallyPaused {
+		state.Status = Spotify.PlayStatus_kPlayStatusPause.Enum()
+	}
+	state.PositionMs = proto.Uint32(options.SeekTo)
+	state.Shuffle = proto.Bool(options.PlayerOptionsOverride.ShufflingContext)
+	state.Repeat = proto.Bool(options.PlayerOptionsOverride.RepeatingContext)
+	return state
+}
+
+// contextState returns the Spirc state playing the tracks of a context, starting at the track with the uid, or else
+// the uri, or else at the index, and whether that track was found. Only the tracks of the pages sent along are known.
+func contextState(context *Spotify.Context, uid, uri string, index int) (*Spotify.State, bool) {
+	state := &Spotify.State{
+		ContextUri: proto.String(context.GetUri()),
+		Status:     Spotify.PlayStatus_kPlayStatusPlay.Enum(),
+	}
+	byUri := -1
+	playing := -1
+	for _, page := range context.GetPages() {
+		for _, track := range page.GetTracks() {
+			if uid != "" && track.GetUid() == uid && playing < 0 {
+				playing = len(state.Track)
+			}
+			if uri != "" && track.GetUri() == uri && byUri < 0 {
+				byUri = len(state.Track)
+			}
+			state.Track = append(state.Track, trackRef(track, context.GetUri()))
+		}
+	}
+	if playing < 0 {
+		playing = byUri
+	}
+	if playing < 0 && index >= 0 && index < len(state.Track) {
+		playing = index
+	}
+	found := playing >= 0
+	if !found {
+		playing = 0
+	}
+	state.PlayingTrackIndex = proto.Uint32(uint32(playing))
+	return state, found
+}
+
+// trackRef returns the Spirc TrackRef of a track of a context
+func trackRef(track *Spotify.ContextTrack, contextUri string) *Spotify.TrackRef {
+	ref := &Spotify.TrackRef{Queued: proto.Bool(false)}
+	if track.GetUri() != "" {
+		ref.Uri = proto.String(track.GetUri())
+	}
+	if len(track.GetGid()) > 0 {
+		ref.Gid = track.GetGid()
+	}
+	if contextUri != "" {
+		ref.Context = proto.String(contextUri)
+	}
+	return ref
+}
+
+func (c *Controller) handleDealerVolume(req dealer.Request) bool {
+	var volume struct {
+		Volume uint32 `json:"volume"`
+	}
+	if err := json.Unmarshal(req.Payload, &volume); err != nil {
+		return false
+	}
+
+	command := Command{
+		Type:   Spotify.MessageType_kMessageTypeVolume,
+		Volume: volume.Volume,
+	}
+	if !c.allows(command) {
+		return false
+	}
+	return c.dispatchCommand(command)
+}
+
+// dispatchCommand passes the command to the handler of this session, returning false if it is not advertised
+func (c *Controller) dispatchCommand(cmd Command) bool {
+	c.localLock.Lock()
+	local := c.local
+	c.localLock.Unlock()
+	if local == nil || local.handler == nil {
+		return false
+	}
+
+	local.handler(cmd)
+	return true
+}
@@ -0,0 +1,72 @@
+package spirc
+
+import (
+	"log"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/ids"
+	"github.com/golang/protobuf/proto"
+)
+
+// ContentFilter returns the track to play instead of the one loaded from Connect, which is the same id unless it is
+// substituted, or an error to leave it out, e.g. player.Player.FilterContent
+type ContentFilter func(id ids.Id) (ids.Id, error)
+
+// SetContentFilter makes the controller filter the tracks loaded by the other Connect devices before passing the load
+// commands to the handler, so that they are filtered the same way as the tracks played locally. Pass nil to remove
+// it.
+func (c *Controller) SetContentFilter(filter ContentFilter) {
+	c.localLock.Lock()
+	c.contentFilter = filter
+	c.localLock.Unlock()
+}
+
+// filterState returns a copy of the state with the tracks vetoed by the filter left out, and the substituted ones
+// replaced. The playing track index is moved to the following track if the playing one is left out. The tracks left
+// out are logged with logger.
+func filterState(state *Spotify.State, filter ContentFilter, logger *log.Logger) *Spotify.State {
+	if state == nil || filter == nil {
+		return state
+	}
+
+	filtered := proto.Clone(state).(*Spotify.State)
+	filtered.Track = nil
+	playing := state.GetPlayingTrackIndex()
+	index := uint32(0)
+	for i, ref := range state.GetTrack() {
+		id, err := trackRefId(ref)
+		if err == nil {
+			id, err = filter(id)
+		}
+		if err != nil {
+			logger.Printf("Leaving out track %d of the Connect state: %v\n", i, err)
+			if uint32(i) < state.GetPlayingTrackIndex() {
+				playing--
+			}
+			continue
+		}
+
+		ref = proto.Clone(ref).(*Spotify.TrackRef)
+		ref.Gid = id.Gid[:]
+		if ref.Uri != nil {
+			ref.Uri = proto.String(id.Uri())
+		}
+		filtered.Track = append(filtered.Track, ref)
+		index++
+	}
+	if state.PlayingTrackIndex != nil {
+		if playing >= index && index > 0 {
+			playing = index - 1
+		}
+		filtered.PlayingTrackIndex = proto.Uint32(playing)
+	}
+	return filtered
+}
+
+// trackRefId returns the id of the track or episode of a TrackRef, from its URI or its GID
+func trackRefId(ref *Spotify.TrackRef) (ids.Id, error) {
+	if ref.GetUri() != "" {
+		return ids.Parse(ref.GetUri())
+	}
+	return ids.FromGid(ids.KindTrack, ref.GetGid())
+}
@@ -0,0 +1,171 @@
+package spirc
+
+import (
+	"strings"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// RemoteCommand is a set of kinds of commands the other Connect devices send to this session
+type RemoteCommand uint32
+
+const (
+	RemotePlay RemoteCommand = 1 << iota
+	RemotePause
+	RemoteSeek
+	RemotePrev
+	RemoteNext
+	RemoteShuffle
+	RemoteRepeat
+	// RemoteVolume covers the volume commands, setting the volume or stepping it up and down
+	RemoteVolume
+	// RemoteLoad covers the load commands, transferring the playback to this session
+	RemoteLoad
+)
+
+// RestrictedErrorCode is the error code of the device state notified to the Spirc devices whose command is
+// rejected by the restrictions
+const RestrictedErrorCode = 1
+
+// remoteCommandNames are the names of the kinds of commands, in the order of their bits
+var remoteCommandNames = []string{"play", "pause", "seek", "prev", "next", "shuffle", "repeat", "volume", "load"}
+
+func (r RemoteCommand) String() string {
+	var names []string
+	for i, name := range remoteCommandNames {
+		if r&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// remoteCommand returns the kind of the commands of the message type, the play/pause toggle being both a play and a
+// pause command
+func remoteCommand(typ Spotify.MessageType) RemoteCommand {
+	switch typ {
+	case Spotify.MessageType_kMessageTypePlay:
+		return RemotePlay
+	case Spotify.MessageType_kMessageTypePause:
+		return RemotePause
+	case Spotify.MessageType_kMessageTypePlayPause:
+		return RemotePlay | RemotePause
+	case Spotify.MessageType_kMessageTypeSeek:
+		return RemoteSeek
+	case Spotify.MessageType_kMessageTypePrev:
+		return RemotePrev
+	case Spotify.MessageType_kMessageTypeNext:
+		return RemoteNext
+	case Spotify.MessageType_kMessageTypeShuffle:
+		return RemoteShuffle
+	case Spotify.MessageType_kMessageTypeRepeat:
+		return RemoteRepeat
+	case Spotify.MessageType_kMessageTypeVolume, Spotify.MessageType_kMessageTypeVolumeUp,
+		Spotify.MessageType_kMessageTypeVolumeDown:
+		return RemoteVolume
+	case Spotify.MessageType_kMessageTypeLoad:
+		return RemoteLoad
+	}
+	return 0
+}
+
+// Restrictions are the remote commands this session doesn't accept, e.g. RemoteVolume when a hardware amplifier owns
+// the volume. They are advertised to the other Connect devices, which grey out the matching controls, and the commands
+// sent anyway are rejected before reaching the command handler.
+type Restrictions struct {
+	Disallowed RemoteCommand
+	// Reason is advertised along the restrictions of the connect-state service, "not_supported" if empty
+	Reason string
+}
+
+// Allows returns true if none of the kinds of the command are disallowed
+func (r Restrictions) Allows(cmd RemoteCommand) bool {
+	return r.Disallowed&cmd == 0
+}
+
+// reason returns the reason advertised for the disallowed commands
+func (r Restrictions) reason() string {
+	if r.Reason == "" {
+		return "not_supported"
+	}
+	return r.Reason
+}
+
+// SetRestrictions sets the remote commands this session disallows, and advertises them if the session is advertised
+func (c *Controller) SetRestrictions(restrictions Restrictions) error {
+	c.localLock.Lock()
+	c.restrictions = restrictions
+	advertised := c.local != nil
+	c.localLock.Unlock()
+
+	if !advertised {
+		return nil
+	}
+	err := c.notify(nil)
+	if putErr := c.putState(Spotify.PutStateReason_PLAYER_STATE_CHANGED); err == nil {
+		err = putErr
+	}
+	return err
+}
+
+// Restrictions returns the remote commands this session disallows
+func (c *Controller) Restrictions() Restrictions {
+	c.localLock.Lock()
+	defer c.localLock.Unlock()
+	return c.restrictions
+}
+
+// allows returns true if the restrictions allow the command, logging it otherwise
+func (c *Controller) allows(cmd Command) bool {
+	restrictions := c.Restrictions()
+	kind := remoteCommand(cmd.Type)
+	if restrictions.Allows(kind) {
+		return true
+	}
+	c.logger().Printf("Rejecting the %s command of %q: %s\n", kind, cmd.From, restrictions.reason())
+	return false
+}
+
+// reject notifies the sender of a rejected Spirc command of the error, along with the state of this session
+func (c *Controller) reject(cmd Command) error {
+	frame := c.newFrame(Spotify.MessageType_kMessageTypeNotify, []string{cmd.From})
+
+	c.localLock.Lock()
+	if c.local == nil {
+		c.localLock.Unlock()
+		return nil
+	}
+	frame.DeviceState = c.local.deviceState(c.restrictions)
+	frame.DeviceState.ErrorCode = proto.Uint32(RestrictedErrorCode)
+	frame.DeviceState.ErrorMessage = proto.String(remoteCommand(cmd.Type).String() + " disallowed: " +
+		c.restrictions.reason())
+	frame.State = c.local.state
+	c.localLock.Unlock()
+
+	return c.sendFrame(frame)
+}
+
+// playerRestrictions returns the Restrictions of the PlayerState of the connect-state service
+func playerRestrictions(restrictions Restrictions) *Spotify.Restrictions {
+	r := &Spotify.Restrictions{}
+	for _, field := range []struct {
+		reasons *[]string
+		kind    RemoteCommand
+	}{
+		{&r.DisallowPausingReasons, RemotePause},
+		{&r.DisallowResumingReasons, RemotePlay},
+		{&r.DisallowSeekingReasons, RemoteSeek},
+		{&r.DisallowSkippingPrevReasons, RemotePrev},
+		{&r.DisallowSkippingNextReasons, RemoteNext},
+		{&r.DisallowTogglingRepeatContextReasons, RemoteRepeat},
+		{&r.DisallowTogglingRepeatTrackReasons, RemoteRepeat},
+		{&r.DisallowTogglingShuffleReasons, RemoteShuffle},
+		{&r.DisallowTransferringPlaybackReasons, RemoteLoad},
+	} {
+		if !restrictions.Allows(field.kind) {
+			*field.reasons = []string{restrictions.reason()}
+		}
+	}
+	return r
+}
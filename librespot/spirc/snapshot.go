@@ -0,0 +1,128 @@
+package spirc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// TrackSnapshot is a track of the queue of a Connect device
+type TrackSnapshot struct {
+	Uri     string `json:"uri"`
+	Queued  bool   `json:"queued,omitempty"`
+	Context string `json:"context,omitempty"`
+}
+
+// DeviceSnapshot is a copy of the Connect state of a device at a point in time, for debugging and for UIs rendering
+// the Connect state directly. It is not updated afterwards.
+type DeviceSnapshot struct {
+	Ident  string `json:"ident"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	// BecameActiveAt is the time the device started playing, only known for this session
+	BecameActiveAt time.Time `json:"became_active_at,omitempty"`
+	Volume         uint32    `json:"volume"`
+
+	Status             Spotify.PlayStatus `json:"status"`
+	ContextUri         string             `json:"context_uri,omitempty"`
+	ContextDescription string             `json:"context_description,omitempty"`
+	TrackIndex         int                `json:"track_index"`
+	Tracks             []TrackSnapshot    `json:"tracks,omitempty"`
+	// Position is the playback position at PositionMeasuredAt
+	Position           time.Duration `json:"position"`
+	PositionMeasuredAt time.Time     `json:"position_measured_at,omitempty"`
+	Shuffle            bool          `json:"shuffle"`
+	Repeat             bool          `json:"repeat"`
+
+	// LastCommandIdent and LastCommandMsgId identify the last command the device executed
+	LastCommandIdent string `json:"last_command_ident,omitempty"`
+	LastCommandMsgId uint32 `json:"last_command_msg_id,omitempty"`
+}
+
+// Track returns the track being played, if any
+func (s *DeviceSnapshot) Track() (TrackSnapshot, bool) {
+	if s.TrackIndex < 0 || s.TrackIndex >= len(s.Tracks) {
+		return TrackSnapshot{}, false
+	}
+	return s.Tracks[s.TrackIndex], true
+}
+
+// PositionAt estimates the playback position at the specified time, assuming that the device kept playing since the
+// position was measured
+func (s *DeviceSnapshot) PositionAt(t time.Time) time.Duration {
+	if s.Status != Spotify.PlayStatus_kPlayStatusPlay || s.PositionMeasuredAt.IsZero() ||
+		t.Before(s.PositionMeasuredAt) {
+		return s.Position
+	}
+	return s.Position + t.Sub(s.PositionMeasuredAt)
+}
+
+// LocalSnapshot returns the Connect state of this session, once advertised with Advertise
+func (c *Controller) LocalSnapshot() (DeviceSnapshot, error) {
+	c.localLock.Lock()
+	defer c.localLock.Unlock()
+
+	if c.local == nil {
+		return DeviceSnapshot{}, errors.New("session is not advertised as a Connect device")
+	}
+
+	snapshot := newDeviceSnapshot(c.session.DeviceId(), c.local.name, c.local.active, c.local.volume, c.local.state)
+	if c.local.active {
+		snapshot.BecameActiveAt = msToTime(uint64(c.local.becameActive))
+	}
+	return snapshot, nil
+}
+
+// DeviceSnapshots returns the Connect state of the other devices, as last notified by them
+func (c *Controller) DeviceSnapshots() []DeviceSnapshot {
+	c.devicesLock.RLock()
+	defer c.devicesLock.RUnlock()
+
+	res := make([]DeviceSnapshot, 0, len(c.devices))
+	for _, device := range c.devices {
+		res = append(res, newDeviceSnapshot(device.Ident, device.Name, device.Active, uint32(device.Volume),
+			device.State))
+	}
+	return res
+}
+
+func newDeviceSnapshot(ident string, name string, active bool, volume uint32, state *Spotify.State) DeviceSnapshot {
+	snapshot := DeviceSnapshot{
+		Ident:              ident,
+		Name:               name,
+		Active:             active,
+		Volume:             volume,
+		Status:             state.GetStatus(),
+		ContextUri:         state.GetContextUri(),
+		ContextDescription: state.GetContextDescription(),
+		TrackIndex:         int(state.GetPlayingTrackIndex()),
+		Position:           time.Duration(state.GetPositionMs()) * time.Millisecond,
+		Shuffle:            state.GetShuffle(),
+		Repeat:             state.GetRepeat(),
+		LastCommandIdent:   state.GetLastCommandIdent(),
+		LastCommandMsgId:   state.GetLastCommandMsgid(),
+	}
+	if at := state.GetPositionMeasuredAt(); at != 0 {
+		snapshot.PositionMeasuredAt = msToTime(at)
+	}
+
+	for _, track := range state.GetTrack() {
+		uri := track.GetUri()
+		if uri == "" && len(track.GetGid()) > 0 {
+			uri = "spotify:track:" + utils.ConvertTo62(track.GetGid())
+		}
+		snapshot.Tracks = append(snapshot.Tracks, TrackSnapshot{
+			Uri:     uri,
+			Queued:  track.GetQueued(),
+			Context: track.GetContext(),
+		})
+	}
+	return snapshot
+}
+
+// msToTime converts a timestamp in milliseconds since the epoch
+func msToTime(ms uint64) time.Time {
+	return time.Unix(0, int64(ms)*int64(time.Millisecond))
+}
@@ -0,0 +1,91 @@
+package spirc
+
+import (
+	"github.com/fischerling/librespot-golang/Spotify"
+	"github.com/golang/protobuf/proto"
+)
+
+// TransferStart is how the playback starts when it is transferred to this device, by a Spirc load command or by a
+// transfer or play command of the dealer
+type TransferStart int
+
+const (
+	// TransferAsRequested starts the playback playing or paused, as requested by the device transferring it. It is the
+	// default.
+	TransferAsRequested TransferStart = iota
+	// TransferPlay starts playing right away
+	TransferPlay
+	// TransferPaused starts paused, until a play command
+	TransferPaused
+	// TransferConfirm starts paused, and sends a play command to the handler once the transfer is confirmed locally,
+	// e.g. by the staff of a kiosk or a conference room
+	TransferConfirm
+)
+
+// TransferConfirmFunc is called with the load command of a transfer when it needs to be confirmed, from its own
+// goroutine, and returns whether the playback starts. It may block until the transfer is confirmed.
+type TransferConfirmFunc func(cmd Command) bool
+
+// SetTransferStart sets how the playback starts when it is transferred to this device. The confirm function is
+// called for TransferConfirm, without it the playback stays paused until a play command.
+func (c *Controller) SetTransferStart(start TransferStart, confirm TransferConfirmFunc) {
+	c.localLock.Lock()
+	c.transferStart = start
+	c.transferConfirm = confirm
+	c.localLock.Unlock()
+}
+
+// startState returns a copy of the state of a load command with the status set by the transfer start behavior
+func startState(state *Spotify.State, start TransferStart) *Spotify.State {
+	var status Spotify.PlayStatus
+	switch {
+	case state == nil || start == TransferAsRequested:
+		return state
+	case start == TransferPlay:
+		status = Spotify.PlayStatus_kPlayStatusPlay
+	default:
+		status = Spotify.PlayStatus_kPlayStatusPause
+	}
+
+	state = proto.Clone(state).(*Spotify.State)
+	state.Status = status.Enum()
+	return state
+}
+
+// load passes a load command to the handler, with its tracks filtered and its status set by the transfer start
+// behavior, and asks for the confirmation of the transfer if needed. It returns false if the session is not advertised.
+func (c *Controller) load(cmd Command) bool {
+	c.localLock.Lock()
+	filter := c.contentFilter
+	start, confirm := c.transferStart, c.transferConfirm
+	c.transfers++
+	transfer := c.transfers
+	c.localLock.Unlock()
+
+	cmd.State = startState(filterState(cmd.State, filter, c.logger()), start)
+	if !c.dispatchCommand(cmd) {
+		return false
+	}
+	if start == TransferConfirm && confirm != nil {
+		go c.confirmTransfer(cmd, transfer, confirm)
+	}
+	return true
+}
+
+// confirmTransfer asks for the confirmation of a transfer, and passes a play command to the handler once it is
+// confirmed, unless another load command was received meanwhile
+func (c *Controller) confirmTransfer(cmd Command, transfer uint64, confirm TransferConfirmFunc) {
+	if !confirm(cmd) {
+		return
+	}
+
+	c.localLock.Lock()
+	current := c.transfers == transfer
+	c.localLock.Unlock()
+	if current {
+		c.dispatchCommand(Command{
+			Type: Spotify.MessageType_kMessageTypePlay,
+			From: cmd.From,
+		})
+	}
+}
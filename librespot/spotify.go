@@ -37,3 +37,15 @@ func LoginDiscoveryBlobFile(cacheBlobPath string, deviceName string) (*core.Sess
 func LoginOAuth(deviceName string, clientId string, clientSecret string) (*core.Session, error) {
 	return core.LoginOAuth(deviceName, clientId, clientSecret)
 }
+
+// LoginWithAccessToken logs in to Spotify with an access token obtained elsewhere, e.g. by the OAuth flow of the
+// application
+func LoginWithAccessToken(token core.AccessToken, deviceName string) (*core.Session, error) {
+	return core.LoginWithAccessToken(token, deviceName)
+}
+
+// NewSession creates a session with the specified configuration and connects it to an access point. The session
+// must then be authenticated with one of its Login methods.
+func NewSession(config core.SessionConfig) (*core.Session, error) {
+	return core.NewSession(config)
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dir is a KV and Blobs store keeping every value in a file of a directory, only readable by the current user. The
+// keys are the paths of the files in the directory.
+type Dir struct {
+	dir string
+}
+
+// NewDir creates a store keeping the values in dir, which is created if needed
+func NewDir(dir string) (*Dir, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Dir{dir: dir}, nil
+}
+
+// file returns the file of a key
+func (d *Dir) file(key string) (string, error) {
+	if err := ValidKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(key)), nil
+}
+
+func (d *Dir) Get(key string) ([]byte, error) {
+	r, err := d.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func (d *Dir) Put(key string, value []byte) error {
+	return d.write(key, func(w io.Writer) error {
+		_, err := w.Write(value)
+		return err
+	})
+}
+
+func (d *Dir) Delete(key string) error {
+	return d.Remove(key)
+}
+
+func (d *Dir) Open(key string) (io.ReadCloser, error) {
+	p, err := d.file(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (d *Dir) Write(key string, r io.Reader) error {
+	return d.write(key, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+func (d *Dir) Remove(key string) error {
+	p, err := d.file(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Keys returns the keys of the files, leaving out the temporary files of the writes in progress
+func (d *Dir) Keys(prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.Walk(d.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(d.dir, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// write writes the file of a key to a temporary file first, then renames it, so that readers never see partial data
+func (d *Dir) write(key string, write func(w io.Writer) error) error {
+	p, err := d.file(key)
+	if err != nil {
+		return err
+	}
+	dir, name := filepath.Split(p)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+name+".tmp")
+	if err != nil {
+		return err
+	}
+	err = write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory is a KV and Blobs store keeping the values in memory, for the lifetime of the process
+type Memory struct {
+	lock   sync.Mutex
+	values map[string][]byte
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{values: map[string][]byte{}}
+}
+
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte{}, value...), nil
+}
+
+func (m *Memory) Put(key string, value []byte) error {
+	m.lock.Lock()
+	m.values[key] = append([]byte{}, value...)
+	m.lock.Unlock()
+	return nil
+}
+
+func (m *Memory) Delete(key string) error {
+	m.lock.Lock()
+	delete(m.values, key)
+	m.lock.Unlock()
+	return nil
+}
+
+func (m *Memory) Open(key string) (io.ReadCloser, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// The stored values are never modified, only replaced
+	return ioutil.NopCloser(bytes.NewReader(value)), nil
+}
+
+func (m *Memory) Write(key string, r io.Reader) error {
+	value, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.lock.Lock()
+	m.values[key] = value
+	m.lock.Unlock()
+	return nil
+}
+
+func (m *Memory) Remove(key string) error {
+	return m.Delete(key)
+}
+
+func (m *Memory) Keys(prefix string) ([]string, error) {
+	m.lock.Lock()
+	var keys []string
+	for key := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	m.lock.Unlock()
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,54 @@
+// Package storage defines the stores backing the caches and the persistent state of the library, so that embedders
+// can keep them in their own databases (BoltDB, SQLite, S3, ...) instead of the files the library would choose.
+//
+// The keys are slash-separated paths, e.g. "<hex file id>/3.chunk", which the directory stores map to files.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound is returned when no value is stored for a key
+var ErrNotFound = errors.New("not found")
+
+// KV stores small values by key, e.g. the credentials and the indexes of the caches. Implementations must be safe
+// for concurrent use.
+type KV interface {
+	// Get returns the value of the key, or ErrNotFound
+	Get(key string) ([]byte, error)
+	// Put stores the value of the key, replacing the previous one. Readers must never see a partial value.
+	Put(key string, value []byte) error
+	// Delete removes the value of the key, if any
+	Delete(key string) error
+}
+
+// Blobs stores large values by key, e.g. the audio chunks and the offline tracks, which are streamed. Implementations
+// must be safe for concurrent use.
+type Blobs interface {
+	// Open returns a reader of the blob of the key, or ErrNotFound
+	Open(key string) (io.ReadCloser, error)
+	// Write stores the blob of the key, read until EOF, replacing the previous one. Readers must never see a partial
+	// blob.
+	Write(key string, r io.Reader) error
+	// Remove removes the blob of the key, if any
+	Remove(key string) error
+}
+
+// Lister lists the keys of a store, e.g. to verify the entries of a cache. The stores of this package implement it.
+type Lister interface {
+	// Keys returns the sorted keys starting with the prefix
+	Keys(prefix string) ([]string, error)
+}
+
+// ValidKey returns an error if the key isn't a clean relative path, e.g. if it would escape a directory
+func ValidKey(key string) error {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") ||
+		strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKV(t *testing.T, kv KV) {
+	if _, err := kv.Get("a/key"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := kv.Put("a/key", []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.Put("a/key", []byte("two")); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := kv.Get("a/key"); err != nil || string(value) != "two" {
+		t.Errorf("Got %q, %v", value, err)
+	}
+	if err := kv.Delete("a/key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.Get("a/key"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound once deleted, got %v", err)
+	}
+	if err := kv.Delete("a/key"); err != nil {
+		t.Errorf("Deleting a missing key failed: %v", err)
+	}
+}
+
+func testBlobs(t *testing.T, blobs Blobs) {
+	if _, err := blobs.Open("blob"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := blobs.Write("blob", strings.NewReader("content")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := blobs.Open("blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "content" {
+		t.Errorf("Got %q, %v", data, err)
+	}
+	if err := blobs.Remove("blob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blobs.Open("blob"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound once removed, got %v", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testKV(t, NewMemory())
+	testBlobs(t, NewMemory())
+}
+
+func TestDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d, err := NewDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testKV(t, d)
+	testBlobs(t, d)
+
+	// The keys are the paths of the files
+	if err := d.Put("file/size", []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "file", "size")); err != nil {
+		t.Errorf("Value not stored in its file: %v", err)
+	}
+
+	for _, key := range []string{"", "/etc/passwd", "../escape", "a/../../escape", "a//b", `a\b`} {
+		if err := d.Put(key, []byte{1}); err == nil {
+			t.Errorf("Invalid key %q accepted", key)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d, err := NewDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lister := range []interface {
+		KV
+		Lister
+	}{NewMemory(), d} {
+		for _, key := range []string{"b/2", "a", "b/1"} {
+			if err := lister.Put(key, []byte{1}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if keys, err := lister.Keys(""); err != nil || strings.Join(keys, " ") != "a b/1 b/2" {
+			t.Errorf("Got keys %v, %v", keys, err)
+		}
+		if keys, err := lister.Keys("b/"); err != nil || strings.Join(keys, " ") != "b/1 b/2" {
+			t.Errorf("Got keys %v, %v", keys, err)
+		}
+	}
+}
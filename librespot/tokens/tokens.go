@@ -0,0 +1,279 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+	"github.com/fischerling/librespot-golang/librespot/ops"
+)
+
+// KeymasterClientId is the client id of the official Spotify client, which is allowed to request tokens for most
+// Web API scopes
+const KeymasterClientId = "65b708073fc0480ea92a077233ca87bd"
+
+// DefaultRefreshMargin is how long before their expiry tokens are refreshed
+const DefaultRefreshMargin = time.Minute
+
+// Fetcher requests new access tokens from the keymaster service. *mercury.Client implements it.
+type Fetcher interface {
+	GetToken(clientId string, scopes string) (*metadata.Token, error)
+}
+
+// Token is an OAuth access token usable to call the Spotify Web API
+type Token struct {
+	AccessToken string
+	TokenType   string
+	Scopes      []string
+	Expiry      time.Time
+}
+
+// Header returns the value of the Authorization header to send with Web API requests
+func (t *Token) Header() string {
+	tokenType := t.TokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// ClientTokenFetcher requests a new client token, which identifies the client installation to the spclient and
+// CDN services, independently of the user
+type ClientTokenFetcher func() (*Token, error)
+
+// clientTokenKey is the cache key of the client token, which can't be a scopes key
+const clientTokenKey = "\x00client-token"
+
+// Provider requests access tokens through the session credentials, and caches them per set of scopes until shortly
+// before they expire. It also caches the client token, if a ClientTokenFetcher is set. It is safe for concurrent use,
+// and is shared by the subsystems of the session (dealer, spclient, storage resolution, Web API calls): concurrent
+// requests for the same token wait for a single refresh.
+type Provider struct {
+	// ClientId is the client the tokens are requested for
+	ClientId string
+	// RefreshMargin is how long before their expiry cached tokens are replaced by new ones
+	RefreshMargin time.Duration
+
+	fetcher      Fetcher
+	clientTokens ClientTokenFetcher
+	now          func() time.Time
+	lock         sync.Mutex
+	tokens       map[string]*Token
+	registry     *ops.Registry
+	// refreshes are the token requests in flight, by cache key
+	refreshes map[string]*refresh
+	// generation is incremented by Clear, so that the tokens requested before aren't cached
+	generation int
+}
+
+// refresh is a token request in flight, whose result is shared by the callers waiting for it
+type refresh struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewProvider creates a provider requesting tokens for the specified client id, or for KeymasterClientId if empty
+func NewProvider(fetcher Fetcher, clientId string) *Provider {
+	if clientId == "" {
+		clientId = KeymasterClientId
+	}
+	return &Provider{
+		ClientId:      clientId,
+		RefreshMargin: DefaultRefreshMargin,
+		fetcher:       fetcher,
+		now:           time.Now,
+		tokens:        make(map[string]*Token),
+		refreshes:     make(map[string]*refresh),
+	}
+}
+
+// SetRegistry sets the registry tracking the token requests in flight, allowing to cancel them
+func (p *Provider) SetRegistry(registry *ops.Registry) {
+	p.lock.Lock()
+	p.registry = registry
+	p.lock.Unlock()
+}
+
+// SetClock sets the function returning the current time, with which the expiry of the tokens is computed and
+// evaluated, e.g. a clock corrected with the time of the server on devices without a battery-backed clock
+func (p *Provider) SetClock(now func() time.Time) {
+	p.lock.Lock()
+	p.now = now
+	p.lock.Unlock()
+}
+
+// SetClientTokenFetcher sets the function requesting the client tokens returned by ClientToken
+func (p *Provider) SetClientTokenFetcher(fetcher ClientTokenFetcher) {
+	p.lock.Lock()
+	p.clientTokens = fetcher
+	p.lock.Unlock()
+}
+
+// Get returns a token valid for the specified scopes, requesting a new one if none is cached or the cached one is
+// about to expire
+func (p *Provider) Get(scopes ...string) (*Token, error) {
+	key := scopesKey(scopes)
+	token, err := p.get(key, func() (*Token, error) {
+		res, err := p.fetch(key)
+		if err != nil {
+			return nil, err
+		}
+		if res.AccessToken == "" {
+			return nil, errors.New("empty token received")
+		}
+		p.lock.Lock()
+		now := p.now
+		p.lock.Unlock()
+		return &Token{
+			AccessToken: res.AccessToken,
+			TokenType:   res.TokenType,
+			Scopes:      res.Scope,
+			Expiry:      now().Add(time.Duration(res.ExpiresIn) * time.Second),
+		}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token for scopes %s: %v", key, err)
+	}
+	return token, nil
+}
+
+// ClientToken returns the client token, requesting a new one if none is cached or the cached one is about to expire.
+// It returns ErrNoClientToken if no ClientTokenFetcher is set.
+func (p *Provider) ClientToken() (*Token, error) {
+	p.lock.Lock()
+	fetcher := p.clientTokens
+	p.lock.Unlock()
+	if fetcher == nil {
+		return nil, ErrNoClientToken
+	}
+
+	token, err := p.get(clientTokenKey, fetcher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client token: %v", err)
+	}
+	return token, nil
+}
+
+// ErrNoClientToken is returned by ClientToken when the provider can't request client tokens
+var ErrNoClientToken = errors.New("no client token fetcher")
+
+// Authorize sets the Authorization header of a request to a token valid for the specified scopes, and its
+// client-token header if a client token can be obtained. The client token is optional for most services, so failing
+// to get one is not an error.
+func (p *Provider) Authorize(req *http.Request, scopes ...string) error {
+	token, err := p.Get(scopes...)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token.Header())
+
+	if clientToken, err := p.ClientToken(); err == nil {
+		req.Header.Set("client-token", clientToken.AccessToken)
+	}
+	return nil
+}
+
+// get returns the cached token with the key if it is still valid, and otherwise requests a new one with fetch,
+// unless another caller already does
+func (p *Provider) get(key string, fetch func() (*Token, error)) (*Token, error) {
+	p.lock.Lock()
+	if token, ok := p.tokens[key]; ok && p.now().Add(p.RefreshMargin).Before(token.Expiry) {
+		p.lock.Unlock()
+		return token, nil
+	}
+	if r, ok := p.refreshes[key]; ok {
+		p.lock.Unlock()
+		<-r.done
+		return r.token, r.err
+	}
+	r := &refresh{done: make(chan struct{})}
+	p.refreshes[key] = r
+	generation := p.generation
+	p.lock.Unlock()
+
+	r.token, r.err = fetch()
+
+	p.lock.Lock()
+	if p.refreshes[key] == r {
+		delete(p.refreshes, key)
+	}
+	if r.err == nil && generation == p.generation {
+		p.tokens[key] = r.token
+	}
+	p.lock.Unlock()
+	close(r.done)
+
+	return r.token, r.err
+}
+
+// fetch requests a new token, until it is received or the request is cancelled
+func (p *Provider) fetch(scopes string) (*metadata.Token, error) {
+	p.lock.Lock()
+	registry := p.registry
+	p.lock.Unlock()
+
+	ctx, finish := registry.Start(ops.KindTokenRefresh, scopes)
+	defer finish()
+
+	type fetchResult struct {
+		token *metadata.Token
+		err   error
+	}
+	done := make(chan fetchResult, 1)
+	go func() {
+		token, err := p.fetcher.GetToken(p.ClientId, scopes)
+		done <- fetchResult{token, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.token, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate drops the cached token for the specified scopes, e.g. after the Web API rejected it
+func (p *Provider) Invalidate(scopes ...string) {
+	p.lock.Lock()
+	delete(p.tokens, scopesKey(scopes))
+	p.lock.Unlock()
+}
+
+// Clear drops all the cached access tokens, e.g. when another user logs in, and detaches the refreshes in flight so
+// that later calls don't join them. The client token, which doesn't depend on the user, is kept.
+func (p *Provider) Clear() {
+	p.lock.Lock()
+	clientToken, ok := p.tokens[clientTokenKey]
+	p.tokens = make(map[string]*Token)
+	if ok {
+		p.tokens[clientTokenKey] = clientToken
+	}
+	clientRefresh, ok := p.refreshes[clientTokenKey]
+	p.refreshes = make(map[string]*refresh)
+	if ok {
+		p.refreshes[clientTokenKey] = clientRefresh
+	}
+	p.generation++
+	p.lock.Unlock()
+}
+
+// scopesKey returns the scopes in a canonical order, comma separated as expected by keymaster
+func scopesKey(scopes []string) string {
+	sorted := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		for _, s := range strings.Split(scope, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				sorted = append(sorted, s)
+			}
+		}
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
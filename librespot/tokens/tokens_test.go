@@ -0,0 +1,177 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/metadata"
+)
+
+type fakeFetcher struct {
+	calls  int
+	scopes string
+	err    error
+}
+
+func (f *fakeFetcher) GetToken(clientId string, scopes string) (*metadata.Token, error) {
+	f.calls++
+	f.scopes = scopes
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &metadata.Token{
+		AccessToken: "token",
+		ExpiresIn:   3600,
+		TokenType:   "Bearer",
+	}, nil
+}
+
+func TestProviderCache(t *testing.T) {
+	fetcher := &fakeFetcher{}
+	provider := NewProvider(fetcher, "")
+	now := time.Unix(1000, 0)
+	provider.now = func() time.Time { return now }
+
+	token, err := provider.Get("user-read-private", "playlist-read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Header() != "Bearer token" || fetcher.scopes != "playlist-read,user-read-private" {
+		t.Errorf("Bad token %v for scopes %q", token, fetcher.scopes)
+	}
+
+	// Same scopes in another order use the cached token
+	provider.Get("playlist-read,user-read-private")
+	if fetcher.calls != 1 {
+		t.Errorf("Token requested %d times, want 1", fetcher.calls)
+	}
+
+	// Tokens are refreshed before they expire
+	now = now.Add(time.Hour - DefaultRefreshMargin)
+	provider.Get("playlist-read", "user-read-private")
+	if fetcher.calls != 2 {
+		t.Errorf("Token not refreshed before expiry")
+	}
+
+	provider.Invalidate("user-read-private", "playlist-read")
+	provider.Get("playlist-read", "user-read-private")
+	if fetcher.calls != 3 {
+		t.Errorf("Token not requested after invalidation")
+	}
+
+	provider.Get("streaming")
+	provider.Clear()
+	provider.Get("playlist-read", "user-read-private")
+	provider.Get("streaming")
+	if fetcher.calls != 6 {
+		t.Errorf("Tokens not requested after clearing the cache")
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	provider := NewProvider(&fakeFetcher{err: errors.New("boom")}, "client")
+	if _, err := provider.Get("streaming"); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+type blockingFetcher struct {
+	lock    sync.Mutex
+	calls   int
+	release chan struct{}
+}
+
+func (f *blockingFetcher) GetToken(clientId string, scopes string) (*metadata.Token, error) {
+	f.lock.Lock()
+	f.calls++
+	f.lock.Unlock()
+	<-f.release
+	return &metadata.Token{AccessToken: "token", ExpiresIn: 3600}, nil
+}
+
+func TestProviderSharedRefresh(t *testing.T) {
+	fetcher := &blockingFetcher{release: make(chan struct{})}
+	provider := NewProvider(fetcher, "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := provider.Get("streaming"); err != nil || token.AccessToken != "token" {
+				t.Errorf("Bad token %v: %v", token, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(fetcher.release)
+	wg.Wait()
+
+	if fetcher.calls != 1 {
+		t.Errorf("Token requested %d times, want 1", fetcher.calls)
+	}
+}
+
+func TestProviderClearDuringRefresh(t *testing.T) {
+	fetcher := &blockingFetcher{release: make(chan struct{})}
+	provider := NewProvider(fetcher, "")
+
+	var wg sync.WaitGroup
+	get := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			provider.Get("streaming")
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+	get()
+	provider.Clear()
+
+	// Requests of the next user don't join the refresh of the previous one
+	get()
+	fetcher.lock.Lock()
+	calls := fetcher.calls
+	fetcher.lock.Unlock()
+	if calls != 2 {
+		t.Errorf("Token requested %d times, want 2", calls)
+	}
+	close(fetcher.release)
+	wg.Wait()
+
+	// Only the token of the next user is cached
+	provider.Get("streaming")
+	if fetcher.calls != 2 {
+		t.Errorf("Token requested %d times, want 2", fetcher.calls)
+	}
+}
+
+func TestProviderClientToken(t *testing.T) {
+	provider := NewProvider(&fakeFetcher{}, "")
+	if _, err := provider.ClientToken(); err != ErrNoClientToken {
+		t.Errorf("Expected ErrNoClientToken, got %v", err)
+	}
+
+	calls := 0
+	provider.SetClientTokenFetcher(func() (*Token, error) {
+		calls++
+		return &Token{AccessToken: "client", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+	req, _ := http.NewRequest("GET", "https://spclient.wg.spotify.com/", nil)
+	if err := provider.Authorize(req, "playlist-read"); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Authorization") != "Bearer token" || req.Header.Get("client-token") != "client" {
+		t.Errorf("Bad headers: %v", req.Header)
+	}
+
+	// The client token doesn't depend on the user
+	provider.Clear()
+	provider.ClientToken()
+	if calls != 1 {
+		t.Errorf("Client token requested %d times, want 1", calls)
+	}
+}
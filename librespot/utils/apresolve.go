@@ -3,40 +3,105 @@ package utils
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
 )
 
 const kAPEndpoint = "https://APResolve.spotify.com/"
+const kDealerEndpoint = "https://APResolve.spotify.com/?type=dealer"
+const kEndpointsEndpoint = "https://APResolve.spotify.com/?type=accesspoint&type=dealer&type=spclient"
+
+// Endpoints lists the servers of each kind, in the host:port form, in the order of preference of the resolver
+type Endpoints struct {
+	AccessPoints []string `json:"accesspoint"`
+	Dealers      []string `json:"dealer"`
+	SpClients    []string `json:"spclient"`
+}
+
+// FallbackEndpoints are the well-known servers, used when the resolver can't be reached
+var FallbackEndpoints = Endpoints{
+	AccessPoints: []string{"ap.spotify.com:443", "ap.spotify.com:4070", "ap.spotify.com:80"},
+	Dealers:      []string{"dealer.spotify.com:443"},
+	SpClients:    []string{"spclient.wg.spotify.com:443"},
+}
 
 // APList is the JSON structure corresponding to the output of the AP endpoint resolve API
 type APList struct {
-	ApList []string `json:"ap_list"`
+	ApList     []string `json:"ap_list"`
+	DealerList []string `json:"dealer"`
 }
 
 // APResolve fetches the available Spotify servers (AP) and picks a random one
 func APResolve() (string, error) {
-	r, err := http.Get(kAPEndpoint)
+	return APResolveWithClient(http.DefaultClient)
+}
+
+// APResolveWithClient fetches the available Spotify servers (AP) using the specified HTTP client, and picks a random
+// one
+func APResolveWithClient(client *http.Client) (string, error) {
+	endpoints, err := resolve(client, kAPEndpoint)
 	if err != nil {
 		return "", err
 	}
-	defer r.Body.Close()
+	if len(endpoints.ApList) == 0 {
+		return "", errors.New("AP endpoint list is empty")
+	}
 
-	var endpoints APList
+	return endpoints.ApList[rand.Intn(len(endpoints.ApList))], nil
+}
 
-	body, err := ioutil.ReadAll(r.Body)
+// DealerResolveWithClient fetches the available dealer servers, delivering push messages over WebSocket, using the
+// specified HTTP client, and picks a random one
+func DealerResolveWithClient(client *http.Client) (string, error) {
+	endpoints, err := resolve(client, kDealerEndpoint)
 	if err != nil {
 		return "", err
 	}
+	if len(endpoints.DealerList) == 0 {
+		return "", errors.New("dealer endpoint list is empty")
+	}
+
+	return endpoints.DealerList[rand.Intn(len(endpoints.DealerList))], nil
+}
 
-	err = json.Unmarshal(body, &endpoints)
+// ResolveEndpoints fetches the lists of access points, dealers and spclients using the specified HTTP client. Unlike
+// APResolveWithClient, the lists are kept in order, so that the servers can be tried one after the other.
+func ResolveEndpoints(client *http.Client) (*Endpoints, error) {
+	var endpoints Endpoints
+	if err := getJson(client, kEndpointsEndpoint, &endpoints); err != nil {
+		return nil, err
+	}
+	if len(endpoints.AccessPoints) == 0 {
+		return nil, errors.New("AP endpoint list is empty")
+	}
+	return &endpoints, nil
+}
+
+func resolve(client *http.Client, endpoint string) (*APList, error) {
+	var endpoints APList
+	if err := getJson(client, endpoint, &endpoints); err != nil {
+		return nil, err
+	}
+	return &endpoints, nil
+}
+
+func getJson(client *http.Client, endpoint string, v interface{}) error {
+	r, err := client.Get(endpoint)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if len(endpoints.ApList) == 0 {
-		return "", errors.New("AP endpoint list is empty")
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", endpoint, r.Status)
 	}
 
-	return endpoints.ApList[rand.Intn(len(endpoints.ApList))], nil
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
 }
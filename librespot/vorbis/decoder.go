@@ -0,0 +1,141 @@
+// Package vorbis decodes the Ogg Vorbis audio files of the player to PCM frames, and seeks in them by time, using the
+// granule positions of the Ogg pages rather than byte offsets.
+//
+// The decoder is backed by libvorbis through cgo, and needs the libogg and libvorbis headers, and the vorbis build
+// tag. Without it, NewDecoder returns ErrVorbisDisabled.
+package vorbis
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+)
+
+// ErrVorbisDisabled is returned by NewDecoder when the library is built without the vorbis tag
+var ErrVorbisDisabled = errors.New("Vorbis decoding is disabled in this build")
+
+// SamplesPerFrame is the number of samples of the frames returned by the decoders, except the last one
+const SamplesPerFrame = 2048
+
+// Info describes the decoded audio
+type Info struct {
+	Channels   int
+	SampleRate int
+}
+
+// Stream is an Ogg Vorbis stream, e.g. a player.AudioFile. It is read sequentially while decoding, and the pages are
+// looked up with ReadAt while seeking.
+type Stream interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// Decoder decodes an audio stream to PCM frames. It is not safe for concurrent use.
+type Decoder interface {
+	// Info returns the channels and sample rate of the audio
+	Info() Info
+	// ReadFrame returns the next frame of samples, each one holding a value between -1 and 1 per channel. It returns
+	// io.EOF at the end of the stream.
+	ReadFrame() ([][]float32, error)
+	// Seek moves to a position in the audio, the next frame starting there
+	Seek(position time.Duration) error
+	// Position returns the position of the start of the next frame
+	Position() time.Duration
+	// Close stops the decoding and releases the decoder
+	Close() error
+}
+
+// backend decodes an Ogg Vorbis stream, e.g. with libvorbis
+type backend interface {
+	// start starts decoding the stream from the page at offset, after the headers of the stream
+	start(offset int64) error
+	// nextFrame returns the next frame decoded, and io.EOF at the end of the stream
+	nextFrame() ([][]float32, error)
+	// stop stops the decoding
+	stop()
+}
+
+// pageDecoder decodes a stream with a backend. It seeks by restarting the backend at the page of the position, and
+// dropping the samples of the page before it.
+type pageDecoder struct {
+	r       Stream
+	backend backend
+	info    Info
+	// audioStart is the offset of the first audio page, following the headers, and size the size of the stream
+	audioStart int64
+	size       int64
+
+	// position is the number of samples before the next frame, and skip the number of samples to drop once seeked,
+	// until the position is reached
+	position int64
+	skip     int64
+}
+
+// newPageDecoder returns a decoder of the stream, whose backend is started at its first audio page
+func newPageDecoder(r Stream, b backend, info Info, audioStart, size int64) *pageDecoder {
+	return &pageDecoder{r: r, backend: b, info: info, audioStart: audioStart, size: size}
+}
+
+func (d *pageDecoder) Info() Info {
+	return d.info
+}
+
+func (d *pageDecoder) ReadFrame() ([][]float32, error) {
+	for {
+		frame, err := d.backend.nextFrame()
+		if err != nil {
+			return nil, err
+		}
+		if d.skip >= int64(len(frame)) {
+			d.skip -= int64(len(frame))
+			continue
+		}
+		frame = frame[d.skip:]
+		d.skip = 0
+		d.position += int64(len(frame))
+		return frame, nil
+	}
+}
+
+func (d *pageDecoder) Seek(position time.Duration) error {
+	if position < 0 {
+		return fmt.Errorf("negative position")
+	}
+	target := samplesAt(position, d.info.SampleRate)
+	offset, before, err := player.OggSeekPage(d.r, d.audioStart, d.size, target)
+	if err != nil {
+		return err
+	}
+
+	d.backend.stop()
+	if err := d.backend.start(offset); err != nil {
+		return err
+	}
+	d.position, d.skip = target, target-before
+	return nil
+}
+
+func (d *pageDecoder) Position() time.Duration {
+	return durationOf(d.position, d.info.SampleRate)
+}
+
+func (d *pageDecoder) Close() error {
+	d.backend.stop()
+	return nil
+}
+
+// samplesAt returns the number of samples played at a position
+func samplesAt(position time.Duration, sampleRate int) int64 {
+	return int64(position) * int64(sampleRate) / int64(time.Second)
+}
+
+// durationOf returns the duration of a number of samples
+func durationOf(samples int64, sampleRate int) time.Duration {
+	if sampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(samples * int64(time.Second) / int64(sampleRate))
+}
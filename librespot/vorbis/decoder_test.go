@@ -0,0 +1,127 @@
+package vorbis
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/oggtest"
+)
+
+// stubBackend decodes frames of 300 samples from the page it is started at, each sample holding its granule
+// position, until the end of the stream
+type stubBackend struct {
+	// granules are the granule positions at the beginning of the pages, by offset
+	granules map[int64]int64
+	total    int64
+
+	next    int64
+	started []int64
+	stopped int
+}
+
+func (b *stubBackend) start(offset int64) error {
+	b.started = append(b.started, offset)
+	b.next = b.granules[offset]
+	return nil
+}
+
+func (b *stubBackend) nextFrame() ([][]float32, error) {
+	if b.next >= b.total {
+		return nil, io.EOF
+	}
+	var frame [][]float32
+	for ; b.next < b.total && len(frame) < 300; b.next++ {
+		frame = append(frame, []float32{float32(b.next)})
+	}
+	return frame, nil
+}
+
+func (b *stubBackend) stop() {
+	b.stopped++
+}
+
+// newStubDecoder returns a decoder of a stream of 5 pages of 1000 samples at 1000Hz, backed by a stubBackend
+func newStubDecoder() (*pageDecoder, *stubBackend, map[int64]int64) {
+	stream, offsets := oggtest.Stream(5)
+	b := &stubBackend{granules: map[int64]int64{}, total: 5000}
+	for granule, offset := range offsets {
+		b.granules[offset] = granule
+	}
+	b.start(offsets[0])
+	r := bytes.NewReader(stream)
+	return newPageDecoder(r, b, Info{Channels: 1, SampleRate: 1000}, offsets[0], r.Size()), b, offsets
+}
+
+func TestPageDecoderRead(t *testing.T) {
+	d, _, _ := newStubDecoder()
+	var samples int64
+	for {
+		frame, err := d.ReadFrame()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if int64(frame[0][0]) != samples {
+			t.Fatalf("Frame starts at %v, want %d", frame[0][0], samples)
+		}
+		samples += int64(len(frame))
+		if d.Position() != time.Duration(samples)*time.Millisecond {
+			t.Fatalf("Position %v after %d samples", d.Position(), samples)
+		}
+	}
+	if samples != 5000 {
+		t.Errorf("Read %d samples, want 5000", samples)
+	}
+}
+
+func TestPageDecoderSeek(t *testing.T) {
+	d, b, offsets := newStubDecoder()
+
+	// The decoding restarts at the page holding the position, the samples before it being dropped, across frames
+	for _, target := range []int64{2500, 3000, 999, 0, 4999} {
+		if err := d.Seek(time.Duration(target) * time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if start := b.started[len(b.started)-1]; start != offsets[target/1000*1000] {
+			t.Errorf("Seeking to %d started at %d, want %d", target, start, offsets[target/1000*1000])
+		}
+		if d.Position() != time.Duration(target)*time.Millisecond {
+			t.Errorf("Position %v after seeking to %d", d.Position(), target)
+		}
+		frame, err := d.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(frame[0][0]) != target {
+			t.Errorf("Seeking to %d, the frame starts at %v", target, frame[0][0])
+		}
+		if d.Position() != time.Duration(target+int64(len(frame)))*time.Millisecond {
+			t.Errorf("Position %v after reading %d samples from %d", d.Position(), len(frame), target)
+		}
+	}
+	if b.stopped != 5 {
+		t.Errorf("Backend stopped %d times, want 5", b.stopped)
+	}
+
+	// Past the end, the decoding restarts at the end of the stream
+	if err := d.Seek(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if start := b.started[len(b.started)-1]; start != offsets[5000] {
+		t.Errorf("Seeking past the end started at %d, want %d", start, offsets[5000])
+	}
+	if _, err := d.ReadFrame(); err != io.EOF {
+		t.Errorf("Expected io.EOF past the end, got %v", err)
+	}
+
+	if err := d.Seek(-time.Second); err == nil {
+		t.Errorf("Seeking to a negative position succeeded")
+	}
+	d.Close()
+	if b.stopped != 7 {
+		t.Errorf("Backend not stopped when closed")
+	}
+}
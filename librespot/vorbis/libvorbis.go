@@ -0,0 +1,103 @@
+//go:build vorbis
+// +build vorbis
+
+package vorbis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/xlab/vorbis-go/decoder"
+)
+
+var errClosed = errors.New("decoder closed")
+
+// libvorbisBackend decodes a stream with libvorbis, from the page at the offset it is started at, after the headers
+// of the stream
+type libvorbisBackend struct {
+	r       Stream
+	headers []byte
+
+	dec  *decoder.Decoder
+	done chan error
+	err  error
+}
+
+// NewDecoder returns a decoder of the Ogg Vorbis stream. Seeking reads the granule positions of a few pages of the
+// stream, see player.OggSeekPage.
+func NewDecoder(r Stream) (Decoder, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	end, err := player.OggAudioStart(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Vorbis headers: %v", err)
+	}
+	headers := make([]byte, end)
+	if n, err := r.ReadAt(headers, 0); n < len(headers) {
+		return nil, err
+	}
+
+	b := &libvorbisBackend{r: r, headers: headers}
+	if err := b.start(end); err != nil {
+		return nil, err
+	}
+	info := b.dec.Info()
+	return newPageDecoder(r, b, Info{Channels: int(info.Channels), SampleRate: int(info.SampleRate)}, end, size), nil
+}
+
+func (b *libvorbisBackend) start(offset int64) error {
+	if _, err := b.r.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	dec, err := decoder.New(io.MultiReader(bytes.NewReader(b.headers), b.r), SamplesPerFrame)
+	if err != nil {
+		return err
+	}
+	// The corrupt pages are skipped
+	dec.SetErrorHandler(func(err error) {})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- dec.Decode()
+	}()
+	b.dec, b.done, b.err = dec, done, nil
+	return nil
+}
+
+// stop stops the decoding, waiting for it to return
+func (b *libvorbisBackend) stop() {
+	b.dec.Close()
+	if b.err == nil {
+		<-b.done
+		b.err = errClosed
+	}
+}
+
+// nextFrame returns the next frame decoded, or the error which ended the decoding once all the frames are read
+func (b *libvorbisBackend) nextFrame() ([][]float32, error) {
+	if b.err == nil {
+		select {
+		case frame := <-b.dec.SamplesOut():
+			return frame, nil
+		case err := <-b.done:
+			b.err = err
+			if b.err == nil {
+				b.err = io.EOF
+			}
+		}
+	}
+	// The decoding returned, the frames left are buffered, unless the decoder is closed
+	select {
+	case frame, ok := <-b.dec.SamplesOut():
+		if ok {
+			return frame, nil
+		}
+	default:
+	}
+	return nil, b.err
+}
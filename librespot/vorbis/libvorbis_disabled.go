@@ -0,0 +1,9 @@
+//go:build !vorbis
+// +build !vorbis
+
+package vorbis
+
+// NewDecoder returns ErrVorbisDisabled, the library being built without the vorbis tag
+func NewDecoder(r Stream) (Decoder, error) {
+	return nil, ErrVorbisDisabled
+}
@@ -0,0 +1,207 @@
+// Package webhook posts the playback events of a player as JSON to HTTP endpoints, so that external systems can react
+// to them without embedding Go code.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/player"
+	"github.com/fischerling/librespot-golang/librespot/utils"
+)
+
+// SignatureHeader is the HTTP header holding the HMAC-SHA256 signature of the request body, as "sha256=<hex>"
+const SignatureHeader = "X-Librespot-Signature"
+
+// Endpoint is a webhook URL events are posted to
+type Endpoint struct {
+	Url string
+	// Secret is the key used to sign the requests. If empty, requests are not signed.
+	Secret []byte
+}
+
+// Config controls the delivery of the events
+type Config struct {
+	Endpoints []Endpoint
+	// MaxRetries is the number of times a delivery is retried after a network error or a 5xx/429 status code
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, doubled at every retry
+	RetryDelay time.Duration
+	// QueueSize is the number of events waiting for delivery before new events are dropped
+	QueueSize int
+	// Client is the HTTP client used to post the events. If nil, a client with a 10 seconds timeout is used.
+	Client *http.Client
+	// Logger receives the events dropped and the failed deliveries. The standard logger is used if nil, e.g. pass
+	// the logger of the session.
+	Logger *log.Logger
+}
+
+// DefaultConfig retries 3 times, starting after 1 second
+var DefaultConfig = Config{
+	MaxRetries: 3,
+	RetryDelay: 1 * time.Second,
+	QueueSize:  64,
+}
+
+// Payload is the JSON body posted for every event
+type Payload struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	TrackId    string    `json:"track_id,omitempty"`
+	FileId     string    `json:"file_id,omitempty"`
+	Format     string    `json:"format,omitempty"`
+	ContextUri string    `json:"context_uri,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Notifier delivers the events in the background, in the order they were received
+type Notifier struct {
+	config Config
+	queue  chan Payload
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewNotifier creates a notifier and starts its delivery goroutine
+func NewNotifier(config Config) *Notifier {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultConfig.QueueSize
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
+
+	n := &Notifier{
+		config: config,
+		queue:  make(chan Payload, config.QueueSize),
+	}
+	n.wg.Add(1)
+	go n.run()
+	return n
+}
+
+// Listener returns a player event listener queueing the events for delivery, to be registered with Player.OnEvent
+func (n *Notifier) Listener() player.EventListener {
+	return func(event player.Event) {
+		n.Notify(NewPayload(event))
+	}
+}
+
+// Notify queues a payload for delivery. It never blocks: the payload is dropped if the queue is full.
+func (n *Notifier) Notify(payload Payload) {
+	select {
+	case n.queue <- payload:
+	default:
+		n.config.Logger.Printf("[webhook] Queue full, dropping %s event\n", payload.Type)
+	}
+}
+
+// Close delivers the queued events, then stops the notifier. Notify must not be called afterwards.
+func (n *Notifier) Close() {
+	n.once.Do(func() {
+		close(n.queue)
+	})
+	n.wg.Wait()
+}
+
+// NewPayload converts a player event to its JSON representation
+func NewPayload(event player.Event) Payload {
+	payload := Payload{
+		Type:       event.Type.String(),
+		Time:       event.Time,
+		Format:     event.Format.String(),
+		ContextUri: event.Origin.ContextUri,
+	}
+	if event.TrackId != nil {
+		payload.TrackId = utils.ConvertTo62(event.TrackId)
+	}
+	if event.FileId != nil {
+		payload.FileId = hex.EncodeToString(event.FileId)
+	}
+	if event.Err != nil {
+		payload.Error = event.Err.Error()
+	}
+	return payload
+}
+
+// Sign returns the signature of the body with the secret, as sent in SignatureHeader
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) run() {
+	defer n.wg.Done()
+
+	for payload := range n.queue {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			n.config.Logger.Printf("[webhook] Failed to encode %s event: %v\n", payload.Type, err)
+			continue
+		}
+
+		for _, endpoint := range n.config.Endpoints {
+			if err := n.deliver(endpoint, body); err != nil {
+				n.config.Logger.Printf("[webhook] Failed to deliver %s event to %s: %v\n", payload.Type, endpoint.Url, err)
+			}
+		}
+	}
+}
+
+// deliver posts the body to the endpoint, retrying on server errors
+func (n *Notifier) deliver(endpoint Endpoint, body []byte) error {
+	delay := n.config.RetryDelay
+	var err error
+
+	for attempt := 0; attempt <= n.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		var retry bool
+		retry, err = n.post(endpoint, body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// post sends a single request, and returns whether it should be retried in case of error
+func (n *Notifier) post(endpoint Endpoint, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(endpoint.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+	}
+
+	resp, err := n.config.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
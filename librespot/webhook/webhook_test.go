@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeliveryRetryAndSignature(t *testing.T) {
+	secret := []byte("secret")
+
+	var lock sync.Mutex
+	attempts := 0
+	var received []Payload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		lock.Lock()
+		defer lock.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Header.Get(SignatureHeader) != Sign(secret, body) {
+			t.Errorf("bad signature %q", r.Header.Get(SignatureHeader))
+		}
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Error(err)
+		}
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	n := NewNotifier(Config{
+		Endpoints:  []Endpoint{{Url: server.URL, Secret: secret}},
+		MaxRetries: 2,
+		RetryDelay: time.Millisecond,
+	})
+	n.Notify(Payload{Type: "track_start", TrackId: "abc"})
+	n.Notify(Payload{Type: "track_end", TrackId: "abc"})
+	n.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if len(received) != 2 || received[0].Type != "track_start" || received[1].Type != "track_end" {
+		t.Errorf("bad payloads received: %v", received)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	logs := new(bytes.Buffer)
+	n := NewNotifier(Config{
+		Endpoints:  []Endpoint{{Url: server.URL}},
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+		Logger:     log.New(logs, "", 0),
+	})
+	n.Notify(Payload{Type: "error"})
+	n.Close()
+
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+	if !strings.Contains(logs.String(), "Failed to deliver error event") {
+		t.Errorf("failed delivery not logged: %q", logs.String())
+	}
+}
@@ -1,6 +1,10 @@
 package librespotmobile
 
-import "github.com/fischerling/librespot-golang/librespot/core"
+import (
+	"time"
+
+	"github.com/fischerling/librespot-golang/librespot/core"
+)
 
 // MobileSession exposes a simplified subset of the core.Session struct that is compatible with the subset
 // of types accepted by gomobile. Most calls are proxied to the underlying core.Session pointer, which we
@@ -31,6 +35,22 @@ func LoginSaved(username string, authData []byte, deviceName string) (*MobileSes
 	return initSessionImpl(sess)
 }
 
+// LoginWithAccessToken logs in with an access token obtained elsewhere, expiring in expiresIn seconds, or 0 if
+// unknown
+func LoginWithAccessToken(token string, expiresIn int64, deviceName string) (*MobileSession, error) {
+	accessToken := core.AccessToken{Token: token}
+	if expiresIn > 0 {
+		accessToken.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	sess, err := core.LoginWithAccessToken(accessToken, deviceName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return initSessionImpl(sess)
+}
+
 func initSessionImpl(sess *core.Session) (*MobileSession, error) {
 	return &MobileSession{
 		session: sess,